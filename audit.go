@@ -0,0 +1,99 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ops for audit error and audit op logs.
+const (
+	OpRecordAuditEvent  = "RecordAuditEvent"
+	OpFindAuditEvents   = "FindAuditEvents"
+	OpDeleteAuditEvents = "DeleteAuditEvents"
+)
+
+// Audit actions are the kinds of mutation recorded by an audit event.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// AuditEvent records who changed which resource and how. Before and After
+// hold the JSON encoded resource prior to and after the change; Before is
+// empty for a create and After is empty for a delete.
+type AuditEvent struct {
+	ID           ID           `json:"id,omitempty"`
+	Time         time.Time    `json:"time"`
+	Action       string       `json:"action"`
+	ResourceType ResourceType `json:"resourceType"`
+	ResourceID   ID           `json:"resourceID,omitempty"`
+	OrgID        ID           `json:"orgID,omitempty"`
+	UserID       ID           `json:"userID,omitempty"`
+	AuthorizerID ID           `json:"authorizerID,omitempty"`
+	// AuthorizerKind is the kind of the authorizer that made the change, e.g.
+	// authorization or session. It is empty for changes made by the system.
+	AuthorizerKind string          `json:"authorizerKind,omitempty"`
+	Before         json.RawMessage `json:"before,omitempty"`
+	After          json.RawMessage `json:"after,omitempty"`
+}
+
+// SetAuthorizer sets the actor of the event from the authorizer.
+func (e *AuditEvent) SetAuthorizer(a Authorizer) {
+	e.UserID = a.GetUserID()
+	e.AuthorizerID = a.Identifier()
+	e.AuthorizerKind = a.Kind()
+}
+
+// Valid returns an error if the event cannot be recorded.
+func (e *AuditEvent) Valid() error {
+	switch e.Action {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete:
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  "audit event action must be one of create, update or delete",
+		}
+	}
+	return e.ResourceType.Valid()
+}
+
+// AuditEventFilter represents a set of filters that restrict the returned
+// audit events. Since is inclusive and Until is exclusive.
+type AuditEventFilter struct {
+	ResourceType *ResourceType
+	ResourceID   *ID
+	OrgID        *ID
+	UserID       *ID
+	Action       *string
+	Since        *time.Time
+	Until        *time.Time
+}
+
+// Match returns true if the event passes the filter.
+func (f AuditEventFilter) Match(e *AuditEvent) bool {
+	return (f.ResourceType == nil || *f.ResourceType == e.ResourceType) &&
+		(f.ResourceID == nil || *f.ResourceID == e.ResourceID) &&
+		(f.OrgID == nil || *f.OrgID == e.OrgID) &&
+		(f.UserID == nil || *f.UserID == e.UserID) &&
+		(f.Action == nil || *f.Action == e.Action) &&
+		(f.Since == nil || !e.Time.Before(*f.Since)) &&
+		(f.Until == nil || e.Time.Before(*f.Until))
+}
+
+// AuditService records and retrieves audit events.
+type AuditService interface {
+	// RecordAuditEvent stores the event, setting its ID and, if unset, its time.
+	RecordAuditEvent(ctx context.Context, e *AuditEvent) error
+
+	// FindAuditEvents returns the events that match the filter in the order
+	// they were recorded, or the reverse if the options are descending.
+	FindAuditEvents(ctx context.Context, filter AuditEventFilter, opt ...FindOptions) ([]*AuditEvent, int, error)
+}
+
+// DefaultAuditEventFindOptions are the default options for the audit log.
+var DefaultAuditEventFindOptions = FindOptions{
+	Descending: true,
+	Limit:      100,
+}
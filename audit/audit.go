@@ -0,0 +1,71 @@
+// Package audit records an audit trail of the changes made through the
+// platform services.
+//
+// The services of this package wrap the influxdb services and record an
+// influxdb.AuditEvent for every successful create, update and delete. The
+// actor of an event is the authorizer on the context; changes made without
+// one are recorded as made by the system. A failure to record an event is
+// logged rather than returned, since the change itself has already been made.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"go.uber.org/zap"
+)
+
+// recorder records the events of a single resource type.
+type recorder struct {
+	log          *zap.Logger
+	audit        influxdb.AuditService
+	resourceType influxdb.ResourceType
+}
+
+func newRecorder(log *zap.Logger, as influxdb.AuditService, rt influxdb.ResourceType) recorder {
+	return recorder{
+		log:          log.With(zap.String("resource_type", string(rt))),
+		audit:        as,
+		resourceType: rt,
+	}
+}
+
+// record records an action on the resource with id. A nil before or after
+// is left out of the event.
+func (r recorder) record(ctx context.Context, action string, orgID, id influxdb.ID, before, after interface{}) {
+	e := &influxdb.AuditEvent{
+		Action:       action,
+		ResourceType: r.resourceType,
+		ResourceID:   id,
+		OrgID:        orgID,
+	}
+	if a, err := icontext.GetAuthorizer(ctx); err == nil {
+		e.SetAuthorizer(a)
+	}
+
+	var err error
+	if e.Before, err = marshal(before); err != nil {
+		r.log.Error("Failed to encode audited resource", zap.Error(err))
+		return
+	}
+	if e.After, err = marshal(after); err != nil {
+		r.log.Error("Failed to encode audited resource", zap.Error(err))
+		return
+	}
+
+	if err := r.audit.RecordAuditEvent(ctx, e); err != nil {
+		r.log.Error("Failed to record audit event",
+			zap.String("action", action),
+			zap.Stringer("resource_id", id),
+			zap.Error(err))
+	}
+}
+
+func marshal(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.AuthorizationService = (*AuthorizationService)(nil)
+
+// AuthorizationService wraps a influxdb.AuthorizationService and records the
+// changes made to authorizations. Tokens are never recorded.
+type AuthorizationService struct {
+	influxdb.AuthorizationService
+	r recorder
+}
+
+// NewAuthorizationService constructs an instance of an auditing authorization service.
+func NewAuthorizationService(log *zap.Logger, s influxdb.AuthorizationService, as influxdb.AuditService) *AuthorizationService {
+	return &AuthorizationService{
+		AuthorizationService: s,
+		r:                    newRecorder(log, as, influxdb.AuthorizationsResourceType),
+	}
+}
+
+// redact returns a copy of the authorization without its token.
+func redact(a *influxdb.Authorization) *influxdb.Authorization {
+	c := *a
+	c.Token = ""
+	return &c
+}
+
+// CreateAuthorization creates the authorization and records its creation.
+func (s *AuthorizationService) CreateAuthorization(ctx context.Context, a *influxdb.Authorization) error {
+	if err := s.AuthorizationService.CreateAuthorization(ctx, a); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionCreate, a.OrgID, a.ID, nil, redact(a))
+	return nil
+}
+
+// UpdateAuthorization updates the authorization and records the authorization
+// before and after the update. Updates that only record the use of the
+// authorization are not audited.
+func (s *AuthorizationService) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+	if upd.Status == nil && upd.Description == nil && upd.ExpiresAt == nil {
+		return s.AuthorizationService.UpdateAuthorization(ctx, id, upd)
+	}
+
+	before, err := s.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.AuthorizationService.UpdateAuthorization(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, a.OrgID, id, redact(before), redact(a))
+	return a, nil
+}
+
+// RotateAuthorization rotates the token of the authorization and records the rotation.
+func (s *AuthorizationService) RotateAuthorization(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	before, err := s.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.AuthorizationService.RotateAuthorization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, a.OrgID, id, redact(before), redact(a))
+	return a, nil
+}
+
+// DeleteAuthorization deletes the authorization and records the deleted authorization.
+func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id influxdb.ID) error {
+	before, err := s.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.AuthorizationService.DeleteAuthorization(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionDelete, before.OrgID, id, redact(before), nil)
+	return nil
+}
@@ -0,0 +1,78 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+func TestAuthorizationService_RedactsTokens(t *testing.T) {
+	id := influxdbtesting.MustIDBase16("020f755c3c082000")
+	as := mock.NewAuthorizationService()
+	as.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+		return &influxdb.Authorization{ID: id, OrgID: id, UserID: id, Token: "old"}, nil
+	}
+	as.RotateAuthorizationFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+		return &influxdb.Authorization{ID: id, OrgID: id, UserID: id, Token: "new"}, nil
+	}
+
+	var events []*influxdb.AuditEvent
+	s := audit.NewAuthorizationService(zap.NewNop(), as, newEventRecorder(&events))
+
+	a, err := s.RotateAuthorization(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Token != "new" {
+		t.Errorf("expected the rotated token to be returned, got %q", a.Token)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(events))
+	}
+	for _, raw := range [][]byte{events[0].Before, events[0].After} {
+		var recorded influxdb.Authorization
+		if err := json.Unmarshal(raw, &recorded); err != nil {
+			t.Fatal(err)
+		}
+		if recorded.Token != "" {
+			t.Errorf("expected token to be redacted, got %q", recorded.Token)
+		}
+	}
+}
+
+func TestAuthorizationService_DoesNotAuditUse(t *testing.T) {
+	id := influxdbtesting.MustIDBase16("020f755c3c082000")
+	as := mock.NewAuthorizationService()
+	as.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+		return &influxdb.Authorization{ID: id, OrgID: id, UserID: id}, nil
+	}
+	as.UpdateAuthorizationFn = func(ctx context.Context, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+		return &influxdb.Authorization{ID: id, OrgID: id, UserID: id}, nil
+	}
+
+	var events []*influxdb.AuditEvent
+	s := audit.NewAuthorizationService(zap.NewNop(), as, newEventRecorder(&events))
+
+	now := time.Now()
+	if _, err := s.UpdateAuthorization(context.Background(), id, &influxdb.AuthorizationUpdate{LastUsedAt: &now}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected use of authorization not to be audited, got %d events", len(events))
+	}
+
+	status := influxdb.Inactive
+	if _, err := s.UpdateAuthorization(context.Background(), id, &influxdb.AuthorizationUpdate{Status: &status}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Errorf("expected status change to be audited, got %d events", len(events))
+	}
+}
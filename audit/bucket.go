@@ -0,0 +1,61 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.BucketService = (*BucketService)(nil)
+
+// BucketService wraps a influxdb.BucketService and records the changes made
+// to buckets.
+type BucketService struct {
+	influxdb.BucketService
+	r recorder
+}
+
+// NewBucketService constructs an instance of an auditing bucket service.
+func NewBucketService(log *zap.Logger, s influxdb.BucketService, as influxdb.AuditService) *BucketService {
+	return &BucketService{
+		BucketService: s,
+		r:             newRecorder(log, as, influxdb.BucketsResourceType),
+	}
+}
+
+// CreateBucket creates the bucket and records its creation.
+func (s *BucketService) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
+	if err := s.BucketService.CreateBucket(ctx, b); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionCreate, b.OrganizationID, b.ID, nil, b)
+	return nil
+}
+
+// UpdateBucket updates the bucket and records the bucket before and after the update.
+func (s *BucketService) UpdateBucket(ctx context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+	before, err := s.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.BucketService.UpdateBucket(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, b.OrganizationID, id, before, b)
+	return b, nil
+}
+
+// DeleteBucket deletes the bucket and records the deleted bucket.
+func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error {
+	before, err := s.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.BucketService.DeleteBucket(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionDelete, before.OrganizationID, id, before, nil)
+	return nil
+}
@@ -0,0 +1,137 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+func TestBucketService(t *testing.T) {
+	orgID := influxdbtesting.MustIDBase16("020f755c3c082000")
+	bucketID := influxdbtesting.MustIDBase16("020f755c3c082001")
+	userID := influxdbtesting.MustIDBase16("020f755c3c082002")
+	authID := influxdbtesting.MustIDBase16("020f755c3c082003")
+
+	existing := &influxdb.Bucket{ID: bucketID, OrganizationID: orgID, Name: "b1"}
+	renamed := &influxdb.Bucket{ID: bucketID, OrganizationID: orgID, Name: "b2"}
+
+	tests := []struct {
+		name   string
+		err    error
+		fn     func(context.Context, influxdb.BucketService) error
+		events []*influxdb.AuditEvent
+	}{
+		{
+			name: "create is recorded",
+			fn: func(ctx context.Context, s influxdb.BucketService) error {
+				return s.CreateBucket(ctx, &influxdb.Bucket{OrganizationID: orgID, Name: "b1"})
+			},
+			events: []*influxdb.AuditEvent{
+				{
+					Action:         influxdb.AuditActionCreate,
+					ResourceType:   influxdb.BucketsResourceType,
+					ResourceID:     bucketID,
+					OrgID:          orgID,
+					UserID:         userID,
+					AuthorizerID:   authID,
+					AuthorizerKind: influxdb.AuthorizationKind,
+					After:          mustMarshal(t, existing),
+				},
+			},
+		},
+		{
+			name: "update records the bucket before and after",
+			fn: func(ctx context.Context, s influxdb.BucketService) error {
+				name := "b2"
+				_, err := s.UpdateBucket(ctx, bucketID, influxdb.BucketUpdate{Name: &name})
+				return err
+			},
+			events: []*influxdb.AuditEvent{
+				{
+					Action:         influxdb.AuditActionUpdate,
+					ResourceType:   influxdb.BucketsResourceType,
+					ResourceID:     bucketID,
+					OrgID:          orgID,
+					UserID:         userID,
+					AuthorizerID:   authID,
+					AuthorizerKind: influxdb.AuthorizationKind,
+					Before:         mustMarshal(t, existing),
+					After:          mustMarshal(t, renamed),
+				},
+			},
+		},
+		{
+			name: "delete records the deleted bucket",
+			fn: func(ctx context.Context, s influxdb.BucketService) error {
+				return s.DeleteBucket(ctx, bucketID)
+			},
+			events: []*influxdb.AuditEvent{
+				{
+					Action:         influxdb.AuditActionDelete,
+					ResourceType:   influxdb.BucketsResourceType,
+					ResourceID:     bucketID,
+					OrgID:          orgID,
+					UserID:         userID,
+					AuthorizerID:   authID,
+					AuthorizerKind: influxdb.AuthorizationKind,
+					Before:         mustMarshal(t, existing),
+				},
+			},
+		},
+		{
+			name: "failed changes are not recorded",
+			err:  errors.New("bucket is locked"),
+			fn: func(ctx context.Context, s influxdb.BucketService) error {
+				return s.DeleteBucket(ctx, bucketID)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := mock.NewBucketService()
+			bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				return existing, nil
+			}
+			bs.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+				b.ID = bucketID
+				return tt.err
+			}
+			bs.UpdateBucketFn = func(ctx context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+				return renamed, tt.err
+			}
+			bs.DeleteBucketFn = func(ctx context.Context, id influxdb.ID) error {
+				return tt.err
+			}
+
+			var events []*influxdb.AuditEvent
+			s := audit.NewBucketService(zap.NewNop(), bs, newEventRecorder(&events))
+
+			ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{ID: authID, UserID: userID})
+			if err := tt.fn(ctx, s); err != tt.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(events, tt.events); diff != "" {
+				t.Errorf("audit events are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
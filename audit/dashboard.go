@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.DashboardService = (*DashboardService)(nil)
+
+// DashboardService wraps a influxdb.DashboardService and records the changes
+// made to dashboards. Changes to the cells of a dashboard are recorded as an
+// update of the dashboard.
+type DashboardService struct {
+	influxdb.DashboardService
+	r recorder
+}
+
+// NewDashboardService constructs an instance of an auditing dashboard service.
+func NewDashboardService(log *zap.Logger, s influxdb.DashboardService, as influxdb.AuditService) *DashboardService {
+	return &DashboardService{
+		DashboardService: s,
+		r:                newRecorder(log, as, influxdb.DashboardsResourceType),
+	}
+}
+
+// CreateDashboard creates the dashboard and records its creation.
+func (s *DashboardService) CreateDashboard(ctx context.Context, d *influxdb.Dashboard) error {
+	if err := s.DashboardService.CreateDashboard(ctx, d); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionCreate, d.OrganizationID, d.ID, nil, d)
+	return nil
+}
+
+// UpdateDashboard updates the dashboard and records the dashboard before and after the update.
+func (s *DashboardService) UpdateDashboard(ctx context.Context, id influxdb.ID, upd influxdb.DashboardUpdate) (*influxdb.Dashboard, error) {
+	var d *influxdb.Dashboard
+	err := s.update(ctx, id, func() (err error) {
+		d, err = s.DashboardService.UpdateDashboard(ctx, id, upd)
+		return err
+	})
+	return d, err
+}
+
+// AddDashboardCell adds the cell and records the update of the dashboard.
+func (s *DashboardService) AddDashboardCell(ctx context.Context, id influxdb.ID, c *influxdb.Cell, opts influxdb.AddDashboardCellOptions) error {
+	return s.update(ctx, id, func() error {
+		return s.DashboardService.AddDashboardCell(ctx, id, c, opts)
+	})
+}
+
+// RemoveDashboardCell removes the cell and records the update of the dashboard.
+func (s *DashboardService) RemoveDashboardCell(ctx context.Context, dashboardID, cellID influxdb.ID) error {
+	return s.update(ctx, dashboardID, func() error {
+		return s.DashboardService.RemoveDashboardCell(ctx, dashboardID, cellID)
+	})
+}
+
+// UpdateDashboardCell updates the cell and records the update of the dashboard.
+func (s *DashboardService) UpdateDashboardCell(ctx context.Context, dashboardID, cellID influxdb.ID, upd influxdb.CellUpdate) (*influxdb.Cell, error) {
+	var c *influxdb.Cell
+	err := s.update(ctx, dashboardID, func() (err error) {
+		c, err = s.DashboardService.UpdateDashboardCell(ctx, dashboardID, cellID, upd)
+		return err
+	})
+	return c, err
+}
+
+// UpdateDashboardCellView updates the view of the cell and records the update of the dashboard.
+func (s *DashboardService) UpdateDashboardCellView(ctx context.Context, dashboardID, cellID influxdb.ID, upd influxdb.ViewUpdate) (*influxdb.View, error) {
+	var v *influxdb.View
+	err := s.update(ctx, dashboardID, func() (err error) {
+		v, err = s.DashboardService.UpdateDashboardCellView(ctx, dashboardID, cellID, upd)
+		return err
+	})
+	return v, err
+}
+
+// ReplaceDashboardCells replaces the cells and records the update of the dashboard.
+func (s *DashboardService) ReplaceDashboardCells(ctx context.Context, id influxdb.ID, cs []*influxdb.Cell) error {
+	return s.update(ctx, id, func() error {
+		return s.DashboardService.ReplaceDashboardCells(ctx, id, cs)
+	})
+}
+
+// DeleteDashboard deletes the dashboard and records the deleted dashboard.
+func (s *DashboardService) DeleteDashboard(ctx context.Context, id influxdb.ID) error {
+	before, err := s.DashboardService.FindDashboardByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.DashboardService.DeleteDashboard(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionDelete, before.OrganizationID, id, before, nil)
+	return nil
+}
+
+// update runs fn and records the dashboard with id before and after it.
+func (s *DashboardService) update(ctx context.Context, id influxdb.ID, fn func() error) error {
+	before, err := s.DashboardService.FindDashboardByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	after, err := s.DashboardService.FindDashboardByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, after.OrganizationID, id, before, after)
+	return nil
+}
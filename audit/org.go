@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.OrganizationService = (*OrgService)(nil)
+
+// OrgService wraps a influxdb.OrganizationService and records the changes
+// made to organizations.
+type OrgService struct {
+	influxdb.OrganizationService
+	r recorder
+}
+
+// NewOrgService constructs an instance of an auditing organization service.
+func NewOrgService(log *zap.Logger, s influxdb.OrganizationService, as influxdb.AuditService) *OrgService {
+	return &OrgService{
+		OrganizationService: s,
+		r:                   newRecorder(log, as, influxdb.OrgsResourceType),
+	}
+}
+
+// CreateOrganization creates the organization and records its creation.
+func (s *OrgService) CreateOrganization(ctx context.Context, o *influxdb.Organization) error {
+	if err := s.OrganizationService.CreateOrganization(ctx, o); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionCreate, o.ID, o.ID, nil, o)
+	return nil
+}
+
+// UpdateOrganization updates the organization and records the organization
+// before and after the update.
+func (s *OrgService) UpdateOrganization(ctx context.Context, id influxdb.ID, upd influxdb.OrganizationUpdate) (*influxdb.Organization, error) {
+	before, err := s.OrganizationService.FindOrganizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	o, err := s.OrganizationService.UpdateOrganization(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, id, id, before, o)
+	return o, nil
+}
+
+// DeleteOrganization deletes the organization and records the deleted organization.
+func (s *OrgService) DeleteOrganization(ctx context.Context, id influxdb.ID) error {
+	before, err := s.OrganizationService.FindOrganizationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.OrganizationService.DeleteOrganization(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionDelete, id, id, before, nil)
+	return nil
+}
@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	// Measurement is the measurement of the points written for audit events.
+	Measurement = "audit"
+
+	actionTag         = "action"
+	resourceTypeTag   = "resourceType"
+	authorizerKindTag = "authorizerKind"
+
+	idField           = "id"
+	resourceIDField   = "resourceID"
+	orgIDField        = "orgID"
+	userIDField       = "userID"
+	authorizerIDField = "authorizerID"
+	beforeField       = "before"
+	afterField        = "after"
+)
+
+// PointsWriter describes the ability to write points into a storage engine.
+// It is a copy of storage.PointsWriter to avoid depending on storage.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+var _ influxdb.AuditService = (*PointsService)(nil)
+
+// PointsService wraps a influxdb.AuditService and also writes every recorded
+// event as a point to a bucket, so that the audit trail can be queried and
+// retained like any other data. The bucket is looked up on every write, so it
+// may be created after the service.
+type PointsService struct {
+	influxdb.AuditService
+	log      *zap.Logger
+	buckets  influxdb.BucketService
+	writer   PointsWriter
+	bucketID influxdb.ID
+}
+
+// NewPointsService constructs an audit service that writes the events to
+// the bucket with bucketID.
+func NewPointsService(log *zap.Logger, as influxdb.AuditService, bs influxdb.BucketService, pw PointsWriter, bucketID influxdb.ID) *PointsService {
+	return &PointsService{
+		AuditService: as,
+		log:          log,
+		buckets:      bs,
+		writer:       pw,
+		bucketID:     bucketID,
+	}
+}
+
+// RecordAuditEvent records the event and writes it to the audit bucket.
+// Failing to write the event is logged and not returned, since the event
+// has been recorded.
+func (s *PointsService) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	if err := s.AuditService.RecordAuditEvent(ctx, e); err != nil {
+		return err
+	}
+	if err := s.writeEvent(ctx, e); err != nil {
+		s.log.Error("Failed to write audit event to bucket",
+			zap.Stringer("bucket_id", s.bucketID),
+			zap.Error(err))
+	}
+	return nil
+}
+
+func (s *PointsService) writeEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	b, err := s.buckets.FindBucketByID(ctx, s.bucketID)
+	if err != nil {
+		return err
+	}
+
+	pt, err := NewPoint(e)
+	if err != nil {
+		return err
+	}
+
+	exploded, err := tsdb.ExplodePoints(b.OrganizationID, b.ID, []models.Point{pt})
+	if err != nil {
+		return err
+	}
+	return s.writer.WritePoints(ctx, exploded)
+}
+
+// NewPoint returns the event as a point. The action, resource type and kind
+// of authorizer are tags and the identifiers and resources are string fields.
+func NewPoint(e *influxdb.AuditEvent) (models.Point, error) {
+	tags := map[string]string{
+		actionTag:       e.Action,
+		resourceTypeTag: string(e.ResourceType),
+	}
+	if e.AuthorizerKind != "" {
+		tags[authorizerKindTag] = e.AuthorizerKind
+	}
+
+	fields := map[string]interface{}{
+		idField: e.ID.String(),
+	}
+	ids := map[string]influxdb.ID{
+		resourceIDField:   e.ResourceID,
+		orgIDField:        e.OrgID,
+		userIDField:       e.UserID,
+		authorizerIDField: e.AuthorizerID,
+	}
+	for k, id := range ids {
+		if id.Valid() {
+			fields[k] = id.String()
+		}
+	}
+	if len(e.Before) > 0 {
+		fields[beforeField] = string(e.Before)
+	}
+	if len(e.After) > 0 {
+		fields[afterField] = string(e.After)
+	}
+
+	return models.NewPoint(Measurement, models.NewTags(tags), fields, e.Time)
+}
@@ -0,0 +1,73 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+func TestNewPoint(t *testing.T) {
+	e := &influxdb.AuditEvent{
+		ID:             influxdbtesting.MustIDBase16("020f755c3c082000"),
+		Time:           time.Unix(0, 1000),
+		Action:         influxdb.AuditActionUpdate,
+		ResourceType:   influxdb.BucketsResourceType,
+		ResourceID:     influxdbtesting.MustIDBase16("020f755c3c082001"),
+		UserID:         influxdbtesting.MustIDBase16("020f755c3c082002"),
+		AuthorizerKind: influxdb.SessionAuthorizionKind,
+		After:          json.RawMessage(`{"name":"b1"}`),
+	}
+
+	pt, err := audit.NewPoint(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `audit,action=update,authorizerKind=session,resourceType=buckets after="{\"name\":\"b1\"}",id="020f755c3c082000",resourceID="020f755c3c082001",userID="020f755c3c082002" 1000`
+	if got := pt.String(); got != want {
+		t.Errorf("unexpected point:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestPointsService_RecordAuditEvent(t *testing.T) {
+	orgID := influxdbtesting.MustIDBase16("020f755c3c082000")
+	bucketID := influxdbtesting.MustIDBase16("020f755c3c082001")
+
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: id, OrganizationID: orgID}, nil
+	}
+	pw := &mock.PointsWriter{}
+
+	var events []*influxdb.AuditEvent
+	s := audit.NewPointsService(zap.NewNop(), newEventRecorder(&events), bs, pw, bucketID)
+
+	e := &influxdb.AuditEvent{
+		ID:           influxdbtesting.MustIDBase16("020f755c3c082002"),
+		Time:         time.Unix(1, 0),
+		Action:       influxdb.AuditActionDelete,
+		ResourceType: influxdb.UsersResourceType,
+	}
+	if err := s.RecordAuditEvent(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected event to be recorded, got %d events", len(events))
+	}
+	if len(pw.Points) != 1 {
+		t.Fatalf("expected 1 point to be written, got %d", len(pw.Points))
+	}
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	if got := pw.Points[0].Name(); string(got) != string(name[:]) {
+		t.Errorf("expected point to be written to bucket %s of org %s", bucketID, orgID)
+	}
+}
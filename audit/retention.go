@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultRetentionCheckInterval is how often expired events are deleted.
+const DefaultRetentionCheckInterval = time.Hour
+
+// EventDeleter deletes the audit events recorded before a time.
+type EventDeleter interface {
+	DeleteAuditEventsBefore(ctx context.Context, t time.Time) (int, error)
+}
+
+// RetentionEnforcer deletes the audit events older than the retention period
+// on an interval, so that the audit trail does not grow without bound.
+type RetentionEnforcer struct {
+	log       *zap.Logger
+	deleter   EventDeleter
+	retention time.Duration
+	interval  time.Duration
+
+	now     func() time.Time
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewRetentionEnforcer returns a RetentionEnforcer that deletes the events
+// older than retention every interval.
+func NewRetentionEnforcer(log *zap.Logger, d EventDeleter, retention, interval time.Duration) *RetentionEnforcer {
+	return &RetentionEnforcer{
+		log:       log,
+		deleter:   d,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Open deletes the expired events and starts deleting them on the interval.
+func (r *RetentionEnforcer) Open() {
+	r.closing = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			r.run(context.Background())
+			select {
+			case <-r.closing:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops deleting expired events.
+func (r *RetentionEnforcer) Close() error {
+	if r.closing != nil {
+		close(r.closing)
+		r.wg.Wait()
+		r.closing = nil
+	}
+	return nil
+}
+
+// run deletes the events older than the retention period.
+func (r *RetentionEnforcer) run(ctx context.Context) {
+	before := r.now().Add(-r.retention)
+	n, err := r.deleter.DeleteAuditEventsBefore(ctx, before)
+	if err != nil {
+		r.log.Error("Failed to delete expired audit events", zap.Int("deleted", n), zap.Error(err))
+		return
+	}
+	if n > 0 {
+		r.log.Info("Deleted expired audit events", zap.Int("deleted", n), zap.Time("before", before))
+	}
+}
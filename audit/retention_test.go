@@ -0,0 +1,46 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/audit"
+	"go.uber.org/zap"
+)
+
+type deleterFunc func(ctx context.Context, t time.Time) (int, error)
+
+func (f deleterFunc) DeleteAuditEventsBefore(ctx context.Context, t time.Time) (int, error) {
+	return f(ctx, t)
+}
+
+func TestRetentionEnforcer(t *testing.T) {
+	deleted := make(chan time.Time, 1)
+	d := deleterFunc(func(ctx context.Context, t time.Time) (int, error) {
+		select {
+		case deleted <- t:
+		default:
+		}
+		return 1, nil
+	})
+
+	r := audit.NewRetentionEnforcer(zap.NewNop(), d, 24*time.Hour, time.Hour)
+	start := time.Now()
+	r.Open()
+	defer r.Close()
+
+	// Expired events are deleted when the enforcer opens.
+	select {
+	case before := <-deleted:
+		if want := start.Add(-24 * time.Hour); before.Before(want) || before.After(time.Now().Add(-24*time.Hour)) {
+			t.Errorf("expected events before %s to be deleted, got %s", want, before)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected expired events to be deleted")
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
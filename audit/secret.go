@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.SecretService = (*SecretService)(nil)
+
+// SecretService wraps a influxdb.SecretService and records the changes made
+// to secrets. The secrets of an organization are audited as a single
+// resource with the ID of the organization, and only the keys of the
+// secrets that changed are recorded, never their values.
+type SecretService struct {
+	influxdb.SecretService
+	r recorder
+}
+
+// NewSecretService constructs an instance of an auditing secret service.
+func NewSecretService(log *zap.Logger, s influxdb.SecretService, as influxdb.AuditService) *SecretService {
+	return &SecretService{
+		SecretService: s,
+		r:             newRecorder(log, as, influxdb.SecretsResourceType),
+	}
+}
+
+type secretKeys struct {
+	Keys []string `json:"keys"`
+}
+
+func keys(m map[string]string) *secretKeys {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return &secretKeys{Keys: ks}
+}
+
+// PutSecret stores the secret and records the key that was set.
+func (s *SecretService) PutSecret(ctx context.Context, orgID influxdb.ID, k string, v string) error {
+	if err := s.SecretService.PutSecret(ctx, orgID, k, v); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, orgID, orgID, nil, &secretKeys{Keys: []string{k}})
+	return nil
+}
+
+// PutSecrets replaces the secrets of the organization and records the keys
+// before and after.
+func (s *SecretService) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	before, err := s.SecretService.GetSecretKeys(ctx, orgID)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	if err := s.SecretService.PutSecrets(ctx, orgID, m); err != nil {
+		return err
+	}
+	sort.Strings(before)
+	s.r.record(ctx, influxdb.AuditActionUpdate, orgID, orgID, &secretKeys{Keys: before}, keys(m))
+	return nil
+}
+
+// PatchSecrets sets the secrets of the organization and records the keys that were set.
+func (s *SecretService) PatchSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	if err := s.SecretService.PatchSecrets(ctx, orgID, m); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, orgID, orgID, nil, keys(m))
+	return nil
+}
+
+// DeleteSecret deletes the secrets and records the keys that were deleted.
+func (s *SecretService) DeleteSecret(ctx context.Context, orgID influxdb.ID, ks ...string) error {
+	if err := s.SecretService.DeleteSecret(ctx, orgID, ks...); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionDelete, orgID, orgID, &secretKeys{Keys: ks}, nil)
+	return nil
+}
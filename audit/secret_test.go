@@ -0,0 +1,48 @@
+package audit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+func TestSecretService_RecordsKeysOnly(t *testing.T) {
+	orgID := influxdbtesting.MustIDBase16("020f755c3c082000")
+	ss := mock.NewSecretService()
+	ss.GetSecretKeysFn = func(ctx context.Context, orgID influxdb.ID) ([]string, error) {
+		return []string{"b", "a"}, nil
+	}
+	ss.PutSecretsFn = func(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+		return nil
+	}
+
+	var events []*influxdb.AuditEvent
+	s := audit.NewSecretService(zap.NewNop(), ss, newEventRecorder(&events))
+
+	if err := s.PutSecrets(context.Background(), orgID, map[string]string{"c": "hunter2", "a": "swordfish"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(events))
+	}
+
+	e := events[0]
+	if e.ResourceID != orgID || e.OrgID != orgID {
+		t.Errorf("expected secrets to be audited as organization %s, got resource %s in %s", orgID, e.ResourceID, e.OrgID)
+	}
+	if got, want := string(e.Before), `{"keys":["a","b"]}`; got != want {
+		t.Errorf("unexpected keys before: got %s, want %s", got, want)
+	}
+	if got, want := string(e.After), `{"keys":["a","c"]}`; got != want {
+		t.Errorf("unexpected keys after: got %s, want %s", got, want)
+	}
+	if strings.Contains(string(e.After), "hunter2") {
+		t.Errorf("secret value was recorded: %s", e.After)
+	}
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.ShareLinkService = (*ShareLinkService)(nil)
+
+// ShareLinkService wraps a influxdb.ShareLinkService and records the
+// authorizations minted and revoked by share links. Share links are recorded
+// as the authorizations they mint, without their keys.
+type ShareLinkService struct {
+	influxdb.ShareLinkService
+	r recorder
+}
+
+// NewShareLinkService constructs an instance of an auditing share link service.
+func NewShareLinkService(log *zap.Logger, s influxdb.ShareLinkService, as influxdb.AuditService) *ShareLinkService {
+	return &ShareLinkService{
+		ShareLinkService: s,
+		r:                newRecorder(log, as, influxdb.AuthorizationsResourceType),
+	}
+}
+
+// redactShareLink returns a copy of the share link without its key.
+func redactShareLink(l *influxdb.ShareLink) *influxdb.ShareLink {
+	c := *l
+	c.Key = ""
+	return &c
+}
+
+// CreateShareLink creates the share link and records the creation of its authorization.
+func (s *ShareLinkService) CreateShareLink(ctx context.Context, l *influxdb.ShareLink) error {
+	if err := s.ShareLinkService.CreateShareLink(ctx, l); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionCreate, l.OrgID, l.AuthorizationID, nil, redactShareLink(l))
+	return nil
+}
+
+// DeleteShareLink deletes the share link and records the deletion of its authorization.
+func (s *ShareLinkService) DeleteShareLink(ctx context.Context, id influxdb.ID) error {
+	before, err := s.ShareLinkService.FindShareLinkByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ShareLinkService.DeleteShareLink(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionDelete, before.OrgID, before.AuthorizationID, redactShareLink(before), nil)
+	return nil
+}
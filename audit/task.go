@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.TaskService = (*TaskService)(nil)
+
+// TaskService wraps a influxdb.TaskService and records the changes made to tasks.
+// Runs are not audited.
+type TaskService struct {
+	influxdb.TaskService
+	r recorder
+}
+
+// NewTaskService constructs an instance of an auditing task service.
+func NewTaskService(log *zap.Logger, s influxdb.TaskService, as influxdb.AuditService) *TaskService {
+	return &TaskService{
+		TaskService: s,
+		r:           newRecorder(log, as, influxdb.TasksResourceType),
+	}
+}
+
+// CreateTask creates the task and records its creation.
+func (s *TaskService) CreateTask(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+	t, err := s.TaskService.CreateTask(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditActionCreate, t.OrganizationID, t.ID, nil, t)
+	return t, nil
+}
+
+// UpdateTask updates the task and records the task before and after the update.
+func (s *TaskService) UpdateTask(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+	before, err := s.TaskService.FindTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.TaskService.UpdateTask(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, t.OrganizationID, id, before, t)
+	return t, nil
+}
+
+// DeleteTask deletes the task and records the deleted task.
+func (s *TaskService) DeleteTask(ctx context.Context, id influxdb.ID) error {
+	before, err := s.TaskService.FindTaskByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.TaskService.DeleteTask(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionDelete, before.OrganizationID, id, before, nil)
+	return nil
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.UserService = (*UserService)(nil)
+
+// UserService wraps a influxdb.UserService and records the changes made to users.
+type UserService struct {
+	influxdb.UserService
+	r recorder
+}
+
+// NewUserService constructs an instance of an auditing user service.
+func NewUserService(log *zap.Logger, s influxdb.UserService, as influxdb.AuditService) *UserService {
+	return &UserService{
+		UserService: s,
+		r:           newRecorder(log, as, influxdb.UsersResourceType),
+	}
+}
+
+// CreateUser creates the user and records its creation.
+func (s *UserService) CreateUser(ctx context.Context, u *influxdb.User) error {
+	if err := s.UserService.CreateUser(ctx, u); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionCreate, 0, u.ID, nil, u)
+	return nil
+}
+
+// UpdateUser updates the user and records the user before and after the update.
+func (s *UserService) UpdateUser(ctx context.Context, id influxdb.ID, upd influxdb.UserUpdate) (*influxdb.User, error) {
+	before, err := s.UserService.FindUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.UserService.UpdateUser(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.r.record(ctx, influxdb.AuditActionUpdate, 0, id, before, u)
+	return u, nil
+}
+
+// DeleteUser deletes the user and records the deleted user.
+func (s *UserService) DeleteUser(ctx context.Context, id influxdb.ID) error {
+	before, err := s.UserService.FindUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.UserService.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	s.r.record(ctx, influxdb.AuditActionDelete, 0, id, before, nil)
+	return nil
+}
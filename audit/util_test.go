@@ -0,0 +1,19 @@
+package audit_test
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+// newEventRecorder returns an audit service that appends the recorded
+// events to events.
+func newEventRecorder(events *[]*influxdb.AuditEvent) *mock.AuditService {
+	as := mock.NewAuditService()
+	as.RecordAuditEventF = func(ctx context.Context, e *influxdb.AuditEvent) error {
+		*events = append(*events, e)
+		return nil
+	}
+	return as
+}
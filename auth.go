@@ -3,11 +3,15 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // AuthorizationKind is returned by (*Authorization).Kind().
 const AuthorizationKind = "authorization"
 
+// ErrAuthorizationExpired is the error msg for an expired authorization.
+const ErrAuthorizationExpired = "authorization has expired"
+
 var (
 	// ErrUnableToCreateToken sanitized error message for all errors when a user cannot create a token
 	ErrUnableToCreateToken = &Error{
@@ -25,12 +29,36 @@ type Authorization struct {
 	OrgID       ID           `json:"orgID"`
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions"`
+
+	// ExpiresAt is the time after which the token is rejected. Tokens
+	// without an expiry never expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// LastUsedAt is when the token last authenticated a request.
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	// CreatedBy is the user that created the authorization.
+	CreatedBy ID `json:"createdBy,omitempty"`
 }
 
 // AuthorizationUpdate is the authorization update request.
 type AuthorizationUpdate struct {
-	Status      *Status `json:"status,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Status      *Status    `json:"status,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+
+	// LastUsedAt is maintained by the server and can not be set by clients.
+	LastUsedAt *time.Time `json:"-"`
+}
+
+// Valid returns an error if the update sets an expiry that has already
+// passed.
+func (u *AuthorizationUpdate) Valid() error {
+	if u.ExpiresAt != nil && u.ExpiresAt.Before(time.Now()) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "expiresAt must be in the future",
+		}
+	}
+	return nil
 }
 
 // Valid ensures that the authorization is valid.
@@ -57,14 +85,26 @@ func (a *Authorization) Allowed(p Permission) bool {
 	return PermissionAllowed(p, a.Permissions)
 }
 
-// IsActive is a stub for idpe.
+// IsActive returns true if the authorization is active and unexpired.
 func IsActive(a *Authorization) bool {
 	return a.IsActive()
 }
 
-// IsActive returns true if the authorization active.
+// IsActive returns true if the authorization is active and unexpired.
 func (a *Authorization) IsActive() bool {
-	return a.Status == Active
+	return a.Status == Active && a.Expired() == nil
+}
+
+// Expired returns an error if the authorization is expired.
+func (a *Authorization) Expired() error {
+	if a.ExpiresAt != nil && time.Now().After(*a.ExpiresAt) {
+		return &Error{
+			Code: EForbidden,
+			Msg:  ErrAuthorizationExpired,
+		}
+	}
+
+	return nil
 }
 
 // GetUserID returns the user id.
@@ -86,6 +126,7 @@ const (
 	OpCreateAuthorization      = "CreateAuthorization"
 	OpUpdateAuthorization      = "UpdateAuthorization"
 	OpDeleteAuthorization      = "DeleteAuthorization"
+	OpRotateAuthorization      = "RotateAuthorization"
 )
 
 // AuthorizationService represents a service for managing authorization data.
@@ -103,9 +144,13 @@ type AuthorizationService interface {
 	// Creates a new authorization and sets a.Token and a.UserID with the new identifier.
 	CreateAuthorization(ctx context.Context, a *Authorization) error
 
-	// UpdateAuthorization updates the status and description if available.
+	// UpdateAuthorization updates the status, description and expiry if available.
 	UpdateAuthorization(ctx context.Context, id ID, udp *AuthorizationUpdate) (*Authorization, error)
 
+	// RotateAuthorization replaces the token of an authorization with a new
+	// one, keeping its ID and permissions. The old token stops working.
+	RotateAuthorization(ctx context.Context, id ID) (*Authorization, error)
+
 	// Removes a authorization by token.
 	DeleteAuthorization(ctx context.Context, id ID) error
 }
@@ -0,0 +1,83 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// ActiveQueryService wraps a query.ActiveQueryService and authorizes actions
+// against it appropriately. Operators may see and cancel any query; other
+// users only their own.
+type ActiveQueryService struct {
+	s query.ActiveQueryService
+}
+
+// NewActiveQueryService constructs an instance of an authorizing active query service.
+func NewActiveQueryService(s query.ActiveQueryService) *ActiveQueryService {
+	return &ActiveQueryService{
+		s: s,
+	}
+}
+
+// FindActiveQueries restricts the filter to the queries of the user of the
+// authorizer on context, unless they are an operator.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	userID, ok, err := queryUserFilter(ctx, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []*query.ActiveQuery{}, nil
+	}
+	filter.UserID = userID
+
+	return s.s.FindActiveQueries(ctx, filter)
+}
+
+// queryUserFilter restricts a filter of the queries of userID to the queries
+// the authorizer on context may see: those of every user for operators, and
+// their own for other users. It reports false if the filter matches none.
+func queryUserFilter(ctx context.Context, userID *influxdb.ID) (*influxdb.ID, bool, error) {
+	a, err := influxdbcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := IsOperator(ctx); err == nil {
+		return userID, true, nil
+	}
+
+	id := a.GetUserID()
+	if userID != nil && *userID != id {
+		return nil, false, nil
+	}
+	return &id, true, nil
+}
+
+// CancelActiveQuery checks to see if the query belongs to the user of the
+// authorizer on context, or if they are an operator.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id influxdb.ID) error {
+	a, err := influxdbcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := IsOperator(ctx); err != nil {
+		queries, ferr := s.s.FindActiveQueries(ctx, query.ActiveQueryFilter{})
+		if ferr != nil {
+			return ferr
+		}
+		for _, q := range queries {
+			if q.ID == id && q.UserID != a.GetUserID() {
+				return err
+			}
+		}
+	}
+
+	return s.s.CancelActiveQuery(ctx, id)
+}
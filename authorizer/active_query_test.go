@@ -0,0 +1,140 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+var operatorPermission = influxdb.Permission{
+	Action: influxdb.WriteAction,
+	Resource: influxdb.Resource{
+		Type: influxdb.OrgsResourceType,
+	},
+}
+
+func activeQueryService(canceled *influxdb.ID) *mock.ActiveQueryService {
+	queries := []*query.ActiveQuery{
+		{ID: 1, OrganizationID: 10, UserID: 2},
+		{ID: 3, OrganizationID: 10, UserID: 4},
+	}
+	return &mock.ActiveQueryService{
+		FindActiveQueriesF: func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+			var qs []*query.ActiveQuery
+			for _, q := range queries {
+				if filter.Match(q) {
+					qs = append(qs, q)
+				}
+			}
+			return qs, nil
+		},
+		CancelActiveQueryF: func(ctx context.Context, id influxdb.ID) error {
+			*canceled = id
+			return nil
+		},
+	}
+}
+
+func TestActiveQueryService_FindActiveQueries(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		userID      *influxdb.ID
+		want        []influxdb.ID
+	}{
+		{
+			name:        "operator sees all queries",
+			permissions: []influxdb.Permission{operatorPermission},
+			want:        []influxdb.ID{1, 3},
+		},
+		{
+			name:        "operator sees queries of another user",
+			permissions: []influxdb.Permission{operatorPermission},
+			userID:      influxdbtesting.IDPtr(4),
+			want:        []influxdb.ID{3},
+		},
+		{
+			name: "user sees own queries",
+			want: []influxdb.ID{1},
+		},
+		{
+			name:   "user does not see queries of another user",
+			userID: influxdbtesting.IDPtr(4),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var canceled influxdb.ID
+			s := authorizer.NewActiveQueryService(activeQueryService(&canceled))
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			queries, err := s.FindActiveQueries(ctx, query.ActiveQueryFilter{UserID: tt.userID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []influxdb.ID
+			for _, q := range queries {
+				got = append(got, q.ID)
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("unexpected queries -want/+got\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestActiveQueryService_CancelActiveQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		id          influxdb.ID
+		err         error
+	}{
+		{
+			name:        "operator cancels query of another user",
+			permissions: []influxdb.Permission{operatorPermission},
+			id:          3,
+		},
+		{
+			name: "user cancels own query",
+			id:   1,
+		},
+		{
+			name: "user cannot cancel query of another user",
+			id:   3,
+			err: &influxdb.Error{
+				Msg:  "write:orgs is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var canceled influxdb.ID
+			s := authorizer.NewActiveQueryService(activeQueryService(&canceled))
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			err := s.CancelActiveQuery(ctx, tt.id)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+
+			want := tt.id
+			if tt.err != nil {
+				want = 0
+			}
+			if canceled != want {
+				t.Errorf("canceled query %v, want %v", canceled, want)
+			}
+		})
+	}
+}
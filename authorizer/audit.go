@@ -0,0 +1,48 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.AuditService = (*AuditService)(nil)
+
+// AuditService wraps a influxdb.AuditService and authorizes actions
+// against it appropriately.
+type AuditService struct {
+	s influxdb.AuditService
+}
+
+// NewAuditService constructs an instance of an authorizing audit service.
+func NewAuditService(s influxdb.AuditService) *AuditService {
+	return &AuditService{
+		s: s,
+	}
+}
+
+// RecordAuditEvent checks to see if the authorizer on context is the operator.
+// Events are normally recorded by the services of the audit package, which
+// are not wrapped by this service.
+func (s *AuditService) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	if err := IsOperator(ctx); err != nil {
+		return err
+	}
+
+	return s.s.RecordAuditEvent(ctx, e)
+}
+
+// FindAuditEvents checks to see if the authorizer on context may see the
+// audit trail. The audit trail of an organization can be seen by those with
+// write access to the organization; the whole audit trail only by the operator.
+func (s *AuditService) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	if filter.OrgID != nil {
+		if err := authorizeWriteOrg(ctx, *filter.OrgID); err != nil {
+			return nil, 0, err
+		}
+	} else if err := IsOperator(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	return s.s.FindAuditEvents(ctx, filter, opt...)
+}
@@ -0,0 +1,81 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestAuditService_FindAuditEvents(t *testing.T) {
+	writeOrg := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+			ID:   influxdbtesting.IDPtr(10),
+		},
+	}
+	readOrg := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+			ID:   influxdbtesting.IDPtr(10),
+		},
+	}
+	operator := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		filter      influxdb.AuditEventFilter
+		err         error
+	}{
+		{
+			name:        "authorized to see the audit trail of an org",
+			permissions: []influxdb.Permission{writeOrg},
+			filter:      influxdb.AuditEventFilter{OrgID: influxdbtesting.IDPtr(10)},
+		},
+		{
+			name:        "unauthorized to see the audit trail of an org",
+			permissions: []influxdb.Permission{readOrg},
+			filter:      influxdb.AuditEventFilter{OrgID: influxdbtesting.IDPtr(10)},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "operator can see the whole audit trail",
+			permissions: []influxdb.Permission{operator},
+		},
+		{
+			name:        "unauthorized to see the whole audit trail",
+			permissions: []influxdb.Permission{writeOrg},
+			err: &influxdb.Error{
+				Msg:  "write:orgs is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewAuditService(mock.NewAuditService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{tt.permissions})
+
+			_, _, err := s.FindAuditEvents(ctx, tt.filter)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
@@ -170,3 +170,17 @@ func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id influ
 
 	return s.s.DeleteAuthorization(ctx, id)
 }
+
+// RotateAuthorization checks to see if the authorizer on context has write access to the authorization provided.
+func (s *AuthorizationService) RotateAuthorization(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	a, err := s.s.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteAuthorization(ctx, a.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.s.RotateAuthorization(ctx, id)
+}
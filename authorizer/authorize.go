@@ -25,3 +25,15 @@ func IsAllowed(ctx context.Context, p influxdb.Permission) error {
 
 	return nil
 }
+
+// IsOperator checks to see if the authorizer on the context holds the global
+// permissions granted to the operator that setup the application. It is used to
+// protect instance wide administrative actions.
+func IsOperator(ctx context.Context) error {
+	return IsAllowed(ctx, influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+		},
+	})
+}
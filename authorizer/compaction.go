@@ -0,0 +1,69 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+var _ storage.CompactionService = (*CompactionService)(nil)
+
+// CompactionService wraps a storage.CompactionService and authorizes actions
+// against it appropriately. Compactions affect the whole instance so every
+// action requires operator permissions.
+type CompactionService struct {
+	s storage.CompactionService
+}
+
+// NewCompactionService constructs an instance of an authorizing compaction service.
+func NewCompactionService(s storage.CompactionService) *CompactionService {
+	return &CompactionService{
+		s: s,
+	}
+}
+
+// CompactionStatus checks to see if the authorizer on context is an operator.
+func (s *CompactionService) CompactionStatus(ctx context.Context) (*tsm1.CompactionStatus, error) {
+	if err := IsOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.s.CompactionStatus(ctx)
+}
+
+// PauseCompactions checks to see if the authorizer on context is an operator.
+func (s *CompactionService) PauseCompactions(ctx context.Context) error {
+	if err := IsOperator(ctx); err != nil {
+		return err
+	}
+
+	return s.s.PauseCompactions(ctx)
+}
+
+// ResumeCompactions checks to see if the authorizer on context is an operator.
+func (s *CompactionService) ResumeCompactions(ctx context.Context) error {
+	if err := IsOperator(ctx); err != nil {
+		return err
+	}
+
+	return s.s.ResumeCompactions(ctx)
+}
+
+// ScheduleFullCompaction checks to see if the authorizer on context is an operator.
+func (s *CompactionService) ScheduleFullCompaction(ctx context.Context) error {
+	if err := IsOperator(ctx); err != nil {
+		return err
+	}
+
+	return s.s.ScheduleFullCompaction(ctx)
+}
+
+// UpdateCompactions checks to see if the authorizer on context is an operator.
+func (s *CompactionService) UpdateCompactions(ctx context.Context, upd storage.CompactionUpdate) (*tsm1.CompactionStatus, error) {
+	if err := IsOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateCompactions(ctx, upd)
+}
@@ -0,0 +1,34 @@
+package authorizer
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/storage"
+)
+
+var _ storage.ImportService = (*ImportService)(nil)
+
+// ImportService wraps a storage.ImportService and authorizes actions against it
+// appropriately. Imports bypass the write path and replace existing points, so
+// they require operator permissions.
+type ImportService struct {
+	s storage.ImportService
+}
+
+// NewImportService constructs an instance of an authorizing import service.
+func NewImportService(s storage.ImportService) *ImportService {
+	return &ImportService{
+		s: s,
+	}
+}
+
+// Import checks to see if the authorizer on context is an operator.
+func (s *ImportService) Import(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) (int, error) {
+	if err := IsOperator(ctx); err != nil {
+		return 0, err
+	}
+
+	return s.s.Import(ctx, orgID, bucketID, r)
+}
@@ -0,0 +1,32 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/storage"
+)
+
+var _ storage.IndexService = (*IndexService)(nil)
+
+// IndexService wraps a storage.IndexService and authorizes actions against it
+// appropriately. The index is shared by every organization so rebuilding it
+// requires operator permissions.
+type IndexService struct {
+	s storage.IndexService
+}
+
+// NewIndexService constructs an instance of an authorizing index service.
+func NewIndexService(s storage.IndexService) *IndexService {
+	return &IndexService{
+		s: s,
+	}
+}
+
+// RebuildIndex checks to see if the authorizer on context is an operator.
+func (s *IndexService) RebuildIndex(ctx context.Context, partitions []int) error {
+	if err := IsOperator(ctx); err != nil {
+		return err
+	}
+
+	return s.s.RebuildIndex(ctx, partitions)
+}
@@ -0,0 +1,99 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.InvitationService = (*InvitationService)(nil)
+
+// InvitationService wraps a influxdb.InvitationService and authorizes actions
+// against it appropriately. Invitations are authorized like the membership of
+// the organization they invite to: reading them requires read access to the
+// organization, and creating or revoking them requires write access.
+type InvitationService struct {
+	s influxdb.InvitationService
+}
+
+// NewInvitationService constructs an instance of an authorizing invitation service.
+func NewInvitationService(s influxdb.InvitationService) *InvitationService {
+	return &InvitationService{
+		s: s,
+	}
+}
+
+// FindInvitationByID checks to see if the authorizer on context has read access to the organization of the invitation.
+func (s *InvitationService) FindInvitationByID(ctx context.Context, id influxdb.ID) (*influxdb.Invitation, error) {
+	i, err := s.s.FindInvitationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadOrg(ctx, i.OrgID); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// FindInvitationByToken is not authorized: the token of an invitation is the
+// secret that grants access to it.
+func (s *InvitationService) FindInvitationByToken(ctx context.Context, token string) (*influxdb.Invitation, error) {
+	return s.s.FindInvitationByToken(ctx, token)
+}
+
+// FindInvitations retrieves all invitations that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *InvitationService) FindInvitations(ctx context.Context, filter influxdb.InvitationFilter) ([]*influxdb.Invitation, int, error) {
+	is, _, err := s.s.FindInvitations(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	invitations := is[:0]
+	for _, i := range is {
+		err := authorizeReadOrg(ctx, i.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		invitations = append(invitations, i)
+	}
+
+	return invitations, len(invitations), nil
+}
+
+// CreateInvitation checks to see if the authorizer on context has write access to the organization invited to.
+func (s *InvitationService) CreateInvitation(ctx context.Context, i *influxdb.Invitation) error {
+	if err := authorizeWriteOrg(ctx, i.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.CreateInvitation(ctx, i)
+}
+
+// DeleteInvitation checks to see if the authorizer on context has write access to the organization of the invitation.
+func (s *InvitationService) DeleteInvitation(ctx context.Context, id influxdb.ID) error {
+	i, err := s.s.FindInvitationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteOrg(ctx, i.OrgID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteInvitation(ctx, id)
+}
+
+// ConsumeInvitation is not authorized: the token of an invitation is the
+// secret that grants access to it.
+func (s *InvitationService) ConsumeInvitation(ctx context.Context, token string) (*influxdb.Invitation, error) {
+	return s.s.ConsumeInvitation(ctx, token)
+}
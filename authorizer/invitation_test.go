@@ -0,0 +1,121 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestInvitationService_CreateInvitation(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name: "authorized to invite to organization",
+			permissions: []influxdb.Permission{
+				{
+					Action: influxdb.WriteAction,
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+						ID:   influxdbtesting.IDPtr(10),
+					},
+				},
+			},
+		},
+		{
+			name: "unauthorized to invite to organization it can only read",
+			permissions: []influxdb.Permission{
+				{
+					Action: influxdb.ReadAction,
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+						ID:   influxdbtesting.IDPtr(10),
+					},
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewInvitationService(mock.NewInvitationService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{tt.permissions})
+
+			err := s.CreateInvitation(ctx, &influxdb.Invitation{OrgID: 10, Username: "invitee"})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestInvitationService_FindInvitations(t *testing.T) {
+	is := mock.NewInvitationService()
+	is.FindInvitationsF = func(ctx context.Context, filter influxdb.InvitationFilter) ([]*influxdb.Invitation, int, error) {
+		return []*influxdb.Invitation{
+			{ID: 1, OrgID: 10},
+			{ID: 2, OrgID: 20},
+		}, 2, nil
+	}
+	s := authorizer.NewInvitationService(is)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: influxdb.ReadAction,
+			Resource: influxdb.Resource{
+				Type: influxdb.OrgsResourceType,
+				ID:   influxdbtesting.IDPtr(20),
+			},
+		},
+	}})
+
+	invitations, _, err := s.FindInvitations(ctx, influxdb.InvitationFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*influxdb.Invitation{{ID: 2, OrgID: 20}}
+	if diff := cmp.Diff(invitations, want); diff != "" {
+		t.Errorf("invitations are different -got/+want\ndiff %s", diff)
+	}
+}
+
+func TestInvitationService_DeleteInvitation(t *testing.T) {
+	is := mock.NewInvitationService()
+	is.FindInvitationByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Invitation, error) {
+		return &influxdb.Invitation{ID: id, OrgID: 10}, nil
+	}
+	is.DeleteInvitationF = func(ctx context.Context, id influxdb.ID) error {
+		t.Errorf("unauthorized invitation %s was revoked", id)
+		return nil
+	}
+	s := authorizer.NewInvitationService(is)
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: influxdb.WriteAction,
+			Resource: influxdb.Resource{
+				Type: influxdb.OrgsResourceType,
+				ID:   influxdbtesting.IDPtr(20),
+			},
+		},
+	}})
+
+	err := s.DeleteInvitation(ctx, 1)
+	influxdbtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Msg:  "write:orgs/000000000000000a is unauthorized",
+		Code: influxdb.EUnauthorized,
+	})
+}
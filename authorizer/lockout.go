@@ -0,0 +1,40 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.LockoutService = (*LockoutService)(nil)
+
+// LockoutService wraps a influxdb.LockoutService and authorizes actions
+// against it appropriately.
+type LockoutService struct {
+	s influxdb.LockoutService
+}
+
+// NewLockoutService constructs an instance of an authorizing lockout service.
+func NewLockoutService(s influxdb.LockoutService) *LockoutService {
+	return &LockoutService{
+		s: s,
+	}
+}
+
+// FindUserLockout checks to see if the authorizer on context is the operator.
+func (s *LockoutService) FindUserLockout(ctx context.Context, name string) (*influxdb.UserLockout, error) {
+	if err := IsOperator(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindUserLockout(ctx, name)
+}
+
+// UnlockUser checks to see if the authorizer on context is the operator.
+func (s *LockoutService) UnlockUser(ctx context.Context, name string) error {
+	if err := IsOperator(ctx); err != nil {
+		return err
+	}
+
+	return s.s.UnlockUser(ctx, name)
+}
@@ -0,0 +1,55 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestLockoutService_UnlockUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name: "operator can unlock users",
+			permissions: []influxdb.Permission{
+				{
+					Action:   influxdb.WriteAction,
+					Resource: influxdb.Resource{Type: influxdb.OrgsResourceType},
+				},
+			},
+		},
+		{
+			name: "users can not unlock users",
+			permissions: []influxdb.Permission{
+				{
+					Action:   influxdb.WriteAction,
+					Resource: influxdb.Resource{Type: influxdb.UsersResourceType},
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewLockoutService(mock.NewLockoutService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{tt.permissions})
+
+			err := s.UnlockUser(ctx, "user1")
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var (
+	_ query.LogSettingsService = (*LogSettingsService)(nil)
+	_ query.HistoryService     = (*HistoryService)(nil)
+)
+
+// LogSettingsService wraps a query.LogSettingsService and authorizes actions
+// against it appropriately.
+type LogSettingsService struct {
+	s query.LogSettingsService
+}
+
+// NewLogSettingsService constructs an instance of an authorizing query log settings service.
+func NewLogSettingsService(s query.LogSettingsService) *LogSettingsService {
+	return &LogSettingsService{
+		s: s,
+	}
+}
+
+// FindLogSettings checks to see if the authorizer on context has read access to the organization.
+func (s *LogSettingsService) FindLogSettings(ctx context.Context, orgID influxdb.ID) (*query.LogSettings, error) {
+	if err := authorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindLogSettings(ctx, orgID)
+}
+
+// PutLogSettings checks to see if the authorizer on context has write access
+// to the organization and to the bucket slow queries are written to.
+func (s *LogSettingsService) PutLogSettings(ctx context.Context, settings *query.LogSettings) error {
+	if err := authorizeWriteOrg(ctx, settings.OrganizationID); err != nil {
+		return err
+	}
+	if settings.BucketID.Valid() {
+		if err := authorizeWriteBucket(ctx, settings.OrganizationID, settings.BucketID); err != nil {
+			return err
+		}
+	}
+
+	return s.s.PutLogSettings(ctx, settings)
+}
+
+// HistoryService wraps a query.HistoryService and authorizes actions against
+// it appropriately. Operators may see the recent queries of any user; other
+// users only their own.
+type HistoryService struct {
+	s query.HistoryService
+}
+
+// NewHistoryService constructs an instance of an authorizing query history service.
+func NewHistoryService(s query.HistoryService) *HistoryService {
+	return &HistoryService{
+		s: s,
+	}
+}
+
+// FindRecentQueries restricts the filter to the queries of the user of the
+// authorizer on context, unless they are an operator.
+func (s *HistoryService) FindRecentQueries(ctx context.Context, filter query.RecentQueryFilter) ([]*query.LoggedQuery, error) {
+	userID, ok, err := queryUserFilter(ctx, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []*query.LoggedQuery{}, nil
+	}
+	filter.UserID = userID
+
+	return s.s.FindRecentQueries(ctx, filter)
+}
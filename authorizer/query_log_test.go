@@ -0,0 +1,141 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestLogSettingsService_PutLogSettings(t *testing.T) {
+	writeOrg := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.OrgsResourceType,
+			ID:   influxdbtesting.IDPtr(10),
+		},
+	}
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		bucketID    influxdb.ID
+		err         error
+	}{
+		{
+			name:        "authorized to write org",
+			permissions: []influxdb.Permission{writeOrg},
+		},
+		{
+			name: "unauthorized to write org",
+			permissions: []influxdb.Permission{
+				{
+					Action: influxdb.ReadAction,
+					Resource: influxdb.Resource{
+						Type: influxdb.OrgsResourceType,
+						ID:   influxdbtesting.IDPtr(10),
+					},
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name: "authorized to write org and bucket",
+			permissions: []influxdb.Permission{
+				writeOrg,
+				{
+					Action: influxdb.WriteAction,
+					Resource: influxdb.Resource{
+						Type:  influxdb.BucketsResourceType,
+						OrgID: influxdbtesting.IDPtr(10),
+						ID:    influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+			bucketID: 1,
+		},
+		{
+			name:        "unauthorized to write bucket",
+			permissions: []influxdb.Permission{writeOrg},
+			bucketID:    1,
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/buckets/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewLogSettingsService(&mock.LogSettingsService{
+				PutLogSettingsF: func(ctx context.Context, s *query.LogSettings) error {
+					return nil
+				},
+			})
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			err := s.PutLogSettings(ctx, &query.LogSettings{OrganizationID: 10, SlowQueryThreshold: time.Second, BucketID: tt.bucketID})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestHistoryService_FindRecentQueries(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		userID      *influxdb.ID
+		want        *influxdb.ID
+		called      bool
+	}{
+		{
+			name:        "operator sees queries of another user",
+			permissions: []influxdb.Permission{operatorPermission},
+			userID:      influxdbtesting.IDPtr(4),
+			want:        influxdbtesting.IDPtr(4),
+			called:      true,
+		},
+		{
+			name:   "user sees own queries",
+			want:   influxdbtesting.IDPtr(2),
+			called: true,
+		},
+		{
+			name:   "user does not see queries of another user",
+			userID: influxdbtesting.IDPtr(4),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			s := authorizer.NewHistoryService(&mock.HistoryService{
+				FindRecentQueriesF: func(ctx context.Context, filter query.RecentQueryFilter) ([]*query.LoggedQuery, error) {
+					called = true
+					if filter.UserID == nil || *filter.UserID != *tt.want {
+						t.Errorf("unexpected user filter %v, want %v", filter.UserID, *tt.want)
+					}
+					return nil, nil
+				},
+			})
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			if _, err := s.FindRecentQueries(ctx, query.RecentQueryFilter{UserID: tt.userID}); err != nil {
+				t.Fatal(err)
+			}
+			if called != tt.called {
+				t.Errorf("history service called %t, want %t", called, tt.called)
+			}
+		})
+	}
+}
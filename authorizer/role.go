@@ -0,0 +1,393 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// RoleService wraps a influxdb.RoleService and authorizes actions
+// against it appropriately.
+type RoleService struct {
+	s  influxdb.RoleService
+	as influxdb.AuthorizationService
+}
+
+// NewRoleService constructs an instance of an authorizing role service.
+// The authorization service is used to look up the owners of authorizations
+// that roles are assigned to.
+func NewRoleService(s influxdb.RoleService, as influxdb.AuthorizationService) *RoleService {
+	return &RoleService{
+		s:  s,
+		as: as,
+	}
+}
+
+func newRolePermission(a influxdb.Action, orgID, id influxdb.ID) (*influxdb.Permission, error) {
+	return influxdb.NewPermissionAtID(id, a, influxdb.RolesResourceType, orgID)
+}
+
+func authorizeReadRole(ctx context.Context, orgID, id influxdb.ID) error {
+	p, err := newRolePermission(influxdb.ReadAction, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func authorizeWriteRole(ctx context.Context, orgID, id influxdb.ID) error {
+	p, err := newRolePermission(influxdb.WriteAction, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FindRoleByID checks to see if the authorizer on context has read access to the id provided.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadRole(ctx, r.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// FindRoles retrieves all roles that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	rs, _, err := s.s.FindRoles(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	roles := rs[:0]
+	for _, r := range rs {
+		err := authorizeReadRole(ctx, r.OrgID, r.ID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		roles = append(roles, r)
+	}
+
+	return roles, len(roles), nil
+}
+
+// CreateRole checks to see if the authorizer on context has write access to
+// roles in the organization and holds every permission of the new role.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	p, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.RolesResourceType, r.OrgID)
+	if err != nil {
+		return err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return err
+	}
+
+	if err := VerifyPermissions(ctx, r.Permissions); err != nil {
+		return err
+	}
+
+	return s.s.CreateRole(ctx, r)
+}
+
+// UpdateRole checks to see if the authorizer on context has write access to
+// the role provided and holds every permission the role is updated to.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteRole(ctx, r.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	if upd.Permissions != nil {
+		if err := VerifyPermissions(ctx, *upd.Permissions); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.s.UpdateRole(ctx, id, upd)
+}
+
+// DeleteRole checks to see if the authorizer on context has write access to the role provided.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteRole(ctx, r.OrgID, id); err != nil {
+		return err
+	}
+
+	return s.s.DeleteRole(ctx, id)
+}
+
+// FindRoleMappings retrieves all role mappings that match the provided filter
+// and then filters the list down to the mappings of roles that are authorized.
+func (s *RoleService) FindRoleMappings(ctx context.Context, filter influxdb.RoleMappingFilter) ([]*influxdb.RoleMapping, int, error) {
+	ms, _, err := s.s.FindRoleMappings(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	mappings := ms[:0]
+	for _, m := range ms {
+		r, err := s.s.FindRoleByID(ctx, m.RoleID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		err = authorizeReadRole(ctx, r.OrgID, r.ID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		mappings = append(mappings, m)
+	}
+
+	return mappings, len(mappings), nil
+}
+
+// authorizeAssignRole checks that the authorizer on context may assign the
+// role to, or remove it from, the subject. Assigning a role grants its
+// permissions, so the authorizer must hold all of them itself.
+func (s *RoleService) authorizeAssignRole(ctx context.Context, roleID, subjectID influxdb.ID, subjectType influxdb.ResourceType) error {
+	r, err := s.s.FindRoleByID(ctx, roleID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteRole(ctx, r.OrgID, r.ID); err != nil {
+		return err
+	}
+
+	if err := VerifyPermissions(ctx, r.Permissions); err != nil {
+		return err
+	}
+
+	if subjectType == influxdb.AuthorizationsResourceType {
+		a, err := s.as.FindAuthorizationByID(ctx, subjectID)
+		if err != nil {
+			return err
+		}
+
+		if err := authorizeWriteAuthorization(ctx, a.UserID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateRoleMapping checks to see if the authorizer on context may assign the role to the subject.
+func (s *RoleService) CreateRoleMapping(ctx context.Context, m *influxdb.RoleMapping) error {
+	if err := s.authorizeAssignRole(ctx, m.RoleID, m.SubjectID, m.SubjectType); err != nil {
+		return err
+	}
+
+	return s.s.CreateRoleMapping(ctx, m)
+}
+
+// DeleteRoleMapping checks to see if the authorizer on context may remove the role from the subject.
+func (s *RoleService) DeleteRoleMapping(ctx context.Context, roleID, subjectID influxdb.ID) error {
+	ms, _, err := s.s.FindRoleMappings(ctx, influxdb.RoleMappingFilter{
+		RoleID:    &roleID,
+		SubjectID: &subjectID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(ms) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrRoleMappingNotFound,
+		}
+	}
+
+	if err := s.authorizeAssignRole(ctx, roleID, subjectID, ms[0].SubjectType); err != nil {
+		return err
+	}
+
+	return s.s.DeleteRoleMapping(ctx, roleID, subjectID)
+}
+
+// AddRolePermissions grants an authorizer the permissions of the roles
+// assigned to it. Authorizations are granted the roles assigned to them and
+// sessions the roles assigned to their user. Other authorizers are left
+// unchanged.
+//
+// Roles are only granted while the subject still belongs to the organization
+// of the role: authorizations must be of it and users members or owners of
+// it, which is looked up with the user resource mapping service. Mappings are
+// not removed when a user leaves an organization, so they are checked here.
+//
+// The services should not be authorizing services since there is no
+// authorizer on the context while it is being resolved.
+func AddRolePermissions(ctx context.Context, rs influxdb.RoleService, urms influxdb.UserResourceMappingService, a influxdb.Authorizer) error {
+	var (
+		filter influxdb.RoleMappingFilter
+		ps     *[]influxdb.Permission
+		member func(orgID influxdb.ID) (bool, error)
+	)
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		filter = influxdb.RoleMappingFilter{SubjectID: &a.ID, SubjectType: influxdb.AuthorizationsResourceType}
+		ps = &a.Permissions
+		member = func(orgID influxdb.ID) (bool, error) {
+			return a.OrgID == orgID, nil
+		}
+	case *influxdb.Session:
+		filter = influxdb.RoleMappingFilter{SubjectID: &a.UserID, SubjectType: influxdb.UsersResourceType}
+		ps = &a.Permissions
+		member = func(orgID influxdb.ID) (bool, error) {
+			_, n, err := urms.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+				ResourceType: influxdb.OrgsResourceType,
+				ResourceID:   orgID,
+				UserID:       a.UserID,
+			})
+			return n > 0, err
+		}
+	default:
+		return nil
+	}
+
+	ms, _, err := rs.FindRoleMappings(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		r, err := rs.FindRoleByID(ctx, m.RoleID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ok, err := member(r.OrgID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		*ps = append(*ps, r.Permissions...)
+	}
+
+	return nil
+}
+
+var (
+	_ influxdb.AuthorizationService = (*RoleAuthorizationService)(nil)
+	_ influxdb.SessionService       = (*RoleSessionService)(nil)
+)
+
+// RoleAuthorizationService wraps a influxdb.AuthorizationService and grants
+// the authorizations looked up by ID or token the permissions of the roles
+// assigned to them. It is used wherever an authorization is resolved to act
+// as, such as authenticating requests and running tasks. It must not wrap the
+// service that manages authorizations, as the granted permissions would be
+// shown and stored as the authorization's own.
+type RoleAuthorizationService struct {
+	influxdb.AuthorizationService
+	rs   influxdb.RoleService
+	urms influxdb.UserResourceMappingService
+}
+
+// NewRoleAuthorizationService constructs an authorization service that grants
+// role permissions. The role and user resource mapping services should not be
+// authorizing services.
+func NewRoleAuthorizationService(s influxdb.AuthorizationService, rs influxdb.RoleService, urms influxdb.UserResourceMappingService) *RoleAuthorizationService {
+	return &RoleAuthorizationService{
+		AuthorizationService: s,
+		rs:                   rs,
+		urms:                 urms,
+	}
+}
+
+// FindAuthorizationByID returns the authorization with the permissions of its roles.
+func (s *RoleAuthorizationService) FindAuthorizationByID(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	a, err := s.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := AddRolePermissions(ctx, s.rs, s.urms, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// FindAuthorizationByToken returns the authorization with the permissions of its roles.
+func (s *RoleAuthorizationService) FindAuthorizationByToken(ctx context.Context, t string) (*influxdb.Authorization, error) {
+	a, err := s.AuthorizationService.FindAuthorizationByToken(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if err := AddRolePermissions(ctx, s.rs, s.urms, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// RoleSessionService wraps a influxdb.SessionService and grants the sessions
+// it finds the permissions of the roles assigned to their user.
+type RoleSessionService struct {
+	influxdb.SessionService
+	rs   influxdb.RoleService
+	urms influxdb.UserResourceMappingService
+}
+
+// NewRoleSessionService constructs a session service that grants role
+// permissions. The role and user resource mapping services should not be
+// authorizing services.
+func NewRoleSessionService(s influxdb.SessionService, rs influxdb.RoleService, urms influxdb.UserResourceMappingService) *RoleSessionService {
+	return &RoleSessionService{
+		SessionService: s,
+		rs:             rs,
+		urms:           urms,
+	}
+}
+
+// FindSession returns the session with the permissions of its user's roles.
+func (s *RoleSessionService) FindSession(ctx context.Context, key string) (*influxdb.Session, error) {
+	sess, err := s.SessionService.FindSession(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := AddRolePermissions(ctx, s.rs, s.urms, sess); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
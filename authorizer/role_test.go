@@ -0,0 +1,293 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestRoleService_CreateRole(t *testing.T) {
+	readBuckets := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+	writeRoles := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.RolesResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name:        "authorized to create role",
+			permissions: []influxdb.Permission{writeRoles, readBuckets},
+		},
+		{
+			name:        "unauthorized to create roles",
+			permissions: []influxdb.Permission{readBuckets},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/roles is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+		{
+			name:        "unauthorized to grant permissions of role",
+			permissions: []influxdb.Permission{writeRoles},
+			err: &influxdb.Error{
+				Msg:  "permission read:orgs/000000000000000a/buckets is not allowed",
+				Code: influxdb.EForbidden,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewRoleService(mock.NewRoleService(), mock.NewAuthorizationService())
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{tt.permissions})
+
+			err := s.CreateRole(ctx, &influxdb.Role{
+				OrgID:       10,
+				Name:        "readers",
+				Permissions: []influxdb.Permission{readBuckets},
+			})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestRoleService_CreateRoleMapping(t *testing.T) {
+	writeRole := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.RolesResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+			ID:    influxdbtesting.IDPtr(1),
+		},
+	}
+	writeUser := influxdb.Permission{
+		Action: influxdb.WriteAction,
+		Resource: influxdb.Resource{
+			Type: influxdb.UsersResourceType,
+			ID:   influxdbtesting.IDPtr(3),
+		},
+	}
+
+	rs := mock.NewRoleService()
+	rs.FindRoleByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+		return &influxdb.Role{ID: id, OrgID: 10, Permissions: []influxdb.Permission{}}, nil
+	}
+	as := mock.NewAuthorizationService()
+	as.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+		return &influxdb.Authorization{ID: id, UserID: 3}, nil
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		mapping     *influxdb.RoleMapping
+		err         error
+	}{
+		{
+			name:        "authorized to assign role to user",
+			permissions: []influxdb.Permission{writeRole},
+			mapping:     &influxdb.RoleMapping{RoleID: 1, SubjectID: 2, SubjectType: influxdb.UsersResourceType},
+		},
+		{
+			name:        "authorized to assign role to authorization",
+			permissions: []influxdb.Permission{writeRole, writeUser},
+			mapping:     &influxdb.RoleMapping{RoleID: 1, SubjectID: 2, SubjectType: influxdb.AuthorizationsResourceType},
+		},
+		{
+			name:        "unauthorized to assign role to authorization of another user",
+			permissions: []influxdb.Permission{writeRole},
+			mapping:     &influxdb.RoleMapping{RoleID: 1, SubjectID: 2, SubjectType: influxdb.AuthorizationsResourceType},
+			err: &influxdb.Error{
+				Msg:  "write:users/0000000000000003 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewRoleService(rs, as)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{tt.permissions})
+
+			err := s.CreateRoleMapping(ctx, tt.mapping)
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestAddRolePermissions(t *testing.T) {
+	readBuckets := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+	readDashboards := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.DashboardsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+
+	rs := mock.NewRoleService()
+	rs.FindRoleMappingsF = func(ctx context.Context, filter influxdb.RoleMappingFilter) ([]*influxdb.RoleMapping, int, error) {
+		switch {
+		case *filter.SubjectID == 1 && filter.SubjectType == influxdb.AuthorizationsResourceType:
+			return []*influxdb.RoleMapping{{RoleID: 100, SubjectID: 1, SubjectType: filter.SubjectType}}, 1, nil
+		case *filter.SubjectID == 5 && filter.SubjectType == influxdb.AuthorizationsResourceType:
+			return []*influxdb.RoleMapping{{RoleID: 100, SubjectID: 5, SubjectType: filter.SubjectType}}, 1, nil
+		case (*filter.SubjectID == 2 || *filter.SubjectID == 6) && filter.SubjectType == influxdb.UsersResourceType:
+			return []*influxdb.RoleMapping{{RoleID: 100, SubjectID: *filter.SubjectID, SubjectType: filter.SubjectType}}, 1, nil
+		}
+		return nil, 0, nil
+	}
+	rs.FindRoleByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+		return &influxdb.Role{ID: id, OrgID: 10, Permissions: []influxdb.Permission{readBuckets}}, nil
+	}
+
+	// Only user 2 is a member of the organization of the role.
+	urms := mock.NewUserResourceMappingService()
+	urms.FindMappingsFn = func(ctx context.Context, filter influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+		if filter.UserID == 2 && filter.ResourceID == 10 && filter.ResourceType == influxdb.OrgsResourceType {
+			return []*influxdb.UserResourceMapping{{UserID: 2, ResourceID: 10, ResourceType: influxdb.OrgsResourceType}}, 1, nil
+		}
+		return nil, 0, nil
+	}
+
+	tests := []struct {
+		name string
+		auth influxdb.Authorizer
+		want []influxdb.Permission
+	}{
+		{
+			name: "authorization is granted its roles",
+			auth: &influxdb.Authorization{ID: 1, OrgID: 10, UserID: 2, Permissions: []influxdb.Permission{readDashboards}},
+			want: []influxdb.Permission{readDashboards, readBuckets},
+		},
+		{
+			name: "authorization is not granted roles of its user",
+			auth: &influxdb.Authorization{ID: 3, OrgID: 10, UserID: 2, Permissions: []influxdb.Permission{readDashboards}},
+			want: []influxdb.Permission{readDashboards},
+		},
+		{
+			name: "authorization is not granted roles of another organization",
+			auth: &influxdb.Authorization{ID: 5, OrgID: 11, UserID: 2, Permissions: []influxdb.Permission{readDashboards}},
+			want: []influxdb.Permission{readDashboards},
+		},
+		{
+			name: "session is granted roles of its user",
+			auth: &influxdb.Session{ID: 4, UserID: 2},
+			want: []influxdb.Permission{readBuckets},
+		},
+		{
+			name: "session is not granted roles of an organization its user left",
+			auth: &influxdb.Session{ID: 7, UserID: 6},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := authorizer.AddRolePermissions(context.Background(), rs, urms, tt.auth); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []influxdb.Permission
+			switch a := tt.auth.(type) {
+			case *influxdb.Authorization:
+				got = a.Permissions
+			case *influxdb.Session:
+				got = a.Permissions
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("permissions are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+func TestRoleAuthorizationService(t *testing.T) {
+	readBuckets := influxdb.Permission{
+		Action: influxdb.ReadAction,
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(10),
+		},
+	}
+
+	rs := mock.NewRoleService()
+	rs.FindRoleMappingsF = func(ctx context.Context, filter influxdb.RoleMappingFilter) ([]*influxdb.RoleMapping, int, error) {
+		return []*influxdb.RoleMapping{{RoleID: 100, SubjectID: *filter.SubjectID, SubjectType: filter.SubjectType}}, 1, nil
+	}
+	rs.FindRoleByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+		return &influxdb.Role{ID: id, OrgID: 10, Permissions: []influxdb.Permission{readBuckets}}, nil
+	}
+
+	as := mock.NewAuthorizationService()
+	as.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+		return &influxdb.Authorization{ID: id, OrgID: 10}, nil
+	}
+	as.FindAuthorizationByTokenFn = func(ctx context.Context, token string) (*influxdb.Authorization, error) {
+		return &influxdb.Authorization{ID: 1, OrgID: 10, Token: token}, nil
+	}
+	ss := mock.NewSessionService()
+	ss.FindSessionFn = func(ctx context.Context, key string) (*influxdb.Session, error) {
+		return &influxdb.Session{ID: 2, Key: key, UserID: 3}, nil
+	}
+	urms := mock.NewUserResourceMappingService()
+	urms.FindMappingsFn = func(ctx context.Context, filter influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, int, error) {
+		return []*influxdb.UserResourceMapping{{UserID: filter.UserID, ResourceID: filter.ResourceID, ResourceType: filter.ResourceType}}, 1, nil
+	}
+
+	ctx := context.Background()
+	ras := authorizer.NewRoleAuthorizationService(as, rs, urms)
+	want := []influxdb.Permission{readBuckets}
+
+	a, err := ras.FindAuthorizationByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(a.Permissions, want); diff != "" {
+		t.Errorf("authorization by ID permissions are different -got/+want\ndiff %s", diff)
+	}
+
+	a, err = ras.FindAuthorizationByToken(ctx, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(a.Permissions, want); diff != "" {
+		t.Errorf("authorization by token permissions are different -got/+want\ndiff %s", diff)
+	}
+
+	s, err := authorizer.NewRoleSessionService(ss, rs, urms).FindSession(ctx, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(s.Permissions, want); diff != "" {
+		t.Errorf("session permissions are different -got/+want\ndiff %s", diff)
+	}
+}
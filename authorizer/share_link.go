@@ -0,0 +1,102 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.ShareLinkService = (*ShareLinkService)(nil)
+
+// ShareLinkService wraps a influxdb.ShareLinkService and authorizes actions
+// against it appropriately. Share links are authorized by the dashboard they
+// share: reading a link requires read access to its dashboard, and creating
+// or deleting one requires write access.
+type ShareLinkService struct {
+	s  influxdb.ShareLinkService
+	ds influxdb.DashboardService
+}
+
+// NewShareLinkService constructs an instance of an authorizing share link
+// service. The dashboard service is used to look up the organization of the
+// dashboards links are created for.
+func NewShareLinkService(s influxdb.ShareLinkService, ds influxdb.DashboardService) *ShareLinkService {
+	return &ShareLinkService{
+		s:  s,
+		ds: ds,
+	}
+}
+
+// FindShareLinkByID checks to see if the authorizer on context has read access to the dashboard of the link.
+func (s *ShareLinkService) FindShareLinkByID(ctx context.Context, id influxdb.ID) (*influxdb.ShareLink, error) {
+	l, err := s.s.FindShareLinkByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadDashboard(ctx, l.OrgID, l.DashboardID); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// FindShareLinkByKey is not authorized: the key of a link is the secret
+// that grants access to it.
+func (s *ShareLinkService) FindShareLinkByKey(ctx context.Context, key string) (*influxdb.ShareLink, error) {
+	return s.s.FindShareLinkByKey(ctx, key)
+}
+
+// FindShareLinks retrieves all share links that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *ShareLinkService) FindShareLinks(ctx context.Context, filter influxdb.ShareLinkFilter) ([]*influxdb.ShareLink, int, error) {
+	ls, _, err := s.s.FindShareLinks(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	links := ls[:0]
+	for _, l := range ls {
+		err := authorizeReadDashboard(ctx, l.OrgID, l.DashboardID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		links = append(links, l)
+	}
+
+	return links, len(links), nil
+}
+
+// CreateShareLink checks to see if the authorizer on context has write access to the dashboard shared.
+func (s *ShareLinkService) CreateShareLink(ctx context.Context, l *influxdb.ShareLink) error {
+	d, err := s.ds.FindDashboardByID(ctx, l.DashboardID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteDashboard(ctx, d.OrganizationID, d.ID); err != nil {
+		return err
+	}
+
+	return s.s.CreateShareLink(ctx, l)
+}
+
+// DeleteShareLink checks to see if the authorizer on context has write access to the dashboard of the link.
+func (s *ShareLinkService) DeleteShareLink(ctx context.Context, id influxdb.ID) error {
+	l, err := s.s.FindShareLinkByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteDashboard(ctx, l.OrgID, l.DashboardID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteShareLink(ctx, id)
+}
@@ -0,0 +1,99 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestShareLinkService_CreateShareLink(t *testing.T) {
+	ds := mock.NewDashboardService()
+	ds.FindDashboardByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+		return &influxdb.Dashboard{ID: id, OrganizationID: 10}, nil
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		err         error
+	}{
+		{
+			name: "authorized to share dashboard",
+			permissions: []influxdb.Permission{
+				{
+					Action: influxdb.WriteAction,
+					Resource: influxdb.Resource{
+						Type:  influxdb.DashboardsResourceType,
+						OrgID: influxdbtesting.IDPtr(10),
+						ID:    influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+		},
+		{
+			name: "unauthorized to share dashboard it can only read",
+			permissions: []influxdb.Permission{
+				{
+					Action: influxdb.ReadAction,
+					Resource: influxdb.Resource{
+						Type:  influxdb.DashboardsResourceType,
+						OrgID: influxdbtesting.IDPtr(10),
+						ID:    influxdbtesting.IDPtr(1),
+					},
+				},
+			},
+			err: &influxdb.Error{
+				Msg:  "write:orgs/000000000000000a/dashboards/0000000000000001 is unauthorized",
+				Code: influxdb.EUnauthorized,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewShareLinkService(mock.NewShareLinkService(), ds)
+
+			ctx := context.Background()
+			ctx = influxdbcontext.SetAuthorizer(ctx, &Authorizer{tt.permissions})
+
+			err := s.CreateShareLink(ctx, &influxdb.ShareLink{DashboardID: 1})
+			influxdbtesting.ErrorsEqual(t, err, tt.err)
+		})
+	}
+}
+
+func TestShareLinkService_FindShareLinks(t *testing.T) {
+	ls := mock.NewShareLinkService()
+	ls.FindShareLinksF = func(ctx context.Context, filter influxdb.ShareLinkFilter) ([]*influxdb.ShareLink, int, error) {
+		return []*influxdb.ShareLink{
+			{ID: 1, OrgID: 10, DashboardID: 100},
+			{ID: 2, OrgID: 10, DashboardID: 200},
+		}, 2, nil
+	}
+	s := authorizer.NewShareLinkService(ls, mock.NewDashboardService())
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{[]influxdb.Permission{
+		{
+			Action: influxdb.ReadAction,
+			Resource: influxdb.Resource{
+				Type:  influxdb.DashboardsResourceType,
+				OrgID: influxdbtesting.IDPtr(10),
+				ID:    influxdbtesting.IDPtr(200),
+			},
+		},
+	}})
+
+	links, _, err := s.FindShareLinks(ctx, influxdb.ShareLinkFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(links, []*influxdb.ShareLink{{ID: 2, OrgID: 10, DashboardID: 200}}); diff != "" {
+		t.Errorf("share links are different -got/+want\ndiff %s", diff)
+	}
+}
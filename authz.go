@@ -122,6 +122,8 @@ const (
 	// ViewsResourceType gives permission to one or more views.
 	ViewsResourceType     = ResourceType("views")     // 12
 	DocumentsResourceType = ResourceType("documents") // 13
+	// RolesResourceType gives permission to one or more roles.
+	RolesResourceType = ResourceType("roles") // 14
 )
 
 // AllResourceTypes is the list of all known resource types.
@@ -140,6 +142,7 @@ var AllResourceTypes = []ResourceType{
 	LabelsResourceType,         // 11
 	ViewsResourceType,          // 12
 	DocumentsResourceType,      // 13
+	RolesResourceType,          // 14
 	// NOTE: when modifying this list, please update the swagger for components.schemas.Permission resource enum.
 }
 
@@ -154,6 +157,7 @@ var OrgResourceTypes = []ResourceType{
 	VariablesResourceType,  // 8
 	SecretsResourceType,    // 10
 	DocumentsResourceType,  //13
+	RolesResourceType,      // 14
 }
 
 // Valid checks if the resource type is a member of the ResourceType enum.
@@ -178,6 +182,7 @@ func (t ResourceType) Valid() (err error) {
 	case LabelsResourceType: // 11
 	case ViewsResourceType: // 12
 	case DocumentsResourceType: // 13
+	case RolesResourceType: // 14
 	default:
 		err = ErrInvalidResourceType
 	}
@@ -431,6 +431,12 @@ func (c *Client) updateAuthorization(ctx context.Context, tx *bolt.Tx, id platfo
 	if upd.Description != nil {
 		a.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		a.ExpiresAt = upd.ExpiresAt
+	}
+	if upd.LastUsedAt != nil {
+		a.LastUsedAt = upd.LastUsedAt
+	}
 
 	b, err := encodeAuthorization(a)
 	if err != nil {
@@ -453,3 +459,50 @@ func (c *Client) updateAuthorization(ctx context.Context, tx *bolt.Tx, id platfo
 	}
 	return a, nil
 }
+
+// RotateAuthorization replaces the token of an authorization with a newly generated one.
+func (c *Client) RotateAuthorization(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+	var a *platform.Authorization
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		var pe *platform.Error
+		a, pe = c.rotateAuthorization(ctx, tx, id)
+		if pe != nil {
+			return &platform.Error{
+				Err: pe,
+				Op:  platform.OpRotateAuthorization,
+			}
+		}
+		return nil
+	})
+	return a, err
+}
+
+func (c *Client) rotateAuthorization(ctx context.Context, tx *bolt.Tx, id platform.ID) (*platform.Authorization, *platform.Error) {
+	a, pe := c.findAuthorizationByID(ctx, tx, id)
+	if pe != nil {
+		return nil, pe
+	}
+
+	if err := tx.Bucket(authorizationIndex).Delete(authorizationIndexKey(a.Token)); err != nil {
+		return nil, &platform.Error{
+			Err: err,
+		}
+	}
+
+	token, err := c.TokenGenerator.Token()
+	if err != nil {
+		return nil, &platform.Error{
+			Err: err,
+		}
+	}
+	a.Token = token
+
+	if !c.uniqueAuthorizationToken(ctx, tx, a) {
+		return nil, platform.ErrUnableToCreateToken
+	}
+
+	if pe := c.putAuthorization(ctx, tx, a); pe != nil {
+		return nil, pe
+	}
+	return a, nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/query"
+	"github.com/spf13/cobra"
+)
+
+var activeQueryListFlags struct {
+	userID string
+}
+
+func init() {
+	queryListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List running queries",
+		Long: `List the queries that are running. Operators see the queries of every
+user; other users only their own.`,
+		Args: cobra.NoArgs,
+		RunE: wrapCheckSetup(activeQueryListF),
+	}
+	queryListCmd.Flags().StringVar(&activeQueryListFlags.userID, "user-id", "", "The ID of the user who made the queries")
+
+	queryKillCmd := &cobra.Command{
+		Use:   "kill [query ID]",
+		Short: "Cancel a running query",
+		Args:  cobra.ExactArgs(1),
+		RunE:  wrapCheckSetup(activeQueryKillF),
+	}
+
+	queryCmd.AddCommand(
+		queryListCmd,
+		queryKillCmd,
+	)
+}
+
+func newActiveQueryService(f Flags) (query.ActiveQueryService, error) {
+	if flags.local {
+		return nil, fmt.Errorf("local flag not supported for query command")
+	}
+	return &http.ActiveQueryService{
+		Addr:               flags.host,
+		Token:              flags.token,
+		InsecureSkipVerify: flags.skipVerify,
+	}, nil
+}
+
+func activeQueryListF(cmd *cobra.Command, args []string) error {
+	s, err := newActiveQueryService(flags)
+	if err != nil {
+		return err
+	}
+
+	var filter query.ActiveQueryFilter
+	if queryFlags.OrgID != "" && queryFlags.Org != "" {
+		return fmt.Errorf("must specify at most one of org or org-id")
+	}
+
+	if queryFlags.OrgID != "" {
+		id, err := platform.IDFromString(queryFlags.OrgID)
+		if err != nil {
+			return fmt.Errorf("failed to decode org-id: %v", err)
+		}
+		filter.OrganizationID = id
+	}
+
+	if queryFlags.Org != "" {
+		orgSvc, err := newOrganizationService(flags)
+		if err != nil {
+			return fmt.Errorf("failed to initialized organization service client: %v", err)
+		}
+
+		o, err := orgSvc.FindOrganization(context.Background(), platform.OrganizationFilter{Name: &queryFlags.Org})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve organization %q: %v", queryFlags.Org, err)
+		}
+		filter.OrganizationID = &o.ID
+	}
+
+	if activeQueryListFlags.userID != "" {
+		id, err := platform.IDFromString(activeQueryListFlags.userID)
+		if err != nil {
+			return fmt.Errorf("failed to decode user-id: %v", err)
+		}
+		filter.UserID = id
+	}
+
+	queries, err := s.FindActiveQueries(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to find queries: %v", err)
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"OrgID",
+		"UserID",
+		"Source",
+		"State",
+		"Elapsed",
+		"Memory",
+		"Query",
+	)
+	for _, q := range queries {
+		w.Write(map[string]interface{}{
+			"ID":      q.ID.String(),
+			"OrgID":   q.OrganizationID.String(),
+			"UserID":  q.UserID.String(),
+			"Source":  q.Source,
+			"State":   q.State,
+			"Elapsed": q.Elapsed.Round(time.Millisecond).String(),
+			"Memory":  q.MemoryBytes,
+			// Flux queries are formatted over many lines.
+			"Query": strings.Join(strings.Fields(q.Query), " "),
+		})
+	}
+	w.Flush()
+	return nil
+}
+
+func activeQueryKillF(cmd *cobra.Command, args []string) error {
+	s, err := newActiveQueryService(flags)
+	if err != nil {
+		return err
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(args[0]); err != nil {
+		return fmt.Errorf("failed to decode query ID: %v", err)
+	}
+
+	if err := s.CancelActiveQuery(context.Background(), id); err != nil {
+		return fmt.Errorf("failed to cancel query: %v", err)
+	}
+	return nil
+}
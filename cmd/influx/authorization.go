@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"os"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influx/internal"
@@ -21,8 +22,9 @@ var authorizationCmd = &cobra.Command{
 
 // AuthorizationCreateFlags are command line args used when creating a authorization
 type AuthorizationCreateFlags struct {
-	user string
-	org  string
+	user      string
+	org       string
+	expiresIn time.Duration
 
 	writeUserPermission bool
 	readUserPermission  bool
@@ -59,6 +61,7 @@ func init() {
 	authorizationCreateCmd.MarkFlagRequired("org")
 
 	authorizationCreateCmd.Flags().StringVarP(&authorizationCreateFlags.user, "user", "u", "", "The user name")
+	authorizationCreateCmd.Flags().DurationVarP(&authorizationCreateFlags.expiresIn, "expires-in", "", 0, "Duration after which the token expires; tokens never expire by default")
 
 	authorizationCreateCmd.Flags().BoolVarP(&authorizationCreateFlags.writeUserPermission, "write-user", "", false, "Grants the permission to perform mutative actions against organization users")
 	authorizationCreateCmd.Flags().BoolVarP(&authorizationCreateFlags.readUserPermission, "read-user", "", false, "Grants the permission to perform read actions against organization users")
@@ -226,6 +229,11 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		OrgID:       o.ID,
 	}
 
+	if authorizationCreateFlags.expiresIn > 0 {
+		expiresAt := time.Now().Add(authorizationCreateFlags.expiresIn).UTC()
+		authorization.ExpiresAt = &expiresAt
+	}
+
 	s, err := newAuthorizationService(flags)
 	if err != nil {
 		return err
@@ -332,6 +340,8 @@ func authorizationFindF(cmd *cobra.Command, args []string) error {
 		"Status",
 		"User",
 		"UserID",
+		"ExpiresAt",
+		"LastUsedAt",
 		"Permissions",
 	)
 
@@ -341,11 +351,21 @@ func authorizationFindF(cmd *cobra.Command, args []string) error {
 			permissions = append(permissions, p.String())
 		}
 
+		var expiresAt, lastUsedAt string
+		if a.ExpiresAt != nil {
+			expiresAt = a.ExpiresAt.Format(time.RFC3339)
+		}
+		if a.LastUsedAt != nil {
+			lastUsedAt = a.LastUsedAt.Format(time.RFC3339)
+		}
+
 		w.Write(map[string]interface{}{
 			"ID":          a.ID,
 			"Token":       a.Token,
 			"Status":      a.Status,
 			"UserID":      a.UserID.String(),
+			"ExpiresAt":   expiresAt,
+			"LastUsedAt":  lastUsedAt,
 			"Permissions": permissions,
 		})
 	}
@@ -565,3 +585,66 @@ func authorizationInactiveF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// AuthorizationRotateFlags are command line args used when rotating the token of an authorization
+type AuthorizationRotateFlags struct {
+	id string
+}
+
+var authorizationRotateFlags AuthorizationRotateFlags
+
+func init() {
+	authorizationRotateCmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Replace the token of an authorization, keeping its ID and permissions",
+		RunE:  wrapCheckSetup(authorizationRotateF),
+	}
+
+	authorizationRotateCmd.Flags().StringVarP(&authorizationRotateFlags.id, "id", "i", "", "The authorization ID (required)")
+	authorizationRotateCmd.MarkFlagRequired("id")
+
+	authorizationCmd.AddCommand(authorizationRotateCmd)
+}
+
+func authorizationRotateF(cmd *cobra.Command, args []string) error {
+	s, err := newAuthorizationService(flags)
+	if err != nil {
+		return err
+	}
+
+	var id platform.ID
+	if err := id.DecodeFromString(authorizationRotateFlags.id); err != nil {
+		return err
+	}
+
+	a, err := s.RotateAuthorization(context.Background(), id)
+	if err != nil {
+		return err
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Token",
+		"Status",
+		"UserID",
+		"Permissions",
+	)
+
+	ps := []string{}
+	for _, p := range a.Permissions {
+		ps = append(ps, p.String())
+	}
+
+	w.Write(map[string]interface{}{
+		"ID":          a.ID.String(),
+		"Token":       a.Token,
+		"Status":      a.Status,
+		"UserID":      a.UserID.String(),
+		"Permissions": ps,
+	})
+
+	w.Flush()
+
+	return nil
+}
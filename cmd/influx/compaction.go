@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// Compaction Command
+var compactionCmd = &cobra.Command{
+	Use:   "compaction",
+	Short: "Storage engine compaction management commands",
+	Run:   compactionF,
+}
+
+func compactionF(cmd *cobra.Command, args []string) {
+	cmd.Usage()
+}
+
+func init() {
+	compactionListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List running and planned compactions",
+		RunE:  wrapCheckSetup(compactionListF),
+	}
+
+	compactionPauseCmd := &cobra.Command{
+		Use:   "pause",
+		Short: "Pause level, optimize and full compactions",
+		RunE:  wrapCheckSetup(compactionPauseF),
+	}
+
+	compactionResumeCmd := &cobra.Command{
+		Use:   "resume",
+		Short: "Resume paused compactions",
+		RunE:  wrapCheckSetup(compactionResumeF),
+	}
+
+	compactionFullCmd := &cobra.Command{
+		Use:   "full",
+		Short: "Trigger a full compaction of the storage engine",
+		RunE:  wrapCheckSetup(compactionFullF),
+	}
+
+	compactionCmd.AddCommand(
+		compactionListCmd,
+		compactionPauseCmd,
+		compactionResumeCmd,
+		compactionFullCmd,
+	)
+}
+
+// Set Command
+type CompactionSetFlags struct {
+	throughput      int
+	throughputBurst int
+	maxConcurrent   int
+}
+
+var compactionSetFlags CompactionSetFlags
+
+func init() {
+	compactionSetCmd := &cobra.Command{
+		Use:   "set",
+		Short: "Change compaction throughput and concurrency",
+		RunE:  wrapCheckSetup(compactionSetF),
+	}
+
+	compactionSetCmd.Flags().IntVarP(&compactionSetFlags.throughput, "throughput", "", -1, "Rate limit in bytes per second for compaction writes; 0 disables the limit")
+	compactionSetCmd.Flags().IntVarP(&compactionSetFlags.throughputBurst, "throughput-burst", "", -1, "Burst rate limit in bytes per second for compaction writes")
+	compactionSetCmd.Flags().IntVarP(&compactionSetFlags.maxConcurrent, "max-concurrent", "", -1, "Maximum number of concurrent compactions; 0 uses the default")
+
+	compactionCmd.AddCommand(compactionSetCmd)
+}
+
+func newCompactionService(f Flags) (storage.CompactionService, error) {
+	if flags.local {
+		return nil, fmt.Errorf("local flag not supported for compaction command")
+	}
+	return &http.CompactionService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}, nil
+}
+
+func compactionListF(cmd *cobra.Command, args []string) error {
+	s, err := newCompactionService(flags)
+	if err != nil {
+		return err
+	}
+
+	status, err := s.CompactionStatus(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to find compactions: %v", err)
+	}
+
+	writeCompactionStatus(status)
+	return nil
+}
+
+func compactionPauseF(cmd *cobra.Command, args []string) error {
+	s, err := newCompactionService(flags)
+	if err != nil {
+		return err
+	}
+
+	if err := s.PauseCompactions(context.Background()); err != nil {
+		return fmt.Errorf("failed to pause compactions: %v", err)
+	}
+	return nil
+}
+
+func compactionResumeF(cmd *cobra.Command, args []string) error {
+	s, err := newCompactionService(flags)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ResumeCompactions(context.Background()); err != nil {
+		return fmt.Errorf("failed to resume compactions: %v", err)
+	}
+	return nil
+}
+
+func compactionFullF(cmd *cobra.Command, args []string) error {
+	s, err := newCompactionService(flags)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ScheduleFullCompaction(context.Background()); err != nil {
+		return fmt.Errorf("failed to schedule full compaction: %v", err)
+	}
+	return nil
+}
+
+func compactionSetF(cmd *cobra.Command, args []string) error {
+	s, err := newCompactionService(flags)
+	if err != nil {
+		return err
+	}
+
+	var upd storage.CompactionUpdate
+	if compactionSetFlags.throughput >= 0 {
+		upd.Throughput = &compactionSetFlags.throughput
+	}
+	if compactionSetFlags.throughputBurst >= 0 {
+		upd.ThroughputBurst = &compactionSetFlags.throughputBurst
+	}
+	if compactionSetFlags.maxConcurrent >= 0 {
+		upd.MaxConcurrent = &compactionSetFlags.maxConcurrent
+	}
+
+	status, err := s.UpdateCompactions(context.Background(), upd)
+	if err != nil {
+		return fmt.Errorf("failed to update compactions: %v", err)
+	}
+
+	writeCompactionStatus(status)
+	return nil
+}
+
+func writeCompactionStatus(status *tsm1.CompactionStatus) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"Paused",
+		"Throughput",
+		"ThroughputBurst",
+		"MaxConcurrent",
+	)
+	w.Write(map[string]interface{}{
+		"Paused":          status.Paused,
+		"Throughput":      status.Throughput,
+		"ThroughputBurst": status.ThroughputBurst,
+		"MaxConcurrent":   status.MaxConcurrent,
+	})
+	w.Flush()
+
+	fmt.Println()
+
+	w = internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"State",
+		"Level",
+		"Generations",
+		"Files",
+		"Bytes",
+		"Elapsed",
+	)
+	for _, c := range status.Running {
+		w.Write(compactionRow("running", c, time.Since(c.Started).Round(time.Second).String()))
+	}
+	for _, c := range status.Planned {
+		w.Write(compactionRow("planned", c, ""))
+	}
+	w.Flush()
+}
+
+func compactionRow(state string, c tsm1.CompactionInfo, elapsed string) map[string]interface{} {
+	gens := make([]string, 0, len(c.Generations))
+	for _, g := range c.Generations {
+		gens = append(gens, fmt.Sprint(g))
+	}
+
+	return map[string]interface{}{
+		"State":       state,
+		"Level":       c.Level,
+		"Generations": strings.Join(gens, ","),
+		"Files":       len(c.Files),
+		"Bytes":       c.Bytes,
+		"Elapsed":     elapsed,
+	}
+}
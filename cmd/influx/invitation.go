@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/spf13/cobra"
+)
+
+// Invitation management
+var organizationInvitationsCmd = &cobra.Command{
+	Use:     "invitations",
+	Aliases: []string{"invitation", "invite"},
+	Short:   "Organization invitation commands",
+	Run:     organizationF,
+}
+
+func init() {
+	organizationCmd.AddCommand(organizationInvitationsCmd)
+}
+
+func newInvitationService(f Flags) *http.InvitationService {
+	return &http.InvitationService{
+		Addr:  f.host,
+		Token: f.token,
+	}
+}
+
+func writeInvitations(withToken bool, is ...*platform.Invitation) {
+	headers := []string{
+		"ID",
+		"OrgID",
+		"Username",
+		"Email",
+		"Role",
+		"ExpiresAt",
+	}
+	if withToken {
+		headers = append(headers, "Token")
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(headers...)
+	for _, i := range is {
+		w.Write(map[string]interface{}{
+			"ID":        i.ID.String(),
+			"OrgID":     i.OrgID.String(),
+			"Username":  i.Username,
+			"Email":     i.Email,
+			"Role":      string(i.Role),
+			"ExpiresAt": i.ExpiresAt.Format(time.RFC3339),
+			"Token":     i.Token,
+		})
+	}
+	w.Flush()
+}
+
+// OrganizationInvitationsCreateFlags define the Create Command
+type OrganizationInvitationsCreateFlags struct {
+	org       string
+	orgID     string
+	username  string
+	email     string
+	owner     bool
+	expiresIn time.Duration
+}
+
+var organizationInvitationsCreateFlags OrganizationInvitationsCreateFlags
+
+func init() {
+	organizationInvitationsCreateCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Invite someone to an organization",
+		RunE:  wrapCheckSetup(organizationInvitationsCreateF),
+	}
+
+	organizationInvitationsCreateCmd.Flags().StringVarP(&organizationInvitationsCreateFlags.org, "org", "o", "", "Name of the organization to invite to")
+	organizationInvitationsCreateCmd.Flags().StringVarP(&organizationInvitationsCreateFlags.orgID, "org-id", "", "", "The ID of the organization to invite to")
+	organizationInvitationsCreateCmd.Flags().StringVarP(&organizationInvitationsCreateFlags.username, "username", "u", "", "Name of the invited user; only that user can accept the invitation")
+	organizationInvitationsCreateCmd.Flags().StringVarP(&organizationInvitationsCreateFlags.email, "email", "e", "", "Email of the invitee")
+	organizationInvitationsCreateCmd.Flags().BoolVarP(&organizationInvitationsCreateFlags.owner, "owner", "", false, "Invite as an owner instead of a member")
+	organizationInvitationsCreateCmd.Flags().DurationVarP(&organizationInvitationsCreateFlags.expiresIn, "expires-in", "", platform.DefaultInvitationExpiry, "How long the invitation can be accepted for")
+
+	organizationInvitationsCmd.AddCommand(organizationInvitationsCreateCmd)
+}
+
+func organizationInvitationsCreateF(cmd *cobra.Command, args []string) error {
+	if organizationInvitationsCreateFlags.username == "" && organizationInvitationsCreateFlags.email == "" {
+		return fmt.Errorf("must specify a username or an email")
+	}
+
+	orgID, err := lookupRoleOrgID(organizationInvitationsCreateFlags.org, organizationInvitationsCreateFlags.orgID)
+	if err != nil {
+		return err
+	}
+
+	i := &platform.Invitation{
+		OrgID:     orgID,
+		Username:  organizationInvitationsCreateFlags.username,
+		Email:     organizationInvitationsCreateFlags.email,
+		Role:      platform.Member,
+		ExpiresAt: time.Now().Add(organizationInvitationsCreateFlags.expiresIn),
+	}
+	if organizationInvitationsCreateFlags.owner {
+		i.Role = platform.Owner
+	}
+
+	if err := newInvitationService(flags).CreateInvitation(context.Background(), i); err != nil {
+		return fmt.Errorf("failed to create invitation: %v", err)
+	}
+
+	writeInvitations(true, i)
+	return nil
+}
+
+// OrganizationInvitationsListFlags define the List Command
+type OrganizationInvitationsListFlags struct {
+	org   string
+	orgID string
+}
+
+var organizationInvitationsListFlags OrganizationInvitationsListFlags
+
+func init() {
+	organizationInvitationsListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List pending invitations",
+		RunE:  wrapCheckSetup(organizationInvitationsListF),
+	}
+
+	organizationInvitationsListCmd.Flags().StringVarP(&organizationInvitationsListFlags.org, "org", "o", "", "Only list invitations to the organization name")
+	organizationInvitationsListCmd.Flags().StringVarP(&organizationInvitationsListFlags.orgID, "org-id", "", "", "Only list invitations to the organization ID")
+
+	organizationInvitationsCmd.AddCommand(organizationInvitationsListCmd)
+}
+
+func organizationInvitationsListF(cmd *cobra.Command, args []string) error {
+	filter := platform.InvitationFilter{}
+	if organizationInvitationsListFlags.org != "" || organizationInvitationsListFlags.orgID != "" {
+		orgID, err := lookupRoleOrgID(organizationInvitationsListFlags.org, organizationInvitationsListFlags.orgID)
+		if err != nil {
+			return err
+		}
+		filter.OrgID = &orgID
+	}
+
+	is, _, err := newInvitationService(flags).FindInvitations(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to list invitations: %v", err)
+	}
+
+	writeInvitations(false, is...)
+	return nil
+}
+
+// OrganizationInvitationsRevokeFlags define the Revoke Command
+type OrganizationInvitationsRevokeFlags struct {
+	id string
+}
+
+var organizationInvitationsRevokeFlags OrganizationInvitationsRevokeFlags
+
+func init() {
+	organizationInvitationsRevokeCmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke an invitation",
+		RunE:  wrapCheckSetup(organizationInvitationsRevokeF),
+	}
+
+	organizationInvitationsRevokeCmd.Flags().StringVarP(&organizationInvitationsRevokeFlags.id, "id", "i", "", "The invitation ID (required)")
+	organizationInvitationsRevokeCmd.MarkFlagRequired("id")
+
+	organizationInvitationsCmd.AddCommand(organizationInvitationsRevokeCmd)
+}
+
+func organizationInvitationsRevokeF(cmd *cobra.Command, args []string) error {
+	id, err := platform.IDFromString(organizationInvitationsRevokeFlags.id)
+	if err != nil {
+		return fmt.Errorf("failed to decode invitation id %q: %v", organizationInvitationsRevokeFlags.id, err)
+	}
+
+	s := newInvitationService(flags)
+	i, err := s.FindInvitationByID(context.Background(), *id)
+	if err != nil {
+		return fmt.Errorf("failed to find invitation: %v", err)
+	}
+
+	if err := s.DeleteInvitation(context.Background(), *id); err != nil {
+		return fmt.Errorf("failed to revoke invitation: %v", err)
+	}
+
+	writeInvitations(false, i)
+	return nil
+}
+
+// OrganizationInvitationsAcceptFlags define the Accept Command
+type OrganizationInvitationsAcceptFlags struct {
+	inviteToken string
+	username    string
+	password    string
+}
+
+var organizationInvitationsAcceptFlags OrganizationInvitationsAcceptFlags
+
+func init() {
+	organizationInvitationsAcceptCmd := &cobra.Command{
+		Use:   "accept",
+		Short: "Accept an invitation",
+		Long: `Accept an invitation as the user of the token, or as a new user when
+a password is given.`,
+		RunE: wrapCheckSetup(organizationInvitationsAcceptF),
+	}
+
+	organizationInvitationsAcceptCmd.Flags().StringVarP(&organizationInvitationsAcceptFlags.inviteToken, "invite-token", "", "", "The token of the invitation (required)")
+	organizationInvitationsAcceptCmd.Flags().StringVarP(&organizationInvitationsAcceptFlags.username, "username", "u", "", "Name of the user to create; defaults to the invited username or email")
+	organizationInvitationsAcceptCmd.Flags().StringVarP(&organizationInvitationsAcceptFlags.password, "password", "p", "", "Password of the user to create")
+	organizationInvitationsAcceptCmd.MarkFlagRequired("invite-token")
+
+	organizationInvitationsCmd.AddCommand(organizationInvitationsAcceptCmd)
+}
+
+func organizationInvitationsAcceptF(cmd *cobra.Command, args []string) error {
+	s := newInvitationService(flags)
+	ctx := context.Background()
+
+	if organizationInvitationsAcceptFlags.password == "" {
+		i, err := s.AcceptInvitation(ctx, organizationInvitationsAcceptFlags.inviteToken)
+		if err != nil {
+			return fmt.Errorf("failed to accept invitation: %v", err)
+		}
+		writeInvitations(false, i)
+		return nil
+	}
+
+	u, err := s.RegisterInvitation(ctx, organizationInvitationsAcceptFlags.inviteToken, organizationInvitationsAcceptFlags.username, organizationInvitationsAcceptFlags.password)
+	if err != nil {
+		return fmt.Errorf("failed to accept invitation: %v", err)
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+	)
+	w.Write(map[string]interface{}{
+		"ID":   u.ID.String(),
+		"Name": u.Name,
+	})
+	w.Flush()
+	return nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -13,6 +14,7 @@ import (
 	"github.com/influxdata/influxdb/http"
 	"github.com/influxdata/influxdb/internal/fs"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/tlsconfig"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -22,17 +24,20 @@ func main() {
 }
 
 var influxCmd = &cobra.Command{
-	Use:   "influx",
-	Short: "Influx Client",
-	Run:   influxF,
+	Use:               "influx",
+	Short:             "Influx Client",
+	Run:               influxF,
+	PersistentPreRunE: configureTLS,
 }
 
 func init() {
 	influxCmd.AddCommand(authorizationCmd)
 	influxCmd.AddCommand(bucketCmd)
+	influxCmd.AddCommand(compactionCmd)
 	influxCmd.AddCommand(organizationCmd)
 	influxCmd.AddCommand(queryCmd)
 	influxCmd.AddCommand(replCmd)
+	influxCmd.AddCommand(roleCmd)
 	influxCmd.AddCommand(setupCmd)
 	influxCmd.AddCommand(taskCmd)
 	influxCmd.AddCommand(userCmd)
@@ -42,9 +47,11 @@ func init() {
 
 // Flags contains all the CLI flag values for influx.
 type Flags struct {
-	token string
-	host  string
-	local bool
+	token      string
+	host       string
+	local      bool
+	caCert     string
+	skipVerify bool
 }
 
 var flags Flags
@@ -95,12 +102,39 @@ func init() {
 
 	influxCmd.PersistentFlags().BoolVar(&flags.local, "local", false, "Run commands locally against the filesystem")
 
+	influxCmd.PersistentFlags().StringVar(&flags.caCert, "ca-cert", "", "Path to PEM encoded certificate authorities trusted to verify the server certificate")
+	viper.BindEnv("CA_CERT")
+	if h := viper.GetString("CA_CERT"); h != "" {
+		flags.caCert = h
+	}
+
+	influxCmd.PersistentFlags().BoolVar(&flags.skipVerify, "skip-verify", false, "Skip verification of the server certificate; insecure")
+	viper.BindEnv("SKIP_VERIFY")
+	if viper.GetBool("SKIP_VERIFY") {
+		flags.skipVerify = true
+	}
+
 	// Override help on all the commands tree
 	walk(influxCmd, func(c *cobra.Command) {
 		c.Flags().BoolP("help", "h", false, fmt.Sprintf("Help for the %s command ", c.Name()))
 	})
 }
 
+// newTLSConfig returns the TLS configuration of the clients set by the flags.
+func newTLSConfig() (*tls.Config, error) {
+	return tlsconfig.NewClientConfig(flags.caCert, flags.skipVerify)
+}
+
+// configureTLS configures the HTTP clients of all commands with the TLS flags.
+func configureTLS(cmd *cobra.Command, args []string) error {
+	c, err := newTLSConfig()
+	if err != nil {
+		return err
+	}
+	http.SetClientTLSConfig(c)
+	return nil
+}
+
 func checkSetup(host string) error {
 	s := &http.SetupService{
 		Addr: flags.host,
@@ -22,8 +22,13 @@ func pingF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("local flag not supported for ping command")
 	}
 
+	tlsConfig, err := newTLSConfig()
+	if err != nil {
+		return err
+	}
 	c := http.Client{
-		Timeout: 5 * time.Second,
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
 	}
 	url := flags.host + "/health"
 	resp, err := c.Get(url)
@@ -1,28 +1,45 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/influxdata/flux/repl"
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/query"
 	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/influxql"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var queryCmd = &cobra.Command{
 	Use:   "query [query literal or @/path/to/query.flux]",
-	Short: "Execute a Flux query",
+	Short: "Execute a Flux or InfluxQL query",
 	Long: `Execute a literal Flux query provided as a string,
-or execute a literal Flux query contained in a file by specifying the file prefixed with an @ sign.`,
+or execute a literal Flux query contained in a file by specifying the file prefixed with an @ sign.
+
+With --type influxql the query is InfluxQL. Measurements are read from the
+--bucket, or from the buckets the --db and --rp are mapped to: the bucket named
+"db/rp", or "db" for the default retention policy.`,
 	Args: cobra.ExactArgs(1),
 	RunE: wrapCheckSetup(fluxQueryF),
 }
 
 var queryFlags struct {
-	OrgID string
-	Org   string
+	OrgID  string
+	Org    string
+	Type   string
+	Bucket string
+	DB     string
+	RP     string
 }
 
 func init() {
@@ -37,6 +54,11 @@ func init() {
 	if h := viper.GetString("ORG"); h != "" {
 		queryFlags.Org = h
 	}
+
+	queryCmd.PersistentFlags().StringVar(&queryFlags.Type, "type", "flux", "The type of the query: flux or influxql")
+	queryCmd.PersistentFlags().StringVarP(&queryFlags.Bucket, "bucket", "b", "", "The bucket of an influxql query")
+	queryCmd.PersistentFlags().StringVar(&queryFlags.DB, "db", "", "The default database of an influxql query")
+	queryCmd.PersistentFlags().StringVar(&queryFlags.RP, "rp", "", "The default retention policy of an influxql query")
 }
 
 func fluxQueryF(cmd *cobra.Command, args []string) error {
@@ -48,6 +70,16 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("must specify exactly one of org or org-id")
 	}
 
+	switch queryFlags.Type {
+	case "flux":
+	case "influxql":
+		if queryFlags.Bucket == "" && queryFlags.DB == "" {
+			return fmt.Errorf("must specify a bucket or db for an influxql query")
+		}
+	default:
+		return fmt.Errorf("invalid query type %q", queryFlags.Type)
+	}
+
 	q, err := repl.LoadQuery(args[0])
 	if err != nil {
 		return fmt.Errorf("failed to load query: %v", err)
@@ -76,6 +108,10 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 		orgID = o.ID
 	}
 
+	if queryFlags.Type == "influxql" {
+		return influxqlQuery(orgID, q)
+	}
+
 	r, err := getFluxREPL(flags.host, flags.token, orgID)
 	if err != nil {
 		return fmt.Errorf("failed to get the flux REPL: %v", err)
@@ -87,3 +123,78 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func influxqlQuery(orgID platform.ID, q string) error {
+	c := influxql.NewCompiler(nil)
+	c.Query = q
+	c.Bucket = queryFlags.Bucket
+	c.DB = queryFlags.DB
+	c.RP = queryFlags.RP
+
+	s := &http.FluxService{
+		Addr:               flags.host,
+		Token:              flags.token,
+		InsecureSkipVerify: flags.skipVerify,
+	}
+	var buf bytes.Buffer
+	if _, err := s.Query(context.Background(), &buf, &query.ProxyRequest{
+		Request: query.Request{
+			OrganizationID: orgID,
+			Compiler:       c,
+		},
+		Dialect: &influxql.Dialect{},
+	}); err != nil {
+		return fmt.Errorf("failed to execute query: %v", err)
+	}
+
+	var resp influxql.Response
+	if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+		return fmt.Errorf("failed to decode query response: %v", err)
+	}
+	if resp.Err != "" {
+		return fmt.Errorf("failed to execute query: %s", resp.Err)
+	}
+
+	for _, res := range resp.Results {
+		if res.Err != "" {
+			return fmt.Errorf("failed to execute statement %d: %s", res.StatementID, res.Err)
+		}
+		for _, row := range res.Series {
+			printInfluxQLRow(row)
+		}
+	}
+	return nil
+}
+
+// printInfluxQLRow prints a series of an InfluxQL result as the influx 1.x
+// shell does.
+func printInfluxQLRow(row *influxql.Row) {
+	fmt.Printf("name: %s\n", row.Name)
+	if len(row.Tags) > 0 {
+		keys := make([]string, 0, len(row.Tags))
+		for k := range row.Tags {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		tags := make([]string, 0, len(keys))
+		for _, k := range keys {
+			tags = append(tags, k+"="+row.Tags[k])
+		}
+		fmt.Printf("tags: %s\n", strings.Join(tags, ", "))
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(row.Columns...)
+	for _, values := range row.Values {
+		m := make(map[string]interface{}, len(values))
+		for i, v := range values {
+			if i < len(row.Columns) {
+				m[row.Columns[i]] = v
+			}
+		}
+		w.Write(m)
+	}
+	w.Flush()
+	fmt.Println()
+}
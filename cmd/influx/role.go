@@ -0,0 +1,489 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/spf13/cobra"
+)
+
+// Role Command
+var roleCmd = &cobra.Command{
+	Use:   "role",
+	Short: "Role management commands",
+	Run:   roleF,
+}
+
+func roleF(cmd *cobra.Command, args []string) {
+	cmd.Usage()
+}
+
+func newRoleService(f Flags) (platform.RoleService, error) {
+	if flags.local {
+		return newLocalKVService()
+	}
+	return &http.RoleService{
+		Addr:  flags.host,
+		Token: flags.token,
+	}, nil
+}
+
+// parseRolePermissions parses permissions of the form action:resource or
+// action:resource/id, such as read:buckets or write:dashboards/<id>, scoped
+// to the organization of the role.
+func parseRolePermissions(orgID platform.ID, ss []string) ([]platform.Permission, error) {
+	ps := make([]platform.Permission, 0, len(ss))
+	for _, s := range ss {
+		parts := strings.SplitN(s, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid permission %q: must be action:resource", s)
+		}
+
+		p := platform.Permission{Action: platform.Action(parts[0])}
+		res := strings.SplitN(parts[1], "/", 2)
+		p.Resource.Type = platform.ResourceType(res[0])
+		if len(res) == 2 {
+			id, err := platform.IDFromString(res[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid permission %q: %v", s, err)
+			}
+			p.Resource.ID = id
+		}
+
+		if p.Resource.Type == platform.OrgsResourceType {
+			if p.Resource.ID != nil && *p.Resource.ID != orgID {
+				return nil, fmt.Errorf("invalid permission %q: not the organization of the role", s)
+			}
+			p.Resource.ID = &orgID
+		} else {
+			p.Resource.OrgID = &orgID
+		}
+
+		if err := p.Valid(); err != nil {
+			return nil, fmt.Errorf("invalid permission %q: %v", s, err)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+func writeRoles(roles ...*platform.Role) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"Name",
+		"OrgID",
+		"Description",
+		"Permissions",
+	)
+	for _, r := range roles {
+		ps := make([]string, 0, len(r.Permissions))
+		for _, p := range r.Permissions {
+			ps = append(ps, p.String())
+		}
+		w.Write(map[string]interface{}{
+			"ID":          r.ID.String(),
+			"Name":        r.Name,
+			"OrgID":       r.OrgID.String(),
+			"Description": r.Description,
+			"Permissions": ps,
+		})
+	}
+	w.Flush()
+}
+
+// lookupRoleOrgID returns the ID of the organization given by exactly one of name or id.
+func lookupRoleOrgID(org, orgID string) (platform.ID, error) {
+	if (org == "" && orgID == "") || (org != "" && orgID != "") {
+		return 0, fmt.Errorf("must specify exactly one of org or org-id")
+	}
+
+	if orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode org id %q: %v", orgID, err)
+		}
+		return *id, nil
+	}
+
+	s, err := newOrganizationService(flags)
+	if err != nil {
+		return 0, fmt.Errorf("failed to initialize organization service client: %v", err)
+	}
+	o, err := s.FindOrganization(context.Background(), platform.OrganizationFilter{Name: &org})
+	if err != nil {
+		return 0, fmt.Errorf("failed to find organization %q: %v", org, err)
+	}
+	return o.ID, nil
+}
+
+// RoleCreateFlags define the Create Command
+type RoleCreateFlags struct {
+	name        string
+	description string
+	org         string
+	orgID       string
+	permissions []string
+}
+
+var roleCreateFlags RoleCreateFlags
+
+func init() {
+	roleCreateCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create role",
+		RunE:  wrapCheckSetup(roleCreateF),
+	}
+
+	roleCreateCmd.Flags().StringVarP(&roleCreateFlags.name, "name", "n", "", "Name of the role")
+	roleCreateCmd.Flags().StringVarP(&roleCreateFlags.description, "description", "d", "", "Description of the role")
+	roleCreateCmd.Flags().StringVarP(&roleCreateFlags.org, "org", "o", "", "Name of the organization that owns the role")
+	roleCreateCmd.Flags().StringVarP(&roleCreateFlags.orgID, "org-id", "", "", "The ID of the organization that owns the role")
+	roleCreateCmd.Flags().StringArrayVarP(&roleCreateFlags.permissions, "permission", "p", nil, "Permission granted by the role, as action:resource[/id] (e.g. read:buckets)")
+	roleCreateCmd.MarkFlagRequired("name")
+
+	roleCmd.AddCommand(roleCreateCmd)
+}
+
+func roleCreateF(cmd *cobra.Command, args []string) error {
+	orgID, err := lookupRoleOrgID(roleCreateFlags.org, roleCreateFlags.orgID)
+	if err != nil {
+		return err
+	}
+
+	ps, err := parseRolePermissions(orgID, roleCreateFlags.permissions)
+	if err != nil {
+		return err
+	}
+
+	s, err := newRoleService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize role service client: %v", err)
+	}
+
+	r := &platform.Role{
+		OrgID:       orgID,
+		Name:        roleCreateFlags.name,
+		Description: roleCreateFlags.description,
+		Permissions: ps,
+	}
+	if err := s.CreateRole(context.Background(), r); err != nil {
+		return fmt.Errorf("failed to create role: %v", err)
+	}
+
+	writeRoles(r)
+	return nil
+}
+
+// RoleFindFlags define the Find Command
+type RoleFindFlags struct {
+	id    string
+	name  string
+	org   string
+	orgID string
+}
+
+var roleFindFlags RoleFindFlags
+
+func init() {
+	roleFindCmd := &cobra.Command{
+		Use:   "find",
+		Short: "Find roles",
+		RunE:  wrapCheckSetup(roleFindF),
+	}
+
+	roleFindCmd.Flags().StringVarP(&roleFindFlags.id, "id", "i", "", "The role ID")
+	roleFindCmd.Flags().StringVarP(&roleFindFlags.name, "name", "n", "", "The role name")
+	roleFindCmd.Flags().StringVarP(&roleFindFlags.org, "org", "o", "", "The role organization name")
+	roleFindCmd.Flags().StringVarP(&roleFindFlags.orgID, "org-id", "", "", "The role organization ID")
+
+	roleCmd.AddCommand(roleFindCmd)
+}
+
+func roleFindF(cmd *cobra.Command, args []string) error {
+	filter := platform.RoleFilter{}
+	if roleFindFlags.id != "" {
+		id, err := platform.IDFromString(roleFindFlags.id)
+		if err != nil {
+			return fmt.Errorf("failed to decode role id %q: %v", roleFindFlags.id, err)
+		}
+		filter.ID = id
+	}
+	if roleFindFlags.name != "" {
+		filter.Name = &roleFindFlags.name
+	}
+	if roleFindFlags.org != "" || roleFindFlags.orgID != "" {
+		orgID, err := lookupRoleOrgID(roleFindFlags.org, roleFindFlags.orgID)
+		if err != nil {
+			return err
+		}
+		filter.OrgID = &orgID
+	}
+
+	s, err := newRoleService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize role service client: %v", err)
+	}
+
+	roles, _, err := s.FindRoles(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve roles: %v", err)
+	}
+
+	writeRoles(roles...)
+	return nil
+}
+
+// RoleUpdateFlags define the Update Command
+type RoleUpdateFlags struct {
+	id          string
+	name        string
+	description string
+	permissions []string
+}
+
+var roleUpdateFlags RoleUpdateFlags
+
+func init() {
+	roleUpdateCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update role",
+		RunE:  wrapCheckSetup(roleUpdateF),
+	}
+
+	roleUpdateCmd.Flags().StringVarP(&roleUpdateFlags.id, "id", "i", "", "The role ID (required)")
+	roleUpdateCmd.Flags().StringVarP(&roleUpdateFlags.name, "name", "n", "", "New name of the role")
+	roleUpdateCmd.Flags().StringVarP(&roleUpdateFlags.description, "description", "d", "", "New description of the role")
+	roleUpdateCmd.Flags().StringArrayVarP(&roleUpdateFlags.permissions, "permission", "p", nil, "Permissions replacing those of the role, as action:resource[/id]")
+	roleUpdateCmd.MarkFlagRequired("id")
+
+	roleCmd.AddCommand(roleUpdateCmd)
+}
+
+func roleUpdateF(cmd *cobra.Command, args []string) error {
+	s, err := newRoleService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize role service client: %v", err)
+	}
+
+	id, err := platform.IDFromString(roleUpdateFlags.id)
+	if err != nil {
+		return fmt.Errorf("failed to decode role id %q: %v", roleUpdateFlags.id, err)
+	}
+
+	upd := platform.RoleUpdate{}
+	if cmd.Flags().Changed("name") {
+		upd.Name = &roleUpdateFlags.name
+	}
+	if cmd.Flags().Changed("description") {
+		upd.Description = &roleUpdateFlags.description
+	}
+	if cmd.Flags().Changed("permission") {
+		r, err := s.FindRoleByID(context.Background(), *id)
+		if err != nil {
+			return fmt.Errorf("failed to find role: %v", err)
+		}
+		ps, err := parseRolePermissions(r.OrgID, roleUpdateFlags.permissions)
+		if err != nil {
+			return err
+		}
+		upd.Permissions = &ps
+	}
+
+	r, err := s.UpdateRole(context.Background(), *id, upd)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %v", err)
+	}
+
+	writeRoles(r)
+	return nil
+}
+
+// RoleDeleteFlags define the Delete Command
+type RoleDeleteFlags struct {
+	id string
+}
+
+var roleDeleteFlags RoleDeleteFlags
+
+func init() {
+	roleDeleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete role",
+		RunE:  wrapCheckSetup(roleDeleteF),
+	}
+
+	roleDeleteCmd.Flags().StringVarP(&roleDeleteFlags.id, "id", "i", "", "The role ID (required)")
+	roleDeleteCmd.MarkFlagRequired("id")
+
+	roleCmd.AddCommand(roleDeleteCmd)
+}
+
+func roleDeleteF(cmd *cobra.Command, args []string) error {
+	s, err := newRoleService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize role service client: %v", err)
+	}
+
+	id, err := platform.IDFromString(roleDeleteFlags.id)
+	if err != nil {
+		return fmt.Errorf("failed to decode role id %q: %v", roleDeleteFlags.id, err)
+	}
+
+	ctx := context.Background()
+	r, err := s.FindRoleByID(ctx, *id)
+	if err != nil {
+		return fmt.Errorf("failed to find role: %v", err)
+	}
+
+	if err := s.DeleteRole(ctx, *id); err != nil {
+		return fmt.Errorf("failed to delete role: %v", err)
+	}
+
+	writeRoles(r)
+	return nil
+}
+
+// RoleMemberFlags define the Assign, Unassign and Members Commands
+type RoleMemberFlags struct {
+	id              string
+	userID          string
+	authorizationID string
+}
+
+var roleMemberFlags RoleMemberFlags
+
+func init() {
+	roleAssignCmd := &cobra.Command{
+		Use:   "assign",
+		Short: "Assign role to a user or authorization",
+		RunE:  wrapCheckSetup(roleAssignF),
+	}
+	roleUnassignCmd := &cobra.Command{
+		Use:   "unassign",
+		Short: "Remove role from a user or authorization",
+		RunE:  wrapCheckSetup(roleUnassignF),
+	}
+	for _, cmd := range []*cobra.Command{roleAssignCmd, roleUnassignCmd} {
+		cmd.Flags().StringVarP(&roleMemberFlags.id, "id", "i", "", "The role ID (required)")
+		cmd.Flags().StringVarP(&roleMemberFlags.userID, "user-id", "u", "", "The user ID")
+		cmd.Flags().StringVarP(&roleMemberFlags.authorizationID, "authorization-id", "a", "", "The authorization ID")
+		cmd.MarkFlagRequired("id")
+		roleCmd.AddCommand(cmd)
+	}
+
+	roleMembersCmd := &cobra.Command{
+		Use:   "members",
+		Short: "List users and authorizations a role is assigned to",
+		RunE:  wrapCheckSetup(roleMembersF),
+	}
+	roleMembersCmd.Flags().StringVarP(&roleMemberFlags.id, "id", "i", "", "The role ID (required)")
+	roleMembersCmd.MarkFlagRequired("id")
+	roleCmd.AddCommand(roleMembersCmd)
+}
+
+func decodeRoleMapping() (*platform.RoleMapping, error) {
+	roleID, err := platform.IDFromString(roleMemberFlags.id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode role id %q: %v", roleMemberFlags.id, err)
+	}
+
+	if (roleMemberFlags.userID == "") == (roleMemberFlags.authorizationID == "") {
+		return nil, fmt.Errorf("must specify exactly one of user-id or authorization-id")
+	}
+
+	m := &platform.RoleMapping{RoleID: *roleID}
+	subject := roleMemberFlags.userID
+	m.SubjectType = platform.UsersResourceType
+	if roleMemberFlags.authorizationID != "" {
+		subject = roleMemberFlags.authorizationID
+		m.SubjectType = platform.AuthorizationsResourceType
+	}
+
+	subjectID, err := platform.IDFromString(subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s id %q: %v", m.SubjectType, subject, err)
+	}
+	m.SubjectID = *subjectID
+	return m, nil
+}
+
+func writeRoleMappings(ms ...*platform.RoleMapping) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"RoleID",
+		"SubjectID",
+		"SubjectType",
+	)
+	for _, m := range ms {
+		w.Write(map[string]interface{}{
+			"RoleID":      m.RoleID.String(),
+			"SubjectID":   m.SubjectID.String(),
+			"SubjectType": m.SubjectType,
+		})
+	}
+	w.Flush()
+}
+
+func roleAssignF(cmd *cobra.Command, args []string) error {
+	m, err := decodeRoleMapping()
+	if err != nil {
+		return err
+	}
+
+	s, err := newRoleService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize role service client: %v", err)
+	}
+
+	if err := s.CreateRoleMapping(context.Background(), m); err != nil {
+		return fmt.Errorf("failed to assign role: %v", err)
+	}
+
+	writeRoleMappings(m)
+	return nil
+}
+
+func roleUnassignF(cmd *cobra.Command, args []string) error {
+	m, err := decodeRoleMapping()
+	if err != nil {
+		return err
+	}
+
+	s, err := newRoleService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize role service client: %v", err)
+	}
+
+	if err := s.DeleteRoleMapping(context.Background(), m.RoleID, m.SubjectID); err != nil {
+		return fmt.Errorf("failed to remove role: %v", err)
+	}
+
+	writeRoleMappings(m)
+	return nil
+}
+
+func roleMembersF(cmd *cobra.Command, args []string) error {
+	s, err := newRoleService(flags)
+	if err != nil {
+		return fmt.Errorf("failed to initialize role service client: %v", err)
+	}
+
+	id, err := platform.IDFromString(roleMemberFlags.id)
+	if err != nil {
+		return fmt.Errorf("failed to decode role id %q: %v", roleMemberFlags.id, err)
+	}
+
+	ms, _, err := s.FindRoleMappings(context.Background(), platform.RoleMappingFilter{RoleID: id})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve role members: %v", err)
+	}
+
+	writeRoleMappings(ms...)
+	return nil
+}
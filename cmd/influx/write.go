@@ -20,17 +20,25 @@ var writeCmd = &cobra.Command{
 	Use:   "write line protocol or @/path/to/points.txt",
 	Short: "Write points to InfluxDB",
 	Long: `Write a single line of line protocol to InfluxDB,
-or add an entire file specified with an @ prefix.`,
+or add an entire file specified with an @ prefix.
+
+With --format csv or json the data is annotated CSV, as returned by
+queries, or JSON points of the form
+{"measurement": "m", "tags": {"t": "v"}, "fields": {"f": 1}, "time": "2019-10-01T00:00:00Z"}.
+JSON numbers are written as floats; write integers as typed values such as
+{"type": "integer", "value": 1}.`,
 	Args: cobra.ExactArgs(1),
 	RunE: wrapCheckSetup(fluxWriteF),
 }
 
 var writeFlags struct {
-	OrgID     string
-	Org       string
-	BucketID  string
-	Bucket    string
-	Precision string
+	OrgID       string
+	Org         string
+	BucketID    string
+	Bucket      string
+	Precision   string
+	Consistency string
+	Format      string
 }
 
 func init() {
@@ -63,6 +71,9 @@ func init() {
 	if p := viper.GetString("PRECISION"); p != "" {
 		writeFlags.Precision = p
 	}
+
+	writeCmd.PersistentFlags().StringVar(&writeFlags.Consistency, "consistency", "", "Durability of the points when the write returns: buffered, wal, or fsync (default fsync)")
+	writeCmd.PersistentFlags().StringVar(&writeFlags.Format, "format", "lp", "Format of the data: lp, csv, or json")
 }
 
 func fluxWriteF(cmd *cobra.Command, args []string) error {
@@ -83,6 +94,13 @@ func fluxWriteF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid precision")
 	}
 
+	switch writeFlags.Format {
+	case "lp", "csv", "json":
+	default:
+		cmd.Usage()
+		return fmt.Errorf("invalid format %q", writeFlags.Format)
+	}
+
 	bs := &http.BucketService{
 		Addr:  flags.host,
 		Token: flags.token,
@@ -142,12 +160,19 @@ func fluxWriteF(cmd *cobra.Command, args []string) error {
 		r = strings.NewReader(args[0])
 	}
 
-	s := write.Batcher{
-		Service: &http.WriteService{
-			Addr:      flags.host,
-			Token:     flags.token,
-			Precision: writeFlags.Precision,
-		},
+	ws := &http.WriteService{
+		Addr:        flags.host,
+		Token:       flags.token,
+		Precision:   writeFlags.Precision,
+		Consistency: writeFlags.Consistency,
+		Format:      writeFlags.Format,
+	}
+
+	// Only line protocol can be split into batches by line; the server
+	// streams CSV and JSON in batches itself.
+	var s platform.WriteService = ws
+	if writeFlags.Format == "lp" {
+		s = &write.Batcher{Service: ws}
 	}
 
 	ctx = signals.WithStandardSignals(ctx)
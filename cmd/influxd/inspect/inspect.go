@@ -1,13 +1,24 @@
 package inspect
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/pkg/csv2lp"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/spf13/cobra"
 )
@@ -66,7 +77,129 @@ in the following ways:
 	dir = filepath.Join(dir, "engine/data")
 	reportTSMCommand.Flags().StringVarP(&reportTSMFlags.dataDir, "data-dir", "", dir, fmt.Sprintf("use provided data directory (defaults to %s).", dir))
 
+	verifyIndexCommand := &cobra.Command{
+		Use:   "verify-index",
+		Short: "Verify the consistency of the TSI index",
+		Long: `
+This command cross-checks the TSI index of a storage engine against the series
+file and the series keys found in the TSM and WAL files. The storage engine must
+not be running.
+
+The following inconsistencies are reported:
+
+	* Series with data that are missing from the series file;
+	* Series with data that are missing from the index;
+	* Series stored in the wrong index partition;
+	* Series in the index that are unknown to the series file; and
+	* Series in the index that have no data.
+
+Inconsistent index partitions can be rebuilt without downtime by a running
+server using the /api/v2/storage/index/rebuild endpoint.`,
+		RunE: inspectVerifyIndexF,
+	}
+
+	verifyIndexCommand.Flags().StringVarP(&verifyIndexFlags.enginePath, "engine-path", "", filepath.Dir(dir), "path to the storage engine")
+	verifyIndexCommand.Flags().BoolVarP(&verifyIndexFlags.verbose, "verbose", "v", false, "print every inconsistent series")
+	verifyIndexCommand.Flags().StringVarP(&verifyIndexFlags.keyFile, "storage-encryption-key-file", "", "", "keyfile of encrypted TSM, WAL and index files")
+
+	verifyTSMCommand := &cobra.Command{
+		Use:   "verify-tsm",
+		Short: "Verify the integrity of TSM files",
+		Long: `
+This command verifies the TSM files within a storage engine directory. The
+storage engine must not be running.
+
+For each file, the following are verified:
+
+	* The tombstone file can be read;
+	* The keys in the index are in order;
+	* The blocks of each key are in order; and
+	* The checksum of each block matches and the block can be decoded.
+
+With the --repair flag, files with corrupt blocks or index entries are
+rewritten without them. Files without any valid blocks are removed.`,
+		RunE: inspectVerifyTSMF,
+	}
+
+	verifyTSMCommand.Flags().StringVarP(&verifyTSMFlags.dataDir, "data-dir", "", dir, fmt.Sprintf("use provided data directory (defaults to %s).", dir))
+	verifyTSMCommand.Flags().StringVarP(&verifyTSMFlags.pattern, "pattern", "", "", "only process TSM files containing pattern")
+	verifyTSMCommand.Flags().BoolVarP(&verifyTSMFlags.repair, "repair", "", false, "rewrite corrupt files without their corrupt blocks")
+	verifyTSMCommand.Flags().StringVarP(&verifyTSMFlags.format, "format", "", "text", "output format: text or json")
+	verifyTSMCommand.Flags().StringVarP(&verifyTSMFlags.keyFile, "storage-encryption-key-file", "", "", "keyfile of encrypted TSM files")
+
+	walDir := filepath.Join(filepath.Dir(dir), "wal")
+	verifyWALCommand := &cobra.Command{
+		Use:   "verify-wal",
+		Short: "Verify the integrity of WAL segment files",
+		Long: `
+This command reads every entry of the WAL segment files within a storage engine
+directory and reports where any corruption begins. The storage engine must not
+be running.
+
+With the --repair flag, corrupt segments are truncated after their last valid
+entry.`,
+		RunE: inspectVerifyWALF,
+	}
+
+	verifyWALCommand.Flags().StringVarP(&verifyWALFlags.walDir, "wal-dir", "", walDir, fmt.Sprintf("use provided WAL directory (defaults to %s).", walDir))
+	verifyWALCommand.Flags().BoolVarP(&verifyWALFlags.repair, "repair", "", false, "truncate corrupt segments after their last valid entry")
+	verifyWALCommand.Flags().StringVarP(&verifyWALFlags.format, "format", "", "text", "output format: text or json")
+	verifyWALCommand.Flags().StringVarP(&verifyWALFlags.keyFile, "storage-encryption-key-file", "", "", "keyfile of encrypted WAL segments")
+
+	exportCommand := &cobra.Command{
+		Use:   "export",
+		Short: "Export data as line protocol or annotated CSV",
+		Long: `
+This command exports data directly from the TSM and WAL files of a storage
+engine, without running queries. The storage engine should not be running.
+
+Data is read a block at a time, first from the TSM files and then from the WAL,
+so a series may appear more than once in the output. Writing the output back in
+order restores the data exactly. When exporting line protocol, the org and
+bucket of the data that follows are written as comments.`,
+		RunE: inspectExportF,
+	}
+
+	exportCommand.Flags().StringVarP(&exportFlags.enginePath, "engine-path", "", filepath.Dir(dir), "path to the storage engine")
+	exportCommand.Flags().StringVarP(&exportFlags.orgID, "org-id", "", "", "export only data belonging to organization ID")
+	exportCommand.Flags().StringVarP(&exportFlags.bucketID, "bucket-id", "", "", "export only data belonging to bucket ID. Requires org flag to be set.")
+	exportCommand.Flags().StringVarP(&exportFlags.measurement, "measurement", "", "", "export only data belonging to measurement")
+	exportCommand.Flags().StringVarP(&exportFlags.start, "start", "", "", "export only data at or after the RFC3339 time")
+	exportCommand.Flags().StringVarP(&exportFlags.end, "end", "", "", "export only data at or before the RFC3339 time")
+	exportCommand.Flags().StringVarP(&exportFlags.format, "format", "", storage.ExportLineProtocol, "output format: lp or csv")
+	exportCommand.Flags().BoolVarP(&exportFlags.compress, "compress", "", false, "compress the output with gzip")
+	exportCommand.Flags().StringVarP(&exportFlags.output, "output", "o", "", "write to the file instead of stdout")
+	exportCommand.Flags().StringVarP(&exportFlags.keyFile, "storage-encryption-key-file", "", "", "keyfile of encrypted TSM and WAL files")
+
+	importCommand := &cobra.Command{
+		Use:   "import",
+		Short: "Bulk import line protocol or annotated CSV directly into TSM files",
+		Long: `
+This command writes data directly to new TSM files, creating any new series in
+the series file and index, and adds the files to the storage engine once every
+point has been imported. The write-ahead log is bypassed, so this is much faster
+than writing the data through the API. The storage engine should not be running;
+use the /api/v2/storage/import endpoint to import into a running server.
+
+Points replace existing points with the same series, field and timestamp. The
+bucket is not checked against the metadata store, so it must already exist.`,
+		RunE: inspectImportF,
+	}
+
+	importCommand.Flags().StringVarP(&importFlags.enginePath, "engine-path", "", filepath.Dir(dir), "path to the storage engine")
+	importCommand.Flags().StringVarP(&importFlags.orgID, "org-id", "", "", "organization ID of the bucket to import into")
+	importCommand.Flags().StringVarP(&importFlags.bucketID, "bucket-id", "", "", "ID of the bucket to import into")
+	importCommand.Flags().StringVarP(&importFlags.file, "file", "f", "", "read from the file instead of stdin")
+	importCommand.Flags().StringVarP(&importFlags.format, "format", "", "lp", "input format: lp or csv")
+	importCommand.Flags().BoolVarP(&importFlags.compressed, "compressed", "", false, "the input is compressed with gzip. Implied by a file ending in .gz")
+	importCommand.Flags().StringVarP(&importFlags.keyFile, "storage-encryption-key-file", "", "", "keyfile of the storage engine; imported files are encrypted with its last key")
+
 	base.AddCommand(reportTSMCommand)
+	base.AddCommand(verifyIndexCommand)
+	base.AddCommand(verifyTSMCommand)
+	base.AddCommand(verifyWALCommand)
+	base.AddCommand(exportCommand)
+	base.AddCommand(importCommand)
 	return base
 }
 
@@ -114,3 +247,354 @@ func inspectReportTSMF(cmd *cobra.Command, args []string) error {
 	_, err := report.Run(true)
 	return err
 }
+
+// verifyIndexFlags defines the `verify-index` Command.
+var verifyIndexFlags = struct {
+	enginePath string
+	verbose    bool
+	keyFile    string
+}{}
+
+// inspectVerifyIndexF runs the verify-index tool.
+func inspectVerifyIndexF(cmd *cobra.Command, args []string) error {
+	keyring, err := loadKeyring(verifyIndexFlags.keyFile)
+	if err != nil {
+		return err
+	}
+
+	verify := &storage.VerifyIndex{
+		Stdout:  os.Stdout,
+		Path:    verifyIndexFlags.enginePath,
+		Config:  storage.NewConfig(),
+		Verbose: verifyIndexFlags.verbose,
+		Keyring: keyring,
+	}
+
+	result, err := verify.Run()
+	if err != nil {
+		return err
+	}
+
+	if n := len(result.Inconsistencies); n > 0 {
+		return fmt.Errorf("index has %d inconsistent series", n)
+	}
+	return nil
+}
+
+// verifyTSMFlags defines the `verify-tsm` Command.
+var verifyTSMFlags = struct {
+	dataDir string
+	pattern string
+	repair  bool
+	format  string
+	keyFile string
+}{}
+
+// inspectVerifyTSMF runs the verify-tsm tool.
+func inspectVerifyTSMF(cmd *cobra.Command, args []string) error {
+	if err := validateFormat(verifyTSMFlags.format); err != nil {
+		return err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(verifyTSMFlags.dataDir, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+
+	keyring, err := loadKeyring(verifyTSMFlags.keyFile)
+	if err != nil {
+		return err
+	}
+
+	verify := &tsm1.VerifyTSM{Repair: verifyTSMFlags.repair, Keyring: keyring}
+	for _, path := range paths {
+		if strings.Contains(path, verifyTSMFlags.pattern) {
+			verify.Paths = append(verify.Paths, path)
+		}
+	}
+
+	results, err := verify.Run()
+	if err != nil {
+		return err
+	}
+
+	var corrupt int
+	for _, r := range results {
+		if !r.Healthy() && !r.Repaired && !r.Removed {
+			corrupt++
+		}
+	}
+
+	if verifyTSMFlags.format == "json" {
+		if err := writeJSON(results); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 8, 2, 1, ' ', 0)
+		fmt.Fprintln(tw, strings.Join([]string{"File", "Blocks", "Status", "Key", "Offset", "Problem"}, "\t"))
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t\t\t\n", r.Path, r.Blocks, tsmStatus(r))
+			for _, p := range r.Problems {
+				fmt.Fprintf(tw, "\t\t%s\t%q\t%d\t%s\n", p.Kind, p.Key, p.Offset, p.Error)
+			}
+		}
+		tw.Flush()
+	}
+
+	if corrupt > 0 {
+		return fmt.Errorf("%d of %d TSM files are corrupt", corrupt, len(results))
+	}
+	return nil
+}
+
+func tsmStatus(r *tsm1.TSMVerification) string {
+	switch {
+	case r.Removed:
+		return "removed"
+	case r.Repaired:
+		return "repaired"
+	case !r.Healthy():
+		return "corrupt"
+	default:
+		return "healthy"
+	}
+}
+
+// verifyWALFlags defines the `verify-wal` Command.
+var verifyWALFlags = struct {
+	walDir  string
+	repair  bool
+	format  string
+	keyFile string
+}{}
+
+// inspectVerifyWALF runs the verify-wal tool.
+func inspectVerifyWALF(cmd *cobra.Command, args []string) error {
+	if err := validateFormat(verifyWALFlags.format); err != nil {
+		return err
+	}
+
+	paths, err := wal.SegmentFileNames(verifyWALFlags.walDir)
+	if err != nil {
+		return err
+	}
+
+	keyring, err := loadKeyring(verifyWALFlags.keyFile)
+	if err != nil {
+		return err
+	}
+
+	verify := &wal.Verify{Paths: paths, Repair: verifyWALFlags.repair, Keyring: keyring}
+	results, err := verify.Run()
+	if err != nil {
+		return err
+	}
+
+	var corrupt int
+	for _, r := range results {
+		if !r.Healthy() && !r.Truncated {
+			corrupt++
+		}
+	}
+
+	if verifyWALFlags.format == "json" {
+		if err := writeJSON(results); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 8, 2, 1, ' ', 0)
+		fmt.Fprintln(tw, strings.Join([]string{"File", "Entries", "Size", "Valid Size", "Status", "Problem"}, "\t"))
+		for _, r := range results {
+			status := "healthy"
+			if r.Truncated {
+				status = "truncated"
+			} else if !r.Healthy() {
+				status = "corrupt"
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\n", r.Path, r.Entries, r.Size, r.ValidSize, status, r.Error)
+		}
+		tw.Flush()
+	}
+
+	if corrupt > 0 {
+		return fmt.Errorf("%d of %d WAL segments are corrupt", corrupt, len(results))
+	}
+	return nil
+}
+
+func validateFormat(format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format %q: must be text or json", format)
+	}
+	return nil
+}
+
+// loadKeyring loads the storage encryption keys from the keyfile at path, if
+// one is given.
+func loadKeyring(path string) (*encryption.Keyring, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return encryption.LoadKeyring(path)
+}
+
+func writeJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "\t")
+	return enc.Encode(v)
+}
+
+// exportFlags defines the `export` Command.
+var exportFlags = struct {
+	enginePath      string
+	orgID, bucketID string
+	measurement     string
+	start, end      string
+	format          string
+	compress        bool
+	output          string
+	keyFile         string
+}{}
+
+// inspectExportF runs the export tool.
+func inspectExportF(cmd *cobra.Command, args []string) error {
+	export := storage.NewExport(exportFlags.enginePath)
+	export.Measurement = exportFlags.measurement
+	export.Format = exportFlags.format
+	export.Compress = exportFlags.compress
+
+	keyring, err := loadKeyring(exportFlags.keyFile)
+	if err != nil {
+		return err
+	}
+	export.Keyring = keyring
+
+	if exportFlags.orgID == "" && exportFlags.bucketID != "" {
+		return errors.New("org-id must be set for non-empty bucket-id")
+	}
+
+	if exportFlags.orgID != "" {
+		orgID, err := influxdb.IDFromString(exportFlags.orgID)
+		if err != nil {
+			return err
+		}
+		export.OrgID = orgID
+	}
+
+	if exportFlags.bucketID != "" {
+		bucketID, err := influxdb.IDFromString(exportFlags.bucketID)
+		if err != nil {
+			return err
+		}
+		export.BucketID = bucketID
+	}
+
+	if exportFlags.start != "" {
+		t, err := time.Parse(time.RFC3339Nano, exportFlags.start)
+		if err != nil {
+			return fmt.Errorf("invalid start time: %v", err)
+		}
+		export.Start = t.UnixNano()
+	}
+
+	if exportFlags.end != "" {
+		t, err := time.Parse(time.RFC3339Nano, exportFlags.end)
+		if err != nil {
+			return fmt.Errorf("invalid end time: %v", err)
+		}
+		export.End = t.UnixNano()
+	}
+
+	if exportFlags.output == "" {
+		export.Out = os.Stdout
+		return export.Run()
+	}
+
+	f, err := os.Create(exportFlags.output)
+	if err != nil {
+		return err
+	}
+	export.Out = f
+
+	if err := export.Run(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// importFlags defines the `import` Command.
+var importFlags = struct {
+	enginePath      string
+	orgID, bucketID string
+	file            string
+	format          string
+	compressed      bool
+	keyFile         string
+}{}
+
+// inspectImportF runs the import tool.
+func inspectImportF(cmd *cobra.Command, args []string) error {
+	if importFlags.format != "lp" && importFlags.format != "csv" {
+		return fmt.Errorf("invalid format %q", importFlags.format)
+	}
+
+	if importFlags.orgID == "" || importFlags.bucketID == "" {
+		return errors.New("org-id and bucket-id must be set")
+	}
+	orgID, err := influxdb.IDFromString(importFlags.orgID)
+	if err != nil {
+		return err
+	}
+	bucketID, err := influxdb.IDFromString(importFlags.bucketID)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if importFlags.file != "" {
+		f, err := os.Open(importFlags.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if importFlags.compressed || strings.HasSuffix(importFlags.file, ".gz") {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	}
+	if importFlags.format == "csv" {
+		r = csv2lp.NewReader(r)
+	}
+
+	var options []storage.Option
+	keyring, err := loadKeyring(importFlags.keyFile)
+	if err != nil {
+		return err
+	} else if keyring != nil {
+		options = append(options, storage.WithKeyring(keyring))
+	}
+
+	engine := storage.NewEngine(importFlags.enginePath, storage.NewConfig(), options...)
+	if err := engine.Open(context.Background()); err != nil {
+		return err
+	}
+
+	n, err := engine.Import(context.Background(), *orgID, *bucketID, r)
+	if err != nil {
+		engine.Close()
+		return err
+	}
+	if err := engine.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d points\n", n)
+	return nil
+}
@@ -0,0 +1,94 @@
+package launcher_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+	"github.com/influxdata/influxdb/query/influxql"
+)
+
+func TestLauncher_InfluxQL(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `
+cpu,host=a value=1 946684800000000000
+cpu,host=a value=2 946684810000000000
+cpu,host=b value=3 946684800000000000
+`)
+
+	query := func(t *testing.T, body string) []byte {
+		t.Helper()
+		req := l.MustNewHTTPRequest("POST", fmt.Sprintf("/api/v2/query?orgID=%s", l.Org.ID), body)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != nethttp.StatusOK {
+			t.Fatalf("unexpected status code: %d, body: %s", resp.StatusCode, b)
+		}
+		return b
+	}
+
+	want := influxql.Response{
+		Results: []influxql.Result{{
+			StatementID: 0,
+			Series: []*influxql.Row{{
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "a"},
+				Columns: []string{"time", "max"},
+				Values:  [][]interface{}{{"2000-01-01T00:00:10Z", float64(2)}},
+			}, {
+				Name:    "cpu",
+				Tags:    map[string]string{"host": "b"},
+				Columns: []string{"time", "max"},
+				Values:  [][]interface{}{{"2000-01-01T00:00:00Z", float64(3)}},
+			}},
+		}},
+	}
+	const q = `SELECT max(value) FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-02T00:00:00Z' GROUP BY host`
+
+	for _, tt := range []struct {
+		name string
+		body string
+	}{
+		{
+			name: "bucket",
+			body: fmt.Sprintf(`{"type": "influxql", "query": %q, "bucket": %q}`, q, l.Bucket.Name),
+		},
+		{
+			name: "database mapped to a bucket",
+			body: fmt.Sprintf(`{"type": "influxql", "query": %q, "db": %q}`, q, l.Bucket.Name),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got influxql.Response
+			if err := json.Unmarshal(query(t, tt.body), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(want, got) {
+				t.Errorf("unexpected response -want/+got\n%s", cmp.Diff(want, got))
+			}
+		})
+	}
+
+	t.Run("annotated csv", func(t *testing.T) {
+		body := fmt.Sprintf(`{"type": "influxql", "query": %q, "bucket": %q, "dialect": {"annotations": ["datatype"]}}`, q, l.Bucket.Name)
+		if b := query(t, body); len(b) == 0 || b[0] != '#' {
+			t.Errorf("expected annotated csv, got:\n%s", b)
+		}
+	})
+}
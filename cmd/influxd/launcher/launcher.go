@@ -2,6 +2,8 @@ package launcher
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -15,6 +17,8 @@ import (
 	"github.com/influxdata/flux/control"
 	"github.com/influxdata/flux/execute"
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
+	"github.com/influxdata/influxdb/authorizer"
 	"github.com/influxdata/influxdb/bolt"
 	"github.com/influxdata/influxdb/chronograf/server"
 	"github.com/influxdata/influxdb/gather"
@@ -26,11 +30,17 @@ import (
 	"github.com/influxdata/influxdb/kit/signals"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/localsecret"
+	"github.com/influxdata/influxdb/lockout"
 	influxlogger "github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/nats"
+	"github.com/influxdata/influxdb/oidc"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	infprom "github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/query"
 	pcontrol "github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/query/querycache"
+	"github.com/influxdata/influxdb/query/querylog"
 	"github.com/influxdata/influxdb/snowflake"
 	"github.com/influxdata/influxdb/source"
 	"github.com/influxdata/influxdb/storage"
@@ -41,6 +51,7 @@ import (
 	"github.com/influxdata/influxdb/task/backend/coordinator"
 	taskexecutor "github.com/influxdata/influxdb/task/backend/executor"
 	"github.com/influxdata/influxdb/telemetry"
+	"github.com/influxdata/influxdb/tlsconfig"
 	_ "github.com/influxdata/influxdb/tsdb/tsi1" // needed for tsi1
 	_ "github.com/influxdata/influxdb/tsdb/tsm1" // needed for tsm1
 	"github.com/influxdata/influxdb/vault"
@@ -134,6 +145,44 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: ":9999",
 			Desc:    "bind address for the REST HTTP API",
 		},
+		{
+			DestP:   &l.writeMaxBodySize,
+			Flag:    "http-write-max-body-size",
+			Default: 0,
+			Desc:    "maximum size in bytes of a decompressed write request body; 0 disables the limit",
+		},
+		{
+			DestP: &l.tlsConfig.CertFile,
+			Flag:  "tls-cert",
+			Desc:  "path to the PEM encoded TLS certificate served on the HTTP bind address; requires tls-key",
+		},
+		{
+			DestP: &l.tlsConfig.KeyFile,
+			Flag:  "tls-key",
+			Desc:  "path to the PEM encoded private key of the TLS certificate",
+		},
+		{
+			DestP: &l.tlsConfig.ClientCAFile,
+			Flag:  "tls-client-ca",
+			Desc:  "path to the PEM encoded certificate authorities verifying TLS client certificates",
+		},
+		{
+			DestP:   &l.tlsConfig.RequireClientCert,
+			Flag:    "tls-require-client-cert",
+			Default: false,
+			Desc:    "refuse TLS connections without a client certificate verified by tls-client-ca",
+		},
+		{
+			DestP: &l.tlsSubjectsPath,
+			Flag:  "tls-client-subjects",
+			Desc:  "path to a JSON list of client certificate subjects mapped to the authorization they authenticate as",
+		},
+		{
+			DestP:   &l.tlsConfig.ReloadInterval,
+			Flag:    "tls-reload-interval",
+			Default: tlsconfig.DefaultReloadInterval,
+			Desc:    "how often the TLS certificates are checked for changes and reloaded",
+		},
 		{
 			DestP:   &l.boltPath,
 			Flag:    "bolt-path",
@@ -167,7 +216,22 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			DestP:   &l.secretStore,
 			Flag:    "secret-store",
 			Default: "bolt",
-			Desc:    "data store for secrets (bolt or vault)",
+			Desc:    "data store for secrets (bolt, local or vault)",
+		},
+		{
+			DestP: &l.secretKeyFile,
+			Flag:  "secret-key-file",
+			Desc:  "path to the master key encrypting secrets of the local secret store; the base64 encoded key can instead be set with " + secretKeyEnv,
+		},
+		{
+			DestP: &l.secretPreviousKeyFiles,
+			Flag:  "secret-previous-key-file",
+			Desc:  "paths to previous master keys of the local secret store; secrets encrypted with them are re-encrypted with the current key on startup",
+		},
+		{
+			DestP: &l.storageEncryptionKeyFile,
+			Flag:  "storage-encryption-key-file",
+			Desc:  "path to the keyfile encrypting TSM, tombstone, WAL and index files at rest, but not the series file; the last key of the file encrypts new files and the others are kept to read existing ones",
 		},
 		{
 			DestP:   &l.reportingDisabled,
@@ -175,6 +239,164 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disable sending telemetry data to https://telemetry.influxdata.com every 8 hours",
 		},
+		{
+			DestP: &l.oidcConfig.Issuer,
+			Flag:  "oidc-issuer",
+			Desc:  "URL of an OpenID Connect identity provider to enable single sign-on with",
+		},
+		{
+			DestP: &l.oidcConfig.ClientID,
+			Flag:  "oidc-client-id",
+			Desc:  "client ID registered with the OpenID Connect identity provider",
+		},
+		{
+			DestP: &l.oidcConfig.ClientSecret,
+			Flag:  "oidc-client-secret",
+			Desc:  "client secret registered with the OpenID Connect identity provider",
+		},
+		{
+			DestP: &l.oidcConfig.RedirectURL,
+			Flag:  "oidc-redirect-url",
+			Desc:  "externally reachable URL of /api/v2/signin/oidc/callback",
+		},
+		{
+			DestP:   &l.oidcConfig.Scopes,
+			Flag:    "oidc-scopes",
+			Default: []string{"email", "profile"},
+			Desc:    "scopes requested from the OpenID Connect identity provider in addition to openid",
+		},
+		{
+			DestP:   &l.oidcConfig.UsernameClaim,
+			Flag:    "oidc-username-claim",
+			Default: oidc.DefaultUsernameClaim,
+			Desc:    "ID token claim used as the username of new users; the email claim requires email_verified",
+		},
+		{
+			DestP: &l.oidcRulesPath,
+			Flag:  "oidc-rules",
+			Desc:  "path to a JSON file of rules granting organization roles to users by the claims of their ID token",
+		},
+		{
+			DestP:   &l.passwordPolicy.MinLength,
+			Flag:    "password-min-length",
+			Default: platform.DefaultPasswordPolicy.MinLength,
+			Desc:    "minimum number of characters of user passwords",
+		},
+		{
+			DestP:   &l.passwordPolicy.RequireUppercase,
+			Flag:    "password-require-uppercase",
+			Default: false,
+			Desc:    "require user passwords to contain an uppercase letter",
+		},
+		{
+			DestP:   &l.passwordPolicy.RequireLowercase,
+			Flag:    "password-require-lowercase",
+			Default: false,
+			Desc:    "require user passwords to contain a lowercase letter",
+		},
+		{
+			DestP:   &l.passwordPolicy.RequireDigit,
+			Flag:    "password-require-digit",
+			Default: false,
+			Desc:    "require user passwords to contain a digit",
+		},
+		{
+			DestP:   &l.passwordPolicy.RequireSymbol,
+			Flag:    "password-require-symbol",
+			Default: false,
+			Desc:    "require user passwords to contain a symbol",
+		},
+		{
+			DestP:   &l.lockoutConfig.UserThreshold,
+			Flag:    "signin-lockout-user-threshold",
+			Default: lockout.DefaultConfig.UserThreshold,
+			Desc:    "failed sign in attempts of a user before it is locked out; 0 disables the lockout of users",
+		},
+		{
+			DestP:   &l.lockoutConfig.AddrThreshold,
+			Flag:    "signin-lockout-addr-threshold",
+			Default: lockout.DefaultConfig.AddrThreshold,
+			Desc:    "failed sign in attempts from an address before it is locked out; 0 disables the lockout of addresses",
+		},
+		{
+			DestP:   &l.lockoutConfig.Delay,
+			Flag:    "signin-lockout-delay",
+			Default: lockout.DefaultConfig.Delay,
+			Desc:    "first sign in lockout, doubled by every further failed attempt",
+		},
+		{
+			DestP:   &l.lockoutConfig.MaxDelay,
+			Flag:    "signin-lockout-max-delay",
+			Default: lockout.DefaultConfig.MaxDelay,
+			Desc:    "longest sign in lockout",
+		},
+		{
+			DestP:   &l.lockoutConfig.Window,
+			Flag:    "signin-lockout-window",
+			Default: lockout.DefaultConfig.Window,
+			Desc:    "time after the last failed sign in attempt after which failures are forgotten",
+		},
+		{
+			DestP:   &l.lockoutConfig.MaxEntries,
+			Flag:    "signin-lockout-max-entries",
+			Default: lockout.DefaultConfig.MaxEntries,
+			Desc:    "users, and addresses, whose failed sign in attempts are tracked; the least recently failed are forgotten first",
+		},
+		{
+			DestP: &l.auditBucketID,
+			Flag:  "audit-bucket-id",
+			Desc:  "ID of a bucket to also write the audit trail to as line protocol",
+		},
+		{
+			DestP: &l.auditRetention,
+			Flag:  "audit-retention",
+			Desc:  "duration audit events are kept for; 0 keeps them forever",
+		},
+		{
+			DestP: &l.queryLogBucketID,
+			Flag:  "query-log-bucket-id",
+			Desc:  "ID of a bucket to write the slow queries of every organization to as line protocol; anyone who can read the bucket can read them, so it belongs in an organization of operators only",
+		},
+		{
+			DestP: &l.queryLogConfig.SlowQueryThreshold,
+			Flag:  "slow-query-threshold",
+			Desc:  "duration at or above which queries of organizations without a threshold of their own are logged as slow; 0 disables",
+		},
+		{
+			DestP:   &l.queryLogConfig.HistorySize,
+			Flag:    "query-history-size",
+			Default: querylog.DefaultHistorySize,
+			Desc:    "number of recent queries kept for each user",
+		},
+		{
+			DestP:   &l.queryLogConfig.QueueSize,
+			Flag:    "query-log-queue-size",
+			Default: querylog.DefaultQueueSize,
+			Desc:    "number of finished queries waiting to be logged; queries are dropped from the log when it is full",
+		},
+		{
+			DestP: &l.queryCacheConfig.MaxSize,
+			Flag:  "query-cache-size",
+			Desc:  "bytes of Flux query responses to cache until the buckets they read change; 0 disables",
+		},
+		{
+			DestP:   &l.queryCacheConfig.MaxEntrySize,
+			Flag:    "query-cache-max-entry-size",
+			Default: querycache.DefaultMaxEntrySize,
+			Desc:    "bytes of the largest Flux query response to cache",
+		},
+		{
+			DestP:   &l.queryCacheConfig.Granularity,
+			Flag:    "query-cache-granularity",
+			Default: querycache.DefaultGranularity,
+			Desc:    "width of the windows of time within which Flux queries relative to now share cached responses; 0 does not cache them",
+		},
+		{
+			DestP:   &l.queryCacheConfig.MaxAge,
+			Flag:    "query-cache-max-age",
+			Default: querycache.DefaultMaxAge,
+			Desc:    "time Flux query responses are cached for at most",
+		},
 	}
 
 	cli.BindOptions(cmd, opts)
@@ -195,10 +417,35 @@ type Launcher struct {
 	reportingDisabled bool
 
 	httpBindAddress string
+	tlsConfig       tlsconfig.Config
+	tlsSubjectsPath string
 	boltPath        string
 	enginePath      string
 	secretStore     string
 
+	writeMaxBodySize int
+
+	secretKeyFile          string
+	secretPreviousKeyFiles []string
+
+	storageEncryptionKeyFile string
+
+	oidcConfig    oidc.Config
+	oidcRulesPath string
+
+	passwordPolicy platform.PasswordPolicy
+	lockoutConfig  lockout.Config
+
+	auditBucketID  string
+	auditRetention time.Duration
+	auditEnforcer  *audit.RetentionEnforcer
+
+	queryLogBucketID string
+	queryLogConfig   querylog.Config
+	queryLogger      *querylog.Logger
+
+	queryCacheConfig querycache.Config
+
 	boltClient    *bolt.Client
 	kvService     *kv.Service
 	engine        *storage.Engine
@@ -208,6 +455,7 @@ type Launcher struct {
 
 	httpPort   int
 	httpServer *nethttp.Server
+	tlsServer  *tlsconfig.Server
 
 	natsServer *nats.Server
 
@@ -256,6 +504,9 @@ func (m *Launcher) Logger() *zap.Logger {
 
 // URL returns the URL to connect to the HTTP server.
 func (m *Launcher) URL() string {
+	if m.tlsServer != nil {
+		return fmt.Sprintf("https://127.0.0.1:%d", m.httpPort)
+	}
 	return fmt.Sprintf("http://127.0.0.1:%d", m.httpPort)
 }
 
@@ -268,10 +519,25 @@ func (m *Launcher) Engine() *storage.Engine {
 // Shutdown shuts down the HTTP server and waits for all services to clean up.
 func (m *Launcher) Shutdown(ctx context.Context) {
 	m.httpServer.Shutdown(ctx)
+	if m.tlsServer != nil {
+		m.tlsServer.Close()
+	}
 
 	m.logger.Info("Stopping", zap.String("service", "task"))
 	m.scheduler.Stop()
 
+	if m.auditEnforcer != nil {
+		m.logger.Info("Stopping", zap.String("service", "audit"))
+		m.auditEnforcer.Close()
+	}
+
+	if m.queryLogger != nil {
+		m.logger.Info("Stopping", zap.String("service", "query-log"))
+		if err := m.queryLogger.Close(); err != nil {
+			m.logger.Info("Failed closing query log", zap.Error(err))
+		}
+	}
+
 	m.logger.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
 
@@ -404,6 +670,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	m.kvService.Logger = m.logger.With(zap.String("store", "kv"))
+	m.kvService.PasswordPolicy = m.passwordPolicy
 	if err := m.kvService.Initialize(ctx); err != nil {
 		m.logger.Error("failed to initialize kv service", zap.Error(err))
 		return err
@@ -421,6 +688,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		orgSvc           platform.OrganizationService             = m.kvService
 		authSvc          platform.AuthorizationService            = m.kvService
 		userSvc          platform.UserService                     = m.kvService
+		roleSvc          platform.RoleService                     = m.kvService
 		variableSvc      platform.VariableService                 = m.kvService
 		bucketSvc        platform.BucketService                   = m.kvService
 		sourceSvc        platform.SourceService                   = m.kvService
@@ -428,6 +696,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		passwdsSvc       platform.PasswordsService                = m.kvService
 		dashboardSvc     platform.DashboardService                = m.kvService
 		dashboardLogSvc  platform.DashboardOperationLogService    = m.kvService
+		shareLinkSvc     platform.ShareLinkService                = m.kvService
+		invitationSvc    platform.InvitationService               = m.kvService
 		userLogSvc       platform.UserOperationLogService         = m.kvService
 		bucketLogSvc     platform.BucketOperationLogService       = m.kvService
 		orgLogSvc        platform.OrganizationOperationLogService = m.kvService
@@ -438,6 +708,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		labelSvc         platform.LabelService                    = m.kvService
 		secretSvc        platform.SecretService                   = m.kvService
 		lookupSvc        platform.LookupService                   = m.kvService
+		auditSvc         platform.AuditService                    = m.kvService
 	)
 
 	switch m.secretStore {
@@ -452,12 +723,43 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			return err
 		}
 		secretSvc = svc
+	case "local":
+		svc, err := m.localSecretService(ctx)
+		if err != nil {
+			m.logger.Error("failed initializing local secret service", zap.Error(err))
+			return err
+		}
+		secretSvc = svc
 	default:
-		err := fmt.Errorf("unknown secret service %q, expected \"bolt\" or \"vault\"", m.secretStore)
+		err := fmt.Errorf("unknown secret service %q, expected \"bolt\", \"local\" or \"vault\"", m.secretStore)
 		m.logger.Error("failed setting secret service", zap.Error(err))
 		return err
 	}
 
+	var oidcProvider *oidc.Provider
+	var oidcProvisioner *oidc.Provisioner
+	if m.oidcConfig.Issuer != "" {
+		oidcProvider, err = oidc.NewProvider(ctx, m.oidcConfig)
+		if err != nil {
+			m.logger.Error("failed initializing oidc provider", zap.Error(err))
+			return err
+		}
+
+		oidcProvisioner = &oidc.Provisioner{
+			Logger:                     m.logger.With(zap.String("service", "oidc")),
+			UserService:                userSvc,
+			UserIdentityService:        m.kvService,
+			OrganizationService:        orgSvc,
+			UserResourceMappingService: userResourceSvc,
+		}
+		if m.oidcRulesPath != "" {
+			if oidcProvisioner.Rules, err = oidc.LoadRules(m.oidcRulesPath); err != nil {
+				m.logger.Error("failed loading oidc rules", zap.Error(err))
+				return err
+			}
+		}
+	}
+
 	chronografSvc, err := server.NewServiceV2(ctx, m.boltClient.DB())
 	if err != nil {
 		m.logger.Error("failed creating chronograf service", zap.Error(err))
@@ -465,8 +767,25 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	var pointsWriter storage.PointsWriter
+	var writeTracker *storage.WriteTracker
 	{
-		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		var engineOptions []storage.Option
+		if m.storageEncryptionKeyFile != "" {
+			kr, err := encryption.LoadKeyring(m.storageEncryptionKeyFile)
+			if err != nil {
+				m.logger.Error("failed loading storage encryption keys", zap.Error(err))
+				return err
+			}
+			engineOptions = append(engineOptions, storage.WithKeyring(kr))
+		}
+		// Changes to buckets are tracked to invalidate cached query responses.
+		if m.queryCacheConfig.MaxSize > 0 {
+			writeTracker = storage.NewWriteTracker()
+			engineOptions = append(engineOptions, storage.WithWriteTracker(writeTracker))
+		}
+		engineOptions = append(engineOptions, storage.WithRetentionEnforcer(bucketSvc))
+
+		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, engineOptions...)
 		m.engine.WithLogger(m.logger)
 
 		if err := m.engine.Open(ctx); err != nil {
@@ -518,7 +837,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			store = taskbackend.NewInMemStore()
 		}
 
-		executor := taskexecutor.NewAsyncQueryServiceExecutor(m.logger.With(zap.String("service", "task-executor")), m.queryController, authSvc, nil)
+		// Tasks run with the permissions of the roles of their authorization.
+		executorAuthSvc := authorizer.NewRoleAuthorizationService(authSvc, roleSvc, userResourceSvc)
+		executor := taskexecutor.NewAsyncQueryServiceExecutor(m.logger.With(zap.String("service", "task-executor")), m.queryController, executorAuthSvc, nil)
 
 		lw := taskbackend.NewPointLogWriter(pointsWriter)
 		queryService := query.QueryServiceBridge{AsyncQueryService: m.queryController}
@@ -577,26 +898,109 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		logger.Info("Stopping")
 	}(m.logger)
 
+	// Record the changes made through the API in the audit trail.
+	auditLogger := m.logger.With(zap.String("service", "audit"))
+	if m.auditRetention > 0 {
+		m.auditEnforcer = audit.NewRetentionEnforcer(auditLogger, m.kvService, m.auditRetention, audit.DefaultRetentionCheckInterval)
+		m.auditEnforcer.Open()
+	}
+	if m.auditBucketID != "" {
+		auditBucketID, err := platform.IDFromString(m.auditBucketID)
+		if err != nil {
+			m.logger.Error("failed parsing audit bucket id", zap.Error(err))
+			return err
+		}
+		auditSvc = audit.NewPointsService(auditLogger, auditSvc, bucketSvc, pointsWriter, *auditBucketID)
+	}
+	authSvc = audit.NewAuthorizationService(auditLogger, authSvc, auditSvc)
+	userSvc = audit.NewUserService(auditLogger, userSvc, auditSvc)
+	orgSvc = audit.NewOrgService(auditLogger, orgSvc, auditSvc)
+	dashboardSvc = audit.NewDashboardService(auditLogger, dashboardSvc, auditSvc)
+	shareLinkSvc = audit.NewShareLinkService(auditLogger, shareLinkSvc, auditSvc)
+	secretSvc = audit.NewSecretService(auditLogger, secretSvc, auditSvc)
+	taskSvc = audit.NewTaskService(auditLogger, taskSvc, auditSvc)
+
+	// Log the queries made through the API, keeping the recent queries of
+	// each user and recording slow queries.
+	if m.queryLogBucketID != "" {
+		queryLogBucketID, err := platform.IDFromString(m.queryLogBucketID)
+		if err != nil {
+			m.logger.Error("failed parsing query log bucket id", zap.Error(err))
+			return err
+		}
+		m.queryLogConfig.BucketID = *queryLogBucketID
+	}
+	// Cache the responses of queries, so that the same queries of dashboards
+	// refreshing in many browsers read the buckets once between changes.
+	if writeTracker != nil {
+		queryCache := querycache.New(storageQueryService, m.queryCacheConfig, query.FromBucketService(bucketSvc), writeTracker)
+		m.reg.MustRegister(queryCache.PrometheusCollectors()...)
+		storageQueryService = queryCache
+	}
+
+	m.queryLogger = querylog.New(m.logger.With(zap.String("service", "query-log")), m.queryLogConfig, m.kvService, bucketSvc, pointsWriter)
+	m.queryLogger.Open()
+	storageQueryService = &query.LoggingProxyQueryService{
+		ProxyQueryService: storageQueryService,
+		QueryLogger:       m.queryLogger,
+	}
+
+	var signinLockout *lockout.Tracker
+	if m.lockoutConfig.UserThreshold > 0 || m.lockoutConfig.AddrThreshold > 0 {
+		signinLockout = lockout.NewTracker(m.lockoutConfig)
+	}
+
 	m.httpServer = &nethttp.Server{
 		Addr: m.httpBindAddress,
 	}
 
+	var clientCertificates http.ClientCertificateMapper
+	if m.tlsSubjectsPath != "" {
+		if m.tlsConfig.ClientCAFile == "" {
+			err := errors.New("tls-client-subjects requires tls-client-ca")
+			m.logger.Error("failed configuring tls", zap.Error(err))
+			return err
+		}
+		subjects, err := tlsconfig.LoadSubjects(m.tlsSubjectsPath)
+		if err != nil {
+			m.logger.Error("failed loading tls client subjects", zap.Error(err))
+			return err
+		}
+		clientCertificates = subjects
+	}
+
 	m.apibackend = &http.APIBackend{
-		AssetsPath:           m.assetsPath,
-		Logger:               m.logger,
-		NewBucketService:     source.NewBucketService,
-		NewQueryService:      source.NewQueryService,
-		PointsWriter:         pointsWriter,
-		AuthorizationService: authSvc,
+		AssetsPath:              m.assetsPath,
+		Logger:                  m.logger,
+		NewBucketService:        source.NewBucketService,
+		NewQueryService:         source.NewQueryService,
+		PointsWriter:            pointsWriter,
+		ReadStore:               readservice.NewStore(m.engine),
+		CompactionService:       m.engine,
+		ActiveQueryService:      m.queryController,
+		QueryLogSettingsService: m.kvService,
+		QueryHistoryService:     m.queryLogger,
+		IndexService:            m.engine,
+		ImportService:           m.engine,
+		OIDCProvider:            oidcProvider,
+		OIDCProvisioner:         oidcProvisioner,
+		SigninLockout:           signinLockout,
+		ClientCertificates:      clientCertificates,
+		WriteMaxBodySize:        int64(m.writeMaxBodySize),
+		AuthorizationService:    authSvc,
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
-		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
+		BucketService:                   audit.NewBucketService(auditLogger, storage.NewBucketService(bucketSvc, m.engine), auditSvc),
 		SessionService:                  sessionSvc,
 		UserService:                     userSvc,
 		OrganizationService:             orgSvc,
+		RoleService:                     roleSvc,
+		AuditService:                    auditSvc,
 		UserResourceMappingService:      userResourceSvc,
 		LabelService:                    labelSvc,
 		DashboardService:                dashboardSvc,
 		DashboardOperationLogService:    dashboardLogSvc,
+		ShareLinkService:                shareLinkSvc,
+		InvitationService:               invitationSvc,
 		BucketOperationLogService:       bucketLogSvc,
 		UserOperationLogService:         userLogSvc,
 		OrganizationOperationLogService: orgLogSvc,
@@ -642,10 +1046,24 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.httpPort = addr.Port
 	}
 
+	transport := "http"
+	if m.tlsConfig.CertFile != "" || m.tlsConfig.KeyFile != "" {
+		if m.tlsServer, err = tlsconfig.NewServer(m.tlsConfig); err != nil {
+			ln.Close()
+			httpLogger.Error("failed loading tls certificates", zap.Error(err))
+			return err
+		}
+		m.tlsServer.Logger = httpLogger
+		m.tlsServer.Open()
+		m.httpServer.TLSConfig = m.tlsServer.TLSConfig()
+		ln = tls.NewListener(ln, m.httpServer.TLSConfig)
+		transport = "https"
+	}
+
 	m.wg.Add(1)
 	go func(logger *zap.Logger) {
 		defer m.wg.Done()
-		logger.Info("Listening", zap.String("transport", "http"), zap.String("addr", m.httpBindAddress), zap.Int("port", m.httpPort))
+		logger.Info("Listening", zap.String("transport", transport), zap.String("addr", m.httpBindAddress), zap.Int("port", m.httpPort))
 
 		if err := m.httpServer.Serve(ln); err != nethttp.ErrServerClosed {
 			logger.Error("failed http service", zap.Error(err))
@@ -656,6 +1074,50 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	return nil
 }
 
+// secretKeyEnv is the environment variable holding the base64 encoded master
+// key of the local secret store. There is no flag for the key itself so that
+// it isn't visible in the process list.
+const secretKeyEnv = "INFLUXD_SECRET_KEY"
+
+// localSecretService returns the secret service encrypting secrets stored in
+// bolt with the configured master key. Secrets encrypted with a previous
+// master key or stored before encryption was enabled are re-encrypted with
+// the current key.
+func (m *Launcher) localSecretService(ctx context.Context) (*localsecret.SecretService, error) {
+	var (
+		key *localsecret.Key
+		err error
+	)
+	switch {
+	case m.secretKeyFile != "":
+		key, err = localsecret.ReadKeyFile(m.secretKeyFile)
+	case os.Getenv(secretKeyEnv) != "":
+		key, err = localsecret.ParseKey(os.Getenv(secretKeyEnv))
+	default:
+		err = errors.New("the local secret store requires a master key set with --secret-key-file or " + secretKeyEnv)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	previous := make([]*localsecret.Key, 0, len(m.secretPreviousKeyFiles))
+	for _, path := range m.secretPreviousKeyFiles {
+		k, err := localsecret.ReadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, k)
+	}
+
+	svc := localsecret.NewSecretService(m.kvService, key, previous...)
+	n, err := svc.Rotate(ctx, m.kvService)
+	if err != nil {
+		return nil, err
+	}
+	m.logger.Info("Using local secret store", zap.String("key_id", key.ID()), zap.Int("secrets_reencrypted", n))
+	return svc, nil
+}
+
 // OrganizationService returns the internal organization service.
 func (m *Launcher) OrganizationService() platform.OrganizationService {
 	return m.apibackend.OrganizationService
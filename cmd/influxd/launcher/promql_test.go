@@ -0,0 +1,137 @@
+package launcher_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/cmd/influxd/launcher"
+	"github.com/influxdata/influxdb/query/promql"
+)
+
+func TestLauncher_PromQL(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `
+up,job=a,instance=x value=1 1569888000000000000
+up,job=a,instance=x value=0 1569888030000000000
+up,job=a,instance=x value=1 1569888060000000000
+up,job=b,instance=y value=1 1569888015000000000
+up,job=b,instance=y value=1 1569888045000000000
+`)
+
+	get := func(t *testing.T, path string, params url.Values) *promql.Response {
+		t.Helper()
+		params.Set("bucket", l.Bucket.Name)
+		resp, err := nethttp.DefaultClient.Do(l.MustNewHTTPRequest("GET", path+"?"+params.Encode(), ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != nethttp.StatusOK {
+			t.Fatalf("unexpected status code: %d, body: %s", resp.StatusCode, body)
+		}
+
+		var r promql.Response
+		if err := json.Unmarshal(body, &r); err != nil {
+			t.Fatal(err)
+		}
+		return &r
+	}
+
+	at := func(sec int64) time.Time {
+		return time.Unix(sec, 0).UTC()
+	}
+
+	t.Run("instant query", func(t *testing.T) {
+		r := get(t, "/api/v1/query", url.Values{
+			"query": {`up`},
+			"time":  {"1569888040"},
+		})
+
+		want := &promql.Data{
+			ResultType: promql.VectorResult,
+			Result: []*promql.Series{
+				{
+					Metric: map[string]string{"__name__": "up", "instance": "x", "job": "a"},
+					Value:  &promql.Sample{Time: at(1569888040), Value: 0},
+				},
+				{
+					Metric: map[string]string{"__name__": "up", "instance": "y", "job": "b"},
+					Value:  &promql.Sample{Time: at(1569888040), Value: 1},
+				},
+			},
+		}
+		if !cmp.Equal(want, r.Data) {
+			t.Errorf("unexpected data -want/+got\n%s", cmp.Diff(want, r.Data))
+		}
+	})
+
+	t.Run("range query", func(t *testing.T) {
+		r := get(t, "/api/v1/query_range", url.Values{
+			"query": {`sum(up)`},
+			"start": {"1569888000"},
+			"end":   {"1569888060"},
+			"step":  {"30s"},
+		})
+
+		want := &promql.Data{
+			ResultType: promql.MatrixResult,
+			Result: []*promql.Series{{
+				Metric: map[string]string{},
+				Values: []promql.Sample{
+					{Time: at(1569888000), Value: 1},
+					{Time: at(1569888030), Value: 1},
+					{Time: at(1569888060), Value: 2},
+				},
+			}},
+		}
+		if !cmp.Equal(want, r.Data) {
+			t.Errorf("unexpected data -want/+got\n%s", cmp.Diff(want, r.Data))
+		}
+	})
+
+	t.Run("query endpoint", func(t *testing.T) {
+		body := fmt.Sprintf(`{"type": "promql", "query": "up{job=\"b\"}", "bucket": %q, "end": "2019-10-01T00:01:00Z"}`, l.Bucket.Name)
+		req := l.MustNewHTTPRequest("POST", fmt.Sprintf("/api/v2/query?orgID=%s", l.Org.ID), body)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := nethttp.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != nethttp.StatusOK {
+			t.Fatalf("unexpected status code: %d, body: %s", resp.StatusCode, b)
+		}
+
+		// The result is annotated CSV with a single row, the sample of job b
+		// at 00:00:45 evaluated at 00:01:00.
+		lines := strings.Split(strings.TrimSpace(string(b)), "\r\n")
+		if len(lines) != 2 {
+			t.Fatalf("unexpected query results:\n%s", b)
+		}
+		for _, s := range []string{"2019-10-01T00:01:00Z", ",up,", ",b,", ",y,"} {
+			if !strings.Contains(lines[1], s) {
+				t.Errorf("expected %q in row %q", s, lines[1])
+			}
+		}
+	})
+}
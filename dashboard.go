@@ -7,6 +7,9 @@ import (
 	"net/url"
 	"sort"
 	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
 )
 
 // ErrDashboardNotFound is the error msg for a missing dashboard.
@@ -688,6 +691,72 @@ type BuilderConfig struct {
 	} `json:"functions"`
 }
 
+// References returns the buckets the query reads from and the names of the
+// dashboard variables it uses. Buckets are read with from() by name or by
+// ID; variables are the members of the v record.
+func (q DashboardQuery) References() (buckets []BucketFilter, variables []string) {
+	for _, name := range q.BuilderConfig.Buckets {
+		name := name
+		buckets = append(buckets, BucketFilter{Name: &name})
+	}
+
+	ast.Walk(ast.CreateVisitor(func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.CallExpression:
+			if callee, ok := n.Callee.(*ast.Identifier); !ok || callee.Name != "from" {
+				return
+			}
+			for _, arg := range n.Arguments {
+				obj, ok := arg.(*ast.ObjectExpression)
+				if !ok {
+					continue
+				}
+				for _, p := range obj.Properties {
+					lit, ok := p.Value.(*ast.StringLiteral)
+					if !ok {
+						continue
+					}
+					switch p.Key.Key() {
+					case "bucket":
+						name := lit.Value
+						buckets = append(buckets, BucketFilter{Name: &name})
+					case "bucketID":
+						if id, err := IDFromString(lit.Value); err == nil {
+							buckets = append(buckets, BucketFilter{ID: id})
+						}
+					}
+				}
+			}
+		case *ast.MemberExpression:
+			if obj, ok := n.Object.(*ast.Identifier); ok && obj.Name == "v" {
+				variables = append(variables, n.Property.Key())
+			}
+		}
+	}), parser.ParseSource(q.Text))
+
+	return buckets, variables
+}
+
+// ViewQueries returns the queries of the properties of a view. Views like
+// markdown notes have no queries.
+func ViewQueries(p ViewProperties) []DashboardQuery {
+	switch p := p.(type) {
+	case XYViewProperties:
+		return p.Queries
+	case LinePlusSingleStatProperties:
+		return p.Queries
+	case SingleStatViewProperties:
+		return p.Queries
+	case HistogramViewProperties:
+		return p.Queries
+	case GaugeViewProperties:
+		return p.Queries
+	case TableViewProperties:
+		return p.Queries
+	}
+	return nil
+}
+
 // Axis represents the visible extents of a visualization
 type Axis struct {
 	Bounds       []string `json:"bounds"` // bounds are an arbitrary list of client-defined strings that specify the viewport for a View
@@ -85,3 +85,27 @@ func jsonEqual(s1, s2 string) (eq bool, err error) {
 
 	return cmp.Equal(o1, o2), nil
 }
+
+func TestDashboardQuery_References(t *testing.T) {
+	q := platform.DashboardQuery{
+		Text: `a = from(bucket: "telegraf") |> range(start: v.timeRangeStart)
+b = from(bucketID: "020f755c3c082000") |> range(start: -1h) |> filter(fn: (r) => r.host == v["host"])
+join(tables: {a, b}, on: ["_time"])`,
+		BuilderConfig: platform.BuilderConfig{Buckets: []string{"builder"}},
+	}
+
+	buckets, variables := q.References()
+
+	builder, telegraf := "builder", "telegraf"
+	wantBuckets := []platform.BucketFilter{
+		{Name: &builder},
+		{Name: &telegraf},
+		{ID: platformtesting.IDPtr(platformtesting.MustIDBase16("020f755c3c082000"))},
+	}
+	if diff := cmp.Diff(buckets, wantBuckets); diff != "" {
+		t.Errorf("buckets are different -got/+want\ndiff %s", diff)
+	}
+	if diff := cmp.Diff(variables, []string{"timeRangeStart", "host"}); diff != "" {
+		t.Errorf("variables are different -got/+want\ndiff %s", diff)
+	}
+}
@@ -21,6 +21,8 @@ const (
 	EForbidden           = "forbidden"
 	EUnauthorized        = "unauthorized"
 	EMethodNotAllowed    = "method not allowed"
+	ETooManyRequests     = "too many requests"
+	ETooLarge            = "request too large"
 )
 
 // Error is the error struct of platform.
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// ActiveQueryBackend is all services and associated parameters required to construct
+// the ActiveQueryHandler.
+type ActiveQueryBackend struct {
+	Logger *zap.Logger
+
+	ActiveQueryService query.ActiveQueryService
+}
+
+// NewActiveQueryBackend returns a new instance of ActiveQueryBackend.
+func NewActiveQueryBackend(b *APIBackend) *ActiveQueryBackend {
+	return &ActiveQueryBackend{
+		Logger: b.Logger.With(zap.String("handler", "active_query")),
+
+		ActiveQueryService: b.ActiveQueryService,
+	}
+}
+
+// ActiveQueryHandler represents an HTTP API handler for listing and
+// canceling the queries that are running.
+type ActiveQueryHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	ActiveQueryService query.ActiveQueryService
+}
+
+const (
+	activeQueriesPath   = "/api/v2/queries"
+	activeQueriesIDPath = "/api/v2/queries/:id"
+)
+
+// NewActiveQueryHandler returns a new instance of ActiveQueryHandler.
+func NewActiveQueryHandler(b *ActiveQueryBackend) *ActiveQueryHandler {
+	h := &ActiveQueryHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		ActiveQueryService: b.ActiveQueryService,
+	}
+
+	h.HandlerFunc("GET", activeQueriesPath, h.handleGetActiveQueries)
+	h.HandlerFunc("DELETE", activeQueriesIDPath, h.handleDeleteActiveQuery)
+	return h
+}
+
+type activeQueriesResponse struct {
+	Queries []*query.ActiveQuery `json:"queries"`
+}
+
+// handleGetActiveQueries is the HTTP handler for the GET /api/v2/queries route.
+func (h *ActiveQueryHandler) handleGetActiveQueries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeGetActiveQueriesRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	queries, err := h.ActiveQueryService.FindActiveQueries(ctx, *filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, activeQueriesResponse{Queries: queries}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeGetActiveQueriesRequest(r *http.Request) (*query.ActiveQueryFilter, error) {
+	qp := r.URL.Query()
+	filter := &query.ActiveQueryFilter{}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrganizationID = id
+	}
+
+	if userID := qp.Get("userID"); userID != "" {
+		id, err := platform.IDFromString(userID)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid userID",
+				Err:  err,
+			}
+		}
+		filter.UserID = id
+	}
+
+	return filter, nil
+}
+
+// handleDeleteActiveQuery is the HTTP handler for the DELETE /api/v2/queries/:id route.
+func (h *ActiveQueryHandler) handleDeleteActiveQuery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}, w)
+		return
+	}
+
+	var i platform.ID
+	if err := i.DecodeFromString(id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.ActiveQueryService.CancelActiveQuery(ctx, i); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ActiveQueryService connects to Influx via HTTP using tokens to list and
+// cancel running queries.
+type ActiveQueryService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// FindActiveQueries returns the running queries that match the filter.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	params := url.Values{}
+	if filter.OrganizationID != nil {
+		params.Set("orgID", filter.OrganizationID.String())
+	}
+	if filter.UserID != nil {
+		params.Set("userID", filter.UserID.String())
+	}
+
+	var resp activeQueriesResponse
+	if err := s.do(ctx, "GET", activeQueriesPath, params, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Queries, nil
+}
+
+// CancelActiveQuery cancels the running query with the id.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id platform.ID) error {
+	return s.do(ctx, "DELETE", path.Join(activeQueriesPath, id.String()), nil, nil)
+}
+
+func (s *ActiveQueryService) do(ctx context.Context, method, path string, params url.Values, v interface{}) error {
+	u, err := newURL(s.Addr, path)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap"
+)
+
+// NewMockActiveQueryBackend returns an ActiveQueryBackend with mock services.
+func NewMockActiveQueryBackend() *ActiveQueryBackend {
+	return &ActiveQueryBackend{
+		Logger: zap.NewNop().With(zap.String("handler", "active_query")),
+
+		ActiveQueryService: &querymock.ActiveQueryService{
+			FindActiveQueriesF: func(context.Context, query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+				return []*query.ActiveQuery{}, nil
+			},
+			CancelActiveQueryF: func(context.Context, platform.ID) error {
+				return nil
+			},
+		},
+	}
+}
+
+func TestActiveQueryHandler(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	started := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		svc   func(*querymock.ActiveQueryService)
+		r     *http.Request
+		wants wants
+	}{
+		{
+			name: "list active queries",
+			svc: func(s *querymock.ActiveQueryService) {
+				s.FindActiveQueriesF = func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+					if filter.OrganizationID == nil || *filter.OrganizationID != 1 || filter.UserID != nil {
+						t.Errorf("unexpected filter %+v", filter)
+					}
+					return []*query.ActiveQuery{
+						{
+							ID:             2,
+							OrganizationID: 1,
+							UserID:         3,
+							Source:         "influx",
+							Type:           "flux",
+							Query:          `from(bucket: "telegraf") |> range(start: -1h)`,
+							State:          "executing",
+							StartTime:      started,
+							Elapsed:        time.Second,
+							MemoryBytes:    1024,
+						},
+					}, nil
+				}
+			},
+			r: httptest.NewRequest("GET", "http://any.url/api/v2/queries?orgID=0000000000000001", nil),
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `
+{
+  "queries": [
+    {
+      "id": "0000000000000002",
+      "orgID": "0000000000000001",
+      "userID": "0000000000000003",
+      "source": "influx",
+      "type": "flux",
+      "query": "from(bucket: \"telegraf\") |> range(start: -1h)",
+      "state": "executing",
+      "startTime": "2019-03-01T00:00:00Z",
+      "elapsed": 1000000000,
+      "memoryBytes": 1024
+    }
+  ]
+}`,
+			},
+		},
+		{
+			name: "list active queries with invalid user id",
+			r:    httptest.NewRequest("GET", "http://any.url/api/v2/queries?userID=x", nil),
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "cancel active query",
+			svc: func(s *querymock.ActiveQueryService) {
+				s.CancelActiveQueryF = func(ctx context.Context, id platform.ID) error {
+					if id != 2 {
+						t.Errorf("unexpected query id %v", id)
+					}
+					return nil
+				}
+			},
+			r: httptest.NewRequest("DELETE", "http://any.url/api/v2/queries/0000000000000002", nil),
+			wants: wants{
+				statusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "cancel query that is not running",
+			svc: func(s *querymock.ActiveQueryService) {
+				s.CancelActiveQueryF = func(ctx context.Context, id platform.ID) error {
+					return &platform.Error{Code: platform.ENotFound, Msg: "query not found"}
+				}
+			},
+			r: httptest.NewRequest("DELETE", "http://any.url/api/v2/queries/0000000000000002", nil),
+			wants: wants{
+				statusCode: http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			activeQueryBackend := NewMockActiveQueryBackend()
+			svc := activeQueryBackend.ActiveQueryService.(*querymock.ActiveQueryService)
+			if tt.svc != nil {
+				tt.svc(svc)
+			}
+			h := NewActiveQueryHandler(activeQueryBackend)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, tt.r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. got %v, want %v", tt.name, res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil || !eq {
+					t.Errorf("%q. -got/+want %s %v", tt.name, diff, err)
+				}
+			}
+		})
+	}
+}
@@ -7,18 +7,29 @@ import (
 	influxdb "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/authorizer"
 	"github.com/influxdata/influxdb/chronograf/server"
+	"github.com/influxdata/influxdb/lockout"
+	"github.com/influxdata/influxdb/oidc"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/reads"
 	"go.uber.org/zap"
 )
 
 // APIHandler is a collection of all the service handlers.
 type APIHandler struct {
+	ActiveQueryHandler   *ActiveQueryHandler
 	BucketHandler        *BucketHandler
+	CompactionHandler    *CompactionHandler
+	IndexHandler         *IndexHandler
+	ImportHandler        *ImportHandler
 	UserHandler          *UserHandler
 	OrgHandler           *OrgHandler
+	RoleHandler          *RoleHandler
+	AuditHandler         *AuditHandler
 	AuthorizationHandler *AuthorizationHandler
 	DashboardHandler     *DashboardHandler
+	ShareLinkHandler     *ShareLinkHandler
+	InvitationHandler    *InvitationHandler
 	LabelHandler         *LabelHandler
 	AssetHandler         *AssetHandler
 	ChronografHandler    *ChronografHandler
@@ -28,7 +39,9 @@ type APIHandler struct {
 	TaskHandler          *TaskHandler
 	TelegrafHandler      *TelegrafHandler
 	QueryHandler         *FluxHandler
+	QueryLogHandler      *QueryLogHandler
 	WriteHandler         *WriteHandler
+	PrometheusHandler    *PrometheusHandler
 	DocumentHandler      *DocumentHandler
 	SetupHandler         *SetupHandler
 	SessionHandler       *SessionHandler
@@ -45,15 +58,31 @@ type APIBackend struct {
 	NewQueryService  func(*influxdb.Source) (query.ProxyQueryService, error)
 
 	PointsWriter                    storage.PointsWriter
+	ReadStore                       reads.Store
+	CompactionService               storage.CompactionService
+	ActiveQueryService              query.ActiveQueryService
+	QueryLogSettingsService         query.LogSettingsService
+	QueryHistoryService             query.HistoryService
+	IndexService                    storage.IndexService
+	ImportService                   storage.ImportService
+	OIDCProvider                    *oidc.Provider
+	OIDCProvisioner                 *oidc.Provisioner
+	SigninLockout                   *lockout.Tracker
+	ClientCertificates              ClientCertificateMapper
+	WriteMaxBodySize                int64
 	AuthorizationService            influxdb.AuthorizationService
 	BucketService                   influxdb.BucketService
 	SessionService                  influxdb.SessionService
 	UserService                     influxdb.UserService
 	OrganizationService             influxdb.OrganizationService
+	RoleService                     influxdb.RoleService
+	AuditService                    influxdb.AuditService
 	UserResourceMappingService      influxdb.UserResourceMappingService
 	LabelService                    influxdb.LabelService
 	DashboardService                influxdb.DashboardService
 	DashboardOperationLogService    influxdb.DashboardOperationLogService
+	ShareLinkService                influxdb.ShareLinkService
+	InvitationService               influxdb.InvitationService
 	BucketOperationLogService       influxdb.BucketOperationLogService
 	UserOperationLogService         influxdb.UserOperationLogService
 	OrganizationOperationLogService influxdb.OrganizationOperationLogService
@@ -96,16 +125,40 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 
 	userBackend := NewUserBackend(b)
 	userBackend.UserService = authorizer.NewUserService(b.UserService)
+	if b.SigninLockout != nil {
+		userBackend.LockoutService = authorizer.NewLockoutService(b.SigninLockout)
+	}
 	h.UserHandler = NewUserHandler(userBackend)
 
 	dashboardBackend := NewDashboardBackend(b)
 	dashboardBackend.DashboardService = authorizer.NewDashboardService(b.DashboardService)
 	h.DashboardHandler = NewDashboardHandler(dashboardBackend)
 
+	shareLinkBackend := NewShareLinkBackend(b)
+	shareLinkBackend.ShareLinkService = authorizer.NewShareLinkService(b.ShareLinkService, b.DashboardService)
+	shareLinkBackend.DashboardService = authorizer.NewDashboardService(b.DashboardService)
+	h.ShareLinkHandler = NewShareLinkHandler(shareLinkBackend)
+
+	invitationBackend := NewInvitationBackend(b)
+	invitationBackend.InvitationService = authorizer.NewInvitationService(b.InvitationService)
+	invitationBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	invitationBackend.UserResourceMappingService = internalURM
+	h.InvitationHandler = NewInvitationHandler(invitationBackend)
+
 	variableBackend := NewVariableBackend(b)
 	variableBackend.VariableService = authorizer.NewVariableService(b.VariableService)
 	h.VariableHandler = NewVariableHandler(variableBackend)
 
+	roleBackend := NewRoleBackend(b)
+	roleBackend.RoleService = authorizer.NewRoleService(b.RoleService, b.AuthorizationService)
+	roleBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	h.RoleHandler = NewRoleHandler(roleBackend)
+
+	auditBackend := NewAuditBackend(b)
+	auditBackend.AuditService = authorizer.NewAuditService(b.AuditService)
+	auditBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	h.AuditHandler = NewAuditHandler(auditBackend)
+
 	authorizationBackend := NewAuthorizationBackend(b)
 	authorizationBackend.AuthorizationService = authorizer.NewAuthorizationService(b.AuthorizationService)
 	h.AuthorizationHandler = NewAuthorizationHandler(authorizationBackend)
@@ -130,12 +183,36 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 	telegrafBackend.TelegrafService = authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)
 	h.TelegrafHandler = NewTelegrafHandler(telegrafBackend)
 
+	compactionBackend := NewCompactionBackend(b)
+	compactionBackend.CompactionService = authorizer.NewCompactionService(b.CompactionService)
+	h.CompactionHandler = NewCompactionHandler(compactionBackend)
+
+	indexBackend := NewIndexBackend(b)
+	indexBackend.IndexService = authorizer.NewIndexService(b.IndexService)
+	h.IndexHandler = NewIndexHandler(indexBackend)
+
+	importBackend := NewImportBackend(b)
+	importBackend.ImportService = authorizer.NewImportService(b.ImportService)
+	h.ImportHandler = NewImportHandler(importBackend)
+
 	writeBackend := NewWriteBackend(b)
 	h.WriteHandler = NewWriteHandler(writeBackend)
 
+	prometheusBackend := NewPrometheusBackend(b)
+	h.PrometheusHandler = NewPrometheusHandler(prometheusBackend)
+
 	fluxBackend := NewFluxBackend(b)
 	h.QueryHandler = NewFluxHandler(fluxBackend)
 
+	activeQueryBackend := NewActiveQueryBackend(b)
+	activeQueryBackend.ActiveQueryService = authorizer.NewActiveQueryService(b.ActiveQueryService)
+	h.ActiveQueryHandler = NewActiveQueryHandler(activeQueryBackend)
+
+	queryLogBackend := NewQueryLogBackend(b)
+	queryLogBackend.QueryLogSettingsService = authorizer.NewLogSettingsService(b.QueryLogSettingsService)
+	queryLogBackend.QueryHistoryService = authorizer.NewHistoryService(b.QueryHistoryService)
+	h.QueryLogHandler = NewQueryLogHandler(queryLogBackend)
+
 	h.ChronografHandler = NewChronografHandler(b.ChronografService)
 	h.SwaggerHandler = newSwaggerLoader(b.Logger.With(zap.String("service", "swagger-loader")))
 	h.LabelHandler = NewLabelHandler(authorizer.NewLabelService(b.LabelService))
@@ -146,16 +223,27 @@ func NewAPIHandler(b *APIBackend) *APIHandler {
 var apiLinks = map[string]interface{}{
 	// when adding new links, please take care to keep this list alphabetical
 	// as this makes it easier to verify values against the swagger document.
+	"audit":          "/api/v2/audit",
 	"authorizations": "/api/v2/authorizations",
 	"buckets":        "/api/v2/buckets",
 	"dashboards":     "/api/v2/dashboards",
 	"external": map[string]string{
 		"statusFeed": "https://www.influxdata.com/feed/json",
 	},
-	"labels":    "/api/v2/labels",
-	"variables": "/api/v2/variables",
-	"me":        "/api/v2/me",
-	"orgs":      "/api/v2/orgs",
+	"invitations": "/api/v2/invitations",
+	"labels":      "/api/v2/labels",
+	"variables":   "/api/v2/variables",
+	"me":          "/api/v2/me",
+	"orgs":        "/api/v2/orgs",
+	"prometheus": map[string]string{
+		"read":       "/api/v2/prometheus/read",
+		"write":      "/api/v2/prometheus/write",
+		"query":      "/api/v1/query",
+		"queryRange": "/api/v1/query_range",
+		"labels":     "/api/v1/labels",
+		"series":     "/api/v1/series",
+	},
+	"queries": "/api/v2/queries",
 	"query": map[string]string{
 		"self":        "/api/v2/query",
 		"ast":         "/api/v2/query/ast",
@@ -163,12 +251,19 @@ var apiLinks = map[string]interface{}{
 		"spec":        "/api/v2/query/spec",
 		"suggestions": "/api/v2/query/suggestions",
 	},
-	"setup":    "/api/v2/setup",
-	"signin":   "/api/v2/signin",
-	"signout":  "/api/v2/signout",
-	"sources":  "/api/v2/sources",
-	"scrapers": "/api/v2/scrapers",
-	"swagger":  "/api/v2/swagger.json",
+	"roles":      "/api/v2/roles",
+	"setup":      "/api/v2/setup",
+	"sharelinks": "/api/v2/sharelinks",
+	"signin":     "/api/v2/signin",
+	"signout":    "/api/v2/signout",
+	"sources":    "/api/v2/sources",
+	"scrapers":   "/api/v2/scrapers",
+	"storage": map[string]string{
+		"compactions": "/api/v2/storage/compactions",
+		"import":      "/api/v2/storage/import",
+		"index":       "/api/v2/storage/index",
+	},
+	"swagger": "/api/v2/swagger.json",
 	"system": map[string]string{
 		"metrics": "/metrics",
 		"debug":   "/debug/pprof",
@@ -201,7 +296,7 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if r.URL.Path == "/api/v2/signin" || r.URL.Path == "/api/v2/signout" {
+	if r.URL.Path == "/api/v2/signin" || r.URL.Path == "/api/v2/signout" || strings.HasPrefix(r.URL.Path, "/api/v2/signin/") {
 		h.SessionHandler.ServeHTTP(w, r)
 		return
 	}
@@ -216,11 +311,41 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/queries/history") || strings.HasPrefix(r.URL.Path, "/api/v2/queries/settings") {
+		h.QueryLogHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/queries") {
+		h.ActiveQueryHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/query") {
 		h.QueryHandler.ServeHTTP(w, r)
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/prometheus") || strings.HasPrefix(r.URL.Path, "/api/v1/") {
+		h.PrometheusHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/storage/compactions") {
+		h.CompactionHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/storage/index") {
+		h.IndexHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/storage/import") {
+		h.ImportHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/buckets") {
 		h.BucketHandler.ServeHTTP(w, r)
 		return
@@ -256,6 +381,26 @@ func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/api/v2/share") {
+		h.ShareLinkHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/invitations") {
+		h.InvitationHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/roles") {
+		h.RoleHandler.ServeHTTP(w, r)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/api/v2/audit") {
+		h.AuditHandler.ServeHTTP(w, r)
+		return
+	}
+
 	if strings.HasPrefix(r.URL.Path, "/api/v2/sources") {
 		h.SourceHandler.ServeHTTP(w, r)
 		return
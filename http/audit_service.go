@@ -0,0 +1,229 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	auditPath = "/api/v2/audit"
+)
+
+// AuditBackend is all services and associated parameters required to construct
+// the AuditHandler.
+type AuditBackend struct {
+	Logger              *zap.Logger
+	AuditService        platform.AuditService
+	OrganizationService platform.OrganizationService
+}
+
+// NewAuditBackend creates a backend used by the audit handler.
+func NewAuditBackend(b *APIBackend) *AuditBackend {
+	return &AuditBackend{
+		Logger:              b.Logger.With(zap.String("handler", "audit")),
+		AuditService:        b.AuditService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// AuditHandler is the handler for the audit service
+type AuditHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	AuditService        platform.AuditService
+	OrganizationService platform.OrganizationService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(b *AuditBackend) *AuditHandler {
+	h := &AuditHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		AuditService:        b.AuditService,
+		OrganizationService: b.OrganizationService,
+	}
+
+	h.HandlerFunc("GET", auditPath, h.handleGetAuditEvents)
+
+	return h
+}
+
+type auditEventResponse struct {
+	*platform.AuditEvent
+	Links map[string]string `json:"links,omitempty"`
+}
+
+func newAuditEventResponse(e *platform.AuditEvent) auditEventResponse {
+	resp := auditEventResponse{AuditEvent: e}
+	if e.ResourceID.Valid() && e.ResourceType != platform.SecretsResourceType {
+		resp.Links = map[string]string{
+			"resource": fmt.Sprintf("/api/v2/%s/%s", e.ResourceType, e.ResourceID),
+		}
+	}
+	return resp
+}
+
+type getAuditEventsResponse struct {
+	Events []auditEventResponse  `json:"events"`
+	Links  *platform.PagingLinks `json:"links"`
+}
+
+func newGetAuditEventsResponse(f auditEventsFilter, opts platform.FindOptions, events []*platform.AuditEvent) getAuditEventsResponse {
+	resp := getAuditEventsResponse{
+		Events: make([]auditEventResponse, 0, len(events)),
+		Links:  newPagingLinks(auditPath, opts, f, len(events)),
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, newAuditEventResponse(e))
+	}
+	return resp
+}
+
+// auditEventsFilter adds the query params of the filter to the paging links.
+type auditEventsFilter struct {
+	platform.AuditEventFilter
+}
+
+func (f auditEventsFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{}
+	if f.OrgID != nil {
+		qp["orgID"] = []string{f.OrgID.String()}
+	}
+	if f.ResourceType != nil {
+		qp["resourceType"] = []string{string(*f.ResourceType)}
+	}
+	if f.ResourceID != nil {
+		qp["resourceID"] = []string{f.ResourceID.String()}
+	}
+	if f.UserID != nil {
+		qp["userID"] = []string{f.UserID.String()}
+	}
+	if f.Action != nil {
+		qp["action"] = []string{*f.Action}
+	}
+	if f.Since != nil {
+		qp["start"] = []string{f.Since.Format(time.RFC3339Nano)}
+	}
+	if f.Until != nil {
+		qp["stop"] = []string{f.Until.Format(time.RFC3339Nano)}
+	}
+	return qp
+}
+
+type getAuditEventsRequest struct {
+	filter auditEventsFilter
+	opts   platform.FindOptions
+}
+
+func decodeGetAuditEventsRequest(ctx context.Context, r *http.Request, orgs platform.OrganizationService) (*getAuditEventsRequest, error) {
+	qp := r.URL.Query()
+	req := &getAuditEventsRequest{}
+
+	opts, err := decodeFindOptions(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	// The most recent events are returned first unless asked otherwise.
+	if qp.Get("descending") == "" {
+		opts.Descending = true
+	}
+	req.opts = *opts
+
+	f := &req.filter
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = id
+	} else if org := qp.Get("org"); org != "" {
+		o, err := orgs.FindOrganization(ctx, platform.OrganizationFilter{Name: &org})
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = &o.ID
+	}
+
+	if rt := qp.Get("resourceType"); rt != "" {
+		t := platform.ResourceType(rt)
+		if err := t.Valid(); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("invalid resource type %q", rt),
+			}
+		}
+		f.ResourceType = &t
+	}
+
+	for _, p := range []struct {
+		name string
+		id   **platform.ID
+	}{
+		{name: "resourceID", id: &f.ResourceID},
+		{name: "userID", id: &f.UserID},
+	} {
+		if v := qp.Get(p.name); v != "" {
+			id, err := platform.IDFromString(v)
+			if err != nil {
+				return nil, err
+			}
+			*p.id = id
+		}
+	}
+
+	if action := qp.Get("action"); action != "" {
+		f.Action = &action
+	}
+
+	for _, p := range []struct {
+		name string
+		t    **time.Time
+	}{
+		{name: "start", t: &f.Since},
+		{name: "stop", t: &f.Until},
+	} {
+		if v := qp.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, &platform.Error{
+					Code: platform.EInvalid,
+					Msg:  fmt.Sprintf("%s must be an RFC3339 time", p.name),
+					Err:  err,
+				}
+			}
+			*p.t = &t
+		}
+	}
+
+	return req, nil
+}
+
+func (h *AuditHandler) handleGetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetAuditEventsRequest(ctx, r, h.OrganizationService)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	events, _, err := h.AuditService.FindAuditEvents(ctx, req.filter.AuditEventFilter, req.opts)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newGetAuditEventsResponse(req.filter, req.opts, events)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+// NewMockAuditBackend returns a AuditBackend with mock services.
+func NewMockAuditBackend() *AuditBackend {
+	return &AuditBackend{
+		Logger:              zap.NewNop().With(zap.String("handler", "audit")),
+		AuditService:        mock.NewAuditService(),
+		OrganizationService: mock.NewOrganizationService(),
+	}
+}
+
+func TestAuditHandler_handleGetAuditEvents(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082000")
+	bucketID := platformtesting.MustIDBase16("020f755c3c082001")
+	userID := platformtesting.MustIDBase16("020f755c3c082002")
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	backend := NewMockAuditBackend()
+	backend.AuditService = &mock.AuditService{
+		FindAuditEventsF: func(ctx context.Context, filter platform.AuditEventFilter, opts ...platform.FindOptions) ([]*platform.AuditEvent, int, error) {
+			if filter.OrgID == nil || *filter.OrgID != orgID {
+				t.Errorf("unexpected org filter %v", filter.OrgID)
+			}
+			if filter.ResourceType == nil || *filter.ResourceType != platform.BucketsResourceType {
+				t.Errorf("unexpected resource type filter %v", filter.ResourceType)
+			}
+			if filter.Since == nil || !filter.Since.Equal(start) {
+				t.Errorf("unexpected start filter %v", filter.Since)
+			}
+			if len(opts) != 1 || !opts[0].Descending || opts[0].Limit != 1 {
+				t.Errorf("unexpected find options %+v", opts)
+			}
+			return []*platform.AuditEvent{
+				{
+					ID:           platformtesting.MustIDBase16("020f755c3c082003"),
+					Time:         start.Add(time.Minute),
+					Action:       platform.AuditActionUpdate,
+					ResourceType: platform.BucketsResourceType,
+					ResourceID:   bucketID,
+					OrgID:        orgID,
+					UserID:       userID,
+					Before:       json.RawMessage(`{"name":"b1"}`),
+					After:        json.RawMessage(`{"name":"b2"}`),
+				},
+			}, 1, nil
+		},
+	}
+	h := NewAuditHandler(backend)
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/audit?orgID=020f755c3c082000&resourceType=buckets&start=2019-06-01T00:00:00Z&limit=1", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != 200 {
+		t.Fatalf("got status %d, want 200: %s", res.StatusCode, body)
+	}
+	want := `
+{
+  "links": {
+    "self": "/api/v2/audit?descending=true&limit=1&offset=0&orgID=020f755c3c082000&resourceType=buckets&start=2019-06-01T00%3A00%3A00Z",
+    "next": "/api/v2/audit?descending=true&limit=1&offset=1&orgID=020f755c3c082000&resourceType=buckets&start=2019-06-01T00%3A00%3A00Z"
+  },
+  "events": [
+    {
+      "id": "020f755c3c082003",
+      "time": "2019-06-01T00:01:00Z",
+      "action": "update",
+      "resourceType": "buckets",
+      "resourceID": "020f755c3c082001",
+      "orgID": "020f755c3c082000",
+      "userID": "020f755c3c082002",
+      "before": {"name": "b1"},
+      "after": {"name": "b2"},
+      "links": {
+        "resource": "/api/v2/buckets/020f755c3c082001"
+      }
+    }
+  ]
+}`
+	if eq, diff, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("got body different from want: %s", diff)
+	}
+}
+
+func TestAuditHandler_handleGetAuditEvents_InvalidResourceType(t *testing.T) {
+	h := NewAuditHandler(NewMockAuditBackend())
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/audit?resourceType=nope", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if res := w.Result(); res.StatusCode != 400 {
+		t.Errorf("got status %d, want 400", res.StatusCode)
+	}
+}
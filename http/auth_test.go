@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -569,6 +570,93 @@ func TestService_handlePostAuthorization(t *testing.T) {
 	}
 }
 
+func TestService_handlePatchAuthorization(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		wants wants
+	}{
+		{
+			name: "expiry in the future",
+			body: `{"expiresAt": "2100-01-01T00:00:00Z"}`,
+			wants: wants{
+				statusCode: http.StatusOK,
+			},
+		},
+		{
+			name: "expiry in the past",
+			body: `{"expiresAt": "2019-01-01T00:00:00Z"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code":"invalid","message":"expiresAt must be in the future"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated bool
+			authorizationBackend := NewMockAuthorizationBackend()
+			authorizationBackend.AuthorizationService = &mock.AuthorizationService{
+				FindAuthorizationByIDFn: func(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+					return &platform.Authorization{ID: id, OrgID: 1, UserID: 2}, nil
+				},
+				UpdateAuthorizationFn: func(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
+					updated = true
+					return &platform.Authorization{ID: id, OrgID: 1, UserID: 2, ExpiresAt: upd.ExpiresAt}, nil
+				},
+			}
+			authorizationBackend.OrganizationService = &mock.OrganizationService{
+				FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+					return &platform.Organization{ID: id, Name: "o1"}, nil
+				},
+			}
+			authorizationBackend.UserService = &mock.UserService{
+				FindUserByIDFn: func(ctx context.Context, id platform.ID) (*platform.User, error) {
+					return &platform.User{ID: id, Name: "u1"}, nil
+				},
+			}
+			h := NewAuthorizationHandler(authorizationBackend)
+
+			r := httptest.NewRequest("PATCH", "http://any.url", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "020f755c3c082000",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+
+			h.handleUpdateAuthorization(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. handleUpdateAuthorization() = %v, want %v: %s", tt.name, res.StatusCode, tt.wants.statusCode, body)
+			}
+			if updated != (tt.wants.statusCode == http.StatusOK) {
+				t.Errorf("%q. handleUpdateAuthorization() updated the authorization = %v", tt.name, updated)
+			}
+			if tt.wants.body == "" {
+				return
+			}
+			if eq, diff, _ := jsonEqual(string(body), tt.wants.body); !eq {
+				t.Errorf("%q. handleUpdateAuthorization() = ***%s***", tt.name, diff)
+			}
+		})
+	}
+}
+
 func TestService_handleDeleteAuthorization(t *testing.T) {
 	type fields struct {
 		AuthorizationService platform.AuthorizationService
@@ -738,7 +826,13 @@ func initAuthorizationService(f platformtesting.AuthorizationFields, t *testing.
 
 	authZ := NewAuthorizationHandler(authorizationBackend)
 	authN := NewAuthenticationHandler()
-	authN.AuthorizationService = svc
+	// Recording the use of the token would change the authorizations under test.
+	authN.AuthorizationService = &mock.AuthorizationService{
+		FindAuthorizationByTokenFn: svc.FindAuthorizationByToken,
+		UpdateAuthorizationFn: func(context.Context, platform.ID, *platform.AuthorizationUpdate) (*platform.Authorization, error) {
+			return nil, nil
+		},
+	}
 	authN.Handler = authZ
 
 	server := httptest.NewServer(authN)
@@ -784,3 +878,7 @@ func MustMarshal(o interface{}) []byte {
 	b, _ := json.Marshal(o)
 	return b
 }
+
+func TestAuthorizationService_RotateAuthorization(t *testing.T) {
+	platformtesting.RotateAuthorization(initAuthorizationService, t)
+}
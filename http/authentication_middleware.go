@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"time"
@@ -19,6 +20,11 @@ type AuthenticationHandler struct {
 	AuthorizationService platform.AuthorizationService
 	SessionService       platform.SessionService
 
+	// ClientCertificates, if set, authenticates requests without a token or
+	// session that present a verified TLS client certificate mapped to an
+	// authorization.
+	ClientCertificates ClientCertificateMapper
+
 	// This is only really used for it's lookup method the specific http
 	// hanlder used to register routes does not matter.
 	noAuthRouter *httprouter.Router
@@ -41,9 +47,16 @@ func (h *AuthenticationHandler) RegisterNoAuthRoute(method, path string) {
 	h.noAuthRouter.HandlerFunc(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 }
 
+// ClientCertificateMapper maps verified TLS client certificates to the
+// authorization they authenticate as.
+type ClientCertificateMapper interface {
+	AuthorizationID(cert *x509.Certificate) (platform.ID, bool)
+}
+
 const (
-	tokenAuthScheme   = "token"
-	sessionAuthScheme = "session"
+	tokenAuthScheme       = "token"
+	sessionAuthScheme     = "session"
+	certificateAuthScheme = "certificate"
 )
 
 // ProbeAuthScheme probes the http request for the requests for token or cookie session.
@@ -71,6 +84,9 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 
 	ctx := r.Context()
 	scheme, err := ProbeAuthScheme(r)
+	if err != nil && h.ClientCertificates != nil && clientCertificate(r) != nil {
+		scheme, err = certificateAuthScheme, nil
+	}
 	if err != nil {
 		UnauthorizedError(ctx, w)
 		return
@@ -93,6 +109,14 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		r = r.WithContext(ctx)
 		h.Handler.ServeHTTP(w, r)
 		return
+	case certificateAuthScheme:
+		ctx, err = h.extractCertificate(ctx, r)
+		if err != nil {
+			break
+		}
+		r = r.WithContext(ctx)
+		h.Handler.ServeHTTP(w, r)
+		return
 	}
 
 	UnauthorizedError(ctx, w)
@@ -109,9 +133,66 @@ func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *htt
 		return ctx, err
 	}
 
+	if err := a.Expired(); err != nil {
+		return ctx, err
+	}
+
+	h.touchAuthorization(ctx, a)
+
 	return platcontext.SetAuthorizer(ctx, a), nil
 }
 
+// clientCertificate returns the verified TLS client certificate of the
+// request, if any.
+func clientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
+
+func (h *AuthenticationHandler) extractCertificate(ctx context.Context, r *http.Request) (context.Context, error) {
+	id, ok := h.ClientCertificates.AuthorizationID(clientCertificate(r))
+	if !ok {
+		return ctx, fmt.Errorf("client certificate is not mapped to an authorization")
+	}
+
+	a, err := h.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return ctx, err
+	}
+
+	if !a.IsActive() {
+		return ctx, fmt.Errorf("authorization %s is inactive", a.ID)
+	}
+	if err := a.Expired(); err != nil {
+		return ctx, err
+	}
+
+	h.touchAuthorization(ctx, a)
+
+	return platcontext.SetAuthorizer(ctx, a), nil
+}
+
+// lastUsedResolution is how stale the recorded last use of a token may get
+// before it is updated. It bounds the writes made for frequently used tokens.
+const lastUsedResolution = time.Minute
+
+// touchAuthorization records that the authorization was used. Failures are
+// logged rather than failing the request.
+func (h *AuthenticationHandler) touchAuthorization(ctx context.Context, a *platform.Authorization) {
+	now := time.Now().UTC()
+	if a.LastUsedAt != nil && now.Sub(*a.LastUsedAt) < lastUsedResolution {
+		return
+	}
+
+	if _, err := h.AuthorizationService.UpdateAuthorization(ctx, a.ID, &platform.AuthorizationUpdate{LastUsedAt: &now}); err != nil {
+		h.Logger.Info("failed to record authorization use", zap.String("authorization", a.ID.String()), zap.Error(err))
+		return
+	}
+	a.LastUsedAt = &now
+}
+
 func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Request) (context.Context, error) {
 	k, err := decodeCookieSession(ctx, r)
 	if err != nil {
@@ -2,6 +2,9 @@ package http_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +14,7 @@ import (
 	platform "github.com/influxdata/influxdb"
 	platformhttp "github.com/influxdata/influxdb/http"
 	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/tlsconfig"
 )
 
 func TestAuthenticationHandler(t *testing.T) {
@@ -76,6 +80,9 @@ func TestAuthenticationHandler(t *testing.T) {
 					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
 						return &platform.Authorization{}, nil
 					},
+					UpdateAuthorizationFn: func(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
+						return nil, nil
+					},
 				},
 				SessionService: mock.NewSessionService(),
 			},
@@ -86,6 +93,24 @@ func TestAuthenticationHandler(t *testing.T) {
 				code: http.StatusOK,
 			},
 		},
+		{
+			name: "token expired",
+			fields: fields{
+				AuthorizationService: &mock.AuthorizationService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+						expiresAt := time.Now().Add(-time.Hour)
+						return &platform.Authorization{ExpiresAt: &expiresAt}, nil
+					},
+				},
+				SessionService: mock.NewSessionService(),
+			},
+			args: args{
+				token: "abc123",
+			},
+			wants: wants{
+				code: http.StatusUnauthorized,
+			},
+		},
 		{
 			name: "token does not exist",
 			fields: fields{
@@ -147,6 +172,100 @@ func TestAuthenticationHandler(t *testing.T) {
 	}
 }
 
+func TestAuthenticationHandler_ClientCertificate(t *testing.T) {
+	authID := platform.ID(1)
+	subjects, err := tlsconfig.NewSubjects([]tlsconfig.Subject{
+		{Subject: "CN=telegraf,O=Acme", AuthorizationID: authID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		mapper   platformhttp.ClientCertificateMapper
+		subject  pkix.Name
+		status   platform.Status
+		verified bool
+		code     int
+	}{
+		{
+			name:     "mapped certificate",
+			mapper:   subjects,
+			subject:  pkix.Name{CommonName: "telegraf", Organization: []string{"Acme"}},
+			status:   platform.Active,
+			verified: true,
+			code:     http.StatusOK,
+		},
+		{
+			name:     "unmapped certificate",
+			mapper:   subjects,
+			subject:  pkix.Name{CommonName: "other", Organization: []string{"Acme"}},
+			status:   platform.Active,
+			verified: true,
+			code:     http.StatusUnauthorized,
+		},
+		{
+			name:     "inactive authorization",
+			mapper:   subjects,
+			subject:  pkix.Name{CommonName: "telegraf", Organization: []string{"Acme"}},
+			status:   platform.Inactive,
+			verified: true,
+			code:     http.StatusUnauthorized,
+		},
+		{
+			name:    "unverified certificate",
+			mapper:  subjects,
+			subject: pkix.Name{CommonName: "telegraf", Organization: []string{"Acme"}},
+			status:  platform.Active,
+			code:    http.StatusUnauthorized,
+		},
+		{
+			name:     "certificates not mapped",
+			subject:  pkix.Name{CommonName: "telegraf", Organization: []string{"Acme"}},
+			status:   platform.Active,
+			verified: true,
+			code:     http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := platformhttp.NewAuthenticationHandler()
+			h.AuthorizationService = &mock.AuthorizationService{
+				FindAuthorizationByIDFn: func(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+					if id != authID {
+						return nil, fmt.Errorf("authorization not found")
+					}
+					return &platform.Authorization{ID: id, Status: tt.status}, nil
+				},
+				UpdateAuthorizationFn: func(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
+					return nil, nil
+				},
+			}
+			h.SessionService = mock.NewSessionService()
+			h.ClientCertificates = tt.mapper
+			h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "https://any.url", nil)
+			cert := &x509.Certificate{Subject: tt.subject}
+			r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+			if tt.verified {
+				r.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+			}
+
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("expected status code to be %d got %d", want, got)
+			}
+		})
+	}
+}
+
 func TestProbeAuthScheme(t *testing.T) {
 	type args struct {
 		token   string
@@ -63,6 +63,16 @@ var (
 	defaultTransport = &http.Transport{}
 )
 
+// SetClientTLSConfig sets the TLS configuration of the transport shared by
+// the clients of this package, such as the certificate authorities trusted
+// in addition to the system ones. Clients with InsecureSkipVerify set skip
+// verification regardless.
+//
+// It is not safe to call SetClientTLSConfig while clients are in use.
+func SetClientTLSConfig(c *tls.Config) {
+	defaultTransport = &http.Transport{TLSClientConfig: c}
+}
+
 func newURL(addr, path string) (*url.URL, error) {
 	u, err := url.Parse(addr)
 	if err != nil {
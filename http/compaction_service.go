@@ -0,0 +1,240 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// CompactionBackend is all services and associated parameters required to construct
+// the CompactionHandler.
+type CompactionBackend struct {
+	Logger *zap.Logger
+
+	CompactionService storage.CompactionService
+}
+
+// NewCompactionBackend returns a new instance of CompactionBackend.
+func NewCompactionBackend(b *APIBackend) *CompactionBackend {
+	return &CompactionBackend{
+		Logger: b.Logger.With(zap.String("handler", "compaction")),
+
+		CompactionService: b.CompactionService,
+	}
+}
+
+// CompactionHandler represents an HTTP API handler for inspecting and
+// controlling storage engine compactions.
+type CompactionHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	CompactionService storage.CompactionService
+}
+
+const (
+	compactionsPath       = "/api/v2/storage/compactions"
+	compactionsPausePath  = "/api/v2/storage/compactions/pause"
+	compactionsResumePath = "/api/v2/storage/compactions/resume"
+	compactionsFullPath   = "/api/v2/storage/compactions/full"
+)
+
+// NewCompactionHandler returns a new instance of CompactionHandler.
+func NewCompactionHandler(b *CompactionBackend) *CompactionHandler {
+	h := &CompactionHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		CompactionService: b.CompactionService,
+	}
+
+	h.HandlerFunc("GET", compactionsPath, h.handleGetCompactions)
+	h.HandlerFunc("PATCH", compactionsPath, h.handlePatchCompactions)
+	h.HandlerFunc("POST", compactionsPausePath, h.handlePostPause)
+	h.HandlerFunc("POST", compactionsResumePath, h.handlePostResume)
+	h.HandlerFunc("POST", compactionsFullPath, h.handlePostFull)
+	return h
+}
+
+// handleGetCompactions is the HTTP handler for the GET /api/v2/storage/compactions route.
+func (h *CompactionHandler) handleGetCompactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	status, err := h.CompactionService.CompactionStatus(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, status); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePatchCompactions is the HTTP handler for the PATCH /api/v2/storage/compactions route.
+func (h *CompactionHandler) handlePatchCompactions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	upd, err := decodePatchCompactionsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	status, err := h.CompactionService.UpdateCompactions(ctx, *upd)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, status); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodePatchCompactionsRequest(ctx context.Context, r *http.Request) (*storage.CompactionUpdate, error) {
+	upd := &storage.CompactionUpdate{}
+	if err := json.NewDecoder(r.Body).Decode(upd); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode compaction update",
+			Err:  err,
+		}
+	}
+
+	if upd.Throughput == nil && upd.ThroughputBurst == nil && upd.MaxConcurrent == nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "compaction update requires throughput, throughputBurst or maxConcurrent",
+		}
+	}
+	return upd, nil
+}
+
+// handlePostPause is the HTTP handler for the POST /api/v2/storage/compactions/pause route.
+func (h *CompactionHandler) handlePostPause(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.CompactionService.PauseCompactions(ctx); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePostResume is the HTTP handler for the POST /api/v2/storage/compactions/resume route.
+func (h *CompactionHandler) handlePostResume(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.CompactionService.ResumeCompactions(ctx); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePostFull is the HTTP handler for the POST /api/v2/storage/compactions/full route.
+func (h *CompactionHandler) handlePostFull(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := h.CompactionService.ScheduleFullCompaction(ctx); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// CompactionService connects to Influx via HTTP using tokens to manage compactions.
+type CompactionService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ storage.CompactionService = (*CompactionService)(nil)
+
+// CompactionStatus returns the running and planned compactions of the server.
+func (s *CompactionService) CompactionStatus(ctx context.Context) (*tsm1.CompactionStatus, error) {
+	var status tsm1.CompactionStatus
+	if err := s.do(ctx, "GET", compactionsPath, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// PauseCompactions pauses level, optimize and full compactions on the server.
+func (s *CompactionService) PauseCompactions(ctx context.Context) error {
+	return s.do(ctx, "POST", compactionsPausePath, nil, nil)
+}
+
+// ResumeCompactions resumes compactions on the server.
+func (s *CompactionService) ResumeCompactions(ctx context.Context) error {
+	return s.do(ctx, "POST", compactionsResumePath, nil, nil)
+}
+
+// ScheduleFullCompaction triggers a full compaction on the server.
+func (s *CompactionService) ScheduleFullCompaction(ctx context.Context) error {
+	return s.do(ctx, "POST", compactionsFullPath, nil, nil)
+}
+
+// UpdateCompactions changes the compaction settings of the server.
+func (s *CompactionService) UpdateCompactions(ctx context.Context, upd storage.CompactionUpdate) (*tsm1.CompactionStatus, error) {
+	var status tsm1.CompactionStatus
+	if err := s.do(ctx, "PATCH", compactionsPath, upd, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (s *CompactionService) do(ctx context.Context, method, path string, body, v interface{}) error {
+	u, err := newURL(s.Addr, path)
+	if err != nil {
+		return err
+	}
+
+	var octets []byte
+	if body != nil {
+		if octets, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,151 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+// NewMockCompactionBackend returns a CompactionBackend with mock services.
+func NewMockCompactionBackend() *CompactionBackend {
+	return &CompactionBackend{
+		Logger: zap.NewNop().With(zap.String("handler", "compaction")),
+
+		CompactionService: mock.NewCompactionService(),
+	}
+}
+
+func TestCompactionHandler(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	started := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	status := &tsm1.CompactionStatus{
+		Throughput:      1024,
+		ThroughputBurst: 2048,
+		MaxConcurrent:   2,
+		Running: []tsm1.CompactionInfo{
+			{Level: "1", Files: []string{"000000001-000000001.tsm"}, Generations: []int{1}, Bytes: 10, Started: started},
+		},
+		Planned: []tsm1.CompactionInfo{},
+	}
+
+	tests := []struct {
+		name  string
+		svc   func(*mock.CompactionService)
+		r     *http.Request
+		wants wants
+	}{
+		{
+			name: "get compaction status",
+			svc: func(s *mock.CompactionService) {
+				s.CompactionStatusFn = func(context.Context) (*tsm1.CompactionStatus, error) {
+					return status, nil
+				}
+			},
+			r: httptest.NewRequest("GET", "http://any.url/api/v2/storage/compactions", nil),
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `
+{
+  "paused": false,
+  "throughput": 1024,
+  "throughputBurst": 2048,
+  "maxConcurrent": 2,
+  "running": [
+    {
+      "level": "1",
+      "files": ["000000001-000000001.tsm"],
+      "generations": [1],
+      "bytes": 10,
+      "started": "2019-03-01T00:00:00Z"
+    }
+  ],
+  "planned": []
+}`,
+			},
+		},
+		{
+			name: "update compaction settings",
+			svc: func(s *mock.CompactionService) {
+				s.UpdateCompactionsFn = func(ctx context.Context, upd storage.CompactionUpdate) (*tsm1.CompactionStatus, error) {
+					if upd.MaxConcurrent == nil || *upd.MaxConcurrent != 3 || upd.Throughput != nil {
+						t.Errorf("unexpected update %+v", upd)
+					}
+					return &tsm1.CompactionStatus{MaxConcurrent: 3}, nil
+				}
+			},
+			r: httptest.NewRequest("PATCH", "http://any.url/api/v2/storage/compactions", strings.NewReader(`{"maxConcurrent": 3}`)),
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"paused": false, "throughput": 0, "throughputBurst": 0, "maxConcurrent": 3, "running": null, "planned": null}`,
+			},
+		},
+		{
+			name: "update without settings",
+			r:    httptest.NewRequest("PATCH", "http://any.url/api/v2/storage/compactions", strings.NewReader(`{}`)),
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "pause compactions",
+			r:    httptest.NewRequest("POST", "http://any.url/api/v2/storage/compactions/pause", nil),
+			wants: wants{
+				statusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "resume compactions",
+			r:    httptest.NewRequest("POST", "http://any.url/api/v2/storage/compactions/resume", nil),
+			wants: wants{
+				statusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "schedule full compaction",
+			r:    httptest.NewRequest("POST", "http://any.url/api/v2/storage/compactions/full", nil),
+			wants: wants{
+				statusCode: http.StatusAccepted,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compactionBackend := NewMockCompactionBackend()
+			svc := mock.NewCompactionService()
+			if tt.svc != nil {
+				tt.svc(svc)
+			}
+			compactionBackend.CompactionService = svc
+			h := NewCompactionHandler(compactionBackend)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, tt.r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. got %v, want %v", tt.name, res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil || !eq {
+					t.Errorf("%q. -got/+want %s %v", tt.name, diff, err)
+				}
+			}
+		})
+	}
+}
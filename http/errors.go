@@ -119,4 +119,6 @@ var statusCodePlatformError = map[string]int{
 	platform.EForbidden:           http.StatusForbidden,
 	platform.EUnauthorized:        http.StatusUnauthorized,
 	platform.EMethodNotAllowed:    http.StatusMethodNotAllowed,
+	platform.ETooManyRequests:     http.StatusTooManyRequests,
+	platform.ETooLarge:            http.StatusRequestEntityTooLarge,
 }
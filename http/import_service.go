@@ -0,0 +1,219 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkg/csv2lp"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// ImportBackend is all services and associated parameters required to construct
+// the ImportHandler.
+type ImportBackend struct {
+	Logger *zap.Logger
+
+	ImportService storage.ImportService
+	BucketService platform.BucketService
+}
+
+// NewImportBackend returns a new instance of ImportBackend.
+func NewImportBackend(b *APIBackend) *ImportBackend {
+	return &ImportBackend{
+		Logger: b.Logger.With(zap.String("handler", "import")),
+
+		ImportService: b.ImportService,
+		BucketService: b.BucketService,
+	}
+}
+
+// ImportHandler represents an HTTP API handler for bulk importing data
+// directly into the storage engine.
+type ImportHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	ImportService storage.ImportService
+	BucketService platform.BucketService
+}
+
+const (
+	importPath = "/api/v2/storage/import"
+)
+
+// NewImportHandler returns a new instance of ImportHandler.
+func NewImportHandler(b *ImportBackend) *ImportHandler {
+	h := &ImportHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		ImportService: b.ImportService,
+		BucketService: b.BucketService,
+	}
+
+	h.HandlerFunc("POST", importPath, h.handlePostImport)
+	return h
+}
+
+type postImportRequest struct {
+	OrgID    platform.ID
+	BucketID platform.ID
+	CSV      bool
+}
+
+type importResponse struct {
+	Points int `json:"points"`
+}
+
+// handlePostImport is the HTTP handler for the POST /api/v2/storage/import route.
+func (h *ImportHandler) handlePostImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	req, err := decodePostImportRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, req.BucketID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if b.OrganizationID != req.OrgID {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.ENotFound,
+			Op:   "http/handlePostImport",
+			Msg:  "bucket not found in organization",
+		}, w)
+		return
+	}
+
+	var in io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "http/handlePostImport",
+				Msg:  errInvalidGzipHeader,
+				Err:  err,
+			}, w)
+			return
+		}
+		defer gr.Close()
+		in = gr
+	}
+	if req.CSV {
+		in = csv2lp.NewReader(in)
+	}
+
+	n, err := h.ImportService.Import(ctx, req.OrgID, req.BucketID, in)
+	if err != nil {
+		h.Logger.Info("Failed to import data", zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, importResponse{Points: n}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodePostImportRequest(ctx context.Context, r *http.Request) (*postImportRequest, error) {
+	qp := r.URL.Query()
+	req := &postImportRequest{}
+
+	if err := req.OrgID.DecodeFromString(qp.Get("orgID")); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}
+	}
+	if err := req.BucketID.DecodeFromString(qp.Get("bucketID")); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid bucketID",
+			Err:  err,
+		}
+	}
+
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid Content-Type",
+				Err:  err,
+			}
+		}
+		req.CSV = mt == "text/csv"
+	}
+	return req, nil
+}
+
+// ImportService connects to Influx via HTTP using tokens to bulk import line
+// protocol directly into the storage engine.
+type ImportService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ storage.ImportService = (*ImportService)(nil)
+
+// Import sends the line protocol read from r to the server to be imported into
+// the bucket, returning the number of points imported.
+func (s *ImportService) Import(ctx context.Context, orgID, bucketID platform.ID, r io.Reader) (int, error) {
+	u, err := newURL(s.Addr, importPath)
+	if err != nil {
+		return 0, err
+	}
+
+	r, err = compressWithGzip(r)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), r)
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Content-Encoding", "gzip")
+	SetToken(s.Token, req)
+
+	params := req.URL.Query()
+	params.Set("orgID", orgID.String())
+	params.Set("bucketID", bucketID.String())
+	req.URL.RawQuery = params.Encode()
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return 0, err
+	}
+
+	var ir importResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ir); err != nil {
+		return 0, err
+	}
+	return ir.Points, nil
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap"
+)
+
+func TestImportHandler_handlePostImport(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		data        string
+		statusCode  int
+	}{
+		{
+			name:       "import line protocol",
+			query:      "orgID=020f755c3c082000&bucketID=020f755c3c082001",
+			body:       "cpu value=1 10\n",
+			data:       "cpu value=1 10\n",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "import annotated csv",
+			query:       "orgID=020f755c3c082000&bucketID=020f755c3c082001",
+			contentType: "text/csv",
+			body:        "#datatype,string,long,dateTime:RFC3339,double,string,string\n,result,table,_time,_value,_field,_measurement\n,,0,1970-01-01T00:00:00.00000001Z,1,value,cpu\n",
+			data:        "cpu value=1 10\n",
+			statusCode:  http.StatusOK,
+		},
+		{
+			name:       "missing bucket",
+			query:      "orgID=020f755c3c082000",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "bucket in another organization",
+			query:      "orgID=020f755c3c082002&bucketID=020f755c3c082001",
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			svc := mock.NewImportService()
+			svc.ImportFn = func(ctx context.Context, orgID, bucketID platform.ID, r io.Reader) (int, error) {
+				called = true
+				data, err := ioutil.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := string(data); got != tt.data {
+					t.Errorf("got data %q, want %q", got, tt.data)
+				}
+				return 1, nil
+			}
+
+			buckets := mock.NewBucketService()
+			buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+				return &platform.Bucket{ID: id, OrganizationID: platform.ID(0x020f755c3c082000)}, nil
+			}
+
+			h := NewImportHandler(&ImportBackend{
+				Logger:        zap.NewNop(),
+				ImportService: svc,
+				BucketService: buckets,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/storage/import?"+tt.query, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got := w.Result().StatusCode; got != tt.statusCode {
+				t.Errorf("got status code %v, want %v", got, tt.statusCode)
+			}
+			if exp := tt.statusCode == http.StatusOK; called != exp {
+				t.Errorf("got import called %v, want %v", called, exp)
+			}
+			if tt.statusCode == http.StatusOK {
+				if got, want := strings.TrimSpace(w.Body.String()), `{"points":1}`; got != want {
+					t.Errorf("got body %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// IndexBackend is all services and associated parameters required to construct
+// the IndexHandler.
+type IndexBackend struct {
+	Logger *zap.Logger
+
+	IndexService storage.IndexService
+}
+
+// NewIndexBackend returns a new instance of IndexBackend.
+func NewIndexBackend(b *APIBackend) *IndexBackend {
+	return &IndexBackend{
+		Logger: b.Logger.With(zap.String("handler", "index")),
+
+		IndexService: b.IndexService,
+	}
+}
+
+// IndexHandler represents an HTTP API handler for repairing the storage
+// engine's index.
+type IndexHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	IndexService storage.IndexService
+}
+
+const (
+	indexRebuildPath = "/api/v2/storage/index/rebuild"
+)
+
+// NewIndexHandler returns a new instance of IndexHandler.
+func NewIndexHandler(b *IndexBackend) *IndexHandler {
+	h := &IndexHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		IndexService: b.IndexService,
+	}
+
+	h.HandlerFunc("POST", indexRebuildPath, h.handlePostIndexRebuild)
+	return h
+}
+
+type postIndexRebuildRequest struct {
+	Partitions []int `json:"partitions,omitempty"`
+}
+
+// handlePostIndexRebuild is the HTTP handler for the POST /api/v2/storage/index/rebuild route.
+func (h *IndexHandler) handlePostIndexRebuild(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodePostIndexRebuildRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.IndexService.RebuildIndex(ctx, req.Partitions); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodePostIndexRebuildRequest(ctx context.Context, r *http.Request) (*postIndexRebuildRequest, error) {
+	req := &postIndexRebuildRequest{}
+
+	// An empty body rebuilds every partition.
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil && err != io.EOF {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode index rebuild request",
+			Err:  err,
+		}
+	}
+	return req, nil
+}
+
+// IndexService connects to Influx via HTTP using tokens to repair the storage
+// engine's index.
+type IndexService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ storage.IndexService = (*IndexService)(nil)
+
+// RebuildIndex rebuilds the provided index partitions, or all of them if none
+// are provided, on the server.
+func (s *IndexService) RebuildIndex(ctx context.Context, partitions []int) error {
+	u, err := newURL(s.Addr, indexRebuildPath)
+	if err != nil {
+		return err
+	}
+
+	octets, err := json.Marshal(postIndexRebuildRequest{Partitions: partitions})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(octets))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap"
+)
+
+func TestIndexHandler_handlePostIndexRebuild(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		partitions []int
+		statusCode int
+	}{
+		{
+			name:       "rebuild all partitions",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "rebuild some partitions",
+			body:       `{"partitions": [1, 3]}`,
+			partitions: []int{1, 3},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "invalid request",
+			body:       `{"partitions": "1"}`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			svc := mock.NewIndexService()
+			svc.RebuildIndexFn = func(ctx context.Context, partitions []int) error {
+				called = true
+				if !reflect.DeepEqual(partitions, tt.partitions) {
+					t.Errorf("got partitions %v, want %v", partitions, tt.partitions)
+				}
+				return nil
+			}
+
+			h := NewIndexHandler(&IndexBackend{
+				Logger:       zap.NewNop(),
+				IndexService: svc,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/storage/index/rebuild", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got := w.Result().StatusCode; got != tt.statusCode {
+				t.Errorf("got status code %v, want %v", got, tt.statusCode)
+			}
+			if exp := tt.statusCode == http.StatusNoContent; called != exp {
+				t.Errorf("got rebuild called %v, want %v", called, exp)
+			}
+		})
+	}
+}
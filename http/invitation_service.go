@@ -0,0 +1,619 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	platcontext "github.com/influxdata/influxdb/context"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	invitationPath = "/api/v2/invitations"
+	// invitationAcceptPath adds the signed in user to the organization of an
+	// invitation.
+	invitationAcceptPath = "/api/v2/invitations/accept"
+	// invitationRegisterPath creates a user for an invitation. It is not
+	// authenticated.
+	invitationRegisterPath = "/api/v2/invitations/register"
+)
+
+// InvitationBackend is all services and associated parameters required to
+// construct the InvitationHandler.
+type InvitationBackend struct {
+	Logger              *zap.Logger
+	InvitationService   platform.InvitationService
+	OrganizationService platform.OrganizationService
+
+	// The services below accept invitations. Invitations are authorized by
+	// their token, so they are not wrapped by authorizers.
+	InvitationTokenService     platform.InvitationService
+	UserService                platform.UserService
+	PasswordsService           platform.PasswordsService
+	UserResourceMappingService platform.UserResourceMappingService
+}
+
+// NewInvitationBackend creates a backend used by the invitation handler.
+func NewInvitationBackend(b *APIBackend) *InvitationBackend {
+	return &InvitationBackend{
+		Logger:              b.Logger.With(zap.String("handler", "invitation")),
+		InvitationService:   b.InvitationService,
+		OrganizationService: b.OrganizationService,
+
+		InvitationTokenService:     b.InvitationService,
+		UserService:                b.UserService,
+		PasswordsService:           b.PasswordsService,
+		UserResourceMappingService: b.UserResourceMappingService,
+	}
+}
+
+// InvitationHandler is the handler for the invitation service
+type InvitationHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	InvitationService   platform.InvitationService
+	OrganizationService platform.OrganizationService
+
+	InvitationTokenService     platform.InvitationService
+	UserService                platform.UserService
+	PasswordsService           platform.PasswordsService
+	UserResourceMappingService platform.UserResourceMappingService
+}
+
+// NewInvitationHandler creates a new InvitationHandler
+func NewInvitationHandler(b *InvitationBackend) *InvitationHandler {
+	h := &InvitationHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		InvitationService:   b.InvitationService,
+		OrganizationService: b.OrganizationService,
+
+		InvitationTokenService:     b.InvitationTokenService,
+		UserService:                b.UserService,
+		PasswordsService:           b.PasswordsService,
+		UserResourceMappingService: b.UserResourceMappingService,
+	}
+
+	h.HandlerFunc("GET", invitationPath, h.handleGetInvitations)
+	h.HandlerFunc("POST", invitationPath, h.handlePostInvitation)
+	h.HandlerFunc("POST", invitationAcceptPath, h.handleAcceptInvitation)
+	h.HandlerFunc("POST", invitationRegisterPath, h.handleRegisterInvitation)
+	h.HandlerFunc("GET", invitationPath+"/:id", h.handleGetInvitation)
+	h.HandlerFunc("DELETE", invitationPath+"/:id", h.handleDeleteInvitation)
+
+	return h
+}
+
+type invitationResponse struct {
+	*platform.Invitation
+	Links map[string]string `json:"links"`
+}
+
+func newInvitationResponse(i *platform.Invitation) invitationResponse {
+	return invitationResponse{
+		Invitation: i,
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", invitationPath, i.ID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", i.OrgID),
+		},
+	}
+}
+
+// withoutToken returns a copy of the invitation without its token. Tokens are
+// only returned to the creator of an invitation.
+func withoutToken(i *platform.Invitation) *platform.Invitation {
+	c := *i
+	c.Token = ""
+	return &c
+}
+
+type getInvitationsResponse struct {
+	Invitations []invitationResponse `json:"invitations"`
+	Links       map[string]string    `json:"links"`
+}
+
+func newGetInvitationsResponse(is []*platform.Invitation) getInvitationsResponse {
+	resp := getInvitationsResponse{
+		Invitations: make([]invitationResponse, 0, len(is)),
+		Links: map[string]string{
+			"self": invitationPath,
+		},
+	}
+	for _, i := range is {
+		resp.Invitations = append(resp.Invitations, newInvitationResponse(withoutToken(i)))
+	}
+	return resp
+}
+
+func decodeGetInvitationsRequest(ctx context.Context, r *http.Request, orgs platform.OrganizationService) (*platform.InvitationFilter, error) {
+	qp := r.URL.Query()
+	f := &platform.InvitationFilter{}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = id
+	} else if org := qp.Get("org"); org != "" {
+		o, err := orgs.FindOrganization(ctx, platform.OrganizationFilter{Name: &org})
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = &o.ID
+	}
+
+	return f, nil
+}
+
+func (h *InvitationHandler) handleGetInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeGetInvitationsRequest(ctx, r, h.OrganizationService)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	is, _, err := h.InvitationService.FindInvitations(ctx, *filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newGetInvitationsResponse(is)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// postInvitationRequest is the part of an invitation set by clients.
+type postInvitationRequest struct {
+	OrgID     platform.ID       `json:"orgID"`
+	Email     string            `json:"email"`
+	Username  string            `json:"username"`
+	Role      platform.UserType `json:"role"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+func (h *InvitationHandler) handlePostInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	i := &platform.Invitation{
+		OrgID:     req.OrgID,
+		Email:     req.Email,
+		Username:  req.Username,
+		Role:      req.Role,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if i.Role == "" {
+		i.Role = platform.Member
+	}
+	if err := i.Validate(); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.InvitationService.CreateInvitation(ctx, i); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newInvitationResponse(i)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func requestInvitationID(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName("id")
+	if urlID == "" {
+		return platform.InvalidID(), &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	id, err := platform.IDFromString(urlID)
+	if err != nil {
+		return platform.InvalidID(), &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}
+	}
+
+	return *id, nil
+}
+
+func (h *InvitationHandler) handleGetInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestInvitationID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	i, err := h.InvitationService.FindInvitationByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newInvitationResponse(withoutToken(i))); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *InvitationHandler) handleDeleteInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestInvitationID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.InvitationService.DeleteInvitation(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// acceptInvitationRequest accepts an invitation as the signed in user.
+type acceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// findInvitation looks up the unexpired invitation with token without
+// consuming it.
+func (h *InvitationHandler) findInvitation(ctx context.Context, token string) (*platform.Invitation, error) {
+	if token == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invitation token is required",
+		}
+	}
+
+	i, err := h.InvitationTokenService.FindInvitationByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.Expired(); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// joinOrganization consumes the invitation and maps the user to its
+// organization with the invited role.
+func (h *InvitationHandler) joinOrganization(ctx context.Context, token string, userID platform.ID) (*platform.Invitation, error) {
+	i, err := h.InvitationTokenService.ConsumeInvitation(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &platform.UserResourceMapping{
+		ResourceType: platform.OrgsResourceType,
+		ResourceID:   i.OrgID,
+		UserID:       userID,
+		UserType:     i.Role,
+	}
+	if err := h.UserResourceMappingService.CreateUserResourceMapping(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func (h *InvitationHandler) handleAcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req acceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	a, err := platcontext.GetAuthorizer(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	i, err := h.findInvitation(ctx, req.Token)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	// Invitations for a username can only be accepted by that user.
+	if i.Username != "" {
+		u, err := h.UserService.FindUserByID(ctx, a.GetUserID())
+		if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+		if u.Name != i.Username {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.EForbidden,
+				Msg:  "invitation is for another user",
+			}, w)
+			return
+		}
+	}
+
+	ms, _, err := h.UserResourceMappingService.FindUserResourceMappings(ctx, platform.UserResourceMappingFilter{
+		ResourceType: platform.OrgsResourceType,
+		ResourceID:   i.OrgID,
+		UserID:       a.GetUserID(),
+	})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	if len(ms) > 0 {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EConflict,
+			Msg:  "user is already a member of the organization",
+		}, w)
+		return
+	}
+
+	i, err = h.joinOrganization(ctx, req.Token, a.GetUserID())
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newInvitationResponse(i)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// registerInvitationRequest accepts an invitation as a new user.
+type registerInvitationRequest struct {
+	Token    string `json:"token"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// handleRegisterInvitation creates a user for someone invited who does not
+// have an account yet, and adds them to the organization of the invitation.
+func (h *InvitationHandler) handleRegisterInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req registerInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	i, err := h.findInvitation(ctx, req.Token)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	name := req.Name
+	switch {
+	case i.Username != "" && name == "":
+		name = i.Username
+	case i.Username != "" && name != i.Username:
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "name must be the invited username",
+		}, w)
+		return
+	case name == "":
+		name = i.Email
+	}
+
+	if _, err := h.UserService.FindUser(ctx, platform.UserFilter{Name: &name}); err == nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EConflict,
+			Msg:  fmt.Sprintf("user with name %s already exists", name),
+		}, w)
+		return
+	} else if platform.ErrorCode(err) != platform.ENotFound {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	u := &platform.User{Name: name}
+	if err := h.UserService.CreateUser(ctx, u); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.PasswordsService.SetPassword(ctx, u.Name, req.Password); err != nil {
+		h.deleteUser(ctx, u.ID)
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if _, err := h.joinOrganization(ctx, req.Token, u.ID); err != nil {
+		h.deleteUser(ctx, u.ID)
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newUserResponse(u)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// deleteUser removes a user created for an invitation that could not be
+// accepted.
+func (h *InvitationHandler) deleteUser(ctx context.Context, id platform.ID) {
+	if err := h.UserService.DeleteUser(ctx, id); err != nil {
+		h.Logger.Info("failed to delete user of unaccepted invitation", zap.Stringer("user", id), zap.Error(err))
+	}
+}
+
+// InvitationService connects to Influx via HTTP using tokens to manage invitations.
+type InvitationService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+func invitationIDPath(id platform.ID) string {
+	return path.Join(invitationPath, id.String())
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into v if it is set.
+func (s *InvitationService) do(ctx context.Context, method, p string, query map[string]string, body, v interface{}) error {
+	u, err := newURL(s.Addr, p)
+	if err != nil {
+		return err
+	}
+
+	qp := u.Query()
+	for k, val := range query {
+		qp.Set(k, val)
+	}
+	u.RawQuery = qp.Encode()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, u.String(), &buf)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// FindInvitationByID returns a single invitation by ID.
+func (s *InvitationService) FindInvitationByID(ctx context.Context, id platform.ID) (*platform.Invitation, error) {
+	var resp invitationResponse
+	if err := s.do(ctx, "GET", invitationIDPath(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Invitation, nil
+}
+
+// FindInvitations returns a list of invitations that match filter and the total count of matching invitations.
+func (s *InvitationService) FindInvitations(ctx context.Context, filter platform.InvitationFilter) ([]*platform.Invitation, int, error) {
+	if filter.ID != nil {
+		i, err := s.FindInvitationByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*platform.Invitation{i}, 1, nil
+	}
+
+	query := map[string]string{}
+	if filter.OrgID != nil {
+		query["orgID"] = filter.OrgID.String()
+	}
+
+	var resp getInvitationsResponse
+	if err := s.do(ctx, "GET", invitationPath, query, nil, &resp); err != nil {
+		return nil, 0, err
+	}
+
+	is := make([]*platform.Invitation, 0, len(resp.Invitations))
+	for _, i := range resp.Invitations {
+		is = append(is, i.Invitation)
+	}
+	return is, len(is), nil
+}
+
+// CreateInvitation creates a new invitation and sets i.ID and i.Token.
+func (s *InvitationService) CreateInvitation(ctx context.Context, i *platform.Invitation) error {
+	var resp invitationResponse
+	if err := s.do(ctx, "POST", invitationPath, nil, i, &resp); err != nil {
+		return err
+	}
+	*i = *resp.Invitation
+	return nil
+}
+
+// DeleteInvitation revokes an invitation by ID.
+func (s *InvitationService) DeleteInvitation(ctx context.Context, id platform.ID) error {
+	return s.do(ctx, "DELETE", invitationIDPath(id), nil, nil, nil)
+}
+
+// AcceptInvitation adds the user of the token of s to the organization of
+// the invitation with token.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, token string) (*platform.Invitation, error) {
+	var resp invitationResponse
+	if err := s.do(ctx, "POST", invitationAcceptPath, nil, acceptInvitationRequest{Token: token}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Invitation, nil
+}
+
+// RegisterInvitation creates a user with name and password and adds it to
+// the organization of the invitation with token. An empty name uses the
+// username or email of the invitation.
+func (s *InvitationService) RegisterInvitation(ctx context.Context, token, name, password string) (*platform.User, error) {
+	var resp userResponse
+	req := registerInvitationRequest{
+		Token:    token,
+		Name:     name,
+		Password: password,
+	}
+	if err := s.do(ctx, "POST", invitationRegisterPath, nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.User, nil
+}
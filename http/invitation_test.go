@@ -0,0 +1,156 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+// NewMockInvitationBackend returns a InvitationBackend with mock services.
+func NewMockInvitationBackend() *InvitationBackend {
+	return &InvitationBackend{
+		Logger:              zap.NewNop().With(zap.String("handler", "invitation")),
+		InvitationService:   mock.NewInvitationService(),
+		OrganizationService: mock.NewOrganizationService(),
+
+		InvitationTokenService:     mock.NewInvitationService(),
+		UserService:                mock.NewUserService(),
+		PasswordsService:           mock.NewPasswordsService("", ""),
+		UserResourceMappingService: mock.NewUserResourceMappingService(),
+	}
+}
+
+func TestInvitationHandler_handleGetInvitations(t *testing.T) {
+	backend := NewMockInvitationBackend()
+	backend.InvitationService = &mock.InvitationService{
+		FindInvitationsF: func(ctx context.Context, filter platform.InvitationFilter) ([]*platform.Invitation, int, error) {
+			return []*platform.Invitation{{ID: 1, OrgID: 10, Username: "invitee", Token: "secret"}}, 1, nil
+		},
+	}
+	h := NewInvitationHandler(backend)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://any.url/api/v2/invitations", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("secret")) {
+		t.Errorf("expected invitation tokens to be left out, got %s", w.Body.String())
+	}
+}
+
+func TestInvitationHandler_handleRegisterInvitation(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082000")
+	userID := platformtesting.MustIDBase16("0b501e7e557ab1ed")
+
+	tests := []struct {
+		name       string
+		invitation *platform.Invitation
+		body       string
+		code       int
+		userName   string
+	}{
+		{
+			name:       "creates the invited user",
+			invitation: &platform.Invitation{OrgID: orgID, Username: "invitee", Role: platform.Owner, ExpiresAt: time.Now().Add(time.Hour)},
+			body:       `{"token": "secret", "password": "p4ssw0rd!"}`,
+			code:       http.StatusCreated,
+			userName:   "invitee",
+		},
+		{
+			name:       "names the user after the invited email",
+			invitation: &platform.Invitation{OrgID: orgID, Email: "invitee@example.com", Role: platform.Member, ExpiresAt: time.Now().Add(time.Hour)},
+			body:       `{"token": "secret", "password": "p4ssw0rd!"}`,
+			code:       http.StatusCreated,
+			userName:   "invitee@example.com",
+		},
+		{
+			name:       "rejects another username",
+			invitation: &platform.Invitation{OrgID: orgID, Username: "invitee", Role: platform.Member, ExpiresAt: time.Now().Add(time.Hour)},
+			body:       `{"token": "secret", "name": "someone", "password": "p4ssw0rd!"}`,
+			code:       http.StatusBadRequest,
+		},
+		{
+			name:       "rejects expired invitation",
+			invitation: &platform.Invitation{OrgID: orgID, Username: "invitee", Role: platform.Member, ExpiresAt: time.Now().Add(-time.Hour)},
+			body:       `{"token": "secret", "password": "p4ssw0rd!"}`,
+			code:       http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mapping *platform.UserResourceMapping
+
+			backend := NewMockInvitationBackend()
+			backend.InvitationTokenService = &mock.InvitationService{
+				FindInvitationByTokenF: func(ctx context.Context, token string) (*platform.Invitation, error) {
+					return tt.invitation, nil
+				},
+				ConsumeInvitationF: func(ctx context.Context, token string) (*platform.Invitation, error) {
+					return tt.invitation, nil
+				},
+			}
+			backend.UserService = &mock.UserService{
+				FindUserFn: func(ctx context.Context, filter platform.UserFilter) (*platform.User, error) {
+					return nil, &platform.Error{Code: platform.ENotFound, Msg: "user not found"}
+				},
+				CreateUserFn: func(ctx context.Context, u *platform.User) error {
+					u.ID = userID
+					return nil
+				},
+			}
+			backend.PasswordsService = &mock.PasswordsService{
+				SetPasswordFn: func(ctx context.Context, name, password string) error { return nil },
+			}
+			backend.UserResourceMappingService = &mock.UserResourceMappingService{
+				CreateMappingFn: func(ctx context.Context, m *platform.UserResourceMapping) error {
+					mapping = m
+					return nil
+				},
+			}
+			h := NewInvitationHandler(backend)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "http://any.url/api/v2/invitations/register", bytes.NewBufferString(tt.body)))
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.code != http.StatusCreated {
+				if mapping != nil {
+					t.Errorf("unexpected mapping %+v", mapping)
+				}
+				return
+			}
+
+			var u userResponse
+			if err := json.NewDecoder(w.Body).Decode(&u); err != nil {
+				t.Fatal(err)
+			}
+			if u.Name != tt.userName {
+				t.Errorf("expected user %s, got %s", tt.userName, u.Name)
+			}
+
+			want := &platform.UserResourceMapping{
+				ResourceType: platform.OrgsResourceType,
+				ResourceID:   orgID,
+				UserID:       userID,
+				UserType:     tt.invitation.Role,
+			}
+			if mapping == nil || *mapping != *want {
+				t.Errorf("expected mapping %+v, got %+v", want, mapping)
+			}
+		})
+	}
+}
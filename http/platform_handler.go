@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/influxdata/influxdb/authorizer"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -24,17 +25,31 @@ func setCORSResponseHeaders(w http.ResponseWriter, r *http.Request) {
 
 // NewPlatformHandler returns a platform handler that serves the API and associated assets.
 func NewPlatformHandler(b *APIBackend) *PlatformHandler {
+	// The API handler wraps the user resource mapping service with an
+	// authorizing one, which can't be used before there is an authorizer.
+	internalURM := b.UserResourceMappingService
+
 	h := NewAuthenticationHandler()
 	h.Handler = NewAPIHandler(b)
 	h.AuthorizationService = b.AuthorizationService
 	h.SessionService = b.SessionService
+	if b.RoleService != nil {
+		// Authorizers are granted the permissions of their roles.
+		h.AuthorizationService = authorizer.NewRoleAuthorizationService(h.AuthorizationService, b.RoleService, internalURM)
+		h.SessionService = authorizer.NewRoleSessionService(h.SessionService, b.RoleService, internalURM)
+	}
+	h.ClientCertificates = b.ClientCertificates
 
 	h.RegisterNoAuthRoute("GET", "/api/v2")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signin")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signout")
+	h.RegisterNoAuthRoute("GET", "/api/v2/signin/oidc")
+	h.RegisterNoAuthRoute("GET", "/api/v2/signin/oidc/callback")
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
+	h.RegisterNoAuthRoute("GET", "/api/v2/share/:key")
+	h.RegisterNoAuthRoute("POST", "/api/v2/invitations/register")
 
 	assetHandler := NewAssetHandler()
 	assetHandler.Path = b.AssetsPath
@@ -61,6 +76,7 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Serve the chronograf assets for any basepath that does not start with addressable parts
 	// of the platform API.
 	if !strings.HasPrefix(r.URL.Path, "/v1") &&
+		!strings.HasPrefix(r.URL.Path, "/api/v1/") &&
 		!strings.HasPrefix(r.URL.Path, "/api/v2") &&
 		!strings.HasPrefix(r.URL.Path, "/chronograf/") {
 		h.AssetHandler.ServeHTTP(w, r)
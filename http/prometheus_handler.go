@@ -0,0 +1,390 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/golang/snappy"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/prometheus/prompb"
+	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// PrometheusBackend is all services and associated parameters required to
+// construct the PrometheusHandler.
+type PrometheusBackend struct {
+	Logger *zap.Logger
+
+	PointsWriter        storage.PointsWriter
+	ReadStore           reads.Store
+	BucketService       platform.BucketService
+	OrganizationService platform.OrganizationService
+	ProxyQueryService   query.ProxyQueryService
+
+	// MaxBodySize is the maximum size in bytes of a decompressed request
+	// body. Zero means unlimited.
+	MaxBodySize int64
+}
+
+// NewPrometheusBackend returns a new instance of PrometheusBackend.
+func NewPrometheusBackend(b *APIBackend) *PrometheusBackend {
+	return &PrometheusBackend{
+		Logger: b.Logger.With(zap.String("handler", "prometheus")),
+
+		PointsWriter:        b.PointsWriter,
+		ReadStore:           b.ReadStore,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+		ProxyQueryService:   b.FluxService,
+
+		MaxBodySize: b.WriteMaxBodySize,
+	}
+}
+
+// PrometheusHandler implements the Prometheus remote storage API, so that
+// Prometheus servers can write samples to and read them back from a bucket,
+// and the query endpoints of the Prometheus HTTP API, so that PromQL clients
+// such as Grafana can query them.
+type PrometheusHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	Now                 func() time.Time
+	PointsWriter        storage.PointsWriter
+	ReadStore           reads.Store
+	BucketService       platform.BucketService
+	OrganizationService platform.OrganizationService
+	ProxyQueryService   query.ProxyQueryService
+
+	// MaxBodySize is the maximum size in bytes of a decompressed request
+	// body. Zero means unlimited.
+	MaxBodySize int64
+	// MaxSamples is the most samples a remote read returns. Zero means
+	// DefaultPrometheusReadSampleLimit.
+	MaxSamples int
+	// MaxResponseSize is the largest PromQL query response in bytes. Zero
+	// means DefaultPrometheusQueryResponseLimit.
+	MaxResponseSize int64
+}
+
+const (
+	prometheusWritePath = "/api/v2/prometheus/write"
+	prometheusReadPath  = "/api/v2/prometheus/read"
+
+	// DefaultPrometheusReadSampleLimit is the most samples a remote read
+	// returns when PrometheusHandler.MaxSamples is not set.
+	DefaultPrometheusReadSampleLimit = 5e7
+
+	// DefaultPrometheusQueryResponseLimit is the largest PromQL query
+	// response when PrometheusHandler.MaxResponseSize is not set.
+	DefaultPrometheusQueryResponseLimit = 64 << 20
+)
+
+// NewPrometheusHandler returns a new instance of PrometheusHandler.
+func NewPrometheusHandler(b *PrometheusBackend) *PrometheusHandler {
+	h := &PrometheusHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+		Now:    time.Now,
+
+		PointsWriter:        b.PointsWriter,
+		ReadStore:           b.ReadStore,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+		ProxyQueryService:   b.ProxyQueryService,
+
+		MaxBodySize: b.MaxBodySize,
+	}
+
+	h.HandlerFunc("POST", prometheusWritePath, h.handlePostWrite)
+	h.HandlerFunc("POST", prometheusReadPath, h.handlePostRead)
+	h.HandlerFunc("GET", prometheusQueryPath, h.handleQuery)
+	h.HandlerFunc("POST", prometheusQueryPath, h.handleQuery)
+	h.HandlerFunc("GET", prometheusQueryRangePath, h.handleQueryRange)
+	h.HandlerFunc("POST", prometheusQueryRangePath, h.handleQueryRange)
+	h.HandlerFunc("GET", prometheusLabelsPath, h.handleLabels)
+	h.HandlerFunc("POST", prometheusLabelsPath, h.handleLabels)
+	h.HandlerFunc("GET", prometheusLabelValuesPath, h.handleLabelValues)
+	h.HandlerFunc("GET", prometheusSeriesPath, h.handleSeries)
+	h.HandlerFunc("POST", prometheusSeriesPath, h.handleSeries)
+	return h
+}
+
+func (h *PrometheusHandler) handlePostWrite(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	bucket, err := h.findBucket(ctx, r, platform.WriteAction)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var req prompb.WriteRequest
+	if err := h.decodeRequest(r, &req); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	points, err := remote.WriteRequestToPoints(&req)
+	if err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/handlePrometheusWrite",
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	exploded, err := tsdb.ExplodePoints(bucket.OrganizationID, bucket.ID, points)
+	if err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePrometheusWrite",
+			Msg:  fmt.Sprintf("unable to convert points to internal structures: %v", err),
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.PointsWriter.WritePoints(ctx, exploded); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePrometheusWrite",
+			Msg:  fmt.Sprintf("unable to write points to database: %v", err),
+			Err:  err,
+		}, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PrometheusHandler) handlePostRead(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	bucket, err := h.findBucket(ctx, r, platform.ReadAction)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var req prompb.ReadRequest
+	if err := h.decodeRequest(r, &req); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	source, err := types.MarshalAny(h.ReadStore.GetSource(uint64(bucket.OrganizationID), uint64(bucket.ID)))
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	limit := h.MaxSamples
+	if limit <= 0 {
+		limit = DefaultPrometheusReadSampleLimit
+	}
+
+	// The sample limit applies to the request as a whole.
+	remaining := limit
+	resp := &prompb.ReadResponse{Results: make([]*prompb.QueryResult, 0, len(req.Queries))}
+	for _, q := range req.Queries {
+		res, err := h.readQuery(ctx, q, source, remaining)
+		if err == remote.ErrSampleLimit {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.ETooLarge,
+				Op:   "http/handlePrometheusRead",
+				Msg:  fmt.Sprintf("remote read exceeds the limit of %d samples", limit),
+			}, w)
+			return
+		} else if err != nil {
+			EncodeError(ctx, err, w)
+			return
+		}
+		for _, ts := range res.Timeseries {
+			remaining -= len(ts.Samples)
+		}
+		resp.Results = append(resp.Results, res)
+	}
+
+	b, err := resp.Marshal()
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Header().Set("Content-Encoding", "snappy")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(snappy.Encode(nil, b)); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+// readQuery reads the samples matching q, returning remote.ErrSampleLimit if
+// there are more than maxSamples.
+func (h *PrometheusHandler) readQuery(ctx context.Context, q *prompb.Query, source *types.Any, maxSamples int) (*prompb.QueryResult, error) {
+	req, err := remote.ReadFilterRequest(q, source)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/handlePrometheusRead",
+			Msg:  err.Error(),
+		}
+	}
+
+	rs, err := h.ReadStore.ReadFilter(ctx, req)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePrometheusRead",
+			Err:  err,
+		}
+	}
+
+	res, err := remote.ReadQueryResult(rs, maxSamples)
+	if err == remote.ErrSampleLimit {
+		return nil, err
+	} else if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePrometheusRead",
+			Err:  err,
+		}
+	}
+	return res, nil
+}
+
+// findBucket returns the bucket named by the org and bucket parameters of r,
+// which may be names or IDs, if the request is authorized to perform action
+// on it.
+func (h *PrometheusHandler) findBucket(ctx context.Context, r *http.Request, action platform.Action) (*platform.Bucket, error) {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	qp := r.URL.Query()
+	org, name := qp.Get("org"), qp.Get("bucket")
+	if org == "" || name == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/findPrometheusBucket",
+			Msg:  "org and bucket are required",
+		}
+	}
+
+	var filter platform.BucketFilter
+	if id, err := platform.IDFromString(org); err == nil {
+		filter.OrganizationID = id
+	} else {
+		filter.Organization = &org
+	}
+	if id, err := platform.IDFromString(name); err == nil {
+		filter.ID = id
+	} else {
+		filter.Name = &name
+	}
+
+	bucket, err := h.BucketService.FindBucket(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeBucket(a, bucket, action); err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+// authorizeBucket returns an error unless a is allowed to perform action on
+// bucket.
+func authorizeBucket(a platform.Authorizer, bucket *platform.Bucket, action platform.Action) error {
+	p, err := platform.NewPermissionAtID(bucket.ID, action, platform.BucketsResourceType, bucket.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if !a.Allowed(*p) {
+		return &platform.Error{
+			Code: platform.EForbidden,
+			Op:   "http/findPrometheusBucket",
+			Msg:  fmt.Sprintf("insufficient permissions to %s bucket", action),
+		}
+	}
+	return nil
+}
+
+// decodeRequest decodes the snappy compressed protocol buffer body of r into
+// m.
+func (h *PrometheusHandler) decodeRequest(r *http.Request, m interface{ Unmarshal([]byte) error }) error {
+	var body io.Reader = r.Body
+	if h.MaxBodySize > 0 {
+		// A compressed body is never allowed to be larger than a
+		// decompressed one.
+		body = &maxBytesReader{r: r.Body, n: h.MaxBodySize}
+	}
+
+	compressed, err := ioutil.ReadAll(body)
+	if err == errBodyTooLarge {
+		return &platform.Error{
+			Code: platform.ETooLarge,
+			Op:   "http/decodePrometheusRequest",
+			Msg:  fmt.Sprintf("request body exceeds %d bytes", h.MaxBodySize),
+		}
+	} else if err != nil {
+		return &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/decodePrometheusRequest",
+			Msg:  "unable to read request body",
+			Err:  err,
+		}
+	}
+
+	// Check the decompressed size before allocating it.
+	if n, err := snappy.DecodedLen(compressed); err == nil && h.MaxBodySize > 0 && int64(n) > h.MaxBodySize {
+		return &platform.Error{
+			Code: platform.ETooLarge,
+			Op:   "http/decodePrometheusRequest",
+			Msg:  fmt.Sprintf("request body exceeds %d bytes", h.MaxBodySize),
+		}
+	}
+
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/decodePrometheusRequest",
+			Msg:  "request body must be snappy compressed",
+			Err:  err,
+		}
+	}
+
+	if err := m.Unmarshal(b); err != nil {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/decodePrometheusRequest",
+			Msg:  "request body is not a valid protocol buffer",
+			Err:  err,
+		}
+	}
+	return nil
+}
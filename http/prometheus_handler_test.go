@@ -0,0 +1,150 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/prometheus/prompb"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"go.uber.org/zap"
+)
+
+// filterStore is a reads.Store that records read filter requests and finds
+// no series.
+type filterStore struct {
+	reads.Store
+	requests []*datatypes.ReadFilterRequest
+}
+
+func (s *filterStore) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	s.requests = append(s.requests, req)
+	return nil, nil
+}
+
+func (s *filterStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return &datatypes.ReadFilterRequest{}
+}
+
+func newTestPrometheusHandler(pw *batchPointsWriter, store reads.Store) *PrometheusHandler {
+	return NewPrometheusHandler(&PrometheusBackend{
+		Logger:       zap.NewNop(),
+		PointsWriter: pw,
+		ReadStore:    store,
+		BucketService: &mock.BucketService{
+			FindBucketFn: func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+				if filter.Organization == nil || *filter.Organization != "org" || filter.Name == nil || *filter.Name != "prom" {
+					return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+				}
+				return &platform.Bucket{ID: 2, OrganizationID: 1, Name: "prom"}, nil
+			},
+		},
+	})
+}
+
+func newTestPrometheusRequest(path string, m proto.Marshaler, action platform.Action) *http.Request {
+	b, err := m.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	r := httptest.NewRequest("POST", "http://any.url"+path+"?org=org&bucket=prom", bytes.NewReader(snappy.Encode(nil, b)))
+	orgID := platform.ID(1)
+	return r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+		Status: platform.Active,
+		Permissions: []platform.Permission{
+			{Action: action, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID}},
+		},
+	}))
+}
+
+func TestPrometheusHandler_handlePostWrite(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: 0, Timestamp: 2000}},
+		}},
+	}
+
+	t.Run("writes samples", func(t *testing.T) {
+		pw := &batchPointsWriter{}
+		h := newTestPrometheusHandler(pw, nil)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newTestPrometheusRequest(prometheusWritePath, req, platform.WriteAction))
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+		}
+		if len(pw.batches) != 1 || pw.batches[0] != 2 {
+			t.Errorf("expected a batch of 2 points, got %v", pw.batches)
+		}
+	})
+
+	t.Run("requires write permission", func(t *testing.T) {
+		pw := &batchPointsWriter{}
+		h := newTestPrometheusHandler(pw, nil)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newTestPrometheusRequest(prometheusWritePath, req, platform.ReadAction))
+
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusForbidden, w.Code, w.Body.String())
+		}
+		if len(pw.batches) != 0 {
+			t.Errorf("expected no points written, got %v", pw.batches)
+		}
+	})
+}
+
+func TestPrometheusHandler_handlePostRead(t *testing.T) {
+	store := &filterStore{}
+	h := newTestPrometheusHandler(nil, store)
+
+	req := &prompb.ReadRequest{
+		Queries: []*prompb.Query{
+			{StartTimestampMs: 1, EndTimestampMs: 2, Matchers: []*prompb.LabelMatcher{{Name: "__name__", Value: "up"}}},
+			{StartTimestampMs: 3, EndTimestampMs: 4, Matchers: []*prompb.LabelMatcher{{Name: "__name__", Value: "down"}}},
+		},
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newTestPrometheusRequest(prometheusReadPath, req, platform.ReadAction))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "snappy" {
+		t.Errorf("expected snappy content encoding, got %q", got)
+	}
+
+	compressed, err := ioutil.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp prompb.ReadResponse
+	if err := resp.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Results) != 2 {
+		t.Errorf("expected a result for each query, got %d", len(resp.Results))
+	}
+	if len(store.requests) != 2 {
+		t.Fatalf("expected 2 read filter requests, got %d", len(store.requests))
+	}
+	if r := store.requests[1]; r.Range.Start != 3e6 || r.Range.End != 4e6 || r.ReadSource == nil {
+		t.Errorf("unexpected read filter request %v", r)
+	}
+}
@@ -0,0 +1,329 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/promql"
+)
+
+const (
+	prometheusQueryPath      = "/api/v1/query"
+	prometheusQueryRangePath = "/api/v1/query_range"
+
+	// prometheusMaxPoints is the most evaluations of a range query, the
+	// same limit as Prometheus has for each series.
+	prometheusMaxPoints = 11000
+)
+
+// handleQuery evaluates a PromQL expression at a single time.
+func (h *PrometheusHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	c, err := decodePrometheusQuery(r, h.Now())
+	if err != nil {
+		encodePrometheusError(w, err)
+		return
+	}
+
+	if v, ok := promql.ScalarValue(c.Query); ok {
+		h.encodeData(w, r, &promql.Data{
+			ResultType: promql.ScalarResult,
+			Scalar:     &promql.Sample{Time: c.End, Value: v},
+		})
+		return
+	}
+
+	d := &promql.Dialect{ResultType: promql.VectorResult}
+	if promql.IsRangeVector(c.Query) {
+		d.ResultType = promql.MatrixResult
+	}
+	h.query(w, r, c, d)
+}
+
+// handleQueryRange evaluates a PromQL expression at each step of a range of
+// time.
+func (h *PrometheusHandler) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	c, err := decodePrometheusQueryRange(r)
+	if err != nil {
+		encodePrometheusError(w, err)
+		return
+	}
+
+	if v, ok := promql.ScalarValue(c.Query); ok {
+		// A scalar is a series without labels in the result of a range
+		// query.
+		series := &promql.Series{Metric: map[string]string{}}
+		for t := c.Start; !t.After(c.End); t = t.Add(c.Step) {
+			series.Values = append(series.Values, promql.Sample{Time: t, Value: v})
+		}
+		h.encodeData(w, r, &promql.Data{
+			ResultType: promql.MatrixResult,
+			Result:     []*promql.Series{series},
+		})
+		return
+	}
+	h.query(w, r, c, &promql.Dialect{ResultType: promql.MatrixResult})
+}
+
+func (h *PrometheusHandler) query(w http.ResponseWriter, r *http.Request, c *promql.Compiler, d *promql.Dialect) {
+	ctx := r.Context()
+
+	// Check the query before it is run, so that invalid queries are
+	// reported as such rather than as failing.
+	if _, err := c.Compile(ctx); err != nil {
+		encodePrometheusError(w, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/handlePrometheusQuery",
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	req, err := h.proxyRequest(ctx, r, c, d)
+	if err != nil {
+		encodePrometheusError(w, err)
+		return
+	}
+	ctx = pcontext.SetAuthorizer(ctx, req.Request.Authorization)
+
+	limit := h.MaxResponseSize
+	if limit <= 0 {
+		limit = DefaultPrometheusQueryResponseLimit
+	}
+
+	// The response is buffered so that failures are reported with the right
+	// status code. The buffer is limited so that large results can't use
+	// unbounded memory.
+	buf := &limitedBuffer{max: limit}
+	if _, err := h.ProxyQueryService.Query(ctx, buf, req); buf.exceeded {
+		encodePrometheusError(w, &platform.Error{
+			Code: platform.EUnprocessableEntity,
+			Op:   "http/handlePrometheusQuery",
+			Msg:  fmt.Sprintf("response exceeded the limit of %d bytes; try a narrower query or a larger step", limit),
+		})
+		return
+	} else if err != nil {
+		encodePrometheusError(w, &platform.Error{
+			Code: platform.EUnprocessableEntity,
+			Op:   "http/handlePrometheusQuery",
+			Msg:  err.Error(),
+		})
+		return
+	}
+
+	d.SetHeaders(w)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+var errResponseTooLarge = errors.New("response too large")
+
+// limitedBuffer is a buffer that fails writes beyond max bytes.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	max      int64
+	exceeded bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if int64(b.buf.Len()+len(p)) > b.max {
+		b.exceeded = true
+		return 0, errResponseTooLarge
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes returns the buffered bytes.
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// proxyRequest returns the request to query the organization named by the
+// org or orgID parameters of r, or else the organization of its token.
+func (h *PrometheusHandler) proxyRequest(ctx context.Context, r *http.Request, c *promql.Compiler, d *promql.Dialect) (*query.ProxyRequest, error) {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := h.queryOrganization(ctx, r, a)
+	if err != nil {
+		return nil, err
+	}
+
+	var token *platform.Authorization
+	switch a := a.(type) {
+	case *platform.Authorization:
+		token = a
+	case *platform.Session:
+		token = a.EphemeralAuth(org.ID)
+	default:
+		return nil, platform.ErrAuthorizerNotSupported
+	}
+
+	return &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  token,
+			OrganizationID: org.ID,
+			Source:         r.Header.Get("User-Agent"),
+			Compiler:       c,
+		},
+		Dialect: d,
+	}, nil
+}
+
+// queryOrganization returns the organization named by the org or orgID
+// parameters of r, or else the organization of the token a.
+func (h *PrometheusHandler) queryOrganization(ctx context.Context, r *http.Request, a platform.Authorizer) (*platform.Organization, error) {
+	qp := r.URL.Query()
+	if qp.Get(OrgID) != "" || qp.Get(OrgName) != "" {
+		return queryOrganization(ctx, r, h.OrganizationService)
+	}
+	if auth, ok := a.(*platform.Authorization); ok {
+		return h.OrganizationService.FindOrganizationByID(ctx, auth.OrgID)
+	}
+	return nil, &platform.Error{
+		Code: platform.EInvalid,
+		Op:   "http/handlePrometheusQuery",
+		Msg:  "org or orgID is required",
+	}
+}
+
+// decodePrometheusQuery decodes the parameters of an instant query, which is
+// evaluated at now unless a time is given.
+func decodePrometheusQuery(r *http.Request, now time.Time) (*promql.Compiler, error) {
+	c := &promql.Compiler{Query: r.FormValue("query"), Bucket: r.FormValue("bucket")}
+	if c.Query == "" {
+		return nil, invalidPrometheusParam("query", "query is required")
+	}
+
+	t := now
+	if v := r.FormValue("time"); v != "" {
+		var err error
+		if t, err = parsePrometheusTime(v); err != nil {
+			return nil, invalidPrometheusParam("time", err.Error())
+		}
+	}
+	c.Start, c.End = t, t
+	return c, nil
+}
+
+// decodePrometheusQueryRange decodes the parameters of a range query.
+func decodePrometheusQueryRange(r *http.Request) (*promql.Compiler, error) {
+	c := &promql.Compiler{Query: r.FormValue("query"), Bucket: r.FormValue("bucket")}
+	if c.Query == "" {
+		return nil, invalidPrometheusParam("query", "query is required")
+	}
+
+	var err error
+	if c.Start, err = parsePrometheusTime(r.FormValue("start")); err != nil {
+		return nil, invalidPrometheusParam("start", err.Error())
+	}
+	if c.End, err = parsePrometheusTime(r.FormValue("end")); err != nil {
+		return nil, invalidPrometheusParam("end", err.Error())
+	}
+	if c.End.Before(c.Start) {
+		return nil, invalidPrometheusParam("end", "end timestamp must not be before start time")
+	}
+
+	if c.Step, err = parsePrometheusDuration(r.FormValue("step")); err != nil {
+		return nil, invalidPrometheusParam("step", err.Error())
+	}
+	if c.Step <= 0 {
+		return nil, invalidPrometheusParam("step", "zero or negative query resolution step widths are not accepted")
+	}
+	if c.End.Sub(c.Start)/c.Step > prometheusMaxPoints {
+		return nil, invalidPrometheusParam("step", fmt.Sprintf("exceeded maximum resolution of %d points per timeseries; try increasing the step", prometheusMaxPoints))
+	}
+	return c, nil
+}
+
+func invalidPrometheusParam(name, msg string) error {
+	return &platform.Error{
+		Code: platform.EInvalid,
+		Op:   "http/decodePrometheusQuery",
+		Msg:  fmt.Sprintf("invalid parameter %q: %s", name, msg),
+	}
+}
+
+// parsePrometheusTime parses a time in seconds since the epoch, to the
+// millisecond, or in RFC3339 format.
+func parsePrometheusTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("time is required")
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		ms := int64(math.Round(f * 1e3))
+		return time.Unix(0, ms*int64(time.Millisecond)).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+	}
+	return t, nil
+}
+
+// parsePrometheusDuration parses a duration in seconds or such as "15s".
+func parsePrometheusDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("step is required")
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		d := f * float64(time.Second)
+		if d > math.MaxInt64 || d < math.MinInt64 {
+			return 0, fmt.Errorf("cannot parse %q to a valid duration: it overflows int64", s)
+		}
+		return time.Duration(d), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %q to a valid duration", s)
+	}
+	return d, nil
+}
+
+// prometheusErrorTypes are the error types of the Prometheus HTTP API for
+// platform error codes.
+var prometheusErrorTypes = map[string]promql.ErrorType{
+	platform.EInvalid:             promql.ErrorBadData,
+	platform.EEmptyValue:          promql.ErrorBadData,
+	platform.EUnprocessableEntity: promql.ErrorExecution,
+	platform.EInternal:            promql.ErrorInternal,
+}
+
+// encodePrometheusError writes err as a Prometheus HTTP API error response.
+func encodePrometheusError(w http.ResponseWriter, err error) {
+	code := platform.ErrorCode(err)
+	httpCode, ok := statusCodePlatformError[code]
+	if !ok {
+		httpCode = http.StatusBadRequest
+	}
+	typ, ok := prometheusErrorTypes[code]
+	if !ok {
+		typ = promql.ErrorType(code)
+	}
+
+	w.Header().Set(PlatformErrorCodeHeader, code)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	b, _ := json.Marshal(promql.NewErrorResponse(typ, platform.ErrorMessage(err)))
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,224 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/query/promql"
+	"go.uber.org/zap"
+)
+
+func TestPrometheusHandler_handleQuery(t *testing.T) {
+	now := time.Unix(1569888000, 0).UTC()
+
+	tests := []struct {
+		name         string
+		path         string
+		queryErr     error
+		maxResponse  int64
+		wantStatus   int
+		wantErrType  promql.ErrorType
+		wantOrgID    platform.ID
+		wantCompiler *promql.Compiler
+		wantDialect  *promql.Dialect
+		wantData     *promql.Data
+	}{
+		{
+			name:       "instant query at now",
+			path:       "/api/v1/query?query=up&bucket=prom",
+			wantStatus: http.StatusOK,
+			wantOrgID:  1,
+			wantCompiler: &promql.Compiler{
+				Query:  "up",
+				Bucket: "prom",
+				Start:  now,
+				End:    now,
+			},
+			wantDialect: &promql.Dialect{ResultType: promql.VectorResult},
+		},
+		{
+			name:       "instant query of a range vector",
+			path:       "/api/v1/query?query=up[1m]&time=1569888000.5&org=other",
+			wantStatus: http.StatusOK,
+			wantOrgID:  2,
+			wantCompiler: &promql.Compiler{
+				Query: "up[1m]",
+				Start: now.Add(500 * time.Millisecond),
+				End:   now.Add(500 * time.Millisecond),
+			},
+			wantDialect: &promql.Dialect{ResultType: promql.MatrixResult},
+		},
+		{
+			name:       "range query",
+			path:       "/api/v1/query_range?query=up&start=2019-10-01T00:00:00Z&end=1569888060&step=15",
+			wantStatus: http.StatusOK,
+			wantOrgID:  1,
+			wantCompiler: &promql.Compiler{
+				Query: "up",
+				Start: now,
+				End:   now.Add(time.Minute),
+				Step:  15 * time.Second,
+			},
+			wantDialect: &promql.Dialect{ResultType: promql.MatrixResult},
+		},
+		{
+			name:       "scalar instant query",
+			path:       "/api/v1/query?query=1%2B1",
+			wantStatus: http.StatusOK,
+			wantData: &promql.Data{
+				ResultType: promql.ScalarResult,
+				Scalar:     &promql.Sample{Time: now, Value: 2},
+			},
+		},
+		{
+			name:       "scalar range query",
+			path:       "/api/v1/query_range?query=2*3&start=1569888000&end=1569888030&step=15",
+			wantStatus: http.StatusOK,
+			wantData: &promql.Data{
+				ResultType: promql.MatrixResult,
+				Result: []*promql.Series{{
+					Metric: map[string]string{},
+					Values: []promql.Sample{
+						{Time: now, Value: 6},
+						{Time: now.Add(15 * time.Second), Value: 6},
+						{Time: now.Add(30 * time.Second), Value: 6},
+					},
+				}},
+			},
+		},
+		{
+			name:        "missing query",
+			path:        "/api/v1/query?time=1569888000",
+			wantStatus:  http.StatusBadRequest,
+			wantErrType: promql.ErrorBadData,
+		},
+		{
+			name:        "invalid time",
+			path:        "/api/v1/query?query=up&time=yesterday",
+			wantStatus:  http.StatusBadRequest,
+			wantErrType: promql.ErrorBadData,
+		},
+		{
+			name:        "end before start",
+			path:        "/api/v1/query_range?query=up&start=1569888060&end=1569888000&step=15s",
+			wantStatus:  http.StatusBadRequest,
+			wantErrType: promql.ErrorBadData,
+		},
+		{
+			name:        "too many points",
+			path:        "/api/v1/query_range?query=up&start=0&end=1569888000&step=1s",
+			wantStatus:  http.StatusBadRequest,
+			wantErrType: promql.ErrorBadData,
+		},
+		{
+			name:        "invalid expression",
+			path:        "/api/v1/query?query=up{",
+			wantStatus:  http.StatusBadRequest,
+			wantErrType: promql.ErrorBadData,
+		},
+		{
+			name:        "failed query",
+			path:        "/api/v1/query?query=up",
+			queryErr:    errors.New("storage is unavailable"),
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantErrType: promql.ErrorExecution,
+		},
+		{
+			name:        "response too large",
+			path:        "/api/v1/query?query=up",
+			maxResponse: 10,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantErrType: promql.ErrorExecution,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *query.ProxyRequest
+			h := NewPrometheusHandler(&PrometheusBackend{
+				Logger: zap.NewNop(),
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+						return &platform.Organization{ID: id, Name: "org"}, nil
+					},
+					FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{ID: 2, Name: *filter.Name}, nil
+					},
+				},
+				ProxyQueryService: &querymock.ProxyQueryService{
+					QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+						got = req
+						if tt.queryErr != nil {
+							return flux.Statistics{}, tt.queryErr
+						}
+						_, err := io.WriteString(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+						return flux.Statistics{}, err
+					},
+				},
+			})
+			h.Now = func() time.Time { return now }
+			h.MaxResponseSize = tt.maxResponse
+
+			r := httptest.NewRequest("GET", "http://any.url"+tt.path, nil)
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+				Status: platform.Active,
+				OrgID:  1,
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("unexpected status code %d: %s", res.StatusCode, w.Body.String())
+			}
+			if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("unexpected content type %q", ct)
+			}
+
+			var resp promql.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.ErrorType != tt.wantErrType {
+				t.Errorf("unexpected error type %q: %s", resp.ErrorType, resp.Error)
+			}
+			if tt.wantData != nil {
+				if got != nil {
+					t.Error("expected the query not to be run")
+				}
+				if !cmp.Equal(tt.wantData, resp.Data) {
+					t.Errorf("unexpected data -want/+got\n%s", cmp.Diff(tt.wantData, resp.Data))
+				}
+			}
+			if tt.wantCompiler == nil {
+				return
+			}
+
+			if got == nil {
+				t.Fatal("expected the query to be run")
+			}
+			if got.Request.OrganizationID != tt.wantOrgID {
+				t.Errorf("unexpected organization %s", got.Request.OrganizationID)
+			}
+			if !cmp.Equal(tt.wantCompiler, got.Request.Compiler) {
+				t.Errorf("unexpected compiler -want/+got\n%s", cmp.Diff(tt.wantCompiler, got.Request.Compiler))
+			}
+			if !cmp.Equal(tt.wantDialect, got.Dialect) {
+				t.Errorf("unexpected dialect -want/+got\n%s", cmp.Diff(tt.wantDialect, got.Dialect))
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/julienschmidt/httprouter"
+
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/prometheus/prompb"
+	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/influxdb/query/promql"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+)
+
+const (
+	prometheusLabelsPath      = "/api/v1/labels"
+	prometheusLabelValuesPath = "/api/v1/label/:name/values"
+	prometheusSeriesPath      = "/api/v1/series"
+)
+
+// prometheusDataResponse is a successful response of the Prometheus HTTP
+// API with data other than the result of a query.
+type prometheusDataResponse struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+// prometheusSeriesRequest is a request for the labels of the series matching
+// any of Matchers in the bucket of Source, within Range.
+type prometheusSeriesRequest struct {
+	Source   *types.Any
+	Range    datatypes.TimestampRange
+	Matchers [][]*prompb.LabelMatcher
+}
+
+// handleLabels returns the names of the labels of the series.
+func (h *PrometheusHandler) handleLabels(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	req, err := h.decodeSeriesRequest(ctx, r, false)
+	if err != nil {
+		encodePrometheusError(w, err)
+		return
+	}
+
+	pred, err := remote.SeriesPredicate(req.Matchers...)
+	if err != nil {
+		encodePrometheusError(w, invalidPrometheusParam("match[]", err.Error()))
+		return
+	}
+	keys, err := h.ReadStore.TagKeys(ctx, &datatypes.TagKeysRequest{
+		TagsSource: req.Source,
+		Range:      req.Range,
+		Predicate:  pred,
+	})
+	if err != nil {
+		encodePrometheusError(w, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePrometheusLabels",
+			Err:  err,
+		})
+		return
+	}
+
+	names := []string{}
+	for _, key := range readStrings(keys) {
+		if name, ok := remote.LabelName(key); ok {
+			names = append(names, name)
+		}
+	}
+	h.encodeData(w, r, uniqueStrings(names))
+}
+
+// handleLabelValues returns the values of a label of the series.
+func (h *PrometheusHandler) handleLabelValues(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	req, err := h.decodeSeriesRequest(ctx, r, false)
+	if err != nil {
+		encodePrometheusError(w, err)
+		return
+	}
+
+	pred, err := remote.SeriesPredicate(req.Matchers...)
+	if err != nil {
+		encodePrometheusError(w, invalidPrometheusParam("match[]", err.Error()))
+		return
+	}
+	name := httprouter.ParamsFromContext(ctx).ByName("name")
+	values, err := h.ReadStore.TagValues(ctx, &datatypes.TagValuesRequest{
+		TagsSource: req.Source,
+		Range:      req.Range,
+		Predicate:  pred,
+		TagKey:     remote.TagKey(name),
+	})
+	if err != nil {
+		encodePrometheusError(w, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePrometheusLabelValues",
+			Err:  err,
+		})
+		return
+	}
+	h.encodeData(w, r, uniqueStrings(readStrings(values)))
+}
+
+// handleSeries returns the labels of the series matching the match[]
+// selectors.
+func (h *PrometheusHandler) handleSeries(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	req, err := h.decodeSeriesRequest(ctx, r, true)
+	if err != nil {
+		encodePrometheusError(w, err)
+		return
+	}
+
+	pred, err := remote.SeriesPredicate(req.Matchers...)
+	if err != nil {
+		encodePrometheusError(w, invalidPrometheusParam("match[]", err.Error()))
+		return
+	}
+	rs, err := h.ReadStore.ReadFilter(ctx, &datatypes.ReadFilterRequest{
+		ReadSource: req.Source,
+		Range:      req.Range,
+		Predicate:  pred,
+	})
+	if err != nil {
+		encodePrometheusError(w, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePrometheusSeries",
+			Err:  err,
+		})
+		return
+	}
+	series, err := remote.ReadSeriesLabels(rs)
+	if err != nil {
+		encodePrometheusError(w, &platform.Error{
+			Code: platform.EInternal,
+			Op:   "http/handlePrometheusSeries",
+			Err:  err,
+		})
+		return
+	}
+
+	data := make([]map[string]string, 0, len(series))
+	for _, labels := range series {
+		m := make(map[string]string, len(labels))
+		for _, l := range labels {
+			m[l.Name] = l.Value
+		}
+		data = append(data, m)
+	}
+	h.encodeData(w, r, data)
+}
+
+// decodeSeriesRequest decodes the bucket, time range and match[] selectors
+// of r, which must be authorized to read the bucket. The bucket belongs to
+// the organization named by the org or orgID parameters, or else to the
+// organization of the token, as for queries.
+func (h *PrometheusHandler) decodeSeriesRequest(ctx context.Context, r *http.Request, matchRequired bool) (*prometheusSeriesRequest, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/decodePrometheusQuery",
+			Msg:  err.Error(),
+		}
+	}
+
+	req := &prometheusSeriesRequest{}
+	for _, m := range r.Form["match[]"] {
+		matchers, err := decodePrometheusSelector(m)
+		if err != nil {
+			return nil, err
+		}
+		req.Matchers = append(req.Matchers, matchers)
+	}
+	if matchRequired && len(req.Matchers) == 0 {
+		return nil, invalidPrometheusParam("match[]", "no match[] parameter provided")
+	}
+
+	// The time range defaults to all time, as in Prometheus.
+	req.Range = datatypes.TimestampRange{Start: models.MinNanoTime, End: models.MaxNanoTime}
+	if v := r.FormValue("start"); v != "" {
+		t, err := parsePrometheusTime(v)
+		if err != nil {
+			return nil, invalidPrometheusParam("start", err.Error())
+		}
+		req.Range.Start = t.UnixNano()
+	}
+	if v := r.FormValue("end"); v != "" {
+		t, err := parsePrometheusTime(v)
+		if err != nil {
+			return nil, invalidPrometheusParam("end", err.Error())
+		}
+		req.Range.End = t.UnixNano()
+	}
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := h.queryOrganization(ctx, r, a)
+	if err != nil {
+		return nil, err
+	}
+	name := r.FormValue("bucket")
+	if name == "" {
+		name = promql.DefaultBucket
+	}
+	bucket, err := h.BucketService.FindBucket(ctx, platform.BucketFilter{
+		OrganizationID: &org.ID,
+		Name:           &name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeBucket(a, bucket, platform.ReadAction); err != nil {
+		return nil, err
+	}
+
+	req.Source, err = types.MarshalAny(h.ReadStore.GetSource(uint64(bucket.OrganizationID), uint64(bucket.ID)))
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// decodePrometheusSelector returns the label matchers of the instant vector
+// selector s.
+func decodePrometheusSelector(s string) ([]*prompb.LabelMatcher, error) {
+	parsed, err := promql.ParsePromQL(s)
+	if err != nil {
+		return nil, invalidPrometheusParam("match[]", err.Error())
+	}
+	sel, ok := parsed.(*promql.Selector)
+	if !ok || sel.Range != 0 || sel.Offset != 0 {
+		return nil, invalidPrometheusParam("match[]", fmt.Sprintf("%q is not an instant vector selector", s))
+	}
+
+	matchers := []*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Name: remote.MetricNameLabel, Value: sel.Name}}
+	for _, m := range sel.LabelMatchers {
+		var value string
+		switch v := m.Value.Value().(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, invalidPrometheusParam("match[]", fmt.Sprintf("invalid value for label %s", m.Name))
+		}
+		matchers = append(matchers, &prompb.LabelMatcher{
+			Type:  prometheusMatchTypes[m.Kind],
+			Name:  m.Name,
+			Value: value,
+		})
+	}
+	return matchers, nil
+}
+
+var prometheusMatchTypes = map[promql.MatchKind]prompb.LabelMatcher_Type{
+	promql.Equal:        prompb.LabelMatcher_EQ,
+	promql.NotEqual:     prompb.LabelMatcher_NEQ,
+	promql.RegexMatch:   prompb.LabelMatcher_RE,
+	promql.RegexNoMatch: prompb.LabelMatcher_NRE,
+}
+
+func (h *PrometheusHandler) encodeData(w http.ResponseWriter, r *http.Request, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(&prometheusDataResponse{Status: "success", Data: data}); err != nil {
+		logEncodingError(h.Logger, r, err)
+	}
+}
+
+// readStrings returns the strings of si.
+func readStrings(si storage.StringIterator) []string {
+	var ss []string
+	for si.Next() {
+		ss = append(ss, si.Value())
+	}
+	return ss
+}
+
+// uniqueStrings returns the distinct strings of ss in order.
+func uniqueStrings(ss []string) []string {
+	sort.Strings(ss)
+	unique := []string{}
+	for i, s := range ss {
+		if i == 0 || s != ss[i-1] {
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
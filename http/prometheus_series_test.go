@@ -0,0 +1,237 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query/promql"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"go.uber.org/zap"
+)
+
+// seriesStore is a reads.Store with the series of tags, which records the
+// predicates of its reads.
+type seriesStore struct {
+	reads.Store
+	series     []models.Tags
+	predicates []string
+}
+
+func (s *seriesStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return &datatypes.ReadFilterRequest{}
+}
+
+func (s *seriesStore) record(pred *datatypes.Predicate) {
+	r := strings.NewReplacer(models.MeasurementTagKey, "_m", models.FieldKeyTagKey, "_f")
+	s.predicates = append(s.predicates, r.Replace(reads.PredicateToExprString(pred)))
+}
+
+func (s *seriesStore) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (storage.StringIterator, error) {
+	s.record(req.Predicate)
+	var keys []string
+	for _, tags := range s.series {
+		for _, t := range tags {
+			keys = append(keys, string(t.Key))
+		}
+	}
+	return &stringIterator{values: keys}, nil
+}
+
+func (s *seriesStore) TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (storage.StringIterator, error) {
+	s.record(req.Predicate)
+	var values []string
+	for _, tags := range s.series {
+		if v := tags.Get([]byte(req.TagKey)); v != nil {
+			values = append(values, string(v))
+		}
+	}
+	return &stringIterator{values: values}, nil
+}
+
+func (s *seriesStore) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
+	s.record(req.Predicate)
+	return &tagsResultSet{series: s.series, i: -1}, nil
+}
+
+type stringIterator struct {
+	values []string
+	value  string
+}
+
+func (si *stringIterator) Next() bool {
+	if len(si.values) == 0 {
+		return false
+	}
+	si.value, si.values = si.values[0], si.values[1:]
+	return true
+}
+
+func (si *stringIterator) Value() string { return si.value }
+
+// tagsResultSet is a reads.ResultSet of series without samples.
+type tagsResultSet struct {
+	series []models.Tags
+	i      int
+}
+
+func (rs *tagsResultSet) Next() bool {
+	rs.i++
+	return rs.i < len(rs.series)
+}
+
+func (rs *tagsResultSet) Cursor() cursors.Cursor     { return nil }
+func (rs *tagsResultSet) Tags() models.Tags          { return rs.series[rs.i] }
+func (rs *tagsResultSet) Close()                     {}
+func (rs *tagsResultSet) Err() error                 { return nil }
+func (rs *tagsResultSet) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+func TestPrometheusHandler_handleSeries(t *testing.T) {
+	series := []models.Tags{
+		models.NewTags(map[string]string{"_measurement": "up", "_field": "value", "job": "a"}),
+		models.NewTags(map[string]string{"_measurement": "up", "_field": "value", "job": "b", "instance": "x"}),
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		permissions   []platform.Permission
+		wantStatus    int
+		wantErrType   promql.ErrorType
+		wantData      string
+		wantPredicate string
+	}{
+		{
+			name:          "label names",
+			path:          "/api/v1/labels",
+			wantStatus:    http.StatusOK,
+			wantData:      `["__name__", "instance", "job"]`,
+			wantPredicate: `'_f' = "value"`,
+		},
+		{
+			name:          "label values of matching series",
+			path:          `/api/v1/label/job/values?match[]=up{job=~"a|b"}`,
+			wantStatus:    http.StatusOK,
+			wantData:      `["a", "b"]`,
+			wantPredicate: `'_f' = "value" AND '_m' = "up" AND 'job' =~ /^(?:a|b)$/`,
+		},
+		{
+			name:          "metric names",
+			path:          "/api/v1/label/__name__/values",
+			wantStatus:    http.StatusOK,
+			wantData:      `["up"]`,
+			wantPredicate: `'_f' = "value"`,
+		},
+		{
+			name:       "series",
+			path:       "/api/v1/series?match[]=up&match[]=down{job!=\"a\"}&start=1569888000&end=1569888060",
+			wantStatus: http.StatusOK,
+			wantData: `[
+				{"__name__": "up", "job": "a"},
+				{"__name__": "up", "instance": "x", "job": "b"}
+			]`,
+			wantPredicate: `'_f' = "value" AND '_m' = "up" OR '_f' = "value" AND '_m' = "down" AND 'job' != "a"`,
+		},
+		{
+			name:        "series without a selector",
+			path:        "/api/v1/series",
+			wantStatus:  http.StatusBadRequest,
+			wantErrType: promql.ErrorBadData,
+		},
+		{
+			name:        "expression that is not a selector",
+			path:        "/api/v1/series?match[]=sum(up)",
+			wantStatus:  http.StatusBadRequest,
+			wantErrType: promql.ErrorBadData,
+		},
+		{
+			name:        "bucket that is not readable",
+			path:        "/api/v1/labels",
+			permissions: []platform.Permission{},
+			wantStatus:  http.StatusForbidden,
+			wantErrType: platform.EForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &seriesStore{series: series}
+			h := NewPrometheusHandler(&PrometheusBackend{
+				Logger:    zap.NewNop(),
+				ReadStore: store,
+				BucketService: &mock.BucketService{
+					FindBucketFn: func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+						if filter.OrganizationID == nil || *filter.OrganizationID != 1 || filter.Name == nil || *filter.Name != promql.DefaultBucket {
+							return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+						}
+						return &platform.Bucket{ID: 2, OrganizationID: 1, Name: promql.DefaultBucket}, nil
+					},
+				},
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+						return &platform.Organization{ID: id, Name: "org"}, nil
+					},
+				},
+			})
+
+			orgID := platform.ID(1)
+			permissions := tt.permissions
+			if permissions == nil {
+				permissions = []platform.Permission{
+					{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID}},
+				}
+			}
+			r := httptest.NewRequest("GET", "http://any.url"+tt.path, nil)
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+				Status:      platform.Active,
+				OrgID:       orgID,
+				Permissions: permissions,
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("unexpected status code %d: %s", res.StatusCode, w.Body.String())
+			}
+
+			var resp struct {
+				Status    string           `json:"status"`
+				Data      interface{}      `json:"data"`
+				ErrorType promql.ErrorType `json:"errorType"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.ErrorType != tt.wantErrType {
+				t.Errorf("unexpected error type %q: %s", resp.ErrorType, w.Body.String())
+			}
+			if tt.wantData == "" {
+				return
+			}
+
+			var want interface{}
+			if err := json.Unmarshal([]byte(tt.wantData), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(want, resp.Data) {
+				t.Errorf("unexpected data -want/+got\n%s", cmp.Diff(want, resp.Data))
+			}
+			if len(store.predicates) != 1 || store.predicates[0] != tt.wantPredicate {
+				t.Errorf("unexpected predicates %q", store.predicates)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -20,10 +21,13 @@ import (
 	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/query"
-	"github.com/influxdata/influxql"
+	"github.com/influxdata/influxdb/query/encoding"
+	"github.com/influxdata/influxdb/query/influxql"
+	"github.com/influxdata/influxdb/query/promql"
+	iql "github.com/influxdata/influxql"
 )
 
-// QueryRequest is a flux query request.
+// QueryRequest is a flux, InfluxQL or PromQL query request.
 type QueryRequest struct {
 	Extern  *ast.File    `json:"extern,omitempty"`
 	Spec    *flux.Spec   `json:"spec,omitempty"`
@@ -32,7 +36,27 @@ type QueryRequest struct {
 	Type    string       `json:"type"`
 	Dialect QueryDialect `json:"dialect"`
 
+	// Bucket, Start, End and Step are the parameters of PromQL queries. The
+	// query is evaluated at each step from start to end, or only at end if
+	// there is no start; end defaults to now.
+	Bucket string     `json:"bucket,omitempty"`
+	Start  *time.Time `json:"start,omitempty"`
+	End    *time.Time `json:"end,omitempty"`
+	Step   string     `json:"step,omitempty"`
+
+	// DB, RP and Cluster are the default database and retention policy of
+	// InfluxQL queries, which are resolved to buckets unless a bucket is
+	// given. InfluxQL results are in the 1.x JSON format, or annotated CSV
+	// when the dialect has annotations.
+	DB      string `json:"db,omitempty"`
+	RP      string `json:"rp,omitempty"`
+	Cluster string `json:"cluster,omitempty"`
+
 	Org *influxdb.Organization `json:"-"`
+
+	// DBRPMappingService resolves the databases and retention policies of
+	// InfluxQL queries.
+	DBRPMappingService influxdb.DBRPMappingService `json:"-"`
 }
 
 // QueryDialect is the formatting options for the query response.
@@ -75,7 +99,17 @@ func (r QueryRequest) Validate() error {
 		}
 	}
 
-	if r.Type != "flux" {
+	switch r.Type {
+	case "flux":
+	case "influxql":
+		if err := r.validateInfluxQL(); err != nil {
+			return err
+		}
+	case "promql":
+		if err := r.validatePromQL(); err != nil {
+			return err
+		}
+	default:
 		return fmt.Errorf(`unknown query type: %s`, r.Type)
 	}
 
@@ -109,6 +143,54 @@ func (r QueryRequest) Validate() error {
 	return nil
 }
 
+func (r QueryRequest) validateInfluxQL() error {
+	if r.Query == "" || r.Spec != nil || r.AST != nil || r.Extern != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "influxql request body requires a query and no spec, AST or external declarations",
+		}
+	}
+
+	if r.Bucket == "" && r.DB == "" {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "influxql request body requires a bucket or db",
+		}
+	}
+	return nil
+}
+
+func (r QueryRequest) validatePromQL() error {
+	if r.Query == "" || r.Spec != nil || r.AST != nil || r.Extern != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "promql request body requires a query and no spec, AST or external declarations",
+		}
+	}
+
+	if r.Start != nil && r.End != nil && r.End.Before(*r.Start) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "promql request end must not be before start",
+		}
+	}
+
+	if r.Step != "" {
+		if step, err := time.ParseDuration(r.Step); err != nil || step <= 0 {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("invalid promql request step %q: must be a positive duration", r.Step),
+			}
+		}
+	} else if r.Start != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "promql request with a start requires a step",
+		}
+	}
+	return nil
+}
+
 // QueryAnalysis is a structured response of errors.
 type QueryAnalysis struct {
 	Errors []queryParseError `json:"errors"`
@@ -158,7 +240,7 @@ func (r QueryRequest) analyzeFluxQuery() (*QueryAnalysis, error) {
 
 func (r QueryRequest) analyzeInfluxQLQuery() (*QueryAnalysis, error) {
 	a := &QueryAnalysis{}
-	_, err := influxql.ParseQuery(r.Query)
+	_, err := iql.ParseQuery(r.Query)
 	if err == nil {
 		a.Errors = []queryParseError{}
 		return a, nil
@@ -222,7 +304,11 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 	}
 	// Query is preferred over spec
 	var compiler flux.Compiler
-	if r.Query != "" {
+	if r.Type == "influxql" {
+		compiler = r.influxqlCompiler(now())
+	} else if r.Type == "promql" {
+		compiler = r.promqlCompiler(now())
+	} else if r.Query != "" {
 		pkg, err := flux.Parse(r.Query)
 		if err != nil {
 			return nil, err
@@ -257,6 +343,16 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		noHeader = !*r.Dialect.Header
 	}
 
+	if r.Type == "influxql" && len(r.Dialect.Annotations) == 0 {
+		return &query.ProxyRequest{
+			Request: query.Request{
+				OrganizationID: r.Org.ID,
+				Compiler:       compiler,
+			},
+			Dialect: &influxql.Dialect{},
+		}, nil
+	}
+
 	// TODO(nathanielc): Use commentPrefix and dateTimeFormat
 	// once they are supported.
 	return &query.ProxyRequest{
@@ -274,6 +370,35 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 	}, nil
 }
 
+func (r QueryRequest) influxqlCompiler(now time.Time) *influxql.Compiler {
+	c := influxql.NewCompiler(r.DBRPMappingService)
+	c.Cluster = r.Cluster
+	c.DB = r.DB
+	c.RP = r.RP
+	c.Bucket = r.Bucket
+	c.Query = r.Query
+	c.Now = &now
+	return c
+}
+
+func (r QueryRequest) promqlCompiler(now time.Time) *promql.Compiler {
+	c := &promql.Compiler{
+		Query:  r.Query,
+		Bucket: r.Bucket,
+		Start:  now,
+		End:    now,
+	}
+	if r.End != nil {
+		c.Start, c.End = *r.End, *r.End
+	}
+	if r.Start != nil {
+		c.Start = *r.Start
+		// Validate guarantees a valid step.
+		c.Step, _ = time.ParseDuration(r.Step)
+	}
+	return c
+}
+
 // QueryRequestFromProxyRequest converts a query.ProxyRequest into a QueryRequest.
 // The ProxyRequest must contain supported compilers and dialects otherwise an error occurs.
 func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error) {
@@ -288,6 +413,24 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 	case lang.ASTCompiler:
 		qr.Type = "flux"
 		qr.AST = c.AST
+	case *influxql.Compiler:
+		qr.Type = "influxql"
+		qr.Query = c.Query
+		qr.Bucket = c.Bucket
+		qr.DB = c.DB
+		qr.RP = c.RP
+		qr.Cluster = c.Cluster
+	case *promql.Compiler:
+		qr.Type = "promql"
+		qr.Query = c.Query
+		qr.Bucket = c.Bucket
+		end := c.End
+		qr.End = &end
+		if !c.Start.Equal(c.End) {
+			start := c.Start
+			qr.Start = &start
+			qr.Step = c.Step.String()
+		}
 	default:
 		return nil, fmt.Errorf("unsupported compiler %T", c)
 	}
@@ -299,6 +442,12 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 		qr.Dialect.CommentPrefix = "#"
 		qr.Dialect.DateTimeFormat = "RFC3339"
 		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+	case *influxql.Dialect:
+		if _, ok := req.Request.Compiler.(*influxql.Compiler); !ok {
+			return nil, fmt.Errorf("unsupported dialect %T for compiler %T", d, req.Request.Compiler)
+		}
+	case *encoding.Dialect:
+		// The format is negotiated with the Accept header.
 	default:
 		return nil, fmt.Errorf("unsupported dialect %T", d)
 	}
@@ -341,11 +490,15 @@ func decodeQueryRequest(ctx context.Context, r *http.Request, svc influxdb.Organ
 	return &req, err
 }
 
-func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb.Authorizer, svc influxdb.OrganizationService) (*query.ProxyRequest, error) {
+func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb.Authorizer, svc influxdb.OrganizationService, bucketSvc influxdb.BucketService) (*query.ProxyRequest, error) {
 	req, err := decodeQueryRequest(ctx, r, svc)
 	if err != nil {
 		return nil, err
 	}
+	req.DBRPMappingService = &influxql.BucketDBRPMappingService{
+		OrganizationID: req.Org.ID,
+		BucketService:  bucketSvc,
+	}
 
 	pr, err := req.ProxyRequest()
 	if err != nil {
@@ -363,5 +516,41 @@ func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb
 	}
 
 	pr.Request.Authorization = token
+	pr.Request.Source = r.Header.Get("User-Agent")
 	return pr, nil
 }
+
+// acceptedQueryFormat returns the format of results accepted by r with the
+// greatest quality, and false if that is annotated CSV or any format.
+func acceptedQueryFormat(r *http.Request) (encoding.Format, bool) {
+	var (
+		format  encoding.Format
+		ok      bool
+		quality = 0.0
+	)
+	for _, accept := range r.Header["Accept"] {
+		for _, v := range strings.Split(accept, ",") {
+			mt, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if v, has := params["q"]; has {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+			if q <= quality {
+				continue
+			}
+
+			switch f, isFormat := encoding.FormatFromContentType(mt); {
+			case isFormat:
+				format, ok, quality = f, true, q
+			case mt == "text/csv" || mt == "*/*" || mt == "text/*":
+				ok, quality = false, q
+			}
+		}
+	}
+	return format, ok
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"time"
@@ -22,6 +23,8 @@ import (
 	"github.com/influxdata/influxdb/kit/check"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/encoding"
+	"github.com/influxdata/influxdb/query/influxql"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -38,6 +41,7 @@ type FluxBackend struct {
 	Logger *zap.Logger
 
 	OrganizationService platform.OrganizationService
+	BucketService       platform.BucketService
 	ProxyQueryService   query.ProxyQueryService
 }
 
@@ -48,6 +52,7 @@ func NewFluxBackend(b *APIBackend) *FluxBackend {
 
 		ProxyQueryService:   b.FluxService,
 		OrganizationService: b.OrganizationService,
+		BucketService:       b.BucketService,
 	}
 }
 
@@ -59,6 +64,7 @@ type FluxHandler struct {
 
 	Now                 func() time.Time
 	OrganizationService platform.OrganizationService
+	BucketService       platform.BucketService
 	ProxyQueryService   query.ProxyQueryService
 }
 
@@ -71,6 +77,7 @@ func NewFluxHandler(b *FluxBackend) *FluxHandler {
 
 		ProxyQueryService:   b.ProxyQueryService,
 		OrganizationService: b.OrganizationService,
+		BucketService:       b.BucketService,
 	}
 
 	h.HandlerFunc("POST", fluxPath, h.handleQuery)
@@ -94,7 +101,7 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req, err := decodeProxyQueryRequest(ctx, r, a, h.OrganizationService)
+	req, err := decodeProxyQueryRequest(ctx, r, a, h.OrganizationService, h.BucketService)
 	if err != nil && err != platform.ErrAuthorizerNotSupported {
 		EncodeError(ctx, err, w)
 		return
@@ -103,6 +110,14 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 	// Transform the context into one with the request's authorization.
 	ctx = pcontext.SetAuthorizer(ctx, req.Request.Authorization)
 
+	// Annotated CSV is the default, but results may be requested in other
+	// formats with the Accept header.
+	if _, ok := req.Dialect.(*csv.Dialect); ok {
+		if f, ok := acceptedQueryFormat(r); ok {
+			req.Dialect = &encoding.Dialect{Format: f}
+		}
+	}
+
 	hd, ok := req.Dialect.(HTTPDialect)
 	if !ok {
 		EncodeError(ctx, fmt.Errorf("unsupported dialect over HTTP %T", req.Dialect), w)
@@ -339,6 +354,11 @@ func (s *FluxService) Query(ctx context.Context, w io.Writer, r *query.ProxyRequ
 	if err != nil {
 		return flux.Statistics{}, tracing.LogError(span, err)
 	}
+	if r.Request.OrganizationID.Valid() {
+		params := url.Values{}
+		params.Set(OrgID, r.Request.OrganizationID.String())
+		u.RawQuery = params.Encode()
+	}
 
 	qreq, err := QueryRequestFromProxyRequest(r)
 	if err != nil {
@@ -357,7 +377,14 @@ func (s *FluxService) Query(ctx context.Context, w io.Writer, r *query.ProxyRequ
 	SetToken(s.Token, hreq)
 
 	hreq.Header.Set("Content-Type", "application/json")
-	hreq.Header.Set("Accept", "text/csv")
+	switch d := r.Dialect.(type) {
+	case *influxql.Dialect:
+		hreq.Header.Set("Accept", "application/json")
+	case *encoding.Dialect:
+		hreq.Header.Set("Accept", d.Format.ContentType())
+	default:
+		hreq.Header.Set("Accept", "text/csv")
+	}
 	hreq = hreq.WithContext(ctx)
 	tracing.InjectToHTTPRequest(span, hreq)
 
@@ -389,6 +416,10 @@ type FluxQueryService struct {
 	Addr               string
 	Token              string
 	InsecureSkipVerify bool
+
+	// Accept is the media type of the results to request; defaults to
+	// annotated CSV.
+	Accept string
 }
 
 // Query runs a flux query against a influx server and decodes the result
@@ -424,8 +455,13 @@ func (s *FluxQueryService) Query(ctx context.Context, r *query.Request) (flux.Re
 
 	SetToken(s.Token, hreq)
 
+	accept := s.Accept
+	if accept == "" {
+		accept = "text/csv"
+	}
+
 	hreq.Header.Set("Content-Type", "application/json")
-	hreq.Header.Set("Accept", "text/csv")
+	hreq.Header.Set("Accept", accept)
 	hreq = hreq.WithContext(ctx)
 	tracing.InjectToHTTPRequest(span, hreq)
 
@@ -440,7 +476,12 @@ func (s *FluxQueryService) Query(ctx context.Context, r *query.Request) (flux.Re
 		return nil, tracing.LogError(span, err)
 	}
 
-	decoder := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	var decoder flux.MultiResultDecoder = csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	if mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		if f, ok := encoding.FormatFromContentType(mt); ok {
+			decoder = encoding.NewMultiResultDecoder(f)
+		}
+	}
 	itr, err := decoder.Decode(resp.Body)
 	if err != nil {
 		return nil, tracing.LogError(span, err)
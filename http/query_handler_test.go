@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,8 +18,14 @@ import (
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/lang"
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/encoding"
+	"github.com/influxdata/influxdb/query/influxql"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap"
 )
 
 func TestFluxService_Query(t *testing.T) {
@@ -47,6 +55,24 @@ func TestFluxService_Query(t *testing.T) {
 			want:   flux.Statistics{},
 			wantW:  "howdy\n",
 		},
+		{
+			name:  "influxql query",
+			ctx:   context.Background(),
+			token: "mytoken",
+			r: &query.ProxyRequest{
+				Request: query.Request{
+					OrganizationID: 1,
+					Compiler: &influxql.Compiler{
+						Query:  "SELECT value FROM cpu",
+						Bucket: "telegraf",
+					},
+				},
+				Dialect: &influxql.Dialect{},
+			},
+			status: http.StatusOK,
+			want:   flux.Statistics{},
+			wantW:  "howdy\n",
+		},
 		{
 			name:  "error status",
 			token: "mytoken",
@@ -95,14 +121,16 @@ func TestFluxQueryService_Query(t *testing.T) {
 	var orgID platform.ID
 	orgID.DecodeFromString("aaaaaaaaaaaaaaaa")
 	tests := []struct {
-		name    string
-		token   string
-		ctx     context.Context
-		r       *query.Request
-		csv     string
-		status  int
-		want    string
-		wantErr bool
+		name        string
+		token       string
+		ctx         context.Context
+		r           *query.Request
+		accept      string
+		contentType string
+		body        string
+		status      int
+		want        string
+		wantErr     bool
 	}{
 		{
 			name:  "error status",
@@ -128,7 +156,7 @@ func TestFluxQueryService_Query(t *testing.T) {
 				},
 			},
 			status: http.StatusOK,
-			csv: `#datatype,string,long,dateTime:RFC3339,double,long,string,boolean,string,string,string
+			body: `#datatype,string,long,dateTime:RFC3339,double,long,string,boolean,string,string,string
 #group,false,false,false,false,false,false,false,true,true,true
 #default,_result,,,,,,,,,
 ,result,table,_time,usage_user,test,mystr,this,cpu,host,_measurement
@@ -136,20 +164,48 @@ func TestFluxQueryService_Query(t *testing.T) {
 `,
 			want: toCRLF(`,_result,0,2018-08-29T13:08:47Z,10.2,10,yay,true,cpu-total,a,cpui
 
+`),
+		},
+		{
+			name:  "returns json",
+			token: "mytoken",
+			ctx:   context.Background(),
+			r: &query.Request{
+				OrganizationID: orgID,
+				Compiler: lang.FluxCompiler{
+					Query: "from()",
+				},
+			},
+			accept:      "application/json",
+			contentType: "application/json",
+			status:      http.StatusOK,
+			body: `{"results":[{"name":"_result","tables":[{"columns":[` +
+				`{"label":"_time","datatype":"dateTime:RFC3339","group":false},{"label":"usage_user","datatype":"double","group":false},` +
+				`{"label":"test","datatype":"long","group":false},{"label":"mystr","datatype":"string","group":false},` +
+				`{"label":"this","datatype":"boolean","group":false},{"label":"cpu","datatype":"string","group":true},` +
+				`{"label":"host","datatype":"string","group":true},{"label":"_measurement","datatype":"string","group":true}],` +
+				`"records":[{"_time":"2018-08-29T13:08:47Z","usage_user":10.2,"test":10,"mystr":"yay","this":true,"cpu":"cpu-total","host":"a","_measurement":"cpui"}]}]}]}`,
+			want: toCRLF(`,_result,0,2018-08-29T13:08:47Z,10.2,10,yay,true,cpu-total,a,cpui
+
 `),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var orgIDStr string
+			var orgIDStr, accept string
 			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				orgIDStr = r.URL.Query().Get(OrgID)
+				accept = r.Header.Get("Accept")
+				if tt.contentType != "" {
+					w.Header().Set("Content-Type", tt.contentType)
+				}
 				w.WriteHeader(tt.status)
-				fmt.Fprintln(w, tt.csv)
+				fmt.Fprintln(w, tt.body)
 			}))
 			s := &FluxQueryService{
-				Addr:  ts.URL,
-				Token: tt.token,
+				Addr:   ts.URL,
+				Token:  tt.token,
+				Accept: tt.accept,
 			}
 			res, err := s.Query(tt.ctx, tt.r)
 			if (err != nil) != tt.wantErr {
@@ -184,6 +240,9 @@ func TestFluxQueryService_Query(t *testing.T) {
 			if got, want := orgIDStr, tt.r.OrganizationID.String(); got != want {
 				t.Errorf("FluxQueryService.Query() encoded orgID = %s, want %s", got, want)
 			}
+			if want := tt.accept; want != "" && accept != want {
+				t.Errorf("FluxQueryService.Query() accept = %s, want %s", accept, want)
+			}
 
 			got := b.String()
 			if !reflect.DeepEqual(got, tt.want) {
@@ -193,6 +252,116 @@ func TestFluxQueryService_Query(t *testing.T) {
 	}
 }
 
+func TestFluxHandler_handleQuery(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		accept          string
+		wantDialect     flux.Dialect
+		wantContentType string
+	}{
+		{
+			name:            "annotated csv by default",
+			body:            `{"query": "from()"}`,
+			wantDialect:     &csv.Dialect{},
+			wantContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:            "any format",
+			body:            `{"query": "from()"}`,
+			accept:          "*/*",
+			wantDialect:     &csv.Dialect{},
+			wantContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:            "json",
+			body:            `{"query": "from()"}`,
+			accept:          "application/json",
+			wantDialect:     &encoding.Dialect{Format: encoding.JSON},
+			wantContentType: "application/json",
+		},
+		{
+			name:            "csv is preferred",
+			body:            `{"query": "from()"}`,
+			accept:          "application/x-ndjson;q=0.5, text/csv",
+			wantDialect:     &csv.Dialect{},
+			wantContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:            "ndjson is preferred",
+			body:            `{"query": "from()"}`,
+			accept:          "text/csv;q=0.5, application/x-ndjson",
+			wantDialect:     &encoding.Dialect{Format: encoding.NDJSON},
+			wantContentType: "application/x-ndjson",
+		},
+		{
+			name:            "arrow",
+			body:            `{"query": "from()"}`,
+			accept:          "application/vnd.apache.arrow.stream",
+			wantDialect:     &encoding.Dialect{Format: encoding.Arrow},
+			wantContentType: "application/vnd.apache.arrow.stream",
+		},
+		{
+			name:            "unsupported format",
+			body:            `{"query": "from()"}`,
+			accept:          "application/xml",
+			wantDialect:     &csv.Dialect{},
+			wantContentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:            "influxql results are not negotiated",
+			body:            `{"type": "influxql", "query": "SELECT value FROM cpu", "bucket": "telegraf"}`,
+			accept:          "application/x-ndjson",
+			wantDialect:     &influxql.Dialect{},
+			wantContentType: "application/json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got flux.Dialect
+			h := NewFluxHandler(&FluxBackend{
+				Logger: zap.NewNop(),
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{ID: *filter.ID, Name: "org"}, nil
+					},
+				},
+				ProxyQueryService: &querymock.ProxyQueryService{
+					QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+						got = req.Dialect
+						return flux.Statistics{}, nil
+					},
+				},
+			})
+
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/query?orgID=0000000000000001", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+				Status: platform.Active,
+				OrgID:  1,
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status code %d: %s", w.Code, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.wantContentType {
+				t.Errorf("unexpected content type %q, want %q", ct, tt.wantContentType)
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(tt.wantDialect) {
+				t.Fatalf("unexpected dialect %T, want %T", got, tt.wantDialect)
+			}
+			if d, ok := tt.wantDialect.(*encoding.Dialect); ok && !cmp.Equal(d, got) {
+				t.Errorf("unexpected dialect -want/+got\n%s", cmp.Diff(d, got))
+			}
+		})
+	}
+}
+
 func TestFluxHandler_postFluxAST(t *testing.T) {
 	tests := []struct {
 		name   string
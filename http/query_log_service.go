@@ -0,0 +1,212 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// QueryLogBackend is all services and associated parameters required to construct
+// the QueryLogHandler.
+type QueryLogBackend struct {
+	Logger *zap.Logger
+
+	QueryLogSettingsService query.LogSettingsService
+	QueryHistoryService     query.HistoryService
+}
+
+// NewQueryLogBackend returns a new instance of QueryLogBackend.
+func NewQueryLogBackend(b *APIBackend) *QueryLogBackend {
+	return &QueryLogBackend{
+		Logger: b.Logger.With(zap.String("handler", "query_log")),
+
+		QueryLogSettingsService: b.QueryLogSettingsService,
+		QueryHistoryService:     b.QueryHistoryService,
+	}
+}
+
+// QueryLogHandler represents an HTTP API handler for the recent queries of
+// users and the slow query log settings of organizations.
+type QueryLogHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	QueryLogSettingsService query.LogSettingsService
+	QueryHistoryService     query.HistoryService
+}
+
+const (
+	queryHistoryPath     = "/api/v2/queries/history"
+	queryLogSettingsPath = "/api/v2/queries/settings"
+)
+
+// NewQueryLogHandler returns a new instance of QueryLogHandler.
+func NewQueryLogHandler(b *QueryLogBackend) *QueryLogHandler {
+	h := &QueryLogHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		QueryLogSettingsService: b.QueryLogSettingsService,
+		QueryHistoryService:     b.QueryHistoryService,
+	}
+
+	h.HandlerFunc("GET", queryHistoryPath, h.handleGetQueryHistory)
+	h.HandlerFunc("GET", queryLogSettingsPath, h.handleGetQueryLogSettings)
+	h.HandlerFunc("PUT", queryLogSettingsPath, h.handlePutQueryLogSettings)
+	return h
+}
+
+type queryHistoryResponse struct {
+	Queries []*query.LoggedQuery `json:"queries"`
+}
+
+// handleGetQueryHistory is the HTTP handler for the GET /api/v2/queries/history route.
+func (h *QueryLogHandler) handleGetQueryHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeGetQueryHistoryRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	queries, err := h.QueryHistoryService.FindRecentQueries(ctx, *filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, queryHistoryResponse{Queries: queries}); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodeGetQueryHistoryRequest(r *http.Request) (*query.RecentQueryFilter, error) {
+	active, err := decodeGetActiveQueriesRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	filter := &query.RecentQueryFilter{
+		OrganizationID: active.OrganizationID,
+		UserID:         active.UserID,
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "limit must be a non-negative integer",
+			}
+		}
+		filter.Limit = n
+	}
+	return filter, nil
+}
+
+// logSettings is the JSON of query log settings, with the threshold as a
+// duration literal such as "10s".
+type logSettings struct {
+	OrganizationID     platform.ID `json:"orgID"`
+	SlowQueryThreshold string      `json:"slowQueryThreshold"`
+	BucketID           platform.ID `json:"bucketID,omitempty"`
+}
+
+func newLogSettings(s *query.LogSettings) *logSettings {
+	return &logSettings{
+		OrganizationID:     s.OrganizationID,
+		SlowQueryThreshold: s.SlowQueryThreshold.String(),
+		BucketID:           s.BucketID,
+	}
+}
+
+// handleGetQueryLogSettings is the HTTP handler for the GET /api/v2/queries/settings route.
+func (h *QueryLogHandler) handleGetQueryLogSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID, err := platform.IDFromString(r.URL.Query().Get("orgID"))
+	if err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid orgID",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	settings, err := h.QueryLogSettingsService.FindLogSettings(ctx, *orgID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLogSettings(settings)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handlePutQueryLogSettings is the HTTP handler for the PUT /api/v2/queries/settings route.
+func (h *QueryLogHandler) handlePutQueryLogSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	settings, err := decodePutQueryLogSettingsRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.QueryLogSettingsService.PutLogSettings(ctx, settings); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newLogSettings(settings)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func decodePutQueryLogSettingsRequest(ctx context.Context, r *http.Request) (*query.LogSettings, error) {
+	var req logSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "unable to decode query log settings",
+			Err:  err,
+		}
+	}
+
+	if !req.OrganizationID.Valid() {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "query log settings require an orgID",
+		}
+	}
+
+	settings := &query.LogSettings{
+		OrganizationID: req.OrganizationID,
+		BucketID:       req.BucketID,
+	}
+	if req.SlowQueryThreshold != "" {
+		d, err := time.ParseDuration(req.SlowQueryThreshold)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "invalid slowQueryThreshold",
+				Err:  err,
+			}
+		}
+		settings.SlowQueryThreshold = d
+	}
+	return settings, nil
+}
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap"
+)
+
+// NewMockQueryLogBackend returns a QueryLogBackend with mock services.
+func NewMockQueryLogBackend() *QueryLogBackend {
+	return &QueryLogBackend{
+		Logger: zap.NewNop().With(zap.String("handler", "query_log")),
+
+		QueryLogSettingsService: &querymock.LogSettingsService{
+			FindLogSettingsF: func(ctx context.Context, orgID platform.ID) (*query.LogSettings, error) {
+				return &query.LogSettings{OrganizationID: orgID}, nil
+			},
+			PutLogSettingsF: func(context.Context, *query.LogSettings) error {
+				return nil
+			},
+		},
+		QueryHistoryService: &querymock.HistoryService{
+			FindRecentQueriesF: func(context.Context, query.RecentQueryFilter) ([]*query.LoggedQuery, error) {
+				return []*query.LoggedQuery{}, nil
+			},
+		},
+	}
+}
+
+func TestQueryLogHandler(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		svc   func(*QueryLogBackend)
+		r     *http.Request
+		wants wants
+	}{
+		{
+			name: "list recent queries of a user",
+			svc: func(b *QueryLogBackend) {
+				b.QueryHistoryService = &querymock.HistoryService{
+					FindRecentQueriesF: func(ctx context.Context, filter query.RecentQueryFilter) ([]*query.LoggedQuery, error) {
+						if filter.UserID == nil || *filter.UserID != 3 || filter.Limit != 10 {
+							t.Errorf("unexpected filter %+v", filter)
+						}
+						return []*query.LoggedQuery{
+							{
+								Time:           time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+								OrganizationID: 1,
+								UserID:         3,
+								Type:           "flux",
+								Query:          `from(bucket: "telegraf")`,
+								ResponseSize:   42,
+								Statistics: flux.Statistics{
+									TotalDuration:   3,
+									CompileDuration: 1,
+									ExecuteDuration: 2,
+								},
+								Slow: true,
+							},
+						}, nil
+					},
+				}
+			},
+			r: httptest.NewRequest("GET", "http://any.url/api/v2/queries/history?userID=0000000000000003&limit=10", nil),
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `
+{
+  "queries": [
+    {
+      "time": "2019-03-01T00:00:00Z",
+      "orgID": "0000000000000001",
+      "userID": "0000000000000003",
+      "type": "flux",
+      "query": "from(bucket: \"telegraf\")",
+      "responseSize": 42,
+      "statistics": {
+        "total_duration": 3,
+        "compile_duration": 1,
+        "queue_duration": 0,
+        "plan_duration": 0,
+        "requeue_duration": 0,
+        "execute_duration": 2,
+        "concurrency": 0,
+        "max_allocated": 0,
+        "metadata": null
+      },
+      "slow": true
+    }
+  ]
+}`,
+			},
+		},
+		{
+			name: "list recent queries with invalid limit",
+			r:    httptest.NewRequest("GET", "http://any.url/api/v2/queries/history?limit=-1", nil),
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "get settings",
+			r:    httptest.NewRequest("GET", "http://any.url/api/v2/queries/settings?orgID=0000000000000001", nil),
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"orgID": "0000000000000001", "slowQueryThreshold": "0s"}`,
+			},
+		},
+		{
+			name: "put settings",
+			svc: func(b *QueryLogBackend) {
+				b.QueryLogSettingsService = &querymock.LogSettingsService{
+					PutLogSettingsF: func(ctx context.Context, s *query.LogSettings) error {
+						if s.OrganizationID != 1 || s.SlowQueryThreshold != 10*time.Second {
+							t.Errorf("unexpected settings %+v", s)
+						}
+						return nil
+					},
+				}
+			},
+			r: httptest.NewRequest("PUT", "http://any.url/api/v2/queries/settings", strings.NewReader(`{"orgID": "0000000000000001", "slowQueryThreshold": "10s"}`)),
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"orgID": "0000000000000001", "slowQueryThreshold": "10s"}`,
+			},
+		},
+		{
+			name: "put settings with invalid threshold",
+			r:    httptest.NewRequest("PUT", "http://any.url/api/v2/queries/settings", strings.NewReader(`{"orgID": "0000000000000001", "slowQueryThreshold": "often"}`)),
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryLogBackend := NewMockQueryLogBackend()
+			if tt.svc != nil {
+				tt.svc(queryLogBackend)
+			}
+			h := NewQueryLogHandler(queryLogBackend)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, tt.r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%q. got %v, want %v", tt.name, res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil || !eq {
+					t.Errorf("%q. -got/+want %s %v", tt.name, diff, err)
+				}
+			}
+		})
+	}
+}
@@ -20,6 +20,8 @@ import (
 	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/query"
 	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/influxql"
+	"github.com/influxdata/influxdb/query/promql"
 )
 
 var cmpOptions = cmp.Options{
@@ -27,6 +29,7 @@ var cmpOptions = cmp.Options{
 	cmpopts.IgnoreUnexported(query.ProxyRequest{}),
 	cmpopts.IgnoreUnexported(query.Request{}),
 	cmpopts.IgnoreUnexported(flux.Spec{}),
+	cmpopts.IgnoreUnexported(influxql.Compiler{}),
 	cmpopts.EquateEmpty(),
 }
 
@@ -74,6 +77,7 @@ func TestQueryRequest_WithDefaults(t *testing.T) {
 }
 
 func TestQueryRequest_Validate(t *testing.T) {
+	start, end := time.Unix(0, 0), time.Unix(60, 0)
 	type fields struct {
 		Extern  *ast.File
 		Spec    *flux.Spec
@@ -81,6 +85,12 @@ func TestQueryRequest_Validate(t *testing.T) {
 		Query   string
 		Type    string
 		Dialect QueryDialect
+		Bucket  string
+		Start   *time.Time
+		End     *time.Time
+		Step    string
+		DB      string
+		RP      string
 		org     *platform.Organization
 	}
 	tests := []struct {
@@ -184,6 +194,70 @@ func TestQueryRequest_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "influxql query requires a bucket or db",
+			fields: fields{
+				Query: "SELECT value FROM cpu",
+				Type:  "influxql",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid influxql query",
+			fields: fields{
+				Query: "SELECT value FROM cpu",
+				Type:  "influxql",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				DB: "telegraf",
+			},
+		},
+		{
+			name: "promql range query requires a step",
+			fields: fields{
+				Query: "up",
+				Type:  "promql",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				Start: &start,
+			},
+			wantErr: true,
+		},
+		{
+			name: "promql query cannot have a spec",
+			fields: fields{
+				Query: "up",
+				Spec:  &flux.Spec{},
+				Type:  "promql",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid promql range query",
+			fields: fields{
+				Query: "up",
+				Type:  "promql",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				Start: &start,
+				End:   &end,
+				Step:  "15s",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -194,6 +268,12 @@ func TestQueryRequest_Validate(t *testing.T) {
 				Query:   tt.fields.Query,
 				Type:    tt.fields.Type,
 				Dialect: tt.fields.Dialect,
+				Bucket:  tt.fields.Bucket,
+				Start:   tt.fields.Start,
+				End:     tt.fields.End,
+				Step:    tt.fields.Step,
+				DB:      tt.fields.DB,
+				RP:      tt.fields.RP,
 				Org:     tt.fields.org,
 			}
 			if err := r.Validate(); (err != nil) != tt.wantErr {
@@ -204,6 +284,7 @@ func TestQueryRequest_Validate(t *testing.T) {
 }
 
 func TestQueryRequest_proxyRequest(t *testing.T) {
+	start, end := time.Unix(0, 0), time.Unix(60, 0)
 	type fields struct {
 		Extern  *ast.File
 		Spec    *flux.Spec
@@ -211,6 +292,12 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 		Query   string
 		Type    string
 		Dialect QueryDialect
+		Bucket  string
+		Start   *time.Time
+		End     *time.Time
+		Step    string
+		DB      string
+		RP      string
 		org     *platform.Organization
 	}
 	tests := []struct {
@@ -342,6 +429,125 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid influxql query",
+			fields: fields{
+				Query: "SELECT value FROM cpu",
+				Type:  "influxql",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				DB:  "telegraf",
+				RP:  "autogen",
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return end },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: &influxql.Compiler{
+						Query: "SELECT value FROM cpu",
+						DB:    "telegraf",
+						RP:    "autogen",
+						Now:   &end,
+					},
+				},
+				Dialect: &influxql.Dialect{},
+			},
+		},
+		{
+			name: "influxql query with annotated csv results",
+			fields: fields{
+				Query: "SELECT value FROM cpu",
+				Type:  "influxql",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Annotations:    []string{"datatype"},
+				},
+				Bucket: "telegraf",
+				org:    &platform.Organization{},
+			},
+			now: func() time.Time { return end },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: &influxql.Compiler{
+						Query:  "SELECT value FROM cpu",
+						Bucket: "telegraf",
+						Now:    &end,
+					},
+				},
+				Dialect: &csv.Dialect{
+					ResultEncoderConfig: csv.ResultEncoderConfig{
+						NoHeader:    false,
+						Delimiter:   ',',
+						Annotations: []string{"datatype"},
+					},
+				},
+			},
+		},
+		{
+			name: "valid promql instant query",
+			fields: fields{
+				Query:  "up",
+				Type:   "promql",
+				Bucket: "metrics",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return end },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: &promql.Compiler{
+						Query:  "up",
+						Bucket: "metrics",
+						Start:  end,
+						End:    end,
+					},
+				},
+				Dialect: &csv.Dialect{
+					ResultEncoderConfig: csv.ResultEncoderConfig{
+						NoHeader:  false,
+						Delimiter: ',',
+					},
+				},
+			},
+		},
+		{
+			name: "valid promql range query",
+			fields: fields{
+				Query: "up",
+				Type:  "promql",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				Start: &start,
+				End:   &end,
+				Step:  "15s",
+				org:   &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: &promql.Compiler{
+						Query: "up",
+						Start: start,
+						End:   end,
+						Step:  15 * time.Second,
+					},
+				},
+				Dialect: &csv.Dialect{
+					ResultEncoderConfig: csv.ResultEncoderConfig{
+						NoHeader:  false,
+						Delimiter: ',',
+					},
+				},
+			},
+		},
 		{
 			name: "valid spec",
 			fields: fields{
@@ -381,6 +587,12 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 				Query:   tt.fields.Query,
 				Type:    tt.fields.Type,
 				Dialect: tt.fields.Dialect,
+				Bucket:  tt.fields.Bucket,
+				Start:   tt.fields.Start,
+				End:     tt.fields.End,
+				Step:    tt.fields.Step,
+				DB:      tt.fields.DB,
+				RP:      tt.fields.RP,
 				Org:     tt.fields.org,
 			}
 			got, err := r.proxyRequest(tt.now)
@@ -684,7 +896,7 @@ func Test_decodeProxyQueryRequest(t *testing.T) {
 	cmpOptions := append(cmpOptions, cmpopts.IgnoreFields(lang.ASTCompiler{}, "Now"))
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := decodeProxyQueryRequest(tt.args.ctx, tt.args.r, tt.args.auth, tt.args.svc)
+			got, err := decodeProxyQueryRequest(tt.args.ctx, tt.args.r, tt.args.auth, tt.args.svc, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("decodeProxyQueryRequest() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,558 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	rolePath = "/api/v2/roles"
+)
+
+// RoleBackend is all services and associated parameters required to construct
+// the RoleHandler.
+type RoleBackend struct {
+	Logger              *zap.Logger
+	RoleService         platform.RoleService
+	OrganizationService platform.OrganizationService
+}
+
+// NewRoleBackend creates a backend used by the role handler.
+func NewRoleBackend(b *APIBackend) *RoleBackend {
+	return &RoleBackend{
+		Logger:              b.Logger.With(zap.String("handler", "role")),
+		RoleService:         b.RoleService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// RoleHandler is the handler for the role service
+type RoleHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	RoleService         platform.RoleService
+	OrganizationService platform.OrganizationService
+}
+
+// NewRoleHandler creates a new RoleHandler
+func NewRoleHandler(b *RoleBackend) *RoleHandler {
+	h := &RoleHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		RoleService:         b.RoleService,
+		OrganizationService: b.OrganizationService,
+	}
+
+	entityPath := fmt.Sprintf("%s/:id", rolePath)
+	entityMembersPath := fmt.Sprintf("%s/members", entityPath)
+	entityMembersIDPath := fmt.Sprintf("%s/:subjectID", entityMembersPath)
+
+	h.HandlerFunc("GET", rolePath, h.handleGetRoles)
+	h.HandlerFunc("POST", rolePath, h.handlePostRole)
+	h.HandlerFunc("GET", entityPath, h.handleGetRole)
+	h.HandlerFunc("PATCH", entityPath, h.handlePatchRole)
+	h.HandlerFunc("DELETE", entityPath, h.handleDeleteRole)
+	h.HandlerFunc("GET", entityMembersPath, h.handleGetRoleMembers)
+	h.HandlerFunc("POST", entityMembersPath, h.handlePostRoleMember)
+	h.HandlerFunc("DELETE", entityMembersIDPath, h.handleDeleteRoleMember)
+
+	return h
+}
+
+type roleLinks struct {
+	Self    string `json:"self"`
+	Members string `json:"members"`
+	Org     string `json:"org"`
+}
+
+type roleResponse struct {
+	*platform.Role
+	Links roleLinks `json:"links"`
+}
+
+func newRoleResponse(r *platform.Role) roleResponse {
+	if r.Permissions == nil {
+		r.Permissions = []platform.Permission{}
+	}
+	return roleResponse{
+		Role: r,
+		Links: roleLinks{
+			Self:    fmt.Sprintf("/api/v2/roles/%s", r.ID),
+			Members: fmt.Sprintf("/api/v2/roles/%s/members", r.ID),
+			Org:     fmt.Sprintf("/api/v2/orgs/%s", r.OrgID),
+		},
+	}
+}
+
+type getRolesResponse struct {
+	Roles []roleResponse    `json:"roles"`
+	Links map[string]string `json:"links"`
+}
+
+func newGetRolesResponse(roles []*platform.Role) getRolesResponse {
+	resp := getRolesResponse{
+		Roles: make([]roleResponse, 0, len(roles)),
+		Links: map[string]string{
+			"self": rolePath,
+		},
+	}
+	for _, r := range roles {
+		resp.Roles = append(resp.Roles, newRoleResponse(r))
+	}
+	return resp
+}
+
+type roleMappingResponse struct {
+	*platform.RoleMapping
+	Links map[string]string `json:"links"`
+}
+
+func newRoleMappingResponse(m *platform.RoleMapping) roleMappingResponse {
+	return roleMappingResponse{
+		RoleMapping: m,
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/roles/%s/members/%s", m.RoleID, m.SubjectID),
+			"role": fmt.Sprintf("/api/v2/roles/%s", m.RoleID),
+		},
+	}
+}
+
+type getRoleMappingsResponse struct {
+	Members []roleMappingResponse `json:"members"`
+	Links   map[string]string     `json:"links"`
+}
+
+func newGetRoleMappingsResponse(roleID platform.ID, ms []*platform.RoleMapping) getRoleMappingsResponse {
+	resp := getRoleMappingsResponse{
+		Members: make([]roleMappingResponse, 0, len(ms)),
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/roles/%s/members", roleID),
+		},
+	}
+	for _, m := range ms {
+		resp.Members = append(resp.Members, newRoleMappingResponse(m))
+	}
+	return resp
+}
+
+func decodeGetRolesRequest(ctx context.Context, r *http.Request, orgs platform.OrganizationService) (*platform.RoleFilter, error) {
+	qp := r.URL.Query()
+	f := &platform.RoleFilter{}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = id
+	} else if org := qp.Get("org"); org != "" {
+		o, err := orgs.FindOrganization(ctx, platform.OrganizationFilter{Name: &org})
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = &o.ID
+	}
+
+	if name := qp.Get("name"); name != "" {
+		f.Name = &name
+	}
+
+	return f, nil
+}
+
+func (h *RoleHandler) handleGetRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeGetRolesRequest(ctx, r, h.OrganizationService)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	roles, _, err := h.RoleService.FindRoles(ctx, *filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newGetRolesResponse(roles)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func requestRoleID(ctx context.Context, name string) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName(name)
+	if urlID == "" {
+		return platform.InvalidID(), &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing " + name,
+		}
+	}
+
+	id, err := platform.IDFromString(urlID)
+	if err != nil {
+		return platform.InvalidID(), &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}
+	}
+
+	return *id, nil
+}
+
+func (h *RoleHandler) handlePostRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	role := &platform.Role{}
+	if err := json.NewDecoder(r.Body).Decode(role); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	if err := role.Validate(); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.CreateRole(ctx, role); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newRoleResponse(role)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *RoleHandler) handleGetRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestRoleID(ctx, "id")
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	role, err := h.RoleService.FindRoleByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRoleResponse(role)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *RoleHandler) handlePatchRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestRoleID(ctx, "id")
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	var upd platform.RoleUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	role, err := h.RoleService.UpdateRole(ctx, id, upd)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRoleResponse(role)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *RoleHandler) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestRoleID(ctx, "id")
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.DeleteRole(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleHandler) handleGetRoleMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestRoleID(ctx, "id")
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ms, _, err := h.RoleService.FindRoleMappings(ctx, platform.RoleMappingFilter{
+		RoleID:      &id,
+		SubjectType: platform.ResourceType(r.URL.Query().Get("subjectType")),
+	})
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newGetRoleMappingsResponse(id, ms)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *RoleHandler) handlePostRoleMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestRoleID(ctx, "id")
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	m := &platform.RoleMapping{}
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+	m.RoleID = id
+
+	if err := m.Validate(); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.CreateRoleMapping(ctx, m); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newRoleMappingResponse(m)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *RoleHandler) handleDeleteRoleMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestRoleID(ctx, "id")
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	subjectID, err := requestRoleID(ctx, "subjectID")
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.RoleService.DeleteRoleMapping(ctx, id, subjectID); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RoleService is a role service over HTTP to the influxdb server
+type RoleService struct {
+	Addr               string
+	Token              string
+	InsecureSkipVerify bool
+}
+
+var _ platform.RoleService = (*RoleService)(nil)
+
+func roleIDPath(id platform.ID) string {
+	return path.Join(rolePath, id.String())
+}
+
+func roleMembersPath(id platform.ID) string {
+	return path.Join(rolePath, id.String(), "members")
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into v if it is set.
+func (s *RoleService) do(ctx context.Context, method, p string, query map[string]string, body, v interface{}) error {
+	u, err := newURL(s.Addr, p)
+	if err != nil {
+		return err
+	}
+
+	qp := u.Query()
+	for k, val := range query {
+		qp.Set(k, val)
+	}
+	u.RawQuery = qp.Encode()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, u.String(), &buf)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	SetToken(s.Token, req)
+
+	hc := newClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// FindRoleByID returns a single role by ID.
+func (s *RoleService) FindRoleByID(ctx context.Context, id platform.ID) (*platform.Role, error) {
+	var r roleResponse
+	if err := s.do(ctx, "GET", roleIDPath(id), nil, nil, &r); err != nil {
+		return nil, err
+	}
+	return r.Role, nil
+}
+
+// FindRoles returns a list of roles that match filter and the total count of matching roles.
+func (s *RoleService) FindRoles(ctx context.Context, filter platform.RoleFilter, opt ...platform.FindOptions) ([]*platform.Role, int, error) {
+	if filter.ID != nil {
+		r, err := s.FindRoleByID(ctx, *filter.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		return []*platform.Role{r}, 1, nil
+	}
+
+	query := map[string]string{}
+	if filter.OrgID != nil {
+		query["orgID"] = filter.OrgID.String()
+	}
+	if filter.Name != nil {
+		query["name"] = *filter.Name
+	}
+
+	var rs getRolesResponse
+	if err := s.do(ctx, "GET", rolePath, query, nil, &rs); err != nil {
+		return nil, 0, err
+	}
+
+	roles := make([]*platform.Role, 0, len(rs.Roles))
+	for _, r := range rs.Roles {
+		roles = append(roles, r.Role)
+	}
+	return roles, len(roles), nil
+}
+
+// CreateRole creates a new role and sets r.ID with the new identifier.
+func (s *RoleService) CreateRole(ctx context.Context, r *platform.Role) error {
+	var resp roleResponse
+	if err := s.do(ctx, "POST", rolePath, nil, r, &resp); err != nil {
+		return err
+	}
+	*r = *resp.Role
+	return nil
+}
+
+// UpdateRole updates a single role with changeset.
+func (s *RoleService) UpdateRole(ctx context.Context, id platform.ID, upd platform.RoleUpdate) (*platform.Role, error) {
+	var r roleResponse
+	if err := s.do(ctx, "PATCH", roleIDPath(id), nil, upd, &r); err != nil {
+		return nil, err
+	}
+	return r.Role, nil
+}
+
+// DeleteRole removes a role by ID.
+func (s *RoleService) DeleteRole(ctx context.Context, id platform.ID) error {
+	return s.do(ctx, "DELETE", roleIDPath(id), nil, nil, nil)
+}
+
+// FindRoleMappings returns the members of a role. Mappings can only be
+// found by role over HTTP.
+func (s *RoleService) FindRoleMappings(ctx context.Context, filter platform.RoleMappingFilter) ([]*platform.RoleMapping, int, error) {
+	if filter.RoleID == nil {
+		return nil, 0, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "role mappings must be filtered by role",
+		}
+	}
+
+	query := map[string]string{}
+	if filter.SubjectType != "" {
+		query["subjectType"] = string(filter.SubjectType)
+	}
+
+	var resp getRoleMappingsResponse
+	if err := s.do(ctx, "GET", roleMembersPath(*filter.RoleID), query, nil, &resp); err != nil {
+		return nil, 0, err
+	}
+
+	ms := make([]*platform.RoleMapping, 0, len(resp.Members))
+	for _, m := range resp.Members {
+		if filter.SubjectID != nil && *filter.SubjectID != m.SubjectID {
+			continue
+		}
+		ms = append(ms, m.RoleMapping)
+	}
+	return ms, len(ms), nil
+}
+
+// CreateRoleMapping assigns a role to a subject.
+func (s *RoleService) CreateRoleMapping(ctx context.Context, m *platform.RoleMapping) error {
+	return s.do(ctx, "POST", roleMembersPath(m.RoleID), nil, m, nil)
+}
+
+// DeleteRoleMapping removes a role from a subject.
+func (s *RoleService) DeleteRoleMapping(ctx context.Context, roleID, subjectID platform.ID) error {
+	return s.do(ctx, "DELETE", path.Join(roleMembersPath(roleID), subjectID.String()), nil, nil, nil)
+}
@@ -0,0 +1,194 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+// NewMockRoleBackend returns a RoleBackend with mock services.
+func NewMockRoleBackend() *RoleBackend {
+	return &RoleBackend{
+		Logger:              zap.NewNop().With(zap.String("handler", "role")),
+		RoleService:         mock.NewRoleService(),
+		OrganizationService: mock.NewOrganizationService(),
+	}
+}
+
+func TestRoleHandler_handleGetRoles(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082000")
+
+	backend := NewMockRoleBackend()
+	backend.OrganizationService = &mock.OrganizationService{
+		FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+			if *filter.Name != "myorg" {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "organization not found"}
+			}
+			return &platform.Organization{ID: orgID, Name: "myorg"}, nil
+		},
+	}
+	backend.RoleService = &mock.RoleService{
+		FindRolesF: func(ctx context.Context, filter platform.RoleFilter, opts ...platform.FindOptions) ([]*platform.Role, int, error) {
+			if filter.OrgID == nil || *filter.OrgID != orgID {
+				t.Errorf("unexpected filter %+v", filter)
+			}
+			return []*platform.Role{
+				{
+					ID:    platformtesting.MustIDBase16("0b501e7e557ab1ed"),
+					OrgID: orgID,
+					Name:  "readers",
+				},
+			}, 1, nil
+		},
+	}
+	h := NewRoleHandler(backend)
+
+	r := httptest.NewRequest("GET", "http://any.url/api/v2/roles?org=myorg", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != 200 {
+		t.Fatalf("got status %d, want 200: %s", res.StatusCode, body)
+	}
+	want := `
+{
+  "links": {
+    "self": "/api/v2/roles"
+  },
+  "roles": [
+    {
+      "id": "0b501e7e557ab1ed",
+      "orgID": "020f755c3c082000",
+      "name": "readers",
+      "permissions": [],
+      "links": {
+        "self": "/api/v2/roles/0b501e7e557ab1ed",
+        "members": "/api/v2/roles/0b501e7e557ab1ed/members",
+        "org": "/api/v2/orgs/020f755c3c082000"
+      }
+    }
+  ]
+}`
+	if eq, diff, _ := jsonEqual(string(body), want); !eq {
+		t.Errorf("got body different from want: %s", diff)
+	}
+}
+
+func TestRoleHandler_handlePostRole(t *testing.T) {
+	orgID := platformtesting.MustIDBase16("020f755c3c082000")
+
+	tests := []struct {
+		name       string
+		role       *platform.Role
+		statusCode int
+	}{
+		{
+			name: "create role",
+			role: &platform.Role{
+				OrgID: orgID,
+				Name:  "readers",
+				Permissions: []platform.Permission{
+					{
+						Action:   platform.ReadAction,
+						Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID},
+					},
+				},
+			},
+			statusCode: 201,
+		},
+		{
+			name:       "role requires a name",
+			role:       &platform.Role{OrgID: orgID},
+			statusCode: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewMockRoleBackend()
+			backend.RoleService = &mock.RoleService{
+				CreateRoleF: func(ctx context.Context, r *platform.Role) error {
+					r.ID = platformtesting.MustIDBase16("0b501e7e557ab1ed")
+					return nil
+				},
+			}
+			h := NewRoleHandler(backend)
+
+			b, err := json.Marshal(tt.role)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest("POST", "http://any.url/api/v2/roles", bytes.NewReader(b))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got := w.Result().StatusCode; got != tt.statusCode {
+				t.Errorf("got status %d, want %d", got, tt.statusCode)
+			}
+		})
+	}
+}
+
+func TestRoleHandler_handlePostRoleMember(t *testing.T) {
+	roleID := platformtesting.MustIDBase16("0b501e7e557ab1ed")
+	userID := platformtesting.MustIDBase16("020f755c3c082001")
+
+	var created *platform.RoleMapping
+	backend := NewMockRoleBackend()
+	backend.RoleService = &mock.RoleService{
+		CreateRoleMappingF: func(ctx context.Context, m *platform.RoleMapping) error {
+			created = m
+			return nil
+		},
+	}
+	h := NewRoleHandler(backend)
+
+	body := `{"subjectID": "020f755c3c082001", "subjectType": "users"}`
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/roles/0b501e7e557ab1ed/members", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got := w.Result().StatusCode; got != 201 {
+		t.Fatalf("got status %d, want 201", got)
+	}
+	want := &platform.RoleMapping{RoleID: roleID, SubjectID: userID, SubjectType: platform.UsersResourceType}
+	if created == nil || *created != *want {
+		t.Errorf("got mapping %+v, want %+v", created, want)
+	}
+}
+
+func TestRoleHandler_handleDeleteRoleMember(t *testing.T) {
+	backend := NewMockRoleBackend()
+	backend.RoleService = &mock.RoleService{
+		DeleteRoleMappingF: func(ctx context.Context, roleID, subjectID platform.ID) error {
+			return &platform.Error{Code: platform.ENotFound, Msg: platform.ErrRoleMappingNotFound}
+		},
+	}
+	h := NewRoleHandler(backend)
+
+	r := httptest.NewRequest("DELETE", "http://any.url", nil)
+	r = r.WithContext(context.WithValue(
+		context.TODO(),
+		httprouter.ParamsKey,
+		httprouter.Params{
+			{Key: "id", Value: "0b501e7e557ab1ed"},
+			{Key: "subjectID", Value: "020f755c3c082001"},
+		}))
+	w := httptest.NewRecorder()
+	h.handleDeleteRoleMember(w, r)
+
+	if got := w.Result().StatusCode; got != 404 {
+		t.Errorf("got status %d, want 404", got)
+	}
+}
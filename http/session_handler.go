@@ -2,9 +2,18 @@ package http
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/lockout"
+	"github.com/influxdata/influxdb/oidc"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
 )
@@ -16,6 +25,14 @@ type SessionBackend struct {
 
 	PasswordsService platform.PasswordsService
 	SessionService   platform.SessionService
+
+	// OIDCProvider enables single sign-on with an OpenID Connect identity
+	// provider when set, provisioning users with OIDCProvisioner.
+	OIDCProvider    *oidc.Provider
+	OIDCProvisioner *oidc.Provisioner
+
+	// Lockout protects password sign in from brute-force attacks when set.
+	Lockout *lockout.Tracker
 }
 
 // NewSessionBackend creates a new SessionBackend with associated logger.
@@ -25,6 +42,9 @@ func NewSessionBackend(b *APIBackend) *SessionBackend {
 
 		PasswordsService: b.PasswordsService,
 		SessionService:   b.SessionService,
+		OIDCProvider:     b.OIDCProvider,
+		OIDCProvisioner:  b.OIDCProvisioner,
+		Lockout:          b.SigninLockout,
 	}
 }
 
@@ -35,8 +55,16 @@ type SessionHandler struct {
 
 	PasswordsService platform.PasswordsService
 	SessionService   platform.SessionService
+	OIDCProvider     *oidc.Provider
+	OIDCProvisioner  *oidc.Provisioner
+	Lockout          *lockout.Tracker
 }
 
+const (
+	signinOIDCPath         = "/api/v2/signin/oidc"
+	signinOIDCCallbackPath = "/api/v2/signin/oidc/callback"
+)
+
 // NewSessionHandler returns a new instance of SessionHandler.
 func NewSessionHandler(b *SessionBackend) *SessionHandler {
 	h := &SessionHandler{
@@ -45,10 +73,17 @@ func NewSessionHandler(b *SessionBackend) *SessionHandler {
 
 		PasswordsService: b.PasswordsService,
 		SessionService:   b.SessionService,
+		OIDCProvider:     b.OIDCProvider,
+		OIDCProvisioner:  b.OIDCProvisioner,
+		Lockout:          b.Lockout,
 	}
 
 	h.HandlerFunc("POST", "/api/v2/signin", h.handleSignin)
 	h.HandlerFunc("POST", "/api/v2/signout", h.handleSignout)
+	if h.OIDCProvider != nil {
+		h.HandlerFunc("GET", signinOIDCPath, h.handleSigninOIDC)
+		h.HandlerFunc("GET", signinOIDCCallbackPath, h.handleSigninOIDCCallback)
+	}
 	return h
 }
 
@@ -62,11 +97,27 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	addr := remoteHost(r)
+	if h.Lockout != nil {
+		if wait, err := h.Lockout.Check(req.Username, addr); err != nil {
+			h.Logger.Info("Refused sign in during lockout", zap.String("user", req.Username), zap.String("remote_addr", addr))
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			EncodeError(ctx, err, w)
+			return
+		}
+	}
+
 	if err := h.PasswordsService.ComparePassword(ctx, req.Username, req.Password); err != nil {
 		// Don't log here, it should already be handled by the service
+		if h.Lockout != nil {
+			h.Lockout.Fail(req.Username, addr)
+		}
 		UnauthorizedError(ctx, w)
 		return
 	}
+	if h.Lockout != nil {
+		h.Lockout.Succeed(req.Username, addr)
+	}
 
 	s, e := h.SessionService.CreateSession(ctx, req.Username)
 	if e != nil {
@@ -78,11 +129,211 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+const (
+	cookieOIDCStateName = "oidc_state"
+	oidcStateLifetime   = 10 * time.Minute
+
+	// oidcLinkState marks a state cookie of a login that links the identity.
+	oidcLinkState = "link"
+)
+
+// handleSigninOIDC is the HTTP handler for the GET /signin/oidc route. It
+// redirects to the identity provider's login page. With the link parameter,
+// the identity is linked to the user of the current session instead of
+// signing in.
+func (h *SessionHandler) handleSigninOIDC(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	link := r.URL.Query().Get("link") == "true"
+	if link {
+		if _, err := h.findCookieSession(ctx, r); err != nil {
+			UnauthorizedError(ctx, w)
+			return
+		}
+	}
+
+	state, err := randomString()
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+	nonce, err := randomString()
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	// The state and nonce are kept in a cookie to be checked on the callback,
+	// which proves the login was started by this browser.
+	value := state + "." + nonce
+	if link {
+		value += "." + oidcLinkState
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieOIDCStateName,
+		Value:    value,
+		Path:     signinOIDCPath,
+		MaxAge:   int(oidcStateLifetime / time.Second),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+	})
+	http.Redirect(w, r, h.OIDCProvider.AuthCodeURL(state, nonce), http.StatusFound)
+}
+
+// handleSigninOIDCCallback is the HTTP handler for the GET /signin/oidc/callback
+// route. The identity provider redirects here after a user has logged in.
+func (h *SessionHandler) handleSigninOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, e := decodeSigninOIDCCallbackRequest(ctx, r)
+	if e != nil {
+		h.Logger.Info("Invalid OIDC callback", zap.Error(e))
+		UnauthorizedError(ctx, w)
+		return
+	}
+
+	// Expire the state cookie as it can only be used once.
+	http.SetCookie(w, &http.Cookie{
+		Name:   cookieOIDCStateName,
+		Path:   signinOIDCPath,
+		MaxAge: -1,
+	})
+
+	id, err := h.OIDCProvider.Exchange(ctx, req.Code, req.Nonce)
+	if err != nil {
+		h.Logger.Info("Failed to verify OIDC identity", zap.Error(err))
+		UnauthorizedError(ctx, w)
+		return
+	}
+
+	if req.Link {
+		h.linkOIDC(ctx, w, r, id)
+		return
+	}
+
+	u, err := h.OIDCProvisioner.Provision(ctx, id)
+	if err != nil {
+		h.Logger.Error("Failed to provision OIDC user", zap.String("user", id.Username), zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	s, err := h.SessionService.CreateSession(ctx, u.Name)
+	if err != nil {
+		UnauthorizedError(ctx, w)
+		return
+	}
+
+	encodeCookieSession(w, s)
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// linkOIDC links the identity to the user of the current session, so that
+// the user can sign in with it from now on.
+func (h *SessionHandler) linkOIDC(ctx context.Context, w http.ResponseWriter, r *http.Request, id *oidc.Identity) {
+	s, err := h.findCookieSession(ctx, r)
+	if err != nil {
+		UnauthorizedError(ctx, w)
+		return
+	}
+
+	if err := h.OIDCProvisioner.Link(ctx, id, s.UserID); err != nil {
+		h.Logger.Info("Failed to link OIDC identity", zap.String("subject", id.Subject), zap.Error(err))
+		EncodeError(ctx, err, w)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// findCookieSession returns the unexpired session of the session cookie.
+func (h *SessionHandler) findCookieSession(ctx context.Context, r *http.Request) (*platform.Session, error) {
+	key, e := decodeCookieSession(ctx, r)
+	if e != nil {
+		return nil, e
+	}
+	s, err := h.SessionService.FindSession(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Expired(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type signinOIDCCallbackRequest struct {
+	Code  string
+	Nonce string
+
+	// Link is true if the identity is linked to the user of the current
+	// session instead of signing in.
+	Link bool
+}
+
+func decodeSigninOIDCCallbackRequest(ctx context.Context, r *http.Request) (*signinOIDCCallbackRequest, *platform.Error) {
+	qp := r.URL.Query()
+	if e := qp.Get("error"); e != "" {
+		return nil, &platform.Error{
+			Code: platform.EUnauthorized,
+			Msg:  "identity provider returned error: " + e,
+		}
+	}
+
+	c, err := r.Cookie(cookieOIDCStateName)
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "missing oidc state cookie",
+			Err:  err,
+		}
+	}
+
+	parts := strings.SplitN(c.Value, ".", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[0] != qp.Get("state") {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "oidc state does not match",
+		}
+	}
+
+	code := qp.Get("code")
+	if code == "" {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "missing authorization code",
+		}
+	}
+
+	return &signinOIDCCallbackRequest{
+		Code:  code,
+		Nonce: parts[1],
+		Link:  len(parts) == 3 && parts[2] == oidcLinkState,
+	}, nil
+}
+
+// randomString returns a random URL safe string.
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 type signinRequest struct {
 	Username string
 	Password string
 }
 
+// remoteHost returns the host of the client that sent the request.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func decodeSigninRequest(ctx context.Context, r *http.Request) (*signinRequest, *platform.Error) {
 	u, p, ok := r.BasicAuth()
 	if !ok {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -11,7 +12,12 @@ import (
 
 	platform "github.com/influxdata/influxdb"
 	platformhttp "github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/lockout"
 	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/oidc"
+	"github.com/influxdata/influxdb/oidc/oidctest"
 )
 
 // NewMockSessionBackend returns a SessionBackend with mock services.
@@ -99,3 +105,225 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionHandler_handleSignin_Lockout(t *testing.T) {
+	password := "supersecret"
+	b := NewMockSessionBackend()
+	b.PasswordsService = &mock.PasswordsService{
+		ComparePasswordFn: func(ctx context.Context, name string, p string) error {
+			if p != password {
+				return &platform.Error{Code: platform.EForbidden, Msg: "your username or password is incorrect"}
+			}
+			return nil
+		},
+	}
+	b.SessionService = &mock.SessionService{
+		CreateSessionFn: func(context.Context, string) (*platform.Session, error) {
+			return &platform.Session{Key: "abc123xyz", ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+	}
+	b.Lockout = lockout.NewTracker(lockout.Config{
+		UserThreshold: 2,
+		AddrThreshold: 10,
+		Delay:         time.Minute,
+		MaxDelay:      time.Hour,
+		Window:        time.Hour,
+	})
+	h := platformhttp.NewSessionHandler(b)
+
+	signin := func(p string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/signin", nil)
+		r.SetBasicAuth("user1", p)
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := signin("wrong"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: got status %d, want %d", i+1, w.Code, http.StatusUnauthorized)
+		}
+	}
+
+	// Even the correct password is refused during the lockout.
+	w := signin(password)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got, want := w.Header().Get("Retry-After"), "60"; got != want {
+		t.Errorf("got Retry-After %q, want %q", got, want)
+	}
+
+	if err := b.Lockout.UnlockUser(context.Background(), "user1"); err != nil {
+		t.Fatal(err)
+	}
+	if w := signin(password); w.Code != http.StatusNoContent {
+		t.Errorf("got status %d after unlock, want %d", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestSessionHandler_handleSigninOIDC(t *testing.T) {
+	idp := oidctest.NewProvider("influxdb", "secret")
+	defer idp.Close()
+	idp.SetClaims(map[string]interface{}{
+		"sub":            "1234",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"groups":         []string{"admins"},
+	})
+
+	ctx := context.Background()
+	provider, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     "influxdb",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:9999/api/v2/signin/oidc/callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A user who signs in with a password, and has a session.
+	bob := &platform.User{Name: "bob@example.com"}
+	if err := svc.CreateUser(ctx, bob); err != nil {
+		t.Fatal(err)
+	}
+
+	var sessionUser string
+	b := NewMockSessionBackend()
+	b.SessionService = &mock.SessionService{
+		CreateSessionFn: func(ctx context.Context, user string) (*platform.Session, error) {
+			sessionUser = user
+			return &platform.Session{Key: "abc123xyz"}, nil
+		},
+		FindSessionFn: func(ctx context.Context, key string) (*platform.Session, error) {
+			if key != "bobsession" {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "session not found"}
+			}
+			return &platform.Session{Key: key, UserID: bob.ID, ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+	}
+	b.OIDCProvider = provider
+	b.OIDCProvisioner = &oidc.Provisioner{
+		Logger:                     zap.NewNop(),
+		UserService:                svc,
+		UserIdentityService:        svc,
+		OrganizationService:        svc,
+		UserResourceMappingService: svc,
+	}
+	h := platformhttp.NewSessionHandler(b)
+
+	// signin starts a sign in at target, which redirects to the identity
+	// provider, and returns the callback from the provider.
+	signin := func(t *testing.T, target, session string) func(state string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest("GET", target, nil)
+		if session != "" {
+			platformhttp.SetCookieSession(session, r)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got, want := w.Code, http.StatusFound; got != want {
+			t.Fatalf("bad status code: got %d want %d", got, want)
+		}
+		loc, err := url.Parse(w.Header().Get("Location"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 {
+			t.Fatalf("expected state cookie, got %v", cookies)
+		}
+
+		return func(state string) *httptest.ResponseRecorder {
+			if state == "" {
+				state = loc.Query().Get("state")
+			}
+			code := idp.Authorize(loc.Query().Get("nonce"))
+			r := httptest.NewRequest("GET", "http://localhost:9999/api/v2/signin/oidc/callback?code="+code+"&state="+state, nil)
+			r.AddCookie(cookies[0])
+			if session != "" {
+				platformhttp.SetCookieSession(session, r)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			return w
+		}
+	}
+
+	t.Run("invalid state", func(t *testing.T) {
+		w := signin(t, "http://localhost:9999/api/v2/signin/oidc", "")("wrong")
+		if got, want := w.Code, http.StatusUnauthorized; got != want {
+			t.Errorf("bad status code: got %d want %d", got, want)
+		}
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		w := signin(t, "http://localhost:9999/api/v2/signin/oidc", "")("")
+		if got, want := w.Code, http.StatusFound; got != want {
+			t.Fatalf("bad status code: got %d want %d", got, want)
+		}
+		if sessionUser != "jane@example.com" {
+			t.Errorf("got session for %q, want %q", sessionUser, "jane@example.com")
+		}
+		if _, err := svc.FindUser(ctx, platform.UserFilter{Name: &sessionUser}); err != nil {
+			t.Errorf("user not provisioned: %v", err)
+		}
+
+		var found bool
+		for _, c := range w.Result().Cookies() {
+			found = found || (c.Name == "session" && c.Value == "abc123xyz")
+		}
+		if !found {
+			t.Errorf("expected session cookie to be set: got %v", w.Header()["Set-Cookie"])
+		}
+	})
+
+	idp.SetClaims(map[string]interface{}{
+		"sub":            "5678",
+		"email":          "bob@example.com",
+		"email_verified": true,
+	})
+	sessionUser = ""
+
+	t.Run("existing user is not linked", func(t *testing.T) {
+		w := signin(t, "http://localhost:9999/api/v2/signin/oidc", "")("")
+		if got, want := w.Code, http.StatusForbidden; got != want {
+			t.Fatalf("bad status code: got %d want %d", got, want)
+		}
+		if sessionUser != "" {
+			t.Errorf("got session for %q, want none", sessionUser)
+		}
+	})
+
+	t.Run("link requires a session", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:9999/api/v2/signin/oidc?link=true", nil))
+		if got, want := w.Code, http.StatusUnauthorized; got != want {
+			t.Fatalf("bad status code: got %d want %d", got, want)
+		}
+	})
+
+	t.Run("link", func(t *testing.T) {
+		w := signin(t, "http://localhost:9999/api/v2/signin/oidc?link=true", "bobsession")("")
+		if got, want := w.Code, http.StatusFound; got != want {
+			t.Fatalf("bad status code: got %d want %d", got, want)
+		}
+		if sessionUser != "" {
+			t.Errorf("got session for %q, want none when linking", sessionUser)
+		}
+
+		w = signin(t, "http://localhost:9999/api/v2/signin/oidc", "")("")
+		if got, want := w.Code, http.StatusFound; got != want {
+			t.Fatalf("bad status code: got %d want %d", got, want)
+		}
+		if sessionUser != "bob@example.com" {
+			t.Errorf("got session for %q, want %q", sessionUser, "bob@example.com")
+		}
+	})
+}
@@ -0,0 +1,308 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	platcontext "github.com/influxdata/influxdb/context"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+)
+
+const (
+	shareLinkPath = "/api/v2/sharelinks"
+	// sharePath resolves share links. It is not authenticated.
+	sharePath = "/api/v2/share"
+)
+
+// ShareLinkBackend is all services and associated parameters required to
+// construct the ShareLinkHandler.
+type ShareLinkBackend struct {
+	Logger           *zap.Logger
+	ShareLinkService platform.ShareLinkService
+	DashboardService platform.DashboardService
+
+	// AuthorizationService looks up the authorizations of share links when
+	// they are resolved, before the request is authenticated.
+	AuthorizationService platform.AuthorizationService
+}
+
+// NewShareLinkBackend creates a backend used by the share link handler.
+func NewShareLinkBackend(b *APIBackend) *ShareLinkBackend {
+	return &ShareLinkBackend{
+		Logger:               b.Logger.With(zap.String("handler", "share_link")),
+		ShareLinkService:     b.ShareLinkService,
+		DashboardService:     b.DashboardService,
+		AuthorizationService: b.AuthorizationService,
+	}
+}
+
+// ShareLinkHandler is the handler for the share link service
+type ShareLinkHandler struct {
+	*httprouter.Router
+
+	Logger *zap.Logger
+
+	ShareLinkService     platform.ShareLinkService
+	DashboardService     platform.DashboardService
+	AuthorizationService platform.AuthorizationService
+}
+
+// NewShareLinkHandler creates a new ShareLinkHandler
+func NewShareLinkHandler(b *ShareLinkBackend) *ShareLinkHandler {
+	h := &ShareLinkHandler{
+		Router: NewRouter(),
+		Logger: b.Logger,
+
+		ShareLinkService:     b.ShareLinkService,
+		DashboardService:     b.DashboardService,
+		AuthorizationService: b.AuthorizationService,
+	}
+
+	h.HandlerFunc("GET", shareLinkPath, h.handleGetShareLinks)
+	h.HandlerFunc("POST", shareLinkPath, h.handlePostShareLink)
+	h.HandlerFunc("GET", shareLinkPath+"/:id", h.handleGetShareLink)
+	h.HandlerFunc("DELETE", shareLinkPath+"/:id", h.handleDeleteShareLink)
+	h.HandlerFunc("GET", sharePath+"/:key", h.handleResolveShareLink)
+
+	return h
+}
+
+type shareLinkResponse struct {
+	*platform.ShareLink
+	Links map[string]string `json:"links"`
+}
+
+func newShareLinkResponse(l *platform.ShareLink) shareLinkResponse {
+	return shareLinkResponse{
+		ShareLink: l,
+		Links: map[string]string{
+			"self":          fmt.Sprintf("%s/%s", shareLinkPath, l.ID),
+			"share":         fmt.Sprintf("%s/%s", sharePath, l.Key),
+			"dashboard":     fmt.Sprintf("/api/v2/dashboards/%s", l.DashboardID),
+			"authorization": fmt.Sprintf("/api/v2/authorizations/%s", l.AuthorizationID),
+		},
+	}
+}
+
+type getShareLinksResponse struct {
+	ShareLinks []shareLinkResponse `json:"shareLinks"`
+	Links      map[string]string   `json:"links"`
+}
+
+func newGetShareLinksResponse(ls []*platform.ShareLink) getShareLinksResponse {
+	resp := getShareLinksResponse{
+		ShareLinks: make([]shareLinkResponse, 0, len(ls)),
+		Links: map[string]string{
+			"self": shareLinkPath,
+		},
+	}
+	for _, l := range ls {
+		resp.ShareLinks = append(resp.ShareLinks, newShareLinkResponse(l))
+	}
+	return resp
+}
+
+func decodeGetShareLinksRequest(r *http.Request) (*platform.ShareLinkFilter, error) {
+	qp := r.URL.Query()
+	f := &platform.ShareLinkFilter{}
+
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := platform.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		f.OrgID = id
+	}
+
+	if dashboardID := qp.Get("dashboardID"); dashboardID != "" {
+		id, err := platform.IDFromString(dashboardID)
+		if err != nil {
+			return nil, err
+		}
+		f.DashboardID = id
+	}
+
+	return f, nil
+}
+
+func (h *ShareLinkHandler) handleGetShareLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filter, err := decodeGetShareLinksRequest(r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	ls, _, err := h.ShareLinkService.FindShareLinks(ctx, *filter)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newGetShareLinksResponse(ls)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// postShareLinkRequest is the part of a share link set by clients.
+type postShareLinkRequest struct {
+	DashboardID platform.ID `json:"dashboardID"`
+	Description string      `json:"description"`
+	ExpiresAt   *time.Time  `json:"expiresAt"`
+}
+
+func (h *ShareLinkHandler) handlePostShareLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req postShareLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}, w)
+		return
+	}
+
+	l := &platform.ShareLink{
+		DashboardID: req.DashboardID,
+		Description: req.Description,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := l.Validate(); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.ShareLinkService.CreateShareLink(ctx, l); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newShareLinkResponse(l)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func requestShareLinkID(ctx context.Context) (platform.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	urlID := params.ByName("id")
+	if urlID == "" {
+		return platform.InvalidID(), &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	id, err := platform.IDFromString(urlID)
+	if err != nil {
+		return platform.InvalidID(), &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  err.Error(),
+		}
+	}
+
+	return *id, nil
+}
+
+func (h *ShareLinkHandler) handleGetShareLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestShareLinkID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	l, err := h.ShareLinkService.FindShareLinkByID(ctx, id)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newShareLinkResponse(l)); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+func (h *ShareLinkHandler) handleDeleteShareLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := requestShareLinkID(ctx)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.ShareLinkService.DeleteShareLink(ctx, id); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// shareLinkResolution is what a share link resolves to: the token to query
+// the shared dashboard with, and the dashboard itself.
+type shareLinkResolution struct {
+	Token     string            `json:"token"`
+	ExpiresAt *time.Time        `json:"expiresAt,omitempty"`
+	Dashboard dashboardResponse `json:"dashboard"`
+}
+
+// handleResolveShareLink resolves a share link for a client that isn't
+// authenticated. The dashboard is read as the authorization of the link, so
+// that the response never contains more than its token can read.
+func (h *ShareLinkHandler) handleResolveShareLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key := httprouter.ParamsFromContext(ctx).ByName("key")
+	l, err := h.ShareLinkService.FindShareLinkByKey(ctx, key)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := l.Expired(); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	a, err := h.AuthorizationService.FindAuthorizationByID(ctx, l.AuthorizationID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if !a.IsActive() {
+		EncodeError(ctx, &platform.Error{
+			Code: platform.EForbidden,
+			Msg:  "share link has been revoked",
+		}, w)
+		return
+	}
+
+	d, err := h.DashboardService.FindDashboardByID(platcontext.SetAuthorizer(ctx, a), l.DashboardID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	res := shareLinkResolution{
+		Token:     a.Token,
+		ExpiresAt: l.ExpiresAt,
+		Dashboard: newDashboardResponse(d, nil),
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	platformtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+// NewMockShareLinkBackend returns a ShareLinkBackend with mock services.
+func NewMockShareLinkBackend() *ShareLinkBackend {
+	return &ShareLinkBackend{
+		Logger:               zap.NewNop().With(zap.String("handler", "share_link")),
+		ShareLinkService:     mock.NewShareLinkService(),
+		DashboardService:     mock.NewDashboardService(),
+		AuthorizationService: mock.NewAuthorizationService(),
+	}
+}
+
+func TestShareLinkHandler_handlePostShareLink(t *testing.T) {
+	dashboardID := platformtesting.MustIDBase16("020f755c3c082000")
+
+	backend := NewMockShareLinkBackend()
+	backend.ShareLinkService = &mock.ShareLinkService{
+		CreateShareLinkF: func(ctx context.Context, l *platform.ShareLink) error {
+			if l.DashboardID != dashboardID || l.Key != "" || l.Permissions != nil {
+				t.Errorf("unexpected share link %+v", l)
+			}
+			l.ID = platformtesting.MustIDBase16("0b501e7e557ab1ed")
+			l.Key = "key"
+			return nil
+		},
+	}
+	h := NewShareLinkHandler(backend)
+
+	// Clients can't choose the key or the permissions of a link.
+	body := `{"dashboardID": "020f755c3c082000", "key": "mine", "permissions": [{"action": "write", "resource": {"type": "buckets"}}]}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "http://any.url/api/v2/sharelinks", bytes.NewBufferString(body)))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	var resp shareLinkResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.Links["share"], "/api/v2/share/key"; got != want {
+		t.Errorf("expected share link %s, got %s", want, got)
+	}
+}
+
+func TestShareLinkHandler_handleResolveShareLink(t *testing.T) {
+	dashboardID := platformtesting.MustIDBase16("020f755c3c082000")
+	authID := platformtesting.MustIDBase16("0b501e7e557ab1ed")
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		link   *platform.ShareLink
+		status platform.Status
+		code   int
+	}{
+		{
+			name:   "resolves link to token and dashboard",
+			link:   &platform.ShareLink{DashboardID: dashboardID, AuthorizationID: authID, ExpiresAt: &future},
+			status: platform.Active,
+			code:   http.StatusOK,
+		},
+		{
+			name:   "expired link",
+			link:   &platform.ShareLink{DashboardID: dashboardID, AuthorizationID: authID, ExpiresAt: &past},
+			status: platform.Active,
+			code:   http.StatusForbidden,
+		},
+		{
+			name:   "inactive authorization",
+			link:   &platform.ShareLink{DashboardID: dashboardID, AuthorizationID: authID},
+			status: platform.Inactive,
+			code:   http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewMockShareLinkBackend()
+			backend.ShareLinkService = &mock.ShareLinkService{
+				FindShareLinkByKeyF: func(ctx context.Context, key string) (*platform.ShareLink, error) {
+					if key != "secret" {
+						return nil, &platform.Error{Code: platform.ENotFound, Msg: platform.ErrShareLinkNotFound}
+					}
+					return tt.link, nil
+				},
+			}
+			backend.AuthorizationService = &mock.AuthorizationService{
+				FindAuthorizationByIDFn: func(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+					return &platform.Authorization{ID: id, Token: "token", Status: tt.status}, nil
+				},
+			}
+			backend.DashboardService = &mock.DashboardService{
+				FindDashboardByIDF: func(ctx context.Context, id platform.ID) (*platform.Dashboard, error) {
+					return &platform.Dashboard{ID: id, Name: "status"}, nil
+				},
+			}
+			h := NewShareLinkHandler(backend)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", "http://any.url/api/v2/share/secret", nil))
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var res shareLinkResolution
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+			if res.Token != "token" || res.Dashboard.ID != dashboardID {
+				t.Errorf("unexpected resolution %+v", res)
+			}
+		})
+	}
+}
@@ -9,6 +9,10 @@ import (
 
 const tokenScheme = "Token " // TODO(goller): I'd like this to be Bearer
 
+// bearerScheme is also accepted, for clients such as Prometheus that can only
+// send bearer tokens.
+const bearerScheme = "Bearer "
+
 // errors
 var (
 	ErrAuthHeaderMissing = errors.New("authorization Header is missing")
@@ -21,10 +25,14 @@ func GetToken(r *http.Request) (string, error) {
 	if header == "" {
 		return "", ErrAuthHeaderMissing
 	}
-	if !strings.HasPrefix(header, tokenScheme) {
+	switch {
+	case strings.HasPrefix(header, tokenScheme):
+		return header[len(tokenScheme):], nil
+	case strings.HasPrefix(header, bearerScheme):
+		return header[len(bearerScheme):], nil
+	default:
 		return "", ErrAuthBadScheme
 	}
-	return header[len(tokenScheme):], nil
 }
 
 // SetToken adds the token to the request.
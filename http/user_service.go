@@ -21,6 +21,10 @@ type UserBackend struct {
 	UserService             influxdb.UserService
 	UserOperationLogService influxdb.UserOperationLogService
 	PasswordsService        influxdb.PasswordsService
+
+	// LockoutService enables the endpoints to inspect and lift the sign in
+	// lockout of users when set.
+	LockoutService influxdb.LockoutService
 }
 
 // NewUserBackend creates a UserBackend using information in the APIBackend.
@@ -40,6 +44,7 @@ type UserHandler struct {
 	UserService             influxdb.UserService
 	UserOperationLogService influxdb.UserOperationLogService
 	PasswordsService        influxdb.PasswordsService
+	LockoutService          influxdb.LockoutService
 }
 
 const (
@@ -49,6 +54,7 @@ const (
 	usersIDPath       = "/api/v2/users/:id"
 	usersPasswordPath = "/api/v2/users/:id/password"
 	usersLogPath      = "/api/v2/users/:id/logs"
+	usersLockoutPath  = "/api/v2/users/:id/lockout"
 )
 
 // NewUserHandler returns a new instance of UserHandler.
@@ -60,6 +66,7 @@ func NewUserHandler(b *UserBackend) *UserHandler {
 		UserService:             b.UserService,
 		UserOperationLogService: b.UserOperationLogService,
 		PasswordsService:        b.PasswordsService,
+		LockoutService:          b.LockoutService,
 	}
 
 	h.HandlerFunc("POST", usersPath, h.handlePostUser)
@@ -69,6 +76,10 @@ func NewUserHandler(b *UserBackend) *UserHandler {
 	h.HandlerFunc("PATCH", usersIDPath, h.handlePatchUser)
 	h.HandlerFunc("DELETE", usersIDPath, h.handleDeleteUser)
 	h.HandlerFunc("PUT", usersPasswordPath, h.handlePutUserPassword)
+	if h.LockoutService != nil {
+		h.HandlerFunc("GET", usersLockoutPath, h.handleGetUserLockout)
+		h.HandlerFunc("DELETE", usersLockoutPath, h.handleDeleteUserLockout)
+	}
 
 	h.HandlerFunc("GET", mePath, h.handleGetMe)
 	h.HandlerFunc("PUT", mePasswordPath, h.handlePutUserPassword)
@@ -248,6 +259,59 @@ func decodeGetUserRequest(ctx context.Context, r *http.Request) (*getUserRequest
 	return req, nil
 }
 
+// handleGetUserLockout is the HTTP handler for the GET /api/v2/users/:id/lockout route.
+func (h *UserHandler) handleGetUserLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetUserRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	u, err := h.UserService.FindUserByID(ctx, req.UserID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	l, err := h.LockoutService.FindUserLockout(ctx, u.Name)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, l); err != nil {
+		logEncodingError(h.Logger, r, err)
+		return
+	}
+}
+
+// handleDeleteUserLockout is the HTTP handler for the DELETE /api/v2/users/:id/lockout route.
+// It lifts the lockout of the user after failed sign in attempts.
+func (h *UserHandler) handleDeleteUserLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeGetUserRequest(ctx, r)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	u, err := h.UserService.FindUserByID(ctx, req.UserID)
+	if err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	if err := h.LockoutService.UnlockUser(ctx, u.Name); err != nil {
+		EncodeError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // handleDeleteUser is the HTTP handler for the DELETE /api/v2/users/:id route.
 func (h *UserHandler) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
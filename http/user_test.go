@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/inmem"
@@ -52,3 +53,45 @@ func TestUserService(t *testing.T) {
 	t.Parallel()
 	platformtesting.UserService(initUserService, t)
 }
+
+func TestUserHandler_handleUserLockout(t *testing.T) {
+	userID := platformtesting.MustIDBase16("020f755c3c082000")
+	lockedUntil := time.Date(2019, 6, 1, 0, 1, 0, 0, time.UTC)
+
+	var unlocked string
+	backend := NewMockUserBackend()
+	backend.UserService = &mock.UserService{
+		FindUserByIDFn: func(ctx context.Context, id platform.ID) (*platform.User, error) {
+			return &platform.User{ID: id, Name: "user1"}, nil
+		},
+	}
+	backend.LockoutService = &mock.LockoutService{
+		FindUserLockoutF: func(ctx context.Context, name string) (*platform.UserLockout, error) {
+			return &platform.UserLockout{Name: name, FailedAttempts: 5, LockedUntil: &lockedUntil}, nil
+		},
+		UnlockUserF: func(ctx context.Context, name string) error {
+			unlocked = name
+			return nil
+		},
+	}
+	h := NewUserHandler(backend)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "http://any.url/api/v2/users/020f755c3c082000/lockout", nil))
+	if w.Code != 200 {
+		t.Fatalf("got status %d, want 200", w.Code)
+	}
+	want := `{"name": "user1", "failedAttempts": 5, "lockedUntil": "2019-06-01T00:01:00Z"}`
+	if eq, diff, _ := jsonEqual(w.Body.String(), want); !eq {
+		t.Errorf("got body different from want: %s", diff)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "http://any.url/api/v2/users/"+userID.String()+"/lockout", nil))
+	if w.Code != 204 {
+		t.Fatalf("got status %d, want 204", w.Code)
+	}
+	if unlocked != "user1" {
+		t.Errorf("expected user1 to be unlocked, got %q", unlocked)
+	}
+}
@@ -1,11 +1,14 @@
 package http
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"mime"
 	"net/http"
 	"time"
 
@@ -16,7 +19,10 @@ import (
 	pcontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/csv2lp"
+	"github.com/influxdata/influxdb/pkg/json2lp"
 	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb"
 )
 
@@ -28,6 +34,10 @@ type WriteBackend struct {
 	PointsWriter        storage.PointsWriter
 	BucketService       platform.BucketService
 	OrganizationService platform.OrganizationService
+
+	// MaxBodySize is the maximum size in bytes of a decompressed request
+	// body. Zero means unlimited.
+	MaxBodySize int64
 }
 
 // NewWriteBackend returns a new instance of WriteBackend.
@@ -38,10 +48,13 @@ func NewWriteBackend(b *APIBackend) *WriteBackend {
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+
+		MaxBodySize: b.WriteMaxBodySize,
 	}
 }
 
-// WriteHandler receives line protocol and sends to a publish function.
+// WriteHandler receives line protocol, annotated CSV or JSON points and sends
+// them to a publish function.
 type WriteHandler struct {
 	*httprouter.Router
 
@@ -51,12 +64,33 @@ type WriteHandler struct {
 	OrganizationService platform.OrganizationService
 
 	PointsWriter storage.PointsWriter
+
+	// MaxBodySize is the maximum size in bytes of a decompressed request
+	// body. Zero means unlimited.
+	MaxBodySize int64
+	// MaxBatchSize is the number of points parsed before they are written.
+	// Zero means DefaultWriteBatchSize.
+	MaxBatchSize int
 }
 
 const (
-	writePath            = "/api/v2/write"
-	errInvalidGzipHeader = "gzipped HTTP body contains an invalid header"
-	errInvalidPrecision  = "invalid precision; valid precision units are ns, us, ms, and s"
+	writePath             = "/api/v2/write"
+	errInvalidGzipHeader  = "gzipped HTTP body contains an invalid header"
+	errInvalidPrecision   = "invalid precision; valid precision units are ns, us, ms, and s"
+	errInvalidConsistency = "invalid consistency; valid consistencies are buffered, wal, and fsync"
+	errInvalidWriteFormat = "invalid format; valid formats are lp, csv, and json"
+
+	// DefaultWriteBatchSize is the number of points written at once when
+	// WriteHandler.MaxBatchSize is not set.
+	DefaultWriteBatchSize = 5000
+
+	// maxWriteLineSize is the size of the longest line, CSV record or JSON
+	// point a write request can contain.
+	maxWriteLineSize = 16 * 1024 * 1024
+
+	// maxWriteLineErrors is the number of rejected lines reported back in
+	// partial write responses.
+	maxWriteLineErrors = 100
 )
 
 // NewWriteHandler creates a new handler at /api/v2/write to receive line protocol.
@@ -68,6 +102,8 @@ func NewWriteHandler(b *WriteBackend) *WriteHandler {
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+
+		MaxBodySize: b.MaxBodySize,
 	}
 
 	h.HandlerFunc("POST", writePath, h.handleWrite)
@@ -184,57 +220,293 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var body io.Reader = in
+	if h.MaxBodySize > 0 {
+		if r.ContentLength > h.MaxBodySize && in == r.Body {
+			EncodeError(ctx, &platform.Error{
+				Code: platform.ETooLarge,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("request body exceeds %d bytes", h.MaxBodySize),
+			}, w)
+			return
+		}
+		// Limit the decompressed body as it is read, since neither gzipped nor
+		// chunked bodies have a known size up front.
+		body = &maxBytesReader{r: in, n: h.MaxBodySize}
+	}
+
 	// TODO(jeff): we should be publishing with the org and bucket instead of
 	// parsing, rewriting, and publishing, but the interface isn't quite there yet.
 	// be sure to remove this when it is there!
-	data, err := ioutil.ReadAll(in)
-	if err != nil {
-		logger.Error("Error reading body", zap.Error(err))
-		EncodeError(ctx, &platform.Error{
-			Code: platform.EInternal,
-			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to read data: %v", err),
-			Err:  err,
-		}, w)
-		return
+	// Line protocol converted from other formats always has nanosecond
+	// timestamps.
+	br := &bodyReader{r: body}
+	var lr lineReader
+	precision := req.Precision
+	switch req.Format {
+	case writeFormatCSV:
+		cr := csv2lp.NewReader(br)
+		cr.SetMaxRecordSize(maxWriteLineSize)
+		lr, precision = cr, "ns"
+	case writeFormatJSON:
+		jr := json2lp.NewReader(br, req.Precision)
+		jr.SetMaxRecordSize(maxWriteLineSize)
+		lr, precision = jr, "ns"
+	default:
+		lr = newLineProtocolReader(br)
 	}
 
-	points, err := models.ParsePointsWithPrecision(data, time.Now(), req.Precision)
-	if err != nil {
-		logger.Error("Error parsing points", zap.Error(err))
-		EncodeError(ctx, &platform.Error{
-			Code: platform.EInvalid,
-			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to parse points: %v", err),
-			Err:  err,
-		}, w)
+	res := &partialWriteResponse{}
+	ctx = wal.SetConsistency(ctx, req.Consistency)
+	if err := h.writeLines(ctx, org.ID, bucket.ID, lr, br, precision, res); err != nil {
+		logger.Error("Error writing points", zap.Int("accepted", res.Accepted), zap.Error(err))
+		EncodeError(ctx, err, w)
 		return
 	}
 
-	exploded, err := tsdb.ExplodePoints(org.ID, bucket.ID, points)
-	if err != nil {
-		logger.Error("Error exploding points", zap.Error(err))
-		EncodeError(ctx, &platform.Error{
-			Code: platform.EInternal,
-			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to convert points to internal structures: %v", err),
-			Err:  err,
-		}, w)
+	if res.Rejected > 0 {
+		logger.Info("Rejected points", zap.Int("accepted", res.Accepted), zap.Int("rejected", res.Rejected))
+		res.Code = platform.EInvalid
+		res.Message = fmt.Sprintf("partial write: %d points rejected, %d accepted; line %d: %s",
+			res.Rejected, res.Accepted, res.Errors[0].Line, res.Errors[0].Reason)
+		w.Header().Set(PlatformErrorCodeHeader, platform.EInvalid)
+		if err := encodeResponse(ctx, w, http.StatusBadRequest, res); err != nil {
+			logEncodingError(h.Logger, r, err)
+		}
 		return
 	}
 
-	if err := h.PointsWriter.WritePoints(ctx, exploded); err != nil {
-		logger.Error("Error writing points", zap.Error(err))
-		EncodeError(ctx, &platform.Error{
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeLines parses the lines read from lr and writes them in batches of at
+// most h.MaxBatchSize points, so that memory use does not grow with the size
+// of the request. Lines that fail to parse or convert are added to res instead
+// of failing the request. Batches written before an error are not rolled back;
+// res.Accepted counts their points.
+func (h *WriteHandler) writeLines(ctx context.Context, orgID, bucketID platform.ID, lr lineReader, br *bodyReader, precision string, res *partialWriteResponse) error {
+	batchSize := h.MaxBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultWriteBatchSize
+	}
+
+	batch := make([]models.Point, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		exploded, err := tsdb.ExplodePoints(orgID, bucketID, batch)
+		if err != nil {
+			return &platform.Error{
+				Code: platform.EInternal,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("unable to convert points to internal structures: %v", err),
+				Err:  err,
+			}
+		}
+
+		if err := h.PointsWriter.WritePoints(ctx, exploded); err != nil {
+			return &platform.Error{
+				Code: platform.EInternal,
+				Op:   "http/handleWrite",
+				Msg:  fmt.Sprintf("unable to write points to database after writing %d points: %v", res.Accepted, err),
+				Err:  err,
+			}
+		}
+
+		res.Accepted += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	now := time.Now()
+	for {
+		tok, line, err := lr.ReadLine()
+		switch e := err.(type) {
+		case nil:
+		case *csv2lp.RecordError:
+			res.reject(line, e.Err)
+			continue
+		case *json2lp.RecordError:
+			res.reject(line, e.Err)
+			continue
+		default:
+			// Lines are only returned once complete, so the points read
+			// before an error are still written.
+			if ferr := flush(); ferr != nil {
+				return ferr
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return h.readError(line, err, br.err, res)
+		}
+
+		// Parsed points refer to the bytes they were parsed from, and readers
+		// reuse their buffers.
+		buf := make([]byte, len(tok))
+		copy(buf, tok)
+
+		points, err := models.ParsePointsWithPrecision(buf, now, precision)
+		if err != nil {
+			res.reject(line, err)
+			continue
+		}
+
+		batch = append(batch, points...)
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readError returns the error for failing to read the body of a write
+// request at line. readErr is the error reading the body itself, if any;
+// otherwise the body is malformed in a way that no more of it can be parsed.
+func (h *WriteHandler) readError(line int, err, readErr error, res *partialWriteResponse) error {
+	if readErr != nil {
+		err = readErr
+	}
+
+	switch {
+	case err == errBodyTooLarge:
+		return &platform.Error{
+			Code: platform.ETooLarge,
+			Op:   "http/handleWrite",
+			Msg:  fmt.Sprintf("request body exceeds %d bytes; %d points were written", h.MaxBodySize, res.Accepted),
+		}
+	case err == bufio.ErrTooLong:
+		return &platform.Error{
+			Code: platform.ETooLarge,
+			Op:   "http/handleWrite",
+			Msg:  fmt.Sprintf("line %d exceeds %d bytes; %d points were written", line, maxWriteLineSize, res.Accepted),
+		}
+	case readErr != nil:
+		return &platform.Error{
 			Code: platform.EInternal,
 			Op:   "http/handleWrite",
-			Msg:  fmt.Sprintf("unable to write points to database: %v", err),
+			Msg:  fmt.Sprintf("unable to read data after writing %d points: %v", res.Accepted, err),
 			Err:  err,
-		}, w)
-		return
+		}
+	default:
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/handleWrite",
+			Msg:  fmt.Sprintf("unable to parse data at line %d after writing %d points: %v", line, res.Accepted, err),
+			Err:  err,
+		}
 	}
+}
 
-	w.WriteHeader(http.StatusNoContent)
+// lineReader reads the body of a write request as line protocol, one line
+// at a time. ReadLine returns the next line and its number in the body, and
+// io.EOF after the last line. Readers of other formats return their
+// RecordError for data that could not be converted to line protocol.
+type lineReader interface {
+	ReadLine() (line []byte, n int, err error)
+}
+
+// lineProtocolReader is a lineReader of a line protocol body.
+type lineProtocolReader struct {
+	scanner *bufio.Scanner
+	lines   int
+}
+
+func newLineProtocolReader(r io.Reader) *lineProtocolReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxWriteLineSize)
+	scanner.Split(models.ScanLines)
+	return &lineProtocolReader{scanner: scanner}
+}
+
+// ReadLine returns the next line, which may contain escaped newlines in
+// string fields.
+func (r *lineProtocolReader) ReadLine() ([]byte, int, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return nil, r.lines + 1, err
+		}
+		return nil, r.lines, io.EOF
+	}
+
+	tok := r.scanner.Bytes()
+	line := r.lines + 1
+	r.lines += bytes.Count(bytes.TrimSuffix(tok, []byte{'\n'}), []byte{'\n'}) + 1
+	return tok, line, nil
+}
+
+// writeLineError is a line of a write request that was rejected.
+type writeLineError struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// partialWriteResponse reports the lines of a write request that were
+// rejected while the others were written. It is a superset of an encoded
+// platform.Error, so clients that only decode errors still report it.
+type partialWriteResponse struct {
+	Code     string           `json:"code"`
+	Message  string           `json:"message"`
+	Accepted int              `json:"accepted"`
+	Rejected int              `json:"rejected"`
+	Errors   []writeLineError `json:"errors"`
+}
+
+func (res *partialWriteResponse) reject(line int, err error) {
+	res.Rejected++
+	if len(res.Errors) < maxWriteLineErrors {
+		res.Errors = append(res.Errors, writeLineError{
+			Line:   line,
+			Reason: err.Error(),
+		})
+	}
+}
+
+// bodyReader records the first error reading the body of a write request,
+// to tell it apart from errors in the content of the body.
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = err
+	}
+	return n, err
+}
+
+// errBodyTooLarge is returned by maxBytesReader once its limit is exceeded.
+var errBodyTooLarge = errors.New("request body too large")
+
+// maxBytesReader reads at most n bytes from r, and then fails with
+// errBodyTooLarge.
+type maxBytesReader struct {
+	r io.Reader
+	n int64
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	if m.n <= 0 {
+		// Check whether the body ends exactly at the limit.
+		var b [1]byte
+		n, err := m.r.Read(b[:])
+		if n > 0 {
+			return 0, errBodyTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > m.n {
+		p = p[:m.n]
+	}
+	n, err := m.r.Read(p)
+	m.n -= int64(n)
+	return n, err
 }
 
 func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest, error) {
@@ -252,25 +524,79 @@ func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest
 		}
 	}
 
+	c, err := wal.ParseConsistency(qp.Get("consistency"))
+	if err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/decodeWriteRequest",
+			Msg:  errInvalidConsistency,
+		}
+	}
+
 	return &postWriteRequest{
-		Bucket:    qp.Get("bucket"),
-		Org:       qp.Get("org"),
-		Precision: p,
+		Bucket:      qp.Get("bucket"),
+		Org:         qp.Get("org"),
+		Precision:   p,
+		Consistency: c,
+		Format:      writeFormat(r.Header.Get("Content-Type")),
 	}, nil
 }
 
 type postWriteRequest struct {
-	Org       string
-	Bucket    string
-	Precision string
+	Org         string
+	Bucket      string
+	Precision   string
+	Consistency wal.Consistency
+	Format      string
+}
+
+// Formats of the body of a write request.
+const (
+	writeFormatLineProtocol = "lp"
+	writeFormatCSV          = "csv"
+	writeFormatJSON         = "json"
+)
+
+// writeFormat returns the format of a write request body with contentType.
+// Anything other than CSV or JSON is line protocol, since clients have never
+// had to set a content type for it.
+func writeFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return writeFormatLineProtocol
+	}
+
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return writeFormatCSV
+	case "application/json", "application/x-ndjson":
+		return writeFormatJSON
+	default:
+		return writeFormatLineProtocol
+	}
+}
+
+// writeContentTypes are the content types WriteService sends for each format.
+var writeContentTypes = map[string]string{
+	writeFormatLineProtocol: "text/plain; charset=utf-8",
+	writeFormatCSV:          "text/csv; charset=utf-8",
+	writeFormatJSON:         "application/json; charset=utf-8",
 }
 
-// WriteService sends data over HTTP to influxdb via line protocol.
+// WriteService sends data over HTTP to influxdb via line protocol, annotated
+// CSV or JSON points.
 type WriteService struct {
 	Addr               string
 	Token              string
 	Precision          string
 	InsecureSkipVerify bool
+
+	// Consistency is how durable the points are when Write returns. The
+	// server defaults to fsync when it is empty.
+	Consistency string
+	// Format is the format of the data written: lp, csv or json. Empty
+	// means line protocol.
+	Format string
 }
 
 var _ platform.WriteService = (*WriteService)(nil)
@@ -289,6 +615,19 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID platform.ID, r
 		}
 	}
 
+	format := s.Format
+	if format == "" {
+		format = writeFormatLineProtocol
+	}
+	contentType, ok := writeContentTypes[format]
+	if !ok {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Op:   "http/Write",
+			Msg:  errInvalidWriteFormat,
+		}
+	}
+
 	u, err := newURL(s.Addr, writePath)
 	if err != nil {
 		return err
@@ -304,7 +643,7 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID platform.ID, r
 		return err
 	}
 
-	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Content-Encoding", "gzip")
 	SetToken(s.Token, req)
 
@@ -322,6 +661,9 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID platform.ID, r
 	params.Set("org", string(org))
 	params.Set("bucket", string(bucket))
 	params.Set("precision", string(precision))
+	if s.Consistency != "" {
+		params.Set("consistency", s.Consistency)
+	}
 	req.URL.RawQuery = params.Encode()
 
 	hc := newClient(u.Scheme, s.InsecureSkipVerify)
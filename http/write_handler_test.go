@@ -3,14 +3,22 @@ package http
 import (
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 
 	platform "github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/storage/wal"
+	"go.uber.org/zap"
 )
 
 func TestWriteService_Write(t *testing.T) {
@@ -20,11 +28,13 @@ func TestWriteService_Write(t *testing.T) {
 		r      io.Reader
 	}
 	tests := []struct {
-		name    string
-		args    args
-		status  int
-		want    string
-		wantErr bool
+		name        string
+		args        args
+		format      string
+		status      int
+		want        string
+		contentType string
+		wantErr     bool
 	}{
 		{
 			args: args{
@@ -32,15 +42,30 @@ func TestWriteService_Write(t *testing.T) {
 				bucket: 2,
 				r:      strings.NewReader("m,t1=v1 f1=2"),
 			},
-			status: http.StatusNoContent,
-			want:   "m,t1=v1 f1=2",
+			status:      http.StatusNoContent,
+			want:        "m,t1=v1 f1=2",
+			contentType: "text/plain; charset=utf-8",
+		},
+		{
+			name: "json",
+			args: args{
+				org:    1,
+				bucket: 2,
+				r:      strings.NewReader(`{"measurement": "m", "fields": {"f1": 2}}`),
+			},
+			format:      "json",
+			status:      http.StatusNoContent,
+			want:        `{"measurement": "m", "fields": {"f1": 2}}`,
+			contentType: "application/json; charset=utf-8",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var org, bucket *platform.ID
 			var lp []byte
+			var contentType string
 			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
 				org, _ = platform.IDFromString(r.URL.Query().Get("org"))
 				bucket, _ = platform.IDFromString(r.URL.Query().Get("bucket"))
 				defer r.Body.Close()
@@ -50,7 +75,8 @@ func TestWriteService_Write(t *testing.T) {
 				w.WriteHeader(tt.status)
 			}))
 			s := &WriteService{
-				Addr: ts.URL,
+				Addr:   ts.URL,
+				Format: tt.format,
 			}
 			if err := s.Write(context.Background(), tt.args.org, tt.args.bucket, tt.args.r); (err != nil) != tt.wantErr {
 				t.Errorf("WriteService.Write() error = %v, wantErr %v", err, tt.wantErr)
@@ -66,6 +92,216 @@ func TestWriteService_Write(t *testing.T) {
 			if got, want := string(lp), tt.want; got != want {
 				t.Errorf("WriteService.Write() = %v, want %v", got, want)
 			}
+
+			if got, want := contentType, tt.contentType; got != want {
+				t.Errorf("WriteService.Write() content type = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// batchPointsWriter records the size and consistency of each batch of
+// points written.
+type batchPointsWriter struct {
+	batches     []int
+	consistency []wal.Consistency
+}
+
+func (w *batchPointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	w.batches = append(w.batches, len(points))
+	w.consistency = append(w.consistency, wal.GetConsistency(ctx))
+	return nil
+}
+
+// newTestWriteHandler returns a WriteHandler that writes to pw and
+// authorizes every write.
+func newTestWriteHandler(pw storage.PointsWriter, maxBodySize int64) *WriteHandler {
+	orgID, bucketID := platform.ID(1), platform.ID(2)
+	return NewWriteHandler(&WriteBackend{
+		Logger:       zap.NewNop(),
+		PointsWriter: pw,
+		BucketService: &mock.BucketService{
+			FindBucketFn: func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+				return &platform.Bucket{ID: bucketID, OrganizationID: orgID}, nil
+			},
+		},
+		OrganizationService: &mock.OrganizationService{
+			FindOrganizationByIDF: func(ctx context.Context, id platform.ID) (*platform.Organization, error) {
+				return &platform.Organization{ID: id}, nil
+			},
+		},
+		MaxBodySize: maxBodySize,
+	})
+}
+
+// newTestWriteRequest returns a write request to the org and bucket of
+// newTestWriteHandler.
+func newTestWriteRequest(query, body string) *http.Request {
+	orgID := platform.ID(1)
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/write?org=0000000000000001&bucket=0000000000000002"+query, strings.NewReader(body))
+	return r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Authorization{
+		Status: platform.Active,
+		Permissions: []platform.Permission{
+			{Action: platform.WriteAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: &orgID}},
+		},
+	}))
+}
+
+func TestWriteHandler_handleWrite(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		maxBodySize int64
+		code        int
+		batches     []int
+		res         *partialWriteResponse
+	}{
+		{
+			name:    "writes points in batches",
+			body:    "m f=1 1\nm f=2 2\nm f=3 3\n# comment\n\nm f=4 4\nm f=5 5",
+			code:    http.StatusNoContent,
+			batches: []int{2, 2, 1},
+		},
+		{
+			name:    "writes valid points of partial write",
+			body:    "m f=1 1\nm f=\"two\nlines\" 2\nm f= 3\nm f=4 4\nbad\nm f=6 6",
+			code:    http.StatusBadRequest,
+			batches: []int{2, 2},
+			res: &partialWriteResponse{
+				Code:     platform.EInvalid,
+				Accepted: 4,
+				Rejected: 2,
+				Errors: []writeLineError{
+					{Line: 4, Reason: "unable to parse 'm f= 3': missing field value"},
+					{Line: 6, Reason: "unable to parse 'bad': missing fields"},
+				},
+			},
+		},
+		{
+			name:        "writes annotated CSV",
+			contentType: "text/csv; charset=utf-8",
+			body:        "#datatype,string,long,dateTime:RFC3339,long,string,string\n,result,table,_time,_value,_field,_measurement\n,,0,1970-01-01T00:00:01Z,1,f,m\n,,0,1970-01-01T00:00:02Z,x,f,m\n,,0,1970-01-01T00:00:03Z,3,f,m\n",
+			code:        http.StatusBadRequest,
+			batches:     []int{2},
+			res: &partialWriteResponse{
+				Code:     platform.EInvalid,
+				Accepted: 2,
+				Rejected: 1,
+				Errors: []writeLineError{
+					{Line: 4, Reason: `invalid long "x"`},
+				},
+			},
+		},
+		{
+			name:        "writes JSON points",
+			contentType: "application/json",
+			body:        `[{"measurement": "m", "fields": {"f": 1}}, {"measurement": "m"}, {"measurement": "m", "fields": {"f": 3}, "time": 3}]`,
+			code:        http.StatusBadRequest,
+			batches:     []int{2},
+			res: &partialWriteResponse{
+				Code:     platform.EInvalid,
+				Accepted: 2,
+				Rejected: 1,
+				Errors: []writeLineError{
+					{Line: 2, Reason: "missing fields"},
+				},
+			},
+		},
+		{
+			name:        "rejects malformed JSON",
+			contentType: "application/x-ndjson",
+			body:        "{\"measurement\": \"m\", \"fields\": {\"f\": 1}}\n{\"measurement\"\n",
+			code:        http.StatusBadRequest,
+			batches:     []int{1},
+		},
+		{
+			name:        "rejects JSON point larger than the longest line",
+			contentType: "application/json",
+			body:        `[{"measurement": "m", "fields": {"f": 1}}, {"measurement": "` + strings.Repeat("m", maxWriteLineSize) + `"}]`,
+			code:        http.StatusRequestEntityTooLarge,
+			batches:     []int{1},
+		},
+		{
+			name:        "writes typed JSON values",
+			contentType: "application/json",
+			body:        `{"measurement": "m", "fields": {"f": {"type": "integer", "value": 1}}}`,
+			code:        http.StatusNoContent,
+			batches:     []int{1},
+		},
+		{
+			name:        "rejects body larger than max body size",
+			body:        "m f=1 1\nm f=2 2\nm f=3 3\n",
+			maxBodySize: 16,
+			code:        http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pw := &batchPointsWriter{}
+			h := newTestWriteHandler(pw, tt.maxBodySize)
+			h.MaxBatchSize = 2
+
+			r := newTestWriteRequest("", tt.body)
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if !reflect.DeepEqual(pw.batches, tt.batches) {
+				t.Errorf("expected batches %v, got %v", tt.batches, pw.batches)
+			}
+			if tt.res == nil {
+				return
+			}
+
+			var res partialWriteResponse
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+			res.Message = ""
+			if !reflect.DeepEqual(&res, tt.res) {
+				t.Errorf("expected partial write %+v, got %+v", tt.res, &res)
+			}
+		})
+	}
+}
+
+func TestWriteHandler_handleWriteConsistency(t *testing.T) {
+	tests := []struct {
+		query       string
+		code        int
+		consistency wal.Consistency
+	}{
+		{query: "", code: http.StatusNoContent, consistency: wal.ConsistencyFsync},
+		{query: "&consistency=buffered", code: http.StatusNoContent, consistency: wal.ConsistencyBuffered},
+		{query: "&consistency=wal", code: http.StatusNoContent, consistency: wal.ConsistencyWAL},
+		{query: "&consistency=fsync", code: http.StatusNoContent, consistency: wal.ConsistencyFsync},
+		{query: "&consistency=any", code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			pw := &batchPointsWriter{}
+			h := newTestWriteHandler(pw, 0)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, newTestWriteRequest(tt.query, "m f=1 1"))
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+			if tt.code != http.StatusNoContent {
+				return
+			}
+			if len(pw.consistency) != 1 || pw.consistency[0] != tt.consistency {
+				t.Errorf("expected points written with consistency %s, got %v", tt.consistency, pw.consistency)
+			}
 		})
 	}
 }
@@ -246,5 +246,35 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id platform.ID, upd *
 		a.Description = *upd.Description
 	}
 
+	if upd.ExpiresAt != nil {
+		a.ExpiresAt = upd.ExpiresAt
+	}
+
+	if upd.LastUsedAt != nil {
+		a.LastUsedAt = upd.LastUsedAt
+	}
+
+	return a, s.PutAuthorization(ctx, a)
+}
+
+// RotateAuthorization replaces the token of an authorization with a newly generated one.
+func (s *Service) RotateAuthorization(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+	op := OpPrefix + platform.OpRotateAuthorization
+	a, err := s.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, &platform.Error{
+			Err: err,
+			Op:  op,
+		}
+	}
+
+	a.Token, err = s.TokenGenerator.Token()
+	if err != nil {
+		return nil, &platform.Error{
+			Err: err,
+			Op:  op,
+		}
+	}
+
 	return a, s.PutAuthorization(ctx, a)
 }
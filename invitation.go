@@ -0,0 +1,111 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrInvitationNotFound is the error msg for a missing invitation.
+const ErrInvitationNotFound = "invitation not found"
+
+// ErrInvitationExpired is the error msg for an expired invitation.
+const ErrInvitationExpired = "invitation has expired"
+
+// DefaultInvitationExpiry is how long invitations without an expiry can be
+// accepted for.
+const DefaultInvitationExpiry = 7 * 24 * time.Hour
+
+// ops for invitations error and invitation op logs.
+const (
+	OpFindInvitationByID    = "FindInvitationByID"
+	OpFindInvitationByToken = "FindInvitationByToken"
+	OpFindInvitations       = "FindInvitations"
+	OpCreateInvitation      = "CreateInvitation"
+	OpDeleteInvitation      = "DeleteInvitation"
+	OpConsumeInvitation     = "ConsumeInvitation"
+)
+
+// Invitation invites someone, who may not have an account yet, to join an
+// organization. The invitee is identified by email or username, and accepts
+// the invitation with its one-time token.
+type Invitation struct {
+	ID       ID     `json:"id,omitempty"`
+	OrgID    ID     `json:"orgID"`
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Role is whether the invitee joins as a member or an owner of the
+	// organization.
+	Role UserType `json:"role"`
+	// Token accepts the invitation. It is only returned when the
+	// invitation is created.
+	Token     string    `json:"token,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy ID        `json:"createdBy,omitempty"`
+}
+
+// Validate returns an error if the invitation is invalid.
+func (i *Invitation) Validate() error {
+	if !i.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "invitation requires an orgID",
+		}
+	}
+
+	if i.Email == "" && i.Username == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "invitation requires an email or a username",
+		}
+	}
+
+	if err := i.Role.Valid(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Expired returns an error if the invitation is expired.
+func (i *Invitation) Expired() error {
+	if time.Now().After(i.ExpiresAt) {
+		return &Error{
+			Code: EForbidden,
+			Msg:  ErrInvitationExpired,
+		}
+	}
+
+	return nil
+}
+
+// InvitationFilter represents a set of filters that restrict the returned
+// invitations.
+type InvitationFilter struct {
+	ID    *ID
+	OrgID *ID
+}
+
+// InvitationService represents a service for managing invitations to
+// organizations.
+type InvitationService interface {
+	// FindInvitationByID returns a single invitation by ID.
+	FindInvitationByID(ctx context.Context, id ID) (*Invitation, error)
+
+	// FindInvitationByToken returns a single invitation by its token.
+	FindInvitationByToken(ctx context.Context, token string) (*Invitation, error)
+
+	// FindInvitations returns a list of invitations that match filter and the total count of matching invitations.
+	FindInvitations(ctx context.Context, filter InvitationFilter) ([]*Invitation, int, error)
+
+	// CreateInvitation creates a new invitation, generating its token, and
+	// sets i.ID with the new identifier.
+	CreateInvitation(ctx context.Context, i *Invitation) error
+
+	// DeleteInvitation revokes an invitation by ID.
+	DeleteInvitation(ctx context.Context, id ID) error
+
+	// ConsumeInvitation removes the unexpired invitation with token and
+	// returns it, so that each invitation is accepted at most once.
+	ConsumeInvitation(ctx context.Context, token string) (*Invitation, error)
+}
@@ -0,0 +1,344 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	influxdb "github.com/influxdata/influxdb"
+)
+
+var (
+	auditBucket      = []byte("auditeventsv1")
+	auditIndexBucket = []byte("auditeventsbyorgv1")
+)
+
+// auditDeleteBatchSize is the most events deleted in one transaction, so
+// that pruning a large audit trail does not hold the store for long.
+const auditDeleteBatchSize = 1000
+
+var _ influxdb.AuditService = (*Service)(nil)
+
+func (s *Service) initializeAudit(ctx context.Context, tx Tx) error {
+	b, err := tx.Bucket(auditBucket)
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(auditIndexBucket)
+	if err != nil {
+		return err
+	}
+
+	// Index the events recorded before the index existed.
+	idxCur, err := idx.Cursor()
+	if err != nil {
+		return err
+	}
+	if k, _ := idxCur.First(); k != nil {
+		return nil
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		e := &influxdb.AuditEvent{}
+		if err := json.Unmarshal(v, e); err != nil {
+			return err
+		}
+		if err := putAuditIndex(idx, e, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditTimeKey is the big endian time, so that keys sort by time.
+func auditTimeKey(t time.Time) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(t.UnixNano()))
+	return k
+}
+
+// auditEventKey orders events by the time they were recorded. The ID breaks
+// ties between events recorded at the same time.
+func auditEventKey(e *influxdb.AuditEvent) ([]byte, error) {
+	encID, err := e.ID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return append(auditTimeKey(e.Time), encID...), nil
+}
+
+// auditIndexKey is the organization of the event followed by its event key,
+// so that the events of an organization are ordered by time.
+func auditIndexKey(orgID influxdb.ID, eventKey []byte) ([]byte, error) {
+	encOrgID, err := orgID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return append(encOrgID, eventKey...), nil
+}
+
+// putAuditIndex indexes the event with key by its organization. Events of no
+// organization are not indexed.
+func putAuditIndex(idx Bucket, e *influxdb.AuditEvent, key []byte) error {
+	if !e.OrgID.Valid() {
+		return nil
+	}
+	ik, err := auditIndexKey(e.OrgID, key)
+	if err != nil {
+		return err
+	}
+	return idx.Put(ik, key)
+}
+
+// RecordAuditEvent stores the event, setting its ID and, if unset, its time.
+func (s *Service) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := e.Valid(); err != nil {
+			return err
+		}
+
+		e.ID = s.IDGenerator.ID()
+		if e.Time.IsZero() {
+			e.Time = s.time()
+		}
+		e.Time = e.Time.UTC()
+
+		k, err := auditEventKey(e)
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(e)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+
+		b, err := tx.Bucket(auditBucket)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(k, v); err != nil {
+			return err
+		}
+
+		idx, err := tx.Bucket(auditIndexBucket)
+		if err != nil {
+			return err
+		}
+		return putAuditIndex(idx, e, k)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpRecordAuditEvent,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindAuditEvents returns the events that match the filter in the order
+// they were recorded, or the reverse if the options are descending. Only the
+// events in the time range of the filter are read and, if the filter has an
+// organization, only the events of the organization.
+func (s *Service) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	var opts influxdb.FindOptions
+	if len(opt) > 0 {
+		opts = opt[0]
+	}
+
+	events := []*influxdb.AuditEvent{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(auditBucket)
+		if err != nil {
+			return err
+		}
+
+		// Without an organization, the events are read in the order of their
+		// keys. With one, the index is read and its values are the keys.
+		scanned := b
+		var prefix []byte
+		if filter.OrgID != nil {
+			if scanned, err = tx.Bucket(auditIndexBucket); err != nil {
+				return err
+			}
+			if prefix, err = filter.OrgID.Encode(); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Err:  err,
+				}
+			}
+		}
+		cur, err := scanned.Cursor()
+		if err != nil {
+			return err
+		}
+
+		seen := 0
+		return scanAuditRange(cur, prefix, filter.Since, filter.Until, opts.Descending, func(k, v []byte) (bool, error) {
+			if filter.OrgID != nil {
+				if v, err = b.Get(v); err != nil {
+					return false, err
+				}
+			}
+
+			e := &influxdb.AuditEvent{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return false, err
+			}
+			if filter.Match(e) {
+				if seen >= opts.Offset {
+					events = append(events, e)
+				}
+				seen++
+			}
+			return opts.Limit <= 0 || len(events) < opts.Limit, nil
+		})
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindAuditEvents,
+			Err: err,
+		}
+	}
+	return events, len(events), nil
+}
+
+// scanAuditRange calls fn for the keys with prefix whose time is in
+// [since, until), in order or in reverse, until fn returns false. Either
+// time may be nil for an open range.
+func scanAuditRange(cur Cursor, prefix []byte, since, until *time.Time, descending bool, fn func(k, v []byte) (bool, error)) error {
+	lo := prefix
+	if since != nil {
+		lo = append(append([]byte{}, prefix...), auditTimeKey(*since)...)
+	}
+	var hi []byte
+	if until != nil {
+		hi = append(append([]byte{}, prefix...), auditTimeKey(*until)...)
+	} else if len(prefix) > 0 {
+		hi = prefixEnd(prefix)
+	}
+
+	var k, v []byte
+	switch {
+	case !descending && len(lo) == 0:
+		k, v = cur.First()
+	case !descending:
+		k, v = cur.Seek(lo)
+	case hi == nil:
+		k, v = cur.Last()
+	default:
+		// Seek finds the first key at or after hi, so the last key before
+		// hi precedes it.
+		if k, _ = cur.Seek(hi); k == nil {
+			k, v = cur.Last()
+		} else {
+			k, v = cur.Prev()
+		}
+	}
+
+	for k != nil {
+		if !bytes.HasPrefix(k, prefix) || bytes.Compare(k, lo) < 0 || (hi != nil && bytes.Compare(k, hi) >= 0) {
+			return nil
+		}
+		ok, err := fn(k, v)
+		if err != nil || !ok {
+			return err
+		}
+
+		if descending {
+			k, v = cur.Prev()
+		} else {
+			k, v = cur.Next()
+		}
+	}
+	return nil
+}
+
+// prefixEnd returns the first key after every key with prefix, or nil if
+// there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// DeleteAuditEventsBefore deletes the events recorded before t, returning
+// how many were deleted. Events are deleted in batches, each in a
+// transaction of its own.
+func (s *Service) DeleteAuditEventsBefore(ctx context.Context, t time.Time) (int, error) {
+	deleted := 0
+	for {
+		n := 0
+		err := s.kv.Update(ctx, func(tx Tx) error {
+			b, err := tx.Bucket(auditBucket)
+			if err != nil {
+				return err
+			}
+			idx, err := tx.Bucket(auditIndexBucket)
+			if err != nil {
+				return err
+			}
+			cur, err := b.Cursor()
+			if err != nil {
+				return err
+			}
+
+			// Collect the keys first, as deleting moves the cursor.
+			type event struct {
+				key   []byte
+				orgID influxdb.ID
+			}
+			var events []event
+			if err := scanAuditRange(cur, nil, nil, &t, false, func(k, v []byte) (bool, error) {
+				e := &influxdb.AuditEvent{}
+				if err := json.Unmarshal(v, e); err != nil {
+					return false, err
+				}
+				events = append(events, event{key: append([]byte{}, k...), orgID: e.OrgID})
+				return len(events) < auditDeleteBatchSize, nil
+			}); err != nil {
+				return err
+			}
+
+			for _, e := range events {
+				if err := b.Delete(e.key); err != nil {
+					return err
+				}
+				if !e.orgID.Valid() {
+					continue
+				}
+				ik, err := auditIndexKey(e.orgID, e.key)
+				if err != nil {
+					return err
+				}
+				if err := idx.Delete(ik); err != nil {
+					return err
+				}
+			}
+			n = len(events)
+			return nil
+		})
+		if err != nil {
+			return deleted, &influxdb.Error{
+				Op:  OpPrefix + influxdb.OpDeleteAuditEvents,
+				Err: err,
+			}
+		}
+		deleted += n
+		if n < auditDeleteBatchSize {
+			return deleted, nil
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltAuditService(t *testing.T) {
+	influxdbtesting.AuditService(initBoltAuditService, t)
+}
+
+func TestInmemAuditService(t *testing.T) {
+	influxdbtesting.AuditService(initInmemAuditService, t)
+}
+
+func initBoltAuditService(f influxdbtesting.AuditFields, t *testing.T) (influxdb.AuditService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initAuditService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemAuditService(f influxdbtesting.AuditFields, t *testing.T) (influxdb.AuditService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initAuditService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initAuditService(s kv.Store, f influxdbtesting.AuditFields, t *testing.T) (influxdb.AuditService, string, func()) {
+	svc := kv.NewService(s)
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing audit service: %v", err)
+	}
+	for _, e := range f.Events {
+		id := e.ID
+		svc.IDGenerator = mock.IDGenerator{IDFn: func() influxdb.ID { return id }}
+		if err := svc.RecordAuditEvent(ctx, e); err != nil {
+			t.Fatalf("failed to populate test audit events: %v", err)
+		}
+	}
+	svc.IDGenerator = f.IDGenerator
+
+	return svc, kv.OpPrefix, func() {}
+}
+
+func TestService_DeleteAuditEventsBefore(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	orgID := influxdbtesting.MustIDBase16("020f755c3c082000")
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		e := &influxdb.AuditEvent{
+			Time:         start.Add(time.Duration(i) * time.Hour),
+			Action:       influxdb.AuditActionCreate,
+			ResourceType: influxdb.BucketsResourceType,
+			OrgID:        orgID,
+		}
+		if i%2 == 0 {
+			e.OrgID = 0
+		}
+		if err := svc.RecordAuditEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := svc.DeleteAuditEventsBefore(ctx, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 events to be deleted, got %d", n)
+	}
+
+	events, _, err := svc.FindAuditEvents(ctx, influxdb.AuditEventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || !events[0].Time.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("expected the 3 most recent events to be kept, got %d", len(events))
+	}
+	events, _, err = svc.FindAuditEvents(ctx, influxdb.AuditEventFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || !events[0].Time.Equal(start.Add(3*time.Hour)) {
+		t.Errorf("expected the deleted events of the organization to be unindexed, got %d events", len(events))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	influxdb "github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
 )
 
 var (
@@ -278,6 +279,11 @@ func (s *Service) createAuthorization(ctx context.Context, tx Tx, a *influxdb.Au
 
 	a.ID = s.IDGenerator.ID()
 
+	if creator, err := icontext.GetAuthorizer(ctx); err == nil {
+		// Record the creator if you can, but don't error if its not there.
+		a.CreatedBy = creator.GetUserID()
+	}
+
 	if err := s.putAuthorization(ctx, tx, a); err != nil {
 		return err
 	}
@@ -445,6 +451,10 @@ func (s *Service) UpdateAuthorization(ctx context.Context, id influxdb.ID, upd *
 }
 
 func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID, upd *influxdb.AuthorizationUpdate) (*influxdb.Authorization, error) {
+	if err := upd.Valid(); err != nil {
+		return nil, err
+	}
+
 	a, err := s.findAuthorizationByID(ctx, tx, id)
 	if err != nil {
 		return nil, err
@@ -456,6 +466,12 @@ func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	if upd.Description != nil {
 		a.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		a.ExpiresAt = upd.ExpiresAt
+	}
+	if upd.LastUsedAt != nil {
+		a.LastUsedAt = upd.LastUsedAt
+	}
 
 	v, err := encodeAuthorization(a)
 	if err != nil {
@@ -484,6 +500,53 @@ func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	return a, nil
 }
 
+// RotateAuthorization replaces the token of an authorization with a newly generated one.
+func (s *Service) RotateAuthorization(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+	var a *influxdb.Authorization
+	var err error
+	err = s.kv.Update(ctx, func(tx Tx) error {
+		a, err = s.rotateAuthorization(ctx, tx, id)
+		return err
+	})
+	return a, err
+}
+
+func (s *Service) rotateAuthorization(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Authorization, error) {
+	a, err := s.findAuthorizationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := idx.Delete(authIndexKey(a.Token)); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	token, err := s.TokenGenerator.Token()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+	a.Token = token
+
+	if err := s.uniqueAuthToken(ctx, tx, a); err != nil {
+		return nil, err
+	}
+
+	if err := s.putAuthorization(ctx, tx, a); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
 func authIndexBucket(tx Tx) (Bucket, error) {
 	b, err := tx.Bucket([]byte(authIndex))
 	if err != nil {
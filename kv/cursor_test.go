@@ -224,6 +224,42 @@ func TestStaticCursor_Seek(t *testing.T) {
 				val: []byte("yoyo"),
 			},
 		},
+		{
+			name: "seeks to the next key without the prefix",
+			args: args{
+				prefix: []byte("b"),
+				pairs: []kv.Pair{
+					{
+						Key:   []byte("abc"),
+						Value: []byte("oyoy"),
+					},
+					{
+						Key:   []byte("cde"),
+						Value: []byte("yyoo"),
+					},
+				},
+			},
+			wants: wants{
+				key: []byte("cde"),
+				val: []byte("yyoo"),
+			},
+		},
+		{
+			name: "seeks past the last key",
+			args: args{
+				prefix: []byte("d"),
+				pairs: []kv.Pair{
+					{
+						Key:   []byte("abc"),
+						Value: []byte("oyoy"),
+					},
+					{
+						Key:   []byte("cde"),
+						Value: []byte("yyoo"),
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
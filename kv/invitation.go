@@ -0,0 +1,339 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	influxdb "github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+)
+
+var (
+	invitationBucket     = []byte("invitationsv1")
+	invitationTokenIndex = []byte("invitationtokensv1")
+)
+
+func (s *Service) initializeInvitations(ctx context.Context, tx Tx) error {
+	for _, name := range [][]byte{invitationBucket, invitationTokenIndex} {
+		if _, err := tx.Bucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindInvitationByID returns a single invitation by ID.
+func (s *Service) FindInvitationByID(ctx context.Context, id influxdb.ID) (*influxdb.Invitation, error) {
+	var i *influxdb.Invitation
+	err := s.kv.View(ctx, func(tx Tx) error {
+		inv, err := s.findInvitationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		i = inv
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindInvitationByID,
+			Err: err,
+		}
+	}
+	return i, nil
+}
+
+func (s *Service) findInvitationByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Invitation, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(invitationBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrInvitationNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	i := &influxdb.Invitation{}
+	if err := json.Unmarshal(v, i); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return i, nil
+}
+
+// FindInvitationByToken returns a single invitation by its token.
+func (s *Service) FindInvitationByToken(ctx context.Context, token string) (*influxdb.Invitation, error) {
+	var i *influxdb.Invitation
+	err := s.kv.View(ctx, func(tx Tx) error {
+		inv, err := s.findInvitationByToken(ctx, tx, token)
+		if err != nil {
+			return err
+		}
+		i = inv
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindInvitationByToken,
+			Err: err,
+		}
+	}
+	return i, nil
+}
+
+func (s *Service) findInvitationByToken(ctx context.Context, tx Tx, token string) (*influxdb.Invitation, error) {
+	idx, err := tx.Bucket(invitationTokenIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := idx.Get([]byte(token))
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrInvitationNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var id influxdb.ID
+	if err := id.Decode(v); err != nil {
+		return nil, err
+	}
+	return s.findInvitationByID(ctx, tx, id)
+}
+
+// FindInvitations returns a list of invitations that match filter and the total count of matching invitations.
+func (s *Service) FindInvitations(ctx context.Context, filter influxdb.InvitationFilter) ([]*influxdb.Invitation, int, error) {
+	invitations := []*influxdb.Invitation{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		is, err := s.findInvitations(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		invitations = is
+		return nil
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindInvitations,
+			Err: err,
+		}
+	}
+	return invitations, len(invitations), nil
+}
+
+func (s *Service) findInvitations(ctx context.Context, tx Tx, filter influxdb.InvitationFilter) ([]*influxdb.Invitation, error) {
+	if filter.ID != nil {
+		i, err := s.findInvitationByID(ctx, tx, *filter.ID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return []*influxdb.Invitation{}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if !filterInvitationFn(filter)(i) {
+			return []*influxdb.Invitation{}, nil
+		}
+		return []*influxdb.Invitation{i}, nil
+	}
+
+	invitations := []*influxdb.Invitation{}
+	filterFn := filterInvitationFn(filter)
+	b, err := tx.Bucket(invitationBucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		i := &influxdb.Invitation{}
+		if err := json.Unmarshal(v, i); err != nil {
+			return nil, err
+		}
+		if filterFn(i) {
+			invitations = append(invitations, i)
+		}
+	}
+	return invitations, nil
+}
+
+func filterInvitationFn(filter influxdb.InvitationFilter) func(i *influxdb.Invitation) bool {
+	return func(i *influxdb.Invitation) bool {
+		return (filter.ID == nil || *filter.ID == i.ID) &&
+			(filter.OrgID == nil || *filter.OrgID == i.OrgID)
+	}
+}
+
+// CreateInvitation creates a new invitation, generating its token, and sets
+// i.ID with the new identifier. Invitations without an expiry expire after
+// influxdb.DefaultInvitationExpiry.
+func (s *Service) CreateInvitation(ctx context.Context, i *influxdb.Invitation) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if i.Role == "" {
+			i.Role = influxdb.Member
+		}
+		if err := i.Validate(); err != nil {
+			return err
+		}
+
+		if _, err := s.findOrganizationByID(ctx, tx, i.OrgID); err != nil {
+			return err
+		}
+
+		token, err := s.TokenGenerator.Token()
+		if err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+
+		i.ID = s.IDGenerator.ID()
+		i.Token = token
+		i.CreatedAt = s.time()
+		if i.ExpiresAt.IsZero() {
+			i.ExpiresAt = i.CreatedAt.Add(influxdb.DefaultInvitationExpiry)
+		}
+		if creator, err := icontext.GetAuthorizer(ctx); err == nil {
+			i.CreatedBy = creator.GetUserID()
+		}
+
+		return s.putInvitation(ctx, tx, i)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateInvitation,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putInvitation(ctx context.Context, tx Tx, i *influxdb.Invitation) error {
+	v, err := json.Marshal(i)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	encID, err := i.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	idx, err := tx.Bucket(invitationTokenIndex)
+	if err != nil {
+		return err
+	}
+	if err := idx.Put([]byte(i.Token), encID); err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(invitationBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encID, v)
+}
+
+// DeleteInvitation revokes an invitation by ID.
+func (s *Service) DeleteInvitation(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		i, err := s.findInvitationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		return s.deleteInvitation(ctx, tx, i)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteInvitation,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteInvitation(ctx context.Context, tx Tx, i *influxdb.Invitation) error {
+	idx, err := tx.Bucket(invitationTokenIndex)
+	if err != nil {
+		return err
+	}
+	if err := idx.Delete([]byte(i.Token)); err != nil {
+		return err
+	}
+
+	encID, err := i.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(invitationBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(encID)
+}
+
+// ConsumeInvitation removes the unexpired invitation with token and returns it.
+func (s *Service) ConsumeInvitation(ctx context.Context, token string) (*influxdb.Invitation, error) {
+	var i *influxdb.Invitation
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		inv, err := s.findInvitationByToken(ctx, tx, token)
+		if err != nil {
+			return err
+		}
+		if err := inv.Expired(); err != nil {
+			return err
+		}
+		i = inv
+		return s.deleteInvitation(ctx, tx, inv)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpConsumeInvitation,
+			Err: err,
+		}
+	}
+	return i, nil
+}
+
+// deleteOrganizationInvitations revokes the invitations to an organization
+// when it is deleted.
+func (s *Service) deleteOrganizationInvitations(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	is, err := s.findInvitations(ctx, tx, influxdb.InvitationFilter{OrgID: &orgID})
+	if err != nil {
+		return err
+	}
+	for _, i := range is {
+		if err := s.deleteInvitation(ctx, tx, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
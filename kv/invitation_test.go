@@ -0,0 +1,93 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestService_Invitation(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	inviter := &influxdb.User{Name: "inviter"}
+	if err := svc.CreateUser(ctx, inviter); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invitations to missing organizations are rejected.
+	if err := svc.CreateInvitation(ctx, &influxdb.Invitation{OrgID: org.ID + 1, Username: "invitee"}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected invitation to missing organization to be rejected, got %v", err)
+	}
+
+	i := &influxdb.Invitation{OrgID: org.ID, Username: "invitee"}
+	actx := icontext.SetAuthorizer(ctx, &influxdb.Authorization{UserID: inviter.ID, Status: influxdb.Active})
+	if err := svc.CreateInvitation(actx, i); err != nil {
+		t.Fatal(err)
+	}
+	if i.Token == "" || i.Role != influxdb.Member || i.CreatedBy != inviter.ID {
+		t.Errorf("unexpected invitation %+v", i)
+	}
+	if want := i.CreatedAt.Add(influxdb.DefaultInvitationExpiry); !i.ExpiresAt.Equal(want) {
+		t.Errorf("expected invitation to expire at %v, got %v", want, i.ExpiresAt)
+	}
+
+	found, err := svc.FindInvitationByToken(ctx, i.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != i.ID {
+		t.Errorf("expected invitation %s, got %s", i.ID, found.ID)
+	}
+
+	// Invitations can only be consumed once.
+	consumed, err := svc.ConsumeInvitation(ctx, i.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consumed.ID != i.ID {
+		t.Errorf("expected invitation %s, got %s", i.ID, consumed.ID)
+	}
+	if _, err := svc.ConsumeInvitation(ctx, i.Token); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected consumed invitation to be not found, got %v", err)
+	}
+
+	// Expired invitations can't be consumed.
+	expired := &influxdb.Invitation{OrgID: org.ID, Email: "invitee@example.com", ExpiresAt: time.Now().Add(-time.Minute)}
+	if err := svc.CreateInvitation(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ConsumeInvitation(ctx, expired.Token); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Errorf("expected expired invitation to be rejected, got %v", err)
+	}
+
+	// Deleting the organization revokes its invitations.
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	if is, _, err := svc.FindInvitations(ctx, influxdb.InvitationFilter{}); err != nil {
+		t.Fatal(err)
+	} else if len(is) != 0 {
+		t.Errorf("expected invitations of deleted organization to be revoked, got %d", len(is))
+	}
+	if _, err := svc.FindInvitationByToken(ctx, expired.Token); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected invitation token to be revoked, got %v", err)
+	}
+}
@@ -429,6 +429,9 @@ func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error
 		if err := s.deleteOrganizationsBuckets(ctx, tx, id); err != nil {
 			return err
 		}
+		if err := s.deleteOrganizationInvitations(ctx, tx, id); err != nil {
+			return err
+		}
 		if pe := s.deleteOrganization(ctx, tx, id); pe != nil {
 			return pe
 		}
@@ -10,6 +10,9 @@ import (
 )
 
 // MinPasswordLength is the shortest password we allow into the system.
+//
+// Deprecated: passwords are checked against the PasswordPolicy of the
+// Service; use influxdb.DefaultPasswordPolicy.MinLength.
 const MinPasswordLength = 8
 
 var (
@@ -22,6 +25,9 @@ var (
 
 	// EShortPassword is used when a password is less than the minimum
 	// acceptable password length.
+	//
+	// Deprecated: passwords are checked against the PasswordPolicy of the
+	// Service, whose errors list every rule a password breaks.
 	EShortPassword = &influxdb.Error{
 		Code: influxdb.EInvalid,
 		Msg:  "passwords must be at least 8 characters long",
@@ -97,8 +103,8 @@ func (s *Service) ComparePassword(ctx context.Context, name string, password str
 }
 
 func (s *Service) setPassword(ctx context.Context, tx Tx, name string, password string) error {
-	if len(password) < MinPasswordLength {
-		return EShortPassword
+	if err := s.PasswordPolicy.Validate(password); err != nil {
+		return err
 	}
 
 	u, err := s.findUserByName(ctx, tx, name)
@@ -505,3 +505,37 @@ func TestService_ComparePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestService_SetPassword_PasswordPolicy(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new inmem kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	svc.PasswordPolicy = influxdb.PasswordPolicy{
+		MinLength:     10,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing password service: %v", err)
+	}
+	if err := svc.CreateUser(ctx, &influxdb.User{Name: "user1"}); err != nil {
+		t.Fatalf("error populating users: %v", err)
+	}
+
+	err = svc.SetPassword(ctx, "user1", "howdydoody")
+	want := &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "passwords must contain a digit, contain a symbol",
+	}
+	influxdbtesting.ErrorsEqual(t, err, want)
+
+	if err := svc.SetPassword(ctx, "user1", "howdy-doody-1"); err != nil {
+		t.Errorf("expected password following the policy to be set: %v", err)
+	}
+}
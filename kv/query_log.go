@@ -0,0 +1,110 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	influxdb "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var (
+	queryLogSettingsBucket = []byte("querylogsettingsv1")
+)
+
+var _ query.LogSettingsService = (*Service)(nil)
+
+func (s *Service) initializeQueryLogSettings(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(queryLogSettingsBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindLogSettings returns the query log settings of the organization, or
+// the defaults if it has none.
+func (s *Service) FindLogSettings(ctx context.Context, orgID influxdb.ID) (*query.LogSettings, error) {
+	settings := &query.LogSettings{OrganizationID: orgID}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		encID, err := orgID.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+
+		b, err := tx.Bucket(queryLogSettingsBucket)
+		if err != nil {
+			return err
+		}
+
+		v, err := b.Get(encID)
+		if IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(v, settings)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + "FindLogSettings",
+			Err: err,
+		}
+	}
+	return settings, nil
+}
+
+// PutLogSettings replaces the query log settings of an organization.
+func (s *Service) PutLogSettings(ctx context.Context, settings *query.LogSettings) error {
+	if settings.SlowQueryThreshold < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   OpPrefix + "PutLogSettings",
+			Msg:  "slow query threshold must not be negative",
+		}
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, settings.OrganizationID); err != nil {
+			return err
+		}
+		if settings.BucketID.Valid() {
+			b, err := s.findBucketByID(ctx, tx, settings.BucketID)
+			if err != nil {
+				return err
+			}
+			if b.OrganizationID != settings.OrganizationID {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "slow queries can only be written to a bucket of the organization",
+				}
+			}
+		}
+
+		encID, err := settings.OrganizationID.Encode()
+		if err != nil {
+			return err
+		}
+
+		v, err := json.Marshal(settings)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(queryLogSettingsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(encID, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + "PutLogSettings",
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestService_LogSettings(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := svc.FindLogSettings(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (query.LogSettings{OrganizationID: org.ID}); *settings != want {
+		t.Errorf("unexpected default settings %+v, want %+v", *settings, want)
+	}
+
+	want := query.LogSettings{OrganizationID: org.ID, SlowQueryThreshold: 5 * time.Second}
+	if err := svc.PutLogSettings(ctx, &want); err != nil {
+		t.Fatal(err)
+	}
+	settings, err = svc.FindLogSettings(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *settings != want {
+		t.Errorf("unexpected settings %+v, want %+v", *settings, want)
+	}
+
+	if err := svc.PutLogSettings(ctx, &query.LogSettings{OrganizationID: org.ID, SlowQueryThreshold: -time.Second}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected invalid error for negative threshold, got %v", err)
+	}
+	if err := svc.PutLogSettings(ctx, &query.LogSettings{OrganizationID: org.ID + 1}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error for missing organization, got %v", err)
+	}
+
+	// Slow queries can only be written to a bucket of the organization.
+	other := &influxdb.Organization{Name: "other"}
+	if err := svc.CreateOrganization(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []*influxdb.Bucket{
+		{Name: "slow", OrganizationID: org.ID},
+		{Name: "slow", OrganizationID: other.ID},
+	} {
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+		err := svc.PutLogSettings(ctx, &query.LogSettings{OrganizationID: org.ID, BucketID: b.ID})
+		if b.OrganizationID == org.ID && err != nil {
+			t.Errorf("unexpected error for bucket of the organization: %v", err)
+		} else if b.OrganizationID != org.ID && influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("expected invalid error for bucket of another organization, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,611 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	influxdb "github.com/influxdata/influxdb"
+)
+
+var (
+	roleBucket        = []byte("rolesv1")
+	roleOrgNameIndex  = []byte("roleorgnamesv1")
+	roleMappingBucket = []byte("rolemappingsv1")
+	roleSubjectIndex  = []byte("rolesubjectsv1")
+)
+
+func (s *Service) initializeRoles(ctx context.Context, tx Tx) error {
+	for _, name := range [][]byte{roleBucket, roleOrgNameIndex, roleMappingBucket, roleSubjectIndex} {
+		if _, err := tx.Bucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindRoleByID returns a single role by ID.
+func (s *Service) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.kv.View(ctx, func(tx Tx) error {
+		role, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		r = role
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindRoleByID,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) findRoleByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Role, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrRoleNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r := &influxdb.Role{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+// FindRoles returns a list of roles that match filter and the total count of matching roles.
+func (s *Service) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	roles := []*influxdb.Role{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		rs, err := s.findRoles(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		roles = rs
+		return nil
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindRoles,
+			Err: err,
+		}
+	}
+	return roles, len(roles), nil
+}
+
+func (s *Service) findRoles(ctx context.Context, tx Tx, filter influxdb.RoleFilter) ([]*influxdb.Role, error) {
+	if filter.ID != nil {
+		r, err := s.findRoleByID(ctx, tx, *filter.ID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return []*influxdb.Role{}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if !filterRoleFn(filter)(r) {
+			return []*influxdb.Role{}, nil
+		}
+		return []*influxdb.Role{r}, nil
+	}
+
+	if filter.OrgID != nil {
+		return s.findOrganizationRoles(ctx, tx, *filter.OrgID, filter.Name)
+	}
+
+	roles := []*influxdb.Role{}
+	filterFn := filterRoleFn(filter)
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		r := &influxdb.Role{}
+		if err := json.Unmarshal(v, r); err != nil {
+			return nil, err
+		}
+		if filterFn(r) {
+			roles = append(roles, r)
+		}
+	}
+	return roles, nil
+}
+
+func filterRoleFn(filter influxdb.RoleFilter) func(r *influxdb.Role) bool {
+	return func(r *influxdb.Role) bool {
+		return (filter.ID == nil || *filter.ID == r.ID) &&
+			(filter.OrgID == nil || *filter.OrgID == r.OrgID) &&
+			(filter.Name == nil || *filter.Name == r.Name)
+	}
+}
+
+// findOrganizationRoles returns the roles of an organization, or only the role
+// with name if it is not nil.
+func (s *Service) findOrganizationRoles(ctx context.Context, tx Tx, orgID influxdb.ID, name *string) ([]*influxdb.Role, error) {
+	prefix, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	if name != nil {
+		prefix = append(prefix, *name...)
+	}
+
+	idx, err := tx.Bucket(roleOrgNameIndex)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := idx.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []*influxdb.Role{}
+	for k, v := cur.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		if name != nil && len(k) != len(prefix) {
+			continue
+		}
+
+		var id influxdb.ID
+		if err := id.Decode(v); err != nil {
+			return nil, err
+		}
+		r, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	return roles, nil
+}
+
+func roleOrgNameKey(orgID influxdb.ID, name string) ([]byte, error) {
+	k, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(k, name...), nil
+}
+
+// CreateRole creates a new role and sets r.ID with the new identifier.
+func (s *Service) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+		if err := s.uniqueRoleName(ctx, tx, r); err != nil {
+			return err
+		}
+
+		r.ID = s.IDGenerator.ID()
+		return s.putRole(ctx, tx, r)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateRole,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) uniqueRoleName(ctx context.Context, tx Tx, r *influxdb.Role) error {
+	key, err := roleOrgNameKey(r.OrgID, r.Name)
+	if err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(roleOrgNameIndex)
+	if err != nil {
+		return err
+	}
+
+	if _, err := idx.Get(key); IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  fmt.Sprintf("role with name %s already exists", r.Name),
+	}
+}
+
+// PutRole will put a role without setting an ID.
+func (s *Service) PutRole(ctx context.Context, r *influxdb.Role) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.putRole(ctx, tx, r)
+	})
+}
+
+func (s *Service) putRole(ctx context.Context, tx Tx, r *influxdb.Role) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	encID, err := r.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	key, err := roleOrgNameKey(r.OrgID, r.Name)
+	if err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(roleOrgNameIndex)
+	if err != nil {
+		return err
+	}
+	if err := idx.Put(key, encID); err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encID, v)
+}
+
+// UpdateRole updates a single role with changeset.
+func (s *Service) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		role, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if upd.Name != nil && *upd.Name != role.Name {
+			if err := s.deleteRoleOrgNameIndex(ctx, tx, role); err != nil {
+				return err
+			}
+			role.Name = *upd.Name
+			if err := s.uniqueRoleName(ctx, tx, role); err != nil {
+				return err
+			}
+		}
+
+		if err := upd.Apply(role); err != nil {
+			return err
+		}
+
+		r = role
+		return s.putRole(ctx, tx, role)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpUpdateRole,
+			Err: err,
+		}
+	}
+	return r, nil
+}
+
+func (s *Service) deleteRoleOrgNameIndex(ctx context.Context, tx Tx, r *influxdb.Role) error {
+	key, err := roleOrgNameKey(r.OrgID, r.Name)
+	if err != nil {
+		return err
+	}
+
+	idx, err := tx.Bucket(roleOrgNameIndex)
+	if err != nil {
+		return err
+	}
+	return idx.Delete(key)
+}
+
+// DeleteRole removes a role by ID, along with all of its mappings.
+func (s *Service) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		r, err := s.findRoleByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		ms, err := s.findRoleMappings(ctx, tx, influxdb.RoleMappingFilter{RoleID: &id})
+		if err != nil {
+			return err
+		}
+		for _, m := range ms {
+			if err := s.deleteRoleMapping(ctx, tx, m.RoleID, m.SubjectID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.deleteRoleOrgNameIndex(ctx, tx, r); err != nil {
+			return err
+		}
+
+		encID, err := id.Encode()
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+		b, err := tx.Bucket(roleBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(encID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteRole,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// roleMappingKey returns the key of a mapping in the mapping bucket, or of its
+// entry in the subject index if the IDs are swapped.
+func roleMappingKey(a, b influxdb.ID) ([]byte, error) {
+	encA, err := a.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	encB, err := b.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(encA, encB...), nil
+}
+
+// FindRoleMappings returns a list of role mappings that match filter and the total count of matching mappings.
+func (s *Service) FindRoleMappings(ctx context.Context, filter influxdb.RoleMappingFilter) ([]*influxdb.RoleMapping, int, error) {
+	ms := []*influxdb.RoleMapping{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		mappings, err := s.findRoleMappings(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		ms = mappings
+		return nil
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindRoleMappings,
+			Err: err,
+		}
+	}
+	return ms, len(ms), nil
+}
+
+func (s *Service) findRoleMappings(ctx context.Context, tx Tx, filter influxdb.RoleMappingFilter) ([]*influxdb.RoleMapping, error) {
+	// Mappings are found by role in the mapping bucket and by subject in the
+	// subject index, which both hold the full mapping.
+	bucket := roleMappingBucket
+	var prefix []byte
+	switch {
+	case filter.RoleID != nil:
+		enc, err := filter.RoleID.Encode()
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+		prefix = enc
+	case filter.SubjectID != nil:
+		enc, err := filter.SubjectID.Encode()
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  err,
+			}
+		}
+		bucket, prefix = roleSubjectIndex, enc
+	}
+
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	ms := []*influxdb.RoleMapping{}
+	k, v := cur.First()
+	if prefix != nil {
+		k, v = cur.Seek(prefix)
+	}
+	for ; k != nil && bytes.HasPrefix(k, prefix); k, v = cur.Next() {
+		m := &influxdb.RoleMapping{}
+		if err := json.Unmarshal(v, m); err != nil {
+			return nil, err
+		}
+		if (filter.RoleID == nil || *filter.RoleID == m.RoleID) &&
+			(filter.SubjectID == nil || *filter.SubjectID == m.SubjectID) &&
+			(filter.SubjectType == "" || filter.SubjectType == m.SubjectType) {
+			ms = append(ms, m)
+		}
+	}
+	return ms, nil
+}
+
+// CreateRoleMapping assigns a role to a subject.
+func (s *Service) CreateRoleMapping(ctx context.Context, m *influxdb.RoleMapping) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := m.Validate(); err != nil {
+			return err
+		}
+		r, err := s.findRoleByID(ctx, tx, m.RoleID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkRoleSubject(ctx, tx, r, m); err != nil {
+			return err
+		}
+
+		key, err := roleMappingKey(m.RoleID, m.SubjectID)
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(roleMappingBucket)
+		if err != nil {
+			return err
+		}
+		if _, err := b.Get(key); err == nil {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  fmt.Sprintf("role is already assigned to %s", m.SubjectID),
+			}
+		} else if !IsNotFound(err) {
+			return err
+		}
+
+		v, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, v); err != nil {
+			return err
+		}
+
+		idxKey, err := roleMappingKey(m.SubjectID, m.RoleID)
+		if err != nil {
+			return err
+		}
+		idx, err := tx.Bucket(roleSubjectIndex)
+		if err != nil {
+			return err
+		}
+		return idx.Put(idxKey, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateRoleMapping,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// checkRoleSubject checks that the subject of m belongs to the organization
+// of the role r: users must be members or owners of it, and authorizations
+// must be of it.
+func (s *Service) checkRoleSubject(ctx context.Context, tx Tx, r *influxdb.Role, m *influxdb.RoleMapping) error {
+	switch m.SubjectType {
+	case influxdb.UsersResourceType:
+		_, err := s.findUserResourceMapping(ctx, tx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.OrgsResourceType,
+			ResourceID:   r.OrgID,
+			UserID:       m.SubjectID,
+		})
+		if err == ErrURMNotFound {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("user %s is not a member of the organization of the role", m.SubjectID),
+			}
+		}
+		return err
+	case influxdb.AuthorizationsResourceType:
+		a, err := s.findAuthorizationByID(ctx, tx, m.SubjectID)
+		if err != nil {
+			return err
+		}
+		if a.OrgID != r.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("authorization %s is not of the organization of the role", m.SubjectID),
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteRoleMapping removes a role from a subject.
+func (s *Service) DeleteRoleMapping(ctx context.Context, roleID, subjectID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.deleteRoleMapping(ctx, tx, roleID, subjectID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteRoleMapping,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteRoleMapping(ctx context.Context, tx Tx, roleID, subjectID influxdb.ID) error {
+	key, err := roleMappingKey(roleID, subjectID)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(roleMappingBucket)
+	if err != nil {
+		return err
+	}
+	if _, err := b.Get(key); IsNotFound(err) {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrRoleMappingNotFound,
+		}
+	} else if err != nil {
+		return err
+	}
+	if err := b.Delete(key); err != nil {
+		return err
+	}
+
+	idxKey, err := roleMappingKey(subjectID, roleID)
+	if err != nil {
+		return err
+	}
+	idx, err := tx.Bucket(roleSubjectIndex)
+	if err != nil {
+		return err
+	}
+	return idx.Delete(idxKey)
+}
@@ -0,0 +1,82 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltRoleService(t *testing.T) {
+	influxdbtesting.RoleService(initBoltRoleService, t)
+}
+
+func TestInmemRoleService(t *testing.T) {
+	influxdbtesting.RoleService(initInmemRoleService, t)
+}
+
+func initBoltRoleService(f influxdbtesting.RoleFields, t *testing.T) (influxdb.RoleService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initRoleService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemRoleService(f influxdbtesting.RoleFields, t *testing.T) (influxdb.RoleService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initRoleService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initRoleService(s kv.Store, f influxdbtesting.RoleFields, t *testing.T) (influxdb.RoleService, string, func()) {
+	svc := kv.NewService(s)
+	svc.IDGenerator = f.IDGenerator
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing role service: %v", err)
+	}
+	for _, m := range f.UserResourceMappings {
+		if err := svc.CreateUserResourceMapping(ctx, m); err != nil {
+			t.Fatalf("failed to populate test user resource mappings: %v", err)
+		}
+	}
+	for _, a := range f.Authorizations {
+		if err := svc.PutAuthorization(ctx, a); err != nil {
+			t.Fatalf("failed to populate test authorizations: %v", err)
+		}
+	}
+	for _, r := range f.Roles {
+		if err := svc.PutRole(ctx, r); err != nil {
+			t.Fatalf("failed to populate test roles: %v", err)
+		}
+	}
+	for _, m := range f.RoleMappings {
+		if err := svc.CreateRoleMapping(ctx, m); err != nil {
+			t.Fatalf("failed to populate test role mappings: %v", err)
+		}
+	}
+
+	done := func() {
+		for _, r := range f.Roles {
+			_ = svc.DeleteRole(ctx, r.ID)
+		}
+	}
+
+	return svc, kv.OpPrefix, done
+}
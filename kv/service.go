@@ -27,6 +27,9 @@ type Service struct {
 	TokenGenerator influxdb.TokenGenerator
 	Hash           Crypt
 
+	// PasswordPolicy is the set of rules passwords must follow to be set.
+	PasswordPolicy influxdb.PasswordPolicy
+
 	time func() time.Time
 }
 
@@ -37,6 +40,7 @@ func NewService(kv Store) *Service {
 		IDGenerator:    snowflake.NewIDGenerator(),
 		TokenGenerator: rand.NewTokenGenerator(64),
 		Hash:           &Bcrypt{},
+		PasswordPolicy: influxdb.DefaultPasswordPolicy,
 		kv:             kv,
 		time:           time.Now,
 	}
@@ -109,10 +113,34 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeRoles(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeAudit(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeShareLinks(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeInvitations(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeQueryLogSettings(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeVariables(ctx, tx); err != nil {
 			return err
 		}
 
+		if err := s.initializeUserIdentities(ctx, tx); err != nil {
+			return err
+		}
+
 		return s.initializeUsers(ctx, tx)
 	})
 }
@@ -0,0 +1,431 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	influxdb "github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+)
+
+var (
+	shareLinkBucket   = []byte("sharelinksv1")
+	shareLinkKeyIndex = []byte("sharelinkkeysv1")
+)
+
+func (s *Service) initializeShareLinks(ctx context.Context, tx Tx) error {
+	for _, name := range [][]byte{shareLinkBucket, shareLinkKeyIndex} {
+		if _, err := tx.Bucket(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindShareLinkByID returns a single share link by ID.
+func (s *Service) FindShareLinkByID(ctx context.Context, id influxdb.ID) (*influxdb.ShareLink, error) {
+	var l *influxdb.ShareLink
+	err := s.kv.View(ctx, func(tx Tx) error {
+		link, err := s.findShareLinkByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		l = link
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindShareLinkByID,
+			Err: err,
+		}
+	}
+	return l, nil
+}
+
+func (s *Service) findShareLinkByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.ShareLink, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(shareLinkBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(encID)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrShareLinkNotFound,
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := &influxdb.ShareLink{}
+	if err := json.Unmarshal(v, l); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return l, nil
+}
+
+// FindShareLinkByKey returns a single share link by its key.
+func (s *Service) FindShareLinkByKey(ctx context.Context, key string) (*influxdb.ShareLink, error) {
+	var l *influxdb.ShareLink
+	err := s.kv.View(ctx, func(tx Tx) error {
+		idx, err := tx.Bucket(shareLinkKeyIndex)
+		if err != nil {
+			return err
+		}
+
+		v, err := idx.Get([]byte(key))
+		if IsNotFound(err) {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrShareLinkNotFound,
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		var id influxdb.ID
+		if err := id.Decode(v); err != nil {
+			return err
+		}
+		link, err := s.findShareLinkByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		l = link
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindShareLinkByKey,
+			Err: err,
+		}
+	}
+	return l, nil
+}
+
+// FindShareLinks returns a list of share links that match filter and the total count of matching share links.
+func (s *Service) FindShareLinks(ctx context.Context, filter influxdb.ShareLinkFilter) ([]*influxdb.ShareLink, int, error) {
+	links := []*influxdb.ShareLink{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		ls, err := s.findShareLinks(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		links = ls
+		return nil
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindShareLinks,
+			Err: err,
+		}
+	}
+	return links, len(links), nil
+}
+
+func (s *Service) findShareLinks(ctx context.Context, tx Tx, filter influxdb.ShareLinkFilter) ([]*influxdb.ShareLink, error) {
+	if filter.ID != nil {
+		l, err := s.findShareLinkByID(ctx, tx, *filter.ID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return []*influxdb.ShareLink{}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if !filterShareLinkFn(filter)(l) {
+			return []*influxdb.ShareLink{}, nil
+		}
+		return []*influxdb.ShareLink{l}, nil
+	}
+
+	links := []*influxdb.ShareLink{}
+	filterFn := filterShareLinkFn(filter)
+	b, err := tx.Bucket(shareLinkBucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+	for k, v := cur.First(); k != nil; k, v = cur.Next() {
+		l := &influxdb.ShareLink{}
+		if err := json.Unmarshal(v, l); err != nil {
+			return nil, err
+		}
+		if filterFn(l) {
+			links = append(links, l)
+		}
+	}
+	return links, nil
+}
+
+func filterShareLinkFn(filter influxdb.ShareLinkFilter) func(l *influxdb.ShareLink) bool {
+	return func(l *influxdb.ShareLink) bool {
+		return (filter.ID == nil || *filter.ID == l.ID) &&
+			(filter.OrgID == nil || *filter.OrgID == l.OrgID) &&
+			(filter.DashboardID == nil || *filter.DashboardID == l.DashboardID)
+	}
+}
+
+// CreateShareLink creates a new share link of l.DashboardID and sets l.ID
+// with the new identifier. The authorization of the link belongs to the
+// user on the context, and is only granted the buckets and variables that
+// user can read, so that sharing a dashboard never exposes more than its
+// creator can see.
+func (s *Service) CreateShareLink(ctx context.Context, l *influxdb.ShareLink) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.createShareLink(ctx, tx, l)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateShareLink,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) createShareLink(ctx context.Context, tx Tx, l *influxdb.ShareLink) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	d, err := s.findDashboardByID(ctx, tx, l.DashboardID)
+	if err != nil {
+		return err
+	}
+	l.OrgID = d.OrganizationID
+
+	allowed := func(influxdb.Permission) bool { return true }
+	if a, err := icontext.GetAuthorizer(ctx); err == nil {
+		l.CreatedBy = a.GetUserID()
+		allowed = a.Allowed
+	}
+	if !l.CreatedBy.Valid() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "share link requires a creator",
+		}
+	}
+
+	ps, err := s.shareLinkPermissions(ctx, tx, d, allowed)
+	if err != nil {
+		return err
+	}
+
+	a := &influxdb.Authorization{
+		OrgID:       l.OrgID,
+		UserID:      l.CreatedBy,
+		Status:      influxdb.Active,
+		Description: fmt.Sprintf("share link of dashboard %s", d.Name),
+		Permissions: ps,
+		ExpiresAt:   l.ExpiresAt,
+	}
+	if err := s.createAuthorization(ctx, tx, a); err != nil {
+		return err
+	}
+
+	key, err := s.TokenGenerator.Token()
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	l.ID = s.IDGenerator.ID()
+	l.AuthorizationID = a.ID
+	l.Key = key
+	l.Permissions = ps
+	l.CreatedAt = s.time()
+	return s.putShareLink(ctx, tx, l)
+}
+
+// shareLinkPermissions returns the permissions to read dashboard d, the
+// buckets its cells query and the variables they use. Bucket and variable
+// permissions are only granted if allowed. Buckets and variables that don't
+// exist are ignored.
+func (s *Service) shareLinkPermissions(ctx context.Context, tx Tx, d *influxdb.Dashboard, allowed func(influxdb.Permission) bool) ([]influxdb.Permission, error) {
+	p, err := influxdb.NewPermissionAtID(d.ID, influxdb.ReadAction, influxdb.DashboardsResourceType, d.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	ps := []influxdb.Permission{*p}
+
+	granted := map[influxdb.ID]bool{}
+	grant := func(id influxdb.ID, rt influxdb.ResourceType) error {
+		if granted[id] {
+			return nil
+		}
+		granted[id] = true
+
+		p, err := influxdb.NewPermissionAtID(id, influxdb.ReadAction, rt, d.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if allowed(*p) {
+			ps = append(ps, *p)
+		}
+		return nil
+	}
+
+	variables := map[string]bool{}
+	for _, c := range d.Cells {
+		v, err := s.findDashboardCellView(ctx, tx, d.ID, c.ID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, q := range influxdb.ViewQueries(v.Properties) {
+			buckets, names := q.References()
+			for _, f := range buckets {
+				var b *influxdb.Bucket
+				if f.ID != nil {
+					b, err = s.findBucketByID(ctx, tx, *f.ID)
+				} else {
+					b, err = s.findBucketByName(ctx, tx, d.OrganizationID, *f.Name)
+				}
+				if influxdb.ErrorCode(err) == influxdb.ENotFound {
+					continue
+				} else if err != nil {
+					return nil, err
+				}
+				if b.OrganizationID != d.OrganizationID {
+					continue
+				}
+				if err := grant(b.ID, influxdb.BucketsResourceType); err != nil {
+					return nil, err
+				}
+			}
+			for _, name := range names {
+				variables[name] = true
+			}
+		}
+	}
+
+	if len(variables) == 0 {
+		return ps, nil
+	}
+
+	vs, err := s.findVariables(ctx, tx, influxdb.VariableFilter{OrganizationID: &d.OrganizationID})
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vs {
+		if !variables[v.Name] {
+			continue
+		}
+		if err := grant(v.ID, influxdb.VariablesResourceType); err != nil {
+			return nil, err
+		}
+	}
+	return ps, nil
+}
+
+func (s *Service) putShareLink(ctx context.Context, tx Tx, l *influxdb.ShareLink) error {
+	v, err := json.Marshal(l)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	encID, err := l.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	idx, err := tx.Bucket(shareLinkKeyIndex)
+	if err != nil {
+		return err
+	}
+	if err := idx.Put([]byte(l.Key), encID); err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(shareLinkBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(encID, v)
+}
+
+// DeleteShareLink removes a share link by ID and revokes its authorization.
+func (s *Service) DeleteShareLink(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		l, err := s.findShareLinkByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		return s.deleteShareLink(ctx, tx, l)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteShareLink,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteShareLink(ctx context.Context, tx Tx, l *influxdb.ShareLink) error {
+	// The authorization may already have been deleted on its own.
+	if err := s.deleteAuthorization(ctx, tx, l.AuthorizationID); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+
+	idx, err := tx.Bucket(shareLinkKeyIndex)
+	if err != nil {
+		return err
+	}
+	if err := idx.Delete([]byte(l.Key)); err != nil {
+		return err
+	}
+
+	encID, err := l.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(shareLinkBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(encID)
+}
+
+// deleteDashboardShareLinks removes the share links of a dashboard when it is
+// deleted, so that their tokens no longer grant access to its buckets.
+func (s *Service) deleteDashboardShareLinks(ctx context.Context, tx Tx, dashboardID influxdb.ID) error {
+	links, err := s.findShareLinks(ctx, tx, influxdb.ShareLinkFilter{DashboardID: &dashboardID})
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		if err := s.deleteShareLink(ctx, tx, l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kv"
+)
+
+func TestService_ShareLink(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+
+	svc := kv.NewService(s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	user := &influxdb.User{Name: "sharer"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	public := &influxdb.Bucket{OrganizationID: org.ID, Name: "public"}
+	private := &influxdb.Bucket{OrganizationID: org.ID, Name: "private"}
+	unused := &influxdb.Bucket{OrganizationID: org.ID, Name: "unused"}
+	for _, b := range []*influxdb.Bucket{public, private, unused} {
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	host := &influxdb.Variable{
+		OrganizationID: org.ID,
+		Name:           "host",
+		Arguments:      &influxdb.VariableArguments{Type: "constant", Values: influxdb.VariableConstantValues{"a"}},
+	}
+	if err := svc.CreateVariable(ctx, host); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &influxdb.Dashboard{OrganizationID: org.ID, Name: "status"}
+	if err := svc.CreateDashboard(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	view := &influxdb.View{
+		Properties: influxdb.XYViewProperties{
+			Type: "xy",
+			Queries: []influxdb.DashboardQuery{
+				{Text: `from(bucket: "public") |> range(start: v.timeRangeStart) |> filter(fn: (r) => r.host == v.host)`},
+				{Text: `from(bucket: "private") |> range(start: -1h)`},
+				{Text: `from(bucket: "missing") |> range(start: -1h)`},
+			},
+		},
+	}
+	if err := svc.AddDashboardCell(ctx, d.ID, &influxdb.Cell{}, influxdb.AddDashboardCellOptions{View: view}); err != nil {
+		t.Fatal(err)
+	}
+
+	readPermission := func(rt influxdb.ResourceType, id influxdb.ID) influxdb.Permission {
+		p, err := influxdb.NewPermissionAtID(id, influxdb.ReadAction, rt, org.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *p
+	}
+
+	// The creator can read the public bucket and every variable, but not the
+	// private bucket.
+	creator := &influxdb.Authorization{
+		UserID: user.ID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			readPermission(influxdb.DashboardsResourceType, d.ID),
+			readPermission(influxdb.BucketsResourceType, public.ID),
+			{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.VariablesResourceType, OrgID: &org.ID}},
+		},
+	}
+	expiresAt := time.Now().Add(time.Hour).UTC()
+	l := &influxdb.ShareLink{DashboardID: d.ID, ExpiresAt: &expiresAt}
+	if err := svc.CreateShareLink(icontext.SetAuthorizer(ctx, creator), l); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []influxdb.Permission{
+		readPermission(influxdb.DashboardsResourceType, d.ID),
+		readPermission(influxdb.BucketsResourceType, public.ID),
+		readPermission(influxdb.VariablesResourceType, host.ID),
+	}
+	if diff := cmp.Diff(l.Permissions, want); diff != "" {
+		t.Errorf("share link permissions are different -got/+want\ndiff %s", diff)
+	}
+	if l.OrgID != org.ID || l.CreatedBy != user.ID || l.Key == "" {
+		t.Errorf("unexpected share link %+v", l)
+	}
+
+	a, err := svc.FindAuthorizationByID(ctx, l.AuthorizationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(a.Permissions, want); diff != "" {
+		t.Errorf("authorization permissions are different -got/+want\ndiff %s", diff)
+	}
+	if a.ExpiresAt == nil || !a.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("expected authorization to expire with the share link, got %v", a.ExpiresAt)
+	}
+
+	found, err := svc.FindShareLinkByKey(ctx, l.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != l.ID {
+		t.Errorf("expected share link %s, got %s", l.ID, found.ID)
+	}
+
+	// Deleting the share link revokes its authorization.
+	if err := svc.DeleteShareLink(ctx, l.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindShareLinkByKey(ctx, l.Key); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected share link to be deleted, got %v", err)
+	}
+	if _, err := svc.FindAuthorizationByID(ctx, l.AuthorizationID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected authorization to be revoked, got %v", err)
+	}
+
+	// Deleting the dashboard deletes its share links.
+	l = &influxdb.ShareLink{DashboardID: d.ID}
+	if err := svc.CreateShareLink(icontext.SetAuthorizer(ctx, creator), l); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.DeleteDashboard(ctx, d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if links, _, err := svc.FindShareLinks(ctx, influxdb.ShareLinkFilter{}); err != nil {
+		t.Fatal(err)
+	} else if len(links) != 0 {
+		t.Errorf("expected share links of deleted dashboard to be deleted, got %d", len(links))
+	}
+	if _, err := svc.FindAuthorizationByID(ctx, l.AuthorizationID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected authorization to be revoked, got %v", err)
+	}
+}
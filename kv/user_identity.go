@@ -0,0 +1,126 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	influxdb "github.com/influxdata/influxdb"
+)
+
+var userIdentityBucket = []byte("useridentitiesv1")
+
+var _ influxdb.UserIdentityService = (*Service)(nil)
+
+func (s *Service) initializeUserIdentities(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(userIdentityBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// userIdentityKey is the length of the issuer followed by the issuer and the
+// subject, so that no two identities have the same key.
+func userIdentityKey(issuer, subject string) []byte {
+	return []byte(strconv.Itoa(len(issuer)) + ":" + issuer + subject)
+}
+
+// FindUserIdentity returns the binding of the identity of issuer and subject.
+func (s *Service) FindUserIdentity(ctx context.Context, issuer, subject string) (*influxdb.UserIdentity, error) {
+	var i *influxdb.UserIdentity
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(userIdentityBucket)
+		if err != nil {
+			return err
+		}
+
+		v, err := b.Get(userIdentityKey(issuer, subject))
+		if IsNotFound(err) {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrUserIdentityNotFound,
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		i = &influxdb.UserIdentity{}
+		return json.Unmarshal(v, i)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpFindUserIdentity,
+			Err: err,
+		}
+	}
+	return i, nil
+}
+
+// CreateUserIdentity binds a user to an identity. It is a conflict if the
+// identity is bound already.
+func (s *Service) CreateUserIdentity(ctx context.Context, i *influxdb.UserIdentity) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if err := i.Validate(); err != nil {
+			return err
+		}
+		if _, err := s.findUserByID(ctx, tx, i.UserID); err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(userIdentityBucket)
+		if err != nil {
+			return err
+		}
+		key := userIdentityKey(i.Issuer, i.Subject)
+		if _, err := b.Get(key); err == nil {
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "identity is already bound to a user",
+			}
+		} else if !IsNotFound(err) {
+			return err
+		}
+
+		v, err := json.Marshal(i)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpCreateUserIdentity,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteUserIdentity removes the binding of the identity of issuer and subject.
+func (s *Service) DeleteUserIdentity(ctx context.Context, issuer, subject string) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(userIdentityBucket)
+		if err != nil {
+			return err
+		}
+
+		key := userIdentityKey(issuer, subject)
+		if _, err := b.Get(key); IsNotFound(err) {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrUserIdentityNotFound,
+			}
+		} else if err != nil {
+			return err
+		}
+		return b.Delete(key)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  OpPrefix + influxdb.OpDeleteUserIdentity,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestBoltUserIdentityService(t *testing.T) {
+	influxdbtesting.UserIdentityService(initBoltUserIdentityService, t)
+}
+
+func TestInmemUserIdentityService(t *testing.T) {
+	influxdbtesting.UserIdentityService(initInmemUserIdentityService, t)
+}
+
+func initBoltUserIdentityService(f influxdbtesting.UserIdentityFields, t *testing.T) (influxdb.UserIdentityService, string, func()) {
+	s, closeBolt, err := NewTestBoltStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initUserIdentityService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initInmemUserIdentityService(f influxdbtesting.UserIdentityFields, t *testing.T) (influxdb.UserIdentityService, string, func()) {
+	s, closeBolt, err := NewTestInmemStore()
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, op, closeSvc := initUserIdentityService(s, f, t)
+	return svc, op, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initUserIdentityService(s kv.Store, f influxdbtesting.UserIdentityFields, t *testing.T) (influxdb.UserIdentityService, string, func()) {
+	svc := kv.NewService(s)
+
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing user identity service: %v", err)
+	}
+	for _, u := range f.Users {
+		if err := svc.PutUser(ctx, u); err != nil {
+			t.Fatalf("failed to populate users: %v", err)
+		}
+	}
+	for _, i := range f.Identities {
+		if err := svc.CreateUserIdentity(ctx, i); err != nil {
+			t.Fatalf("failed to populate user identities: %v", err)
+		}
+	}
+
+	return svc, kv.OpPrefix, func() {
+		for _, i := range f.Identities {
+			_ = svc.DeleteUserIdentity(ctx, i.Issuer, i.Subject)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Package localsecret implements influxdb.SecretService by encrypting secret
+// values with AES-GCM before they are stored in another SecretService,
+// typically the bolt store.
+//
+// Values are sealed with the current master key. Values sealed with a
+// previous key can still be opened as long as that key is configured, and
+// Rotate re-encrypts all of them with the current key.
+package localsecret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// KeySize is the size in bytes of a master key, selecting AES-256.
+const KeySize = 32
+
+// prefix marks values sealed by this package. It is followed by the id of
+// the key the value was sealed with and the base64 encoded nonce and
+// ciphertext, separated by "$".
+const prefix = "$aesgcm$"
+
+// Key is a master key used to seal secret values.
+type Key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewKey returns a key from KeySize random bytes.
+func NewKey(b []byte) (*Key, error) {
+	if len(b) != KeySize {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("secret master key must be %d bytes long, got %d", KeySize, len(b)),
+		}
+	}
+
+	block, err := aes.NewCipher(b)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(b)
+	return &Key{
+		id:   hex.EncodeToString(sum[:8]),
+		aead: aead,
+	}, nil
+}
+
+// ParseKey returns a key from its base64 encoding.
+func ParseKey(s string) (*Key, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "secret master key must be base64 encoded",
+			Err:  err,
+		}
+	}
+	return NewKey(b)
+}
+
+// ReadKeyFile returns the key stored in the file at path, either as raw
+// bytes or base64 encoded.
+func ReadKeyFile(path string) (*Key, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == KeySize {
+		return NewKey(b)
+	}
+	return ParseKey(string(b))
+}
+
+// GenerateKey returns the base64 encoding of a new random master key.
+func GenerateKey() (string, error) {
+	b := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// ID identifies the key without revealing it.
+func (k *Key) ID() string {
+	return k.id
+}
+
+// additionalData binds a sealed value to the organization and secret key it
+// is stored under, so that it can't be moved to another one.
+func additionalData(orgID influxdb.ID, k string) []byte {
+	return []byte(orgID.String() + "/" + k)
+}
+
+func (k *Key) seal(orgID influxdb.ID, key, v string) (string, error) {
+	nonce := make([]byte, k.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	ct := k.aead.Seal(nonce, nonce, []byte(v), additionalData(orgID, key))
+	return prefix + k.id + "$" + base64.StdEncoding.EncodeToString(ct), nil
+}
+
+func (k *Key) open(orgID influxdb.ID, key string, ct []byte) (string, error) {
+	n := k.aead.NonceSize()
+	if len(ct) < n {
+		return "", errCorrupt(key)
+	}
+	v, err := k.aead.Open(nil, ct[:n], ct[n:], additionalData(orgID, key))
+	if err != nil {
+		return "", errCorrupt(key)
+	}
+	return string(v), nil
+}
+
+func errCorrupt(key string) error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Msg:  fmt.Sprintf("secret %q could not be decrypted", key),
+	}
+}
+
+var _ influxdb.SecretService = (*SecretService)(nil)
+
+// SecretService encrypts the secrets stored in another SecretService.
+type SecretService struct {
+	s influxdb.SecretService
+
+	key      *Key
+	previous map[string]*Key
+}
+
+// NewSecretService returns a SecretService storing secrets in s sealed with
+// key. Secrets sealed with one of the previous keys can still be loaded.
+func NewSecretService(s influxdb.SecretService, key *Key, previous ...*Key) *SecretService {
+	svc := &SecretService{
+		s:        s,
+		key:      key,
+		previous: make(map[string]*Key, len(previous)),
+	}
+	for _, k := range previous {
+		svc.previous[k.id] = k
+	}
+	return svc
+}
+
+// decrypt opens a stored value. It reports whether the value is sealed with
+// the current key.
+func (s *SecretService) decrypt(orgID influxdb.ID, key, v string) (string, bool, error) {
+	if !strings.HasPrefix(v, prefix) {
+		return "", false, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  fmt.Sprintf("secret %q is not encrypted; restart influxd to encrypt existing secrets", key),
+		}
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(v, prefix), "$", 2)
+	if len(parts) != 2 {
+		return "", false, errCorrupt(key)
+	}
+	ct, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", false, errCorrupt(key)
+	}
+
+	k, current := s.key, true
+	if parts[0] != s.key.id {
+		k, current = s.previous[parts[0]], false
+	}
+	if k == nil {
+		return "", false, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  fmt.Sprintf("secret %q is encrypted with unknown master key %s", key, parts[0]),
+		}
+	}
+
+	pt, err := k.open(orgID, key, ct)
+	return pt, current, err
+}
+
+func (s *SecretService) encrypt(orgID influxdb.ID, m map[string]string) (map[string]string, error) {
+	sealed := make(map[string]string, len(m))
+	for k, v := range m {
+		ct, err := s.key.seal(orgID, k, v)
+		if err != nil {
+			return nil, err
+		}
+		sealed[k] = ct
+	}
+	return sealed, nil
+}
+
+// LoadSecret retrieves the secret value v found at key k for organization orgID.
+func (s *SecretService) LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+	v, err := s.s.LoadSecret(ctx, orgID, k)
+	if err != nil {
+		return "", err
+	}
+	pt, _, err := s.decrypt(orgID, k, v)
+	return pt, err
+}
+
+// GetSecretKeys retrieves all secret keys that are stored for the organization orgID.
+func (s *SecretService) GetSecretKeys(ctx context.Context, orgID influxdb.ID) ([]string, error) {
+	return s.s.GetSecretKeys(ctx, orgID)
+}
+
+// PutSecret stores the secret pair (k,v) for the organization orgID.
+func (s *SecretService) PutSecret(ctx context.Context, orgID influxdb.ID, k string, v string) error {
+	ct, err := s.key.seal(orgID, k, v)
+	if err != nil {
+		return err
+	}
+	return s.s.PutSecret(ctx, orgID, k, ct)
+}
+
+// PutSecrets puts all provided secrets and overwrites any previous values.
+func (s *SecretService) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	sealed, err := s.encrypt(orgID, m)
+	if err != nil {
+		return err
+	}
+	return s.s.PutSecrets(ctx, orgID, sealed)
+}
+
+// PatchSecrets patches all provided secrets and updates any previous values.
+func (s *SecretService) PatchSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	sealed, err := s.encrypt(orgID, m)
+	if err != nil {
+		return err
+	}
+	return s.s.PatchSecrets(ctx, orgID, sealed)
+}
+
+// DeleteSecret removes a single secret from the secret store.
+func (s *SecretService) DeleteSecret(ctx context.Context, orgID influxdb.ID, ks ...string) error {
+	return s.s.DeleteSecret(ctx, orgID, ks...)
+}
+
+// Rotate re-encrypts with the current key the secrets of all organizations
+// that are sealed with a previous key or not encrypted at all, such as those
+// stored before switching to this service. It returns the number of secrets
+// re-encrypted. Once it succeeds, the previous keys are no longer needed.
+func (s *SecretService) Rotate(ctx context.Context, orgs influxdb.OrganizationService) (int, error) {
+	os, _, err := orgs.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, o := range os {
+		keys, err := s.s.GetSecretKeys(ctx, o.ID)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			}
+			return n, err
+		}
+
+		stale := make(map[string]string)
+		for _, k := range keys {
+			v, err := s.s.LoadSecret(ctx, o.ID, k)
+			if err != nil {
+				return n, err
+			}
+
+			if !strings.HasPrefix(v, prefix) {
+				stale[k] = v
+				continue
+			}
+			pt, current, err := s.decrypt(o.ID, k, v)
+			if err != nil {
+				return n, err
+			}
+			if !current {
+				stale[k] = pt
+			}
+		}
+		if len(stale) == 0 {
+			continue
+		}
+
+		if err := s.PatchSecrets(ctx, o.ID, stale); err != nil {
+			return n, err
+		}
+		n += len(stale)
+	}
+	return n, nil
+}
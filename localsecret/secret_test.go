@@ -0,0 +1,158 @@
+package localsecret_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/localsecret"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func newKey(t *testing.T) *localsecret.Key {
+	t.Helper()
+	s, err := localsecret.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := localsecret.ParseKey(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newStore(t *testing.T) *kv.Service {
+	t.Helper()
+	s := kv.NewService(inmem.NewKVStore())
+	if err := s.Initialize(context.Background()); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+	return s
+}
+
+func initSecretService(f influxdbtesting.SecretServiceFields, t *testing.T) (influxdb.SecretService, func()) {
+	svc := localsecret.NewSecretService(newStore(t), newKey(t))
+	ctx := context.Background()
+	for _, s := range f.Secrets {
+		for k, v := range s.Env {
+			if err := svc.PutSecret(ctx, s.OrganizationID, k, v); err != nil {
+				t.Fatalf("failed to populate secrets: %v", err)
+			}
+		}
+	}
+	return svc, func() {}
+}
+
+func TestSecretService(t *testing.T) {
+	influxdbtesting.SecretService(initSecretService, t)
+}
+
+func TestSecretService_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+	svc := localsecret.NewSecretService(store, newKey(t))
+	orgID := influxdbtesting.MustIDBase16("020f755c3c082000")
+
+	if err := svc.PutSecret(ctx, orgID, "token", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := store.LoadSecret(ctx, orgID, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(v, "hunter2") {
+		t.Fatalf("expected secret to be stored encrypted, got %q", v)
+	}
+
+	// A value moved to another key must not decrypt.
+	if err := store.PutSecret(ctx, orgID, "other", v); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.LoadSecret(ctx, orgID, "other"); err == nil {
+		t.Error("expected secret moved to another key to fail to decrypt")
+	}
+
+	// Another master key can't decrypt it either.
+	other := localsecret.NewSecretService(store, newKey(t))
+	if _, err := other.LoadSecret(ctx, orgID, "token"); err == nil {
+		t.Error("expected secret to fail to decrypt with another master key")
+	}
+}
+
+func TestSecretService_Rotate(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+	orgs := []*influxdb.Organization{
+		{Name: "org1"},
+		{Name: "org2"},
+		{Name: "org3"},
+	}
+	for _, o := range orgs {
+		if err := store.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldKey, newKey := newKey(t), newKey(t)
+	old := localsecret.NewSecretService(store, oldKey)
+	if err := old.PutSecrets(ctx, orgs[0].ID, map[string]string{"a": "1", "b": "2"}); err != nil {
+		t.Fatal(err)
+	}
+	// Secrets stored before encryption was enabled are encrypted as well.
+	if err := store.PutSecret(ctx, orgs[2].ID, "c", "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := localsecret.NewSecretService(store, newKey, oldKey)
+	if v, err := svc.LoadSecret(ctx, orgs[0].ID, "a"); err != nil || v != "1" {
+		t.Fatalf("expected secret sealed with previous key to load, got %q, %v", v, err)
+	}
+	if _, err := svc.LoadSecret(ctx, orgs[2].ID, "c"); err == nil {
+		t.Fatal("expected plaintext secret to fail to load before rotation")
+	}
+
+	n, err := svc.Rotate(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("expected 3 secrets to be re-encrypted, got %d", n)
+	}
+
+	rotated := localsecret.NewSecretService(store, newKey)
+	for _, s := range []struct {
+		orgID influxdb.ID
+		k, v  string
+	}{
+		{orgs[0].ID, "a", "1"},
+		{orgs[0].ID, "b", "2"},
+		{orgs[2].ID, "c", "3"},
+	} {
+		v, err := rotated.LoadSecret(ctx, s.orgID, s.k)
+		if err != nil {
+			t.Errorf("expected %s to load without the previous key: %v", s.k, err)
+			continue
+		}
+		if v != s.v {
+			t.Errorf("expected %s to be %q, got %q", s.k, s.v, v)
+		}
+	}
+
+	if n, err := rotated.Rotate(ctx, store); err != nil || n != 0 {
+		t.Errorf("expected nothing left to rotate, got %d, %v", n, err)
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	if _, err := localsecret.NewKey([]byte("short")); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected short key to be invalid, got %v", err)
+	}
+	if _, err := localsecret.ParseKey("not base64!"); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Errorf("expected malformed key to be invalid, got %v", err)
+	}
+}
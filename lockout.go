@@ -0,0 +1,34 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrSigninLocked is the error msg for sign ins refused during a lockout.
+// It is the same whether the user or the address is locked out, so as not
+// to reveal which users exist.
+const ErrSigninLocked = "too many failed sign in attempts; try again later"
+
+// ops for lockout errors.
+const (
+	OpFindUserLockout = "FindUserLockout"
+	OpUnlockUser      = "UnlockUser"
+)
+
+// UserLockout is the record of the failed sign in attempts of a user.
+type UserLockout struct {
+	Name           string     `json:"name"`
+	FailedAttempts int        `json:"failedAttempts"`
+	LockedUntil    *time.Time `json:"lockedUntil,omitempty"`
+}
+
+// LockoutService manages the lockout of users after repeated failed sign ins.
+type LockoutService interface {
+	// FindUserLockout returns the failed sign in attempts of the user with name.
+	FindUserLockout(ctx context.Context, name string) (*UserLockout, error)
+
+	// UnlockUser forgets the failed sign in attempts of the user with name,
+	// allowing them to sign in again immediately.
+	UnlockUser(ctx context.Context, name string) error
+}
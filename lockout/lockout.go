@@ -0,0 +1,269 @@
+// Package lockout protects password sign in from brute-force attacks.
+//
+// A Tracker counts the failed sign in attempts of every user and of every
+// address they come from. Once either reaches its threshold, further
+// attempts are refused for a delay that doubles with every further failure.
+// Failures are forgotten after a quiet window, after a successful sign in
+// of the user, or when an operator unlocks the user.
+//
+// The counters are kept in memory, so they are per process and are lost on
+// restart. At most MaxEntries users and as many addresses are tracked; the
+// counters that failed least recently are forgotten first.
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+// Config configures the thresholds and delays of a Tracker.
+type Config struct {
+	// UserThreshold is the number of failed attempts of a user before it is
+	// locked out.
+	UserThreshold int
+	// AddrThreshold is the number of failed attempts from an address before
+	// it is locked out. It is higher than the user threshold since many
+	// users may share an address.
+	AddrThreshold int
+	// Delay is the first lockout, doubled by every further failure.
+	Delay time.Duration
+	// MaxDelay caps the lockout.
+	MaxDelay time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+	// MaxEntries is the number of users, and of addresses, that are tracked.
+	// Zero means no limit.
+	MaxEntries int
+}
+
+// DefaultConfig is the default configuration of a Tracker.
+var DefaultConfig = Config{
+	UserThreshold: 5,
+	AddrThreshold: 20,
+	Delay:         time.Second,
+	MaxDelay:      15 * time.Minute,
+	Window:        time.Hour,
+	MaxEntries:    100000,
+}
+
+type counter struct {
+	failures    int
+	pending     int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+var _ influxdb.LockoutService = (*Tracker)(nil)
+
+// Tracker counts failed sign in attempts by user and by address.
+type Tracker struct {
+	config Config
+
+	mu        sync.Mutex
+	users     map[string]*counter
+	addrs     map[string]*counter
+	lastSweep time.Time
+
+	now func() time.Time
+}
+
+// NewTracker returns a Tracker with the configuration.
+func NewTracker(c Config) *Tracker {
+	return &Tracker{
+		config: c,
+		users:  make(map[string]*counter),
+		addrs:  make(map[string]*counter),
+		now:    time.Now,
+	}
+}
+
+// WithTime sets the function for computing the current time.
+// Should only be used in tests for mocking.
+func (t *Tracker) WithTime(fn func() time.Time) {
+	t.now = fn
+}
+
+// Check returns an error with the time left to wait if sign ins of the user
+// with name or from addr are locked out. Otherwise it reserves an attempt,
+// which must be followed by Fail or Succeed. Attempts are reserved so that
+// concurrent sign ins can't make more guesses than the threshold allows:
+// once the failed and pending attempts reach it, only one attempt at a time
+// is allowed.
+func (t *Tracker) Check(name, addr string) (time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+	user := t.counter(t.users, name, now)
+	a := t.counter(t.addrs, addr, now)
+
+	wait := t.locked(user, now)
+	if w := t.locked(a, now); w > wait {
+		wait = w
+	}
+	if wait == 0 && (busy(user, t.config.UserThreshold) || busy(a, t.config.AddrThreshold)) {
+		wait = t.config.Delay
+	}
+	if wait > 0 {
+		return wait, &influxdb.Error{
+			Code: influxdb.ETooManyRequests,
+			Msg:  influxdb.ErrSigninLocked,
+		}
+	}
+
+	user.pending++
+	a.pending++
+	return 0, nil
+}
+
+func (t *Tracker) locked(c *counter, now time.Time) time.Duration {
+	if !now.Before(c.lockedUntil) {
+		return 0
+	}
+	return c.lockedUntil.Sub(now)
+}
+
+// busy returns true if another attempt is pending and the attempts would
+// reach the threshold.
+func busy(c *counter, threshold int) bool {
+	return threshold > 0 && c.pending > 0 && c.failures+c.pending >= threshold
+}
+
+// counter returns the counter of key, forgetting its failures if they are
+// outside of the window. If there is no counter and the limit of entries is
+// reached, the least recently failed counter is forgotten to make room.
+func (t *Tracker) counter(counters map[string]*counter, key string, now time.Time) *counter {
+	c, ok := counters[key]
+	if !ok {
+		if t.config.MaxEntries > 0 && len(counters) >= t.config.MaxEntries {
+			t.evict(counters, now)
+		}
+		c = &counter{last: now}
+		counters[key] = c
+	}
+	if now.Sub(c.last) > t.config.Window {
+		c.failures = 0
+		c.lockedUntil = time.Time{}
+	}
+	return c
+}
+
+// evict forgets the counters outside of the window or, if there are none,
+// the least recently failed counter.
+func (t *Tracker) evict(counters map[string]*counter, now time.Time) {
+	var oldest string
+	var found bool
+	for k, c := range counters {
+		if now.Sub(c.last) > t.config.Window {
+			delete(counters, k)
+			continue
+		}
+		if !found || c.last.Before(counters[oldest].last) {
+			oldest, found = k, true
+		}
+	}
+	if found && len(counters) >= t.config.MaxEntries {
+		delete(counters, oldest)
+	}
+}
+
+// Fail records a failed sign in of the user with name from addr.
+func (t *Tracker) Fail(name, addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.fail(t.users, name, t.config.UserThreshold, now)
+	t.fail(t.addrs, addr, t.config.AddrThreshold, now)
+}
+
+func (t *Tracker) fail(counters map[string]*counter, key string, threshold int, now time.Time) {
+	c := t.counter(counters, key, now)
+	release(c)
+	c.failures++
+	c.last = now
+
+	if threshold <= 0 || c.failures < threshold {
+		return
+	}
+	c.lockedUntil = now.Add(t.delay(c.failures - threshold))
+}
+
+func release(c *counter) {
+	if c.pending > 0 {
+		c.pending--
+	}
+}
+
+// delay returns the lockout after n failures beyond the threshold.
+func (t *Tracker) delay(n int) time.Duration {
+	d := t.config.Delay
+	for i := 0; i < n && d < t.config.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > t.config.MaxDelay {
+		d = t.config.MaxDelay
+	}
+	return d
+}
+
+// sweep forgets the counters outside of the window, at most once per window.
+func (t *Tracker) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.config.Window {
+		return
+	}
+	t.lastSweep = now
+	for _, counters := range []map[string]*counter{t.users, t.addrs} {
+		for k, c := range counters {
+			if now.Sub(c.last) > t.config.Window {
+				delete(counters, k)
+			}
+		}
+	}
+}
+
+// Succeed records a successful sign in of the user with name from addr,
+// forgetting the failures of the user. The failures of the address are
+// kept, so that signing in to one account does not allow guessing the
+// passwords of others.
+func (t *Tracker) Succeed(name, addr string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.users, name)
+	if c, ok := t.addrs[addr]; ok {
+		release(c)
+	}
+}
+
+// FindUserLockout returns the failed sign in attempts of the user with name.
+func (t *Tracker) FindUserLockout(ctx context.Context, name string) (*influxdb.UserLockout, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	l := &influxdb.UserLockout{Name: name}
+	c, ok := t.users[name]
+	if !ok || t.now().Sub(c.last) > t.config.Window {
+		return l, nil
+	}
+
+	l.FailedAttempts = c.failures
+	if t.locked(c, t.now()) > 0 {
+		until := c.lockedUntil
+		l.LockedUntil = &until
+	}
+	return l, nil
+}
+
+// UnlockUser forgets the failed sign in attempts of the user with name.
+func (t *Tracker) UnlockUser(ctx context.Context, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.users, name)
+	return nil
+}
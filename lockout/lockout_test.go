@@ -0,0 +1,209 @@
+package lockout_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/lockout"
+)
+
+var testConfig = lockout.Config{
+	UserThreshold: 3,
+	AddrThreshold: 5,
+	Delay:         time.Second,
+	MaxDelay:      10 * time.Second,
+	Window:        time.Hour,
+}
+
+func newTracker(now *time.Time) *lockout.Tracker {
+	t := lockout.NewTracker(testConfig)
+	t.WithTime(func() time.Time { return *now })
+	return t
+}
+
+func TestTracker_UserLockout(t *testing.T) {
+	now := time.Unix(0, 0)
+	tr := newTracker(&now)
+
+	for i := 0; i < 2; i++ {
+		tr.Fail("user1", "10.0.0.1")
+	}
+	if _, err := tr.Check("user1", "10.0.0.2"); err != nil {
+		t.Fatalf("expected user not to be locked out below the threshold: %v", err)
+	}
+
+	// The lockout doubles with every failure beyond the threshold and is
+	// capped at the max delay.
+	for i, want := range []time.Duration{1, 2, 4, 8, 10, 10} {
+		tr.Fail("user1", "10.0.0.2")
+		wait, err := tr.Check("user1", "10.0.0.3")
+		if influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+			t.Fatalf("failure %d: expected user to be locked out, got %v", i+3, err)
+		}
+		if wait != want*time.Second {
+			t.Errorf("failure %d: expected lockout of %s, got %s", i+3, want*time.Second, wait)
+		}
+	}
+
+	now = now.Add(10 * time.Second)
+	if _, err := tr.Check("user1", "10.0.0.3"); err != nil {
+		t.Errorf("expected lockout to end: %v", err)
+	}
+	if _, err := tr.Check("user2", "10.0.0.3"); err != nil {
+		t.Errorf("expected other users not to be locked out: %v", err)
+	}
+}
+
+func TestTracker_AddrLockout(t *testing.T) {
+	now := time.Unix(0, 0)
+	tr := newTracker(&now)
+
+	users := []string{"a", "b", "c", "d", "e"}
+	for _, u := range users {
+		tr.Fail(u, "10.0.0.1")
+	}
+	if _, err := tr.Check("f", "10.0.0.1"); influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+		t.Errorf("expected address to be locked out, got %v", err)
+	}
+	if _, err := tr.Check("f", "10.0.0.2"); err != nil {
+		t.Errorf("expected other addresses not to be locked out: %v", err)
+	}
+
+	// Signing in to one account does not lift the lockout of the address.
+	tr.Succeed("a", "10.0.0.1")
+	if _, err := tr.Check("a", "10.0.0.1"); influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+		t.Errorf("expected address to stay locked out, got %v", err)
+	}
+}
+
+func TestTracker_AddrLockoutOnly(t *testing.T) {
+	config := testConfig
+	config.UserThreshold = 0
+	tr := lockout.NewTracker(config)
+
+	for i := 0; i < 4; i++ {
+		tr.Fail("user1", "10.0.0.1")
+	}
+	if _, err := tr.Check("user1", "10.0.0.2"); err != nil {
+		t.Errorf("expected users not to be locked out: %v", err)
+	}
+
+	tr.Fail("user1", "10.0.0.1")
+	if _, err := tr.Check("user2", "10.0.0.1"); influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+		t.Errorf("expected address to be locked out, got %v", err)
+	}
+}
+
+func TestTracker_Window(t *testing.T) {
+	now := time.Unix(0, 0)
+	tr := newTracker(&now)
+
+	tr.Fail("user1", "10.0.0.1")
+	tr.Fail("user1", "10.0.0.1")
+	now = now.Add(2 * time.Hour)
+	tr.Fail("user1", "10.0.0.1")
+
+	l, err := tr.FindUserLockout(context.Background(), "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.FailedAttempts != 1 || l.LockedUntil != nil {
+		t.Errorf("expected failures outside of the window to be forgotten, got %+v", l)
+	}
+}
+
+func TestTracker_UnlockUser(t *testing.T) {
+	now := time.Unix(0, 0)
+	tr := newTracker(&now)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		tr.Fail("user1", "10.0.0.1")
+	}
+
+	l, err := tr.FindUserLockout(ctx, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.FailedAttempts != 3 || l.LockedUntil == nil || !l.LockedUntil.Equal(now.Add(time.Second)) {
+		t.Errorf("unexpected lockout %+v", l)
+	}
+
+	if err := tr.UnlockUser(ctx, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Check("user1", "10.0.0.2"); err != nil {
+		t.Errorf("expected user to be unlocked: %v", err)
+	}
+	if l, _ := tr.FindUserLockout(ctx, "user1"); l.FailedAttempts != 0 {
+		t.Errorf("expected failures to be forgotten, got %d", l.FailedAttempts)
+	}
+}
+
+func TestTracker_ConcurrentAttempts(t *testing.T) {
+	now := time.Unix(0, 0)
+	tr := newTracker(&now)
+
+	// Attempts in flight count towards the threshold, so that concurrent
+	// sign ins can't all be checked before any of them fail.
+	for i := 0; i < 2; i++ {
+		if _, err := tr.Check("user1", "10.0.0.1"); err != nil {
+			t.Fatalf("attempt %d: expected sign in to be allowed: %v", i+1, err)
+		}
+	}
+	if wait, err := tr.Check("user1", "10.0.0.1"); err != nil {
+		t.Fatalf("expected the attempt reaching the threshold to be allowed: %v", err)
+	} else if wait != 0 {
+		t.Fatalf("expected no wait, got %s", wait)
+	}
+	if _, err := tr.Check("user1", "10.0.0.2"); influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+		t.Fatalf("expected attempt beyond the threshold to be refused, got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		tr.Fail("user1", "10.0.0.1")
+	}
+	if _, err := tr.Check("user1", "10.0.0.2"); influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+		t.Fatalf("expected user to be locked out, got %v", err)
+	}
+
+	// Once the lockout ends, one attempt at a time is allowed.
+	now = now.Add(time.Second)
+	if _, err := tr.Check("user1", "10.0.0.2"); err != nil {
+		t.Fatalf("expected lockout to end: %v", err)
+	}
+	if _, err := tr.Check("user1", "10.0.0.3"); influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+		t.Fatalf("expected a second attempt to be refused, got %v", err)
+	}
+	tr.Succeed("user1", "10.0.0.2")
+	if _, err := tr.Check("user1", "10.0.0.3"); err != nil {
+		t.Errorf("expected user to sign in again after a success: %v", err)
+	}
+}
+
+func TestTracker_MaxEntries(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := testConfig
+	c.MaxEntries = 2
+	tr := lockout.NewTracker(c)
+	tr.WithTime(func() time.Time { return now })
+	ctx := context.Background()
+
+	for _, u := range []string{"a", "b", "c"} {
+		tr.Fail(u, "10.0.0.1")
+		now = now.Add(time.Second)
+	}
+
+	// The least recently failed user is forgotten to make room.
+	for u, want := range map[string]int{"a": 0, "b": 1, "c": 1} {
+		l, err := tr.FindUserLockout(ctx, u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.FailedAttempts != want {
+			t.Errorf("user %s: got %d failed attempts, want %d", u, l.FailedAttempts, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.AuditService = &AuditService{}
+
+// AuditService is a mock implementation of platform.AuditService.
+type AuditService struct {
+	RecordAuditEventF func(context.Context, *platform.AuditEvent) error
+	FindAuditEventsF  func(context.Context, platform.AuditEventFilter, ...platform.FindOptions) ([]*platform.AuditEvent, int, error)
+}
+
+// NewAuditService returns a mock of AuditService where its methods will return zero values.
+func NewAuditService() *AuditService {
+	return &AuditService{
+		RecordAuditEventF: func(context.Context, *platform.AuditEvent) error { return nil },
+		FindAuditEventsF: func(context.Context, platform.AuditEventFilter, ...platform.FindOptions) ([]*platform.AuditEvent, int, error) {
+			return nil, 0, nil
+		},
+	}
+}
+
+func (s *AuditService) RecordAuditEvent(ctx context.Context, e *platform.AuditEvent) error {
+	return s.RecordAuditEventF(ctx, e)
+}
+
+func (s *AuditService) FindAuditEvents(ctx context.Context, filter platform.AuditEventFilter, opts ...platform.FindOptions) ([]*platform.AuditEvent, int, error) {
+	return s.FindAuditEventsF(ctx, filter, opts...)
+}
@@ -22,6 +22,7 @@ type AuthorizationService struct {
 	CreateAuthorizationFn      func(context.Context, *platform.Authorization) error
 	DeleteAuthorizationFn      func(context.Context, platform.ID) error
 	UpdateAuthorizationFn      func(context.Context, platform.ID, *platform.AuthorizationUpdate) (*platform.Authorization, error)
+	RotateAuthorizationFn      func(context.Context, platform.ID) (*platform.Authorization, error)
 }
 
 // NewAuthorizationService returns a mock AuthorizationService where its methods will return
@@ -38,6 +39,7 @@ func NewAuthorizationService() *AuthorizationService {
 		UpdateAuthorizationFn: func(context.Context, platform.ID, *platform.AuthorizationUpdate) (*platform.Authorization, error) {
 			return nil, nil
 		},
+		RotateAuthorizationFn: func(context.Context, platform.ID) (*platform.Authorization, error) { return nil, nil },
 	}
 }
 
@@ -69,3 +71,8 @@ func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id platf
 func (s *AuthorizationService) UpdateAuthorization(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
 	return s.UpdateAuthorizationFn(ctx, id, upd)
 }
+
+// RotateAuthorization replaces the token of an authorization.
+func (s *AuthorizationService) RotateAuthorization(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+	return s.RotateAuthorizationFn(ctx, id)
+}
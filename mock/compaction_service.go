@@ -0,0 +1,60 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+var _ storage.CompactionService = (*CompactionService)(nil)
+
+// CompactionService is a mock implementation of storage.CompactionService.
+type CompactionService struct {
+	CompactionStatusFn       func(context.Context) (*tsm1.CompactionStatus, error)
+	PauseCompactionsFn       func(context.Context) error
+	ResumeCompactionsFn      func(context.Context) error
+	ScheduleFullCompactionFn func(context.Context) error
+	UpdateCompactionsFn      func(context.Context, storage.CompactionUpdate) (*tsm1.CompactionStatus, error)
+}
+
+// NewCompactionService returns a mock CompactionService where its methods
+// will return zero values.
+func NewCompactionService() *CompactionService {
+	return &CompactionService{
+		CompactionStatusFn: func(context.Context) (*tsm1.CompactionStatus, error) {
+			return &tsm1.CompactionStatus{}, nil
+		},
+		PauseCompactionsFn:       func(context.Context) error { return nil },
+		ResumeCompactionsFn:      func(context.Context) error { return nil },
+		ScheduleFullCompactionFn: func(context.Context) error { return nil },
+		UpdateCompactionsFn: func(context.Context, storage.CompactionUpdate) (*tsm1.CompactionStatus, error) {
+			return &tsm1.CompactionStatus{}, nil
+		},
+	}
+}
+
+// CompactionStatus returns the running and planned compactions.
+func (s *CompactionService) CompactionStatus(ctx context.Context) (*tsm1.CompactionStatus, error) {
+	return s.CompactionStatusFn(ctx)
+}
+
+// PauseCompactions pauses compactions.
+func (s *CompactionService) PauseCompactions(ctx context.Context) error {
+	return s.PauseCompactionsFn(ctx)
+}
+
+// ResumeCompactions resumes compactions.
+func (s *CompactionService) ResumeCompactions(ctx context.Context) error {
+	return s.ResumeCompactionsFn(ctx)
+}
+
+// ScheduleFullCompaction schedules a full compaction.
+func (s *CompactionService) ScheduleFullCompaction(ctx context.Context) error {
+	return s.ScheduleFullCompactionFn(ctx)
+}
+
+// UpdateCompactions updates the compaction settings.
+func (s *CompactionService) UpdateCompactions(ctx context.Context, upd storage.CompactionUpdate) (*tsm1.CompactionStatus, error) {
+	return s.UpdateCompactionsFn(ctx, upd)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/storage"
+)
+
+var _ storage.ImportService = (*ImportService)(nil)
+
+// ImportService is a mock implementation of storage.ImportService.
+type ImportService struct {
+	ImportFn func(context.Context, influxdb.ID, influxdb.ID, io.Reader) (int, error)
+}
+
+// NewImportService returns a mock ImportService where its methods will return
+// zero values.
+func NewImportService() *ImportService {
+	return &ImportService{
+		ImportFn: func(context.Context, influxdb.ID, influxdb.ID, io.Reader) (int, error) { return 0, nil },
+	}
+}
+
+// Import imports the line protocol read from r into the bucket.
+func (s *ImportService) Import(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) (int, error) {
+	return s.ImportFn(ctx, orgID, bucketID, r)
+}
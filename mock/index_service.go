@@ -0,0 +1,27 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/storage"
+)
+
+var _ storage.IndexService = (*IndexService)(nil)
+
+// IndexService is a mock implementation of storage.IndexService.
+type IndexService struct {
+	RebuildIndexFn func(context.Context, []int) error
+}
+
+// NewIndexService returns a mock IndexService where its methods will return
+// zero values.
+func NewIndexService() *IndexService {
+	return &IndexService{
+		RebuildIndexFn: func(context.Context, []int) error { return nil },
+	}
+}
+
+// RebuildIndex rebuilds the provided index partitions.
+func (s *IndexService) RebuildIndex(ctx context.Context, partitions []int) error {
+	return s.RebuildIndexFn(ctx, partitions)
+}
@@ -0,0 +1,63 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.InvitationService = &InvitationService{}
+
+// InvitationService is a mock implementation of platform.InvitationService.
+type InvitationService struct {
+	FindInvitationByIDF    func(context.Context, platform.ID) (*platform.Invitation, error)
+	FindInvitationByTokenF func(context.Context, string) (*platform.Invitation, error)
+	FindInvitationsF       func(context.Context, platform.InvitationFilter) ([]*platform.Invitation, int, error)
+	CreateInvitationF      func(context.Context, *platform.Invitation) error
+	DeleteInvitationF      func(context.Context, platform.ID) error
+	ConsumeInvitationF     func(context.Context, string) (*platform.Invitation, error)
+}
+
+// NewInvitationService returns a mock of InvitationService where its methods will return zero values.
+func NewInvitationService() *InvitationService {
+	return &InvitationService{
+		FindInvitationByIDF:    func(context.Context, platform.ID) (*platform.Invitation, error) { return nil, nil },
+		FindInvitationByTokenF: func(context.Context, string) (*platform.Invitation, error) { return nil, nil },
+		FindInvitationsF: func(context.Context, platform.InvitationFilter) ([]*platform.Invitation, int, error) {
+			return nil, 0, nil
+		},
+		CreateInvitationF:  func(context.Context, *platform.Invitation) error { return nil },
+		DeleteInvitationF:  func(context.Context, platform.ID) error { return nil },
+		ConsumeInvitationF: func(context.Context, string) (*platform.Invitation, error) { return nil, nil },
+	}
+}
+
+// FindInvitationByID returns a single invitation by ID.
+func (s *InvitationService) FindInvitationByID(ctx context.Context, id platform.ID) (*platform.Invitation, error) {
+	return s.FindInvitationByIDF(ctx, id)
+}
+
+// FindInvitationByToken returns a single invitation by its token.
+func (s *InvitationService) FindInvitationByToken(ctx context.Context, token string) (*platform.Invitation, error) {
+	return s.FindInvitationByTokenF(ctx, token)
+}
+
+// FindInvitations returns a list of invitations that match filter and the total count of matching invitations.
+func (s *InvitationService) FindInvitations(ctx context.Context, filter platform.InvitationFilter) ([]*platform.Invitation, int, error) {
+	return s.FindInvitationsF(ctx, filter)
+}
+
+// CreateInvitation creates a new invitation.
+func (s *InvitationService) CreateInvitation(ctx context.Context, i *platform.Invitation) error {
+	return s.CreateInvitationF(ctx, i)
+}
+
+// DeleteInvitation revokes an invitation by ID.
+func (s *InvitationService) DeleteInvitation(ctx context.Context, id platform.ID) error {
+	return s.DeleteInvitationF(ctx, id)
+}
+
+// ConsumeInvitation removes the invitation with token and returns it.
+func (s *InvitationService) ConsumeInvitation(ctx context.Context, token string) (*platform.Invitation, error) {
+	return s.ConsumeInvitationF(ctx, token)
+}
@@ -0,0 +1,31 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.LockoutService = &LockoutService{}
+
+// LockoutService is a mock implementation of platform.LockoutService.
+type LockoutService struct {
+	FindUserLockoutF func(context.Context, string) (*platform.UserLockout, error)
+	UnlockUserF      func(context.Context, string) error
+}
+
+// NewLockoutService returns a mock of LockoutService where its methods will return zero values.
+func NewLockoutService() *LockoutService {
+	return &LockoutService{
+		FindUserLockoutF: func(context.Context, string) (*platform.UserLockout, error) { return nil, nil },
+		UnlockUserF:      func(context.Context, string) error { return nil },
+	}
+}
+
+func (s *LockoutService) FindUserLockout(ctx context.Context, name string) (*platform.UserLockout, error) {
+	return s.FindUserLockoutF(ctx, name)
+}
+
+func (s *LockoutService) UnlockUser(ctx context.Context, name string) error {
+	return s.UnlockUserF(ctx, name)
+}
@@ -0,0 +1,71 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.RoleService = &RoleService{}
+
+// RoleService is a mock implementation of platform.RoleService.
+type RoleService struct {
+	FindRoleByIDF      func(context.Context, platform.ID) (*platform.Role, error)
+	FindRolesF         func(context.Context, platform.RoleFilter, ...platform.FindOptions) ([]*platform.Role, int, error)
+	CreateRoleF        func(context.Context, *platform.Role) error
+	UpdateRoleF        func(context.Context, platform.ID, platform.RoleUpdate) (*platform.Role, error)
+	DeleteRoleF        func(context.Context, platform.ID) error
+	FindRoleMappingsF  func(context.Context, platform.RoleMappingFilter) ([]*platform.RoleMapping, int, error)
+	CreateRoleMappingF func(context.Context, *platform.RoleMapping) error
+	DeleteRoleMappingF func(ctx context.Context, roleID, subjectID platform.ID) error
+}
+
+// NewRoleService returns a mock of RoleService where its methods will return zero values.
+func NewRoleService() *RoleService {
+	return &RoleService{
+		FindRoleByIDF: func(context.Context, platform.ID) (*platform.Role, error) { return nil, nil },
+		FindRolesF: func(context.Context, platform.RoleFilter, ...platform.FindOptions) ([]*platform.Role, int, error) {
+			return nil, 0, nil
+		},
+		CreateRoleF: func(context.Context, *platform.Role) error { return nil },
+		UpdateRoleF: func(context.Context, platform.ID, platform.RoleUpdate) (*platform.Role, error) { return nil, nil },
+		DeleteRoleF: func(context.Context, platform.ID) error { return nil },
+		FindRoleMappingsF: func(context.Context, platform.RoleMappingFilter) ([]*platform.RoleMapping, int, error) {
+			return nil, 0, nil
+		},
+		CreateRoleMappingF: func(context.Context, *platform.RoleMapping) error { return nil },
+		DeleteRoleMappingF: func(ctx context.Context, roleID, subjectID platform.ID) error { return nil },
+	}
+}
+
+func (s *RoleService) FindRoleByID(ctx context.Context, id platform.ID) (*platform.Role, error) {
+	return s.FindRoleByIDF(ctx, id)
+}
+
+func (s *RoleService) FindRoles(ctx context.Context, filter platform.RoleFilter, opts ...platform.FindOptions) ([]*platform.Role, int, error) {
+	return s.FindRolesF(ctx, filter, opts...)
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, r *platform.Role) error {
+	return s.CreateRoleF(ctx, r)
+}
+
+func (s *RoleService) UpdateRole(ctx context.Context, id platform.ID, upd platform.RoleUpdate) (*platform.Role, error) {
+	return s.UpdateRoleF(ctx, id, upd)
+}
+
+func (s *RoleService) DeleteRole(ctx context.Context, id platform.ID) error {
+	return s.DeleteRoleF(ctx, id)
+}
+
+func (s *RoleService) FindRoleMappings(ctx context.Context, filter platform.RoleMappingFilter) ([]*platform.RoleMapping, int, error) {
+	return s.FindRoleMappingsF(ctx, filter)
+}
+
+func (s *RoleService) CreateRoleMapping(ctx context.Context, m *platform.RoleMapping) error {
+	return s.CreateRoleMappingF(ctx, m)
+}
+
+func (s *RoleService) DeleteRoleMapping(ctx context.Context, roleID, subjectID platform.ID) error {
+	return s.DeleteRoleMappingF(ctx, roleID, subjectID)
+}
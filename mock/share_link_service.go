@@ -0,0 +1,56 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.ShareLinkService = &ShareLinkService{}
+
+// ShareLinkService is a mock implementation of platform.ShareLinkService.
+type ShareLinkService struct {
+	FindShareLinkByIDF  func(context.Context, platform.ID) (*platform.ShareLink, error)
+	FindShareLinkByKeyF func(context.Context, string) (*platform.ShareLink, error)
+	FindShareLinksF     func(context.Context, platform.ShareLinkFilter) ([]*platform.ShareLink, int, error)
+	CreateShareLinkF    func(context.Context, *platform.ShareLink) error
+	DeleteShareLinkF    func(context.Context, platform.ID) error
+}
+
+// NewShareLinkService returns a mock of ShareLinkService where its methods will return zero values.
+func NewShareLinkService() *ShareLinkService {
+	return &ShareLinkService{
+		FindShareLinkByIDF:  func(context.Context, platform.ID) (*platform.ShareLink, error) { return nil, nil },
+		FindShareLinkByKeyF: func(context.Context, string) (*platform.ShareLink, error) { return nil, nil },
+		FindShareLinksF: func(context.Context, platform.ShareLinkFilter) ([]*platform.ShareLink, int, error) {
+			return nil, 0, nil
+		},
+		CreateShareLinkF: func(context.Context, *platform.ShareLink) error { return nil },
+		DeleteShareLinkF: func(context.Context, platform.ID) error { return nil },
+	}
+}
+
+// FindShareLinkByID returns a single share link by ID.
+func (s *ShareLinkService) FindShareLinkByID(ctx context.Context, id platform.ID) (*platform.ShareLink, error) {
+	return s.FindShareLinkByIDF(ctx, id)
+}
+
+// FindShareLinkByKey returns a single share link by its key.
+func (s *ShareLinkService) FindShareLinkByKey(ctx context.Context, key string) (*platform.ShareLink, error) {
+	return s.FindShareLinkByKeyF(ctx, key)
+}
+
+// FindShareLinks returns a list of share links that match filter and the total count of matching share links.
+func (s *ShareLinkService) FindShareLinks(ctx context.Context, filter platform.ShareLinkFilter) ([]*platform.ShareLink, int, error) {
+	return s.FindShareLinksF(ctx, filter)
+}
+
+// CreateShareLink creates a new share link.
+func (s *ShareLinkService) CreateShareLink(ctx context.Context, l *platform.ShareLink) error {
+	return s.CreateShareLinkF(ctx, l)
+}
+
+// DeleteShareLink removes a share link by ID.
+func (s *ShareLinkService) DeleteShareLink(ctx context.Context, id platform.ID) error {
+	return s.DeleteShareLinkF(ctx, id)
+}
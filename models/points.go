@@ -1165,6 +1165,30 @@ func scanLine(buf []byte, i int) (int, []byte) {
 	return i, buf[start:i]
 }
 
+// ScanLines is a bufio.SplitFunc that splits line protocol into lines. Unlike
+// bufio.ScanLines, newlines within quoted string field values do not end a
+// line. Tokens include their trailing newline, if any, and can be passed to
+// ParsePointsWithPrecision.
+func ScanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	i, line := scanLine(data, 0)
+	// A newline in the last byte of data may still turn out to be escaped, so
+	// only split on it once more data has been read.
+	if i < len(data)-1 || (i < len(data) && atEOF) {
+		return i + 1, data[:i+1], nil
+	}
+
+	if atEOF {
+		return len(data), line, nil
+	}
+
+	// Request more data.
+	return 0, nil, nil
+}
+
 // scanTo returns the end position in buf and the next consecutive block
 // of bytes, starting from i and ending with stop byte, where stop byte
 // has not been escaped.
@@ -1,6 +1,7 @@
 package models_test
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/influxdata/influxdb/models"
@@ -1930,6 +1932,39 @@ cpu,host=serverA,region=us-east value=1.0 946730096789012345`,
 	}
 }
 
+func TestScanLines(t *testing.T) {
+	batch := "cpu value=1 1\n" +
+		"cpu,host=a\\ b value=\"two\nlines\" 2\n" +
+		"\n" +
+		"cpu value=\"escaped \\\" quote\nstill quoted\" 3\n" +
+		"cpu value=4 4"
+	exp := []string{
+		"cpu value=1 1\n",
+		"cpu,host=a\\ b value=\"two\nlines\" 2\n",
+		"\n",
+		"cpu value=\"escaped \\\" quote\nstill quoted\" 3\n",
+		"cpu value=4 4",
+	}
+
+	// Reading one byte at a time splits lines at every possible position.
+	for _, r := range []io.Reader{strings.NewReader(batch), iotest.OneByteReader(strings.NewReader(batch))} {
+		scanner := bufio.NewScanner(r)
+		scanner.Split(models.ScanLines)
+
+		var got []string
+		for scanner.Scan() {
+			got = append(got, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("ScanLines() mismatch:\n got %q\n exp %q", got, exp)
+		}
+	}
+}
+
 func TestNewPointEscaped(t *testing.T) {
 	// commas
 	pt := models.MustNewPoint("cpu,main", models.NewTags(map[string]string{"tag,bar": "value"}), models.Fields{"name,bar": 1.0}, time.Unix(0, 0))
@@ -0,0 +1,293 @@
+// Package oidc implements single sign-on with OpenID Connect identity
+// providers using the authorization code flow.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	gojwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/oauth2"
+)
+
+// DefaultUsernameClaim is the ID token claim used as the username when none is
+// configured.
+const DefaultUsernameClaim = "email"
+
+// Config is the configuration of an OpenID Connect identity provider.
+type Config struct {
+	// Issuer is the URL of the identity provider. Its configuration is
+	// discovered from Issuer + "/.well-known/openid-configuration".
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	// Scopes requested in addition to the openid scope.
+	Scopes []string
+
+	// UsernameClaim is the ID token claim holding the name of the user. If it
+	// is the email claim, the email_verified claim must be true.
+	UsernameClaim string
+
+	// HTTPClient is used to communicate with the identity provider. The
+	// default client is used if nil.
+	HTTPClient *http.Client
+}
+
+// Identity is an identity verified by an identity provider. The issuer and
+// subject identify it; the username may be reassigned by the provider.
+type Identity struct {
+	Issuer   string
+	Subject  string
+	Username string
+
+	// Claims are all claims of the ID token.
+	Claims map[string]interface{}
+}
+
+// discovery is the subset of the OpenID provider metadata that is used.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider authenticates users with an OpenID Connect identity provider.
+type Provider struct {
+	config  Config
+	oauth2  *oauth2.Config
+	jwksURL string
+	client  *http.Client
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PublicKey
+}
+
+// NewProvider returns a Provider for the identity provider described by c,
+// discovering its endpoints.
+func NewProvider(ctx context.Context, c Config) (*Provider, error) {
+	if c.Issuer == "" || c.ClientID == "" {
+		return nil, errors.New("oidc: issuer and client id are required")
+	}
+	if c.UsernameClaim == "" {
+		c.UsernameClaim = DefaultUsernameClaim
+	}
+
+	p := &Provider{
+		config: c,
+		client: c.HTTPClient,
+	}
+	if p.client == nil {
+		p.client = http.DefaultClient
+	}
+
+	var d discovery
+	if err := p.getJSON(ctx, strings.TrimSuffix(c.Issuer, "/")+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("oidc: unable to discover provider configuration: %v", err)
+	}
+	if d.Issuer != c.Issuer {
+		return nil, fmt.Errorf("oidc: provider issuer %q does not match %q", d.Issuer, c.Issuer)
+	}
+
+	p.jwksURL = d.JWKSURI
+	p.oauth2 = &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		Scopes:       append([]string{"openid"}, c.Scopes...),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  d.AuthorizationEndpoint,
+			TokenURL: d.TokenEndpoint,
+		},
+	}
+	return p, nil
+}
+
+// AuthCodeURL returns the URL of the identity provider's login page. The
+// state is returned unchanged to the redirect URL and nonce is included in
+// the ID token.
+func (p *Provider) AuthCodeURL(state, nonce string) string {
+	return p.oauth2.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce))
+}
+
+// Exchange exchanges an authorization code for an ID token, returning the
+// identity it verifies. The ID token must contain nonce.
+func (p *Provider) Exchange(ctx context.Context, code, nonce string) (*Identity, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	tok, err := p.oauth2.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: unable to exchange code: %v", err)
+	}
+
+	raw, ok := tok.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+
+	claims, err := p.verify(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return nil, errors.New("oidc: id token has invalid nonce")
+	}
+
+	id := &Identity{Claims: claims}
+	id.Issuer, _ = claims["iss"].(string)
+	id.Subject, _ = claims["sub"].(string)
+	id.Username, _ = claims[p.config.UsernameClaim].(string)
+	if id.Subject == "" {
+		return nil, errors.New("oidc: id token has no subject")
+	}
+	if id.Username == "" {
+		return nil, fmt.Errorf("oidc: id token has no %s claim", p.config.UsernameClaim)
+	}
+	if p.config.UsernameClaim == "email" && !emailVerified(claims["email_verified"]) {
+		return nil, errors.New("oidc: id token email is not verified")
+	}
+	return id, nil
+}
+
+// emailVerified returns true if the email_verified claim is true. Some
+// providers send it as a string.
+func emailVerified(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// verify checks the signature, issuer, audience and lifetime of the ID token,
+// returning its claims.
+func (p *Provider) verify(ctx context.Context, raw string) (gojwt.MapClaims, error) {
+	claims := gojwt.MapClaims{}
+	if _, err := gojwt.ParseWithClaims(raw, claims, func(t *gojwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*gojwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	}); err != nil {
+		return nil, fmt.Errorf("oidc: invalid id token: %v", err)
+	}
+
+	if !claims.VerifyIssuer(p.config.Issuer, true) {
+		return nil, errors.New("oidc: id token has invalid issuer")
+	}
+	if !audienceContains(claims["aud"], p.config.ClientID) {
+		return nil, errors.New("oidc: id token has invalid audience")
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("oidc: id token has no expiry")
+	}
+	return claims, nil
+}
+
+// audienceContains returns true if the aud claim, which may be a string or a
+// list of strings, contains clientID.
+func audienceContains(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key returns the signing key with the key ID kid. The provider's keys are
+// fetched again if kid is unknown, as keys are rotated.
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// jwk is an RSA JSON web key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// fetchKeys returns the RSA signing keys of the provider by key ID.
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.jwksURL, &set); err != nil {
+		return nil, fmt.Errorf("unable to fetch signing keys: %v", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %v", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %v", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// getJSON decodes the JSON response of a GET request to url into v.
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,103 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/oidc"
+	"github.com/influxdata/influxdb/oidc/oidctest"
+)
+
+func TestProvider_Exchange(t *testing.T) {
+	idp := oidctest.NewProvider("influxdb", "secret")
+	defer idp.Close()
+
+	p, err := oidc.NewProvider(context.Background(), oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     "influxdb",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:9999/api/v2/signin/oidc/callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(p.AuthCodeURL("state", "nonce"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.String(), idp.URL+"/authorize"; !strings.HasPrefix(got, want) {
+		t.Errorf("got auth code url %s, want prefix %s", got, want)
+	}
+	if got := u.Query().Get("nonce"); got != "nonce" {
+		t.Errorf("got nonce %q, want %q", got, "nonce")
+	}
+
+	idp.SetClaims(map[string]interface{}{
+		"sub":            "1234",
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"groups":         []string{"admins"},
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		id, err := p.Exchange(context.Background(), idp.Authorize("nonce"), "nonce")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.Issuer != idp.URL || id.Subject != "1234" || id.Username != "jane@example.com" {
+			t.Errorf("got identity %s %s %s", id.Issuer, id.Subject, id.Username)
+		}
+		if groups, _ := id.Claims["groups"].([]interface{}); len(groups) != 1 || groups[0] != "admins" {
+			t.Errorf("got groups %v", id.Claims["groups"])
+		}
+	})
+
+	t.Run("invalid nonce", func(t *testing.T) {
+		if _, err := p.Exchange(context.Background(), idp.Authorize("other"), "nonce"); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		if _, err := p.Exchange(context.Background(), "unknown", "nonce"); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("invalid client", func(t *testing.T) {
+		other, err := oidc.NewProvider(context.Background(), oidc.Config{
+			Issuer:       idp.URL,
+			ClientID:     "influxdb",
+			ClientSecret: "wrong",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := other.Exchange(context.Background(), idp.Authorize("nonce"), "nonce"); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		for _, verified := range []interface{}{false, "false", nil} {
+			idp.SetClaims(map[string]interface{}{
+				"sub":            "1234",
+				"email":          "jane@example.com",
+				"email_verified": verified,
+			})
+			if _, err := p.Exchange(context.Background(), idp.Authorize("nonce"), "nonce"); err == nil {
+				t.Errorf("expected error for email_verified %v", verified)
+			}
+		}
+	})
+
+	t.Run("missing username claim", func(t *testing.T) {
+		idp.SetClaims(map[string]interface{}{"sub": "1234"})
+		if _, err := p.Exchange(context.Background(), idp.Authorize("nonce"), "nonce"); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// Rule grants users a role in an organization when a claim of their ID token
+// has a value. Claims that are lists, such as groups, match if any of their
+// values match.
+type Rule struct {
+	Claim string            `json:"claim"`
+	Value string            `json:"value"`
+	Org   string            `json:"org"`
+	Role  influxdb.UserType `json:"role"`
+}
+
+// Validate returns an error if the rule is incomplete.
+func (r Rule) Validate() error {
+	if r.Claim == "" || r.Value == "" {
+		return fmt.Errorf("rule requires a claim and value")
+	}
+	if r.Org == "" {
+		return fmt.Errorf("rule for %s=%s requires an org", r.Claim, r.Value)
+	}
+	if err := r.Role.Valid(); err != nil {
+		return fmt.Errorf("rule for %s=%s has invalid role %q", r.Claim, r.Value, r.Role)
+	}
+	return nil
+}
+
+// Matches returns true if claims satisfy the rule.
+func (r Rule) Matches(claims map[string]interface{}) bool {
+	switch v := claims[r.Claim].(type) {
+	case string:
+		return v == r.Value
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok && s == r.Value {
+				return true
+			}
+		}
+	case bool:
+		return fmt.Sprint(v) == r.Value
+	}
+	return false
+}
+
+// LoadRules reads a JSON list of rules from the file at path.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("oidc: invalid rules in %s: %v", path, err)
+	}
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return nil, fmt.Errorf("oidc: %v", err)
+		}
+	}
+	return rules, nil
+}
+
+// Provisioner creates users for identities verified by an identity provider
+// and grants them the roles their rules match.
+//
+// Users are bound to identities by the issuer and subject of the identity,
+// never by their name, as the username claim of an identity, such as an email
+// address, can be chosen or reassigned at the provider.
+type Provisioner struct {
+	Logger *zap.Logger
+	Rules  []Rule
+
+	UserService                influxdb.UserService
+	UserIdentityService        influxdb.UserIdentityService
+	OrganizationService        influxdb.OrganizationService
+	UserResourceMappingService influxdb.UserResourceMappingService
+}
+
+// Provision returns the user bound to the identity, and grants it the roles
+// of every matching rule. Existing roles are never removed, but members are
+// promoted to owners when a rule says so.
+//
+// The first time an identity signs in, a user named by the identity is
+// created and bound to it. If a user with the name exists already, such as a
+// user who signs in with a password, the identity is refused until the user
+// links it with Link.
+func (p *Provisioner) Provision(ctx context.Context, id *Identity) (*influxdb.User, error) {
+	u, err := p.boundUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		if u, err = p.createUser(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range p.Rules {
+		if !r.Matches(id.Claims) {
+			continue
+		}
+		if err := p.grant(ctx, u, r); err != nil {
+			return nil, err
+		}
+	}
+	return u, nil
+}
+
+// Link binds the identity to the existing user with userID, who must have
+// signed in otherwise, such as with a password. An identity can only be bound
+// to one user.
+func (p *Provisioner) Link(ctx context.Context, id *Identity, userID influxdb.ID) error {
+	u, err := p.boundUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if u != nil {
+		if u.ID == userID {
+			return nil
+		}
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "identity is linked to another user",
+		}
+	}
+
+	if err := p.UserIdentityService.CreateUserIdentity(ctx, &influxdb.UserIdentity{
+		Issuer:  id.Issuer,
+		Subject: id.Subject,
+		UserID:  userID,
+	}); err != nil {
+		return err
+	}
+	p.Logger.Info("Linked identity to user",
+		zap.String("issuer", id.Issuer),
+		zap.String("subject", id.Subject),
+		zap.String("user_id", userID.String()))
+	return nil
+}
+
+// boundUser returns the user bound to the identity, or nil if there is none.
+// The binding of a user that was deleted is removed.
+func (p *Provisioner) boundUser(ctx context.Context, id *Identity) (*influxdb.User, error) {
+	b, err := p.UserIdentityService.FindUserIdentity(ctx, id.Issuer, id.Subject)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	u, err := p.UserService.FindUserByID(ctx, b.UserID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		err := p.UserIdentityService.DeleteUserIdentity(ctx, id.Issuer, id.Subject)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return u, err
+}
+
+// createUser creates a user named by the identity and binds it to the
+// identity.
+func (p *Provisioner) createUser(ctx context.Context, id *Identity) (*influxdb.User, error) {
+	_, err := p.UserService.FindUser(ctx, influxdb.UserFilter{Name: &id.Username})
+	if err == nil {
+		p.Logger.Info("Refused identity of existing user that is not linked",
+			zap.String("user", id.Username),
+			zap.String("issuer", id.Issuer),
+			zap.String("subject", id.Subject))
+		return nil, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  fmt.Sprintf("user %s exists and is not linked to this identity; sign in as the user to link it", id.Username),
+		}
+	} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return nil, err
+	}
+
+	u := &influxdb.User{Name: id.Username}
+	if err := p.UserService.CreateUser(ctx, u); err != nil {
+		return nil, err
+	}
+	if err := p.UserIdentityService.CreateUserIdentity(ctx, &influxdb.UserIdentity{
+		Issuer:  id.Issuer,
+		Subject: id.Subject,
+		UserID:  u.ID,
+	}); err != nil {
+		// The user would otherwise be refused as unlinked on the next sign in.
+		if derr := p.UserService.DeleteUser(ctx, u.ID); derr != nil {
+			p.Logger.Error("Failed to remove user of unbound identity", zap.String("user", u.Name), zap.Error(derr))
+		}
+		return nil, err
+	}
+
+	p.Logger.Info("Provisioned user from identity provider",
+		zap.String("user", u.Name),
+		zap.String("issuer", id.Issuer),
+		zap.String("subject", id.Subject))
+	return u, nil
+}
+
+// grant gives the user the role of rule r in its organization.
+func (p *Provisioner) grant(ctx context.Context, u *influxdb.User, r Rule) error {
+	o, err := p.OrganizationService.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &r.Org})
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		p.Logger.Info("Skipping rule for unknown organization", zap.String("org", r.Org))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	ms, _, err := p.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   o.ID,
+		ResourceType: influxdb.OrgsResourceType,
+		UserID:       u.ID,
+	})
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	for _, m := range ms {
+		if m.UserType == r.Role || m.UserType == influxdb.Owner {
+			return nil
+		}
+		if err := p.UserResourceMappingService.DeleteUserResourceMapping(ctx, o.ID, u.ID); err != nil {
+			return err
+		}
+	}
+
+	return p.UserResourceMappingService.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		UserID:       u.ID,
+		UserType:     r.Role,
+		MappingType:  influxdb.UserMappingType,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   o.ID,
+	})
+}
@@ -0,0 +1,207 @@
+package oidc_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/oidc"
+	"go.uber.org/zap"
+)
+
+func TestRule_Matches(t *testing.T) {
+	claims := map[string]interface{}{
+		"groups":         []interface{}{"admins", "devs"},
+		"hd":             "example.com",
+		"email_verified": true,
+	}
+
+	tests := []struct {
+		rule oidc.Rule
+		want bool
+	}{
+		{rule: oidc.Rule{Claim: "groups", Value: "devs"}, want: true},
+		{rule: oidc.Rule{Claim: "groups", Value: "ops"}, want: false},
+		{rule: oidc.Rule{Claim: "hd", Value: "example.com"}, want: true},
+		{rule: oidc.Rule{Claim: "email_verified", Value: "true"}, want: true},
+		{rule: oidc.Rule{Claim: "missing", Value: "x"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Matches(claims); got != tt.want {
+			t.Errorf("%s=%s: got %v, want %v", tt.rule.Claim, tt.rule.Value, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oidc-rules-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rules.json")
+	if err := ioutil.WriteFile(path, []byte(`[{"claim": "groups", "value": "admins", "org": "myorg", "role": "owner"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := oidc.LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].Role != influxdb.Owner {
+		t.Errorf("got rules %+v", rules)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`[{"claim": "groups", "value": "admins", "org": "myorg", "role": "admin"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := oidc.LoadRules(path); err == nil {
+		t.Error("expected error for invalid role")
+	}
+}
+
+func newProvisioner(t *testing.T, rules ...oidc.Rule) (*oidc.Provisioner, *kv.Service) {
+	t.Helper()
+	svc := kv.NewService(inmem.NewKVStore())
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return &oidc.Provisioner{
+		Logger:                     zap.NewNop(),
+		Rules:                      rules,
+		UserService:                svc,
+		UserIdentityService:        svc,
+		OrganizationService:        svc,
+		UserResourceMappingService: svc,
+	}, svc
+}
+
+func TestProvisioner_Provision(t *testing.T) {
+	ctx := context.Background()
+	p, svc := newProvisioner(t,
+		oidc.Rule{Claim: "groups", Value: "devs", Org: "myorg", Role: influxdb.Member},
+		oidc.Rule{Claim: "groups", Value: "admins", Org: "myorg", Role: influxdb.Owner},
+		oidc.Rule{Claim: "groups", Value: "devs", Org: "unknown", Role: influxdb.Owner},
+	)
+
+	org := &influxdb.Organization{Name: "myorg"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	roles := func(u *influxdb.User) []influxdb.UserType {
+		ms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceID:   org.ID,
+			ResourceType: influxdb.OrgsResourceType,
+			UserID:       u.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var types []influxdb.UserType
+		for _, m := range ms {
+			types = append(types, m.UserType)
+		}
+		return types
+	}
+
+	u, err := p.Provision(ctx, &oidc.Identity{
+		Issuer:   "https://idp.example.com",
+		Subject:  "1",
+		Username: "jane",
+		Claims:   map[string]interface{}{"groups": []interface{}{"devs"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindUserByID(ctx, u.ID); err != nil {
+		t.Fatalf("user not created: %v", err)
+	}
+	if got := roles(u); len(got) != 1 || got[0] != influxdb.Member {
+		t.Fatalf("got roles %v, want [member]", got)
+	}
+
+	// Signing in again as an admin promotes the existing user, which is found
+	// by its identity even if the username changed.
+	u2, err := p.Provision(ctx, &oidc.Identity{
+		Issuer:   "https://idp.example.com",
+		Subject:  "1",
+		Username: "jane.doe",
+		Claims:   map[string]interface{}{"groups": []interface{}{"devs", "admins"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u2.ID != u.ID {
+		t.Fatalf("got user %v, want existing user %v", u2.ID, u.ID)
+	}
+	if got := roles(u); len(got) != 1 || got[0] != influxdb.Owner {
+		t.Fatalf("got roles %v, want [owner]", got)
+	}
+
+	// Another identity with the name of the user is refused.
+	for _, id := range []*oidc.Identity{
+		{Issuer: "https://idp.example.com", Subject: "2", Username: "jane"},
+		{Issuer: "https://other.example.com", Subject: "1", Username: "jane"},
+	} {
+		if _, err := p.Provision(ctx, id); influxdb.ErrorCode(err) != influxdb.EForbidden {
+			t.Errorf("%s %s: got error %v, want forbidden", id.Issuer, id.Subject, err)
+		}
+	}
+}
+
+func TestProvisioner_Link(t *testing.T) {
+	ctx := context.Background()
+	p, svc := newProvisioner(t)
+
+	// A user who signs in with a password.
+	u := &influxdb.User{Name: "jane@example.com"}
+	if err := svc.CreateUser(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	id := &oidc.Identity{Issuer: "https://idp.example.com", Subject: "1", Username: "jane@example.com"}
+	if _, err := p.Provision(ctx, id); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("got error %v, want forbidden before the identity is linked", err)
+	}
+
+	if err := p.Link(ctx, id, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Link(ctx, id, u.ID); err != nil {
+		t.Fatalf("linking again: %v", err)
+	}
+	got, err := p.Provision(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != u.ID {
+		t.Fatalf("got user %v, want linked user %v", got.ID, u.ID)
+	}
+
+	// The identity can't be linked to another user.
+	other := &influxdb.User{Name: "bob@example.com"}
+	if err := svc.CreateUser(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Link(ctx, id, other.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("got error %v, want conflict", err)
+	}
+
+	// The identity is provisioned again once its user is deleted.
+	if err := svc.DeleteUser(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err = p.Provision(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID == u.ID || got.Name != "jane@example.com" {
+		t.Fatalf("got user %v %s, want a new user", got.ID, got.Name)
+	}
+}
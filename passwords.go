@@ -1,6 +1,11 @@
 package influxdb
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
 
 // PasswordsService is the service for managing basic auth passwords.
 type PasswordsService interface {
@@ -13,3 +18,60 @@ type PasswordsService interface {
 	// updates to the new password.
 	CompareAndSetPassword(ctx context.Context, name string, old string, new string) error
 }
+
+// PasswordPolicy is the set of rules a password must follow to be set.
+type PasswordPolicy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+}
+
+// DefaultPasswordPolicy only requires passwords to be at least 8 characters long.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength: 8,
+}
+
+// Validate returns an error listing every rule of the policy that the
+// password breaks.
+func (p PasswordPolicy) Validate(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var rules []string
+	if len([]rune(password)) < p.MinLength {
+		rules = append(rules, fmt.Sprintf("be at least %d characters long", p.MinLength))
+	}
+	if p.RequireUppercase && !upper {
+		rules = append(rules, "contain an uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		rules = append(rules, "contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		rules = append(rules, "contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		rules = append(rules, "contain a symbol")
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return &Error{
+		Code: EInvalid,
+		Msg:  "passwords must " + strings.Join(rules, ", "),
+	}
+}
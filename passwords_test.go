@@ -0,0 +1,69 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	strict := influxdb.PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+
+	tests := []struct {
+		name     string
+		policy   influxdb.PasswordPolicy
+		password string
+		err      error
+	}{
+		{
+			name:     "default policy accepts long passwords",
+			policy:   influxdb.DefaultPasswordPolicy,
+			password: "howdydoody",
+		},
+		{
+			name:     "default policy rejects short passwords",
+			policy:   influxdb.DefaultPasswordPolicy,
+			password: "short",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "passwords must be at least 8 characters long",
+			},
+		},
+		{
+			name:     "length counts characters rather than bytes",
+			policy:   influxdb.DefaultPasswordPolicy,
+			password: "pässwör",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "passwords must be at least 8 characters long",
+			},
+		},
+		{
+			name:     "strict policy accepts complex passwords",
+			policy:   strict,
+			password: "Howdy-D00dy",
+		},
+		{
+			name:     "every broken rule is listed",
+			policy:   strict,
+			password: "howdy",
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "passwords must be at least 8 characters long, contain an uppercase letter, contain a digit, contain a symbol",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			influxdbtesting.ErrorsEqual(t, tt.policy.Validate(tt.password), tt.err)
+		})
+	}
+}
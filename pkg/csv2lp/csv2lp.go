@@ -0,0 +1,349 @@
+// Package csv2lp converts annotated CSV, as produced by Flux queries and
+// exports, to line protocol.
+package csv2lp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/escape"
+)
+
+// Columns with special meaning in annotated CSV. Any other named column is
+// written as a tag.
+const (
+	timeColumn        = "_time"
+	valueColumn       = "_value"
+	fieldColumn       = "_field"
+	measurementColumn = "_measurement"
+)
+
+// ignoredColumns are the annotated CSV columns that are not written as tags.
+var ignoredColumns = map[string]bool{
+	"":       true,
+	"result": true,
+	"table":  true,
+	"_start": true,
+	"_stop":  true,
+}
+
+// Reader reads annotated CSV from an underlying reader and returns it as line
+// protocol. Each table in the CSV must have _measurement, _field and _value
+// columns, and tables with different columns must be separated by annotations. The _time column is optional; rows without a time are written
+// without a timestamp. The type of _value is taken from the #datatype
+// annotation if present, and otherwise inferred from the value itself.
+type Reader struct {
+	r     *csv.Reader
+	limit recordLimiter
+	buf   bytes.Buffer
+	err   error
+
+	n        int // The number of records read.
+	table    *table
+	datatype []string
+	defaults []string
+}
+
+// NewReader returns a new Reader that converts the annotated CSV in r.
+func NewReader(r io.Reader) *Reader {
+	cr := &Reader{limit: recordLimiter{r: r}}
+	cr.r = csv.NewReader(&cr.limit)
+	cr.r.FieldsPerRecord = -1
+	cr.r.ReuseRecord = true
+	return cr
+}
+
+// SetMaxRecordSize limits the size in bytes of a record, so that a single
+// record can't use unbounded memory. Reading a larger record returns
+// bufio.ErrTooLong. Zero, the default, means no limit.
+func (r *Reader) SetMaxRecordSize(n int) {
+	r.limit.size = int64(n)
+}
+
+// Read reads converted line protocol into p. Any error converting the CSV
+// stops reading.
+func (r *Reader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	return r.buf.Read(p)
+}
+
+// ReadLine returns the line protocol for the next data record and the number
+// of that record, skipping annotations and headers. Unlike Read, a record
+// that cannot be converted does not stop reading: its error is a
+// *RecordError and the following call continues with the next record.
+// ReadLine returns io.EOF at the end of the CSV. The returned line is only
+// valid until the next call, and ReadLine must not be mixed with Read.
+func (r *Reader) ReadLine() ([]byte, int, error) {
+	r.buf.Reset()
+	for r.buf.Len() == 0 {
+		if err := r.next(); err == bufio.ErrTooLong {
+			return nil, r.n + 1, err
+		} else if err != nil {
+			return nil, r.n, err
+		}
+	}
+	return r.buf.Bytes(), r.n, nil
+}
+
+// RecordError is returned for a data record that could not be converted to
+// line protocol.
+type RecordError struct {
+	Record int // The number of the record in the CSV, starting at 1.
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Record, e.Err)
+}
+
+// next converts the next CSV record, appending any line protocol to the buffer.
+func (r *Reader) next() error {
+	r.limit.reset(r.r.InputOffset())
+	record, err := r.r.Read()
+	if err != nil {
+		return err
+	}
+	r.n++
+
+	// Annotations precede the header of a new table.
+	if strings.HasPrefix(record[0], "#") {
+		if r.table != nil {
+			r.table, r.datatype, r.defaults = nil, nil, nil
+		}
+		switch record[0] {
+		case "#datatype":
+			r.datatype = append([]string(nil), record...)
+		case "#default":
+			r.defaults = append([]string(nil), record...)
+		}
+		return nil
+	}
+
+	if r.table == nil {
+		t, err := newTable(record, r.datatype, r.defaults)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", r.n, err)
+		}
+		r.table = t
+		return nil
+	}
+
+	if err := r.table.appendLine(&r.buf, record); err != nil {
+		return &RecordError{Record: r.n, Err: err}
+	}
+	return nil
+}
+
+// recordLimiter limits the bytes read from r to size beyond the start of the
+// current record.
+type recordLimiter struct {
+	r    io.Reader
+	size int64
+	read int64
+	max  int64
+}
+
+// reset starts a record at offset in the input.
+func (l *recordLimiter) reset(offset int64) {
+	l.max = offset + l.size
+}
+
+func (l *recordLimiter) Read(p []byte) (int, error) {
+	if l.size > 0 {
+		if l.read >= l.max {
+			return 0, bufio.ErrTooLong
+		}
+		if int64(len(p)) > l.max-l.read {
+			p = p[:l.max-l.read]
+		}
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	return n, err
+}
+
+// table describes the columns of a table of annotated CSV.
+type table struct {
+	time, value, field, measurement int
+	timeType, valueType             string
+	tags                            []int // Sorted by tag key.
+	keys                            []string
+	defaults                        []string
+}
+
+func newTable(header, datatype, defaults []string) (*table, error) {
+	t := &table{
+		time:        -1,
+		value:       -1,
+		field:       -1,
+		measurement: -1,
+		keys:        append([]string(nil), header...),
+		defaults:    make([]string, len(header)),
+	}
+	copy(t.defaults, defaults)
+
+	for i, name := range header {
+		switch name {
+		case timeColumn:
+			t.time = i
+			if i < len(datatype) {
+				t.timeType = datatype[i]
+			}
+		case valueColumn:
+			t.value = i
+			if i < len(datatype) {
+				t.valueType = datatype[i]
+			}
+		case fieldColumn:
+			t.field = i
+		case measurementColumn:
+			t.measurement = i
+		default:
+			if !ignoredColumns[name] {
+				t.tags = append(t.tags, i)
+			}
+		}
+	}
+
+	switch {
+	case t.measurement < 0:
+		return nil, fmt.Errorf("missing %s column", measurementColumn)
+	case t.field < 0:
+		return nil, fmt.Errorf("missing %s column", fieldColumn)
+	case t.value < 0:
+		return nil, fmt.Errorf("missing %s column", valueColumn)
+	}
+
+	sort.Slice(t.tags, func(i, j int) bool { return header[t.tags[i]] < header[t.tags[j]] })
+	return t, nil
+}
+
+// get returns the value of column i in record, or its default if empty.
+func (t *table) get(record []string, i int) string {
+	if i < 0 {
+		return ""
+	} else if i < len(record) && record[i] != "" {
+		return record[i]
+	}
+	return t.defaults[i]
+}
+
+// appendLine appends the line protocol for record to buf. Nothing is appended
+// if record cannot be converted.
+func (t *table) appendLine(buf *bytes.Buffer, record []string) error {
+	measurement := t.get(record, t.measurement)
+	if measurement == "" {
+		return fmt.Errorf("empty %s", measurementColumn)
+	}
+	field := t.get(record, t.field)
+	if field == "" {
+		return fmt.Errorf("empty %s", fieldColumn)
+	}
+
+	value, err := formatValue(t.get(record, t.value), t.valueType)
+	if err != nil {
+		return err
+	}
+
+	var ts string
+	if s := t.get(record, t.time); s != "" {
+		if ts, err = formatTime(s, t.timeType); err != nil {
+			return err
+		}
+	}
+
+	buf.Write(models.EscapeMeasurement([]byte(measurement)))
+	for _, i := range t.tags {
+		if v := t.get(record, i); v != "" {
+			buf.WriteByte(',')
+			buf.Write(escape.Bytes([]byte(t.keys[i])))
+			buf.WriteByte('=')
+			buf.Write(escape.Bytes([]byte(v)))
+		}
+	}
+	buf.WriteByte(' ')
+	buf.Write(escape.Bytes([]byte(field)))
+	buf.WriteByte('=')
+	buf.WriteString(value)
+	if ts != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(ts)
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+// formatValue returns s as a line protocol field value of the annotated CSV
+// type typ. If typ is empty the type is inferred from s.
+func formatValue(s, typ string) (string, error) {
+	if typ == "" {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			typ = "double"
+		} else if _, err := strconv.ParseBool(s); err == nil {
+			typ = "boolean"
+		} else {
+			typ = "string"
+		}
+	}
+
+	switch typ {
+	case "double":
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid double %q", s)
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case "long":
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid long %q", s)
+		}
+		return strconv.FormatInt(v, 10) + "i", nil
+	case "unsignedLong":
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid unsignedLong %q", s)
+		}
+		return strconv.FormatUint(v, 10) + "u", nil
+	case "boolean":
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid boolean %q", s)
+		}
+		return strconv.FormatBool(v), nil
+	case "string":
+		return `"` + models.EscapeStringField(s) + `"`, nil
+	default:
+		return "", fmt.Errorf("unsupported %s data type %q", valueColumn, typ)
+	}
+}
+
+// formatTime returns the time s as nanoseconds since the epoch. Times of the
+// long type are already in nanoseconds; all others must be RFC3339 times.
+func formatTime(s, typ string) (string, error) {
+	if typ == "long" {
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid %s %q", timeColumn, s)
+		}
+		return s, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q", timeColumn, s)
+	}
+	return strconv.FormatInt(t.UnixNano(), 10), nil
+}
@@ -0,0 +1,143 @@
+package csv2lp_test
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/csv2lp"
+)
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		lp   string
+		err  string
+	}{
+		{
+			name: "annotated",
+			csv: `#datatype,string,long,dateTime:RFC3339,double,string,string,string
+#group,false,false,false,false,true,true,true
+#default,_result,,,,,,
+,result,table,_time,_value,_field,_measurement,host
+,,0,1970-01-01T00:00:00.00000002Z,2.5,value,cpu,a b
+,,1,1970-01-01T00:00:00.00000003Z,3.5,value,cpu,
+
+#datatype,string,long,dateTime:RFC3339,string,string,string
+#group,false,false,false,false,true,true
+#default,_result,,,,,disk
+,result,table,_time,_value,_field,_measurement
+,,2,1970-01-01T00:00:00.00000003Z,"/a ""b""",path,
+`,
+			lp: `cpu,host=a\ b value=2.5 20
+cpu value=3.5 30
+disk path="/a \"b\"" 30
+`,
+		},
+		{
+			name: "unannotated",
+			csv: `_measurement,_field,_value,region,_time
+m,f,1,west,1970-01-01T00:00:01Z
+m,f,true,,
+m,f,abc,,
+`,
+			lp: `m,region=west f=1 1000000000
+m f=true
+m f="abc"
+`,
+		},
+		{
+			name: "long values",
+			csv: `#datatype,string,string,long,unsignedLong,string
+,_measurement,_field,_time,_value,tag
+,m,f,10,5,x
+`,
+			lp: "m,tag=x f=5u 10\n",
+		},
+		{
+			name: "missing field column",
+			csv:  "_measurement,_value\nm,1\n",
+			err:  "record 1: missing _field column",
+		},
+		{
+			name: "invalid value",
+			csv:  "#datatype,string,string,long\n,_measurement,_field,_value\n,m,f,abc\n",
+			err:  `record 3: invalid long "abc"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ioutil.ReadAll(csv2lp.NewReader(strings.NewReader(tt.csv)))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("got error %v, exp %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.lp {
+				t.Fatalf("unexpected line protocol:\ngot:\n%s\nexp:\n%s", got, tt.lp)
+			}
+		})
+	}
+}
+
+func TestReader_ReadLine(t *testing.T) {
+	in := `#datatype,string,string,long
+,_measurement,_field,_value
+,m,f,1
+,m,f,abc
+,,f,3
+,m,f,4
+`
+	r := csv2lp.NewReader(strings.NewReader(in))
+
+	type result struct {
+		line   string
+		record int
+		err    string
+	}
+	exp := []result{
+		{line: "m f=1i\n", record: 3},
+		{record: 4, err: `record 4: invalid long "abc"`},
+		{record: 5, err: "record 5: empty _measurement"},
+		{line: "m f=4i\n", record: 6},
+	}
+
+	for _, e := range exp {
+		line, n, err := r.ReadLine()
+		got := result{line: string(line), record: n}
+		if err != nil {
+			if _, ok := err.(*csv2lp.RecordError); !ok {
+				t.Fatalf("expected a record error, got %T: %v", err, err)
+			}
+			got.err = err.Error()
+		}
+		if got != e {
+			t.Fatalf("got %+v, exp %+v", got, e)
+		}
+	}
+
+	if _, _, err := r.ReadLine(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestReader_SetMaxRecordSize(t *testing.T) {
+	in := "#datatype,string,string,long\n,_measurement,_field,_value\n,m,f,1\n,m,f,\"" + strings.Repeat("1", 100) + "\"\n"
+	r := csv2lp.NewReader(strings.NewReader(in))
+	r.SetMaxRecordSize(40)
+
+	if line, n, err := r.ReadLine(); err != nil || n != 3 || string(line) != "m f=1i\n" {
+		t.Fatalf("got %q, %d, %v", line, n, err)
+	}
+	if _, n, err := r.ReadLine(); err != bufio.ErrTooLong || n != 4 {
+		t.Fatalf("got record %d, %v, want record 4 to be too long", n, err)
+	}
+}
@@ -0,0 +1,189 @@
+// Package encryption provides the keys and formats used to encrypt storage
+// engine files at rest with AES-GCM.
+//
+// Keys are identified by an id that is stored alongside the data they
+// encrypt, so that files written with a previous key can still be read after
+// a new key is added to the keyring. Data is always written with the current
+// key of the keyring.
+//
+// The series file of the storage engine is not encrypted, so series keys are
+// stored in plaintext there.
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// KeySize is the size in bytes of a key, selecting AES-256.
+const KeySize = 32
+
+// MaxKeyIDLength is the maximum length of a key id.
+const MaxKeyIDLength = 255
+
+// ErrDecrypt is returned when encrypted data fails authentication, because
+// it is corrupt or was encrypted with another key.
+var ErrDecrypt = errors.New("encryption: message authentication failed")
+
+// Key is an AES-GCM key with its id.
+type Key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewKey returns the key with id made of the KeySize bytes in b.
+func NewKey(id string, b []byte) (*Key, error) {
+	if id == "" || len(id) > MaxKeyIDLength || strings.ContainsAny(id, " \t\r\n") {
+		return nil, fmt.Errorf("encryption: key id must be 1 to %d characters without whitespace", MaxKeyIDLength)
+	} else if len(b) != KeySize {
+		return nil, fmt.Errorf("encryption: key %s must be %d bytes long, got %d", id, KeySize, len(b))
+	}
+
+	block, err := aes.NewCipher(b)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{id: id, aead: aead}, nil
+}
+
+// ID returns the id of the key.
+func (k *Key) ID() string { return k.id }
+
+// Overhead returns the number of bytes sealing adds to the plaintext.
+func (k *Key) Overhead() int { return k.aead.NonceSize() + k.aead.Overhead() }
+
+// Seal encrypts and authenticates plaintext and additional data ad, and
+// appends the random nonce followed by the ciphertext to dst.
+func (k *Key) Seal(dst, plaintext, ad []byte) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, make([]byte, k.aead.NonceSize())...)
+	nonce := dst[n:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return k.aead.Seal(dst, nonce, plaintext, ad), nil
+}
+
+// Open authenticates and decrypts data sealed by Seal with the same
+// additional data ad, and appends the plaintext to dst.
+func (k *Key) Open(dst, sealed, ad []byte) ([]byte, error) {
+	n := k.aead.NonceSize()
+	if len(sealed) < n+k.aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+	b, err := k.aead.Open(dst, sealed[:n], sealed[n:], ad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return b, nil
+}
+
+// Keyring holds the keys data may be encrypted with.
+type Keyring struct {
+	current *Key
+	keys    map[string]*Key
+}
+
+// NewKeyring returns a keyring holding keys. The last key is the current
+// one that new data is encrypted with.
+func NewKeyring(keys ...*Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("encryption: keyring must hold at least one key")
+	}
+
+	kr := &Keyring{
+		current: keys[len(keys)-1],
+		keys:    make(map[string]*Key, len(keys)),
+	}
+	for _, k := range keys {
+		if _, ok := kr.keys[k.id]; ok {
+			return nil, fmt.Errorf("encryption: duplicate key id %s", k.id)
+		}
+		kr.keys[k.id] = k
+	}
+	return kr, nil
+}
+
+// LoadKeyring reads a keyring from the keyfile at path.
+//
+// Each line of the keyfile holds a key id and the base64 encoding of the key,
+// separated by whitespace. Blank lines and lines starting with # are ignored.
+// The last key is the current one, so that a key is rotated by appending a
+// new one to the file.
+func LoadKeyring(path string) (*Keyring, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []*Key
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("encryption: %s:%d: expected a key id and a base64 encoded key", path, line)
+		}
+		b, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("encryption: %s:%d: %v", path, line, err)
+		}
+		k, err := NewKey(fields[0], b)
+		if err != nil {
+			return nil, fmt.Errorf("%v (%s:%d)", err, path, line)
+		}
+		keys = append(keys, k)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewKeyring(keys...)
+}
+
+// Current returns the key new data is encrypted with.
+func (kr *Keyring) Current() *Key { return kr.current }
+
+// Key returns the key with id.
+func (kr *Keyring) Key(id string) (*Key, error) {
+	k, ok := kr.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("encryption: unknown key %s", id)
+	}
+	return k, nil
+}
+
+// AppendKeyID appends the length prefixed id to dst, for storing the id of
+// a key in a file header.
+func AppendKeyID(dst []byte, id string) []byte {
+	dst = append(dst, byte(len(id)))
+	return append(dst, id...)
+}
+
+// ReadKeyID reads a key id written by AppendKeyID from the start of b and
+// returns it along with the number of bytes read.
+func ReadKeyID(b []byte) (string, int, error) {
+	if len(b) < 1 || len(b) < 1+int(b[0]) {
+		return "", 0, io.ErrShortBuffer
+	} else if b[0] == 0 {
+		return "", 0, errors.New("encryption: empty key id")
+	}
+	n := 1 + int(b[0])
+	return string(b[1:n]), n, nil
+}
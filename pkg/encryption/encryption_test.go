@@ -0,0 +1,176 @@
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+)
+
+func mustKey(t *testing.T, id string, fill byte) *encryption.Key {
+	t.Helper()
+	k, err := encryption.NewKey(id, bytes.Repeat([]byte{fill}, encryption.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestKey_SealOpen(t *testing.T) {
+	k := mustKey(t, "k1", 1)
+
+	sealed, err := k.Seal([]byte("prefix"), []byte("hello"), []byte("ad"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(sealed, []byte("prefix")) || len(sealed) != len("prefix")+len("hello")+k.Overhead() {
+		t.Fatalf("unexpected sealed data %x", sealed)
+	}
+
+	got, err := k.Open(nil, sealed[len("prefix"):], []byte("ad"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(got) != "hello" {
+		t.Fatalf("expected hello, got %q", got)
+	}
+
+	if _, err := k.Open(nil, sealed[len("prefix"):], []byte("other")); err != encryption.ErrDecrypt {
+		t.Errorf("expected additional data mismatch to fail, got %v", err)
+	}
+	if _, err := mustKey(t, "k2", 2).Open(nil, sealed[len("prefix"):], []byte("ad")); err != encryption.ErrDecrypt {
+		t.Errorf("expected another key to fail, got %v", err)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	k1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, encryption.KeySize))
+	k2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, encryption.KeySize))
+	path := filepath.Join(dir, "keys")
+	data := strings.Join([]string{"# storage keys", "2019-01 " + k1, "", "2019-06\t" + k2}, "\n")
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	kr, err := encryption.LoadKeyring(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := kr.Current().ID(); id != "2019-06" {
+		t.Errorf("expected the last key to be current, got %s", id)
+	}
+	if _, err := kr.Key("2019-01"); err != nil {
+		t.Errorf("expected previous key to be found: %v", err)
+	}
+	if _, err := kr.Key("unknown"); err == nil {
+		t.Error("expected unknown key not to be found")
+	}
+
+	if err := ioutil.WriteFile(path, []byte("k1 "+k1+"\nk1 "+k2), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encryption.LoadKeyring(path); err == nil {
+		t.Error("expected duplicate key ids to fail")
+	}
+}
+
+func TestStream(t *testing.T) {
+	old, cur := mustKey(t, "old", 1), mustKey(t, "cur", 2)
+	kr, err := encryption.NewKeyring(old, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 10, encryption.ChunkSize, 3*encryption.ChunkSize + 7} {
+		plain := bytes.Repeat([]byte("0123456789"), size/10+1)[:size]
+
+		var buf bytes.Buffer
+		w := encryption.NewWriter(&buf, old)
+		// Write in uneven pieces to cross chunk boundaries.
+		for p := plain; len(p) > 0; {
+			n := 1000
+			if n > len(p) {
+				n = len(p)
+			}
+			if _, err := w.Write(p[:n]); err != nil {
+				t.Fatal(err)
+			}
+			p = p[n:]
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		b := buf.Bytes()
+		if !encryption.IsStream(b) {
+			t.Fatalf("size %d: expected an encrypted stream", size)
+		}
+		got, err := encryption.ReadStream(b, kr)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: plaintext mismatch", size)
+		}
+
+		if size > encryption.ChunkSize {
+			// Dropping the final chunk must be detected.
+			last := len(b) - (4 + size%encryption.ChunkSize + old.Overhead())
+			if _, err := encryption.ReadStream(b[:last], kr); err == nil {
+				t.Errorf("size %d: expected truncated stream to fail", size)
+			}
+		}
+	}
+}
+
+func TestSection(t *testing.T) {
+	key := mustKey(t, "cur", 2)
+	ad := []byte("offset")
+
+	for _, size := range []int{0, 10, encryption.ChunkSize, 3*encryption.ChunkSize + 7} {
+		plain := bytes.Repeat([]byte("0123456789"), size/10+1)[:size]
+
+		var buf bytes.Buffer
+		w := encryption.NewSectionWriter(&buf, key, ad)
+		if _, err := w.Write(plain); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		buf.WriteString("trailer")
+
+		r := bytes.NewReader(buf.Bytes())
+		got, err := encryption.ReadSection(nil, r, key, ad)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("size %d: plaintext mismatch", size)
+		}
+		if rest, _ := ioutil.ReadAll(r); string(rest) != "trailer" {
+			t.Fatalf("size %d: expected the data after the section to be unread, got %q", size, rest)
+		}
+
+		if _, err := encryption.ReadSection(nil, bytes.NewReader(buf.Bytes()), key, []byte("moved")); err != encryption.ErrDecrypt {
+			t.Errorf("size %d: expected section with other additional data to fail, got %v", size, err)
+		}
+		if size > encryption.ChunkSize {
+			// Dropping the final chunk must be detected.
+			last := buf.Len() - len("trailer") - (4 + size%encryption.ChunkSize + key.Overhead())
+			if _, err := encryption.ReadSection(nil, bytes.NewReader(buf.Bytes()[:last]), key, ad); err == nil {
+				t.Errorf("size %d: expected truncated section to fail", size)
+			}
+		}
+	}
+}
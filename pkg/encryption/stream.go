@@ -0,0 +1,227 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// StreamMagic starts a file written by a Writer. Its first byte is not
+// printable so that it can't be mistaken for the signature of a plaintext
+// file.
+const StreamMagic = "\xffENC"
+
+// ChunkSize is the size of the plaintext chunks a Writer seals.
+const ChunkSize = 64 * 1024
+
+// finalChunk is set in the length prefix of the final chunk of a section.
+const finalChunk = 1 << 31
+
+// chunkAD appends to ad the additional data of the chunk at index i,
+// binding it to its position in the stream and marking the final chunk so
+// that a truncated stream is detected.
+func chunkAD(ad []byte, i uint64, final bool) []byte {
+	var b [9]byte
+	binary.BigEndian.PutUint64(b[:8], i)
+	if final {
+		b[8] = 1
+	}
+	return append(ad[:len(ad):len(ad)], b[:]...)
+}
+
+// Writer encrypts a stream written to it, for files that are written once
+// and read back whole.
+//
+// The stream is made of a header holding StreamMagic and the key id,
+// followed by chunks of at most ChunkSize bytes of plaintext, each sealed
+// separately and prefixed with its sealed length as a 4 byte integer.
+//
+// A Writer returned by NewSectionWriter instead encrypts a section of a file
+// that holds the id of its key, such as the index of a TSM file. It writes
+// no header, and sets the highest bit of the length of the final chunk, so
+// that the section can be followed by other data.
+type Writer struct {
+	w   io.Writer
+	key *Key
+
+	// section is true if the stream is a section of a file, whose chunks are
+	// also bound to ad.
+	section bool
+	ad      []byte
+
+	buf    []byte
+	sealed []byte
+	chunk  uint64
+	err    error
+}
+
+// NewWriter returns a Writer encrypting with key to w. Close must be called
+// to write the final chunk.
+func NewWriter(w io.Writer, key *Key) *Writer {
+	return &Writer{
+		w:   w,
+		key: key,
+		buf: make([]byte, 0, ChunkSize),
+	}
+}
+
+// NewSectionWriter returns a Writer encrypting a section of a file with key
+// to w. The chunks are bound to ad, such as the offset of the section in the
+// file, so that they can't be moved. Close must be called to write the
+// final chunk.
+func NewSectionWriter(w io.Writer, key *Key, ad []byte) *Writer {
+	return &Writer{
+		w:       w,
+		key:     key,
+		section: true,
+		ad:      ad,
+		buf:     make([]byte, 0, ChunkSize),
+	}
+}
+
+// Write buffers p, sealing and writing every full chunk. It returns the
+// number of bytes of plaintext written.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	var n int
+	for len(p) > 0 {
+		if len(w.buf) == ChunkSize {
+			if w.err = w.flush(false); w.err != nil {
+				return n, w.err
+			}
+		}
+		m := copy(w.buf[len(w.buf):ChunkSize], p)
+		w.buf = w.buf[:len(w.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+func (w *Writer) flush(final bool) error {
+	if w.chunk == 0 && !w.section {
+		hdr := AppendKeyID([]byte(StreamMagic), w.key.ID())
+		if _, err := w.w.Write(hdr); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	w.sealed, err = w.key.Seal(append(w.sealed[:0], 0, 0, 0, 0), w.buf, chunkAD(w.ad, w.chunk, final))
+	if err != nil {
+		return err
+	}
+	n := uint32(len(w.sealed) - 4)
+	if w.section && final {
+		n |= finalChunk
+	}
+	binary.BigEndian.PutUint32(w.sealed[:4], n)
+	if _, err := w.w.Write(w.sealed); err != nil {
+		return err
+	}
+
+	w.buf = w.buf[:0]
+	w.chunk++
+	return nil
+}
+
+// Close seals and writes the final chunk. It does not close the underlying
+// writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = w.flush(true)
+	if w.err != nil {
+		return w.err
+	}
+	w.err = errors.New("encryption: writer closed")
+	return nil
+}
+
+// IsStream returns true if b starts with StreamMagic.
+func IsStream(b []byte) bool {
+	return bytes.HasPrefix(b, []byte(StreamMagic))
+}
+
+// ReadStream decrypts the whole stream b written by a Writer with a key of kr.
+func ReadStream(b []byte, kr *Keyring) ([]byte, error) {
+	if !IsStream(b) {
+		return nil, errors.New("encryption: not an encrypted stream")
+	}
+	b = b[len(StreamMagic):]
+
+	id, n, err := ReadKeyID(b)
+	if err != nil {
+		return nil, err
+	}
+	key, err := kr.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	b = b[n:]
+
+	plain := make([]byte, 0, len(b))
+	for i := uint64(0); ; i++ {
+		if len(b) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		sz := int(binary.BigEndian.Uint32(b[:4]))
+		if len(b) < 4+sz {
+			return nil, io.ErrUnexpectedEOF
+		}
+		sealed := b[4 : 4+sz]
+		b = b[4+sz:]
+
+		final := len(b) == 0
+		if plain, err = key.Open(plain, sealed, chunkAD(nil, i, final)); err != nil {
+			return nil, err
+		}
+		if final {
+			return plain, nil
+		}
+	}
+}
+
+// ReadSection reads a section written by a Writer from NewSectionWriter with
+// key and ad from r, and appends its plaintext to dst. It reads nothing
+// from r after the section.
+func ReadSection(dst []byte, r io.Reader, key *Key, ad []byte) ([]byte, error) {
+	var sealed []byte
+	for i := uint64(0); ; i++ {
+		var hdr [4]byte
+		if _, err := io.ReadFull(r, hdr[:]); err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+		n := binary.BigEndian.Uint32(hdr[:])
+		final := n&finalChunk != 0
+		n &^= finalChunk
+		if int(n) > ChunkSize+key.Overhead() {
+			return nil, ErrDecrypt
+		}
+
+		if cap(sealed) < int(n) {
+			sealed = make([]byte, n)
+		}
+		sealed = sealed[:n]
+		if _, err := io.ReadFull(r, sealed); err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+
+		var err error
+		if dst, err = key.Open(dst, sealed, chunkAD(ad, i, final)); err != nil {
+			return nil, err
+		}
+		if final {
+			return dst, nil
+		}
+	}
+}
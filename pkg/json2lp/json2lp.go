@@ -0,0 +1,363 @@
+// Package json2lp converts points encoded as JSON to line protocol.
+//
+// Each point is a JSON object:
+//
+//	{
+//		"measurement": "cpu",
+//		"tags": {"host": "server01"},
+//		"fields": {"usage": 0.64, "healthy": true, "state": "ok"},
+//		"time": "2019-10-01T12:00:00Z"
+//	}
+//
+// measurement and at least one field are required. Numeric fields are written
+// as floats, and booleans and strings as themselves. Fields of other types are
+// objects giving the type of the value:
+//
+//	"fields": {"count": {"type": "integer", "value": 42}}
+//
+// The types are integer, unsigned, float, boolean and string. time is either
+// an RFC3339 string or an integer in the precision given to NewReader; points
+// without a time are written without a timestamp. The input is either an
+// array of points, or a stream of points such as newline-delimited JSON.
+package json2lp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/escape"
+)
+
+// Reader reads JSON points from an underlying reader and returns them as line
+// protocol with nanosecond timestamps.
+type Reader struct {
+	r          *bufio.Reader
+	dec        *json.Decoder
+	limit      recordLimiter
+	multiplier int64
+
+	array bool // Whether the points are in an array.
+	done  bool
+	n     int // The number of points read.
+	buf   bytes.Buffer
+}
+
+// NewReader returns a new Reader that converts the JSON points in r. Integer
+// times are in the given precision.
+func NewReader(r io.Reader, precision string) *Reader {
+	return &Reader{
+		r:          bufio.NewReader(r),
+		multiplier: models.GetPrecisionMultiplier(precision),
+	}
+}
+
+// SetMaxRecordSize limits the size in bytes of a point, so that a single
+// point can't use unbounded memory. ReadLine returns bufio.ErrTooLong for a
+// larger point. Zero, the default, means no limit. It must be called before
+// the first ReadLine.
+func (r *Reader) SetMaxRecordSize(n int) {
+	r.limit.size = int64(n)
+}
+
+// ReadLine returns the line protocol for the next point and the number of
+// that point, starting at 1. A point that cannot be converted does not stop
+// reading: its error is a *RecordError and the following call continues with
+// the next point. Any other error, such as malformed JSON, is final. ReadLine
+// returns io.EOF after the last point. The returned line is only valid until
+// the next call.
+func (r *Reader) ReadLine() ([]byte, int, error) {
+	if r.dec == nil {
+		if err := r.start(); err != nil {
+			return nil, r.n, err
+		}
+	}
+	if r.done {
+		return nil, r.n, io.EOF
+	}
+	r.limit.reset(r.dec.InputOffset())
+
+	if r.array && !r.dec.More() {
+		// Consume the closing bracket, which must end the input.
+		if tok, err := r.dec.Token(); err != nil {
+			return nil, r.n, err
+		} else if tok != json.Delim(']') {
+			return nil, r.n, fmt.Errorf("unexpected %v in array of points", tok)
+		}
+		if _, err := r.dec.Token(); err != io.EOF {
+			return nil, r.n, errors.New("unexpected data after array of points")
+		}
+		r.done = true
+		return nil, r.n, io.EOF
+	}
+
+	var p point
+	err := r.dec.Decode(&p)
+	if err == io.EOF && !r.array {
+		r.done = true
+		return nil, r.n, io.EOF
+	}
+	r.n++
+
+	// The decoder skips past values of the wrong type, so only the point
+	// containing one is rejected.
+	if e, ok := err.(*json.UnmarshalTypeError); ok {
+		if e.Field == "" {
+			err = fmt.Errorf("point must be an object, got %s", e.Value)
+		} else {
+			err = fmt.Errorf("invalid %s: unexpected %s", e.Field, e.Value)
+		}
+		return nil, r.n, &RecordError{Record: r.n, Err: err}
+	} else if err != nil {
+		return nil, r.n, err
+	}
+
+	r.buf.Reset()
+	if err := p.appendLine(&r.buf, r.multiplier); err != nil {
+		return nil, r.n, &RecordError{Record: r.n, Err: err}
+	}
+	return r.buf.Bytes(), r.n, nil
+}
+
+// start determines whether the points are in an array.
+func (r *Reader) start() error {
+	for {
+		c, err := r.r.ReadByte()
+		if err == io.EOF {
+			r.done = true
+			break
+		} else if err != nil {
+			return err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			r.array = c == '['
+			if err := r.r.UnreadByte(); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	r.limit.r = r.r
+	r.limit.reset(0)
+	r.dec = json.NewDecoder(&r.limit)
+	r.dec.UseNumber()
+	if r.array {
+		// Consume the opening bracket.
+		if _, err := r.dec.Token(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordError is returned for a point that could not be converted to line
+// protocol.
+type RecordError struct {
+	Record int // The number of the point in the input, starting at 1.
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("point %d: %v", e.Record, e.Err)
+}
+
+// recordLimiter limits the bytes read from r to size beyond the start of the
+// current record.
+type recordLimiter struct {
+	r    io.Reader
+	size int64
+	read int64
+	max  int64
+}
+
+// reset starts a record at offset in the input.
+func (l *recordLimiter) reset(offset int64) {
+	l.max = offset + l.size
+}
+
+func (l *recordLimiter) Read(p []byte) (int, error) {
+	if l.size > 0 {
+		if l.read >= l.max {
+			return 0, bufio.ErrTooLong
+		}
+		if int64(len(p)) > l.max-l.read {
+			p = p[:l.max-l.read]
+		}
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	return n, err
+}
+
+// point is a point as encoded in JSON.
+type point struct {
+	Measurement string                 `json:"measurement"`
+	Tags        map[string]string      `json:"tags"`
+	Fields      map[string]interface{} `json:"fields"`
+	Time        json.RawMessage        `json:"time"`
+}
+
+// appendLine appends the line protocol for p to buf. Nothing is appended if
+// p cannot be converted.
+func (p *point) appendLine(buf *bytes.Buffer, multiplier int64) error {
+	if p.Measurement == "" {
+		return errors.New("missing measurement")
+	}
+	if len(p.Fields) == 0 {
+		return errors.New("missing fields")
+	}
+
+	tagKeys := make([]string, 0, len(p.Tags))
+	for k := range p.Tags {
+		if k == "" {
+			return errors.New("empty tag key")
+		}
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+
+	fieldKeys := make([]string, 0, len(p.Fields))
+	values := make(map[string]string, len(p.Fields))
+	for k, v := range p.Fields {
+		if k == "" {
+			return errors.New("empty field key")
+		}
+		s, err := formatValue(v)
+		if err != nil {
+			return fmt.Errorf("field %q: %v", k, err)
+		}
+		fieldKeys = append(fieldKeys, k)
+		values[k] = s
+	}
+	sort.Strings(fieldKeys)
+
+	ts, err := formatTime(p.Time, multiplier)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(models.EscapeMeasurement([]byte(p.Measurement)))
+	for _, k := range tagKeys {
+		if v := p.Tags[k]; v != "" {
+			buf.WriteByte(',')
+			buf.Write(escape.Bytes([]byte(k)))
+			buf.WriteByte('=')
+			buf.Write(escape.Bytes([]byte(v)))
+		}
+	}
+	for i, k := range fieldKeys {
+		if i == 0 {
+			buf.WriteByte(' ')
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.Write(escape.Bytes([]byte(k)))
+		buf.WriteByte('=')
+		buf.WriteString(values[k])
+	}
+	if ts != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(ts)
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+// formatValue returns the decoded JSON value v as a line protocol field value.
+func formatValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil || math.IsInf(f, 0) {
+			return "", fmt.Errorf("invalid number %s", v)
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return `"` + models.EscapeStringField(v) + `"`, nil
+	case map[string]interface{}:
+		return formatTypedValue(v)
+	case nil:
+		return "", errors.New("null value")
+	default:
+		return "", errors.New("value must be a number, boolean, string or typed value")
+	}
+}
+
+// formatTypedValue returns the decoded JSON typed value v, an object with type
+// and value, as a line protocol field value.
+func formatTypedValue(v map[string]interface{}) (string, error) {
+	typ, _ := v["type"].(string)
+	switch typ {
+	case "integer", "unsigned":
+		n, ok := v["value"].(json.Number)
+		if !ok {
+			return "", fmt.Errorf("%s value must be a number", typ)
+		}
+		if typ == "integer" {
+			if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+				return "", fmt.Errorf("invalid integer %s", n)
+			}
+			return n.String() + "i", nil
+		}
+		if _, err := strconv.ParseUint(n.String(), 10, 64); err != nil {
+			return "", fmt.Errorf("invalid unsigned %s", n)
+		}
+		return n.String() + "u", nil
+	case "float":
+		if _, ok := v["value"].(json.Number); !ok {
+			return "", errors.New("float value must be a number")
+		}
+	case "boolean":
+		if _, ok := v["value"].(bool); !ok {
+			return "", errors.New("boolean value must be a boolean")
+		}
+	case "string":
+		if _, ok := v["value"].(string); !ok {
+			return "", errors.New("string value must be a string")
+		}
+	default:
+		return "", fmt.Errorf("invalid type %q; valid types are integer, unsigned, float, boolean and string", typ)
+	}
+	return formatValue(v["value"])
+}
+
+// formatTime returns the JSON time raw as nanoseconds since the epoch, or an
+// empty string if there is no time. Integer times are multiplied by
+// multiplier.
+func formatTime(raw json.RawMessage, multiplier int64) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", fmt.Errorf("invalid time %s", raw)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return "", fmt.Errorf("invalid time %q: must be RFC3339", s)
+		}
+		return strconv.FormatInt(t.UnixNano(), 10), nil
+	}
+
+	v, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid time %s: must be an integer or an RFC3339 string", raw)
+	}
+	if v > math.MaxInt64/multiplier || v < math.MinInt64/multiplier {
+		return "", fmt.Errorf("time %s out of range", raw)
+	}
+	return strconv.FormatInt(v*multiplier, 10), nil
+}
@@ -0,0 +1,150 @@
+package json2lp_test
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/json2lp"
+)
+
+func TestReader_ReadLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		precision string
+		lines     []string // Rejected points are prefixed with "error: ".
+		err       string   // The final error, if not io.EOF.
+	}{
+		{
+			name: "array",
+			json: `[
+				{"measurement": "cpu", "tags": {"host": "a b", "region": ""}, "fields": {"usage": 0.5, "ok": true, "state": "x \"y\""}, "time": "1970-01-01T00:00:01Z"},
+				{"measurement": "cpu", "fields": {"usage": 1}}
+			]`,
+			lines: []string{
+				`cpu,host=a\ b ok=true,state="x \"y\"",usage=0.5 1000000000` + "\n",
+				"cpu usage=1\n",
+			},
+		},
+		{
+			name:      "stream with precision",
+			json:      "{\"measurement\": \"m\", \"fields\": {\"f\": 1}, \"time\": 2}\n{\"measurement\": \"m\", \"fields\": {\"f\": 2}, \"time\": 3}\n",
+			precision: "s",
+			lines: []string{
+				"m f=1 2000000000\n",
+				"m f=2 3000000000\n",
+			},
+		},
+		{
+			name: "empty",
+			json: " \n",
+		},
+		{
+			name: "rejected points",
+			json: `[
+				{"fields": {"f": 1}},
+				{"measurement": "m"},
+				{"measurement": "m", "fields": {"f": null}},
+				{"measurement": "m", "tags": {"t": 1}, "fields": {"f": 1}},
+				{"measurement": "m", "fields": {"f": 1}, "time": "yesterday"},
+				3,
+				{"measurement": "m", "fields": {"f": 6}}
+			]`,
+			lines: []string{
+				"error: point 1: missing measurement",
+				"error: point 2: missing fields",
+				`error: point 3: field "f": null value`,
+				"error: point 4: invalid tags.t: unexpected number",
+				`error: point 5: invalid time "yesterday": must be RFC3339`,
+				"error: point 6: point must be an object, got number",
+				"m f=6\n",
+			},
+		},
+		{
+			name: "typed values",
+			json: `[
+				{"measurement": "m", "fields": {"i": {"type": "integer", "value": -9223372036854775808}, "u": {"type": "unsigned", "value": 18446744073709551615}, "f": {"type": "float", "value": 1}, "b": {"type": "boolean", "value": false}, "s": {"type": "string", "value": "1"}}},
+				{"measurement": "m", "fields": {"i": {"type": "integer", "value": 1.5}}},
+				{"measurement": "m", "fields": {"u": {"type": "unsigned", "value": -1}}},
+				{"measurement": "m", "fields": {"s": {"type": "string", "value": 1}}},
+				{"measurement": "m", "fields": {"t": {"type": "time", "value": 1}}}
+			]`,
+			lines: []string{
+				`m b=false,f=1,i=-9223372036854775808i,s="1",u=18446744073709551615u` + "\n",
+				`error: point 2: field "i": invalid integer 1.5`,
+				`error: point 3: field "u": invalid unsigned -1`,
+				`error: point 4: field "s": string value must be a string`,
+				`error: point 5: field "t": invalid type "time"; valid types are integer, unsigned, float, boolean and string`,
+			},
+		},
+		{
+			name:  "unterminated array",
+			json:  `[{"measurement": "m", "fields": {"f": 1}}`,
+			lines: []string{"m f=1\n"},
+			err:   "unexpected end of JSON input",
+		},
+		{
+			name: "malformed",
+			json: `{"measurement": "m", "fields": {"f": 1}} {"measurement"`,
+			lines: []string{
+				"m f=1\n",
+			},
+			err: "unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			precision := tt.precision
+			if precision == "" {
+				precision = "ns"
+			}
+			r := json2lp.NewReader(strings.NewReader(tt.json), precision)
+
+			var got []string
+			var err error
+			for {
+				var line []byte
+				line, _, err = r.ReadLine()
+				if e, ok := err.(*json2lp.RecordError); ok {
+					got = append(got, "error: "+e.Error())
+					continue
+				} else if err != nil {
+					break
+				}
+				got = append(got, string(line))
+			}
+
+			if tt.err == "" && err != io.EOF {
+				t.Fatalf("unexpected error %v", err)
+			} else if tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Fatalf("got error %v, exp %s", err, tt.err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.lines, "|") {
+				t.Fatalf("unexpected lines:\ngot: %q\nexp: %q", got, tt.lines)
+			}
+		})
+	}
+}
+
+func TestReader_SetMaxRecordSize(t *testing.T) {
+	point := `{"measurement": "m", "fields": {"f": 1}}`
+	for _, json := range []string{
+		"[" + point + ", " + point + ", {\"measurement\": \"" + strings.Repeat("m", 100) + "\"}]",
+		point + "\n" + point + "\n{\"measurement\": \"" + strings.Repeat("m", 100) + "\"}\n",
+	} {
+		r := json2lp.NewReader(strings.NewReader(json), "ns")
+		r.SetMaxRecordSize(len(point) + 2)
+
+		for i := 1; i <= 2; i++ {
+			if line, n, err := r.ReadLine(); err != nil || n != i || string(line) != "m f=1\n" {
+				t.Fatalf("point %d: got %q, %d, %v", i, line, n, err)
+			}
+		}
+		if _, n, err := r.ReadLine(); err != bufio.ErrTooLong || n != 3 {
+			t.Fatalf("got point %d, %v, want point 3 to be too long", n, err)
+		}
+	}
+}
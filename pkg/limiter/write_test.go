@@ -2,6 +2,7 @@ package limiter_test
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"testing"
 	"time"
@@ -28,7 +29,51 @@ func TestWriter_Limited(t *testing.T) {
 	}
 }
 
+func TestWriter_LargerThanBurst(t *testing.T) {
+	// Writes larger than the burst, such as a block written with a
+	// throughput below the block size, must be limited rather than fail.
+	limit := 64 * 1024
+	w := limiter.NewWriter(discardCloser{}, limit, 0)
+
+	start := time.Now()
+	n, err := w.Write(make([]byte, 2*limit))
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal("write error: ", err)
+	}
+	if n != 2*limit {
+		t.Fatalf("unexpected bytes written: exp %d, got %d", 2*limit, n)
+	}
+
+	rate := float64(n) / elapsed.Seconds()
+	if rate > float64(limit) {
+		t.Errorf("rate limit mismath: exp %f, got %f", float64(limit), rate)
+	}
+}
+
 type discardCloser struct{}
 
 func (d discardCloser) Write(b []byte) (int, error) { return len(b), nil }
 func (d discardCloser) Close() error                { return nil }
+
+func TestUpdateRate(t *testing.T) {
+	r := limiter.NewRate(1, 1)
+	if err := limiter.UpdateRate(r, 0, 1024); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// With the limit removed a large write must not block.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.WaitN(ctx, 512); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := limiter.UpdateRate(rateFunc(nil), 1, 1); err == nil {
+		t.Fatal("expected error updating unsupported rate")
+	}
+}
+
+type rateFunc func(ctx context.Context, n int) error
+
+func (fn rateFunc) WaitN(ctx context.Context, n int) error { return fn(ctx, n) }
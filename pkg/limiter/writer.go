@@ -2,8 +2,10 @@ package limiter
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -20,9 +22,71 @@ type Rate interface {
 }
 
 func NewRate(bytesPerSec, burstLimit int) Rate {
-	limiter := rate.NewLimiter(rate.Limit(bytesPerSec), burstLimit)
+	r := &adjustableRate{}
+	r.set(bytesPerSec, burstLimit)
+	return r
+}
+
+// UpdateRate changes the rate and burst limit of a Rate returned by NewRate.
+// A bytesPerSec value of zero removes the rate limit entirely. It returns an
+// error if r does not support being updated.
+func UpdateRate(r Rate, bytesPerSec, burstLimit int) error {
+	ar, ok := r.(*adjustableRate)
+	if !ok {
+		return fmt.Errorf("rate limiter %T does not support updates", r)
+	}
+	ar.set(bytesPerSec, burstLimit)
+	return nil
+}
+
+// adjustableRate is a token bucket rate limiter whose limits can be changed
+// while it is in use.
+type adjustableRate struct {
+	mu      sync.RWMutex
+	limiter *rate.Limiter
+}
+
+func (r *adjustableRate) set(bytesPerSec, burstLimit int) {
+	limit := rate.Limit(bytesPerSec)
+	if bytesPerSec <= 0 {
+		limit = rate.Inf
+	}
+	if burstLimit <= 0 {
+		burstLimit = bytesPerSec
+	}
+	if burstLimit <= 0 {
+		burstLimit = 1
+	}
+
+	limiter := rate.NewLimiter(limit, burstLimit)
 	limiter.AllowN(time.Now(), burstLimit) // spend initial burst
-	return limiter
+
+	r.mu.Lock()
+	r.limiter = limiter
+	r.mu.Unlock()
+}
+
+// WaitN blocks until n bytes may be written. Writes larger than the burst
+// limit wait for the burst limit at a time, so that they are rate limited
+// rather than rejected.
+func (r *adjustableRate) WaitN(ctx context.Context, n int) error {
+	r.mu.RLock()
+	limiter := r.limiter
+	r.mu.RUnlock()
+
+	if limiter.Limit() == rate.Inf {
+		return limiter.WaitN(ctx, n)
+	}
+	for burst := limiter.Burst(); n > 0; n -= burst {
+		m := n
+		if m > burst {
+			m = burst
+		}
+		if err := limiter.WaitN(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // NewWriter returns a writer that implements io.Writer with rate limiting.
@@ -42,6 +42,10 @@ func (a *authzSvc) UpdateAuthorization(context.Context, platform.ID, *platform.A
 	return nil, a.Err
 }
 
+func (a *authzSvc) RotateAuthorization(context.Context, platform.ID) (*platform.Authorization, error) {
+	return nil, a.Err
+}
+
 func TestAuthorizationService_Metrics(t *testing.T) {
 	a := new(authzSvc)
 
@@ -0,0 +1,4 @@
+// Package prompb contains the protocol buffers of Prometheus remote storage.
+package prompb
+
+//go:generate protoc -I ../../internal -I . --plugin ../../scripts/protoc-gen-gogofaster --gogofaster_out=. types.proto remote.proto
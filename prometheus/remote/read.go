@@ -0,0 +1,266 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/prometheus/prompb"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+// ErrSampleLimit is returned when reading more samples than allowed.
+var ErrSampleLimit = errors.New("sample limit exceeded")
+
+// ReadFilterRequest returns the storage read of the series matching q in
+// source. Label matchers become a predicate on the tags of the series, and
+// __name__ matchers a predicate on their measurement.
+func ReadFilterRequest(q *prompb.Query, source *types.Any) (*datatypes.ReadFilterRequest, error) {
+	root, err := seriesNode(q.Matchers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &datatypes.ReadFilterRequest{
+		ReadSource: source,
+		Range: datatypes.TimestampRange{
+			Start: q.StartTimestampMs * int64(time.Millisecond),
+			End:   q.EndTimestampMs * int64(time.Millisecond),
+		},
+		Predicate: &datatypes.Predicate{Root: root},
+	}, nil
+}
+
+// SeriesPredicate returns the storage predicate of the series matching any
+// of the sets of matchers, or of all series if there are none.
+func SeriesPredicate(matchers ...[]*prompb.LabelMatcher) (*datatypes.Predicate, error) {
+	var root *datatypes.Node
+	for _, ms := range matchers {
+		n, err := seriesNode(ms)
+		if err != nil {
+			return nil, err
+		}
+		if root == nil {
+			root = n
+			continue
+		}
+		root = &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalOr},
+			Children: []*datatypes.Node{root, n},
+		}
+	}
+	if root == nil {
+		// Only the value field holds samples.
+		root = comparison(datatypes.ComparisonEqual, models.FieldKeyTagKey, stringLiteral(FieldKey))
+	}
+	return &datatypes.Predicate{Root: root}, nil
+}
+
+// seriesNode returns the predicate node of the series matching all of
+// matchers.
+func seriesNode(matchers []*prompb.LabelMatcher) (*datatypes.Node, error) {
+	// Only the value field holds samples.
+	root := comparison(datatypes.ComparisonEqual, models.FieldKeyTagKey, stringLiteral(FieldKey))
+	for _, m := range matchers {
+		n, err := matcherToNode(m)
+		if err != nil {
+			return nil, err
+		}
+		root = &datatypes.Node{
+			NodeType: datatypes.NodeTypeLogicalExpression,
+			Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+			Children: []*datatypes.Node{root, n},
+		}
+	}
+	return root, nil
+}
+
+// TagKey returns the key of the label name in the tags of series read from
+// storage, as in the tag keys and values reads.
+func TagKey(name string) string {
+	if name == MetricNameLabel {
+		return measurementKey
+	}
+	return name
+}
+
+// LabelName returns the label name for the tag key of a series in storage,
+// and false for the key of the field, which is not a label.
+func LabelName(key string) (string, bool) {
+	switch key {
+	case models.MeasurementTagKey, measurementKey:
+		return MetricNameLabel, true
+	case models.FieldKeyTagKey, fieldKey:
+		return "", false
+	default:
+		return key, true
+	}
+}
+
+func matcherToNode(m *prompb.LabelMatcher) (*datatypes.Node, error) {
+	key := m.Name
+	if key == MetricNameLabel {
+		key = models.MeasurementTagKey
+	}
+
+	switch m.Type {
+	case prompb.LabelMatcher_EQ:
+		return comparison(datatypes.ComparisonEqual, key, stringLiteral(m.Value)), nil
+	case prompb.LabelMatcher_NEQ:
+		return comparison(datatypes.ComparisonNotEqual, key, stringLiteral(m.Value)), nil
+	case prompb.LabelMatcher_RE, prompb.LabelMatcher_NRE:
+		// Prometheus regular expressions match whole label values.
+		re := "^(?:" + m.Value + ")$"
+		if _, err := regexp.Compile(re); err != nil {
+			return nil, fmt.Errorf("invalid regular expression for label %s: %v", m.Name, err)
+		}
+		op := datatypes.ComparisonRegex
+		if m.Type == prompb.LabelMatcher_NRE {
+			op = datatypes.ComparisonNotRegex
+		}
+		return comparison(op, key, &datatypes.Node{
+			NodeType: datatypes.NodeTypeLiteral,
+			Value:    &datatypes.Node_RegexValue{RegexValue: re},
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown label matcher type %d", m.Type)
+	}
+}
+
+func comparison(op datatypes.Node_Comparison, key string, value *datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: op},
+		Children: []*datatypes.Node{
+			{
+				NodeType: datatypes.NodeTypeTagRef,
+				Value:    &datatypes.Node_TagRefValue{TagRefValue: key},
+			},
+			value,
+		},
+	}
+}
+
+func stringLiteral(s string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value:    &datatypes.Node_StringValue{StringValue: s},
+	}
+}
+
+// ReadQueryResult returns the time series read by rs, failing with
+// ErrSampleLimit if there are more than maxSamples samples. Integer values are
+// returned as floats, and series of other types are skipped.
+func ReadQueryResult(rs reads.ResultSet, maxSamples int) (*prompb.QueryResult, error) {
+	res := &prompb.QueryResult{}
+	if rs == nil {
+		return res, nil
+	}
+	defer rs.Close()
+
+	samples := 0
+	for rs.Next() {
+		ts := &prompb.TimeSeries{Labels: tagsToLabels(rs.Tags())}
+
+		cur := rs.Cursor()
+		if cur == nil {
+			continue
+		}
+		err := readSamples(cur, func(t int64, v float64) error {
+			samples++
+			if samples > maxSamples {
+				return ErrSampleLimit
+			}
+			ts.Samples = append(ts.Samples, prompb.Sample{
+				Timestamp: t / int64(time.Millisecond),
+				Value:     v,
+			})
+			return nil
+		})
+		cur.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ts.Samples) > 0 {
+			res.Timeseries = append(res.Timeseries, ts)
+		}
+	}
+	return res, rs.Err()
+}
+
+// ReadSeriesLabels returns the labels of each series read by rs, without
+// reading their samples.
+func ReadSeriesLabels(rs reads.ResultSet) ([][]prompb.Label, error) {
+	if rs == nil {
+		return nil, nil
+	}
+	defer rs.Close()
+
+	var series [][]prompb.Label
+	for rs.Next() {
+		series = append(series, tagsToLabels(rs.Tags()))
+	}
+	return series, rs.Err()
+}
+
+// readSamples calls fn with each value read from cur.
+func readSamples(cur cursors.Cursor, fn func(t int64, v float64) error) error {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, t := range a.Timestamps {
+				if err := fn(t, a.Values[i]); err != nil {
+					return err
+				}
+			}
+		}
+	case cursors.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, t := range a.Timestamps {
+				if err := fn(t, float64(a.Values[i])); err != nil {
+					return err
+				}
+			}
+		}
+	case cursors.UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, t := range a.Timestamps {
+				if err := fn(t, float64(a.Values[i])); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return cur.Err()
+}
+
+// Keys of the measurement and field of series read from storage.
+const (
+	measurementKey = "_measurement"
+	fieldKey       = "_field"
+)
+
+// tagsToLabels returns the labels of the series with tags, naming it after
+// its measurement.
+func tagsToLabels(tags models.Tags) []prompb.Label {
+	labels := make([]prompb.Label, 0, len(tags))
+	for _, t := range tags {
+		switch string(t.Key) {
+		case measurementKey:
+			labels = append(labels, prompb.Label{Name: MetricNameLabel, Value: string(t.Value)})
+		case fieldKey:
+		default:
+			labels = append(labels, prompb.Label{Name: string(t.Key), Value: string(t.Value)})
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
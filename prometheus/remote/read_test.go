@@ -0,0 +1,146 @@
+package remote_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/prometheus/prompb"
+	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+func TestReadFilterRequest(t *testing.T) {
+	var req prompb.ReadRequest
+	readFixture(t, "read_request.pb.snappy", &req)
+	if len(req.Queries) != 1 {
+		t.Fatalf("expected 1 query, got %d", len(req.Queries))
+	}
+
+	source := &types.Any{TypeUrl: "source"}
+	rfr, err := remote.ReadFilterRequest(req.Queries[0], source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rfr.ReadSource != source {
+		t.Errorf("expected read source %v, got %v", source, rfr.ReadSource)
+	}
+	if got, exp := rfr.Range.Start, int64(1571486400000000000); got != exp {
+		t.Errorf("expected start %d, got %d", exp, got)
+	}
+	if got, exp := rfr.Range.End, int64(1571486700000000000); got != exp {
+		t.Errorf("expected end %d, got %d", exp, got)
+	}
+
+	// The measurement and field tag keys are not printable.
+	got := strings.NewReplacer(models.MeasurementTagKey, "_m", models.FieldKeyTagKey, "_f").Replace(reads.PredicateToExprString(rfr.Predicate))
+	exp := `'_f' = "value" AND '_m' = "http_requests_total" AND 'code' =~ /^(?:2..)$/ AND 'job' != "node"`
+	if got != exp {
+		t.Errorf("unexpected predicate:\ngot: %s\nexp: %s", got, exp)
+	}
+}
+
+func TestReadFilterRequest_InvalidRegex(t *testing.T) {
+	q := &prompb.Query{
+		Matchers: []*prompb.LabelMatcher{{Type: prompb.LabelMatcher_RE, Name: "job", Value: "("}},
+	}
+	if _, err := remote.ReadFilterRequest(q, nil); err == nil {
+		t.Fatal("expected an error for an invalid regular expression")
+	}
+}
+
+func TestSeriesPredicate(t *testing.T) {
+	pred, err := remote.SeriesPredicate(
+		[]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_EQ, Name: "__name__", Value: "up"}},
+		[]*prompb.LabelMatcher{{Type: prompb.LabelMatcher_NEQ, Name: "job", Value: "node"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.NewReplacer(models.MeasurementTagKey, "_m", models.FieldKeyTagKey, "_f").Replace(reads.PredicateToExprString(pred))
+	exp := `'_f' = "value" AND '_m' = "up" OR '_f' = "value" AND 'job' != "node"`
+	if got != exp {
+		t.Errorf("unexpected predicate:\ngot: %s\nexp: %s", got, exp)
+	}
+}
+
+func TestReadQueryResult(t *testing.T) {
+	newResultSet := func() reads.ResultSet {
+		return &sliceResultSet{series: []series{
+			{
+				tags: models.NewTags(map[string]string{"_measurement": "up", "_field": "value", "job": "node"}),
+				cur:  &floatCursor{ts: []int64{1e9, 2e9}, vs: []float64{1, 0}},
+			},
+			{
+				tags: models.NewTags(map[string]string{"_measurement": "up", "_field": "value", "job": "prometheus"}),
+				cur:  &floatCursor{},
+			},
+		}}
+	}
+
+	res, err := remote.ReadQueryResult(newResultSet(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &prompb.QueryResult{
+		Timeseries: []*prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "up"}, {Name: "job", Value: "node"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1000}, {Value: 0, Timestamp: 2000}},
+		}},
+	}
+	if !reflect.DeepEqual(res, exp) {
+		t.Errorf("unexpected result:\ngot: %v\nexp: %v", res, exp)
+	}
+
+	if _, err := remote.ReadQueryResult(newResultSet(), 1); err != remote.ErrSampleLimit {
+		t.Errorf("expected sample limit error, got %v", err)
+	}
+}
+
+type series struct {
+	tags models.Tags
+	cur  cursors.Cursor
+}
+
+// sliceResultSet is a reads.ResultSet of a fixed set of series.
+type sliceResultSet struct {
+	series []series
+	i      int
+}
+
+func (rs *sliceResultSet) Next() bool {
+	rs.i++
+	return rs.i <= len(rs.series)
+}
+
+func (rs *sliceResultSet) Cursor() cursors.Cursor { return rs.series[rs.i-1].cur }
+func (rs *sliceResultSet) Tags() models.Tags      { return rs.series[rs.i-1].tags }
+func (rs *sliceResultSet) Close()                 {}
+func (rs *sliceResultSet) Err() error             { return nil }
+func (rs *sliceResultSet) Stats() cursors.CursorStats {
+	return cursors.CursorStats{}
+}
+
+// floatCursor returns all its values in a single array.
+type floatCursor struct {
+	ts   []int64
+	vs   []float64
+	done bool
+}
+
+func (c *floatCursor) Next() *cursors.FloatArray {
+	if c.done {
+		return &cursors.FloatArray{}
+	}
+	c.done = true
+	return &cursors.FloatArray{Timestamps: c.ts, Values: c.vs}
+}
+
+func (c *floatCursor) Close()                     {}
+func (c *floatCursor) Err() error                 { return nil }
+func (c *floatCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
@@ -0,0 +1,59 @@
+// Package remote converts between Prometheus remote storage requests and
+// InfluxDB points and storage reads.
+//
+// A Prometheus time series is stored as a series of the measurement named by
+// its __name__ label, with the value field holding its samples and its other
+// labels as tags.
+package remote
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/prometheus/prompb"
+)
+
+const (
+	// MetricNameLabel is the label holding the name of a time series.
+	MetricNameLabel = "__name__"
+
+	// FieldKey is the field holding the values of samples.
+	FieldKey = "value"
+)
+
+// WriteRequestToPoints converts the samples of req to points. Samples that
+// are NaN or infinite, such as the markers Prometheus writes for stale series,
+// have no line protocol representation and are dropped.
+func WriteRequestToPoints(req *prompb.WriteRequest) ([]models.Point, error) {
+	var points []models.Point
+	for _, ts := range req.Timeseries {
+		var name string
+		tags := make(map[string]string, len(ts.Labels))
+		for _, l := range ts.Labels {
+			if l.Name == MetricNameLabel {
+				name = l.Value
+			} else if l.Value != "" {
+				tags[l.Name] = l.Value
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("time series is missing the %s label", MetricNameLabel)
+		}
+
+		t := models.NewTags(tags)
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+				continue
+			}
+
+			p, err := models.NewPoint(name, t, models.Fields{FieldKey: s.Value}, time.Unix(0, s.Timestamp*int64(time.Millisecond)))
+			if err != nil {
+				return nil, fmt.Errorf("time series %s: %v", name, err)
+			}
+			points = append(points, p)
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,64 @@
+package remote_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/prometheus/prompb"
+	"github.com/influxdata/influxdb/prometheus/remote"
+)
+
+// readFixture decodes the snappy compressed protocol buffer in testdata/name
+// into m.
+func readFixture(t *testing.T, name string, m interface{ Unmarshal([]byte) error }) {
+	t.Helper()
+	compressed, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriteRequestToPoints(t *testing.T) {
+	var req prompb.WriteRequest
+	readFixture(t, "write_request.pb.snappy", &req)
+
+	points, err := remote.WriteRequestToPoints(&req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The staleness marker of the up series is dropped.
+	exp := []string{
+		"http_requests_total,code=200,instance=localhost:9090,job=prometheus value=1027 1571486400000000000",
+		"http_requests_total,code=200,instance=localhost:9090,job=prometheus value=1043 1571486415000000000",
+		"up,instance=localhost:9100,job=node value=1 1571486400000000000",
+	}
+	if len(points) != len(exp) {
+		t.Fatalf("expected %d points, got %d: %v", len(exp), len(points), points)
+	}
+	for i, p := range points {
+		if got := p.String(); got != exp[i] {
+			t.Errorf("point %d: got %s, exp %s", i, got, exp[i])
+		}
+	}
+}
+
+func TestWriteRequestToPoints_MissingName(t *testing.T) {
+	req := &prompb.WriteRequest{
+		Timeseries: []prompb.TimeSeries{{
+			Labels:  []prompb.Label{{Name: "job", Value: "node"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: 1}},
+		}},
+	}
+	if _, err := remote.WriteRequestToPoints(req); err == nil {
+		t.Fatal("expected an error for a time series without a name")
+	}
+}
@@ -0,0 +1,43 @@
+package query
+
+import (
+	"time"
+
+	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
+)
+
+// ActiveQuery describes a query that is running.
+type ActiveQuery struct {
+	ID             platform.ID       `json:"id"`
+	OrganizationID platform.ID       `json:"orgID"`
+	UserID         platform.ID       `json:"userID,omitempty"`
+	Source         string            `json:"source,omitempty"`
+	Type           flux.CompilerType `json:"type"`
+	// Query is the text of the query, if it has one.
+	Query string `json:"query,omitempty"`
+	// State is the stage of the query, such as queueing or executing.
+	State     string        `json:"state"`
+	StartTime time.Time     `json:"startTime"`
+	Elapsed   time.Duration `json:"elapsed"`
+	// MemoryBytes is the most memory the query has allocated so far.
+	MemoryBytes int64 `json:"memoryBytes"`
+}
+
+// ActiveQueryFilter selects the queries that are running for an
+// organization or user.
+type ActiveQueryFilter struct {
+	OrganizationID *platform.ID
+	UserID         *platform.ID
+}
+
+// Match reports whether q is selected by the filter.
+func (f ActiveQueryFilter) Match(q *ActiveQuery) bool {
+	if f.OrganizationID != nil && *f.OrganizationID != q.OrganizationID {
+		return false
+	}
+	if f.UserID != nil && *f.UserID != q.UserID {
+		return false
+	}
+	return true
+}
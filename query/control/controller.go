@@ -3,6 +3,9 @@ package control
 
 import (
 	"context"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/control"
@@ -16,16 +19,38 @@ import (
 // orgLabel is the metric label to use in the controller
 const orgLabel = "org"
 
+var (
+	_ query.AsyncQueryService  = (*Controller)(nil)
+	_ query.ActiveQueryService = (*Controller)(nil)
+)
+
 // Controller implements AsyncQueryService by consuming a control.Controller.
+// It also implements ActiveQueryService, with the requests of the queries
+// that are running.
 type Controller struct {
 	c *control.Controller
+
+	mu       sync.RWMutex
+	requests map[control.QueryID]*activeRequest
+
+	now func() time.Time
+}
+
+// activeRequest is the request of a query that is running.
+type activeRequest struct {
+	req   *query.Request
+	start time.Time
 }
 
 // NewController creates a new Controller specific to platform.
 func New(config control.Config) *Controller {
 	config.MetricLabelKeys = append(config.MetricLabelKeys, orgLabel)
 	c := control.New(config)
-	return &Controller{c: c}
+	return &Controller{
+		c:        c,
+		requests: make(map[control.QueryID]*activeRequest),
+		now:      time.Now,
+	}
 }
 
 // Query satisfies the AsyncQueryService while ensuring the request is propagated on the context.
@@ -37,6 +62,7 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 	ctx = query.ContextWithRequest(ctx, req)
 	// Set the org label value for controller metrics
 	ctx = context.WithValue(ctx, orgLabel, req.OrganizationID.String())
+	start := c.now()
 	q, err := c.c.Query(ctx, req.Compiler)
 	if err != nil {
 		// If the controller reports an error, it's usually because of a syntax error
@@ -47,7 +73,90 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 		}
 	}
 
-	return q, nil
+	cq, ok := q.(*control.Query)
+	if !ok {
+		return q, nil
+	}
+	c.mu.Lock()
+	c.requests[cq.ID()] = &activeRequest{req: req, start: start}
+	c.mu.Unlock()
+	return &trackedQuery{Query: cq, c: c}, nil
+}
+
+// trackedQuery forgets the request of a query once it is done.
+type trackedQuery struct {
+	*control.Query
+	c *Controller
+}
+
+func (q *trackedQuery) Done() {
+	q.Query.Done()
+
+	q.c.mu.Lock()
+	delete(q.c.requests, q.ID())
+	q.c.mu.Unlock()
+}
+
+// FindActiveQueries returns the running queries that match the filter,
+// ordered by ID.
+func (c *Controller) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	now := c.now()
+	queries := []*query.ActiveQuery{}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, q := range c.c.Queries() {
+		ar, ok := c.requests[q.ID()]
+		if !ok {
+			continue
+		}
+
+		aq := &query.ActiveQuery{
+			ID:             platform.ID(q.ID()),
+			OrganizationID: ar.req.OrganizationID,
+			Source:         ar.req.Source,
+			Type:           ar.req.Compiler.CompilerType(),
+			Query:          query.CompilerQuery(ar.req.Compiler),
+			State:          q.State().String(),
+			StartTime:      ar.start,
+			Elapsed:        now.Sub(ar.start),
+			MemoryBytes:    q.Statistics().MaxAllocated,
+		}
+		if ar.req.Authorization != nil {
+			aq.UserID = ar.req.Authorization.UserID
+		}
+		if filter.Match(aq) {
+			queries = append(queries, aq)
+		}
+	}
+
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].ID < queries[j].ID
+	})
+	return queries, nil
+}
+
+// CancelActiveQuery cancels the running query with the id.
+func (c *Controller) CancelActiveQuery(ctx context.Context, id platform.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, q := range c.c.Queries() {
+		if _, ok := c.requests[q.ID()]; ok && platform.ID(q.ID()) == id {
+			q.Cancel()
+			return nil
+		}
+	}
+	return &platform.Error{
+		Code: platform.ENotFound,
+		Op:   "query/CancelActiveQuery",
+		Msg:  "query not found",
+	}
 }
 
 // PrometheusCollectors satisifies the prom.PrometheusCollector interface.
@@ -0,0 +1,294 @@
+package encoding
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/flux/values"
+)
+
+// The metadata keys of Arrow schemas and fields.
+const (
+	resultKey = "result"
+	tableKey  = "table"
+	errorKey  = "error"
+	groupKey  = "group"
+)
+
+// arrowMultiResultEncoder encodes each table as an Apache Arrow IPC stream,
+// one after another. The metadata of the schema of a stream has the name of
+// the result and the index of the table in it, and the metadata of each
+// field is whether the column is part of the group key. Times are
+// timestamps in nanoseconds. The columns of tables are already Arrow arrays,
+// so they are written as they are.
+//
+// If the query fails, the last stream has no fields or records and the
+// error in its metadata.
+type arrowMultiResultEncoder struct{}
+
+func (e *arrowMultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	wc := &iocounter.Writer{Writer: w}
+
+	var err error
+	for err == nil && results.More() {
+		res := results.Next()
+		n := 0
+		err = res.Tables().Do(func(tbl flux.Table) error {
+			if tbl.Empty() {
+				return tbl.Do(func(flux.ColReader) error { return nil })
+			}
+			schema, err := arrowSchema(res.Name(), n, tbl)
+			if err != nil {
+				return &encoderError{err: err}
+			}
+			n++
+			return encodeArrowTable(wc, schema, tbl)
+		})
+		flush(w)
+	}
+	if err == nil {
+		err = results.Err()
+	}
+	if err != nil && flux.IsEncoderError(err) {
+		return wc.Count(), err
+	}
+
+	if err != nil {
+		md := arrow.NewMetadata([]string{errorKey}, []string{err.Error()})
+		aw := ipc.NewWriter(wc, ipc.WithSchema(arrow.NewSchema(nil, &md)))
+		if err := aw.Close(); err != nil {
+			return wc.Count(), &encoderError{err: err}
+		}
+	}
+	return wc.Count(), nil
+}
+
+// encodeArrowTable writes tbl as a stream. The stream is ended even if
+// reading the table fails, so that the error may follow it.
+func encodeArrowTable(w io.Writer, schema *arrow.Schema, tbl flux.Table) error {
+	aw := ipc.NewWriter(w, ipc.WithSchema(schema))
+	err := tbl.Do(func(cr flux.ColReader) error {
+		rec := arrowRecord(schema, cr)
+		defer rec.Release()
+		if err := aw.Write(rec); err != nil {
+			return &encoderError{err: err}
+		}
+		return nil
+	})
+	if cerr := aw.Close(); cerr != nil && err == nil {
+		err = &encoderError{err: cerr}
+	}
+	return err
+}
+
+func arrowSchema(name string, table int, tbl flux.Table) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(tbl.Cols()))
+	for j, c := range tbl.Cols() {
+		typ, err := arrowType(c.Type)
+		if err != nil {
+			return nil, err
+		}
+		md := arrow.NewMetadata([]string{groupKey}, []string{strconv.FormatBool(tbl.Key().HasCol(c.Label))})
+		fields[j] = arrow.Field{Name: c.Label, Type: typ, Nullable: true, Metadata: md}
+	}
+	md := arrow.NewMetadata([]string{resultKey, tableKey}, []string{name, strconv.Itoa(table)})
+	return arrow.NewSchema(fields, &md), nil
+}
+
+func arrowType(typ flux.ColType) (arrow.DataType, error) {
+	switch typ {
+	case flux.TBool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case flux.TInt:
+		return arrow.PrimitiveTypes.Int64, nil
+	case flux.TUInt:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case flux.TFloat:
+		return arrow.PrimitiveTypes.Float64, nil
+	case flux.TString:
+		return arrow.BinaryTypes.String, nil
+	case flux.TTime:
+		return &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}, nil
+	default:
+		return nil, fmt.Errorf("unknown column type %v", typ)
+	}
+}
+
+func fluxType(typ arrow.DataType) (flux.ColType, error) {
+	switch typ.ID() {
+	case arrow.BOOL:
+		return flux.TBool, nil
+	case arrow.INT64:
+		return flux.TInt, nil
+	case arrow.UINT64:
+		return flux.TUInt, nil
+	case arrow.FLOAT64:
+		return flux.TFloat, nil
+	case arrow.STRING, arrow.BINARY:
+		return flux.TString, nil
+	case arrow.TIMESTAMP:
+		if ts := typ.(*arrow.TimestampType); ts.Unit == arrow.Nanosecond {
+			return flux.TTime, nil
+		}
+	}
+	return flux.TInvalid, fmt.Errorf("unsupported arrow type %v", typ)
+}
+
+// arrowRecord returns the columns of cr as a record of schema. Strings and
+// times share the buffers of the columns, with the types of the schema.
+func arrowRecord(schema *arrow.Schema, cr flux.ColReader) array.Record {
+	cols := make([]array.Interface, len(cr.Cols()))
+	for j, c := range cr.Cols() {
+		var data *array.Data
+		switch c.Type {
+		case flux.TBool:
+			data = cr.Bools(j).Data()
+		case flux.TInt:
+			data = cr.Ints(j).Data()
+		case flux.TUInt:
+			data = cr.UInts(j).Data()
+		case flux.TFloat:
+			data = cr.Floats(j).Data()
+		case flux.TString:
+			data = cr.Strings(j).Data()
+		case flux.TTime:
+			data = cr.Times(j).Data()
+		}
+		data = array.NewData(schema.Field(j).Type, data.Len(), data.Buffers(), nil, data.NullN(), data.Offset())
+		cols[j] = array.MakeFromData(data)
+		data.Release()
+	}
+
+	rec := array.NewRecord(schema, cols, int64(cr.Len()))
+	for _, col := range cols {
+		col.Release()
+	}
+	return rec
+}
+
+// arrowMultiResultDecoder decodes results in the Arrow format.
+type arrowMultiResultDecoder struct{}
+
+func (d *arrowMultiResultDecoder) Decode(r io.ReadCloser) (flux.ResultIterator, error) {
+	defer r.Close()
+
+	var rb resultsBuilder
+	itr := &resultIterator{}
+	br := bufio.NewReader(r)
+	for {
+		if _, err := br.Peek(1); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		ar, err := ipc.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		md := ar.Schema().Metadata()
+		if i := md.FindKey(errorKey); i >= 0 {
+			// Read to the end of the stream, which has no records.
+			for ar.Next() {
+			}
+			ar.Release()
+			itr.err = errors.New(md.Values()[i])
+			continue
+		}
+
+		tbl, err := decodeArrowTable(ar)
+		ar.Release()
+		if err != nil {
+			return nil, err
+		}
+		name := ""
+		if i := md.FindKey(resultKey); i >= 0 {
+			name = md.Values()[i]
+		}
+		rb.add(name, tbl)
+	}
+
+	itr.results = rb.results
+	return itr, nil
+}
+
+// decodeArrowTable returns the table of the records of a stream.
+func decodeArrowTable(ar *ipc.Reader) (flux.Table, error) {
+	fields := ar.Schema().Fields()
+	cols := make([]column, len(fields))
+	metas := make([]flux.ColMeta, len(fields))
+	for j, f := range fields {
+		typ, err := fluxType(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		metas[j] = flux.ColMeta{Label: f.Name, Type: typ}
+		cols[j] = column{Label: f.Name}
+		if i := f.Metadata.FindKey(groupKey); i >= 0 {
+			cols[j].Group = f.Metadata.Values()[i] == "true"
+		}
+	}
+
+	var b *execute.ColListTableBuilder
+	for ar.Next() {
+		rec := ar.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			row := make([]values.Value, len(metas))
+			for j, c := range metas {
+				row[j] = arrowValue(rec.Column(j), c.Type, i)
+			}
+			if b == nil {
+				var err error
+				if b, err = newTableBuilder(cols, metas, row); err != nil {
+					return nil, err
+				}
+			}
+			for j, v := range row {
+				if err := b.AppendValue(j, v); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := ar.Err(); err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, errors.New("table has no records")
+	}
+	return b.Table()
+}
+
+// arrowValue returns the value in row i of arr, a column of type typ.
+func arrowValue(arr array.Interface, typ flux.ColType, i int) values.Value {
+	if arr.IsNull(i) {
+		return values.NewNull(flux.SemanticType(typ))
+	}
+	switch arr := arr.(type) {
+	case *array.Boolean:
+		return values.NewBool(arr.Value(i))
+	case *array.Int64:
+		return values.NewInt(arr.Value(i))
+	case *array.Uint64:
+		return values.NewUInt(arr.Value(i))
+	case *array.Float64:
+		return values.NewFloat(arr.Value(i))
+	case *array.String:
+		return values.NewString(arr.Value(i))
+	case *array.Binary:
+		return values.NewString(arr.ValueString(i))
+	case *array.Timestamp:
+		return values.NewTime(values.Time(arr.Value(i)))
+	default:
+		return values.NewNull(flux.SemanticType(typ))
+	}
+}
@@ -0,0 +1,317 @@
+// Package encoding implements the JSON, newline-delimited JSON and Apache
+// Arrow formats of query results, which clients may ask for instead of
+// annotated CSV.
+//
+// Each format has the result name, the columns of each table with their
+// datatypes and whether they are part of the group key, and the records of
+// the table. Tables without records are omitted, as the group key of a
+// table is read from its records.
+package encoding
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+)
+
+// Format is an encoding of query results.
+type Format int
+
+const (
+	// JSON encodes the results as a single JSON document, with the tables
+	// of each result as arrays of records.
+	JSON Format = iota
+	// NDJSON encodes the columns and each record of a table as a line of JSON.
+	NDJSON
+	// Arrow encodes each table as an Apache Arrow IPC stream.
+	Arrow
+)
+
+var formats = []struct {
+	name         string
+	contentTypes []string
+}{
+	JSON:   {name: "json", contentTypes: []string{"application/json"}},
+	NDJSON: {name: "ndjson", contentTypes: []string{"application/x-ndjson"}},
+	Arrow:  {name: "arrow", contentTypes: []string{"application/vnd.apache.arrow.stream", "application/vnd.influx.arrow"}},
+}
+
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formats) {
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+	return formats[f].name
+}
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	return formats[f].contentTypes[0]
+}
+
+// FormatFromContentType returns the format of the media type mt, and whether
+// there is one.
+func FormatFromContentType(mt string) (Format, bool) {
+	for f, format := range formats {
+		for _, ct := range format.contentTypes {
+			if ct == mt {
+				return Format(f), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// AddDialectMappings adds the dialect mappings of each format.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	for f := range formats {
+		d := &Dialect{Format: Format(f)}
+		if err := mappings.Add(d.DialectType(), func() flux.Dialect {
+			return &Dialect{Format: d.Format}
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dialect encodes results in one of the formats.
+type Dialect struct {
+	Format Format
+}
+
+func (d *Dialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", d.Format.ContentType())
+}
+
+func (d *Dialect) Encoder() flux.MultiResultEncoder {
+	return NewMultiResultEncoder(d.Format)
+}
+
+func (d *Dialect) DialectType() flux.DialectType {
+	return flux.DialectType(d.Format.String())
+}
+
+// NewMultiResultEncoder returns an encoder of results in the format f.
+func NewMultiResultEncoder(f Format) flux.MultiResultEncoder {
+	switch f {
+	case NDJSON:
+		return &ndjsonMultiResultEncoder{}
+	case Arrow:
+		return &arrowMultiResultEncoder{}
+	default:
+		return &jsonMultiResultEncoder{}
+	}
+}
+
+// NewMultiResultDecoder returns a decoder of results in the format f.
+func NewMultiResultDecoder(f Format) flux.MultiResultDecoder {
+	switch f {
+	case NDJSON:
+		return &ndjsonMultiResultDecoder{}
+	case Arrow:
+		return &arrowMultiResultDecoder{}
+	default:
+		return &jsonMultiResultDecoder{}
+	}
+}
+
+// The datatypes of columns, which are the same as in annotated CSV.
+const (
+	boolDatatype   = "boolean"
+	intDatatype    = "long"
+	uintDatatype   = "unsignedLong"
+	floatDatatype  = "double"
+	stringDatatype = "string"
+	timeDatatype   = "dateTime:RFC3339"
+)
+
+func datatype(typ flux.ColType) (string, error) {
+	switch typ {
+	case flux.TBool:
+		return boolDatatype, nil
+	case flux.TInt:
+		return intDatatype, nil
+	case flux.TUInt:
+		return uintDatatype, nil
+	case flux.TFloat:
+		return floatDatatype, nil
+	case flux.TString:
+		return stringDatatype, nil
+	case flux.TTime:
+		return timeDatatype, nil
+	default:
+		return "", fmt.Errorf("unknown column type %v", typ)
+	}
+}
+
+func colType(datatype string) (flux.ColType, error) {
+	switch datatype {
+	case boolDatatype:
+		return flux.TBool, nil
+	case intDatatype:
+		return flux.TInt, nil
+	case uintDatatype:
+		return flux.TUInt, nil
+	case floatDatatype:
+		return flux.TFloat, nil
+	case stringDatatype:
+		return flux.TString, nil
+	case timeDatatype:
+		return flux.TTime, nil
+	default:
+		return flux.TInvalid, fmt.Errorf("unknown datatype %q", datatype)
+	}
+}
+
+// column describes a column of a table.
+type column struct {
+	Label    string `json:"label"`
+	Datatype string `json:"datatype"`
+	Group    bool   `json:"group"`
+}
+
+func tableColumns(tbl flux.Table) ([]column, error) {
+	cols := make([]column, len(tbl.Cols()))
+	for j, c := range tbl.Cols() {
+		dt, err := datatype(c.Type)
+		if err != nil {
+			return nil, err
+		}
+		cols[j] = column{Label: c.Label, Datatype: dt, Group: tbl.Key().HasCol(c.Label)}
+	}
+	return cols, nil
+}
+
+func colMetas(cols []column) ([]flux.ColMeta, error) {
+	metas := make([]flux.ColMeta, len(cols))
+	for j, c := range cols {
+		typ, err := colType(c.Datatype)
+		if err != nil {
+			return nil, err
+		}
+		metas[j] = flux.ColMeta{Label: c.Label, Type: typ}
+	}
+	return metas, nil
+}
+
+// newTableBuilder returns a builder of a decoded table, whose group key is
+// that of its first record.
+func newTableBuilder(cols []column, metas []flux.ColMeta, record []values.Value) (*execute.ColListTableBuilder, error) {
+	var keyCols []flux.ColMeta
+	var keyValues []values.Value
+	for j, c := range cols {
+		if c.Group {
+			keyCols = append(keyCols, metas[j])
+			keyValues = append(keyValues, record[j])
+		}
+	}
+
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(keyCols, keyValues), &memory.Allocator{})
+	for _, c := range metas {
+		if _, err := b.AddCol(c); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// result is a decoded result.
+type result struct {
+	name   string
+	tables []flux.Table
+}
+
+func (r *result) Name() string {
+	return r.name
+}
+
+func (r *result) Tables() flux.TableIterator {
+	return r
+}
+
+func (r *result) Do(f func(flux.Table) error) error {
+	for _, tbl := range r.tables {
+		if err := f(tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resultIterator iterates over decoded results, and then reports the error
+// of the query if it failed.
+type resultIterator struct {
+	results []flux.Result
+	err     error
+}
+
+func (r *resultIterator) More() bool {
+	return len(r.results) > 0
+}
+
+func (r *resultIterator) Next() flux.Result {
+	next := r.results[0]
+	r.results = r.results[1:]
+	return next
+}
+
+func (r *resultIterator) Release() {
+	r.results = nil
+}
+
+func (r *resultIterator) Err() error {
+	return r.err
+}
+
+func (r *resultIterator) Statistics() flux.Statistics {
+	return flux.Statistics{}
+}
+
+// resultsBuilder collects decoded results in the order of their first table.
+type resultsBuilder struct {
+	results []flux.Result
+	byName  map[string]*result
+}
+
+func (b *resultsBuilder) add(name string, tbl flux.Table) {
+	r, ok := b.byName[name]
+	if !ok {
+		if b.byName == nil {
+			b.byName = make(map[string]*result)
+		}
+		r = &result{name: name}
+		b.byName[name] = r
+		b.results = append(b.results, r)
+	}
+	r.tables = append(r.tables, tbl)
+}
+
+// encoderError is an error writing results, as opposed to an error of the
+// query, which is encoded in the results.
+type encoderError struct {
+	err error
+}
+
+func (e *encoderError) Error() string {
+	return e.err.Error()
+}
+
+func (e *encoderError) IsEncoderError() bool {
+	return true
+}
+
+// flusher is implemented by writers, such as http.ResponseWriter, which may
+// send the results written so far.
+type flusher interface {
+	Flush()
+}
+
+func flush(w interface{}) {
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+}
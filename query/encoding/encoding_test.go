@@ -0,0 +1,227 @@
+package encoding_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query/encoding"
+)
+
+func ts(s string) execute.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		panic(err)
+	}
+	return execute.Time(t.UnixNano())
+}
+
+var cols = []flux.ColMeta{
+	{Label: "_time", Type: flux.TTime},
+	{Label: "_measurement", Type: flux.TString},
+	{Label: "ok", Type: flux.TBool},
+	{Label: "n", Type: flux.TInt},
+	{Label: "u", Type: flux.TUInt},
+	{Label: "_value", Type: flux.TFloat},
+}
+
+func testResults(err error) []*executetest.Result {
+	return []*executetest.Result{
+		{
+			Nm: "_result",
+			Tbls: []*executetest.Table{
+				{
+					KeyCols: []string{"_measurement"},
+					ColMeta: cols,
+					Data: [][]interface{}{
+						{ts("2019-10-01T00:00:00Z"), "cpu", true, int64(-1), uint64(1), 1.5},
+						{ts("2019-10-01T00:00:10.5Z"), "cpu", nil, nil, nil, nil},
+					},
+				},
+				{
+					KeyCols:   []string{"_measurement"},
+					KeyValues: []interface{}{"disk"},
+					ColMeta:   cols,
+				},
+				{
+					KeyCols: []string{"_measurement"},
+					ColMeta: cols,
+					Data: [][]interface{}{
+						{ts("2019-10-01T00:00:00Z"), "mem", false, int64(2), uint64(math.MaxUint64), -2.25},
+					},
+				},
+			},
+		},
+		{
+			Nm: "other",
+			Tbls: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{{Label: "name", Type: flux.TString}},
+					Data:    [][]interface{}{{"a"}, {"b \"quoted\""}},
+				},
+			},
+			Err: err,
+		},
+	}
+}
+
+func resultIterator(results []*executetest.Result) flux.ResultIterator {
+	rs := make([]flux.Result, len(results))
+	for i, r := range results {
+		rs[i] = r
+	}
+	return flux.NewSliceResultIterator(rs)
+}
+
+func TestMultiResultEncoder_Encode(t *testing.T) {
+	nonFinite := []*executetest.Result{{
+		Nm: "_result",
+		Tbls: []*executetest.Table{{
+			ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TFloat}},
+			Data:    [][]interface{}{{math.NaN()}, {math.Inf(1)}, {math.Inf(-1)}},
+		}},
+	}}
+
+	for _, tt := range []struct {
+		name    string
+		format  encoding.Format
+		results []*executetest.Result
+		want    string
+	}{
+		{
+			name:    "json",
+			format:  encoding.JSON,
+			results: testResults(nil)[:1],
+			want: `{"results":[{"name":"_result","tables":[` +
+				`{"columns":[{"label":"_time","datatype":"dateTime:RFC3339","group":false},{"label":"_measurement","datatype":"string","group":true},{"label":"ok","datatype":"boolean","group":false},{"label":"n","datatype":"long","group":false},{"label":"u","datatype":"unsignedLong","group":false},{"label":"_value","datatype":"double","group":false}],` +
+				`"records":[{"_time":"2019-10-01T00:00:00Z","_measurement":"cpu","ok":true,"n":-1,"u":1,"_value":1.5},{"_time":"2019-10-01T00:00:10.5Z","_measurement":"cpu","ok":null,"n":null,"u":null,"_value":null}]},` +
+				`{"columns":[{"label":"_time","datatype":"dateTime:RFC3339","group":false},{"label":"_measurement","datatype":"string","group":true},{"label":"ok","datatype":"boolean","group":false},{"label":"n","datatype":"long","group":false},{"label":"u","datatype":"unsignedLong","group":false},{"label":"_value","datatype":"double","group":false}],` +
+				`"records":[{"_time":"2019-10-01T00:00:00Z","_measurement":"mem","ok":false,"n":2,"u":18446744073709551615,"_value":-2.25}]}]}]}` + "\n",
+		},
+		{
+			name:    "json with non-finite floats and an error",
+			format:  encoding.JSON,
+			results: append(nonFinite, testResults(errors.New("expected error"))[1]),
+			want:    `{"results":[{"name":"_result","tables":[{"columns":[{"label":"_value","datatype":"double","group":false}],"records":[{"_value":"NaN"},{"_value":"+Inf"},{"_value":"-Inf"}]}]},{"name":"other","tables":[]}],"error":"expected error"}` + "\n",
+		},
+		{
+			name:    "ndjson with non-finite floats and an error",
+			format:  encoding.NDJSON,
+			results: append(nonFinite, testResults(errors.New("expected error"))[1]),
+			want: `{"result":"_result","table":0,"columns":[{"label":"_value","datatype":"double","group":false}]}
+{"result":"_result","table":0,"record":{"_value":"NaN"}}
+{"result":"_result","table":0,"record":{"_value":"+Inf"}}
+{"result":"_result","table":0,"record":{"_value":"-Inf"}}
+{"error":"expected error"}
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := encoding.NewMultiResultEncoder(tt.format).Encode(&buf, resultIterator(tt.results))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(buf.Len()) {
+				t.Errorf("unexpected count of bytes written %d, want %d", n, buf.Len())
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("unexpected encoding -want/+got\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestMultiResultDecoder_Decode(t *testing.T) {
+	for _, format := range []encoding.Format{encoding.JSON, encoding.NDJSON, encoding.Arrow} {
+		for _, tt := range []struct {
+			name    string
+			err     error
+			wantErr string
+		}{
+			{name: "results"},
+			{name: "error", err: errors.New("expected error"), wantErr: "expected error"},
+		} {
+			t.Run(format.String()+" "+tt.name, func(t *testing.T) {
+				var buf bytes.Buffer
+				if _, err := encoding.NewMultiResultEncoder(format).Encode(&buf, resultIterator(testResults(tt.err))); err != nil {
+					t.Fatal(err)
+				}
+
+				itr, err := encoding.NewMultiResultDecoder(format).Decode(ioutil.NopCloser(&buf))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer itr.Release()
+
+				var got []*executetest.Result
+				for itr.More() {
+					res := itr.Next()
+					r := &executetest.Result{Nm: res.Name()}
+					if err := res.Tables().Do(func(tbl flux.Table) error {
+						c, err := executetest.ConvertTable(tbl)
+						if err != nil {
+							return err
+						}
+						r.Tbls = append(r.Tbls, c)
+						return nil
+					}); err != nil {
+						t.Fatal(err)
+					}
+					r.Normalize()
+					got = append(got, r)
+				}
+
+				gotErr := ""
+				if err := itr.Err(); err != nil {
+					gotErr = err.Error()
+				}
+				if gotErr != tt.wantErr {
+					t.Errorf("unexpected error %q, want %q", gotErr, tt.wantErr)
+				}
+
+				// Tables without records are not encoded, and there are
+				// no tables of a result that fails.
+				want := testResults(nil)
+				want[0].Tbls = append(want[0].Tbls[:1], want[0].Tbls[2])
+				if tt.err != nil {
+					want = want[:1]
+				}
+				for _, r := range want {
+					r.Normalize()
+				}
+				if !cmp.Equal(want, got) {
+					t.Errorf("unexpected results -want/+got\n%s", cmp.Diff(want, got))
+				}
+			})
+		}
+	}
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	for _, tt := range []struct {
+		contentType string
+		want        encoding.Format
+		ok          bool
+	}{
+		{contentType: "application/json", want: encoding.JSON, ok: true},
+		{contentType: "application/x-ndjson", want: encoding.NDJSON, ok: true},
+		{contentType: "application/vnd.apache.arrow.stream", want: encoding.Arrow, ok: true},
+		{contentType: "application/vnd.influx.arrow", want: encoding.Arrow, ok: true},
+		{contentType: "text/csv"},
+	} {
+		t.Run(tt.contentType, func(t *testing.T) {
+			got, ok := encoding.FormatFromContentType(tt.contentType)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("got %v, %t, want %v, %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
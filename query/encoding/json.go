@@ -0,0 +1,478 @@
+package encoding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/flux/values"
+)
+
+// jsonMultiResultEncoder encodes results as a JSON document such as:
+//
+//	{"results":[{"name":"_result","tables":[{"columns":[...],"records":[{...},...]}]}]}
+//
+// Each record is an object of the values of the columns in order. Times are
+// in RFC3339 format, and floats that are not finite are the strings "NaN",
+// "+Inf" and "-Inf". If the query fails, the results so far are followed by
+// an "error" member.
+type jsonMultiResultEncoder struct{}
+
+func (e *jsonMultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	jw := newJSONWriter(w)
+	jw.WriteString(`{"results":[`)
+
+	var err error
+	for i := 0; err == nil && results.More(); i++ {
+		res := results.Next()
+		if i > 0 {
+			jw.WriteString(",")
+		}
+		jw.WriteString(`{"name":`)
+		jw.Marshal(res.Name())
+		jw.WriteString(`,"tables":[`)
+
+		n := 0
+		err = res.Tables().Do(func(tbl flux.Table) error {
+			cols, err := tableColumns(tbl)
+			if err != nil {
+				return &encoderError{err: err}
+			}
+			if tbl.Empty() {
+				return tbl.Do(func(flux.ColReader) error { return nil })
+			}
+
+			if n > 0 {
+				jw.WriteString(",")
+			}
+			n++
+			jw.WriteString(`{"columns":`)
+			jw.Marshal(cols)
+			jw.WriteString(`,"records":[`)
+			defer jw.WriteString("]}")
+
+			first := true
+			return tbl.Do(func(cr flux.ColReader) error {
+				for i := 0; i < cr.Len(); i++ {
+					if !first {
+						jw.WriteString(",")
+					}
+					first = false
+					jw.Write(appendRecord(nil, cols, cr, i))
+				}
+				return jw.Err()
+			})
+		})
+		jw.WriteString("]}")
+		flush(w)
+	}
+	if err == nil {
+		err = results.Err()
+	}
+	if err != nil && flux.IsEncoderError(err) {
+		return jw.Count(), err
+	}
+
+	jw.WriteString("]")
+	if err != nil {
+		jw.WriteString(`,"error":`)
+		jw.Marshal(err.Error())
+	}
+	jw.WriteString("}\n")
+	return jw.Count(), jw.Err()
+}
+
+// ndjsonMultiResultEncoder encodes results as newline-delimited JSON. The
+// columns of each table are a line, followed by a line for each record:
+//
+//	{"result":"_result","table":0,"columns":[...]}
+//	{"result":"_result","table":0,"record":{...}}
+//
+// Values are as in the JSON format. If the query fails, the last line is
+// {"error":"..."}.
+type ndjsonMultiResultEncoder struct{}
+
+func (e *ndjsonMultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	jw := newJSONWriter(w)
+
+	var err error
+	for err == nil && results.More() {
+		res := results.Next()
+		name, _ := json.Marshal(res.Name())
+
+		n := 0
+		err = res.Tables().Do(func(tbl flux.Table) error {
+			cols, err := tableColumns(tbl)
+			if err != nil {
+				return &encoderError{err: err}
+			}
+			if tbl.Empty() {
+				return tbl.Do(func(flux.ColReader) error { return nil })
+			}
+
+			prefix := append([]byte(`{"result":`), name...)
+			prefix = append(prefix, `,"table":`...)
+			prefix = strconv.AppendInt(prefix, int64(n), 10)
+			n++
+
+			jw.Write(prefix)
+			jw.WriteString(`,"columns":`)
+			jw.Marshal(cols)
+			jw.WriteString("}\n")
+
+			line := prefix
+			return tbl.Do(func(cr flux.ColReader) error {
+				for i := 0; i < cr.Len(); i++ {
+					line = append(line[:len(prefix)], `,"record":`...)
+					line = appendRecord(line, cols, cr, i)
+					jw.Write(append(line, "}\n"...))
+				}
+				return jw.Err()
+			})
+		})
+		flush(w)
+	}
+	if err == nil {
+		err = results.Err()
+	}
+	if err != nil && flux.IsEncoderError(err) {
+		return jw.Count(), err
+	}
+
+	if err != nil {
+		jw.WriteString(`{"error":`)
+		jw.Marshal(err.Error())
+		jw.WriteString("}\n")
+	}
+	return jw.Count(), jw.Err()
+}
+
+// jsonWriter writes JSON and keeps the first error, so that it need only be
+// checked once the JSON is written.
+type jsonWriter struct {
+	w   *iocounter.Writer
+	err error
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{w: &iocounter.Writer{Writer: w}}
+}
+
+func (w *jsonWriter) Write(p []byte) {
+	if w.err == nil {
+		_, w.err = w.w.Write(p)
+	}
+}
+
+func (w *jsonWriter) WriteString(s string) {
+	w.Write([]byte(s))
+}
+
+func (w *jsonWriter) Marshal(v interface{}) {
+	if w.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		w.err = err
+		return
+	}
+	w.Write(b)
+}
+
+func (w *jsonWriter) Count() int64 {
+	return w.w.Count()
+}
+
+// Err returns the first error writing JSON, as an encoder error.
+func (w *jsonWriter) Err() error {
+	if w.err == nil {
+		return nil
+	}
+	return &encoderError{err: w.err}
+}
+
+// appendRecord appends row i of cr as a JSON object to b.
+func appendRecord(b []byte, cols []column, cr flux.ColReader, i int) []byte {
+	b = append(b, '{')
+	for j, c := range cols {
+		if j > 0 {
+			b = append(b, ',')
+		}
+		b = appendString(b, c.Label)
+		b = append(b, ':')
+		b = appendValue(b, cr, j, i)
+	}
+	return append(b, '}')
+}
+
+// appendValue appends the value in row i of column j of cr to b.
+func appendValue(b []byte, cr flux.ColReader, j, i int) []byte {
+	switch cr.Cols()[j].Type {
+	case flux.TBool:
+		vs := cr.Bools(j)
+		if vs.IsNull(i) {
+			break
+		}
+		return strconv.AppendBool(b, vs.Value(i))
+	case flux.TInt:
+		vs := cr.Ints(j)
+		if vs.IsNull(i) {
+			break
+		}
+		return strconv.AppendInt(b, vs.Value(i), 10)
+	case flux.TUInt:
+		vs := cr.UInts(j)
+		if vs.IsNull(i) {
+			break
+		}
+		return strconv.AppendUint(b, vs.Value(i), 10)
+	case flux.TFloat:
+		vs := cr.Floats(j)
+		if vs.IsNull(i) {
+			break
+		}
+		switch v := vs.Value(i); {
+		case math.IsNaN(v):
+			return append(b, `"NaN"`...)
+		case math.IsInf(v, 1):
+			return append(b, `"+Inf"`...)
+		case math.IsInf(v, -1):
+			return append(b, `"-Inf"`...)
+		default:
+			return strconv.AppendFloat(b, v, 'g', -1, 64)
+		}
+	case flux.TString:
+		vs := cr.Strings(j)
+		if vs.IsNull(i) {
+			break
+		}
+		return appendString(b, vs.ValueString(i))
+	case flux.TTime:
+		vs := cr.Times(j)
+		if vs.IsNull(i) {
+			break
+		}
+		b = append(b, '"')
+		b = values.Time(vs.Value(i)).Time().UTC().AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"')
+	}
+	return append(b, "null"...)
+}
+
+func appendString(b []byte, s string) []byte {
+	// Marshaling a string does not fail.
+	v, _ := json.Marshal(s)
+	return append(b, v...)
+}
+
+// jsonMultiResultDecoder decodes results in the JSON format.
+type jsonMultiResultDecoder struct{}
+
+type jsonResponse struct {
+	Results []struct {
+		Name   string `json:"name"`
+		Tables []struct {
+			Columns []column                 `json:"columns"`
+			Records []map[string]interface{} `json:"records"`
+		} `json:"tables"`
+	} `json:"results"`
+	Error string `json:"error,omitempty"`
+}
+
+func (d *jsonMultiResultDecoder) Decode(r io.ReadCloser) (flux.ResultIterator, error) {
+	defer r.Close()
+
+	var resp jsonResponse
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&resp); err != nil {
+		return nil, err
+	}
+
+	var rb resultsBuilder
+	for _, res := range resp.Results {
+		for _, t := range res.Tables {
+			tbl, err := decodeTable(t.Columns, t.Records)
+			if err != nil {
+				return nil, err
+			}
+			rb.add(res.Name, tbl)
+		}
+	}
+
+	itr := &resultIterator{results: rb.results}
+	if resp.Error != "" {
+		itr.err = errors.New(resp.Error)
+	}
+	return itr, nil
+}
+
+// ndjsonMultiResultDecoder decodes results in the newline-delimited JSON format.
+type ndjsonMultiResultDecoder struct{}
+
+type ndjsonLine struct {
+	Result  string                 `json:"result"`
+	Table   int                    `json:"table"`
+	Columns []column               `json:"columns"`
+	Record  map[string]interface{} `json:"record"`
+	Error   string                 `json:"error"`
+}
+
+func (d *ndjsonMultiResultDecoder) Decode(r io.ReadCloser) (flux.ResultIterator, error) {
+	defer r.Close()
+
+	var (
+		rb      resultsBuilder
+		cur     *ndjsonLine
+		records []map[string]interface{}
+		itr     = &resultIterator{}
+	)
+	finish := func() error {
+		if cur == nil {
+			return nil
+		}
+		tbl, err := decodeTable(cur.Columns, records)
+		if err != nil {
+			return err
+		}
+		rb.add(cur.Result, tbl)
+		cur, records = nil, nil
+		return nil
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for {
+		var line ndjsonLine
+		if err := dec.Decode(&line); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case line.Error != "":
+			itr.err = errors.New(line.Error)
+		case line.Columns != nil:
+			if err := finish(); err != nil {
+				return nil, err
+			}
+			cur = &line
+		case line.Record != nil:
+			if cur == nil || cur.Result != line.Result || cur.Table != line.Table {
+				return nil, fmt.Errorf("record of table %d of result %q without columns", line.Table, line.Result)
+			}
+			records = append(records, line.Record)
+		}
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+
+	itr.results = rb.results
+	return itr, nil
+}
+
+// decodeTable returns the table of decoded JSON records.
+func decodeTable(cols []column, records []map[string]interface{}) (flux.Table, error) {
+	metas, err := colMetas(cols)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("table has no records")
+	}
+
+	rows := make([][]values.Value, len(records))
+	for i, rec := range records {
+		rows[i] = make([]values.Value, len(metas))
+		for j, c := range metas {
+			v, err := decodeValue(rec[c.Label], c.Type)
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %v", c.Label, err)
+			}
+			rows[i][j] = v
+		}
+	}
+
+	b, err := newTableBuilder(cols, metas, rows[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for j, v := range row {
+			if err := b.AppendValue(j, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return b.Table()
+}
+
+// decodeValue decodes a JSON value of a column of type typ.
+func decodeValue(v interface{}, typ flux.ColType) (values.Value, error) {
+	if v == nil {
+		return values.NewNull(flux.SemanticType(typ)), nil
+	}
+
+	switch typ {
+	case flux.TBool:
+		if b, ok := v.(bool); ok {
+			return values.NewBool(b), nil
+		}
+	case flux.TInt:
+		if n, ok := v.(json.Number); ok {
+			i, err := strconv.ParseInt(string(n), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewInt(i), nil
+		}
+	case flux.TUInt:
+		if n, ok := v.(json.Number); ok {
+			u, err := strconv.ParseUint(string(n), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewUInt(u), nil
+		}
+	case flux.TFloat:
+		switch v := v.(type) {
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return nil, err
+			}
+			return values.NewFloat(f), nil
+		case string:
+			switch v {
+			case "NaN":
+				return values.NewFloat(math.NaN()), nil
+			case "+Inf":
+				return values.NewFloat(math.Inf(1)), nil
+			case "-Inf":
+				return values.NewFloat(math.Inf(-1)), nil
+			}
+		}
+	case flux.TString:
+		if s, ok := v.(string); ok {
+			return values.NewString(s), nil
+		}
+	case flux.TTime:
+		if s, ok := v.(string); ok {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewTime(values.ConvertTime(t)), nil
+		}
+	}
+	return nil, fmt.Errorf("invalid %v value %v", typ, v)
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	platform "github.com/influxdata/influxdb"
+)
+
+// LogSettings configure the logging of the queries of an organization.
+type LogSettings struct {
+	OrganizationID platform.ID `json:"orgID"`
+	// SlowQueryThreshold is the duration at or above which a query is
+	// logged as slow. Zero uses the default of the server.
+	SlowQueryThreshold time.Duration `json:"slowQueryThreshold"`
+	// BucketID is the bucket of the organization its slow queries are
+	// written to. If it is not valid, they are only logged.
+	BucketID platform.ID `json:"bucketID,omitempty"`
+}
+
+// LoggedQuery is a query that has finished, with the statistics of its
+// execution.
+type LoggedQuery struct {
+	// Time is the time the query finished.
+	Time            time.Time         `json:"time"`
+	OrganizationID  platform.ID       `json:"orgID"`
+	UserID          platform.ID       `json:"userID,omitempty"`
+	AuthorizationID platform.ID       `json:"authorizationID,omitempty"`
+	Source          string            `json:"source,omitempty"`
+	Type            flux.CompilerType `json:"type"`
+	Query           string            `json:"query,omitempty"`
+	Error           string            `json:"error,omitempty"`
+	ResponseSize    int64             `json:"responseSize"`
+	Statistics      flux.Statistics   `json:"statistics"`
+	// Slow is whether the query took at least the slow query threshold of
+	// its organization.
+	Slow bool `json:"slow"`
+}
+
+// RecentQueryFilter selects the recent queries of an organization or user.
+type RecentQueryFilter struct {
+	OrganizationID *platform.ID
+	UserID         *platform.ID
+	// Limit is the most queries to return; zero returns every query kept.
+	Limit int
+}
+
+// Match reports whether q is selected by the filter.
+func (f RecentQueryFilter) Match(q *LoggedQuery) bool {
+	if f.OrganizationID != nil && *f.OrganizationID != q.OrganizationID {
+		return false
+	}
+	if f.UserID != nil && *f.UserID != q.UserID {
+		return false
+	}
+	return true
+}
+
+// QueryTexter is implemented by compilers of queries written as text.
+type QueryTexter interface {
+	QueryText() string
+}
+
+// CompilerQuery returns the text of the query of a compiler, or an empty
+// string if it was compiled from a spec. The text of queries compiled from
+// an AST is the formatted AST, including any files prepended to it, such as
+// the extern of a request.
+func CompilerQuery(c flux.Compiler) string {
+	switch c := c.(type) {
+	case lang.FluxCompiler:
+		return c.Query
+	case lang.ASTCompiler:
+		return FormatAST(c.AST)
+	case QueryTexter:
+		return c.QueryText()
+	default:
+		return ""
+	}
+}
+
+// FormatAST returns the Flux text of the files of pkg, or an empty string
+// if pkg is not a valid AST. ASTs may come from the API as JSON, and the
+// formatter panics on ones that are missing nodes.
+func FormatAST(pkg *ast.Package) (text string) {
+	if pkg == nil || ast.GetError(pkg) != nil {
+		return ""
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			text = ""
+		}
+	}()
+
+	files := make([]string, 0, len(pkg.Files))
+	for _, f := range pkg.Files {
+		files = append(files, ast.Format(f))
+	}
+	return strings.Join(files, "\n\n")
+}
@@ -0,0 +1,52 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestCompilerQuery(t *testing.T) {
+	astCompiler := lang.ASTCompiler{
+		AST: parser.ParseSource(`from(bucket:"telegraf")|>range(start:v.timeRangeStart)`),
+	}
+	astCompiler.PrependFile(parser.ParseSource(`v = {timeRangeStart: -1h}`).Files[0])
+
+	tests := []struct {
+		name     string
+		compiler flux.Compiler
+		want     string
+	}{
+		{
+			name:     "flux",
+			compiler: lang.FluxCompiler{Query: `from(bucket: "telegraf")`},
+			want:     `from(bucket: "telegraf")`,
+		},
+		{
+			name:     "ast with extern",
+			compiler: astCompiler,
+			want:     "v = {timeRangeStart: -1h}\n\nfrom(bucket: \"telegraf\")\n\t|> range(start: v.timeRangeStart)",
+		},
+		{
+			name:     "invalid ast",
+			compiler: lang.ASTCompiler{AST: &ast.Package{Files: []*ast.File{{Body: []ast.Statement{&ast.ExpressionStatement{Expression: &ast.PipeExpression{}}}}}}},
+			want:     "",
+		},
+		{
+			name:     "spec",
+			compiler: lang.SpecCompiler{Spec: &flux.Spec{}},
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := query.CompilerQuery(tt.compiler); got != tt.want {
+				t.Errorf("unexpected query text -want/+got\n-%q\n+%q", tt.want, got)
+			}
+		})
+	}
+}
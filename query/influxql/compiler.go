@@ -22,6 +22,7 @@ type Compiler struct {
 	Cluster string     `json:"cluster,omitempty"`
 	DB      string     `json:"db,omitempty"`
 	RP      string     `json:"rp,omitempty"`
+	Bucket  string     `json:"bucket,omitempty"`
 	Query   string     `json:"query"`
 	Now     *time.Time `json:"now,omitempty"`
 
@@ -49,6 +50,7 @@ func (c *Compiler) Compile(ctx context.Context) (*flux.Spec, error) {
 			DefaultDatabase:        c.DB,
 			DefaultRetentionPolicy: c.RP,
 			Now:                    now,
+			Bucket:                 c.Bucket,
 		},
 	)
 	astPkg, err := transpiler.Transpile(ctx, c.Query)
@@ -60,3 +62,8 @@ func (c *Compiler) Compile(ctx context.Context) (*flux.Spec, error) {
 func (c *Compiler) CompilerType() flux.CompilerType {
 	return CompilerType
 }
+
+// QueryText returns the text of the query.
+func (c *Compiler) QueryText() string {
+	return c.Query
+}
@@ -1,12 +1,50 @@
 package influxql_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/influxdb/query/influxql"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 )
 
 func TestCompiler(t *testing.T) {
 	var _ flux.Compiler = (*influxql.Compiler)(nil)
 }
+
+func TestCompiler_Bucket(t *testing.T) {
+	c := influxql.NewCompiler(nil)
+	c.Bucket = "telegraf"
+	c.Query = `SELECT value FROM db0.autogen.cpu; SELECT value FROM mem`
+
+	spec, err := c.Compile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	for _, op := range spec.Operations {
+		from, ok := op.Spec.(*influxdb.FromOpSpec)
+		if !ok {
+			continue
+		}
+		if from.Bucket != "telegraf" || from.BucketID != "" {
+			t.Errorf("unexpected from %+v", from)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Errorf("got %d from operations, want 2", n)
+	}
+}
+
+func TestCompiler_NoBucket(t *testing.T) {
+	c := influxql.NewCompiler(nil)
+	c.DB = "db0"
+	c.Query = `SELECT value FROM cpu`
+
+	if _, err := c.Compile(context.Background()); err == nil {
+		t.Error("expected an error without a bucket or mappings")
+	}
+}
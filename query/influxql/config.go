@@ -10,4 +10,8 @@ type Config struct {
 	DefaultRetentionPolicy string
 	Now                    time.Time
 	Cluster                string
+
+	// Bucket is the bucket all measurements are read from, regardless of
+	// their database and retention policy, when it is set.
+	Bucket string
 }
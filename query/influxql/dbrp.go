@@ -0,0 +1,95 @@
+package influxql
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.DBRPMappingService = (*BucketDBRPMappingService)(nil)
+
+// BucketDBRPMappingService maps the databases and retention policies of an
+// organization to its buckets by name, as buckets are named when they are
+// migrated from 1.x: a retention policy of a database maps to the bucket
+// named "db/rp", and the default retention policy to the bucket named "db".
+// Mappings cannot be created or deleted.
+type BucketDBRPMappingService struct {
+	OrganizationID platform.ID
+	BucketService  platform.BucketService
+}
+
+// FindBy returns the mapping of the bucket for db and rp.
+func (s *BucketDBRPMappingService) FindBy(ctx context.Context, cluster, db, rp string) (*platform.DBRPMapping, error) {
+	name := db
+	if rp != "" {
+		name += "/" + rp
+	}
+
+	b, err := s.BucketService.FindBucket(ctx, platform.BucketFilter{
+		OrganizationID: &s.OrganizationID,
+		Name:           &name,
+	})
+	if err != nil {
+		if platform.ErrorCode(err) == platform.ENotFound {
+			return nil, &platform.Error{
+				Code: platform.ENotFound,
+				Msg:  "no bucket named " + name + " for database " + db,
+			}
+		}
+		return nil, err
+	}
+
+	return &platform.DBRPMapping{
+		Cluster:         cluster,
+		Database:        db,
+		RetentionPolicy: rp,
+		Default:         rp == "",
+		OrganizationID:  b.OrganizationID,
+		BucketID:        b.ID,
+	}, nil
+}
+
+// Find returns the mapping of the bucket for the database and retention
+// policy of filter.
+func (s *BucketDBRPMappingService) Find(ctx context.Context, filter platform.DBRPMappingFilter) (*platform.DBRPMapping, error) {
+	if filter.Database == nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "database is required",
+		}
+	}
+
+	var cluster, rp string
+	if filter.Cluster != nil {
+		cluster = *filter.Cluster
+	}
+	if filter.RetentionPolicy != nil {
+		rp = *filter.RetentionPolicy
+	}
+	return s.FindBy(ctx, cluster, *filter.Database, rp)
+}
+
+// FindMany returns the mapping Find returns for filter.
+func (s *BucketDBRPMappingService) FindMany(ctx context.Context, filter platform.DBRPMappingFilter, opt ...platform.FindOptions) ([]*platform.DBRPMapping, int, error) {
+	m, err := s.Find(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return []*platform.DBRPMapping{m}, 1, nil
+}
+
+// Create is not supported, buckets are mapped by name.
+func (s *BucketDBRPMappingService) Create(ctx context.Context, m *platform.DBRPMapping) error {
+	return &platform.Error{
+		Code: platform.EMethodNotAllowed,
+		Msg:  "databases are mapped to buckets by name",
+	}
+}
+
+// Delete is not supported, buckets are mapped by name.
+func (s *BucketDBRPMappingService) Delete(ctx context.Context, cluster, db, rp string) error {
+	return &platform.Error{
+		Code: platform.EMethodNotAllowed,
+		Msg:  "databases are mapped to buckets by name",
+	}
+}
@@ -0,0 +1,84 @@
+package influxql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query/influxql"
+)
+
+func TestBucketDBRPMappingService_Find(t *testing.T) {
+	buckets := map[string]platform.ID{
+		"telegraf":          1,
+		"telegraf/one_week": 2,
+	}
+	svc := &influxql.BucketDBRPMappingService{
+		OrganizationID: 10,
+		BucketService: &mock.BucketService{
+			FindBucketFn: func(ctx context.Context, filter platform.BucketFilter) (*platform.Bucket, error) {
+				if filter.OrganizationID == nil || *filter.OrganizationID != 10 {
+					t.Fatalf("unexpected organization in filter %v", filter)
+				}
+				id, ok := buckets[*filter.Name]
+				if !ok {
+					return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+				}
+				return &platform.Bucket{ID: id, OrganizationID: 10, Name: *filter.Name}, nil
+			},
+		},
+	}
+
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		filter   platform.DBRPMappingFilter
+		want     *platform.DBRPMapping
+		wantCode string
+	}{
+		{
+			name:   "default retention policy",
+			filter: platform.DBRPMappingFilter{Cluster: str(""), Database: str("telegraf")},
+			want: &platform.DBRPMapping{
+				Database:       "telegraf",
+				Default:        true,
+				OrganizationID: 10,
+				BucketID:       1,
+			},
+		},
+		{
+			name:   "retention policy",
+			filter: platform.DBRPMappingFilter{Database: str("telegraf"), RetentionPolicy: str("one_week")},
+			want: &platform.DBRPMapping{
+				Database:        "telegraf",
+				RetentionPolicy: "one_week",
+				OrganizationID:  10,
+				BucketID:        2,
+			},
+		},
+		{
+			name:     "unknown retention policy",
+			filter:   platform.DBRPMappingFilter{Database: str("telegraf"), RetentionPolicy: str("autogen")},
+			wantCode: platform.ENotFound,
+		},
+		{
+			name:     "no database",
+			filter:   platform.DBRPMappingFilter{RetentionPolicy: str("autogen")},
+			wantCode: platform.EInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.Find(context.Background(), tt.filter)
+			if code := platform.ErrorCode(err); code != tt.wantCode {
+				t.Fatalf("unexpected error code %q: %v", code, err)
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("unexpected mapping -want/+got\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
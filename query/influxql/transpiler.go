@@ -642,6 +642,10 @@ func (t *transpilerState) mapType(ref *influxql.VarRef) influxql.DataType {
 }
 
 func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error) {
+	if t.config.Bucket != "" {
+		return fromCall("bucket", t.config.Bucket), nil
+	}
+
 	db, rp := m.Database, m.RetentionPolicy
 	if db == "" {
 		if t.config.DefaultDatabase == "" {
@@ -665,11 +669,19 @@ func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error)
 	}
 	defaultRP := rp == ""
 	filter.Default = &defaultRP
+	if t.dbrpMappingSvc == nil {
+		return nil, errors.New("no database and retention policy mappings, bucket is required")
+	}
 	mapping, err := t.dbrpMappingSvc.Find(context.TODO(), filter)
 	if err != nil {
 		return nil, err
 	}
 
+	return fromCall("bucketID", mapping.BucketID.String()), nil
+}
+
+// fromCall returns a call to from with a single string argument.
+func fromCall(key, value string) *ast.CallExpression {
 	return &ast.CallExpression{
 		Callee: &ast.Identifier{
 			Name: "from",
@@ -679,16 +691,16 @@ func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error)
 				Properties: []*ast.Property{
 					{
 						Key: &ast.Identifier{
-							Name: "bucketID",
+							Name: key,
 						},
 						Value: &ast.StringLiteral{
-							Value: mapping.BucketID.String(),
+							Value: value,
 						},
 					},
 				},
 			},
 		},
-	}, nil
+	}
 }
 
 func (t *transpilerState) assignment(expr ast.Expression) *ast.Identifier {
@@ -0,0 +1,26 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ query.ActiveQueryService = (*ActiveQueryService)(nil)
+
+// ActiveQueryService mocks the ActiveQueryService for testing.
+type ActiveQueryService struct {
+	FindActiveQueriesF func(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error)
+	CancelActiveQueryF func(ctx context.Context, id platform.ID) error
+}
+
+// FindActiveQueries returns the queries that are running.
+func (s *ActiveQueryService) FindActiveQueries(ctx context.Context, filter query.ActiveQueryFilter) ([]*query.ActiveQuery, error) {
+	return s.FindActiveQueriesF(ctx, filter)
+}
+
+// CancelActiveQuery cancels a query that is running.
+func (s *ActiveQueryService) CancelActiveQuery(ctx context.Context, id platform.ID) error {
+	return s.CancelActiveQueryF(ctx, id)
+}
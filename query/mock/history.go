@@ -0,0 +1,39 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var (
+	_ query.LogSettingsService = (*LogSettingsService)(nil)
+	_ query.HistoryService     = (*HistoryService)(nil)
+)
+
+// LogSettingsService mocks the LogSettingsService for testing.
+type LogSettingsService struct {
+	FindLogSettingsF func(ctx context.Context, orgID platform.ID) (*query.LogSettings, error)
+	PutLogSettingsF  func(ctx context.Context, s *query.LogSettings) error
+}
+
+// FindLogSettings returns the query log settings of an organization.
+func (s *LogSettingsService) FindLogSettings(ctx context.Context, orgID platform.ID) (*query.LogSettings, error) {
+	return s.FindLogSettingsF(ctx, orgID)
+}
+
+// PutLogSettings replaces the query log settings of an organization.
+func (s *LogSettingsService) PutLogSettings(ctx context.Context, settings *query.LogSettings) error {
+	return s.PutLogSettingsF(ctx, settings)
+}
+
+// HistoryService mocks the HistoryService for testing.
+type HistoryService struct {
+	FindRecentQueriesF func(ctx context.Context, filter query.RecentQueryFilter) ([]*query.LoggedQuery, error)
+}
+
+// FindRecentQueries returns the queries that have finished recently.
+func (s *HistoryService) FindRecentQueries(ctx context.Context, filter query.RecentQueryFilter) ([]*query.LoggedQuery, error) {
+	return s.FindRecentQueriesF(ctx, filter)
+}
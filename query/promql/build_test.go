@@ -0,0 +1,341 @@
+package promql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	fluxquerytest "github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/csv"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/promql"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+)
+
+// samples are the samples of the Prometheus bucket, as they are read from
+// storage. Labels a series does not have are empty.
+const samples = `
+#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,double,string,string,string,string,string
+#group,false,false,true,true,false,false,true,true,true,true,true
+#default,_result,,,,,,,,,,
+,result,table,_start,_stop,_time,_value,_field,_measurement,code,instance,job
+,,0,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:00:00Z,1,value,up,,x,a
+,,0,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:00:30Z,0,value,up,,x,a
+,,0,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:01:00Z,1,value,up,,x,a
+,,1,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:00:15Z,1,value,up,,y,b
+,,1,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:00:45Z,1,value,up,,y,b
+,,2,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:00:00Z,10,value,requests,200,,a
+,,2,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:01:00Z,20,value,requests,200,,a
+,,3,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:00:00Z,1,value,requests,500,,a
+,,3,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:01:00Z,3,value,requests,500,,a
+,,4,1970-01-01T00:00:00Z,2030-01-01T00:00:00Z,2019-10-01T00:00:30Z,5,value,requests,200,,b
+`
+
+func TestBuild(t *testing.T) {
+	at := func(s string) time.Time {
+		t, err := time.Parse(time.RFC3339, "2019-10-01T"+s+"Z")
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		start, end time.Time
+		step       time.Duration
+		resultType promql.ResultType
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:  "latest sample of each series",
+			query: `up`,
+			start: at("00:00:40"),
+			end:   at("00:00:40"),
+			want: `[
+				{"metric": {"__name__": "up", "instance": "x", "job": "a"}, "value": [1569888040, "0"]},
+				{"metric": {"__name__": "up", "instance": "y", "job": "b"}, "value": [1569888040, "1"]}
+			]`,
+		},
+		{
+			name:  "sample at the evaluation time",
+			query: `up{job="a"}`,
+			start: at("00:00:30"),
+			end:   at("00:00:30"),
+			want:  `[{"metric": {"__name__": "up", "instance": "x", "job": "a"}, "value": [1569888030, "0"]}]`,
+		},
+		{
+			name:  "sample at the lookback delta",
+			query: `up`,
+			start: at("00:06:00"),
+			end:   at("00:06:00"),
+			want:  `[]`,
+		},
+		{
+			name:  "sample within the lookback delta",
+			query: `up`,
+			start: at("00:05:59"),
+			end:   at("00:05:59"),
+			want:  `[{"metric": {"__name__": "up", "instance": "x", "job": "a"}, "value": [1569888359, "1"]}]`,
+		},
+		{
+			name:  "regular expression matchers",
+			query: `up{job=~"a|c", job!~"c"}`,
+			start: at("00:00:40"),
+			end:   at("00:00:40"),
+			want:  `[{"metric": {"__name__": "up", "instance": "x", "job": "a"}, "value": [1569888040, "0"]}]`,
+		},
+		{
+			name:  "offset",
+			query: `up{job="a"} offset 30s`,
+			start: at("00:01:00"),
+			end:   at("00:01:00"),
+			want:  `[{"metric": {"__name__": "up", "instance": "x", "job": "a"}, "value": [1569888060, "0"]}]`,
+		},
+		{
+			name:       "range vector",
+			query:      `up{job="b"}[1m]`,
+			start:      at("00:01:00"),
+			end:        at("00:01:00"),
+			resultType: promql.MatrixResult,
+			want:       `[{"metric": {"__name__": "up", "instance": "y", "job": "b"}, "values": [[1569888015, "1"], [1569888045, "1"]]}]`,
+		},
+		{
+			name:       "range query",
+			query:      `up{job="a"}`,
+			start:      at("00:00:00"),
+			end:        at("00:01:10"),
+			step:       30 * time.Second,
+			resultType: promql.MatrixResult,
+			want:       `[{"metric": {"__name__": "up", "instance": "x", "job": "a"}, "values": [[1569888000, "1"], [1569888030, "0"], [1569888060, "1"]]}]`,
+		},
+		{
+			name:       "aggregate at each step",
+			query:      `count(up)`,
+			start:      at("00:00:00"),
+			end:        at("00:00:30"),
+			step:       15 * time.Second,
+			resultType: promql.MatrixResult,
+			want:       `[{"metric": {}, "values": [[1569888000, "1"], [1569888015, "2"], [1569888030, "2"]]}]`,
+		},
+		{
+			name:  "aggregate by labels",
+			query: `sum by (job) (requests)`,
+			start: at("00:00:30"),
+			end:   at("00:00:30"),
+			want: `[
+				{"metric": {"job": "a"}, "value": [1569888030, "11"]},
+				{"metric": {"job": "b"}, "value": [1569888030, "5"]}
+			]`,
+		},
+		{
+			name:       "selector aggregate",
+			query:      `max(requests) by (job)`,
+			start:      at("00:00:00"),
+			end:        at("00:01:00"),
+			step:       time.Minute,
+			resultType: promql.MatrixResult,
+			want: `[
+				{"metric": {"job": "a"}, "values": [[1569888000, "10"], [1569888060, "20"]]},
+				{"metric": {"job": "b"}, "values": [[1569888060, "5"]]}
+			]`,
+		},
+		{
+			name:  "aggregate without labels",
+			query: `max without (code) (requests)`,
+			start: at("00:01:00"),
+			end:   at("00:01:00"),
+			want: `[
+				{"metric": {"job": "a"}, "value": [1569888060, "20"]},
+				{"metric": {"job": "b"}, "value": [1569888060, "5"]}
+			]`,
+		},
+		{
+			name:  "rate",
+			query: `rate(requests[100s])`,
+			start: at("00:01:00"),
+			end:   at("00:01:00"),
+			want: `[
+				{"metric": {"code": "200", "job": "a"}, "value": [1569888060, "0.1"]},
+				{"metric": {"code": "500", "job": "a"}, "value": [1569888060, "0.02"]}
+			]`,
+		},
+		{
+			name:  "rate ignores counter resets",
+			query: `rate(up{job="a"}[100s])`,
+			start: at("00:01:00"),
+			end:   at("00:01:00"),
+			want:  `[{"metric": {"instance": "x", "job": "a"}, "value": [1569888060, "0.01"]}]`,
+		},
+		{
+			name:  "aggregate of increase",
+			query: `sum by (job) (increase(requests[100s]))`,
+			start: at("00:01:00"),
+			end:   at("00:01:00"),
+			want:  `[{"metric": {"job": "a"}, "value": [1569888060, "12"]}]`,
+		},
+		{
+			name:  "irate",
+			query: `irate(requests{code="500"}[100s])`,
+			start: at("00:01:00"),
+			end:   at("00:01:00"),
+			want:  `[{"metric": {"code": "500", "job": "a"}, "value": [1569888060, "0.03333333333333333"]}]`,
+		},
+		{
+			name:       "function over time at each step",
+			query:      `max_over_time(up{job="a"}[45s])`,
+			start:      at("00:00:30"),
+			end:        at("00:01:00"),
+			step:       30 * time.Second,
+			resultType: promql.MatrixResult,
+			want:       `[{"metric": {"instance": "x", "job": "a"}, "values": [[1569888030, "1"], [1569888060, "1"]]}]`,
+		},
+		{
+			name:  "arithmetic with a count",
+			query: `count_over_time(up[1m]) * 2`,
+			start: at("00:01:00"),
+			end:   at("00:01:00"),
+			want: `[
+				{"metric": {"instance": "x", "job": "a"}, "value": [1569888060, "4"]},
+				{"metric": {"instance": "y", "job": "b"}, "value": [1569888060, "4"]}
+			]`,
+		},
+		{
+			name:       "arithmetic at each step",
+			query:      `up{job="a"} * 2 + 1`,
+			start:      at("00:00:00"),
+			end:        at("00:01:00"),
+			step:       30 * time.Second,
+			resultType: promql.MatrixResult,
+			want:       `[{"metric": {"instance": "x", "job": "a"}, "values": [[1569888000, "3"], [1569888030, "1"], [1569888060, "3"]]}]`,
+		},
+		{
+			name:  "scalar on the left hand side",
+			query: `10 - up{job="b"}`,
+			start: at("00:00:40"),
+			end:   at("00:00:40"),
+			want:  `[{"metric": {"instance": "y", "job": "b"}, "value": [1569888040, "9"]}]`,
+		},
+		{
+			name:  "comparison filters",
+			query: `up == 1`,
+			start: at("00:00:40"),
+			end:   at("00:00:40"),
+			want:  `[{"metric": {"__name__": "up", "instance": "y", "job": "b"}, "value": [1569888040, "1"]}]`,
+		},
+		{
+			name:  "comparison with bool",
+			query: `up > bool 0`,
+			start: at("00:00:40"),
+			end:   at("00:00:40"),
+			want: `[
+				{"metric": {"instance": "x", "job": "a"}, "value": [1569888040, "0"]},
+				{"metric": {"instance": "y", "job": "b"}, "value": [1569888040, "1"]}
+			]`,
+		},
+		{
+			name:    "binary operator between vectors",
+			query:   `up * up`,
+			start:   at("00:00:00"),
+			end:     at("00:00:00"),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported binary operator",
+			query:   `up % 2`,
+			start:   at("00:00:00"),
+			end:     at("00:00:00"),
+			wantErr: true,
+		},
+		{
+			name:    "scalar",
+			query:   `1 + 1`,
+			start:   at("00:00:00"),
+			end:     at("00:00:00"),
+			wantErr: true,
+		},
+		{
+			name:    "comparison between scalars without bool",
+			query:   `1 > 2`,
+			start:   at("00:00:00"),
+			end:     at("00:00:00"),
+			wantErr: true,
+		},
+		{
+			name:    "function of an instant vector",
+			query:   `rate(up)`,
+			start:   at("00:00:00"),
+			end:     at("00:00:00"),
+			wantErr: true,
+		},
+		{
+			name:    "range vector in a range query",
+			query:   `up[1m]`,
+			start:   at("00:00:00"),
+			end:     at("00:01:00"),
+			step:    time.Minute,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported aggregate",
+			query:   `topk(3, up)`,
+			start:   at("00:00:00"),
+			end:     at("00:00:00"),
+			wantErr: true,
+		},
+	}
+
+	querier := fluxquerytest.NewQuerier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiler := &promql.Compiler{
+				Query: tt.query,
+				Start: tt.start,
+				End:   tt.end,
+				Step:  tt.step,
+			}
+			spec, err := compiler.Compile(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			fluxquerytest.ReplaceOpInSpec(spec, func(op *flux.Operation) flux.OperationSpec {
+				if op.Spec.Kind() == influxdb.FromKind {
+					return &csv.FromCSVOpSpec{CSV: samples}
+				}
+				return nil
+			})
+
+			var buf bytes.Buffer
+			dialect := &promql.Dialect{ResultType: tt.resultType}
+			if _, err := querier.Query(context.Background(), &buf, lang.SpecCompiler{Spec: spec}, dialect); err != nil {
+				t.Fatalf("unexpected query error %v: %s", err, buf.String())
+			}
+
+			var resp promql.Response
+			if err := json.Unmarshal(buf.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			var want []*promql.Series
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Status != "success" {
+				t.Fatalf("unexpected response %s", buf.String())
+			}
+			if !cmp.Equal(want, resp.Data.Result) {
+				t.Errorf("unexpected result -want/+got\n%s", cmp.Diff(want, resp.Data.Result))
+			}
+		})
+	}
+}
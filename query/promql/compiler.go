@@ -0,0 +1,52 @@
+package promql
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux"
+)
+
+const CompilerType = "promql"
+
+// DefaultBucket is the bucket PromQL queries read from when none is given.
+const DefaultBucket = "prometheus"
+
+// AddCompilerMappings adds the promql specific compiler mappings.
+func AddCompilerMappings(mappings flux.CompilerMappings) error {
+	return mappings.Add(CompilerType, func() flux.Compiler {
+		return new(Compiler)
+	})
+}
+
+// Compiler converts a PromQL query to a Flux specification.
+type Compiler struct {
+	Query  string        `json:"query"`
+	Bucket string        `json:"bucket,omitempty"`
+	Start  time.Time     `json:"start"`
+	End    time.Time     `json:"end"`
+	Step   time.Duration `json:"step,omitempty"`
+}
+
+// Compile builds the specification evaluating the query over the bucket.
+func (c *Compiler) Compile(ctx context.Context) (*flux.Spec, error) {
+	bucket := c.Bucket
+	if bucket == "" {
+		bucket = DefaultBucket
+	}
+	return Build(c.Query, Evaluation{
+		Bucket: bucket,
+		Start:  c.Start,
+		End:    c.End,
+		Step:   c.Step,
+	})
+}
+
+func (c *Compiler) CompilerType() flux.CompilerType {
+	return CompilerType
+}
+
+// QueryText returns the text of the query.
+func (c *Compiler) QueryText() string {
+	return c.Query
+}
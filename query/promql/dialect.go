@@ -0,0 +1,39 @@
+package promql
+
+import (
+	"net/http"
+
+	"github.com/influxdata/flux"
+)
+
+const DialectType = "promql"
+
+// AddDialectMappings adds the promql specific dialect mappings.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	return mappings.Add(DialectType, func() flux.Dialect {
+		return new(Dialect)
+	})
+}
+
+// Dialect encodes results as a response of the Prometheus HTTP API.
+type Dialect struct {
+	// ResultType is whether each result row is a sample of an instant
+	// vector, or each table is a series of a matrix; defaults to a vector.
+	ResultType ResultType `json:"resultType,omitempty"`
+}
+
+func (d *Dialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+}
+
+func (d *Dialect) Encoder() flux.MultiResultEncoder {
+	rt := d.ResultType
+	if rt == "" {
+		rt = VectorResult
+	}
+	return &MultiResultEncoder{ResultType: rt}
+}
+
+func (d *Dialect) DialectType() flux.DialectType {
+	return DialectType
+}
@@ -0,0 +1,489 @@
+package promql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/influxdb/prometheus/remote"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+)
+
+// pipeline builds a flux query specification where each operation consumes
+// the result of the one before.
+type pipeline struct {
+	spec *flux.Spec
+}
+
+func newPipeline(e Evaluation) *pipeline {
+	return &pipeline{spec: &flux.Spec{Now: e.End}}
+}
+
+func (p *pipeline) add(spec flux.OperationSpec) {
+	op := &flux.Operation{
+		ID:   flux.OperationID(fmt.Sprintf("%s%d", spec.Kind(), len(p.spec.Operations))),
+		Spec: spec,
+	}
+	if n := len(p.spec.Operations); n > 0 {
+		p.spec.Edges = append(p.spec.Edges, flux.Edge{
+			Parent: p.spec.Operations[n-1].ID,
+			Child:  op.ID,
+		})
+	}
+	p.spec.Operations = append(p.spec.Operations, op)
+}
+
+// read reads the samples of the series selected by s between start and stop.
+func (p *pipeline) read(s *Selector, bucket string, start, stop time.Time) error {
+	metric, labels, err := selectorPredicates(s)
+	if err != nil {
+		return err
+	}
+	p.add(&influxdb.FromOpSpec{Bucket: bucket})
+	p.add(newRangeOpSpec(start, stop))
+	p.add(&universe.FilterOpSpec{Fn: metric})
+	if labels != nil {
+		p.add(&universe.FilterOpSpec{Fn: labels})
+	}
+	return nil
+}
+
+// selectRange selects the samples in the range of s before the evaluation
+// time of e.
+func (p *pipeline) selectRange(s *Selector, e Evaluation) error {
+	end := e.End.Add(-s.Offset)
+	// The range includes its end but not its start.
+	return p.read(s, e.Bucket, end.Add(-s.Range+1), end.Add(1))
+}
+
+// selectInstant selects the latest sample of each series within the
+// lookback delta of each evaluation time of e, with the evaluation time as
+// its time.
+func (p *pipeline) selectInstant(s *Selector, e Evaluation) error {
+	if err := p.selectWindows(s, e, DefaultLookbackDelta); err != nil {
+		return err
+	}
+	p.add(&universe.LastOpSpec{SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel}})
+	p.atEvaluationTimes(e)
+	return nil
+}
+
+// selectWindows selects the samples of each series within period before
+// each evaluation time of e, in a table for each series and evaluation
+// time. The tables stop at their evaluation time.
+func (p *pipeline) selectWindows(s *Selector, e Evaluation, period time.Duration) error {
+	step := e.Step
+	if e.Instant() {
+		step = period
+	}
+
+	// Samples are read from the period before the first evaluation to a
+	// step after the last one, so that no window of samples that is
+	// evaluated is cut short. The extra evaluations are removed by
+	// atEvaluationTimes.
+	start := e.Start.Add(-s.Offset - period + 1)
+	stop := e.End.Add(-s.Offset + step + 1)
+	if err := p.read(s, e.Bucket, start, stop); err != nil {
+		return err
+	}
+
+	// Windows include their start but not their stop, whereas the period
+	// includes the evaluation time but not the time the period before it.
+	// Shifting the samples a nanosecond earlier accounts for both, and the
+	// shift also undoes the offset of the selector.
+	p.add(&universe.ShiftOpSpec{
+		Shift:   flux.Duration(s.Offset - 1),
+		Columns: []string{execute.DefaultTimeColLabel},
+	})
+
+	// Each window stops at an evaluation time.
+	offset := time.Duration(e.Start.UnixNano() % int64(step))
+	if offset < 0 {
+		offset += step
+	}
+	p.add(&universe.WindowOpSpec{
+		Every:       flux.Duration(step),
+		Period:      flux.Duration(period),
+		Offset:      flux.Duration(offset),
+		TimeColumn:  execute.DefaultTimeColLabel,
+		StartColumn: execute.DefaultStartColLabel,
+		StopColumn:  execute.DefaultStopColLabel,
+	})
+	return nil
+}
+
+// atEvaluationTimes merges the windows of selectWindows, reduced to a row
+// each, into a table for each series with the evaluation times of e as the
+// times of the rows.
+func (p *pipeline) atEvaluationTimes(e Evaluation) {
+	p.add(&universe.DropOpSpec{Columns: []string{execute.DefaultTimeColLabel}})
+	p.add(&universe.DuplicateOpSpec{Column: execute.DefaultStopColLabel, As: execute.DefaultTimeColLabel})
+	p.add(&universe.WindowOpSpec{
+		Every:       flux.Duration(math.MaxInt64),
+		Period:      flux.Duration(math.MaxInt64),
+		TimeColumn:  execute.DefaultTimeColLabel,
+		StartColumn: execute.DefaultStartColLabel,
+		StopColumn:  execute.DefaultStopColLabel,
+	})
+	p.add(newRangeOpSpec(e.Start, e.End.Add(1)))
+}
+
+// call applies fn to the samples in the range of s before each evaluation
+// time of e.
+//
+// Unlike Prometheus, rate and increase do not extrapolate to the edges of
+// the range: they are computed from the samples in the range only. A
+// decrease between samples is taken to be a counter reset, and is ignored.
+func (p *pipeline) call(fn FunctionKind, s *Selector, e Evaluation) error {
+	if err := p.selectWindows(s, e, s.Range); err != nil {
+		return err
+	}
+
+	value := []string{execute.DefaultValueColLabel}
+	switch fn {
+	case RateKind, IncreaseKind:
+		p.add(&universe.DifferenceOpSpec{NonNegative: true, Columns: value})
+		p.add(&universe.SumOpSpec{AggregateConfig: execute.AggregateConfig{Columns: value}})
+		// The sum of a window with a single sample is null. A selector
+		// removes it, as a row function fails on a null value.
+		p.add(&universe.LastOpSpec{SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel}})
+	case IrateKind:
+		p.add(&universe.DerivativeOpSpec{
+			Unit:        flux.Duration(time.Second),
+			NonNegative: true,
+			Columns:     value,
+			TimeColumn:  execute.DefaultTimeColLabel,
+		})
+		p.add(&universe.LastOpSpec{SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel}})
+	case AvgOverTimeKind:
+		p.add(&universe.MeanOpSpec{AggregateConfig: execute.AggregateConfig{Columns: value}})
+	case MinOverTimeKind:
+		p.add(&universe.MinOpSpec{SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel}})
+	case MaxOverTimeKind:
+		p.add(&universe.MaxOpSpec{SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel}})
+	case SumOverTimeKind:
+		p.add(&universe.SumOpSpec{AggregateConfig: execute.AggregateConfig{Columns: value}})
+	case CountOverTimeKind:
+		p.count()
+	default:
+		return fmt.Errorf("unsupported function %s", functionNames[fn])
+	}
+	p.atEvaluationTimes(e)
+
+	if fn == RateKind {
+		p.mapValue(&semantic.BinaryExpression{
+			Operator: ast.DivisionOperator,
+			Left:     valueColumn(),
+			Right:    &semantic.FloatLiteral{Value: s.Range.Seconds()},
+		})
+	}
+	// The result of a function is not the metric it is applied to.
+	p.dropMetricName()
+	return nil
+}
+
+// binary applies the arithmetic or comparison operator op between each
+// sample and a scalar, which is the left hand side lhs if it is not nil
+// and otherwise the right hand side rhs. A comparison filters the samples
+// unless returnBool is set, in which case the sample values become 1 or 0.
+func (p *pipeline) binary(op BinaryOpKind, lhs, rhs *float64, returnBool bool) {
+	var left, right semantic.Expression = valueColumn(), valueColumn()
+	if lhs != nil {
+		left = &semantic.FloatLiteral{Value: *lhs}
+	} else {
+		right = &semantic.FloatLiteral{Value: *rhs}
+	}
+	expr := &semantic.BinaryExpression{
+		Operator: binaryOperators[op],
+		Left:     left,
+		Right:    right,
+	}
+
+	if op.comparison() && !returnBool {
+		p.add(&universe.FilterOpSpec{Fn: rowFunction("r", expr)})
+		return
+	}
+	if op.comparison() {
+		p.mapValue(&semantic.CallExpression{
+			Callee: &semantic.IdentifierExpression{Name: "float"},
+			Arguments: &semantic.ObjectExpression{
+				Properties: []*semantic.Property{{Key: &semantic.Identifier{Name: "v"}, Value: expr}},
+			},
+		})
+	} else {
+		p.mapValue(expr)
+	}
+	p.dropMetricName()
+}
+
+var binaryOperators = map[BinaryOpKind]ast.OperatorKind{
+	AddKind: ast.AdditionOperator,
+	SubKind: ast.SubtractionOperator,
+	MulKind: ast.MultiplicationOperator,
+	DivKind: ast.DivisionOperator,
+	EqlKind: ast.EqualOperator,
+	NeqKind: ast.NotEqualOperator,
+	LssKind: ast.LessThanOperator,
+	LteKind: ast.LessThanEqualOperator,
+	GtrKind: ast.GreaterThanOperator,
+	GteKind: ast.GreaterThanEqualOperator,
+}
+
+// mapValue replaces the value of each sample with expr.
+func (p *pipeline) mapValue(expr semantic.Expression) {
+	p.add(&universe.MapOpSpec{
+		Fn: rowFunction("r", &semantic.ObjectExpression{
+			Properties: []*semantic.Property{
+				{
+					Key:   &semantic.Identifier{Name: execute.DefaultTimeColLabel},
+					Value: column(execute.DefaultTimeColLabel),
+				},
+				{
+					Key:   &semantic.Identifier{Name: execute.DefaultValueColLabel},
+					Value: expr,
+				},
+			},
+		}),
+		MergeKey: true,
+	})
+}
+
+// count counts the samples of each table. The count is a float, as all
+// sample values are, so that it can be used in binary operations.
+func (p *pipeline) count() {
+	p.mapValue(&semantic.FloatLiteral{Value: 1})
+	p.add(&universe.SumOpSpec{AggregateConfig: execute.AggregateConfig{Columns: []string{execute.DefaultValueColLabel}}})
+}
+
+func (p *pipeline) dropMetricName() {
+	p.add(&universe.DropOpSpec{Columns: []string{columnName(remote.MetricNameLabel)}})
+}
+
+// aggregate aggregates the series selected at each evaluation time by the
+// labels of a.
+func (p *pipeline) aggregate(a *AggregateExpr) error {
+	var labels []string
+	without := a.Aggregate != nil && a.Aggregate.Without
+	if a.Aggregate != nil {
+		for _, l := range a.Aggregate.Labels {
+			labels = append(labels, columnName(l.Name))
+		}
+	}
+
+	// Without the labels, the series are grouped by all of their other
+	// labels, which are those that are not a column of every sample.
+	var group *universe.GroupOpSpec
+	if without {
+		ignored := append(labels[:len(labels):len(labels)],
+			columnName(remote.MetricNameLabel),
+			execute.DefaultStartColLabel,
+			execute.DefaultStopColLabel,
+			"_field",
+			execute.DefaultValueColLabel,
+		)
+		group = &universe.GroupOpSpec{Mode: "except", Columns: ignored}
+	} else {
+		group = &universe.GroupOpSpec{
+			Mode:    "by",
+			Columns: append(labels[:len(labels):len(labels)], execute.DefaultTimeColLabel),
+		}
+	}
+	p.add(group)
+
+	columns := []string{execute.DefaultValueColLabel}
+	switch a.Op.Kind {
+	case SumKind:
+		p.add(&universe.SumOpSpec{AggregateConfig: execute.AggregateConfig{Columns: columns}})
+	case CountKind:
+		p.count()
+	case AvgKind:
+		p.add(&universe.MeanOpSpec{AggregateConfig: execute.AggregateConfig{Columns: columns}})
+	case MinKind, MaxKind:
+		if a.Op.Kind == MinKind {
+			p.add(&universe.MinOpSpec{SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel}})
+		} else {
+			p.add(&universe.MaxOpSpec{SelectorConfig: execute.SelectorConfig{Column: execute.DefaultValueColLabel}})
+		}
+		// Selectors keep the labels of the series they select from, which
+		// the aggregated series does not have.
+		p.add(&universe.KeepOpSpec{Predicate: columnPredicate(group, execute.DefaultValueColLabel)})
+	default:
+		return fmt.Errorf("unsupported aggregation operator %s", operatorNames[a.Op.Kind])
+	}
+
+	if without {
+		p.add(&universe.GroupOpSpec{
+			Mode:    "except",
+			Columns: []string{execute.DefaultTimeColLabel, execute.DefaultValueColLabel},
+		})
+	} else {
+		p.add(&universe.GroupOpSpec{Mode: "by", Columns: labels})
+	}
+	p.add(&universe.SortOpSpec{Columns: []string{execute.DefaultTimeColLabel}})
+	return nil
+}
+
+// columnPredicate returns the function matching the columns of the group
+// key of g and the columns in also.
+func columnPredicate(g *universe.GroupOpSpec, also ...string) *semantic.FunctionExpression {
+	op, logical := ast.EqualOperator, ast.OrOperator
+	if g.Mode == "except" {
+		op, logical = ast.NotEqualOperator, ast.AndOperator
+	}
+	var key semantic.Expression = &semantic.BooleanLiteral{Value: g.Mode == "except"}
+	for _, c := range g.Columns {
+		key = &semantic.LogicalExpression{
+			Operator: logical,
+			Left:     key,
+			Right: &semantic.BinaryExpression{
+				Operator: op,
+				Left:     &semantic.IdentifierExpression{Name: "column"},
+				Right:    &semantic.StringLiteral{Value: c},
+			},
+		}
+	}
+	for _, c := range also {
+		key = &semantic.LogicalExpression{
+			Operator: ast.OrOperator,
+			Left:     key,
+			Right: &semantic.BinaryExpression{
+				Operator: ast.EqualOperator,
+				Left:     &semantic.IdentifierExpression{Name: "column"},
+				Right:    &semantic.StringLiteral{Value: c},
+			},
+		}
+	}
+	return rowFunction("column", key)
+}
+
+var operatorNames = map[OperatorKind]string{
+	CountValuesKind: "count_values",
+	TopKind:         "topk",
+	BottomKind:      "bottomk",
+	QuantileKind:    "quantile",
+	SumKind:         "sum",
+	MinKind:         "min",
+	MaxKind:         "max",
+	AvgKind:         "avg",
+	StdevKind:       "stddev",
+	StdVarKind:      "stdvar",
+	CountKind:       "count",
+}
+
+func newRangeOpSpec(start, stop time.Time) *universe.RangeOpSpec {
+	return &universe.RangeOpSpec{
+		Start:       flux.Time{Absolute: start},
+		Stop:        flux.Time{Absolute: stop},
+		TimeColumn:  execute.DefaultTimeColLabel,
+		StartColumn: execute.DefaultStartColLabel,
+		StopColumn:  execute.DefaultStopColLabel,
+	}
+}
+
+// columnName returns the column of the Prometheus label name.
+func columnName(label string) string {
+	if label == remote.MetricNameLabel {
+		return "_measurement"
+	}
+	return label
+}
+
+// selectorPredicates returns the filter functions selecting the samples of
+// the metric of s, and those of its series matched by the label matchers of
+// s, if it has any. The label matchers are applied to the samples of the
+// metric only, as a row function fails on a label the row does not have.
+func selectorPredicates(s *Selector) (metric, labels *semantic.FunctionExpression, err error) {
+	metric = rowFunction("r", &semantic.LogicalExpression{
+		Operator: ast.AndOperator,
+		Left:     compareColumn("_measurement", ast.EqualOperator, &semantic.StringLiteral{Value: s.Name}),
+		Right:    compareColumn("_field", ast.EqualOperator, &semantic.StringLiteral{Value: remote.FieldKey}),
+	})
+	if len(s.LabelMatchers) == 0 {
+		return metric, nil, nil
+	}
+
+	var node semantic.Expression
+
+	for _, m := range s.LabelMatchers {
+		var value string
+		switch v := m.Value.Value().(type) {
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return nil, nil, fmt.Errorf("invalid value for label %s", m.Name)
+		}
+
+		var cmp semantic.Expression
+		switch m.Kind {
+		case Equal:
+			cmp = compareColumn(columnName(m.Name), ast.EqualOperator, &semantic.StringLiteral{Value: value})
+		case NotEqual:
+			cmp = compareColumn(columnName(m.Name), ast.NotEqualOperator, &semantic.StringLiteral{Value: value})
+		case RegexMatch, RegexNoMatch:
+			// Prometheus regular expressions match the whole label value.
+			re, err := regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid regular expression for label %s: %v", m.Name, err)
+			}
+			op := ast.RegexpMatchOperator
+			if m.Kind == RegexNoMatch {
+				op = ast.NotRegexpMatchOperator
+			}
+			cmp = compareColumn(columnName(m.Name), op, &semantic.RegexpLiteral{Value: re})
+		default:
+			return nil, nil, fmt.Errorf("unknown label match kind %d", m.Kind)
+		}
+
+		if node == nil {
+			node = cmp
+			continue
+		}
+		node = &semantic.LogicalExpression{
+			Operator: ast.AndOperator,
+			Left:     node,
+			Right:    cmp,
+		}
+	}
+	return metric, rowFunction("r", node), nil
+}
+
+func compareColumn(name string, op ast.OperatorKind, value semantic.Expression) *semantic.BinaryExpression {
+	return &semantic.BinaryExpression{
+		Operator: op,
+		Left:     column(name),
+		Right:    value,
+	}
+}
+
+// column returns the expression of the column name of the row r.
+func column(name string) *semantic.MemberExpression {
+	return &semantic.MemberExpression{
+		Object:   &semantic.IdentifierExpression{Name: "r"},
+		Property: name,
+	}
+}
+
+func valueColumn() *semantic.MemberExpression {
+	return column(execute.DefaultValueColLabel)
+}
+
+// rowFunction returns the function of the parameter param evaluating body.
+func rowFunction(param string, body semantic.Expression) *semantic.FunctionExpression {
+	return &semantic.FunctionExpression{
+		Block: &semantic.FunctionBlock{
+			Parameters: &semantic.FunctionParameters{
+				List: []*semantic.FunctionParameter{{Key: &semantic.Identifier{Name: param}}},
+			},
+			Body: body,
+		},
+	}
+}
@@ -31,34 +31,34 @@ var g = &grammar{
 			name: "Grammar",
 			pos:  position{line: 11, col: 1, offset: 234},
 			expr: &actionExpr{
-				pos: position{line: 11, col: 12, offset: 245},
+				pos: position{line: 11, col: 11, offset: 244},
 				run: (*parser).callonGrammar1,
 				expr: &seqExpr{
-					pos: position{line: 11, col: 12, offset: 245},
+					pos: position{line: 11, col: 11, offset: 244},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 11, col: 12, offset: 245},
+							pos:   position{line: 11, col: 11, offset: 244},
 							label: "grammar",
 							expr: &choiceExpr{
-								pos: position{line: 11, col: 22, offset: 255},
+								pos: position{line: 11, col: 21, offset: 254},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 11, col: 22, offset: 255},
+										pos:  position{line: 11, col: 21, offset: 254},
 										name: "Comment",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 11, col: 32, offset: 265},
-										name: "AggregateExpression",
-									},
-									&ruleRefExpr{
-										pos:  position{line: 11, col: 54, offset: 287},
-										name: "VectorSelector",
+										pos:  position{line: 11, col: 31, offset: 264},
+										name: "Expression",
 									},
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 11, col: 71, offset: 304},
+							pos:  position{line: 11, col: 44, offset: 277},
+							name: "__",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 11, col: 47, offset: 280},
 							name: "EOF",
 						},
 					},
@@ -67,39 +67,39 @@ var g = &grammar{
 		},
 		{
 			name: "SourceChar",
-			pos:  position{line: 15, col: 1, offset: 337},
+			pos:  position{line: 15, col: 1, offset: 313},
 			expr: &anyMatcher{
-				line: 15, col: 14, offset: 350,
+				line: 15, col: 14, offset: 326,
 			},
 		},
 		{
 			name: "Comment",
-			pos:  position{line: 17, col: 1, offset: 353},
+			pos:  position{line: 17, col: 1, offset: 329},
 			expr: &actionExpr{
-				pos: position{line: 17, col: 11, offset: 363},
+				pos: position{line: 17, col: 11, offset: 339},
 				run: (*parser).callonComment1,
 				expr: &seqExpr{
-					pos: position{line: 17, col: 11, offset: 363},
+					pos: position{line: 17, col: 11, offset: 339},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 17, col: 11, offset: 363},
+							pos:        position{line: 17, col: 11, offset: 339},
 							val:        "#",
 							ignoreCase: false,
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 17, col: 15, offset: 367},
+							pos: position{line: 17, col: 15, offset: 343},
 							expr: &seqExpr{
-								pos: position{line: 17, col: 17, offset: 369},
+								pos: position{line: 17, col: 17, offset: 345},
 								exprs: []interface{}{
 									&notExpr{
-										pos: position{line: 17, col: 17, offset: 369},
+										pos: position{line: 17, col: 17, offset: 345},
 										expr: &ruleRefExpr{
-											pos:  position{line: 17, col: 18, offset: 370},
+											pos:  position{line: 17, col: 18, offset: 346},
 											name: "EOL",
 										},
 									},
 									&ruleRefExpr{
-										pos:  position{line: 17, col: 22, offset: 374},
+										pos:  position{line: 17, col: 22, offset: 350},
 										name: "SourceChar",
 									},
 								},
@@ -111,15 +111,15 @@ var g = &grammar{
 		},
 		{
 			name: "Identifier",
-			pos:  position{line: 21, col: 1, offset: 434},
+			pos:  position{line: 21, col: 1, offset: 410},
 			expr: &actionExpr{
-				pos: position{line: 21, col: 14, offset: 447},
+				pos: position{line: 21, col: 14, offset: 423},
 				run: (*parser).callonIdentifier1,
 				expr: &labeledExpr{
-					pos:   position{line: 21, col: 14, offset: 447},
+					pos:   position{line: 21, col: 14, offset: 423},
 					label: "ident",
 					expr: &ruleRefExpr{
-						pos:  position{line: 21, col: 20, offset: 453},
+						pos:  position{line: 21, col: 20, offset: 429},
 						name: "IdentifierName",
 					},
 				},
@@ -127,21 +127,21 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierName",
-			pos:  position{line: 29, col: 1, offset: 637},
+			pos:  position{line: 28, col: 1, offset: 602},
 			expr: &actionExpr{
-				pos: position{line: 29, col: 18, offset: 654},
+				pos: position{line: 28, col: 18, offset: 619},
 				run: (*parser).callonIdentifierName1,
 				expr: &seqExpr{
-					pos: position{line: 29, col: 18, offset: 654},
+					pos: position{line: 28, col: 18, offset: 619},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 29, col: 18, offset: 654},
+							pos:  position{line: 28, col: 18, offset: 619},
 							name: "IdentifierStart",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 29, col: 34, offset: 670},
+							pos: position{line: 28, col: 34, offset: 635},
 							expr: &ruleRefExpr{
-								pos:  position{line: 29, col: 34, offset: 670},
+								pos:  position{line: 28, col: 34, offset: 635},
 								name: "IdentifierPart",
 							},
 						},
@@ -151,9 +151,9 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierStart",
-			pos:  position{line: 32, col: 1, offset: 721},
+			pos:  position{line: 31, col: 1, offset: 686},
 			expr: &charClassMatcher{
-				pos:        position{line: 32, col: 19, offset: 739},
+				pos:        position{line: 31, col: 19, offset: 704},
 				val:        "[\\pL_]",
 				chars:      []rune{'_'},
 				classes:    []*unicode.RangeTable{rangeTable("L")},
@@ -163,16 +163,16 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierPart",
-			pos:  position{line: 33, col: 1, offset: 746},
+			pos:  position{line: 32, col: 1, offset: 711},
 			expr: &choiceExpr{
-				pos: position{line: 33, col: 18, offset: 763},
+				pos: position{line: 32, col: 18, offset: 728},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 33, col: 18, offset: 763},
+						pos:  position{line: 32, col: 18, offset: 728},
 						name: "IdentifierStart",
 					},
 					&charClassMatcher{
-						pos:        position{line: 33, col: 36, offset: 781},
+						pos:        position{line: 32, col: 36, offset: 746},
 						val:        "[\\p{Nd}]",
 						classes:    []*unicode.RangeTable{rangeTable("Nd")},
 						ignoreCase: false,
@@ -183,74 +183,74 @@ var g = &grammar{
 		},
 		{
 			name: "StringLiteral",
-			pos:  position{line: 35, col: 1, offset: 791},
+			pos:  position{line: 34, col: 1, offset: 756},
 			expr: &choiceExpr{
-				pos: position{line: 35, col: 17, offset: 807},
+				pos: position{line: 34, col: 17, offset: 772},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 35, col: 17, offset: 807},
+						pos: position{line: 34, col: 17, offset: 772},
 						run: (*parser).callonStringLiteral2,
 						expr: &choiceExpr{
-							pos: position{line: 35, col: 19, offset: 809},
+							pos: position{line: 34, col: 19, offset: 774},
 							alternatives: []interface{}{
 								&seqExpr{
-									pos: position{line: 35, col: 19, offset: 809},
+									pos: position{line: 34, col: 19, offset: 774},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 35, col: 19, offset: 809},
+											pos:        position{line: 34, col: 19, offset: 774},
 											val:        "\"",
 											ignoreCase: false,
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 35, col: 23, offset: 813},
+											pos: position{line: 34, col: 23, offset: 778},
 											expr: &ruleRefExpr{
-												pos:  position{line: 35, col: 23, offset: 813},
+												pos:  position{line: 34, col: 23, offset: 778},
 												name: "DoubleStringChar",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 35, col: 41, offset: 831},
+											pos:        position{line: 34, col: 41, offset: 796},
 											val:        "\"",
 											ignoreCase: false,
 										},
 									},
 								},
 								&seqExpr{
-									pos: position{line: 35, col: 47, offset: 837},
+									pos: position{line: 34, col: 47, offset: 802},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 35, col: 47, offset: 837},
+											pos:        position{line: 34, col: 47, offset: 802},
 											val:        "'",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 35, col: 51, offset: 841},
+											pos:  position{line: 34, col: 51, offset: 806},
 											name: "SingleStringChar",
 										},
 										&litMatcher{
-											pos:        position{line: 35, col: 68, offset: 858},
+											pos:        position{line: 34, col: 68, offset: 823},
 											val:        "'",
 											ignoreCase: false,
 										},
 									},
 								},
 								&seqExpr{
-									pos: position{line: 35, col: 74, offset: 864},
+									pos: position{line: 34, col: 74, offset: 829},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 35, col: 74, offset: 864},
+											pos:        position{line: 34, col: 74, offset: 829},
 											val:        "`",
 											ignoreCase: false,
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 35, col: 78, offset: 868},
+											pos: position{line: 34, col: 78, offset: 833},
 											expr: &ruleRefExpr{
-												pos:  position{line: 35, col: 78, offset: 868},
+												pos:  position{line: 34, col: 78, offset: 833},
 												name: "RawStringChar",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 35, col: 93, offset: 883},
+											pos:        position{line: 34, col: 93, offset: 848},
 											val:        "`",
 											ignoreCase: false,
 										},
@@ -260,35 +260,35 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 41, col: 5, offset: 1029},
+						pos: position{line: 40, col: 5, offset: 994},
 						run: (*parser).callonStringLiteral18,
 						expr: &choiceExpr{
-							pos: position{line: 41, col: 7, offset: 1031},
+							pos: position{line: 40, col: 7, offset: 996},
 							alternatives: []interface{}{
 								&seqExpr{
-									pos: position{line: 41, col: 9, offset: 1033},
+									pos: position{line: 40, col: 9, offset: 998},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 41, col: 9, offset: 1033},
+											pos:        position{line: 40, col: 9, offset: 998},
 											val:        "\"",
 											ignoreCase: false,
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 41, col: 13, offset: 1037},
+											pos: position{line: 40, col: 13, offset: 1002},
 											expr: &ruleRefExpr{
-												pos:  position{line: 41, col: 13, offset: 1037},
+												pos:  position{line: 40, col: 13, offset: 1002},
 												name: "DoubleStringChar",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 41, col: 33, offset: 1057},
+											pos: position{line: 40, col: 33, offset: 1022},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 41, col: 33, offset: 1057},
+													pos:  position{line: 40, col: 33, offset: 1022},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 41, col: 39, offset: 1063},
+													pos:  position{line: 40, col: 39, offset: 1028},
 													name: "EOF",
 												},
 											},
@@ -296,29 +296,29 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 41, col: 51, offset: 1075},
+									pos: position{line: 40, col: 51, offset: 1040},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 41, col: 51, offset: 1075},
+											pos:        position{line: 40, col: 51, offset: 1040},
 											val:        "'",
 											ignoreCase: false,
 										},
 										&zeroOrOneExpr{
-											pos: position{line: 41, col: 55, offset: 1079},
+											pos: position{line: 40, col: 55, offset: 1044},
 											expr: &ruleRefExpr{
-												pos:  position{line: 41, col: 55, offset: 1079},
+												pos:  position{line: 40, col: 55, offset: 1044},
 												name: "SingleStringChar",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 41, col: 75, offset: 1099},
+											pos: position{line: 40, col: 75, offset: 1064},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 41, col: 75, offset: 1099},
+													pos:  position{line: 40, col: 75, offset: 1064},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 41, col: 81, offset: 1105},
+													pos:  position{line: 40, col: 81, offset: 1070},
 													name: "EOF",
 												},
 											},
@@ -326,22 +326,22 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 41, col: 91, offset: 1115},
+									pos: position{line: 40, col: 91, offset: 1080},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 41, col: 91, offset: 1115},
+											pos:        position{line: 40, col: 91, offset: 1080},
 											val:        "`",
 											ignoreCase: false,
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 41, col: 95, offset: 1119},
+											pos: position{line: 40, col: 95, offset: 1084},
 											expr: &ruleRefExpr{
-												pos:  position{line: 41, col: 95, offset: 1119},
+												pos:  position{line: 40, col: 95, offset: 1084},
 												name: "RawStringChar",
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 41, col: 110, offset: 1134},
+											pos:  position{line: 40, col: 110, offset: 1099},
 											name: "EOF",
 										},
 									},
@@ -354,51 +354,51 @@ var g = &grammar{
 		},
 		{
 			name: "DoubleStringChar",
-			pos:  position{line: 45, col: 1, offset: 1205},
+			pos:  position{line: 44, col: 1, offset: 1170},
 			expr: &choiceExpr{
-				pos: position{line: 45, col: 20, offset: 1224},
+				pos: position{line: 44, col: 20, offset: 1189},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 45, col: 20, offset: 1224},
+						pos: position{line: 44, col: 20, offset: 1189},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 45, col: 20, offset: 1224},
+								pos: position{line: 44, col: 20, offset: 1189},
 								expr: &choiceExpr{
-									pos: position{line: 45, col: 23, offset: 1227},
+									pos: position{line: 44, col: 23, offset: 1192},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 45, col: 23, offset: 1227},
+											pos:        position{line: 44, col: 23, offset: 1192},
 											val:        "\"",
 											ignoreCase: false,
 										},
 										&litMatcher{
-											pos:        position{line: 45, col: 29, offset: 1233},
+											pos:        position{line: 44, col: 29, offset: 1198},
 											val:        "\\",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 45, col: 36, offset: 1240},
+											pos:  position{line: 44, col: 36, offset: 1205},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 45, col: 42, offset: 1246},
+								pos:  position{line: 44, col: 42, offset: 1211},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 45, col: 55, offset: 1259},
+						pos: position{line: 44, col: 55, offset: 1224},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 45, col: 55, offset: 1259},
+								pos:        position{line: 44, col: 55, offset: 1224},
 								val:        "\\",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 45, col: 60, offset: 1264},
+								pos:  position{line: 44, col: 60, offset: 1229},
 								name: "DoubleStringEscape",
 							},
 						},
@@ -408,51 +408,51 @@ var g = &grammar{
 		},
 		{
 			name: "SingleStringChar",
-			pos:  position{line: 46, col: 1, offset: 1283},
+			pos:  position{line: 45, col: 1, offset: 1248},
 			expr: &choiceExpr{
-				pos: position{line: 46, col: 20, offset: 1302},
+				pos: position{line: 45, col: 20, offset: 1267},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 46, col: 20, offset: 1302},
+						pos: position{line: 45, col: 20, offset: 1267},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 46, col: 20, offset: 1302},
+								pos: position{line: 45, col: 20, offset: 1267},
 								expr: &choiceExpr{
-									pos: position{line: 46, col: 23, offset: 1305},
+									pos: position{line: 45, col: 23, offset: 1270},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 46, col: 23, offset: 1305},
+											pos:        position{line: 45, col: 23, offset: 1270},
 											val:        "'",
 											ignoreCase: false,
 										},
 										&litMatcher{
-											pos:        position{line: 46, col: 29, offset: 1311},
+											pos:        position{line: 45, col: 29, offset: 1276},
 											val:        "\\",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 46, col: 36, offset: 1318},
+											pos:  position{line: 45, col: 36, offset: 1283},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 46, col: 42, offset: 1324},
+								pos:  position{line: 45, col: 42, offset: 1289},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 46, col: 55, offset: 1337},
+						pos: position{line: 45, col: 55, offset: 1302},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 46, col: 55, offset: 1337},
+								pos:        position{line: 45, col: 55, offset: 1302},
 								val:        "\\",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 46, col: 60, offset: 1342},
+								pos:  position{line: 45, col: 60, offset: 1307},
 								name: "SingleStringEscape",
 							},
 						},
@@ -462,20 +462,20 @@ var g = &grammar{
 		},
 		{
 			name: "RawStringChar",
-			pos:  position{line: 47, col: 1, offset: 1361},
+			pos:  position{line: 46, col: 1, offset: 1326},
 			expr: &seqExpr{
-				pos: position{line: 47, col: 17, offset: 1377},
+				pos: position{line: 46, col: 17, offset: 1342},
 				exprs: []interface{}{
 					&notExpr{
-						pos: position{line: 47, col: 17, offset: 1377},
+						pos: position{line: 46, col: 17, offset: 1342},
 						expr: &litMatcher{
-							pos:        position{line: 47, col: 18, offset: 1378},
+							pos:        position{line: 46, col: 18, offset: 1343},
 							val:        "`",
 							ignoreCase: false,
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 47, col: 22, offset: 1382},
+						pos:  position{line: 46, col: 22, offset: 1347},
 						name: "SourceChar",
 					},
 				},
@@ -483,40 +483,40 @@ var g = &grammar{
 		},
 		{
 			name: "DoubleStringEscape",
-			pos:  position{line: 49, col: 1, offset: 1394},
+			pos:  position{line: 48, col: 1, offset: 1359},
 			expr: &choiceExpr{
-				pos: position{line: 49, col: 22, offset: 1415},
+				pos: position{line: 48, col: 22, offset: 1380},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 49, col: 24, offset: 1417},
+						pos: position{line: 48, col: 24, offset: 1382},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 49, col: 24, offset: 1417},
+								pos:        position{line: 48, col: 24, offset: 1382},
 								val:        "\"",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 49, col: 30, offset: 1423},
+								pos:  position{line: 48, col: 30, offset: 1388},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 50, col: 7, offset: 1452},
+						pos: position{line: 49, col: 7, offset: 1417},
 						run: (*parser).callonDoubleStringEscape5,
 						expr: &choiceExpr{
-							pos: position{line: 50, col: 9, offset: 1454},
+							pos: position{line: 49, col: 9, offset: 1419},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 50, col: 9, offset: 1454},
+									pos:  position{line: 49, col: 9, offset: 1419},
 									name: "SourceChar",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 50, col: 22, offset: 1467},
+									pos:  position{line: 49, col: 22, offset: 1432},
 									name: "EOL",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 50, col: 28, offset: 1473},
+									pos:  position{line: 49, col: 28, offset: 1438},
 									name: "EOF",
 								},
 							},
@@ -527,40 +527,40 @@ var g = &grammar{
 		},
 		{
 			name: "SingleStringEscape",
-			pos:  position{line: 53, col: 1, offset: 1538},
+			pos:  position{line: 52, col: 1, offset: 1503},
 			expr: &choiceExpr{
-				pos: position{line: 53, col: 22, offset: 1559},
+				pos: position{line: 52, col: 22, offset: 1524},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 53, col: 24, offset: 1561},
+						pos: position{line: 52, col: 24, offset: 1526},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 53, col: 24, offset: 1561},
+								pos:        position{line: 52, col: 24, offset: 1526},
 								val:        "'",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 53, col: 30, offset: 1567},
+								pos:  position{line: 52, col: 30, offset: 1532},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 54, col: 7, offset: 1596},
+						pos: position{line: 53, col: 7, offset: 1561},
 						run: (*parser).callonSingleStringEscape5,
 						expr: &choiceExpr{
-							pos: position{line: 54, col: 9, offset: 1598},
+							pos: position{line: 53, col: 9, offset: 1563},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 54, col: 9, offset: 1598},
+									pos:  position{line: 53, col: 9, offset: 1563},
 									name: "SourceChar",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 54, col: 22, offset: 1611},
+									pos:  position{line: 53, col: 22, offset: 1576},
 									name: "EOL",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 54, col: 28, offset: 1617},
+									pos:  position{line: 53, col: 28, offset: 1582},
 									name: "EOF",
 								},
 							},
@@ -571,28 +571,28 @@ var g = &grammar{
 		},
 		{
 			name: "CommonEscapeSequence",
-			pos:  position{line: 58, col: 1, offset: 1683},
+			pos:  position{line: 57, col: 1, offset: 1648},
 			expr: &choiceExpr{
-				pos: position{line: 58, col: 24, offset: 1706},
+				pos: position{line: 57, col: 24, offset: 1671},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 58, col: 24, offset: 1706},
+						pos:  position{line: 57, col: 24, offset: 1671},
 						name: "SingleCharEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 58, col: 43, offset: 1725},
+						pos:  position{line: 57, col: 43, offset: 1690},
 						name: "OctalEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 58, col: 57, offset: 1739},
+						pos:  position{line: 57, col: 57, offset: 1704},
 						name: "HexEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 58, col: 69, offset: 1751},
+						pos:  position{line: 57, col: 69, offset: 1716},
 						name: "LongUnicodeEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 58, col: 89, offset: 1771},
+						pos:  position{line: 57, col: 89, offset: 1736},
 						name: "ShortUnicodeEscape",
 					},
 				},
@@ -600,47 +600,47 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharEscape",
-			pos:  position{line: 59, col: 1, offset: 1790},
+			pos:  position{line: 58, col: 1, offset: 1755},
 			expr: &choiceExpr{
-				pos: position{line: 59, col: 20, offset: 1809},
+				pos: position{line: 58, col: 20, offset: 1774},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 59, col: 20, offset: 1809},
+						pos:        position{line: 58, col: 20, offset: 1774},
 						val:        "a",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 59, col: 26, offset: 1815},
+						pos:        position{line: 58, col: 26, offset: 1780},
 						val:        "b",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 59, col: 32, offset: 1821},
+						pos:        position{line: 58, col: 32, offset: 1786},
 						val:        "n",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 59, col: 38, offset: 1827},
+						pos:        position{line: 58, col: 38, offset: 1792},
 						val:        "f",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 59, col: 44, offset: 1833},
+						pos:        position{line: 58, col: 44, offset: 1798},
 						val:        "r",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 59, col: 50, offset: 1839},
+						pos:        position{line: 58, col: 50, offset: 1804},
 						val:        "t",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 59, col: 56, offset: 1845},
+						pos:        position{line: 58, col: 56, offset: 1810},
 						val:        "v",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 59, col: 62, offset: 1851},
+						pos:        position{line: 58, col: 62, offset: 1816},
 						val:        "\\",
 						ignoreCase: false,
 					},
@@ -649,50 +649,50 @@ var g = &grammar{
 		},
 		{
 			name: "OctalEscape",
-			pos:  position{line: 60, col: 1, offset: 1856},
+			pos:  position{line: 59, col: 1, offset: 1821},
 			expr: &choiceExpr{
-				pos: position{line: 60, col: 15, offset: 1870},
+				pos: position{line: 59, col: 15, offset: 1835},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 60, col: 15, offset: 1870},
+						pos: position{line: 59, col: 15, offset: 1835},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 60, col: 15, offset: 1870},
+								pos:  position{line: 59, col: 15, offset: 1835},
 								name: "OctalDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 60, col: 26, offset: 1881},
+								pos:  position{line: 59, col: 26, offset: 1846},
 								name: "OctalDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 60, col: 37, offset: 1892},
+								pos:  position{line: 59, col: 37, offset: 1857},
 								name: "OctalDigit",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 61, col: 7, offset: 1909},
+						pos: position{line: 60, col: 7, offset: 1874},
 						run: (*parser).callonOctalEscape6,
 						expr: &seqExpr{
-							pos: position{line: 61, col: 7, offset: 1909},
+							pos: position{line: 60, col: 7, offset: 1874},
 							exprs: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 61, col: 7, offset: 1909},
+									pos:  position{line: 60, col: 7, offset: 1874},
 									name: "OctalDigit",
 								},
 								&choiceExpr{
-									pos: position{line: 61, col: 20, offset: 1922},
+									pos: position{line: 60, col: 20, offset: 1887},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 61, col: 20, offset: 1922},
+											pos:  position{line: 60, col: 20, offset: 1887},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 61, col: 33, offset: 1935},
+											pos:  position{line: 60, col: 33, offset: 1900},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 61, col: 39, offset: 1941},
+											pos:  position{line: 60, col: 39, offset: 1906},
 											name: "EOF",
 										},
 									},
@@ -705,52 +705,52 @@ var g = &grammar{
 		},
 		{
 			name: "HexEscape",
-			pos:  position{line: 64, col: 1, offset: 2002},
+			pos:  position{line: 63, col: 1, offset: 1967},
 			expr: &choiceExpr{
-				pos: position{line: 64, col: 13, offset: 2014},
+				pos: position{line: 63, col: 13, offset: 1979},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 64, col: 13, offset: 2014},
+						pos: position{line: 63, col: 13, offset: 1979},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 64, col: 13, offset: 2014},
+								pos:        position{line: 63, col: 13, offset: 1979},
 								val:        "x",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 64, col: 17, offset: 2018},
+								pos:  position{line: 63, col: 17, offset: 1983},
 								name: "HexDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 64, col: 26, offset: 2027},
+								pos:  position{line: 63, col: 26, offset: 1992},
 								name: "HexDigit",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 65, col: 7, offset: 2042},
+						pos: position{line: 64, col: 7, offset: 2007},
 						run: (*parser).callonHexEscape6,
 						expr: &seqExpr{
-							pos: position{line: 65, col: 7, offset: 2042},
+							pos: position{line: 64, col: 7, offset: 2007},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 65, col: 7, offset: 2042},
+									pos:        position{line: 64, col: 7, offset: 2007},
 									val:        "x",
 									ignoreCase: false,
 								},
 								&choiceExpr{
-									pos: position{line: 65, col: 13, offset: 2048},
+									pos: position{line: 64, col: 13, offset: 2013},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 65, col: 13, offset: 2048},
+											pos:  position{line: 64, col: 13, offset: 2013},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 65, col: 26, offset: 2061},
+											pos:  position{line: 64, col: 26, offset: 2026},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 65, col: 32, offset: 2067},
+											pos:  position{line: 64, col: 32, offset: 2032},
 											name: "EOF",
 										},
 									},
@@ -763,80 +763,80 @@ var g = &grammar{
 		},
 		{
 			name: "LongUnicodeEscape",
-			pos:  position{line: 68, col: 1, offset: 2134},
+			pos:  position{line: 67, col: 1, offset: 2099},
 			expr: &choiceExpr{
-				pos: position{line: 69, col: 5, offset: 2159},
+				pos: position{line: 68, col: 5, offset: 2124},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 69, col: 5, offset: 2159},
+						pos: position{line: 68, col: 5, offset: 2124},
 						run: (*parser).callonLongUnicodeEscape2,
 						expr: &seqExpr{
-							pos: position{line: 69, col: 5, offset: 2159},
+							pos: position{line: 68, col: 5, offset: 2124},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 69, col: 5, offset: 2159},
+									pos:        position{line: 68, col: 5, offset: 2124},
 									val:        "U",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 69, col: 9, offset: 2163},
+									pos:  position{line: 68, col: 9, offset: 2128},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 69, col: 18, offset: 2172},
+									pos:  position{line: 68, col: 18, offset: 2137},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 69, col: 27, offset: 2181},
+									pos:  position{line: 68, col: 27, offset: 2146},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 69, col: 36, offset: 2190},
+									pos:  position{line: 68, col: 36, offset: 2155},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 69, col: 45, offset: 2199},
+									pos:  position{line: 68, col: 45, offset: 2164},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 69, col: 54, offset: 2208},
+									pos:  position{line: 68, col: 54, offset: 2173},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 69, col: 63, offset: 2217},
+									pos:  position{line: 68, col: 63, offset: 2182},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 69, col: 72, offset: 2226},
+									pos:  position{line: 68, col: 72, offset: 2191},
 									name: "HexDigit",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 72, col: 7, offset: 2328},
+						pos: position{line: 71, col: 7, offset: 2293},
 						run: (*parser).callonLongUnicodeEscape13,
 						expr: &seqExpr{
-							pos: position{line: 72, col: 7, offset: 2328},
+							pos: position{line: 71, col: 7, offset: 2293},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 72, col: 7, offset: 2328},
+									pos:        position{line: 71, col: 7, offset: 2293},
 									val:        "U",
 									ignoreCase: false,
 								},
 								&choiceExpr{
-									pos: position{line: 72, col: 13, offset: 2334},
+									pos: position{line: 71, col: 13, offset: 2299},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 72, col: 13, offset: 2334},
+											pos:  position{line: 71, col: 13, offset: 2299},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 72, col: 26, offset: 2347},
+											pos:  position{line: 71, col: 26, offset: 2312},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 72, col: 32, offset: 2353},
+											pos:  position{line: 71, col: 32, offset: 2318},
 											name: "EOF",
 										},
 									},
@@ -849,64 +849,64 @@ var g = &grammar{
 		},
 		{
 			name: "ShortUnicodeEscape",
-			pos:  position{line: 75, col: 1, offset: 2416},
+			pos:  position{line: 74, col: 1, offset: 2381},
 			expr: &choiceExpr{
-				pos: position{line: 76, col: 5, offset: 2442},
+				pos: position{line: 75, col: 5, offset: 2407},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 76, col: 5, offset: 2442},
+						pos: position{line: 75, col: 5, offset: 2407},
 						run: (*parser).callonShortUnicodeEscape2,
 						expr: &seqExpr{
-							pos: position{line: 76, col: 5, offset: 2442},
+							pos: position{line: 75, col: 5, offset: 2407},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 76, col: 5, offset: 2442},
+									pos:        position{line: 75, col: 5, offset: 2407},
 									val:        "u",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 76, col: 9, offset: 2446},
+									pos:  position{line: 75, col: 9, offset: 2411},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 76, col: 18, offset: 2455},
+									pos:  position{line: 75, col: 18, offset: 2420},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 76, col: 27, offset: 2464},
+									pos:  position{line: 75, col: 27, offset: 2429},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 76, col: 36, offset: 2473},
+									pos:  position{line: 75, col: 36, offset: 2438},
 									name: "HexDigit",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 79, col: 7, offset: 2575},
+						pos: position{line: 78, col: 7, offset: 2540},
 						run: (*parser).callonShortUnicodeEscape9,
 						expr: &seqExpr{
-							pos: position{line: 79, col: 7, offset: 2575},
+							pos: position{line: 78, col: 7, offset: 2540},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 79, col: 7, offset: 2575},
+									pos:        position{line: 78, col: 7, offset: 2540},
 									val:        "u",
 									ignoreCase: false,
 								},
 								&choiceExpr{
-									pos: position{line: 79, col: 13, offset: 2581},
+									pos: position{line: 78, col: 13, offset: 2546},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 79, col: 13, offset: 2581},
+											pos:  position{line: 78, col: 13, offset: 2546},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 79, col: 26, offset: 2594},
+											pos:  position{line: 78, col: 26, offset: 2559},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 79, col: 32, offset: 2600},
+											pos:  position{line: 78, col: 32, offset: 2565},
 											name: "EOF",
 										},
 									},
@@ -919,9 +919,9 @@ var g = &grammar{
 		},
 		{
 			name: "OctalDigit",
-			pos:  position{line: 83, col: 1, offset: 2664},
+			pos:  position{line: 82, col: 1, offset: 2629},
 			expr: &charClassMatcher{
-				pos:        position{line: 83, col: 14, offset: 2677},
+				pos:        position{line: 82, col: 14, offset: 2642},
 				val:        "[0-7]",
 				ranges:     []rune{'0', '7'},
 				ignoreCase: false,
@@ -930,9 +930,9 @@ var g = &grammar{
 		},
 		{
 			name: "DecimalDigit",
-			pos:  position{line: 84, col: 1, offset: 2683},
+			pos:  position{line: 83, col: 1, offset: 2648},
 			expr: &charClassMatcher{
-				pos:        position{line: 84, col: 16, offset: 2698},
+				pos:        position{line: 83, col: 16, offset: 2663},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -941,9 +941,9 @@ var g = &grammar{
 		},
 		{
 			name: "HexDigit",
-			pos:  position{line: 85, col: 1, offset: 2704},
+			pos:  position{line: 84, col: 1, offset: 2669},
 			expr: &charClassMatcher{
-				pos:        position{line: 85, col: 12, offset: 2715},
+				pos:        position{line: 84, col: 12, offset: 2680},
 				val:        "[0-9a-f]i",
 				ranges:     []rune{'0', '9', 'a', 'f'},
 				ignoreCase: true,
@@ -952,44 +952,44 @@ var g = &grammar{
 		},
 		{
 			name: "CharClassMatcher",
-			pos:  position{line: 87, col: 1, offset: 2726},
+			pos:  position{line: 86, col: 1, offset: 2691},
 			expr: &choiceExpr{
-				pos: position{line: 87, col: 20, offset: 2745},
+				pos: position{line: 86, col: 20, offset: 2710},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 87, col: 20, offset: 2745},
+						pos: position{line: 86, col: 20, offset: 2710},
 						run: (*parser).callonCharClassMatcher2,
 						expr: &seqExpr{
-							pos: position{line: 87, col: 20, offset: 2745},
+							pos: position{line: 86, col: 20, offset: 2710},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 87, col: 20, offset: 2745},
+									pos:        position{line: 86, col: 20, offset: 2710},
 									val:        "[",
 									ignoreCase: false,
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 87, col: 24, offset: 2749},
+									pos: position{line: 86, col: 24, offset: 2714},
 									expr: &choiceExpr{
-										pos: position{line: 87, col: 26, offset: 2751},
+										pos: position{line: 86, col: 26, offset: 2716},
 										alternatives: []interface{}{
 											&ruleRefExpr{
-												pos:  position{line: 87, col: 26, offset: 2751},
+												pos:  position{line: 86, col: 26, offset: 2716},
 												name: "ClassCharRange",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 87, col: 43, offset: 2768},
+												pos:  position{line: 86, col: 43, offset: 2733},
 												name: "ClassChar",
 											},
 											&seqExpr{
-												pos: position{line: 87, col: 55, offset: 2780},
+												pos: position{line: 86, col: 55, offset: 2745},
 												exprs: []interface{}{
 													&litMatcher{
-														pos:        position{line: 87, col: 55, offset: 2780},
+														pos:        position{line: 86, col: 55, offset: 2745},
 														val:        "\\",
 														ignoreCase: false,
 													},
 													&ruleRefExpr{
-														pos:  position{line: 87, col: 60, offset: 2785},
+														pos:  position{line: 86, col: 60, offset: 2750},
 														name: "UnicodeClassEscape",
 													},
 												},
@@ -998,14 +998,14 @@ var g = &grammar{
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 87, col: 82, offset: 2807},
+									pos:        position{line: 86, col: 82, offset: 2772},
 									val:        "]",
 									ignoreCase: false,
 								},
 								&zeroOrOneExpr{
-									pos: position{line: 87, col: 86, offset: 2811},
+									pos: position{line: 86, col: 86, offset: 2776},
 									expr: &litMatcher{
-										pos:        position{line: 87, col: 86, offset: 2811},
+										pos:        position{line: 86, col: 86, offset: 2776},
 										val:        "i",
 										ignoreCase: false,
 									},
@@ -1014,44 +1014,44 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 89, col: 5, offset: 2853},
+						pos: position{line: 88, col: 5, offset: 2818},
 						run: (*parser).callonCharClassMatcher15,
 						expr: &seqExpr{
-							pos: position{line: 89, col: 5, offset: 2853},
+							pos: position{line: 88, col: 5, offset: 2818},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 89, col: 5, offset: 2853},
+									pos:        position{line: 88, col: 5, offset: 2818},
 									val:        "[",
 									ignoreCase: false,
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 89, col: 9, offset: 2857},
+									pos: position{line: 88, col: 9, offset: 2822},
 									expr: &seqExpr{
-										pos: position{line: 89, col: 11, offset: 2859},
+										pos: position{line: 88, col: 11, offset: 2824},
 										exprs: []interface{}{
 											&notExpr{
-												pos: position{line: 89, col: 11, offset: 2859},
+												pos: position{line: 88, col: 11, offset: 2824},
 												expr: &ruleRefExpr{
-													pos:  position{line: 89, col: 14, offset: 2862},
+													pos:  position{line: 88, col: 14, offset: 2827},
 													name: "EOL",
 												},
 											},
 											&ruleRefExpr{
-												pos:  position{line: 89, col: 20, offset: 2868},
+												pos:  position{line: 88, col: 20, offset: 2833},
 												name: "SourceChar",
 											},
 										},
 									},
 								},
 								&choiceExpr{
-									pos: position{line: 89, col: 36, offset: 2884},
+									pos: position{line: 88, col: 36, offset: 2849},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 89, col: 36, offset: 2884},
+											pos:  position{line: 88, col: 36, offset: 2849},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 89, col: 42, offset: 2890},
+											pos:  position{line: 88, col: 42, offset: 2855},
 											name: "EOF",
 										},
 									},
@@ -1064,21 +1064,21 @@ var g = &grammar{
 		},
 		{
 			name: "ClassCharRange",
-			pos:  position{line: 93, col: 1, offset: 2962},
+			pos:  position{line: 92, col: 1, offset: 2927},
 			expr: &seqExpr{
-				pos: position{line: 93, col: 18, offset: 2979},
+				pos: position{line: 92, col: 18, offset: 2944},
 				exprs: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 93, col: 18, offset: 2979},
+						pos:  position{line: 92, col: 18, offset: 2944},
 						name: "ClassChar",
 					},
 					&litMatcher{
-						pos:        position{line: 93, col: 28, offset: 2989},
+						pos:        position{line: 92, col: 28, offset: 2954},
 						val:        "-",
 						ignoreCase: false,
 					},
 					&ruleRefExpr{
-						pos:  position{line: 93, col: 32, offset: 2993},
+						pos:  position{line: 92, col: 32, offset: 2958},
 						name: "ClassChar",
 					},
 				},
@@ -1086,51 +1086,51 @@ var g = &grammar{
 		},
 		{
 			name: "ClassChar",
-			pos:  position{line: 94, col: 1, offset: 3003},
+			pos:  position{line: 93, col: 1, offset: 2968},
 			expr: &choiceExpr{
-				pos: position{line: 94, col: 13, offset: 3015},
+				pos: position{line: 93, col: 13, offset: 2980},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 94, col: 13, offset: 3015},
+						pos: position{line: 93, col: 13, offset: 2980},
 						exprs: []interface{}{
 							&notExpr{
-								pos: position{line: 94, col: 13, offset: 3015},
+								pos: position{line: 93, col: 13, offset: 2980},
 								expr: &choiceExpr{
-									pos: position{line: 94, col: 16, offset: 3018},
+									pos: position{line: 93, col: 16, offset: 2983},
 									alternatives: []interface{}{
 										&litMatcher{
-											pos:        position{line: 94, col: 16, offset: 3018},
+											pos:        position{line: 93, col: 16, offset: 2983},
 											val:        "]",
 											ignoreCase: false,
 										},
 										&litMatcher{
-											pos:        position{line: 94, col: 22, offset: 3024},
+											pos:        position{line: 93, col: 22, offset: 2989},
 											val:        "\\",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 94, col: 29, offset: 3031},
+											pos:  position{line: 93, col: 29, offset: 2996},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 94, col: 35, offset: 3037},
+								pos:  position{line: 93, col: 35, offset: 3002},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 94, col: 48, offset: 3050},
+						pos: position{line: 93, col: 48, offset: 3015},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 94, col: 48, offset: 3050},
+								pos:        position{line: 93, col: 48, offset: 3015},
 								val:        "\\",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 94, col: 53, offset: 3055},
+								pos:  position{line: 93, col: 53, offset: 3020},
 								name: "CharClassEscape",
 							},
 						},
@@ -1140,51 +1140,51 @@ var g = &grammar{
 		},
 		{
 			name: "CharClassEscape",
-			pos:  position{line: 95, col: 1, offset: 3071},
+			pos:  position{line: 94, col: 1, offset: 3036},
 			expr: &choiceExpr{
-				pos: position{line: 95, col: 19, offset: 3089},
+				pos: position{line: 94, col: 19, offset: 3054},
 				alternatives: []interface{}{
 					&choiceExpr{
-						pos: position{line: 95, col: 21, offset: 3091},
+						pos: position{line: 94, col: 21, offset: 3056},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 95, col: 21, offset: 3091},
+								pos:        position{line: 94, col: 21, offset: 3056},
 								val:        "]",
 								ignoreCase: false,
 							},
 							&ruleRefExpr{
-								pos:  position{line: 95, col: 27, offset: 3097},
+								pos:  position{line: 94, col: 27, offset: 3062},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 96, col: 7, offset: 3126},
+						pos: position{line: 95, col: 7, offset: 3091},
 						run: (*parser).callonCharClassEscape5,
 						expr: &seqExpr{
-							pos: position{line: 96, col: 7, offset: 3126},
+							pos: position{line: 95, col: 7, offset: 3091},
 							exprs: []interface{}{
 								&notExpr{
-									pos: position{line: 96, col: 7, offset: 3126},
+									pos: position{line: 95, col: 7, offset: 3091},
 									expr: &litMatcher{
-										pos:        position{line: 96, col: 8, offset: 3127},
+										pos:        position{line: 95, col: 8, offset: 3092},
 										val:        "p",
 										ignoreCase: false,
 									},
 								},
 								&choiceExpr{
-									pos: position{line: 96, col: 14, offset: 3133},
+									pos: position{line: 95, col: 14, offset: 3098},
 									alternatives: []interface{}{
 										&ruleRefExpr{
-											pos:  position{line: 96, col: 14, offset: 3133},
+											pos:  position{line: 95, col: 14, offset: 3098},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 96, col: 27, offset: 3146},
+											pos:  position{line: 95, col: 27, offset: 3111},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 96, col: 33, offset: 3152},
+											pos:  position{line: 95, col: 33, offset: 3117},
 											name: "EOF",
 										},
 									},
@@ -1197,49 +1197,49 @@ var g = &grammar{
 		},
 		{
 			name: "UnicodeClassEscape",
-			pos:  position{line: 100, col: 1, offset: 3218},
+			pos:  position{line: 99, col: 1, offset: 3183},
 			expr: &seqExpr{
-				pos: position{line: 100, col: 22, offset: 3239},
+				pos: position{line: 99, col: 22, offset: 3204},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 100, col: 22, offset: 3239},
+						pos:        position{line: 99, col: 22, offset: 3204},
 						val:        "p",
 						ignoreCase: false,
 					},
 					&choiceExpr{
-						pos: position{line: 101, col: 7, offset: 3252},
+						pos: position{line: 100, col: 7, offset: 3217},
 						alternatives: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 101, col: 7, offset: 3252},
+								pos:  position{line: 100, col: 7, offset: 3217},
 								name: "SingleCharUnicodeClass",
 							},
 							&actionExpr{
-								pos: position{line: 102, col: 7, offset: 3281},
+								pos: position{line: 101, col: 7, offset: 3246},
 								run: (*parser).callonUnicodeClassEscape5,
 								expr: &seqExpr{
-									pos: position{line: 102, col: 7, offset: 3281},
+									pos: position{line: 101, col: 7, offset: 3246},
 									exprs: []interface{}{
 										&notExpr{
-											pos: position{line: 102, col: 7, offset: 3281},
+											pos: position{line: 101, col: 7, offset: 3246},
 											expr: &litMatcher{
-												pos:        position{line: 102, col: 8, offset: 3282},
+												pos:        position{line: 101, col: 8, offset: 3247},
 												val:        "{",
 												ignoreCase: false,
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 102, col: 14, offset: 3288},
+											pos: position{line: 101, col: 14, offset: 3253},
 											alternatives: []interface{}{
 												&ruleRefExpr{
-													pos:  position{line: 102, col: 14, offset: 3288},
+													pos:  position{line: 101, col: 14, offset: 3253},
 													name: "SourceChar",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 102, col: 27, offset: 3301},
+													pos:  position{line: 101, col: 27, offset: 3266},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 102, col: 33, offset: 3307},
+													pos:  position{line: 101, col: 33, offset: 3272},
 													name: "EOF",
 												},
 											},
@@ -1248,26 +1248,26 @@ var g = &grammar{
 								},
 							},
 							&actionExpr{
-								pos: position{line: 103, col: 7, offset: 3378},
+								pos: position{line: 102, col: 7, offset: 3343},
 								run: (*parser).callonUnicodeClassEscape13,
 								expr: &seqExpr{
-									pos: position{line: 103, col: 7, offset: 3378},
+									pos: position{line: 102, col: 7, offset: 3343},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 103, col: 7, offset: 3378},
+											pos:        position{line: 102, col: 7, offset: 3343},
 											val:        "{",
 											ignoreCase: false,
 										},
 										&labeledExpr{
-											pos:   position{line: 103, col: 11, offset: 3382},
+											pos:   position{line: 102, col: 11, offset: 3347},
 											label: "ident",
 											expr: &ruleRefExpr{
-												pos:  position{line: 103, col: 17, offset: 3388},
+												pos:  position{line: 102, col: 17, offset: 3353},
 												name: "IdentifierName",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 103, col: 32, offset: 3403},
+											pos:        position{line: 102, col: 32, offset: 3368},
 											val:        "}",
 											ignoreCase: false,
 										},
@@ -1275,34 +1275,34 @@ var g = &grammar{
 								},
 							},
 							&actionExpr{
-								pos: position{line: 109, col: 7, offset: 3567},
+								pos: position{line: 108, col: 7, offset: 3532},
 								run: (*parser).callonUnicodeClassEscape19,
 								expr: &seqExpr{
-									pos: position{line: 109, col: 7, offset: 3567},
+									pos: position{line: 108, col: 7, offset: 3532},
 									exprs: []interface{}{
 										&litMatcher{
-											pos:        position{line: 109, col: 7, offset: 3567},
+											pos:        position{line: 108, col: 7, offset: 3532},
 											val:        "{",
 											ignoreCase: false,
 										},
 										&ruleRefExpr{
-											pos:  position{line: 109, col: 11, offset: 3571},
+											pos:  position{line: 108, col: 11, offset: 3536},
 											name: "IdentifierName",
 										},
 										&choiceExpr{
-											pos: position{line: 109, col: 28, offset: 3588},
+											pos: position{line: 108, col: 28, offset: 3553},
 											alternatives: []interface{}{
 												&litMatcher{
-													pos:        position{line: 109, col: 28, offset: 3588},
+													pos:        position{line: 108, col: 28, offset: 3553},
 													val:        "]",
 													ignoreCase: false,
 												},
 												&ruleRefExpr{
-													pos:  position{line: 109, col: 34, offset: 3594},
+													pos:  position{line: 108, col: 34, offset: 3559},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 109, col: 40, offset: 3600},
+													pos:  position{line: 108, col: 40, offset: 3565},
 													name: "EOF",
 												},
 											},
@@ -1317,9 +1317,9 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharUnicodeClass",
-			pos:  position{line: 114, col: 1, offset: 3680},
+			pos:  position{line: 113, col: 1, offset: 3645},
 			expr: &charClassMatcher{
-				pos:        position{line: 114, col: 26, offset: 3705},
+				pos:        position{line: 113, col: 26, offset: 3670},
 				val:        "[LMNCPZS]",
 				chars:      []rune{'L', 'M', 'N', 'C', 'P', 'Z', 'S'},
 				ignoreCase: false,
@@ -1328,39 +1328,39 @@ var g = &grammar{
 		},
 		{
 			name: "Number",
-			pos:  position{line: 117, col: 1, offset: 3717},
+			pos:  position{line: 116, col: 1, offset: 3682},
 			expr: &actionExpr{
-				pos: position{line: 117, col: 10, offset: 3726},
+				pos: position{line: 116, col: 10, offset: 3691},
 				run: (*parser).callonNumber1,
 				expr: &seqExpr{
-					pos: position{line: 117, col: 10, offset: 3726},
+					pos: position{line: 116, col: 10, offset: 3691},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 117, col: 10, offset: 3726},
+							pos: position{line: 116, col: 10, offset: 3691},
 							expr: &litMatcher{
-								pos:        position{line: 117, col: 10, offset: 3726},
+								pos:        position{line: 116, col: 10, offset: 3691},
 								val:        "-",
 								ignoreCase: false,
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 117, col: 15, offset: 3731},
+							pos:  position{line: 116, col: 15, offset: 3696},
 							name: "Integer",
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 117, col: 23, offset: 3739},
+							pos: position{line: 116, col: 23, offset: 3704},
 							expr: &seqExpr{
-								pos: position{line: 117, col: 25, offset: 3741},
+								pos: position{line: 116, col: 25, offset: 3706},
 								exprs: []interface{}{
 									&litMatcher{
-										pos:        position{line: 117, col: 25, offset: 3741},
+										pos:        position{line: 116, col: 25, offset: 3706},
 										val:        ".",
 										ignoreCase: false,
 									},
 									&oneOrMoreExpr{
-										pos: position{line: 117, col: 29, offset: 3745},
+										pos: position{line: 116, col: 29, offset: 3710},
 										expr: &ruleRefExpr{
-											pos:  position{line: 117, col: 29, offset: 3745},
+											pos:  position{line: 116, col: 29, offset: 3710},
 											name: "Digit",
 										},
 									},
@@ -1373,29 +1373,29 @@ var g = &grammar{
 		},
 		{
 			name: "Integer",
-			pos:  position{line: 121, col: 1, offset: 3797},
+			pos:  position{line: 120, col: 1, offset: 3762},
 			expr: &choiceExpr{
-				pos: position{line: 121, col: 11, offset: 3807},
+				pos: position{line: 120, col: 11, offset: 3772},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 121, col: 11, offset: 3807},
+						pos:        position{line: 120, col: 11, offset: 3772},
 						val:        "0",
 						ignoreCase: false,
 					},
 					&actionExpr{
-						pos: position{line: 121, col: 17, offset: 3813},
+						pos: position{line: 120, col: 17, offset: 3778},
 						run: (*parser).callonInteger3,
 						expr: &seqExpr{
-							pos: position{line: 121, col: 17, offset: 3813},
+							pos: position{line: 120, col: 17, offset: 3778},
 							exprs: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 121, col: 17, offset: 3813},
+									pos:  position{line: 120, col: 17, offset: 3778},
 									name: "NonZeroDigit",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 121, col: 30, offset: 3826},
+									pos: position{line: 120, col: 30, offset: 3791},
 									expr: &ruleRefExpr{
-										pos:  position{line: 121, col: 30, offset: 3826},
+										pos:  position{line: 120, col: 30, offset: 3791},
 										name: "Digit",
 									},
 								},
@@ -1407,9 +1407,9 @@ var g = &grammar{
 		},
 		{
 			name: "NonZeroDigit",
-			pos:  position{line: 125, col: 1, offset: 3890},
+			pos:  position{line: 124, col: 1, offset: 3855},
 			expr: &charClassMatcher{
-				pos:        position{line: 125, col: 16, offset: 3905},
+				pos:        position{line: 124, col: 16, offset: 3870},
 				val:        "[1-9]",
 				ranges:     []rune{'1', '9'},
 				ignoreCase: false,
@@ -1418,9 +1418,9 @@ var g = &grammar{
 		},
 		{
 			name: "Digit",
-			pos:  position{line: 126, col: 1, offset: 3911},
+			pos:  position{line: 125, col: 1, offset: 3876},
 			expr: &charClassMatcher{
-				pos:        position{line: 126, col: 9, offset: 3919},
+				pos:        position{line: 125, col: 9, offset: 3884},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -1429,31 +1429,31 @@ var g = &grammar{
 		},
 		{
 			name: "LabelBlock",
-			pos:  position{line: 128, col: 1, offset: 3926},
+			pos:  position{line: 127, col: 1, offset: 3891},
 			expr: &choiceExpr{
-				pos: position{line: 128, col: 14, offset: 3939},
+				pos: position{line: 127, col: 14, offset: 3904},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 128, col: 14, offset: 3939},
+						pos: position{line: 127, col: 14, offset: 3904},
 						run: (*parser).callonLabelBlock2,
 						expr: &seqExpr{
-							pos: position{line: 128, col: 14, offset: 3939},
+							pos: position{line: 127, col: 14, offset: 3904},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 128, col: 14, offset: 3939},
+									pos:        position{line: 127, col: 14, offset: 3904},
 									val:        "{",
 									ignoreCase: false,
 								},
 								&labeledExpr{
-									pos:   position{line: 128, col: 18, offset: 3943},
+									pos:   position{line: 127, col: 18, offset: 3908},
 									label: "block",
 									expr: &ruleRefExpr{
-										pos:  position{line: 128, col: 24, offset: 3949},
+										pos:  position{line: 127, col: 24, offset: 3914},
 										name: "LabelMatches",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 128, col: 37, offset: 3962},
+									pos:        position{line: 127, col: 37, offset: 3927},
 									val:        "}",
 									ignoreCase: false,
 								},
@@ -1461,22 +1461,22 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 130, col: 5, offset: 3994},
+						pos: position{line: 129, col: 5, offset: 3959},
 						run: (*parser).callonLabelBlock8,
 						expr: &seqExpr{
-							pos: position{line: 130, col: 5, offset: 3994},
+							pos: position{line: 129, col: 5, offset: 3959},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 130, col: 5, offset: 3994},
+									pos:        position{line: 129, col: 5, offset: 3959},
 									val:        "{",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 130, col: 9, offset: 3998},
+									pos:  position{line: 129, col: 9, offset: 3963},
 									name: "LabelMatches",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 130, col: 22, offset: 4011},
+									pos:  position{line: 129, col: 22, offset: 3976},
 									name: "EOF",
 								},
 							},
@@ -1487,12 +1487,12 @@ var g = &grammar{
 		},
 		{
 			name: "NanoSecondUnits",
-			pos:  position{line: 134, col: 1, offset: 4076},
+			pos:  position{line: 133, col: 1, offset: 4041},
 			expr: &actionExpr{
-				pos: position{line: 134, col: 19, offset: 4094},
+				pos: position{line: 133, col: 19, offset: 4059},
 				run: (*parser).callonNanoSecondUnits1,
 				expr: &litMatcher{
-					pos:        position{line: 134, col: 19, offset: 4094},
+					pos:        position{line: 133, col: 19, offset: 4059},
 					val:        "ns",
 					ignoreCase: false,
 				},
@@ -1500,25 +1500,25 @@ var g = &grammar{
 		},
 		{
 			name: "MicroSecondUnits",
-			pos:  position{line: 139, col: 1, offset: 4199},
+			pos:  position{line: 138, col: 1, offset: 4164},
 			expr: &actionExpr{
-				pos: position{line: 139, col: 20, offset: 4218},
+				pos: position{line: 138, col: 20, offset: 4183},
 				run: (*parser).callonMicroSecondUnits1,
 				expr: &choiceExpr{
-					pos: position{line: 139, col: 21, offset: 4219},
+					pos: position{line: 138, col: 21, offset: 4184},
 					alternatives: []interface{}{
 						&litMatcher{
-							pos:        position{line: 139, col: 21, offset: 4219},
+							pos:        position{line: 138, col: 21, offset: 4184},
 							val:        "us",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 139, col: 28, offset: 4226},
+							pos:        position{line: 138, col: 28, offset: 4191},
 							val:        "µs",
 							ignoreCase: false,
 						},
 						&litMatcher{
-							pos:        position{line: 139, col: 35, offset: 4234},
+							pos:        position{line: 138, col: 35, offset: 4199},
 							val:        "μs",
 							ignoreCase: false,
 						},
@@ -1528,12 +1528,12 @@ var g = &grammar{
 		},
 		{
 			name: "MilliSecondUnits",
-			pos:  position{line: 144, col: 1, offset: 4343},
+			pos:  position{line: 143, col: 1, offset: 4308},
 			expr: &actionExpr{
-				pos: position{line: 144, col: 20, offset: 4362},
+				pos: position{line: 143, col: 20, offset: 4327},
 				run: (*parser).callonMilliSecondUnits1,
 				expr: &litMatcher{
-					pos:        position{line: 144, col: 20, offset: 4362},
+					pos:        position{line: 143, col: 20, offset: 4327},
 					val:        "ms",
 					ignoreCase: false,
 				},
@@ -1541,12 +1541,12 @@ var g = &grammar{
 		},
 		{
 			name: "SecondUnits",
-			pos:  position{line: 149, col: 1, offset: 4469},
+			pos:  position{line: 148, col: 1, offset: 4434},
 			expr: &actionExpr{
-				pos: position{line: 149, col: 15, offset: 4483},
+				pos: position{line: 148, col: 15, offset: 4448},
 				run: (*parser).callonSecondUnits1,
 				expr: &litMatcher{
-					pos:        position{line: 149, col: 15, offset: 4483},
+					pos:        position{line: 148, col: 15, offset: 4448},
 					val:        "s",
 					ignoreCase: false,
 				},
@@ -1554,12 +1554,12 @@ var g = &grammar{
 		},
 		{
 			name: "MinuteUnits",
-			pos:  position{line: 153, col: 1, offset: 4520},
+			pos:  position{line: 152, col: 1, offset: 4485},
 			expr: &actionExpr{
-				pos: position{line: 153, col: 15, offset: 4534},
+				pos: position{line: 152, col: 15, offset: 4499},
 				run: (*parser).callonMinuteUnits1,
 				expr: &litMatcher{
-					pos:        position{line: 153, col: 15, offset: 4534},
+					pos:        position{line: 152, col: 15, offset: 4499},
 					val:        "m",
 					ignoreCase: false,
 				},
@@ -1567,12 +1567,12 @@ var g = &grammar{
 		},
 		{
 			name: "HourUnits",
-			pos:  position{line: 157, col: 1, offset: 4571},
+			pos:  position{line: 156, col: 1, offset: 4536},
 			expr: &actionExpr{
-				pos: position{line: 157, col: 13, offset: 4583},
+				pos: position{line: 156, col: 13, offset: 4548},
 				run: (*parser).callonHourUnits1,
 				expr: &litMatcher{
-					pos:        position{line: 157, col: 13, offset: 4583},
+					pos:        position{line: 156, col: 13, offset: 4548},
 					val:        "h",
 					ignoreCase: false,
 				},
@@ -1580,12 +1580,12 @@ var g = &grammar{
 		},
 		{
 			name: "DayUnits",
-			pos:  position{line: 161, col: 1, offset: 4618},
+			pos:  position{line: 160, col: 1, offset: 4583},
 			expr: &actionExpr{
-				pos: position{line: 161, col: 12, offset: 4629},
+				pos: position{line: 160, col: 12, offset: 4594},
 				run: (*parser).callonDayUnits1,
 				expr: &litMatcher{
-					pos:        position{line: 161, col: 12, offset: 4629},
+					pos:        position{line: 160, col: 12, offset: 4594},
 					val:        "d",
 					ignoreCase: false,
 				},
@@ -1593,12 +1593,12 @@ var g = &grammar{
 		},
 		{
 			name: "WeekUnits",
-			pos:  position{line: 167, col: 1, offset: 4837},
+			pos:  position{line: 166, col: 1, offset: 4802},
 			expr: &actionExpr{
-				pos: position{line: 167, col: 13, offset: 4849},
+				pos: position{line: 166, col: 13, offset: 4814},
 				run: (*parser).callonWeekUnits1,
 				expr: &litMatcher{
-					pos:        position{line: 167, col: 13, offset: 4849},
+					pos:        position{line: 166, col: 13, offset: 4814},
 					val:        "w",
 					ignoreCase: false,
 				},
@@ -1606,12 +1606,12 @@ var g = &grammar{
 		},
 		{
 			name: "YearUnits",
-			pos:  position{line: 173, col: 1, offset: 5060},
+			pos:  position{line: 172, col: 1, offset: 5025},
 			expr: &actionExpr{
-				pos: position{line: 173, col: 13, offset: 5072},
+				pos: position{line: 172, col: 13, offset: 5037},
 				run: (*parser).callonYearUnits1,
 				expr: &litMatcher{
-					pos:        position{line: 173, col: 13, offset: 5072},
+					pos:        position{line: 172, col: 13, offset: 5037},
 					val:        "y",
 					ignoreCase: false,
 				},
@@ -1619,44 +1619,44 @@ var g = &grammar{
 		},
 		{
 			name: "DurationUnits",
-			pos:  position{line: 179, col: 1, offset: 5269},
+			pos:  position{line: 178, col: 1, offset: 5234},
 			expr: &choiceExpr{
-				pos: position{line: 179, col: 18, offset: 5286},
+				pos: position{line: 178, col: 18, offset: 5251},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 18, offset: 5286},
+						pos:  position{line: 178, col: 18, offset: 5251},
 						name: "NanoSecondUnits",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 36, offset: 5304},
+						pos:  position{line: 178, col: 36, offset: 5269},
 						name: "MicroSecondUnits",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 55, offset: 5323},
+						pos:  position{line: 178, col: 55, offset: 5288},
 						name: "MilliSecondUnits",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 74, offset: 5342},
+						pos:  position{line: 178, col: 74, offset: 5307},
 						name: "SecondUnits",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 88, offset: 5356},
+						pos:  position{line: 178, col: 88, offset: 5321},
 						name: "MinuteUnits",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 102, offset: 5370},
+						pos:  position{line: 178, col: 102, offset: 5335},
 						name: "HourUnits",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 114, offset: 5382},
+						pos:  position{line: 178, col: 114, offset: 5347},
 						name: "DayUnits",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 125, offset: 5393},
+						pos:  position{line: 178, col: 125, offset: 5358},
 						name: "WeekUnits",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 179, col: 137, offset: 5405},
+						pos:  position{line: 178, col: 137, offset: 5370},
 						name: "YearUnits",
 					},
 				},
@@ -1664,26 +1664,26 @@ var g = &grammar{
 		},
 		{
 			name: "Duration",
-			pos:  position{line: 181, col: 1, offset: 5417},
+			pos:  position{line: 180, col: 1, offset: 5382},
 			expr: &actionExpr{
-				pos: position{line: 181, col: 12, offset: 5428},
+				pos: position{line: 180, col: 12, offset: 5393},
 				run: (*parser).callonDuration1,
 				expr: &seqExpr{
-					pos: position{line: 181, col: 12, offset: 5428},
+					pos: position{line: 180, col: 12, offset: 5393},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 181, col: 12, offset: 5428},
+							pos:   position{line: 180, col: 12, offset: 5393},
 							label: "dur",
 							expr: &ruleRefExpr{
-								pos:  position{line: 181, col: 16, offset: 5432},
+								pos:  position{line: 180, col: 16, offset: 5397},
 								name: "Integer",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 181, col: 24, offset: 5440},
+							pos:   position{line: 180, col: 24, offset: 5405},
 							label: "units",
 							expr: &ruleRefExpr{
-								pos:  position{line: 181, col: 30, offset: 5446},
+								pos:  position{line: 180, col: 30, offset: 5411},
 								name: "DurationUnits",
 							},
 						},
@@ -1693,82 +1693,82 @@ var g = &grammar{
 		},
 		{
 			name: "Operators",
-			pos:  position{line: 187, col: 1, offset: 5595},
+			pos:  position{line: 186, col: 1, offset: 5560},
 			expr: &choiceExpr{
-				pos: position{line: 187, col: 13, offset: 5607},
+				pos: position{line: 186, col: 13, offset: 5572},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 187, col: 13, offset: 5607},
+						pos:        position{line: 186, col: 13, offset: 5572},
 						val:        "-",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 19, offset: 5613},
+						pos:        position{line: 186, col: 19, offset: 5578},
 						val:        "+",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 25, offset: 5619},
+						pos:        position{line: 186, col: 25, offset: 5584},
 						val:        "*",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 31, offset: 5625},
+						pos:        position{line: 186, col: 31, offset: 5590},
 						val:        "%",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 37, offset: 5631},
+						pos:        position{line: 186, col: 37, offset: 5596},
 						val:        "/",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 43, offset: 5637},
+						pos:        position{line: 186, col: 43, offset: 5602},
 						val:        "==",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 50, offset: 5644},
+						pos:        position{line: 186, col: 50, offset: 5609},
 						val:        "!=",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 57, offset: 5651},
+						pos:        position{line: 186, col: 57, offset: 5616},
 						val:        "<=",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 64, offset: 5658},
+						pos:        position{line: 186, col: 64, offset: 5623},
 						val:        "<",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 70, offset: 5664},
+						pos:        position{line: 186, col: 70, offset: 5629},
 						val:        ">=",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 77, offset: 5671},
+						pos:        position{line: 186, col: 77, offset: 5636},
 						val:        ">",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 83, offset: 5677},
+						pos:        position{line: 186, col: 83, offset: 5642},
 						val:        "=~",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 90, offset: 5684},
+						pos:        position{line: 186, col: 90, offset: 5649},
 						val:        "!~",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 97, offset: 5691},
+						pos:        position{line: 186, col: 97, offset: 5656},
 						val:        "^",
 						ignoreCase: false,
 					},
 					&litMatcher{
-						pos:        position{line: 187, col: 103, offset: 5697},
+						pos:        position{line: 186, col: 103, offset: 5662},
 						val:        "=",
 						ignoreCase: false,
 					},
@@ -1777,42 +1777,42 @@ var g = &grammar{
 		},
 		{
 			name: "LabelOperators",
-			pos:  position{line: 189, col: 1, offset: 5702},
+			pos:  position{line: 188, col: 1, offset: 5667},
 			expr: &choiceExpr{
-				pos: position{line: 189, col: 19, offset: 5720},
+				pos: position{line: 188, col: 19, offset: 5685},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 189, col: 19, offset: 5720},
+						pos: position{line: 188, col: 19, offset: 5685},
 						run: (*parser).callonLabelOperators2,
 						expr: &litMatcher{
-							pos:        position{line: 189, col: 19, offset: 5720},
+							pos:        position{line: 188, col: 19, offset: 5685},
 							val:        "!=",
 							ignoreCase: false,
 						},
 					},
 					&actionExpr{
-						pos: position{line: 191, col: 5, offset: 5756},
+						pos: position{line: 190, col: 5, offset: 5721},
 						run: (*parser).callonLabelOperators4,
 						expr: &litMatcher{
-							pos:        position{line: 191, col: 5, offset: 5756},
+							pos:        position{line: 190, col: 5, offset: 5721},
 							val:        "=~",
 							ignoreCase: false,
 						},
 					},
 					&actionExpr{
-						pos: position{line: 193, col: 5, offset: 5794},
+						pos: position{line: 192, col: 5, offset: 5759},
 						run: (*parser).callonLabelOperators6,
 						expr: &litMatcher{
-							pos:        position{line: 193, col: 5, offset: 5794},
+							pos:        position{line: 192, col: 5, offset: 5759},
 							val:        "!~",
 							ignoreCase: false,
 						},
 					},
 					&actionExpr{
-						pos: position{line: 195, col: 5, offset: 5834},
+						pos: position{line: 194, col: 5, offset: 5799},
 						run: (*parser).callonLabelOperators8,
 						expr: &litMatcher{
-							pos:        position{line: 195, col: 5, offset: 5834},
+							pos:        position{line: 194, col: 5, offset: 5799},
 							val:        "=",
 							ignoreCase: false,
 						},
@@ -1822,57 +1822,57 @@ var g = &grammar{
 		},
 		{
 			name: "Label",
-			pos:  position{line: 199, col: 1, offset: 5865},
+			pos:  position{line: 198, col: 1, offset: 5830},
 			expr: &ruleRefExpr{
-				pos:  position{line: 199, col: 9, offset: 5873},
+				pos:  position{line: 198, col: 9, offset: 5838},
 				name: "Identifier",
 			},
 		},
 		{
 			name: "LabelMatch",
-			pos:  position{line: 200, col: 1, offset: 5884},
+			pos:  position{line: 199, col: 1, offset: 5849},
 			expr: &actionExpr{
-				pos: position{line: 200, col: 14, offset: 5897},
+				pos: position{line: 199, col: 14, offset: 5862},
 				run: (*parser).callonLabelMatch1,
 				expr: &seqExpr{
-					pos: position{line: 200, col: 14, offset: 5897},
+					pos: position{line: 199, col: 14, offset: 5862},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 200, col: 14, offset: 5897},
+							pos:   position{line: 199, col: 14, offset: 5862},
 							label: "label",
 							expr: &ruleRefExpr{
-								pos:  position{line: 200, col: 20, offset: 5903},
+								pos:  position{line: 199, col: 20, offset: 5868},
 								name: "Label",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 26, offset: 5909},
+							pos:  position{line: 199, col: 26, offset: 5874},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 200, col: 29, offset: 5912},
+							pos:   position{line: 199, col: 29, offset: 5877},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 200, col: 32, offset: 5915},
+								pos:  position{line: 199, col: 32, offset: 5880},
 								name: "LabelOperators",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 47, offset: 5930},
+							pos:  position{line: 199, col: 47, offset: 5895},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 200, col: 50, offset: 5933},
+							pos:   position{line: 199, col: 50, offset: 5898},
 							label: "match",
 							expr: &choiceExpr{
-								pos: position{line: 200, col: 58, offset: 5941},
+								pos: position{line: 199, col: 58, offset: 5906},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 200, col: 58, offset: 5941},
+										pos:  position{line: 199, col: 58, offset: 5906},
 										name: "StringLiteral",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 200, col: 74, offset: 5957},
+										pos:  position{line: 199, col: 74, offset: 5922},
 										name: "Number",
 									},
 								},
@@ -1884,32 +1884,32 @@ var g = &grammar{
 		},
 		{
 			name: "LabelMatches",
-			pos:  position{line: 203, col: 1, offset: 6047},
+			pos:  position{line: 202, col: 1, offset: 6012},
 			expr: &actionExpr{
-				pos: position{line: 203, col: 16, offset: 6062},
+				pos: position{line: 202, col: 16, offset: 6027},
 				run: (*parser).callonLabelMatches1,
 				expr: &seqExpr{
-					pos: position{line: 203, col: 16, offset: 6062},
+					pos: position{line: 202, col: 16, offset: 6027},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 203, col: 16, offset: 6062},
+							pos:   position{line: 202, col: 16, offset: 6027},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 203, col: 22, offset: 6068},
+								pos:  position{line: 202, col: 22, offset: 6033},
 								name: "LabelMatch",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 203, col: 33, offset: 6079},
+							pos:  position{line: 202, col: 33, offset: 6044},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 203, col: 36, offset: 6082},
+							pos:   position{line: 202, col: 36, offset: 6047},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 203, col: 41, offset: 6087},
+								pos: position{line: 202, col: 41, offset: 6052},
 								expr: &ruleRefExpr{
-									pos:  position{line: 203, col: 41, offset: 6087},
+									pos:  position{line: 202, col: 41, offset: 6052},
 									name: "LabelMatchesRest",
 								},
 							},
@@ -1920,27 +1920,27 @@ var g = &grammar{
 		},
 		{
 			name: "LabelMatchesRest",
-			pos:  position{line: 207, col: 1, offset: 6166},
+			pos:  position{line: 206, col: 1, offset: 6131},
 			expr: &actionExpr{
-				pos: position{line: 207, col: 21, offset: 6186},
+				pos: position{line: 206, col: 21, offset: 6151},
 				run: (*parser).callonLabelMatchesRest1,
 				expr: &seqExpr{
-					pos: position{line: 207, col: 21, offset: 6186},
+					pos: position{line: 206, col: 21, offset: 6151},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 207, col: 21, offset: 6186},
+							pos:        position{line: 206, col: 21, offset: 6151},
 							val:        ",",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 207, col: 25, offset: 6190},
+							pos:  position{line: 206, col: 25, offset: 6155},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 207, col: 28, offset: 6193},
+							pos:   position{line: 206, col: 28, offset: 6158},
 							label: "match",
 							expr: &ruleRefExpr{
-								pos:  position{line: 207, col: 34, offset: 6199},
+								pos:  position{line: 206, col: 34, offset: 6164},
 								name: "LabelMatch",
 							},
 						},
@@ -1950,27 +1950,27 @@ var g = &grammar{
 		},
 		{
 			name: "LabelList",
-			pos:  position{line: 211, col: 1, offset: 6237},
+			pos:  position{line: 210, col: 1, offset: 6202},
 			expr: &choiceExpr{
-				pos: position{line: 211, col: 13, offset: 6249},
+				pos: position{line: 210, col: 13, offset: 6214},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 211, col: 13, offset: 6249},
+						pos: position{line: 210, col: 13, offset: 6214},
 						run: (*parser).callonLabelList2,
 						expr: &seqExpr{
-							pos: position{line: 211, col: 14, offset: 6250},
+							pos: position{line: 210, col: 14, offset: 6215},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 211, col: 14, offset: 6250},
+									pos:        position{line: 210, col: 14, offset: 6215},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 211, col: 18, offset: 6254},
+									pos:  position{line: 210, col: 18, offset: 6219},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 211, col: 21, offset: 6257},
+									pos:        position{line: 210, col: 21, offset: 6222},
 									val:        ")",
 									ignoreCase: false,
 								},
@@ -1978,49 +1978,49 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 213, col: 6, offset: 6289},
+						pos: position{line: 212, col: 6, offset: 6254},
 						run: (*parser).callonLabelList7,
 						expr: &seqExpr{
-							pos: position{line: 213, col: 6, offset: 6289},
+							pos: position{line: 212, col: 6, offset: 6254},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 213, col: 6, offset: 6289},
+									pos:        position{line: 212, col: 6, offset: 6254},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 213, col: 10, offset: 6293},
+									pos:  position{line: 212, col: 10, offset: 6258},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 213, col: 13, offset: 6296},
+									pos:   position{line: 212, col: 13, offset: 6261},
 									label: "label",
 									expr: &ruleRefExpr{
-										pos:  position{line: 213, col: 19, offset: 6302},
+										pos:  position{line: 212, col: 19, offset: 6267},
 										name: "Label",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 213, col: 25, offset: 6308},
+									pos:  position{line: 212, col: 25, offset: 6273},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 213, col: 28, offset: 6311},
+									pos:   position{line: 212, col: 28, offset: 6276},
 									label: "rest",
 									expr: &zeroOrMoreExpr{
-										pos: position{line: 213, col: 33, offset: 6316},
+										pos: position{line: 212, col: 33, offset: 6281},
 										expr: &ruleRefExpr{
-											pos:  position{line: 213, col: 33, offset: 6316},
+											pos:  position{line: 212, col: 33, offset: 6281},
 											name: "LabelListRest",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 213, col: 48, offset: 6331},
+									pos:  position{line: 212, col: 48, offset: 6296},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 213, col: 51, offset: 6334},
+									pos:        position{line: 212, col: 51, offset: 6299},
 									val:        ")",
 									ignoreCase: false,
 								},
@@ -2032,27 +2032,27 @@ var g = &grammar{
 		},
 		{
 			name: "LabelListRest",
-			pos:  position{line: 217, col: 1, offset: 6400},
+			pos:  position{line: 216, col: 1, offset: 6365},
 			expr: &actionExpr{
-				pos: position{line: 217, col: 18, offset: 6417},
+				pos: position{line: 216, col: 18, offset: 6382},
 				run: (*parser).callonLabelListRest1,
 				expr: &seqExpr{
-					pos: position{line: 217, col: 18, offset: 6417},
+					pos: position{line: 216, col: 18, offset: 6382},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 217, col: 18, offset: 6417},
+							pos:        position{line: 216, col: 18, offset: 6382},
 							val:        ",",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 217, col: 22, offset: 6421},
+							pos:  position{line: 216, col: 22, offset: 6386},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 217, col: 25, offset: 6424},
+							pos:   position{line: 216, col: 25, offset: 6389},
 							label: "label",
 							expr: &ruleRefExpr{
-								pos:  position{line: 217, col: 31, offset: 6430},
+								pos:  position{line: 216, col: 31, offset: 6395},
 								name: "Label",
 							},
 						},
@@ -2062,62 +2062,62 @@ var g = &grammar{
 		},
 		{
 			name: "VectorSelector",
-			pos:  position{line: 221, col: 1, offset: 6463},
+			pos:  position{line: 220, col: 1, offset: 6428},
 			expr: &actionExpr{
-				pos: position{line: 221, col: 18, offset: 6480},
+				pos: position{line: 220, col: 18, offset: 6445},
 				run: (*parser).callonVectorSelector1,
 				expr: &seqExpr{
-					pos: position{line: 221, col: 18, offset: 6480},
+					pos: position{line: 220, col: 18, offset: 6445},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 221, col: 18, offset: 6480},
+							pos:   position{line: 220, col: 18, offset: 6445},
 							label: "metric",
 							expr: &ruleRefExpr{
-								pos:  position{line: 221, col: 25, offset: 6487},
+								pos:  position{line: 220, col: 25, offset: 6452},
 								name: "Identifier",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 36, offset: 6498},
+							pos:  position{line: 220, col: 36, offset: 6463},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 221, col: 40, offset: 6502},
+							pos:   position{line: 220, col: 40, offset: 6467},
 							label: "block",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 221, col: 46, offset: 6508},
+								pos: position{line: 220, col: 46, offset: 6473},
 								expr: &ruleRefExpr{
-									pos:  position{line: 221, col: 46, offset: 6508},
+									pos:  position{line: 220, col: 46, offset: 6473},
 									name: "LabelBlock",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 58, offset: 6520},
+							pos:  position{line: 220, col: 58, offset: 6485},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 221, col: 61, offset: 6523},
+							pos:   position{line: 220, col: 61, offset: 6488},
 							label: "rng",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 221, col: 65, offset: 6527},
+								pos: position{line: 220, col: 65, offset: 6492},
 								expr: &ruleRefExpr{
-									pos:  position{line: 221, col: 65, offset: 6527},
+									pos:  position{line: 220, col: 65, offset: 6492},
 									name: "Range",
 								},
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 221, col: 72, offset: 6534},
+							pos:  position{line: 220, col: 72, offset: 6499},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 221, col: 75, offset: 6537},
+							pos:   position{line: 220, col: 75, offset: 6502},
 							label: "offset",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 221, col: 82, offset: 6544},
+								pos: position{line: 220, col: 82, offset: 6509},
 								expr: &ruleRefExpr{
-									pos:  position{line: 221, col: 82, offset: 6544},
+									pos:  position{line: 220, col: 82, offset: 6509},
 									name: "Offset",
 								},
 							},
@@ -2128,36 +2128,36 @@ var g = &grammar{
 		},
 		{
 			name: "Range",
-			pos:  position{line: 225, col: 1, offset: 6622},
+			pos:  position{line: 224, col: 1, offset: 6587},
 			expr: &actionExpr{
-				pos: position{line: 225, col: 9, offset: 6630},
+				pos: position{line: 224, col: 9, offset: 6595},
 				run: (*parser).callonRange1,
 				expr: &seqExpr{
-					pos: position{line: 225, col: 9, offset: 6630},
+					pos: position{line: 224, col: 9, offset: 6595},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 225, col: 9, offset: 6630},
+							pos:        position{line: 224, col: 9, offset: 6595},
 							val:        "[",
 							ignoreCase: false,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 225, col: 13, offset: 6634},
+							pos:  position{line: 224, col: 13, offset: 6599},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 225, col: 16, offset: 6637},
+							pos:   position{line: 224, col: 16, offset: 6602},
 							label: "dur",
 							expr: &ruleRefExpr{
-								pos:  position{line: 225, col: 20, offset: 6641},
+								pos:  position{line: 224, col: 20, offset: 6606},
 								name: "Duration",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 225, col: 29, offset: 6650},
+							pos:  position{line: 224, col: 29, offset: 6615},
 							name: "__",
 						},
 						&litMatcher{
-							pos:        position{line: 225, col: 32, offset: 6653},
+							pos:        position{line: 224, col: 32, offset: 6618},
 							val:        "]",
 							ignoreCase: false,
 						},
@@ -2167,27 +2167,27 @@ var g = &grammar{
 		},
 		{
 			name: "Offset",
-			pos:  position{line: 229, col: 1, offset: 6682},
+			pos:  position{line: 228, col: 1, offset: 6647},
 			expr: &actionExpr{
-				pos: position{line: 229, col: 10, offset: 6691},
+				pos: position{line: 228, col: 10, offset: 6656},
 				run: (*parser).callonOffset1,
 				expr: &seqExpr{
-					pos: position{line: 229, col: 10, offset: 6691},
+					pos: position{line: 228, col: 10, offset: 6656},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 229, col: 10, offset: 6691},
+							pos:        position{line: 228, col: 10, offset: 6656},
 							val:        "offset",
 							ignoreCase: true,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 229, col: 20, offset: 6701},
+							pos:  position{line: 228, col: 20, offset: 6666},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 229, col: 23, offset: 6704},
+							pos:   position{line: 228, col: 23, offset: 6669},
 							label: "dur",
 							expr: &ruleRefExpr{
-								pos:  position{line: 229, col: 27, offset: 6708},
+								pos:  position{line: 228, col: 27, offset: 6673},
 								name: "Duration",
 							},
 						},
@@ -2197,12 +2197,12 @@ var g = &grammar{
 		},
 		{
 			name: "CountValueOperator",
-			pos:  position{line: 233, col: 1, offset: 6742},
+			pos:  position{line: 232, col: 1, offset: 6707},
 			expr: &actionExpr{
-				pos: position{line: 233, col: 22, offset: 6763},
+				pos: position{line: 232, col: 22, offset: 6728},
 				run: (*parser).callonCountValueOperator1,
 				expr: &litMatcher{
-					pos:        position{line: 233, col: 22, offset: 6763},
+					pos:        position{line: 232, col: 22, offset: 6728},
 					val:        "count_values",
 					ignoreCase: true,
 				},
@@ -2210,28 +2210,28 @@ var g = &grammar{
 		},
 		{
 			name: "BinaryAggregateOperators",
-			pos:  position{line: 239, col: 1, offset: 6848},
+			pos:  position{line: 238, col: 1, offset: 6813},
 			expr: &actionExpr{
-				pos: position{line: 239, col: 29, offset: 6876},
+				pos: position{line: 238, col: 29, offset: 6841},
 				run: (*parser).callonBinaryAggregateOperators1,
 				expr: &labeledExpr{
-					pos:   position{line: 239, col: 29, offset: 6876},
+					pos:   position{line: 238, col: 29, offset: 6841},
 					label: "op",
 					expr: &choiceExpr{
-						pos: position{line: 239, col: 33, offset: 6880},
+						pos: position{line: 238, col: 33, offset: 6845},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 239, col: 33, offset: 6880},
+								pos:        position{line: 238, col: 33, offset: 6845},
 								val:        "topk",
 								ignoreCase: true,
 							},
 							&litMatcher{
-								pos:        position{line: 239, col: 43, offset: 6890},
+								pos:        position{line: 238, col: 43, offset: 6855},
 								val:        "bottomk",
 								ignoreCase: true,
 							},
 							&litMatcher{
-								pos:        position{line: 239, col: 56, offset: 6903},
+								pos:        position{line: 238, col: 56, offset: 6868},
 								val:        "quantile",
 								ignoreCase: true,
 							},
@@ -2242,48 +2242,48 @@ var g = &grammar{
 		},
 		{
 			name: "UnaryAggregateOperators",
-			pos:  position{line: 245, col: 1, offset: 7005},
+			pos:  position{line: 244, col: 1, offset: 6970},
 			expr: &actionExpr{
-				pos: position{line: 245, col: 27, offset: 7031},
+				pos: position{line: 244, col: 27, offset: 6996},
 				run: (*parser).callonUnaryAggregateOperators1,
 				expr: &labeledExpr{
-					pos:   position{line: 245, col: 27, offset: 7031},
+					pos:   position{line: 244, col: 27, offset: 6996},
 					label: "op",
 					expr: &choiceExpr{
-						pos: position{line: 245, col: 31, offset: 7035},
+						pos: position{line: 244, col: 31, offset: 7000},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 245, col: 31, offset: 7035},
+								pos:        position{line: 244, col: 31, offset: 7000},
 								val:        "sum",
 								ignoreCase: true,
 							},
 							&litMatcher{
-								pos:        position{line: 245, col: 40, offset: 7044},
+								pos:        position{line: 244, col: 40, offset: 7009},
 								val:        "min",
 								ignoreCase: true,
 							},
 							&litMatcher{
-								pos:        position{line: 245, col: 49, offset: 7053},
+								pos:        position{line: 244, col: 49, offset: 7018},
 								val:        "max",
 								ignoreCase: true,
 							},
 							&litMatcher{
-								pos:        position{line: 245, col: 58, offset: 7062},
+								pos:        position{line: 244, col: 58, offset: 7027},
 								val:        "avg",
 								ignoreCase: true,
 							},
 							&litMatcher{
-								pos:        position{line: 245, col: 67, offset: 7071},
+								pos:        position{line: 244, col: 67, offset: 7036},
 								val:        "stddev",
 								ignoreCase: true,
 							},
 							&litMatcher{
-								pos:        position{line: 245, col: 79, offset: 7083},
+								pos:        position{line: 244, col: 79, offset: 7048},
 								val:        "stdvar",
 								ignoreCase: true,
 							},
 							&litMatcher{
-								pos:        position{line: 245, col: 91, offset: 7095},
+								pos:        position{line: 244, col: 91, offset: 7060},
 								val:        "count",
 								ignoreCase: true,
 							},
@@ -2294,20 +2294,20 @@ var g = &grammar{
 		},
 		{
 			name: "AggregateOperators",
-			pos:  position{line: 251, col: 1, offset: 7194},
+			pos:  position{line: 250, col: 1, offset: 7159},
 			expr: &choiceExpr{
-				pos: position{line: 251, col: 22, offset: 7215},
+				pos: position{line: 250, col: 22, offset: 7180},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 251, col: 22, offset: 7215},
+						pos:  position{line: 250, col: 22, offset: 7180},
 						name: "CountValueOperator",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 251, col: 43, offset: 7236},
+						pos:  position{line: 250, col: 43, offset: 7201},
 						name: "BinaryAggregateOperators",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 251, col: 70, offset: 7263},
+						pos:  position{line: 250, col: 70, offset: 7228},
 						name: "UnaryAggregateOperators",
 					},
 				},
@@ -2315,41 +2315,41 @@ var g = &grammar{
 		},
 		{
 			name: "AggregateBy",
-			pos:  position{line: 253, col: 1, offset: 7288},
+			pos:  position{line: 252, col: 1, offset: 7253},
 			expr: &actionExpr{
-				pos: position{line: 253, col: 15, offset: 7302},
+				pos: position{line: 252, col: 15, offset: 7267},
 				run: (*parser).callonAggregateBy1,
 				expr: &seqExpr{
-					pos: position{line: 253, col: 15, offset: 7302},
+					pos: position{line: 252, col: 15, offset: 7267},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 253, col: 15, offset: 7302},
+							pos:        position{line: 252, col: 15, offset: 7267},
 							val:        "by",
 							ignoreCase: true,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 253, col: 21, offset: 7308},
+							pos:  position{line: 252, col: 21, offset: 7273},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 253, col: 24, offset: 7311},
+							pos:   position{line: 252, col: 24, offset: 7276},
 							label: "labels",
 							expr: &ruleRefExpr{
-								pos:  position{line: 253, col: 31, offset: 7318},
+								pos:  position{line: 252, col: 31, offset: 7283},
 								name: "LabelList",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 253, col: 41, offset: 7328},
+							pos:  position{line: 252, col: 41, offset: 7293},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 253, col: 44, offset: 7331},
+							pos:   position{line: 252, col: 44, offset: 7296},
 							label: "keep",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 253, col: 49, offset: 7336},
+								pos: position{line: 252, col: 49, offset: 7301},
 								expr: &litMatcher{
-									pos:        position{line: 253, col: 49, offset: 7336},
+									pos:        position{line: 252, col: 49, offset: 7301},
 									val:        "keep_common",
 									ignoreCase: true,
 								},
@@ -2361,27 +2361,27 @@ var g = &grammar{
 		},
 		{
 			name: "AggregateWithout",
-			pos:  position{line: 260, col: 1, offset: 7449},
+			pos:  position{line: 259, col: 1, offset: 7414},
 			expr: &actionExpr{
-				pos: position{line: 260, col: 20, offset: 7468},
+				pos: position{line: 259, col: 20, offset: 7433},
 				run: (*parser).callonAggregateWithout1,
 				expr: &seqExpr{
-					pos: position{line: 260, col: 20, offset: 7468},
+					pos: position{line: 259, col: 20, offset: 7433},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 260, col: 20, offset: 7468},
+							pos:        position{line: 259, col: 20, offset: 7433},
 							val:        "without",
 							ignoreCase: true,
 						},
 						&ruleRefExpr{
-							pos:  position{line: 260, col: 31, offset: 7479},
+							pos:  position{line: 259, col: 31, offset: 7444},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 260, col: 34, offset: 7482},
+							pos:   position{line: 259, col: 34, offset: 7447},
 							label: "labels",
 							expr: &ruleRefExpr{
-								pos:  position{line: 260, col: 41, offset: 7489},
+								pos:  position{line: 259, col: 41, offset: 7454},
 								name: "LabelList",
 							},
 						},
@@ -2391,16 +2391,16 @@ var g = &grammar{
 		},
 		{
 			name: "AggregateGroup",
-			pos:  position{line: 267, col: 1, offset: 7601},
+			pos:  position{line: 266, col: 1, offset: 7566},
 			expr: &choiceExpr{
-				pos: position{line: 267, col: 18, offset: 7618},
+				pos: position{line: 266, col: 18, offset: 7583},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 267, col: 18, offset: 7618},
+						pos:  position{line: 266, col: 18, offset: 7583},
 						name: "AggregateBy",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 267, col: 32, offset: 7632},
+						pos:  position{line: 266, col: 32, offset: 7597},
 						name: "AggregateWithout",
 					},
 				},
@@ -2408,86 +2408,86 @@ var g = &grammar{
 		},
 		{
 			name: "AggregateExpression",
-			pos:  position{line: 269, col: 1, offset: 7650},
+			pos:  position{line: 268, col: 1, offset: 7615},
 			expr: &choiceExpr{
-				pos: position{line: 270, col: 1, offset: 7672},
+				pos: position{line: 269, col: 1, offset: 7637},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 270, col: 1, offset: 7672},
+						pos: position{line: 269, col: 1, offset: 7637},
 						run: (*parser).callonAggregateExpression2,
 						expr: &seqExpr{
-							pos: position{line: 270, col: 1, offset: 7672},
+							pos: position{line: 269, col: 1, offset: 7637},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 270, col: 1, offset: 7672},
+									pos:   position{line: 269, col: 1, offset: 7637},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 270, col: 4, offset: 7675},
+										pos:  position{line: 269, col: 4, offset: 7640},
 										name: "CountValueOperator",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 270, col: 24, offset: 7695},
+									pos:  position{line: 269, col: 24, offset: 7660},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 270, col: 27, offset: 7698},
+									pos:        position{line: 269, col: 27, offset: 7663},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 270, col: 31, offset: 7702},
+									pos:  position{line: 269, col: 31, offset: 7667},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 270, col: 34, offset: 7705},
+									pos:   position{line: 269, col: 34, offset: 7670},
 									label: "param",
 									expr: &ruleRefExpr{
-										pos:  position{line: 270, col: 40, offset: 7711},
+										pos:  position{line: 269, col: 40, offset: 7676},
 										name: "StringLiteral",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 270, col: 54, offset: 7725},
+									pos:  position{line: 269, col: 54, offset: 7690},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 270, col: 57, offset: 7728},
+									pos:        position{line: 269, col: 57, offset: 7693},
 									val:        ",",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 270, col: 61, offset: 7732},
+									pos:  position{line: 269, col: 61, offset: 7697},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 270, col: 64, offset: 7735},
+									pos:   position{line: 269, col: 64, offset: 7700},
 									label: "vector",
 									expr: &ruleRefExpr{
-										pos:  position{line: 270, col: 71, offset: 7742},
-										name: "VectorSelector",
+										pos:  position{line: 269, col: 71, offset: 7707},
+										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 270, col: 86, offset: 7757},
+									pos:  position{line: 269, col: 82, offset: 7718},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 270, col: 89, offset: 7760},
+									pos:        position{line: 269, col: 85, offset: 7721},
 									val:        ")",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 270, col: 93, offset: 7764},
+									pos:  position{line: 269, col: 89, offset: 7725},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 270, col: 96, offset: 7767},
+									pos:   position{line: 269, col: 92, offset: 7728},
 									label: "group",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 270, col: 102, offset: 7773},
+										pos: position{line: 269, col: 98, offset: 7734},
 										expr: &ruleRefExpr{
-											pos:  position{line: 270, col: 102, offset: 7773},
+											pos:  position{line: 269, col: 98, offset: 7734},
 											name: "AggregateGroup",
 										},
 									},
@@ -2496,82 +2496,82 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 276, col: 1, offset: 7921},
+						pos: position{line: 275, col: 1, offset: 7885},
 						run: (*parser).callonAggregateExpression22,
 						expr: &seqExpr{
-							pos: position{line: 276, col: 1, offset: 7921},
+							pos: position{line: 275, col: 1, offset: 7885},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 276, col: 1, offset: 7921},
+									pos:   position{line: 275, col: 1, offset: 7885},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 276, col: 4, offset: 7924},
+										pos:  position{line: 275, col: 4, offset: 7888},
 										name: "CountValueOperator",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 276, col: 24, offset: 7944},
+									pos:  position{line: 275, col: 24, offset: 7908},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 276, col: 27, offset: 7947},
+									pos:   position{line: 275, col: 27, offset: 7911},
 									label: "group",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 276, col: 33, offset: 7953},
+										pos: position{line: 275, col: 33, offset: 7917},
 										expr: &ruleRefExpr{
-											pos:  position{line: 276, col: 33, offset: 7953},
+											pos:  position{line: 275, col: 33, offset: 7917},
 											name: "AggregateGroup",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 276, col: 49, offset: 7969},
+									pos:  position{line: 275, col: 49, offset: 7933},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 276, col: 52, offset: 7972},
+									pos:        position{line: 275, col: 52, offset: 7936},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 276, col: 56, offset: 7976},
+									pos:  position{line: 275, col: 56, offset: 7940},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 276, col: 59, offset: 7979},
+									pos:   position{line: 275, col: 59, offset: 7943},
 									label: "param",
 									expr: &ruleRefExpr{
-										pos:  position{line: 276, col: 65, offset: 7985},
+										pos:  position{line: 275, col: 65, offset: 7949},
 										name: "StringLiteral",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 276, col: 79, offset: 7999},
+									pos:  position{line: 275, col: 79, offset: 7963},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 276, col: 82, offset: 8002},
+									pos:        position{line: 275, col: 82, offset: 7966},
 									val:        ",",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 276, col: 86, offset: 8006},
+									pos:  position{line: 275, col: 86, offset: 7970},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 276, col: 89, offset: 8009},
+									pos:   position{line: 275, col: 89, offset: 7973},
 									label: "vector",
 									expr: &ruleRefExpr{
-										pos:  position{line: 276, col: 96, offset: 8016},
-										name: "VectorSelector",
+										pos:  position{line: 275, col: 96, offset: 7980},
+										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 276, col: 111, offset: 8031},
+									pos:  position{line: 275, col: 107, offset: 7991},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 276, col: 114, offset: 8034},
+									pos:        position{line: 275, col: 110, offset: 7994},
 									val:        ")",
 									ignoreCase: false,
 								},
@@ -2579,81 +2579,81 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 282, col: 1, offset: 8170},
+						pos: position{line: 281, col: 1, offset: 8133},
 						run: (*parser).callonAggregateExpression42,
 						expr: &seqExpr{
-							pos: position{line: 282, col: 1, offset: 8170},
+							pos: position{line: 281, col: 1, offset: 8133},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 282, col: 1, offset: 8170},
+									pos:   position{line: 281, col: 1, offset: 8133},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 282, col: 4, offset: 8173},
+										pos:  position{line: 281, col: 4, offset: 8136},
 										name: "BinaryAggregateOperators",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 30, offset: 8199},
+									pos:  position{line: 281, col: 30, offset: 8162},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 282, col: 33, offset: 8202},
+									pos:        position{line: 281, col: 33, offset: 8165},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 37, offset: 8206},
+									pos:  position{line: 281, col: 37, offset: 8169},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 282, col: 41, offset: 8210},
+									pos:   position{line: 281, col: 41, offset: 8173},
 									label: "param",
 									expr: &ruleRefExpr{
-										pos:  position{line: 282, col: 47, offset: 8216},
+										pos:  position{line: 281, col: 47, offset: 8179},
 										name: "Number",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 54, offset: 8223},
+									pos:  position{line: 281, col: 54, offset: 8186},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 282, col: 57, offset: 8226},
+									pos:        position{line: 281, col: 57, offset: 8189},
 									val:        ",",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 61, offset: 8230},
+									pos:  position{line: 281, col: 61, offset: 8193},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 282, col: 64, offset: 8233},
+									pos:   position{line: 281, col: 64, offset: 8196},
 									label: "vector",
 									expr: &ruleRefExpr{
-										pos:  position{line: 282, col: 71, offset: 8240},
-										name: "VectorSelector",
+										pos:  position{line: 281, col: 71, offset: 8203},
+										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 86, offset: 8255},
+									pos:  position{line: 281, col: 82, offset: 8214},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 282, col: 89, offset: 8258},
+									pos:        position{line: 281, col: 85, offset: 8217},
 									val:        ")",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 93, offset: 8262},
+									pos:  position{line: 281, col: 89, offset: 8221},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 282, col: 96, offset: 8265},
+									pos:   position{line: 281, col: 92, offset: 8224},
 									label: "group",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 282, col: 102, offset: 8271},
+										pos: position{line: 281, col: 98, offset: 8230},
 										expr: &ruleRefExpr{
-											pos:  position{line: 282, col: 102, offset: 8271},
+											pos:  position{line: 281, col: 98, offset: 8230},
 											name: "AggregateGroup",
 										},
 									},
@@ -2662,82 +2662,82 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 288, col: 1, offset: 8412},
+						pos: position{line: 287, col: 1, offset: 8374},
 						run: (*parser).callonAggregateExpression62,
 						expr: &seqExpr{
-							pos: position{line: 288, col: 1, offset: 8412},
+							pos: position{line: 287, col: 1, offset: 8374},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 288, col: 1, offset: 8412},
+									pos:   position{line: 287, col: 1, offset: 8374},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 288, col: 4, offset: 8415},
+										pos:  position{line: 287, col: 4, offset: 8377},
 										name: "BinaryAggregateOperators",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 288, col: 30, offset: 8441},
+									pos:  position{line: 287, col: 30, offset: 8403},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 288, col: 33, offset: 8444},
+									pos:   position{line: 287, col: 33, offset: 8406},
 									label: "group",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 288, col: 39, offset: 8450},
+										pos: position{line: 287, col: 39, offset: 8412},
 										expr: &ruleRefExpr{
-											pos:  position{line: 288, col: 39, offset: 8450},
+											pos:  position{line: 287, col: 39, offset: 8412},
 											name: "AggregateGroup",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 288, col: 55, offset: 8466},
+									pos:  position{line: 287, col: 55, offset: 8428},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 288, col: 58, offset: 8469},
+									pos:        position{line: 287, col: 58, offset: 8431},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 288, col: 62, offset: 8473},
+									pos:  position{line: 287, col: 62, offset: 8435},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 288, col: 66, offset: 8477},
+									pos:   position{line: 287, col: 66, offset: 8439},
 									label: "param",
 									expr: &ruleRefExpr{
-										pos:  position{line: 288, col: 72, offset: 8483},
+										pos:  position{line: 287, col: 72, offset: 8445},
 										name: "Number",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 288, col: 79, offset: 8490},
+									pos:  position{line: 287, col: 79, offset: 8452},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 288, col: 82, offset: 8493},
+									pos:        position{line: 287, col: 82, offset: 8455},
 									val:        ",",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 288, col: 86, offset: 8497},
+									pos:  position{line: 287, col: 86, offset: 8459},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 288, col: 89, offset: 8500},
+									pos:   position{line: 287, col: 89, offset: 8462},
 									label: "vector",
 									expr: &ruleRefExpr{
-										pos:  position{line: 288, col: 96, offset: 8507},
-										name: "VectorSelector",
+										pos:  position{line: 287, col: 96, offset: 8469},
+										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 288, col: 111, offset: 8522},
+									pos:  position{line: 287, col: 107, offset: 8480},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 288, col: 114, offset: 8525},
+									pos:        position{line: 287, col: 110, offset: 8483},
 									val:        ")",
 									ignoreCase: false,
 								},
@@ -2745,60 +2745,60 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 294, col: 1, offset: 8654},
+						pos: position{line: 293, col: 1, offset: 8615},
 						run: (*parser).callonAggregateExpression82,
 						expr: &seqExpr{
-							pos: position{line: 294, col: 1, offset: 8654},
+							pos: position{line: 293, col: 1, offset: 8615},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 294, col: 1, offset: 8654},
+									pos:   position{line: 293, col: 1, offset: 8615},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 294, col: 4, offset: 8657},
+										pos:  position{line: 293, col: 4, offset: 8618},
 										name: "UnaryAggregateOperators",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 294, col: 29, offset: 8682},
+									pos:  position{line: 293, col: 29, offset: 8643},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 294, col: 32, offset: 8685},
+									pos:        position{line: 293, col: 32, offset: 8646},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 294, col: 36, offset: 8689},
+									pos:  position{line: 293, col: 36, offset: 8650},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 294, col: 39, offset: 8692},
+									pos:   position{line: 293, col: 39, offset: 8653},
 									label: "vector",
 									expr: &ruleRefExpr{
-										pos:  position{line: 294, col: 46, offset: 8699},
-										name: "VectorSelector",
+										pos:  position{line: 293, col: 46, offset: 8660},
+										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 294, col: 61, offset: 8714},
+									pos:  position{line: 293, col: 57, offset: 8671},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 294, col: 64, offset: 8717},
+									pos:        position{line: 293, col: 60, offset: 8674},
 									val:        ")",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 294, col: 68, offset: 8721},
+									pos:  position{line: 293, col: 64, offset: 8678},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 294, col: 71, offset: 8724},
+									pos:   position{line: 293, col: 67, offset: 8681},
 									label: "group",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 294, col: 77, offset: 8730},
+										pos: position{line: 293, col: 73, offset: 8687},
 										expr: &ruleRefExpr{
-											pos:  position{line: 294, col: 77, offset: 8730},
+											pos:  position{line: 293, col: 73, offset: 8687},
 											name: "AggregateGroup",
 										},
 									},
@@ -2807,61 +2807,61 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 298, col: 1, offset: 8823},
+						pos: position{line: 297, col: 1, offset: 8783},
 						run: (*parser).callonAggregateExpression97,
 						expr: &seqExpr{
-							pos: position{line: 298, col: 1, offset: 8823},
+							pos: position{line: 297, col: 1, offset: 8783},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 298, col: 1, offset: 8823},
+									pos:   position{line: 297, col: 1, offset: 8783},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 298, col: 4, offset: 8826},
+										pos:  position{line: 297, col: 4, offset: 8786},
 										name: "UnaryAggregateOperators",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 298, col: 29, offset: 8851},
+									pos:  position{line: 297, col: 29, offset: 8811},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 298, col: 32, offset: 8854},
+									pos:   position{line: 297, col: 32, offset: 8814},
 									label: "group",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 298, col: 38, offset: 8860},
+										pos: position{line: 297, col: 38, offset: 8820},
 										expr: &ruleRefExpr{
-											pos:  position{line: 298, col: 38, offset: 8860},
+											pos:  position{line: 297, col: 38, offset: 8820},
 											name: "AggregateGroup",
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 298, col: 54, offset: 8876},
+									pos:  position{line: 297, col: 54, offset: 8836},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 298, col: 57, offset: 8879},
+									pos:        position{line: 297, col: 57, offset: 8839},
 									val:        "(",
 									ignoreCase: false,
 								},
 								&ruleRefExpr{
-									pos:  position{line: 298, col: 61, offset: 8883},
+									pos:  position{line: 297, col: 61, offset: 8843},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 298, col: 64, offset: 8886},
+									pos:   position{line: 297, col: 64, offset: 8846},
 									label: "vector",
 									expr: &ruleRefExpr{
-										pos:  position{line: 298, col: 71, offset: 8893},
-										name: "VectorSelector",
+										pos:  position{line: 297, col: 71, offset: 8853},
+										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 298, col: 86, offset: 8908},
+									pos:  position{line: 297, col: 82, offset: 8864},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 298, col: 89, offset: 8911},
+									pos:        position{line: 297, col: 85, offset: 8867},
 									val:        ")",
 									ignoreCase: false,
 								},
@@ -2871,24 +2871,636 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "Expression",
+			pos:  position{line: 301, col: 1, offset: 8950},
+			expr: &ruleRefExpr{
+				pos:  position{line: 301, col: 14, offset: 8963},
+				name: "ComparisonExpression",
+			},
+		},
+		{
+			name: "ComparisonExpression",
+			pos:  position{line: 303, col: 1, offset: 8985},
+			expr: &actionExpr{
+				pos: position{line: 303, col: 24, offset: 9008},
+				run: (*parser).callonComparisonExpression1,
+				expr: &seqExpr{
+					pos: position{line: 303, col: 24, offset: 9008},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 303, col: 24, offset: 9008},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 303, col: 30, offset: 9014},
+								name: "AdditiveExpression",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 303, col: 49, offset: 9033},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 303, col: 54, offset: 9038},
+								expr: &ruleRefExpr{
+									pos:  position{line: 303, col: 54, offset: 9038},
+									name: "ComparisonRest",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ComparisonRest",
+			pos:  position{line: 307, col: 1, offset: 9112},
+			expr: &actionExpr{
+				pos: position{line: 307, col: 18, offset: 9129},
+				run: (*parser).callonComparisonRest1,
+				expr: &seqExpr{
+					pos: position{line: 307, col: 18, offset: 9129},
+					exprs: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 307, col: 18, offset: 9129},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 307, col: 21, offset: 9132},
+							label: "op",
+							expr: &ruleRefExpr{
+								pos:  position{line: 307, col: 24, offset: 9135},
+								name: "ComparisonOperators",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 307, col: 44, offset: 9155},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 307, col: 47, offset: 9158},
+							label: "returnBool",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 307, col: 58, offset: 9169},
+								expr: &ruleRefExpr{
+									pos:  position{line: 307, col: 58, offset: 9169},
+									name: "BoolModifier",
+								},
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 307, col: 72, offset: 9183},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 307, col: 75, offset: 9186},
+							label: "rhs",
+							expr: &ruleRefExpr{
+								pos:  position{line: 307, col: 79, offset: 9190},
+								name: "AdditiveExpression",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AdditiveExpression",
+			pos:  position{line: 311, col: 1, offset: 9300},
+			expr: &actionExpr{
+				pos: position{line: 311, col: 22, offset: 9321},
+				run: (*parser).callonAdditiveExpression1,
+				expr: &seqExpr{
+					pos: position{line: 311, col: 22, offset: 9321},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 311, col: 22, offset: 9321},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 311, col: 28, offset: 9327},
+								name: "MultiplicativeExpression",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 311, col: 53, offset: 9352},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 311, col: 58, offset: 9357},
+								expr: &ruleRefExpr{
+									pos:  position{line: 311, col: 58, offset: 9357},
+									name: "AdditiveRest",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AdditiveRest",
+			pos:  position{line: 315, col: 1, offset: 9429},
+			expr: &actionExpr{
+				pos: position{line: 315, col: 16, offset: 9444},
+				run: (*parser).callonAdditiveRest1,
+				expr: &seqExpr{
+					pos: position{line: 315, col: 16, offset: 9444},
+					exprs: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 315, col: 16, offset: 9444},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 315, col: 19, offset: 9447},
+							label: "op",
+							expr: &ruleRefExpr{
+								pos:  position{line: 315, col: 22, offset: 9450},
+								name: "AdditiveOperators",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 315, col: 40, offset: 9468},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 315, col: 43, offset: 9471},
+							label: "rhs",
+							expr: &ruleRefExpr{
+								pos:  position{line: 315, col: 47, offset: 9475},
+								name: "MultiplicativeExpression",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "MultiplicativeExpression",
+			pos:  position{line: 319, col: 1, offset: 9579},
+			expr: &actionExpr{
+				pos: position{line: 319, col: 28, offset: 9606},
+				run: (*parser).callonMultiplicativeExpression1,
+				expr: &seqExpr{
+					pos: position{line: 319, col: 28, offset: 9606},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 319, col: 28, offset: 9606},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 319, col: 34, offset: 9612},
+								name: "PowerExpression",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 319, col: 50, offset: 9628},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 319, col: 55, offset: 9633},
+								expr: &ruleRefExpr{
+									pos:  position{line: 319, col: 55, offset: 9633},
+									name: "MultiplicativeRest",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "MultiplicativeRest",
+			pos:  position{line: 323, col: 1, offset: 9711},
+			expr: &actionExpr{
+				pos: position{line: 323, col: 22, offset: 9732},
+				run: (*parser).callonMultiplicativeRest1,
+				expr: &seqExpr{
+					pos: position{line: 323, col: 22, offset: 9732},
+					exprs: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 323, col: 22, offset: 9732},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 323, col: 25, offset: 9735},
+							label: "op",
+							expr: &ruleRefExpr{
+								pos:  position{line: 323, col: 28, offset: 9738},
+								name: "MultiplicativeOperators",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 323, col: 52, offset: 9762},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 323, col: 55, offset: 9765},
+							label: "rhs",
+							expr: &ruleRefExpr{
+								pos:  position{line: 323, col: 59, offset: 9769},
+								name: "PowerExpression",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "PowerExpression",
+			pos:  position{line: 328, col: 1, offset: 9908},
+			expr: &choiceExpr{
+				pos: position{line: 328, col: 19, offset: 9926},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 328, col: 19, offset: 9926},
+						run: (*parser).callonPowerExpression2,
+						expr: &seqExpr{
+							pos: position{line: 328, col: 19, offset: 9926},
+							exprs: []interface{}{
+								&labeledExpr{
+									pos:   position{line: 328, col: 19, offset: 9926},
+									label: "lhs",
+									expr: &ruleRefExpr{
+										pos:  position{line: 328, col: 23, offset: 9930},
+										name: "PrimaryExpression",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 328, col: 41, offset: 9948},
+									name: "__",
+								},
+								&litMatcher{
+									pos:        position{line: 328, col: 44, offset: 9951},
+									val:        "^",
+									ignoreCase: false,
+								},
+								&ruleRefExpr{
+									pos:  position{line: 328, col: 48, offset: 9955},
+									name: "__",
+								},
+								&labeledExpr{
+									pos:   position{line: 328, col: 51, offset: 9958},
+									label: "rhs",
+									expr: &ruleRefExpr{
+										pos:  position{line: 328, col: 55, offset: 9962},
+										name: "PowerExpression",
+									},
+								},
+							},
+						},
+					},
+					&ruleRefExpr{
+						pos:  position{line: 330, col: 5, offset: 10075},
+						name: "PrimaryExpression",
+					},
+				},
+			},
+		},
+		{
+			name: "PrimaryExpression",
+			pos:  position{line: 332, col: 1, offset: 10094},
+			expr: &choiceExpr{
+				pos: position{line: 332, col: 21, offset: 10114},
+				alternatives: []interface{}{
+					&ruleRefExpr{
+						pos:  position{line: 332, col: 21, offset: 10114},
+						name: "ParenExpression",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 332, col: 39, offset: 10132},
+						name: "AggregateExpression",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 332, col: 61, offset: 10154},
+						name: "FunctionCall",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 332, col: 76, offset: 10169},
+						name: "Number",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 332, col: 85, offset: 10178},
+						name: "VectorSelector",
+					},
+				},
+			},
+		},
+		{
+			name: "ParenExpression",
+			pos:  position{line: 334, col: 1, offset: 10194},
+			expr: &actionExpr{
+				pos: position{line: 334, col: 19, offset: 10212},
+				run: (*parser).callonParenExpression1,
+				expr: &seqExpr{
+					pos: position{line: 334, col: 19, offset: 10212},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 334, col: 19, offset: 10212},
+							val:        "(",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 334, col: 23, offset: 10216},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 334, col: 26, offset: 10219},
+							label: "expr",
+							expr: &ruleRefExpr{
+								pos:  position{line: 334, col: 31, offset: 10224},
+								name: "Expression",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 334, col: 42, offset: 10235},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 334, col: 45, offset: 10238},
+							val:        ")",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ComparisonOperators",
+			pos:  position{line: 338, col: 1, offset: 10268},
+			expr: &choiceExpr{
+				pos: position{line: 338, col: 23, offset: 10290},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 338, col: 23, offset: 10290},
+						run: (*parser).callonComparisonOperators2,
+						expr: &litMatcher{
+							pos:        position{line: 338, col: 23, offset: 10290},
+							val:        "==",
+							ignoreCase: false,
+						},
+					},
+					&actionExpr{
+						pos: position{line: 340, col: 5, offset: 10325},
+						run: (*parser).callonComparisonOperators4,
+						expr: &litMatcher{
+							pos:        position{line: 340, col: 5, offset: 10325},
+							val:        "!=",
+							ignoreCase: false,
+						},
+					},
+					&actionExpr{
+						pos: position{line: 342, col: 5, offset: 10360},
+						run: (*parser).callonComparisonOperators6,
+						expr: &litMatcher{
+							pos:        position{line: 342, col: 5, offset: 10360},
+							val:        "<=",
+							ignoreCase: false,
+						},
+					},
+					&actionExpr{
+						pos: position{line: 344, col: 5, offset: 10395},
+						run: (*parser).callonComparisonOperators8,
+						expr: &litMatcher{
+							pos:        position{line: 344, col: 5, offset: 10395},
+							val:        "<",
+							ignoreCase: false,
+						},
+					},
+					&actionExpr{
+						pos: position{line: 346, col: 5, offset: 10429},
+						run: (*parser).callonComparisonOperators10,
+						expr: &litMatcher{
+							pos:        position{line: 346, col: 5, offset: 10429},
+							val:        ">=",
+							ignoreCase: false,
+						},
+					},
+					&actionExpr{
+						pos: position{line: 348, col: 5, offset: 10464},
+						run: (*parser).callonComparisonOperators12,
+						expr: &litMatcher{
+							pos:        position{line: 348, col: 5, offset: 10464},
+							val:        ">",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "AdditiveOperators",
+			pos:  position{line: 352, col: 1, offset: 10497},
+			expr: &choiceExpr{
+				pos: position{line: 352, col: 21, offset: 10517},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 352, col: 21, offset: 10517},
+						run: (*parser).callonAdditiveOperators2,
+						expr: &litMatcher{
+							pos:        position{line: 352, col: 21, offset: 10517},
+							val:        "+",
+							ignoreCase: false,
+						},
+					},
+					&actionExpr{
+						pos: position{line: 354, col: 5, offset: 10551},
+						run: (*parser).callonAdditiveOperators4,
+						expr: &litMatcher{
+							pos:        position{line: 354, col: 5, offset: 10551},
+							val:        "-",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "MultiplicativeOperators",
+			pos:  position{line: 358, col: 1, offset: 10584},
+			expr: &choiceExpr{
+				pos: position{line: 358, col: 27, offset: 10610},
+				alternatives: []interface{}{
+					&actionExpr{
+						pos: position{line: 358, col: 27, offset: 10610},
+						run: (*parser).callonMultiplicativeOperators2,
+						expr: &litMatcher{
+							pos:        position{line: 358, col: 27, offset: 10610},
+							val:        "*",
+							ignoreCase: false,
+						},
+					},
+					&actionExpr{
+						pos: position{line: 360, col: 5, offset: 10644},
+						run: (*parser).callonMultiplicativeOperators4,
+						expr: &litMatcher{
+							pos:        position{line: 360, col: 5, offset: 10644},
+							val:        "/",
+							ignoreCase: false,
+						},
+					},
+					&actionExpr{
+						pos: position{line: 362, col: 5, offset: 10678},
+						run: (*parser).callonMultiplicativeOperators6,
+						expr: &litMatcher{
+							pos:        position{line: 362, col: 5, offset: 10678},
+							val:        "%",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "BoolModifier",
+			pos:  position{line: 366, col: 1, offset: 10711},
+			expr: &seqExpr{
+				pos: position{line: 366, col: 16, offset: 10726},
+				exprs: []interface{}{
+					&litMatcher{
+						pos:        position{line: 366, col: 16, offset: 10726},
+						val:        "bool",
+						ignoreCase: true,
+					},
+					&notExpr{
+						pos: position{line: 366, col: 24, offset: 10734},
+						expr: &ruleRefExpr{
+							pos:  position{line: 366, col: 25, offset: 10735},
+							name: "IdentifierPart",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "FunctionNames",
+			pos:  position{line: 368, col: 1, offset: 10751},
+			expr: &actionExpr{
+				pos: position{line: 368, col: 17, offset: 10767},
+				run: (*parser).callonFunctionNames1,
+				expr: &seqExpr{
+					pos: position{line: 368, col: 17, offset: 10767},
+					exprs: []interface{}{
+						&choiceExpr{
+							pos: position{line: 368, col: 19, offset: 10769},
+							alternatives: []interface{}{
+								&litMatcher{
+									pos:        position{line: 368, col: 19, offset: 10769},
+									val:        "rate",
+									ignoreCase: true,
+								},
+								&litMatcher{
+									pos:        position{line: 368, col: 29, offset: 10779},
+									val:        "irate",
+									ignoreCase: true,
+								},
+								&litMatcher{
+									pos:        position{line: 368, col: 40, offset: 10790},
+									val:        "increase",
+									ignoreCase: true,
+								},
+								&litMatcher{
+									pos:        position{line: 368, col: 54, offset: 10804},
+									val:        "avg_over_time",
+									ignoreCase: true,
+								},
+								&litMatcher{
+									pos:        position{line: 368, col: 73, offset: 10823},
+									val:        "min_over_time",
+									ignoreCase: true,
+								},
+								&litMatcher{
+									pos:        position{line: 368, col: 92, offset: 10842},
+									val:        "max_over_time",
+									ignoreCase: true,
+								},
+								&litMatcher{
+									pos:        position{line: 368, col: 111, offset: 10861},
+									val:        "sum_over_time",
+									ignoreCase: true,
+								},
+								&litMatcher{
+									pos:        position{line: 368, col: 130, offset: 10880},
+									val:        "count_over_time",
+									ignoreCase: true,
+								},
+							},
+						},
+						&notExpr{
+							pos: position{line: 368, col: 151, offset: 10901},
+							expr: &ruleRefExpr{
+								pos:  position{line: 368, col: 152, offset: 10902},
+								name: "IdentifierPart",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "FunctionCall",
+			pos:  position{line: 372, col: 1, offset: 10969},
+			expr: &actionExpr{
+				pos: position{line: 372, col: 16, offset: 10984},
+				run: (*parser).callonFunctionCall1,
+				expr: &seqExpr{
+					pos: position{line: 372, col: 16, offset: 10984},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 372, col: 16, offset: 10984},
+							label: "fn",
+							expr: &ruleRefExpr{
+								pos:  position{line: 372, col: 19, offset: 10987},
+								name: "FunctionNames",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 372, col: 33, offset: 11001},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 372, col: 36, offset: 11004},
+							val:        "(",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 372, col: 40, offset: 11008},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 372, col: 43, offset: 11011},
+							label: "arg",
+							expr: &ruleRefExpr{
+								pos:  position{line: 372, col: 47, offset: 11015},
+								name: "Expression",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 372, col: 58, offset: 11026},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 372, col: 61, offset: 11029},
+							val:        ")",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "__",
-			pos:  position{line: 302, col: 1, offset: 8991},
+			pos:  position{line: 376, col: 1, offset: 11096},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 302, col: 6, offset: 8996},
+				pos: position{line: 376, col: 6, offset: 11101},
 				expr: &choiceExpr{
-					pos: position{line: 302, col: 8, offset: 8998},
+					pos: position{line: 376, col: 8, offset: 11103},
 					alternatives: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 302, col: 8, offset: 8998},
+							pos:  position{line: 376, col: 8, offset: 11103},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 302, col: 21, offset: 9011},
+							pos:  position{line: 376, col: 21, offset: 11116},
 							name: "EOL",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 302, col: 27, offset: 9017},
+							pos:  position{line: 376, col: 27, offset: 11122},
 							name: "Comment",
 						},
 					},
@@ -2897,20 +3509,20 @@ var g = &grammar{
 		},
 		{
 			name: "_",
-			pos:  position{line: 303, col: 1, offset: 9028},
+			pos:  position{line: 377, col: 1, offset: 11133},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 303, col: 5, offset: 9032},
+				pos: position{line: 377, col: 5, offset: 11137},
 				expr: &ruleRefExpr{
-					pos:  position{line: 303, col: 5, offset: 9032},
+					pos:  position{line: 377, col: 5, offset: 11137},
 					name: "Whitespace",
 				},
 			},
 		},
 		{
 			name: "Whitespace",
-			pos:  position{line: 305, col: 1, offset: 9045},
+			pos:  position{line: 379, col: 1, offset: 11150},
 			expr: &charClassMatcher{
-				pos:        position{line: 305, col: 14, offset: 9058},
+				pos:        position{line: 379, col: 14, offset: 11163},
 				val:        "[ \\t\\r]",
 				chars:      []rune{' ', '\t', '\r'},
 				ignoreCase: false,
@@ -2919,62 +3531,62 @@ var g = &grammar{
 		},
 		{
 			name: "EOL",
-			pos:  position{line: 306, col: 1, offset: 9066},
+			pos:  position{line: 380, col: 1, offset: 11171},
 			expr: &litMatcher{
-				pos:        position{line: 306, col: 7, offset: 9072},
+				pos:        position{line: 380, col: 7, offset: 11177},
 				val:        "\n",
 				ignoreCase: false,
 			},
 		},
 		{
 			name: "EOS",
-			pos:  position{line: 307, col: 1, offset: 9077},
+			pos:  position{line: 381, col: 1, offset: 11182},
 			expr: &choiceExpr{
-				pos: position{line: 307, col: 7, offset: 9083},
+				pos: position{line: 381, col: 7, offset: 11188},
 				alternatives: []interface{}{
 					&seqExpr{
-						pos: position{line: 307, col: 7, offset: 9083},
+						pos: position{line: 381, col: 7, offset: 11188},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 307, col: 7, offset: 9083},
+								pos:  position{line: 381, col: 7, offset: 11188},
 								name: "__",
 							},
 							&litMatcher{
-								pos:        position{line: 307, col: 10, offset: 9086},
+								pos:        position{line: 381, col: 10, offset: 11191},
 								val:        ";",
 								ignoreCase: false,
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 307, col: 16, offset: 9092},
+						pos: position{line: 381, col: 16, offset: 11197},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 307, col: 16, offset: 9092},
+								pos:  position{line: 381, col: 16, offset: 11197},
 								name: "_",
 							},
 							&zeroOrOneExpr{
-								pos: position{line: 307, col: 18, offset: 9094},
+								pos: position{line: 381, col: 18, offset: 11199},
 								expr: &ruleRefExpr{
-									pos:  position{line: 307, col: 18, offset: 9094},
+									pos:  position{line: 381, col: 18, offset: 11199},
 									name: "SingleLineComment",
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 307, col: 37, offset: 9113},
+								pos:  position{line: 381, col: 37, offset: 11218},
 								name: "EOL",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 307, col: 43, offset: 9119},
+						pos: position{line: 381, col: 43, offset: 11224},
 						exprs: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 307, col: 43, offset: 9119},
+								pos:  position{line: 381, col: 43, offset: 11224},
 								name: "__",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 307, col: 46, offset: 9122},
+								pos:  position{line: 381, col: 46, offset: 11227},
 								name: "EOF",
 							},
 						},
@@ -2984,11 +3596,11 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 309, col: 1, offset: 9127},
+			pos:  position{line: 383, col: 1, offset: 11232},
 			expr: &notExpr{
-				pos: position{line: 309, col: 7, offset: 9133},
+				pos: position{line: 383, col: 7, offset: 11238},
 				expr: &anyMatcher{
-					line: 309, col: 8, offset: 9134,
+					line: 383, col: 8, offset: 11239,
 				},
 			},
 		},
@@ -3555,7 +4167,7 @@ func (p *parser) callonAggregateWithout1() (interface{}, error) {
 func (c *current) onAggregateExpression2(op, param, vector, group interface{}) (interface{}, error) {
 	oper := op.(*Operator)
 	oper.Arg = param.(*StringLiteral)
-	return NewAggregateExpr(oper, vector.(*Selector), group)
+	return NewAggregateExpr(oper, vector.(QueryBuilder), group)
 }
 
 func (p *parser) callonAggregateExpression2() (interface{}, error) {
@@ -3567,7 +4179,7 @@ func (p *parser) callonAggregateExpression2() (interface{}, error) {
 func (c *current) onAggregateExpression22(op, group, param, vector interface{}) (interface{}, error) {
 	oper := op.(*Operator)
 	oper.Arg = param.(*StringLiteral)
-	return NewAggregateExpr(oper, vector.(*Selector), group)
+	return NewAggregateExpr(oper, vector.(QueryBuilder), group)
 }
 
 func (p *parser) callonAggregateExpression22() (interface{}, error) {
@@ -3579,7 +4191,7 @@ func (p *parser) callonAggregateExpression22() (interface{}, error) {
 func (c *current) onAggregateExpression42(op, param, vector, group interface{}) (interface{}, error) {
 	oper := op.(*Operator)
 	oper.Arg = param.(*Number)
-	return NewAggregateExpr(oper, vector.(*Selector), group)
+	return NewAggregateExpr(oper, vector.(QueryBuilder), group)
 }
 
 func (p *parser) callonAggregateExpression42() (interface{}, error) {
@@ -3591,7 +4203,7 @@ func (p *parser) callonAggregateExpression42() (interface{}, error) {
 func (c *current) onAggregateExpression62(op, group, param, vector interface{}) (interface{}, error) {
 	oper := op.(*Operator)
 	oper.Arg = param.(*Number)
-	return NewAggregateExpr(oper, vector.(*Selector), group)
+	return NewAggregateExpr(oper, vector.(QueryBuilder), group)
 }
 
 func (p *parser) callonAggregateExpression62() (interface{}, error) {
@@ -3601,7 +4213,7 @@ func (p *parser) callonAggregateExpression62() (interface{}, error) {
 }
 
 func (c *current) onAggregateExpression82(op, vector, group interface{}) (interface{}, error) {
-	return NewAggregateExpr(op.(*Operator), vector.(*Selector), group)
+	return NewAggregateExpr(op.(*Operator), vector.(QueryBuilder), group)
 }
 
 func (p *parser) callonAggregateExpression82() (interface{}, error) {
@@ -3611,7 +4223,7 @@ func (p *parser) callonAggregateExpression82() (interface{}, error) {
 }
 
 func (c *current) onAggregateExpression97(op, group, vector interface{}) (interface{}, error) {
-	return NewAggregateExpr(op.(*Operator), vector.(*Selector), group)
+	return NewAggregateExpr(op.(*Operator), vector.(QueryBuilder), group)
 }
 
 func (p *parser) callonAggregateExpression97() (interface{}, error) {
@@ -3620,6 +4232,216 @@ func (p *parser) callonAggregateExpression97() (interface{}, error) {
 	return p.cur.onAggregateExpression97(stack["op"], stack["group"], stack["vector"])
 }
 
+func (c *current) onComparisonExpression1(first, rest interface{}) (interface{}, error) {
+	return NewBinaryExpr(first.(QueryBuilder), rest)
+}
+
+func (p *parser) callonComparisonExpression1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonExpression1(stack["first"], stack["rest"])
+}
+
+func (c *current) onComparisonRest1(op, returnBool, rhs interface{}) (interface{}, error) {
+	return NewBinaryOperand(op.(BinaryOpKind), returnBool != nil, rhs.(QueryBuilder))
+}
+
+func (p *parser) callonComparisonRest1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonRest1(stack["op"], stack["returnBool"], stack["rhs"])
+}
+
+func (c *current) onAdditiveExpression1(first, rest interface{}) (interface{}, error) {
+	return NewBinaryExpr(first.(QueryBuilder), rest)
+}
+
+func (p *parser) callonAdditiveExpression1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAdditiveExpression1(stack["first"], stack["rest"])
+}
+
+func (c *current) onAdditiveRest1(op, rhs interface{}) (interface{}, error) {
+	return NewBinaryOperand(op.(BinaryOpKind), false, rhs.(QueryBuilder))
+}
+
+func (p *parser) callonAdditiveRest1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAdditiveRest1(stack["op"], stack["rhs"])
+}
+
+func (c *current) onMultiplicativeExpression1(first, rest interface{}) (interface{}, error) {
+	return NewBinaryExpr(first.(QueryBuilder), rest)
+}
+
+func (p *parser) callonMultiplicativeExpression1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiplicativeExpression1(stack["first"], stack["rest"])
+}
+
+func (c *current) onMultiplicativeRest1(op, rhs interface{}) (interface{}, error) {
+	return NewBinaryOperand(op.(BinaryOpKind), false, rhs.(QueryBuilder))
+}
+
+func (p *parser) callonMultiplicativeRest1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiplicativeRest1(stack["op"], stack["rhs"])
+}
+
+func (c *current) onPowerExpression2(lhs, rhs interface{}) (interface{}, error) {
+	return &BinaryExpr{Op: PowKind, LHS: lhs.(QueryBuilder), RHS: rhs.(QueryBuilder)}, nil
+}
+
+func (p *parser) callonPowerExpression2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPowerExpression2(stack["lhs"], stack["rhs"])
+}
+
+func (c *current) onParenExpression1(expr interface{}) (interface{}, error) {
+	return expr, nil
+}
+
+func (p *parser) callonParenExpression1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onParenExpression1(stack["expr"])
+}
+
+func (c *current) onComparisonOperators2() (interface{}, error) {
+	return EqlKind, nil
+}
+
+func (p *parser) callonComparisonOperators2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonOperators2()
+}
+
+func (c *current) onComparisonOperators4() (interface{}, error) {
+	return NeqKind, nil
+}
+
+func (p *parser) callonComparisonOperators4() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonOperators4()
+}
+
+func (c *current) onComparisonOperators6() (interface{}, error) {
+	return LteKind, nil
+}
+
+func (p *parser) callonComparisonOperators6() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonOperators6()
+}
+
+func (c *current) onComparisonOperators8() (interface{}, error) {
+	return LssKind, nil
+}
+
+func (p *parser) callonComparisonOperators8() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonOperators8()
+}
+
+func (c *current) onComparisonOperators10() (interface{}, error) {
+	return GteKind, nil
+}
+
+func (p *parser) callonComparisonOperators10() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonOperators10()
+}
+
+func (c *current) onComparisonOperators12() (interface{}, error) {
+	return GtrKind, nil
+}
+
+func (p *parser) callonComparisonOperators12() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onComparisonOperators12()
+}
+
+func (c *current) onAdditiveOperators2() (interface{}, error) {
+	return AddKind, nil
+}
+
+func (p *parser) callonAdditiveOperators2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAdditiveOperators2()
+}
+
+func (c *current) onAdditiveOperators4() (interface{}, error) {
+	return SubKind, nil
+}
+
+func (p *parser) callonAdditiveOperators4() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAdditiveOperators4()
+}
+
+func (c *current) onMultiplicativeOperators2() (interface{}, error) {
+	return MulKind, nil
+}
+
+func (p *parser) callonMultiplicativeOperators2() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiplicativeOperators2()
+}
+
+func (c *current) onMultiplicativeOperators4() (interface{}, error) {
+	return DivKind, nil
+}
+
+func (p *parser) callonMultiplicativeOperators4() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiplicativeOperators4()
+}
+
+func (c *current) onMultiplicativeOperators6() (interface{}, error) {
+	return ModKind, nil
+}
+
+func (p *parser) callonMultiplicativeOperators6() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onMultiplicativeOperators6()
+}
+
+func (c *current) onFunctionNames1() (interface{}, error) {
+	return ToFunctionKind(string(c.text)), nil
+}
+
+func (p *parser) callonFunctionNames1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFunctionNames1()
+}
+
+func (c *current) onFunctionCall1(fn, arg interface{}) (interface{}, error) {
+	return NewCall(fn.(FunctionKind), arg.(QueryBuilder))
+}
+
+func (p *parser) callonFunctionCall1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onFunctionCall1(stack["fn"], stack["arg"])
+}
+
 var (
 	// errNoRule is returned when the grammar to parse has no rule.
 	errNoRule = errors.New("grammar has no rule")
@@ -3678,18 +4500,17 @@ func Entrypoint(ruleName string) Option {
 //
 // Example usage:
 //
-//     input := "input"
-//     stats := Stats{}
-//     _, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     fmt.Println(string(b))
-//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
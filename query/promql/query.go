@@ -3,10 +3,31 @@ package promql
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/influxdata/flux"
 )
 
+// DefaultLookbackDelta is how long before an evaluation time an instant
+// vector selector looks for the latest sample of a series, as in Prometheus.
+const DefaultLookbackDelta = 5 * time.Minute
+
+// Evaluation is the bucket and the times a PromQL expression is evaluated at.
+type Evaluation struct {
+	// Bucket is the name of the bucket the samples are read from.
+	Bucket string
+	// Start and End are the first and last evaluation times. They are equal
+	// for an instant query.
+	Start, End time.Time
+	// Step is the time between evaluations of a range query.
+	Step time.Duration
+}
+
+// Instant returns whether the expression is evaluated at a single time.
+func (e Evaluation) Instant() bool {
+	return e.Start.Equal(e.End)
+}
+
 func ParsePromQL(promql string, opts ...Option) (interface{}, error) {
 	f, err := Parse("", []byte(promql), opts...)
 	if err != nil {
@@ -15,14 +36,58 @@ func ParsePromQL(promql string, opts ...Option) (interface{}, error) {
 	return f, nil
 }
 
-func Build(promql string, opts ...Option) (*flux.Spec, error) {
+// Build builds a flux query specification that evaluates promql at each
+// step of e.
+//
+// Samples are read with the schema written by the Prometheus remote write
+// endpoint: the metric name is the measurement, the labels are tags and the
+// sample is the "value" field. The result has a table for each series, and a
+// row for each time the series has a value. Instant queries may select a
+// range of samples with a range vector selector; those rows are the samples
+// themselves.
+func Build(promql string, e Evaluation, opts ...Option) (*flux.Spec, error) {
+	if e.Bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	if e.End.Before(e.Start) {
+		return nil, fmt.Errorf("end must not be before start")
+	}
+	if !e.Instant() && e.Step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+
 	parsed, err := ParsePromQL(promql, opts...)
 	if err != nil {
 		return nil, err
 	}
 	builder, ok := parsed.(QueryBuilder)
 	if !ok {
-		return nil, fmt.Errorf("unable to build as %t is not a QueryBuilder", parsed)
+		return nil, fmt.Errorf("unable to build as %T is not a QueryBuilder", parsed)
+	}
+	return builder.QuerySpec(e)
+}
+
+// IsRangeVector returns whether promql is a range vector selector, whose
+// instant query result is a matrix rather than a vector.
+func IsRangeVector(promql string) bool {
+	parsed, err := ParsePromQL(promql)
+	if err != nil {
+		return false
+	}
+	s, ok := parsed.(*Selector)
+	return ok && s.Range > 0
+}
+
+// ScalarValue returns the value of promql if it is a scalar expression,
+// which is evaluated without reading any samples.
+func ScalarValue(promql string) (float64, bool) {
+	parsed, err := ParsePromQL(promql)
+	if err != nil {
+		return 0, false
+	}
+	q, ok := parsed.(QueryBuilder)
+	if !ok {
+		return 0, false
 	}
-	return builder.QuerySpec()
+	return Scalar(q)
 }
@@ -5,13 +5,6 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
-	"github.com/influxdata/flux"
-	"github.com/influxdata/flux/ast"
-	"github.com/influxdata/flux/semantic"
-	"github.com/influxdata/flux/semantic/semantictest"
-	"github.com/influxdata/flux/stdlib/universe"
-	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 )
 
 func TestParsePromQL(t *testing.T) {
@@ -181,7 +174,7 @@ func TestParsePromQL(t *testing.T) {
 				Op: &Operator{
 					Kind: MinKind,
 				},
-				Selector: &Selector{
+				Vector: &Selector{
 					Name: "some_metric",
 				},
 				Aggregate: &Aggregate{
@@ -201,7 +194,7 @@ func TestParsePromQL(t *testing.T) {
 				Op: &Operator{
 					Kind: CountKind,
 				},
-				Selector: &Selector{
+				Vector: &Selector{
 					Name: "some_metric",
 				},
 				Aggregate: &Aggregate{
@@ -221,7 +214,7 @@ func TestParsePromQL(t *testing.T) {
 				Op: &Operator{
 					Kind: AvgKind,
 				},
-				Selector: &Selector{
+				Vector: &Selector{
 					Name: "some_metric",
 				},
 				Aggregate: &Aggregate{
@@ -241,7 +234,7 @@ func TestParsePromQL(t *testing.T) {
 				Op: &Operator{
 					Kind: SumKind,
 				},
-				Selector: &Selector{
+				Vector: &Selector{
 					Name: "some_metric",
 				},
 				Aggregate: &Aggregate{
@@ -264,7 +257,7 @@ func TestParsePromQL(t *testing.T) {
 				Op: &Operator{
 					Kind: SumKind,
 				},
-				Selector: &Selector{
+				Vector: &Selector{
 					Name: "some_metric",
 				},
 				Aggregate: &Aggregate{
@@ -284,7 +277,7 @@ func TestParsePromQL(t *testing.T) {
 				Op: &Operator{
 					Kind: SumKind,
 				},
-				Selector: &Selector{
+				Vector: &Selector{
 					Name: "some_metric",
 				},
 				Aggregate: &Aggregate{
@@ -307,7 +300,7 @@ func TestParsePromQL(t *testing.T) {
 						String: "version",
 					},
 				},
-				Selector: &Selector{
+				Vector: &Selector{
 					Name: "build_version",
 				},
 			},
@@ -319,7 +312,7 @@ func TestParsePromQL(t *testing.T) {
 				Op: &Operator{
 					Kind: SumKind,
 				},
-				Selector: &Selector{
+				Vector: &Selector{
 					Name:  "node_cpu",
 					Range: 170 * time.Hour,
 					LabelMatchers: []*LabelMatcher{
@@ -334,262 +327,79 @@ func TestParsePromQL(t *testing.T) {
 				},
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := ParsePromQL(tt.promql, tt.opts...)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("ParsePromQL() %s error = %v, wantErr %v", tt.promql, err, tt.wantErr)
-				return
-			}
-			if !cmp.Equal(tt.want, got) {
-				t.Errorf("ParsePromQL() = %s -got/+want %s", tt.promql, cmp.Diff(tt.want, got))
-			}
-		})
-	}
-}
-
-func TestBuild(t *testing.T) {
-	tests := []struct {
-		name    string
-		promql  string
-		opts    []Option
-		want    *flux.Spec
-		wantErr bool
-	}{
 		{
-			name:   "aggregate with count without a group by",
-			promql: `count(node_cpu{mode="user",cpu="cpu2"})`,
-			want: &flux.Spec{
-				Operations: []*flux.Operation{
-					{
-						ID:   flux.OperationID("from"),
-						Spec: &influxdb.FromOpSpec{Bucket: "prometheus"},
-					},
-					{
-						ID: "where",
-						Spec: &universe.FilterOpSpec{
-							Fn: &semantic.FunctionExpression{
-								Block: &semantic.FunctionBlock{
-									Parameters: &semantic.FunctionParameters{
-										List: []*semantic.FunctionParameter{{Key: &semantic.Identifier{Name: "r"}}},
-									},
-									Body: &semantic.LogicalExpression{
-										Operator: ast.AndOperator,
-										Left: &semantic.LogicalExpression{
-											Operator: ast.AndOperator,
-											Left: &semantic.BinaryExpression{
-												Operator: ast.EqualOperator,
-												Left: &semantic.MemberExpression{
-													Object: &semantic.IdentifierExpression{
-														Name: "r",
-													},
-													Property: "_metric",
-												},
-												Right: &semantic.StringLiteral{
-													Value: "node_cpu",
-												},
-											},
-											Right: &semantic.BinaryExpression{
-												Operator: ast.EqualOperator,
-												Left: &semantic.MemberExpression{
-													Object: &semantic.IdentifierExpression{
-														Name: "r",
-													},
-													Property: "mode",
-												},
-												Right: &semantic.StringLiteral{
-													Value: "user",
-												},
-											},
-										},
-										Right: &semantic.BinaryExpression{
-											Operator: ast.EqualOperator,
-											Left: &semantic.MemberExpression{
-												Object: &semantic.IdentifierExpression{
-													Name: "r",
-												},
-												Property: "cpu",
-											},
-											Right: &semantic.StringLiteral{
-												Value: "cpu2",
-											},
-										},
-									},
-								},
-							},
-						},
-					},
-					{
-						ID: flux.OperationID("count"), Spec: &universe.CountOpSpec{},
-					},
-				},
-				Edges: []flux.Edge{
-					{
-						Parent: flux.OperationID("from"),
-						Child:  flux.OperationID("where"),
-					},
-					{
-						Parent: flux.OperationID("where"),
-						Child:  flux.OperationID("count"),
-					},
-				},
+			name:   "binary operators by precedence",
+			promql: `1 + up * 2 > bool 3`,
+			want: &BinaryExpr{
+				Op: GtrKind,
+				LHS: &BinaryExpr{
+					Op:  AddKind,
+					LHS: &Number{Val: 1},
+					RHS: &BinaryExpr{
+						Op:  MulKind,
+						LHS: &Selector{Name: "up"},
+						RHS: &Number{Val: 2},
+					},
+				},
+				RHS:        &Number{Val: 3},
+				ReturnBool: true,
 			},
 		},
 		{
-			name:   "range of time but no aggregates",
-			promql: `node_cpu{mode="user"}[2m] offset 5m`,
-			want: &flux.Spec{
-				Operations: []*flux.Operation{
-					{
-						ID:   flux.OperationID("from"),
-						Spec: &influxdb.FromOpSpec{Bucket: "prometheus"},
-					},
-					{
-						ID: flux.OperationID("range"),
-						Spec: &universe.RangeOpSpec{
-							Start: flux.Time{Relative: -time.Minute * 7},
-						},
-					},
-					{
-						ID: "where",
-						Spec: &universe.FilterOpSpec{
-							Fn: &semantic.FunctionExpression{
-								Block: &semantic.FunctionBlock{
-									Parameters: &semantic.FunctionParameters{
-										List: []*semantic.FunctionParameter{{Key: &semantic.Identifier{Name: "r"}}},
-									},
-									Body: &semantic.LogicalExpression{
-										Operator: ast.AndOperator,
-										Left: &semantic.BinaryExpression{
-											Operator: ast.EqualOperator,
-											Left: &semantic.MemberExpression{
-												Object: &semantic.IdentifierExpression{
-													Name: "r",
-												},
-												Property: "_metric",
-											},
-											Right: &semantic.StringLiteral{
-												Value: "node_cpu",
-											},
-										},
-										Right: &semantic.BinaryExpression{
-											Operator: ast.EqualOperator,
-											Left: &semantic.MemberExpression{
-												Object: &semantic.IdentifierExpression{
-													Name: "r",
-												},
-												Property: "mode",
-											},
-											Right: &semantic.StringLiteral{
-												Value: "user",
-											},
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-				Edges: []flux.Edge{
-					{
-						Parent: flux.OperationID("from"),
-						Child:  flux.OperationID("range"),
-					},
-					{
-						Parent: flux.OperationID("range"),
-						Child:  flux.OperationID("where"),
-					},
+			name:   "left associative operators",
+			promql: `(up - 1) - 2 / 4`,
+			want: &BinaryExpr{
+				Op: SubKind,
+				LHS: &BinaryExpr{
+					Op:  SubKind,
+					LHS: &Selector{Name: "up"},
+					RHS: &Number{Val: 1},
+				},
+				RHS: &BinaryExpr{
+					Op:  DivKind,
+					LHS: &Number{Val: 2},
+					RHS: &Number{Val: 4},
 				},
 			},
 		},
-
 		{
-			name:   "sum over a range",
-			promql: `sum(node_cpu{_measurement="m0"}[170h])`,
-			want: &flux.Spec{
-				Operations: []*flux.Operation{
-					{
-						ID:   flux.OperationID("from"),
-						Spec: &influxdb.FromOpSpec{Bucket: "prometheus"},
-					},
-					{
-						ID: flux.OperationID("range"),
-						Spec: &universe.RangeOpSpec{
-							Start: flux.Time{Relative: -170 * time.Hour},
-						},
-					},
-					{
-						ID: "where",
-						Spec: &universe.FilterOpSpec{
-							Fn: &semantic.FunctionExpression{
-								Block: &semantic.FunctionBlock{
-									Parameters: &semantic.FunctionParameters{
-										List: []*semantic.FunctionParameter{{Key: &semantic.Identifier{Name: "r"}}},
-									},
-									Body: &semantic.LogicalExpression{
-										Operator: ast.AndOperator,
-										Left: &semantic.BinaryExpression{
-											Operator: ast.EqualOperator,
-											Left: &semantic.MemberExpression{
-												Object: &semantic.IdentifierExpression{
-													Name: "r",
-												},
-												Property: "_metric",
-											},
-											Right: &semantic.StringLiteral{
-												Value: "node_cpu",
-											},
-										},
-										Right: &semantic.BinaryExpression{
-											Operator: ast.EqualOperator,
-											Left: &semantic.MemberExpression{
-												Object: &semantic.IdentifierExpression{
-													Name: "r",
-												},
-												Property: "_measurement",
-											},
-											Right: &semantic.StringLiteral{
-												Value: "m0",
-											},
-										},
-									},
-								},
-							},
-						},
-					},
-					{
-						ID: flux.OperationID("sum"), Spec: &universe.SumOpSpec{},
-					},
+			name:   "aggregate of a function",
+			promql: `sum without (code) (rate(requests[5m]))`,
+			want: &AggregateExpr{
+				Op: &Operator{
+					Kind: SumKind,
 				},
-				Edges: []flux.Edge{
-					{
-						Parent: flux.OperationID("from"),
-						Child:  flux.OperationID("range"),
-					},
-					{
-						Parent: flux.OperationID("range"),
-						Child:  flux.OperationID("where"),
-					},
-					{
-						Parent: flux.OperationID("where"),
-						Child:  flux.OperationID("sum"),
+				Vector: &Call{
+					Func: RateKind,
+					Arg: &Selector{
+						Name:  "requests",
+						Range: 5 * time.Minute,
 					},
 				},
+				Aggregate: &Aggregate{
+					Without: true,
+					Labels:  []*Identifier{{Name: "code"}},
+				},
 			},
 		},
+		{
+			name:    "unknown function",
+			promql:  `delta(requests[5m])`,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := Build(tt.promql, tt.opts...)
-
+			got, err := ParsePromQL(tt.promql, tt.opts...)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Build() %s error = %v, wantErr %v", tt.promql, err, tt.wantErr)
+				t.Errorf("ParsePromQL() %s error = %v, wantErr %v", tt.promql, err, tt.wantErr)
 				return
 			}
-			opts := append(semantictest.CmpOptions, []cmp.Option{cmp.AllowUnexported(flux.Spec{}), cmpopts.IgnoreUnexported(flux.Spec{})}...)
-			if !cmp.Equal(tt.want, got, opts...) {
-				t.Errorf("Build() = %s -want/+got\n%s", tt.promql, cmp.Diff(tt.want, got, opts...))
+			if tt.wantErr {
+				return
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("ParsePromQL() = %s -got/+want %s", tt.promql, cmp.Diff(tt.want, got))
 			}
 		})
 	}
@@ -0,0 +1,291 @@
+package promql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/influxdb/prometheus/remote"
+)
+
+// ResultType is the type of the data of a query response.
+type ResultType string
+
+// The result types of query responses.
+const (
+	ScalarResult ResultType = "scalar"
+	VectorResult ResultType = "vector"
+	MatrixResult ResultType = "matrix"
+)
+
+// ErrorType categorizes the error of a query response.
+type ErrorType string
+
+// The error types of query responses.
+const (
+	ErrorBadData   ErrorType = "bad_data"
+	ErrorExecution ErrorType = "execution"
+	ErrorInternal  ErrorType = "internal"
+)
+
+// Response is a response of the Prometheus HTTP API.
+type Response struct {
+	Status    string    `json:"status"`
+	Data      *Data     `json:"data,omitempty"`
+	ErrorType ErrorType `json:"errorType,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NewErrorResponse returns the response for a failed request.
+func NewErrorResponse(typ ErrorType, msg string) *Response {
+	return &Response{
+		Status:    "error",
+		ErrorType: typ,
+		Error:     msg,
+	}
+}
+
+// Data is the result of a query. The result of a scalar query is the
+// single sample Scalar rather than series.
+type Data struct {
+	ResultType ResultType `json:"resultType"`
+	Result     []*Series  `json:"result"`
+	Scalar     *Sample    `json:"-"`
+}
+
+// scalarData is the encoding of the Data of a scalar query.
+type scalarData struct {
+	ResultType ResultType `json:"resultType"`
+	Result     *Sample    `json:"result"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d *Data) MarshalJSON() ([]byte, error) {
+	if d.ResultType == ScalarResult {
+		return json.Marshal(&scalarData{ResultType: d.ResultType, Result: d.Scalar})
+	}
+	type data Data
+	return json.Marshal((*data)(d))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Data) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		ResultType ResultType      `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	d.ResultType = raw.ResultType
+	if d.ResultType == ScalarResult {
+		d.Scalar = new(Sample)
+		return json.Unmarshal(raw.Result, d.Scalar)
+	}
+	return json.Unmarshal(raw.Result, &d.Result)
+}
+
+// Series is a series of an instant vector, with a single value, or of a
+// matrix, with the values in a range of time.
+type Series struct {
+	Metric map[string]string `json:"metric"`
+	Value  *Sample           `json:"value,omitempty"`
+	Values []Sample          `json:"values,omitempty"`
+}
+
+// Sample is the value of a series at a time. It is encoded as an array of
+// the time in seconds and the value as a string.
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s Sample) MarshalJSON() ([]byte, error) {
+	// Prometheus timestamps have millisecond precision.
+	ms := s.Time.UnixNano() / int64(time.Millisecond)
+	t := strconv.FormatFloat(float64(ms)/1e3, 'f', -1, 64)
+	return []byte(`[` + t + `,"` + formatValue(s.Value) + `"]`), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Sample) UnmarshalJSON(b []byte) error {
+	var pair [2]interface{}
+	if err := json.Unmarshal(b, &pair); err != nil {
+		return err
+	}
+	t, ok := pair[0].(float64)
+	if !ok {
+		return fmt.Errorf("invalid sample time %v", pair[0])
+	}
+	v, ok := pair[1].(string)
+	if !ok {
+		return fmt.Errorf("invalid sample value %v", pair[1])
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("invalid sample value %q", v)
+	}
+	ms := int64(math.Round(t * 1e3))
+	s.Time = time.Unix(0, ms*int64(time.Millisecond)).UTC()
+	s.Value = f
+	return nil
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+}
+
+// MultiResultEncoder encodes results as a Prometheus HTTP API response.
+type MultiResultEncoder struct {
+	ResultType ResultType
+}
+
+// Encode writes the series of the results to w. If the results fail, the
+// error is written as an error response and also returned.
+func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	wc := &iocounter.Writer{Writer: w}
+
+	data := &Data{ResultType: e.ResultType, Result: []*Series{}}
+	err := e.encodeResults(data, results)
+	resp := &Response{Status: "success", Data: data}
+	if err != nil {
+		resp = NewErrorResponse(ErrorExecution, err.Error())
+	}
+
+	if encErr := json.NewEncoder(wc).Encode(resp); encErr != nil && err == nil {
+		err = encErr
+	}
+	return wc.Count(), err
+}
+
+func (e *MultiResultEncoder) encodeResults(data *Data, results flux.ResultIterator) error {
+	for results.More() {
+		res := results.Next()
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			return e.encodeTable(data, tbl)
+		}); err != nil {
+			results.Release()
+			return err
+		}
+	}
+	if err := results.Err(); err != nil {
+		return err
+	}
+	sortSeries(data.Result)
+	return nil
+}
+
+func (e *MultiResultEncoder) encodeTable(data *Data, tbl flux.Table) error {
+	metric := tableMetric(tbl.Key())
+	timeIdx := execute.ColIdx(execute.DefaultTimeColLabel, tbl.Cols())
+	valueIdx := execute.ColIdx(execute.DefaultValueColLabel, tbl.Cols())
+	if timeIdx < 0 || valueIdx < 0 {
+		return fmt.Errorf("table is missing the %s or %s column", execute.DefaultTimeColLabel, execute.DefaultValueColLabel)
+	}
+
+	var samples []Sample
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		times := cr.Times(timeIdx)
+		for i := 0; i < cr.Len(); i++ {
+			if !times.IsValid(i) {
+				continue
+			}
+			v, ok := floatValue(cr, valueIdx, i)
+			if !ok {
+				continue
+			}
+			samples = append(samples, Sample{
+				Time:  execute.Time(times.Value(i)).Time(),
+				Value: v,
+			})
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if e.ResultType == MatrixResult {
+		if len(samples) > 0 {
+			data.Result = append(data.Result, &Series{Metric: metric, Values: samples})
+		}
+		return nil
+	}
+	for i := range samples {
+		data.Result = append(data.Result, &Series{Metric: metric, Value: &samples[i]})
+	}
+	return nil
+}
+
+// floatValue returns the value in row i of column j as a float, and whether
+// there is one.
+func floatValue(cr flux.ColReader, j, i int) (float64, bool) {
+	switch cr.Cols()[j].Type {
+	case flux.TFloat:
+		vs := cr.Floats(j)
+		return vs.Value(i), vs.IsValid(i)
+	case flux.TInt:
+		vs := cr.Ints(j)
+		return float64(vs.Value(i)), vs.IsValid(i)
+	case flux.TUInt:
+		vs := cr.UInts(j)
+		return float64(vs.Value(i)), vs.IsValid(i)
+	default:
+		return 0, false
+	}
+}
+
+// tableMetric returns the labels of the series in a table, which are the
+// string columns of its group key other than the field. As in Prometheus,
+// empty labels are omitted.
+func tableMetric(key flux.GroupKey) map[string]string {
+	metric := make(map[string]string)
+	for j, c := range key.Cols() {
+		if c.Type != flux.TString || c.Label == "_field" || key.IsNull(j) || key.ValueString(j) == "" {
+			continue
+		}
+		label := c.Label
+		if label == "_measurement" {
+			label = remote.MetricNameLabel
+		}
+		metric[label] = key.ValueString(j)
+	}
+	return metric
+}
+
+// sortSeries sorts series by their labels, so that responses are stable.
+func sortSeries(series []*Series) {
+	sort.SliceStable(series, func(i, j int) bool {
+		return seriesKey(series[i]) < seriesKey(series[j])
+	})
+}
+
+func seriesKey(s *Series) string {
+	names := make([]string, 0, len(s.Metric))
+	for k := range s.Metric {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var key string
+	for _, k := range names {
+		key += k + "\x00" + s.Metric[k] + "\x00"
+	}
+	return key
+}
@@ -2,15 +2,12 @@ package promql
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/influxdata/flux"
-	"github.com/influxdata/flux/ast"
-	"github.com/influxdata/flux/semantic"
-	"github.com/influxdata/flux/stdlib/universe"
-	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 )
 
 type ArgKind int
@@ -24,8 +21,10 @@ const (
 	SelectorKind
 )
 
+// QueryBuilder is an expression that builds a flux query specification
+// evaluating it.
 type QueryBuilder interface {
-	QuerySpec() (*flux.Spec, error)
+	QuerySpec(e Evaluation) (*flux.Spec, error)
 }
 
 type Arg interface {
@@ -91,6 +90,10 @@ func (n *Number) Value() interface{} {
 	return n.Val
 }
 
+func (n *Number) QuerySpec(e Evaluation) (*flux.Spec, error) {
+	return nil, fmt.Errorf("expected an instant vector, got a scalar")
+}
+
 func NewNumber(val string) (*Number, error) {
 	num, err := strconv.ParseFloat(val, 64)
 	if err != nil {
@@ -141,131 +144,28 @@ type Selector struct {
 	LabelMatchers []*LabelMatcher `json:"label_matchers,omitempty"`
 }
 
-func (s *Selector) QuerySpec() (*flux.Spec, error) {
-	parent := "from"
-	ops := []*flux.Operation{
-		{
-			ID: "from", // TODO: Change this to a UUID
-			Spec: &influxdb.FromOpSpec{
-				Bucket: "prometheus",
-			},
-		},
+// QuerySpec builds the specification selecting the series of s. A range
+// vector selector selects the samples in its range before the evaluation
+// time of an instant query.
+func (s *Selector) QuerySpec(e Evaluation) (*flux.Spec, error) {
+	if s.Range <= 0 {
+		return buildVector(s, e)
 	}
-	edges := []flux.Edge{}
-
-	rng, err := NewRangeOp(s.Range, s.Offset)
-	if err != nil {
-		return nil, err
+	if !e.Instant() {
+		return nil, fmt.Errorf("range vector selector %s[%s] is only allowed in instant queries", s.Name, s.Range)
 	}
-
-	if rng != nil {
-		ops = append(ops, rng)
-		edge := flux.Edge{
-			Parent: flux.OperationID(parent),
-			Child:  "range",
-		}
-		parent = "range"
-		edges = append(edges, edge)
-	}
-
-	where, err := NewWhereOperation(s.Name, s.LabelMatchers)
-	if err != nil {
+	p := newPipeline(e)
+	if err := p.selectRange(s, e); err != nil {
 		return nil, err
 	}
-
-	ops = append(ops, where)
-	edge := flux.Edge{
-		Parent: flux.OperationID(parent),
-		Child:  "where",
-	}
-	parent = "where"
-	edges = append(edges, edge)
-
-	return &flux.Spec{
-		Operations: ops,
-		Edges:      edges,
-	}, nil
+	return p.spec, nil
 }
 
-func NewRangeOp(rng, offset time.Duration) (*flux.Operation, error) {
-	if rng == 0 && offset == 0 {
-		return nil, nil
+func (s *Selector) buildVector(p *pipeline, e Evaluation) error {
+	if s.Range > 0 {
+		return fmt.Errorf("expected an instant vector, got the range vector %s[%s]", s.Name, s.Range)
 	}
-	return &flux.Operation{
-		ID: "range", // TODO: Change this to a UUID
-		Spec: &universe.RangeOpSpec{
-			Start: flux.Time{
-				Relative: -rng - offset,
-			},
-		},
-	}, nil
-}
-
-var operatorLookup = map[MatchKind]ast.OperatorKind{
-	Equal:        ast.EqualOperator,
-	NotEqual:     ast.NotEqualOperator,
-	RegexMatch:   ast.EqualOperator,
-	RegexNoMatch: ast.NotEqualOperator,
-}
-
-func NewWhereOperation(metricName string, labels []*LabelMatcher) (*flux.Operation, error) {
-	var node semantic.Expression = &semantic.BinaryExpression{
-		Operator: ast.EqualOperator,
-		Left: &semantic.MemberExpression{
-			Object: &semantic.IdentifierExpression{
-				Name: "r",
-			},
-			Property: "_metric",
-		},
-		Right: &semantic.StringLiteral{
-			Value: metricName,
-		},
-	}
-	for _, label := range labels {
-		op, ok := operatorLookup[label.Kind]
-		if !ok {
-			return nil, fmt.Errorf("unknown label match kind %d", label.Kind)
-		}
-		ref := &semantic.MemberExpression{
-			Object: &semantic.IdentifierExpression{
-				Name: "r",
-			},
-			Property: label.Name,
-		}
-		var value semantic.Expression
-		if label.Value.Type() == StringKind {
-			value = &semantic.StringLiteral{
-				Value: label.Value.Value().(string),
-			}
-		} else if label.Value.Type() == NumberKind {
-			value = &semantic.FloatLiteral{
-				Value: label.Value.Value().(float64),
-			}
-		}
-		node = &semantic.LogicalExpression{
-			Operator: ast.AndOperator,
-			Left:     node,
-			Right: &semantic.BinaryExpression{
-				Operator: op,
-				Left:     ref,
-				Right:    value,
-			},
-		}
-	}
-
-	return &flux.Operation{
-		ID: "where", // TODO: Change this to a UUID
-		Spec: &universe.FilterOpSpec{
-			Fn: &semantic.FunctionExpression{
-				Block: &semantic.FunctionBlock{
-					Parameters: &semantic.FunctionParameters{
-						List: []*semantic.FunctionParameter{{Key: &semantic.Identifier{Name: "r"}}},
-					},
-					Body: node,
-				},
-			},
-		},
-	}, nil
+	return p.selectInstant(s, e)
 }
 
 func (s *Selector) Type() ArgKind {
@@ -302,23 +202,6 @@ type Aggregate struct {
 	Labels  []*Identifier `json:"labels,omitempty"`
 }
 
-func (a *Aggregate) QuerySpec() (*flux.Operation, error) {
-	if a.Without {
-		return nil, fmt.Errorf("unable to merge using `without`")
-	}
-	keys := make([]string, len(a.Labels))
-	for i := range a.Labels {
-		keys[i] = a.Labels[i].Name
-	}
-	return &flux.Operation{
-		ID: "merge",
-		Spec: &universe.GroupOpSpec{
-			Columns: keys,
-			Mode:    "by",
-		},
-	}, nil
-}
-
 type OperatorKind int
 
 const (
@@ -371,116 +254,301 @@ type Operator struct {
 	Arg  Arg          `json:"arg,omitempty"`
 }
 
-func (o *Operator) QuerySpec() (*flux.Operation, error) {
-	switch o.Kind {
-	case CountValuesKind, BottomKind, QuantileKind, StdVarKind:
-		return nil, fmt.Errorf("unable to run %d yet", o.Kind)
-	case CountKind:
-		return &flux.Operation{
-			ID:   "count",
-			Spec: &universe.CountOpSpec{},
-		}, nil
-	//case TopKind:
-	//	return &flux.Operation{
-	//		ID:   "top",
-	//		Spec: &universe.TopOpSpec{}, // TODO: Top doesn't have arg yet
-	//	}, nil
-	case SumKind:
-		return &flux.Operation{
-			ID:   "sum",
-			Spec: &universe.SumOpSpec{},
-		}, nil
-	//case MinKind:
-	//	return &flux.Operation{
-	//		ID:   "min",
-	//		Spec: &universe.MinOpSpec{},
-	//	}, nil
-	//case MaxKind:
-	//	return &flux.Operation{
-	//		ID:   "max",
-	//		Spec: &universe.MaxOpSpec{},
-	//	}, nil
-	//case AvgKind:
-	//	return &flux.Operation{
-	//		ID:   "mean",
-	//		Spec: &universe.MeanOpSpec{},
-	//	}, nil
-	//case StdevKind:
-	//	return &flux.Operation{
-	//		ID:   "stddev",
-	//		Spec: &universe.StddevOpSpec{},
-	//	}, nil
-	default:
-		return nil, fmt.Errorf("unknown Op kind %d", o.Kind)
-	}
+type AggregateExpr struct {
+	Op        *Operator    `json:"op,omitempty"`
+	Vector    QueryBuilder `json:"vector,omitempty"`
+	Aggregate *Aggregate   `json:"aggregate,omitempty"`
 }
 
-type AggregateExpr struct {
-	Op        *Operator  `json:"op,omitempty"`
-	Selector  *Selector  `json:"selector,omitempty"`
-	Aggregate *Aggregate `json:"aggregate,omitempty"`
+func (a *AggregateExpr) QuerySpec(e Evaluation) (*flux.Spec, error) {
+	return buildVector(a, e)
 }
 
-func (a *AggregateExpr) QuerySpec() (*flux.Spec, error) {
-	spec, err := a.Selector.QuerySpec()
-	if err != nil {
-		return nil, err
+func (a *AggregateExpr) buildVector(p *pipeline, e Evaluation) error {
+	if err := buildOperand(p, e, operatorNames[a.Op.Kind], a.Vector); err != nil {
+		return err
 	}
+	return p.aggregate(a)
+}
 
-	if a.Aggregate != nil {
-		agg, err := a.Aggregate.QuerySpec()
-		if err != nil {
-			return nil, err
-		}
+func NewAggregateExpr(op *Operator, vector QueryBuilder, group interface{}) (*AggregateExpr, error) {
+	expr := &AggregateExpr{
+		Op:     op,
+		Vector: vector,
+	}
+	if group != nil {
+		expr.Aggregate = group.(*Aggregate)
+	}
+	return expr, nil
+}
+
+// FunctionKind is an enum for the functions over range vectors.
+type FunctionKind int
+
+// Possible FunctionKinds.
+const (
+	UnknownFunctionKind FunctionKind = iota
+	RateKind
+	IrateKind
+	IncreaseKind
+	AvgOverTimeKind
+	MinOverTimeKind
+	MaxOverTimeKind
+	SumOverTimeKind
+	CountOverTimeKind
+)
 
-		parent := flux.OperationID("from")
-		if len(spec.Edges) > 0 {
-			tail := spec.Edges[len(spec.Edges)-1]
-			parent = tail.Child
+var functionNames = map[FunctionKind]string{
+	RateKind:          "rate",
+	IrateKind:         "irate",
+	IncreaseKind:      "increase",
+	AvgOverTimeKind:   "avg_over_time",
+	MinOverTimeKind:   "min_over_time",
+	MaxOverTimeKind:   "max_over_time",
+	SumOverTimeKind:   "sum_over_time",
+	CountOverTimeKind: "count_over_time",
+}
+
+func ToFunctionKind(fn string) FunctionKind {
+	fn = strings.ToLower(fn)
+	for kind, name := range functionNames {
+		if name == fn {
+			return kind
 		}
+	}
+	return UnknownFunctionKind
+}
 
-		spec.Operations = append(spec.Operations, agg)
-		spec.Edges = append(spec.Edges, flux.Edge{
-			Parent: parent,
-			Child:  agg.ID,
-		})
+// Call is a call of a function over a range vector, such as rate.
+type Call struct {
+	Func FunctionKind `json:"func,omitempty"`
+	Arg  QueryBuilder `json:"arg,omitempty"`
+}
+
+func NewCall(fn FunctionKind, arg QueryBuilder) (*Call, error) {
+	return &Call{
+		Func: fn,
+		Arg:  arg,
+	}, nil
+}
+
+func (c *Call) QuerySpec(e Evaluation) (*flux.Spec, error) {
+	return buildVector(c, e)
+}
+
+func (c *Call) buildVector(p *pipeline, e Evaluation) error {
+	s, ok := c.Arg.(*Selector)
+	if !ok || s.Range <= 0 {
+		return fmt.Errorf("%s expects a range vector selector", functionNames[c.Func])
 	}
+	return p.call(c.Func, s, e)
+}
 
-	op, err := a.Op.QuerySpec()
-	if err != nil {
-		return nil, err
+// BinaryOpKind is an enum for the binary operators.
+type BinaryOpKind int
+
+// Possible BinaryOpKinds.
+const (
+	UnknownBinaryOpKind BinaryOpKind = iota
+	AddKind
+	SubKind
+	MulKind
+	DivKind
+	ModKind
+	PowKind
+	EqlKind
+	NeqKind
+	LssKind
+	LteKind
+	GtrKind
+	GteKind
+)
+
+var binaryOpNames = map[BinaryOpKind]string{
+	AddKind: "+",
+	SubKind: "-",
+	MulKind: "*",
+	DivKind: "/",
+	ModKind: "%",
+	PowKind: "^",
+	EqlKind: "==",
+	NeqKind: "!=",
+	LssKind: "<",
+	LteKind: "<=",
+	GtrKind: ">",
+	GteKind: ">=",
+}
+
+// comparison returns whether k is a comparison operator.
+func (k BinaryOpKind) comparison() bool {
+	return k >= EqlKind
+}
+
+// BinaryExpr is a binary operation between two expressions.
+type BinaryExpr struct {
+	Op         BinaryOpKind `json:"op,omitempty"`
+	LHS        QueryBuilder `json:"lhs,omitempty"`
+	RHS        QueryBuilder `json:"rhs,omitempty"`
+	ReturnBool bool         `json:"return_bool,omitempty"`
+}
+
+// BinaryOperand is an operator and its right hand side, which the parser
+// folds with the left hand side into a BinaryExpr.
+type BinaryOperand struct {
+	Op         BinaryOpKind
+	RHS        QueryBuilder
+	ReturnBool bool
+}
+
+func NewBinaryOperand(op BinaryOpKind, returnBool bool, rhs QueryBuilder) (*BinaryOperand, error) {
+	return &BinaryOperand{
+		Op:         op,
+		RHS:        rhs,
+		ReturnBool: returnBool,
+	}, nil
+}
+
+// NewBinaryExpr folds the operands in rest into left associative binary
+// expressions starting with first.
+func NewBinaryExpr(first QueryBuilder, rest interface{}) (QueryBuilder, error) {
+	expr := first
+	for _, r := range toIfaceSlice(rest) {
+		if o, ok := r.(*BinaryOperand); ok {
+			expr = &BinaryExpr{
+				Op:         o.Op,
+				LHS:        expr,
+				RHS:        o.RHS,
+				ReturnBool: o.ReturnBool,
+			}
+		}
 	}
+	return expr, nil
+}
 
-	parent := flux.OperationID("from")
-	if len(spec.Edges) > 0 {
-		tail := spec.Edges[len(spec.Edges)-1]
-		parent = tail.Child
+func (b *BinaryExpr) QuerySpec(e Evaluation) (*flux.Spec, error) {
+	return buildVector(b, e)
+}
+
+func (b *BinaryExpr) buildVector(p *pipeline, e Evaluation) error {
+	op := binaryOpNames[b.Op]
+	lhs, lok := Scalar(b.LHS)
+	rhs, rok := Scalar(b.RHS)
+	switch {
+	case lok && rok:
+		if b.Op.comparison() && !b.ReturnBool {
+			return fmt.Errorf("comparisons between scalars must use bool")
+		}
+		return fmt.Errorf("expected an instant vector, got a scalar")
+	case !lok && !rok:
+		return fmt.Errorf("binary operator %s between two vectors is not supported", op)
+	case b.Op == ModKind || b.Op == PowKind:
+		return fmt.Errorf("binary operator %s is not supported", op)
+	}
+
+	if rok {
+		if err := buildOperand(p, e, op, b.LHS); err != nil {
+			return err
+		}
+		p.binary(b.Op, nil, &rhs, b.ReturnBool)
+		return nil
+	}
+	if err := buildOperand(p, e, op, b.RHS); err != nil {
+		return err
+	}
+	p.binary(b.Op, &lhs, nil, b.ReturnBool)
+	return nil
+}
+
+// Scalar returns the value of q if it is a scalar expression: a number or
+// a binary operation between scalars.
+func Scalar(q QueryBuilder) (float64, bool) {
+	switch q := q.(type) {
+	case *Number:
+		return q.Val, true
+	case *BinaryExpr:
+		lhs, ok := Scalar(q.LHS)
+		if !ok {
+			return 0, false
+		}
+		rhs, ok := Scalar(q.RHS)
+		if !ok {
+			return 0, false
+		}
+		var cmp bool
+		switch q.Op {
+		case AddKind:
+			return lhs + rhs, true
+		case SubKind:
+			return lhs - rhs, true
+		case MulKind:
+			return lhs * rhs, true
+		case DivKind:
+			return lhs / rhs, true
+		case ModKind:
+			return math.Mod(lhs, rhs), true
+		case PowKind:
+			return math.Pow(lhs, rhs), true
+		case EqlKind:
+			cmp = lhs == rhs
+		case NeqKind:
+			cmp = lhs != rhs
+		case LssKind:
+			cmp = lhs < rhs
+		case LteKind:
+			cmp = lhs <= rhs
+		case GtrKind:
+			cmp = lhs > rhs
+		case GteKind:
+			cmp = lhs >= rhs
+		default:
+			return 0, false
+		}
+		// Comparisons between scalars must return a value.
+		if !q.ReturnBool {
+			return 0, false
+		}
+		if cmp {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
 	}
-	spec.Operations = append(spec.Operations, op)
-	spec.Edges = append(spec.Edges, flux.Edge{
-		Parent: parent,
-		Child:  op.ID,
-	})
-	return spec, nil
 }
 
-func NewAggregateExpr(op *Operator, selector *Selector, group interface{}) (*AggregateExpr, error) {
-	expr := &AggregateExpr{
-		Op:       op,
-		Selector: selector,
+// vectorBuilder is an expression that evaluates to an instant vector. It
+// adds the operations evaluating it to a pipeline, which result in a table
+// for each series with a row for each evaluation time it has a value at.
+type vectorBuilder interface {
+	buildVector(p *pipeline, e Evaluation) error
+}
+
+// buildVector builds the specification evaluating v at each evaluation
+// time of e.
+func buildVector(v vectorBuilder, e Evaluation) (*flux.Spec, error) {
+	p := newPipeline(e)
+	if err := v.buildVector(p, e); err != nil {
+		return nil, err
 	}
-	if group != nil {
-		expr.Aggregate = group.(*Aggregate)
+	return p.spec, nil
+}
+
+// buildOperand adds the operations evaluating the operand of the operator
+// or function named name, which must be an instant vector.
+func buildOperand(p *pipeline, e Evaluation, name string, operand QueryBuilder) error {
+	v, ok := operand.(vectorBuilder)
+	if !ok {
+		return fmt.Errorf("%s expects an instant vector", name)
 	}
-	return expr, nil
+	return v.buildVector(p, e)
 }
 
 type Comment struct {
 	Source string `json:"source,omitempty"`
 }
 
-func (c *Comment) QuerySpec() (*flux.Spec, error) {
-	return nil, fmt.Errorf("unable to represent comments in the AST")
+func (c *Comment) QuerySpec(e Evaluation) (*flux.Spec, error) {
+	return nil, fmt.Errorf("query contains only a comment")
 }
 
 func toIfaceSlice(v interface{}) []interface{} {
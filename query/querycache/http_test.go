@@ -0,0 +1,111 @@
+package querycache_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/query/querycache"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap"
+)
+
+type bucketLookup map[string]influxdb.ID
+
+func (l bucketLookup) Lookup(ctx context.Context, orgID influxdb.ID, name string) (influxdb.ID, bool) {
+	id, ok := l[name]
+	return id, ok
+}
+
+type bucketVersions map[influxdb.ID]uint64
+
+func (v bucketVersions) BucketVersion(bucketID influxdb.ID) uint64 {
+	return v[bucketID]
+}
+
+// TestProxyQueryService_FluxHandler caches queries of dashboards, which are
+// sent to /api/v2/query with the variables of the dashboard as an extern.
+func TestProxyQueryService_FluxHandler(t *testing.T) {
+	orgID := influxdbtesting.MustIDBase16("020f755c3c082000")
+	bucketID := influxdbtesting.MustIDBase16("020f755c3c082001")
+	versions := bucketVersions{}
+
+	n := 0
+	s := querycache.New(&querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			n++
+			_, err := io.WriteString(w, "#datatype,string,long\n")
+			return flux.Statistics{}, err
+		},
+	}, querycache.Config{
+		MaxSize:      1024,
+		MaxEntrySize: 1024,
+		Granularity:  time.Hour,
+	}, bucketLookup{"telegraf": bucketID}, versions)
+
+	h := http.NewFluxHandler(&http.FluxBackend{
+		Logger: zap.NewNop(),
+		OrganizationService: &mock.OrganizationService{
+			FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return &influxdb.Organization{ID: *filter.ID, Name: "org"}, nil
+			},
+		},
+		ProxyQueryService: s,
+	})
+
+	doQuery := func(extern string) string {
+		t.Helper()
+		body, err := json.Marshal(map[string]interface{}{
+			"query":  `from(bucket: "telegraf") |> range(start: v.timeRangeStart) |> filter(fn: (r) => r._measurement == "cpu")`,
+			"extern": parser.ParseSource(extern).Files[0],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := httptest.NewRequest("POST", "http://any.url/api/v2/query?orgID="+orgID.String(), strings.NewReader(string(body)))
+		r.Header.Set("Content-Type", "application/json")
+		r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &influxdb.Authorization{
+			Status: influxdb.Active,
+			OrgID:  orgID,
+		}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != nethttp.StatusOK {
+			t.Fatalf("unexpected status code %d: %s", w.Code, w.Body.String())
+		}
+		return w.Body.String()
+	}
+
+	first := doQuery(`option v = {timeRangeStart: -1h}`)
+	if got := doQuery(`option v = {timeRangeStart: -1h}`); got != first {
+		t.Fatalf("unexpected cached response %q, want %q", got, first)
+	}
+	if n != 1 {
+		t.Fatalf("expected dashboard query to hit the cache, but performed %d queries", n)
+	}
+
+	doQuery(`option v = {timeRangeStart: -5m}`)
+	if n != 2 {
+		t.Fatalf("expected query with another extern to miss the cache, but performed %d queries", n)
+	}
+
+	versions[bucketID]++
+	doQuery(`option v = {timeRangeStart: -1h}`)
+	if n != 3 {
+		t.Fatalf("expected write to the bucket to invalidate the response, but performed %d queries", n)
+	}
+}
@@ -0,0 +1,221 @@
+package querycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// BucketLookup resolves the names of buckets of an organization.
+// It is implemented by query.BucketLookup.
+type BucketLookup interface {
+	Lookup(ctx context.Context, orgID influxdb.ID, name string) (influxdb.ID, bool)
+}
+
+// cacheKey identifies the response of a query, and has the buckets it reads.
+type cacheKey struct {
+	key     string
+	buckets []influxdb.ID
+}
+
+// newCacheKey returns the key of the response of req, or false if it may
+// not be cached. Only Flux queries that read buckets named by literals, and
+// that import no packages and write nothing, are cached.
+//
+// The key is a hash of the organization, the formatted AST of the query, the
+// buckets it reads, the permissions of its authorization and its dialect. If
+// the query is relative to now, it also has the window of width granularity
+// that now is in, so that the query shares its response within the window;
+// without a granularity, such queries are not cached.
+func newCacheKey(ctx context.Context, req *query.ProxyRequest, bl BucketLookup, now time.Time, granularity time.Duration) (*cacheKey, bool) {
+	var pkg *ast.Package
+	switch c := req.Request.Compiler.(type) {
+	case lang.ASTCompiler:
+		// The AST is shared with the compiler, and must not be checked
+		// again, since checking annotates it with errors.
+		pkg = c.AST
+		if !c.Now.IsZero() {
+			now = c.Now
+		}
+	case lang.FluxCompiler:
+		pkg = parser.ParseSource(c.Query)
+		if ast.Check(pkg) > 0 {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	// The AST is formatted before it is analyzed, since formatting fails on
+	// ASTs that are missing nodes.
+	text := query.FormatAST(pkg)
+	if text == "" {
+		return nil, false
+	}
+	a := analyze(pkg)
+	if !a.cacheable || (a.relative && granularity <= 0) {
+		return nil, false
+	}
+
+	orgID := req.Request.OrganizationID
+	k := &cacheKey{}
+	seen := make(map[influxdb.ID]bool)
+	for _, name := range a.bucketNames {
+		id, ok := bl.Lookup(ctx, orgID, name)
+		if !ok {
+			return nil, false
+		}
+		if !seen[id] {
+			seen[id] = true
+			k.buckets = append(k.buckets, id)
+		}
+	}
+	for _, s := range a.bucketIDs {
+		id, err := influxdb.IDFromString(s)
+		if err != nil {
+			return nil, false
+		}
+		if !seen[*id] {
+			seen[*id] = true
+			k.buckets = append(k.buckets, *id)
+		}
+	}
+	sort.Slice(k.buckets, func(i, j int) bool { return k.buckets[i] < k.buckets[j] })
+
+	var permissions []influxdb.Permission
+	if req.Request.Authorization != nil {
+		permissions = req.Request.Authorization.Permissions
+	}
+	octets, err := json.Marshal(struct {
+		OrgID       influxdb.ID           `json:"orgID"`
+		Query       string                `json:"query"`
+		Buckets     []influxdb.ID         `json:"buckets"`
+		Permissions []influxdb.Permission `json:"permissions"`
+		DialectType string                `json:"dialectType"`
+		Dialect     interface{}           `json:"dialect"`
+		Window      int64                 `json:"window,omitempty"`
+	}{
+		OrgID:       orgID,
+		Query:       text,
+		Buckets:     k.buckets,
+		Permissions: permissions,
+		DialectType: string(req.Dialect.DialectType()),
+		Dialect:     req.Dialect,
+		Window:      window(a.relative, now, granularity),
+	})
+	if err != nil {
+		return nil, false
+	}
+
+	sum := sha256.Sum256(octets)
+	k.key = hex.EncodeToString(sum[:])
+	return k, true
+}
+
+// window returns the start of the window of width granularity that now is
+// in, in nanoseconds, or zero if the query is not relative to now.
+func window(relative bool, now time.Time, granularity time.Duration) int64 {
+	if !relative {
+		return 0
+	}
+	return now.Truncate(granularity).UnixNano()
+}
+
+// analysis is what a query reads and whether its response may be cached.
+type analysis struct {
+	cacheable   bool
+	relative    bool
+	bucketNames []string
+	bucketIDs   []string
+}
+
+// analyze finds the buckets the query reads with from, and whether it is
+// relative to now: whether it has durations, refers to now, or has a range
+// without a stop, which is now. Queries that import packages or call to or
+// buckets may not be cached, since they may read other sources or write.
+func analyze(pkg *ast.Package) *analysis {
+	a := &analysis{cacheable: true}
+	froms := 0
+	ast.Walk(ast.CreateVisitor(func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.ImportDeclaration:
+			a.cacheable = false
+		case *ast.DurationLiteral:
+			a.relative = true
+		case *ast.Identifier:
+			if n.Name == "now" {
+				a.relative = true
+			}
+		case *ast.CallExpression:
+			callee, ok := n.Callee.(*ast.Identifier)
+			if !ok {
+				return
+			}
+			switch callee.Name {
+			case "to", "buckets":
+				a.cacheable = false
+			case "from":
+				froms++
+				if !a.addFrom(n) {
+					a.cacheable = false
+				}
+			case "range":
+				if _, ok := callArgument(n, "stop"); !ok {
+					a.relative = true
+				}
+			}
+		}
+	}), pkg)
+
+	if froms == 0 {
+		a.cacheable = false
+	}
+	return a
+}
+
+// addFrom adds the bucket of a call to from, and reports whether it is a
+// literal.
+func (a *analysis) addFrom(call *ast.CallExpression) bool {
+	if v, ok := callArgument(call, "bucket"); ok {
+		lit, ok := v.(*ast.StringLiteral)
+		if !ok {
+			return false
+		}
+		a.bucketNames = append(a.bucketNames, lit.Value)
+		return true
+	}
+	if v, ok := callArgument(call, "bucketID"); ok {
+		lit, ok := v.(*ast.StringLiteral)
+		if !ok {
+			return false
+		}
+		a.bucketIDs = append(a.bucketIDs, lit.Value)
+		return true
+	}
+	return false
+}
+
+// callArgument returns the value of the named argument of a call.
+func callArgument(call *ast.CallExpression, name string) (ast.Expression, bool) {
+	for _, arg := range call.Arguments {
+		obj, ok := arg.(*ast.ObjectExpression)
+		if !ok {
+			continue
+		}
+		for _, p := range obj.Properties {
+			if p.Key.Key() == name {
+				return p.Value, true
+			}
+		}
+	}
+	return nil, false
+}
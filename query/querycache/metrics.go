@@ -0,0 +1,55 @@
+package querycache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// cacheMetrics is a collection of metrics relating to the query cache.
+type cacheMetrics struct {
+	requests  *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+
+	entries prometheus.Gauge
+	size    prometheus.Gauge
+}
+
+func newCacheMetrics() *cacheMetrics {
+	const namespace = "query"
+	const subsystem = "cache"
+
+	return &cacheMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Number of queries, split out by whether they were answered from the cache (hit), cached (miss) or could not be cached (uncacheable).",
+		}, []string{"result"}),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evictions_total",
+			Help:      "Number of responses removed from the cache, split out by whether the cache was full (size), their buckets changed (invalidated) or they were too old (expired).",
+		}, []string{"reason"}),
+
+		entries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "entries",
+			Help:      "Number of responses in the cache.",
+		}),
+		size: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "size_bytes",
+			Help:      "Total size of the responses in the cache.",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *cacheMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requests,
+		m.evictions,
+		m.entries,
+		m.size,
+	}
+}
@@ -0,0 +1,247 @@
+// Package querycache caches the responses of Flux queries, so that
+// dashboards refreshing the same queries from many browsers do not run them
+// again and again. A response is invalidated when the buckets it read are
+// written to, imported into or deleted from.
+package querycache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// DefaultMaxEntrySize is the default size of the largest response cached.
+	DefaultMaxEntrySize = 1 << 20
+
+	// DefaultGranularity is the default width of the windows of time within
+	// which queries relative to now share their response.
+	DefaultGranularity = 10 * time.Second
+
+	// DefaultMaxAge is the default time a response is cached.
+	DefaultMaxAge = 5 * time.Minute
+)
+
+// Config configures the query cache.
+type Config struct {
+	// MaxSize is the total size of the responses cached, in bytes.
+	// The least recently used responses are evicted to stay within it.
+	MaxSize int
+
+	// MaxEntrySize is the size of the largest response cached, in bytes.
+	MaxEntrySize int
+
+	// Granularity is the width of the windows of time within which queries
+	// relative to now share their response.
+	Granularity time.Duration
+
+	// MaxAge is the time a response is cached, even if its buckets do not change.
+	MaxAge time.Duration
+}
+
+// NewConfig returns a Config with the defaults and no size.
+func NewConfig() Config {
+	return Config{
+		MaxEntrySize: DefaultMaxEntrySize,
+		Granularity:  DefaultGranularity,
+		MaxAge:       DefaultMaxAge,
+	}
+}
+
+// BucketVersioner counts the changes to buckets.
+// It is implemented by storage.WriteTracker.
+type BucketVersioner interface {
+	BucketVersion(bucketID influxdb.ID) uint64
+}
+
+// ProxyQueryService wraps a query.ProxyQueryService and caches the
+// responses of its queries.
+type ProxyQueryService struct {
+	s        query.ProxyQueryService
+	config   Config
+	lookup   BucketLookup
+	versions BucketVersioner
+	metrics  *cacheMetrics
+
+	// now returns the current time, and may be replaced in tests.
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	size    int
+}
+
+// entry is a cached response.
+type entry struct {
+	key      string
+	response []byte
+	versions map[influxdb.ID]uint64
+	created  time.Time
+}
+
+// New returns a ProxyQueryService caching the responses of s. Bucket names
+// are resolved with lookup, and responses are invalidated when the versions
+// of their buckets change.
+func New(s query.ProxyQueryService, config Config, lookup BucketLookup, versions BucketVersioner) *ProxyQueryService {
+	return &ProxyQueryService{
+		s:        s,
+		config:   config,
+		lookup:   lookup,
+		versions: versions,
+		metrics:  newCacheMetrics(),
+		now:      time.Now,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (s *ProxyQueryService) PrometheusCollectors() []prometheus.Collector {
+	return s.metrics.PrometheusCollectors()
+}
+
+// Check returns the status of the wrapped service.
+func (s *ProxyQueryService) Check(ctx context.Context) check.Response {
+	return s.s.Check(ctx)
+}
+
+// Query writes the cached response of req to w if there is one. Otherwise
+// it performs the query, and caches its response if it succeeds. The
+// statistics of a cached response are empty.
+func (s *ProxyQueryService) Query(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	k, ok := newCacheKey(ctx, req, s.lookup, s.now(), s.config.Granularity)
+	if !ok {
+		s.metrics.requests.WithLabelValues("uncacheable").Inc()
+		return s.s.Query(ctx, w, req)
+	}
+
+	if response, ok := s.get(k); ok {
+		s.metrics.requests.WithLabelValues("hit").Inc()
+		_, err := w.Write(response)
+		return flux.Statistics{}, err
+	}
+	s.metrics.requests.WithLabelValues("miss").Inc()
+
+	// The versions are read before the query, so that changes during it
+	// invalidate its response.
+	versions := make(map[influxdb.ID]uint64, len(k.buckets))
+	for _, id := range k.buckets {
+		versions[id] = s.versions.BucketVersion(id)
+	}
+	created := s.now()
+
+	buf := &limitedBuffer{max: s.config.MaxEntrySize}
+	stats, err := s.s.Query(ctx, io.MultiWriter(w, buf), req)
+	if err == nil && !buf.full {
+		s.put(&entry{
+			key:      k.key,
+			response: buf.Bytes(),
+			versions: versions,
+			created:  created,
+		})
+	}
+	return stats, err
+}
+
+// get returns the cached response for k, removing it if it is too old or
+// its buckets changed.
+func (s *ProxyQueryService) get(k *cacheKey) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[k.key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*entry)
+
+	if s.config.MaxAge > 0 && s.now().Sub(e.created) >= s.config.MaxAge {
+		s.remove(el, "expired")
+		return nil, false
+	}
+	for id, v := range e.versions {
+		if s.versions.BucketVersion(id) != v {
+			s.remove(el, "invalidated")
+			return nil, false
+		}
+	}
+
+	s.lru.MoveToFront(el)
+	return e.response, true
+}
+
+// put caches e, evicting the least recently used responses to make room.
+func (s *ProxyQueryService) put(e *entry) {
+	n := len(e.response)
+	if n > s.config.MaxSize {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[e.key]; ok {
+		s.remove(el, "")
+	}
+	for s.size+n > s.config.MaxSize {
+		s.remove(s.lru.Back(), "size")
+	}
+
+	s.entries[e.key] = s.lru.PushFront(e)
+	s.size += n
+	s.metrics.entries.Set(float64(len(s.entries)))
+	s.metrics.size.Set(float64(s.size))
+}
+
+// remove removes a cached response, counting the eviction for reason
+// unless it is empty. It must be called with mu held.
+func (s *ProxyQueryService) remove(el *list.Element, reason string) {
+	e := s.lru.Remove(el).(*entry)
+	delete(s.entries, e.key)
+	s.size -= len(e.response)
+	if reason != "" {
+		s.metrics.evictions.WithLabelValues(reason).Inc()
+	}
+	s.metrics.entries.Set(float64(len(s.entries)))
+	s.metrics.size.Set(float64(s.size))
+}
+
+// limitedBuffer buffers writes until they exceed max bytes, and then
+// discards them. It never fails, so that the response is always written
+// to the client.
+type limitedBuffer struct {
+	buf  bytes.Buffer
+	max  int
+	full bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.full {
+		return len(p), nil
+	}
+	if b.buf.Len()+len(p) > b.max {
+		b.full = true
+		b.buf.Reset()
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// Bytes returns the buffered writes.
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
@@ -0,0 +1,260 @@
+package querycache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+var (
+	orgID    = influxdbtesting.MustIDBase16("020f755c3c082000")
+	bucketID = influxdbtesting.MustIDBase16("020f755c3c082001")
+)
+
+type bucketLookup map[string]influxdb.ID
+
+func (l bucketLookup) Lookup(ctx context.Context, orgID influxdb.ID, name string) (influxdb.ID, bool) {
+	id, ok := l[name]
+	return id, ok
+}
+
+type bucketVersions map[influxdb.ID]uint64
+
+func (v bucketVersions) BucketVersion(bucketID influxdb.ID) uint64 {
+	return v[bucketID]
+}
+
+// newRequest returns a request of the query compiled from its AST, as
+// requests from the API are.
+func newRequest(q string, permissions ...influxdb.Permission) *query.ProxyRequest {
+	return &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  &influxdb.Authorization{Permissions: permissions},
+			OrganizationID: orgID,
+			Compiler:       lang.ASTCompiler{AST: parser.ParseSource(q)},
+		},
+		Dialect: &csv.Dialect{},
+	}
+}
+
+// newService returns a cache over a service answering with the number of
+// queries it has performed.
+func newService(config Config, versions bucketVersions) (*ProxyQueryService, *int) {
+	n := 0
+	s := &querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			n++
+			_, err := io.WriteString(w, strings.Repeat("x", n))
+			return flux.Statistics{TotalDuration: time.Second}, err
+		},
+	}
+	return New(s, config, bucketLookup{"telegraf": bucketID}, versions), &n
+}
+
+func doQuery(t *testing.T, s *ProxyQueryService, req *query.ProxyRequest) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := s.Query(context.Background(), &buf, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.String()
+}
+
+func TestProxyQueryService_Cacheable(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		cacheable bool
+	}{
+		{name: "absolute range", query: `from(bucket: "telegraf") |> range(start: 2019-01-01T00:00:00Z, stop: 2019-01-02T00:00:00Z)`, cacheable: true},
+		{name: "relative range", query: `from(bucket: "telegraf") |> range(start: -1h)`, cacheable: true},
+		{name: "bucket ID", query: `from(bucketID: "020f755c3c082001") |> range(start: -1h)`, cacheable: true},
+		{name: "unknown bucket", query: `from(bucket: "unknown") |> range(start: -1h)`},
+		{name: "invalid bucket ID", query: `from(bucketID: "invalid") |> range(start: -1h)`},
+		{name: "bucket variable", query: "b = \"telegraf\"\nfrom(bucket: b) |> range(start: -1h)"},
+		{name: "to", query: `from(bucket: "telegraf") |> range(start: -1h) |> to(bucket: "other")`},
+		{name: "buckets", query: `buckets()`},
+		{name: "import", query: "import \"csv\"\nfrom(bucket: \"telegraf\") |> range(start: -1h)"},
+		{name: "syntax error", query: `from(bucket: "telegraf") |>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, n := newService(Config{MaxSize: 1024, MaxEntrySize: 1024, Granularity: time.Minute}, bucketVersions{})
+			doQuery(t, s, newRequest(tt.query))
+			doQuery(t, s, newRequest(tt.query))
+			if got, want := *n == 1, tt.cacheable; got != want {
+				t.Fatalf("unexpected cacheability after %d queries: got %v want %v", *n, got, want)
+			}
+		})
+	}
+}
+
+func TestProxyQueryService_Key(t *testing.T) {
+	s, n := newService(Config{MaxSize: 1024, MaxEntrySize: 1024}, bucketVersions{})
+	q := `from(bucket: "telegraf") |> range(start: 2019-01-01T00:00:00Z, stop: 2019-01-02T00:00:00Z)`
+
+	doQuery(t, s, newRequest(q))
+	// Formatting does not change the key.
+	doQuery(t, s, newRequest("from(bucket:\"telegraf\")\n  |> range(start:2019-01-01T00:00:00Z,stop:2019-01-02T00:00:00Z)"))
+	if *n != 1 {
+		t.Fatalf("expected reformatted query to hit the cache, but performed %d queries", *n)
+	}
+
+	// Different permissions do not share responses.
+	doQuery(t, s, newRequest(q, influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: &bucketID},
+	}))
+	if *n != 2 {
+		t.Fatalf("expected query with other permissions to miss the cache, but performed %d queries", *n)
+	}
+
+	// Different dialects do not share responses.
+	req := newRequest(q)
+	req.Dialect = &csv.Dialect{ResultEncoderConfig: csv.ResultEncoderConfig{NoHeader: true}}
+	doQuery(t, s, req)
+	if *n != 3 {
+		t.Fatalf("expected query with other dialect to miss the cache, but performed %d queries", *n)
+	}
+}
+
+func TestProxyQueryService_FluxCompiler(t *testing.T) {
+	s, n := newService(Config{MaxSize: 1024, MaxEntrySize: 1024}, bucketVersions{})
+	q := `from(bucket: "telegraf") |> range(start: 2019-01-01T00:00:00Z, stop: 2019-01-02T00:00:00Z)`
+
+	for i := 0; i < 2; i++ {
+		req := newRequest(q)
+		req.Request.Compiler = lang.FluxCompiler{Query: q}
+		doQuery(t, s, req)
+	}
+	// The text of the query has the same key as its AST.
+	doQuery(t, s, newRequest(q))
+	if *n != 1 {
+		t.Fatalf("expected query text to hit the cache, but performed %d queries", *n)
+	}
+}
+
+func TestProxyQueryService_Granularity(t *testing.T) {
+	s, n := newService(Config{MaxSize: 1024, MaxEntrySize: 1024, Granularity: 10 * time.Second}, bucketVersions{})
+	now := time.Unix(100, 0)
+	s.now = func() time.Time { return now }
+	q := `from(bucket: "telegraf") |> range(start: -1h)`
+
+	doQuery(t, s, newRequest(q))
+	now = now.Add(9 * time.Second)
+	doQuery(t, s, newRequest(q))
+	if *n != 1 {
+		t.Fatalf("expected query within granularity to hit the cache, but performed %d queries", *n)
+	}
+
+	now = now.Add(time.Second)
+	doQuery(t, s, newRequest(q))
+	if *n != 2 {
+		t.Fatalf("expected query in next window to miss the cache, but performed %d queries", *n)
+	}
+
+	// Without a granularity, relative queries are not cached.
+	s.config.Granularity = 0
+	doQuery(t, s, newRequest(q))
+	doQuery(t, s, newRequest(q))
+	if *n != 4 {
+		t.Fatalf("expected query without granularity to miss the cache, but performed %d queries", *n)
+	}
+}
+
+func TestProxyQueryService_Invalidation(t *testing.T) {
+	versions := bucketVersions{}
+	s, _ := newService(Config{MaxSize: 1024, MaxEntrySize: 1024, MaxAge: time.Minute}, versions)
+	now := time.Unix(100, 0)
+	s.now = func() time.Time { return now }
+	q := `from(bucket: "telegraf") |> range(start: 2019-01-01T00:00:00Z, stop: 2019-01-02T00:00:00Z)`
+
+	if got := doQuery(t, s, newRequest(q)); got != "x" {
+		t.Fatalf("unexpected response: %q", got)
+	}
+	if got := doQuery(t, s, newRequest(q)); got != "x" {
+		t.Fatalf("unexpected cached response: %q", got)
+	}
+
+	versions[bucketID]++
+	if got := doQuery(t, s, newRequest(q)); got != "xx" {
+		t.Fatalf("expected write to invalidate response, got %q", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got := doQuery(t, s, newRequest(q)); got != "xxx" {
+		t.Fatalf("expected old response to expire, got %q", got)
+	}
+}
+
+func TestProxyQueryService_Size(t *testing.T) {
+	s, n := newService(Config{MaxSize: 4, MaxEntrySize: 3}, bucketVersions{})
+	q1 := `from(bucket: "telegraf") |> range(start: 2019-01-01T00:00:00Z, stop: 2019-01-02T00:00:00Z)`
+	q2 := `from(bucket: "telegraf") |> range(start: 2019-01-02T00:00:00Z, stop: 2019-01-03T00:00:00Z)`
+	q3 := `from(bucket: "telegraf") |> range(start: 2019-01-03T00:00:00Z, stop: 2019-01-04T00:00:00Z)`
+
+	doQuery(t, s, newRequest(q1)) // "x"
+	doQuery(t, s, newRequest(q2)) // "xx"
+	doQuery(t, s, newRequest(q1))
+	if *n != 2 {
+		t.Fatalf("expected both responses to be cached, but performed %d queries", *n)
+	}
+
+	// Caching "xxx" evicts the least recently used response, of q2.
+	doQuery(t, s, newRequest(q3))
+	doQuery(t, s, newRequest(q1))
+	doQuery(t, s, newRequest(q3))
+	if *n != 3 {
+		t.Fatalf("expected q1 and q3 to be cached, but performed %d queries", *n)
+	}
+
+	// The response "xxxx" is larger than MaxEntrySize, and is not cached.
+	for i := 0; i < 2; i++ {
+		doQuery(t, s, newRequest(q2))
+	}
+	if *n != 5 {
+		t.Fatalf("expected large response not to be cached, but performed %d queries", *n)
+	}
+
+	s.mu.Lock()
+	size, entries := s.size, len(s.entries)
+	s.mu.Unlock()
+	if size != 4 || entries != 2 {
+		t.Fatalf("unexpected cache size %d with %d entries", size, entries)
+	}
+}
+
+func TestProxyQueryService_Error(t *testing.T) {
+	n := 0
+	s := New(&querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			n++
+			_, _ = io.WriteString(w, "partial")
+			return flux.Statistics{}, errors.New("expected error")
+		},
+	}, Config{MaxSize: 1024, MaxEntrySize: 1024}, bucketLookup{"telegraf": bucketID}, bucketVersions{})
+
+	q := `from(bucket: "telegraf") |> range(start: -1h)`
+	for i := 0; i < 2; i++ {
+		if _, err := s.Query(context.Background(), ioutil.Discard, newRequest(q)); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if n != 2 {
+		t.Fatalf("expected failed query not to be cached, but performed %d queries", n)
+	}
+}
@@ -0,0 +1,312 @@
+// Package querylog logs the queries that have finished. It keeps the recent
+// queries of each user in memory, and logs the queries that are slow for
+// their organization, also writing them as points to a bucket so that they
+// can be queried and retained like any other data.
+//
+// Each organization may set a bucket of its own for its slow queries. The
+// operator may also set a bucket for the slow queries of every organization,
+// which anyone who can read that bucket can see, so it belongs in an
+// organization of operators only.
+//
+// Finished queries are queued and recorded in the background, so that
+// looking up the settings of organizations and writing points does not
+// delay the responses of queries. Queries are dropped if the queue is full.
+package querylog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultHistorySize is the default number of recent queries kept for
+	// each user.
+	DefaultHistorySize = 100
+
+	// DefaultQueueSize is the default number of finished queries waiting to
+	// be recorded.
+	DefaultQueueSize = 1000
+)
+
+// Config configures a Logger.
+type Config struct {
+	// BucketID is the bucket the slow queries of every organization are
+	// written to, in addition to the bucket of their organization. If it is
+	// not valid, they are only written to the buckets of organizations.
+	BucketID influxdb.ID
+
+	// SlowQueryThreshold is the duration at or above which a query is slow,
+	// for organizations without a threshold of their own. Zero disables the
+	// slow query log of those organizations.
+	SlowQueryThreshold time.Duration
+
+	// HistorySize is the number of recent queries kept for each user.
+	HistorySize int
+
+	// QueueSize is the number of finished queries waiting to be recorded.
+	QueueSize int
+}
+
+// PointsWriter describes the ability to write points into a storage engine.
+// It is a copy of storage.PointsWriter to avoid depending on storage.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []models.Point) error
+}
+
+var (
+	_ query.Logger         = (*Logger)(nil)
+	_ query.HistoryService = (*Logger)(nil)
+)
+
+// Logger is a query.Logger that keeps the recent queries of each user and
+// records slow queries.
+type Logger struct {
+	log      *zap.Logger
+	config   Config
+	settings query.LogSettingsService
+	buckets  influxdb.BucketService
+	writer   PointsWriter
+
+	mu      sync.RWMutex
+	history map[influxdb.ID][]*query.LoggedQuery
+
+	queueMu sync.RWMutex
+	queue   chan *query.LoggedQuery
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// New returns a Logger that looks up the query log settings of each
+// organization in settings. Buckets are looked up on every write, so they
+// may be created after the logger. Queries are recorded once the logger is
+// opened.
+func New(log *zap.Logger, config Config, settings query.LogSettingsService, bs influxdb.BucketService, pw PointsWriter) *Logger {
+	if config.HistorySize <= 0 {
+		config.HistorySize = DefaultHistorySize
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	return &Logger{
+		log:      log,
+		config:   config,
+		settings: settings,
+		buckets:  bs,
+		writer:   pw,
+		history:  make(map[influxdb.ID][]*query.LoggedQuery),
+		queue:    make(chan *query.LoggedQuery, config.QueueSize),
+	}
+}
+
+// Open starts recording the queued queries.
+func (l *Logger) Open() {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		for q := range l.queue {
+			l.record(context.Background(), q)
+		}
+	}()
+}
+
+// Close records the queued queries and stops the logger. Queries logged
+// after Close are dropped.
+func (l *Logger) Close() error {
+	l.queueMu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.queueMu.Unlock()
+
+	l.wg.Wait()
+	return nil
+}
+
+// Log queues the query to be added to the history of its user, and recorded
+// if it is slow for its organization. The query is dropped if the queue is
+// full.
+func (l *Logger) Log(log query.Log) error {
+	q := newLoggedQuery(log)
+
+	l.queueMu.RLock()
+	defer l.queueMu.RUnlock()
+	if l.closed {
+		return errLoggerClosed
+	}
+	select {
+	case l.queue <- q:
+		return nil
+	default:
+		l.log.Warn("Dropped query, the query log queue is full",
+			zap.Stringer("org_id", q.OrganizationID),
+			zap.Int("queue_size", l.config.QueueSize))
+		return errQueueFull
+	}
+}
+
+var (
+	errLoggerClosed = errors.New("query logger is closed")
+	errQueueFull    = errors.New("query log queue is full")
+)
+
+// record adds the query to the history of its user, and logs and writes it
+// if it is slow for its organization.
+func (l *Logger) record(ctx context.Context, q *query.LoggedQuery) {
+	settings := l.findSettings(ctx, q.OrganizationID)
+	threshold := settings.SlowQueryThreshold
+	if threshold == 0 {
+		threshold = l.config.SlowQueryThreshold
+	}
+	if threshold > 0 {
+		q.Slow = q.Statistics.TotalDuration >= threshold
+	}
+	l.remember(q)
+
+	if !q.Slow {
+		return
+	}
+
+	l.log.Info("Slow query",
+		zap.Stringer("org_id", q.OrganizationID),
+		zap.Stringer("user_id", q.UserID),
+		zap.Stringer("authorization_id", q.AuthorizationID),
+		zap.String("source", q.Source),
+		zap.String("type", string(q.Type)),
+		zap.String("query", q.Query),
+		zap.Duration("total_duration", q.Statistics.TotalDuration),
+		zap.Duration("compile_duration", q.Statistics.CompileDuration),
+		zap.Duration("queue_duration", q.Statistics.QueueDuration),
+		zap.Duration("execute_duration", q.Statistics.ExecuteDuration),
+		zap.Int64("max_allocated", q.Statistics.MaxAllocated),
+		zap.String("error", q.Error))
+
+	bucketIDs := []influxdb.ID{l.config.BucketID}
+	if settings.BucketID != l.config.BucketID {
+		bucketIDs = append(bucketIDs, settings.BucketID)
+	}
+	for _, bucketID := range bucketIDs {
+		if !bucketID.Valid() {
+			continue
+		}
+		if err := l.writeQuery(ctx, bucketID, q); err != nil {
+			l.log.Error("Failed to write slow query to bucket",
+				zap.Stringer("bucket_id", bucketID),
+				zap.Error(err))
+		}
+	}
+}
+
+// findSettings returns the query log settings of the organization, or the
+// defaults if they cannot be found.
+func (l *Logger) findSettings(ctx context.Context, orgID influxdb.ID) *query.LogSettings {
+	settings, err := l.settings.FindLogSettings(ctx, orgID)
+	if err != nil {
+		l.log.Error("Failed to find query log settings",
+			zap.Stringer("org_id", orgID),
+			zap.Error(err))
+		return &query.LogSettings{OrganizationID: orgID}
+	}
+	return settings
+}
+
+func newLoggedQuery(log query.Log) *query.LoggedQuery {
+	q := &query.LoggedQuery{
+		Time:           log.Time,
+		OrganizationID: log.OrganizationID,
+		ResponseSize:   log.ResponseSize,
+		Statistics:     log.Statistics,
+	}
+	if log.Error != nil {
+		q.Error = log.Error.Error()
+	}
+	if log.ProxyRequest == nil {
+		return q
+	}
+
+	req := log.ProxyRequest.Request
+	if req.Authorization != nil {
+		q.UserID = req.Authorization.UserID
+		q.AuthorizationID = req.Authorization.ID
+	}
+	q.Source = req.Source
+	if req.Compiler != nil {
+		q.Type = req.Compiler.CompilerType()
+		q.Query = query.CompilerQuery(req.Compiler)
+	}
+	return q
+}
+
+// remember adds q to the history of its user, forgetting the oldest query
+// of the user if the history is full.
+func (l *Logger) remember(q *query.LoggedQuery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h := l.history[q.UserID]
+	if len(h) == l.config.HistorySize {
+		copy(h, h[1:])
+		h = h[:len(h)-1]
+	}
+	l.history[q.UserID] = append(h, q)
+}
+
+// FindRecentQueries returns the recent queries that match the filter, the
+// most recent first.
+func (l *Logger) FindRecentQueries(ctx context.Context, filter query.RecentQueryFilter) ([]*query.LoggedQuery, error) {
+	queries := []*query.LoggedQuery{}
+
+	l.mu.RLock()
+	for userID, h := range l.history {
+		if filter.UserID != nil && *filter.UserID != userID {
+			continue
+		}
+		for _, q := range h {
+			if filter.Match(q) {
+				queries = append(queries, q)
+			}
+		}
+	}
+	l.mu.RUnlock()
+
+	sort.SliceStable(queries, func(i, j int) bool {
+		return queries[i].Time.After(queries[j].Time)
+	})
+	if filter.Limit > 0 && len(queries) > filter.Limit {
+		queries = queries[:filter.Limit]
+	}
+	return queries, nil
+}
+
+// writeQuery writes q to the bucket. The bucket of an organization only
+// receives its own queries; the bucket of the config receives every query.
+func (l *Logger) writeQuery(ctx context.Context, bucketID influxdb.ID, q *query.LoggedQuery) error {
+	b, err := l.buckets.FindBucketByID(ctx, bucketID)
+	if err != nil {
+		return err
+	}
+	if bucketID != l.config.BucketID && b.OrganizationID != q.OrganizationID {
+		return fmt.Errorf("bucket %s does not belong to organization %s", bucketID, q.OrganizationID)
+	}
+
+	pt, err := NewPoint(q)
+	if err != nil {
+		return err
+	}
+
+	exploded, err := tsdb.ExplodePoints(b.OrganizationID, b.ID, []models.Point{pt})
+	if err != nil {
+		return err
+	}
+	return l.writer.WritePoints(ctx, exploded)
+}
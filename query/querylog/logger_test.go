@@ -0,0 +1,263 @@
+package querylog_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/query/querylog"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+var (
+	orgID    = influxdbtesting.MustIDBase16("020f755c3c082000")
+	bucketID = influxdbtesting.MustIDBase16("020f755c3c082001")
+	userID   = influxdbtesting.MustIDBase16("020f755c3c082002")
+	authID   = influxdbtesting.MustIDBase16("020f755c3c082003")
+)
+
+func newLog(t time.Time, user influxdb.ID, duration time.Duration) query.Log {
+	return query.Log{
+		Time:           t,
+		OrganizationID: orgID,
+		ProxyRequest: &query.ProxyRequest{
+			Request: query.Request{
+				Authorization:  &influxdb.Authorization{ID: authID, UserID: user, Token: "secret"},
+				OrganizationID: orgID,
+				Source:         "influx",
+				Compiler:       lang.FluxCompiler{Query: `from(bucket: "telegraf") |> range(start: -1h)`},
+			},
+		},
+		ResponseSize: 42,
+		Statistics: flux.Statistics{
+			TotalDuration:   duration,
+			CompileDuration: time.Millisecond,
+			QueueDuration:   2 * time.Millisecond,
+			ExecuteDuration: duration - 3*time.Millisecond,
+			MaxAllocated:    1024,
+		},
+	}
+}
+
+func TestNewPoint(t *testing.T) {
+	q := &query.LoggedQuery{
+		Time:            time.Unix(0, 1000),
+		OrganizationID:  orgID,
+		UserID:          userID,
+		AuthorizationID: authID,
+		Type:            lang.FluxCompilerType,
+		Query:           `from(bucket: "b")`,
+		Error:           "expected error",
+		ResponseSize:    42,
+		Statistics: flux.Statistics{
+			TotalDuration:   10,
+			CompileDuration: 1,
+			QueueDuration:   2,
+			ExecuteDuration: 7,
+			Concurrency:     1,
+			MaxAllocated:    1024,
+		},
+	}
+
+	pt, err := querylog.NewPoint(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `slow_queries,orgID=020f755c3c082000,type=flux authorizationID="020f755c3c082003",compileDuration=1i,concurrency=1i,error="expected error",executeDuration=7i,maxAllocated=1024i,planDuration=0i,query="from(bucket: \"b\")",queueDuration=2i,requeueDuration=0i,responseSize=42i,totalDuration=10i,userID="020f755c3c082002" 1000`
+	if got := pt.String(); got != want {
+		t.Errorf("unexpected point:\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestLogger_Log(t *testing.T) {
+	operatorOrgID := orgID + 10
+	orgBucketID := bucketID + 10
+	bs := mock.NewBucketService()
+	bs.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		if id == orgBucketID {
+			return &influxdb.Bucket{ID: id, OrganizationID: orgID}, nil
+		}
+		return &influxdb.Bucket{ID: id, OrganizationID: operatorOrgID}, nil
+	}
+	pw := &mock.PointsWriter{}
+	settings := &querymock.LogSettingsService{
+		FindLogSettingsF: func(ctx context.Context, id influxdb.ID) (*query.LogSettings, error) {
+			return &query.LogSettings{OrganizationID: id, SlowQueryThreshold: time.Second, BucketID: orgBucketID}, nil
+		},
+	}
+
+	l := querylog.New(zap.NewNop(), querylog.Config{
+		BucketID:           bucketID,
+		SlowQueryThreshold: time.Minute,
+	}, settings, bs, pw)
+	l.Open()
+
+	if err := l.Log(newLog(time.Unix(1, 0), userID, 100*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(newLog(time.Unix(2, 0), userID, 2*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The slow query is written to the bucket of its organization and to the
+	// bucket of the operator, as a point per field.
+	written := make(map[string]int)
+	for _, p := range pw.Points {
+		written[string(p.Name())]++
+	}
+	for _, name := range [][16]byte{
+		tsdb.EncodeName(orgID, orgBucketID),
+		tsdb.EncodeName(operatorOrgID, bucketID),
+	} {
+		if written[string(name[:])] == 0 {
+			t.Errorf("expected slow query to be written to %x", name)
+		}
+	}
+	if len(written) != 2 {
+		t.Errorf("expected slow query to be written to 2 buckets, got %d", len(written))
+	}
+
+	queries, err := l.FindRecentQueries(context.Background(), query.RecentQueryFilter{UserID: &userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*query.LoggedQuery{
+		{
+			Time:            time.Unix(2, 0),
+			OrganizationID:  orgID,
+			UserID:          userID,
+			AuthorizationID: authID,
+			Source:          "influx",
+			Type:            lang.FluxCompilerType,
+			Query:           `from(bucket: "telegraf") |> range(start: -1h)`,
+			ResponseSize:    42,
+			Statistics:      newLog(time.Time{}, userID, 2*time.Second).Statistics,
+			Slow:            true,
+		},
+		{
+			Time:            time.Unix(1, 0),
+			OrganizationID:  orgID,
+			UserID:          userID,
+			AuthorizationID: authID,
+			Source:          "influx",
+			Type:            lang.FluxCompilerType,
+			Query:           `from(bucket: "telegraf") |> range(start: -1h)`,
+			ResponseSize:    42,
+			Statistics:      newLog(time.Time{}, userID, 100*time.Millisecond).Statistics,
+		},
+	}
+	if !cmp.Equal(want, queries) {
+		t.Errorf("unexpected recent queries -want/+got\n%s", cmp.Diff(want, queries))
+	}
+}
+
+func TestLogger_FindRecentQueries(t *testing.T) {
+	settings := &querymock.LogSettingsService{
+		FindLogSettingsF: func(ctx context.Context, id influxdb.ID) (*query.LogSettings, error) {
+			return nil, errors.New("settings unavailable")
+		},
+	}
+	l := querylog.New(zap.NewNop(), querylog.Config{HistorySize: 2}, settings, mock.NewBucketService(), &mock.PointsWriter{})
+	l.Open()
+
+	other := userID + 1
+	for i, user := range []influxdb.ID{userID, other, userID, userID} {
+		if err := l.Log(newLog(time.Unix(int64(i), 0), user, time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	times := func(queries []*query.LoggedQuery) []int64 {
+		var ts []int64
+		for _, q := range queries {
+			ts = append(ts, q.Time.Unix())
+		}
+		return ts
+	}
+
+	for _, tt := range []struct {
+		name   string
+		filter query.RecentQueryFilter
+		want   []int64
+	}{
+		{
+			name: "all users",
+			want: []int64{3, 2, 1},
+		},
+		{
+			name:   "user keeps the most recent queries",
+			filter: query.RecentQueryFilter{UserID: &userID},
+			want:   []int64{3, 2},
+		},
+		{
+			name:   "limit",
+			filter: query.RecentQueryFilter{Limit: 1},
+			want:   []int64{3},
+		},
+		{
+			name:   "other organization",
+			filter: query.RecentQueryFilter{OrganizationID: &bucketID},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			queries, err := l.FindRecentQueries(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := times(queries); !cmp.Equal(tt.want, got) {
+				t.Errorf("unexpected queries -want/+got\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestLogger_Queue(t *testing.T) {
+	settings := &querymock.LogSettingsService{
+		FindLogSettingsF: func(ctx context.Context, id influxdb.ID) (*query.LogSettings, error) {
+			return &query.LogSettings{OrganizationID: id}, nil
+		},
+	}
+	l := querylog.New(zap.NewNop(), querylog.Config{QueueSize: 1}, settings, mock.NewBucketService(), &mock.PointsWriter{})
+
+	// Queries are dropped while the queue is full, rather than delaying
+	// the responses of queries.
+	if err := l.Log(newLog(time.Unix(1, 0), userID, time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(newLog(time.Unix(2, 0), userID, time.Second)); err == nil {
+		t.Fatal("expected query to be dropped while the queue is full")
+	}
+
+	// Closing records the queued queries.
+	l.Open()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	queries, err := l.FindRecentQueries(context.Background(), query.RecentQueryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 || !queries[0].Time.Equal(time.Unix(1, 0)) {
+		t.Errorf("expected the queued query to be recorded, got %d queries", len(queries))
+	}
+	if err := l.Log(newLog(time.Unix(3, 0), userID, time.Second)); err == nil {
+		t.Error("expected queries logged after close to be dropped")
+	}
+}
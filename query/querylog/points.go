@@ -0,0 +1,74 @@
+package querylog
+
+import (
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+)
+
+const (
+	// Measurement is the measurement of the points written for slow queries.
+	Measurement = "slow_queries"
+
+	orgIDTag = "orgID"
+	typeTag  = "type"
+
+	queryField           = "query"
+	userIDField          = "userID"
+	authorizationIDField = "authorizationID"
+	sourceField          = "source"
+	errorField           = "error"
+	responseSizeField    = "responseSize"
+	totalDurationField   = "totalDuration"
+	compileDurationField = "compileDuration"
+	queueDurationField   = "queueDuration"
+	planDurationField    = "planDuration"
+	requeueDurationField = "requeueDuration"
+	executeDurationField = "executeDuration"
+	concurrencyField     = "concurrency"
+	maxAllocatedField    = "maxAllocated"
+)
+
+// NewPoint returns the query as a point at the time it finished. The
+// organization and type of the query are tags, the text of the query and the
+// identifiers are string fields, and the statistics are integer fields, with
+// durations in nanoseconds.
+func NewPoint(q *query.LoggedQuery) (models.Point, error) {
+	tags := map[string]string{
+		orgIDTag: q.OrganizationID.String(),
+	}
+	if q.Type != "" {
+		tags[typeTag] = string(q.Type)
+	}
+
+	s := q.Statistics
+	fields := map[string]interface{}{
+		queryField:           q.Query,
+		responseSizeField:    q.ResponseSize,
+		totalDurationField:   int64(s.TotalDuration),
+		compileDurationField: int64(s.CompileDuration),
+		queueDurationField:   int64(s.QueueDuration),
+		planDurationField:    int64(s.PlanDuration),
+		requeueDurationField: int64(s.RequeueDuration),
+		executeDurationField: int64(s.ExecuteDuration),
+		concurrencyField:     int64(s.Concurrency),
+		maxAllocatedField:    s.MaxAllocated,
+	}
+	ids := map[string]influxdb.ID{
+		userIDField:          q.UserID,
+		authorizationIDField: q.AuthorizationID,
+	}
+	for k, id := range ids {
+		if id.Valid() {
+			fields[k] = id.String()
+		}
+	}
+	if q.Source != "" {
+		fields[sourceField] = q.Source
+	}
+	if q.Error != "" {
+		fields[errorField] = q.Error
+	}
+
+	return models.NewPoint(Measurement, models.NewTags(tags), fields, q.Time)
+}
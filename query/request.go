@@ -15,6 +15,9 @@ type Request struct {
 	Authorization  *platform.Authorization `json:"authorization,omitempty"`
 	OrganizationID platform.ID             `json:"organization_id"`
 
+	// Source is the client that made the request, such as its user agent.
+	Source string `json:"source,omitempty"`
+
 	// Command
 
 	// Compiler converts the query to a specification to run against the data.
@@ -5,6 +5,7 @@ import (
 	"io"
 
 	"github.com/influxdata/flux"
+	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/check"
 )
 
@@ -33,3 +34,29 @@ type ProxyQueryService interface {
 	// The number of bytes written to w is returned __independent__ of any error.
 	Query(ctx context.Context, w io.Writer, req *ProxyRequest) (flux.Statistics, error)
 }
+
+// ActiveQueryService lists and cancels the queries that are running.
+type ActiveQueryService interface {
+	// FindActiveQueries returns the running queries that match the filter.
+	FindActiveQueries(ctx context.Context, filter ActiveQueryFilter) ([]*ActiveQuery, error)
+
+	// CancelActiveQuery cancels the running query with the id.
+	CancelActiveQuery(ctx context.Context, id platform.ID) error
+}
+
+// LogSettingsService stores the query log settings of organizations.
+type LogSettingsService interface {
+	// FindLogSettings returns the settings of the organization, or the
+	// defaults if it has none.
+	FindLogSettings(ctx context.Context, orgID platform.ID) (*LogSettings, error)
+
+	// PutLogSettings replaces the settings of an organization.
+	PutLogSettings(ctx context.Context, s *LogSettings) error
+}
+
+// HistoryService lists the queries that have finished recently.
+type HistoryService interface {
+	// FindRecentQueries returns the recent queries that match the filter,
+	// the most recent first.
+	FindRecentQueries(ctx context.Context, filter RecentQueryFilter) ([]*LoggedQuery, error)
+}
@@ -0,0 +1,190 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrRoleNotFound is the error msg for a missing role.
+const ErrRoleNotFound = "role not found"
+
+// ErrRoleMappingNotFound is the error msg for a missing role mapping.
+const ErrRoleMappingNotFound = "role mapping not found"
+
+// ops for roles error and role op logs.
+const (
+	OpFindRoleByID      = "FindRoleByID"
+	OpFindRoles         = "FindRoles"
+	OpCreateRole        = "CreateRole"
+	OpUpdateRole        = "UpdateRole"
+	OpDeleteRole        = "DeleteRole"
+	OpFindRoleMappings  = "FindRoleMappings"
+	OpCreateRoleMapping = "CreateRoleMapping"
+	OpDeleteRoleMapping = "DeleteRoleMapping"
+)
+
+// Role is a named set of permissions in an organization. The permissions of a
+// role are granted to every user and authorization it is assigned to, so
+// changing them changes the permissions of all holders.
+type Role struct {
+	ID          ID           `json:"id,omitempty"`
+	OrgID       ID           `json:"orgID"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Validate returns an error if the role is invalid. Every permission of a
+// role must be scoped to the organization of the role.
+func (r *Role) Validate() error {
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role requires an orgID",
+		}
+	}
+
+	if r.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role requires a name",
+		}
+	}
+
+	return validateRolePermissions(r.OrgID, r.Permissions)
+}
+
+func validateRolePermissions(orgID ID, ps []Permission) error {
+	for _, p := range ps {
+		if err := p.Valid(); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Err:  err,
+			}
+		}
+
+		if p.Resource.Type == OrgsResourceType {
+			if p.Resource.ID == nil || *p.Resource.ID != orgID {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("permission %s is not scoped to organization %s", p, orgID),
+				}
+			}
+			continue
+		}
+
+		if p.Resource.OrgID == nil || *p.Resource.OrgID != orgID {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("permission %s is not scoped to organization %s", p, orgID),
+			}
+		}
+	}
+	return nil
+}
+
+// RoleUpdate represents updates to a role.
+// Only fields which are set are updated.
+type RoleUpdate struct {
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Permissions *[]Permission `json:"permissions,omitempty"`
+}
+
+// Apply applies the update to the role, returning an error if the updated
+// role is invalid.
+func (u RoleUpdate) Apply(r *Role) error {
+	if u.Name != nil {
+		r.Name = *u.Name
+	}
+
+	if u.Description != nil {
+		r.Description = *u.Description
+	}
+
+	if u.Permissions != nil {
+		r.Permissions = *u.Permissions
+	}
+
+	return r.Validate()
+}
+
+// RoleFilter represents a set of filters that restrict the returned roles.
+type RoleFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+}
+
+// RoleMapping assigns a role to a user or an authorization. Users are granted
+// the permissions of their roles in their sessions.
+type RoleMapping struct {
+	RoleID      ID           `json:"roleID"`
+	SubjectID   ID           `json:"subjectID"`
+	SubjectType ResourceType `json:"subjectType"`
+}
+
+// Validate returns an error if the mapping is invalid.
+func (m *RoleMapping) Validate() error {
+	if !m.RoleID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role mapping requires a roleID",
+		}
+	}
+
+	if !m.SubjectID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role mapping requires a subjectID",
+		}
+	}
+
+	switch m.SubjectType {
+	case UsersResourceType, AuthorizationsResourceType:
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("roles may only be assigned to %s or %s", UsersResourceType, AuthorizationsResourceType),
+		}
+	}
+
+	return nil
+}
+
+// RoleMappingFilter represents a set of filters that restrict the returned
+// role mappings.
+type RoleMappingFilter struct {
+	RoleID      *ID
+	SubjectID   *ID
+	SubjectType ResourceType
+}
+
+// RoleService represents a service for managing roles and their assignment.
+type RoleService interface {
+	// FindRoleByID returns a single role by ID.
+	FindRoleByID(ctx context.Context, id ID) (*Role, error)
+
+	// FindRoles returns a list of roles that match filter and the total count of matching roles.
+	// Additional options provide pagination & sorting.
+	FindRoles(ctx context.Context, filter RoleFilter, opt ...FindOptions) ([]*Role, int, error)
+
+	// CreateRole creates a new role and sets r.ID with the new identifier.
+	CreateRole(ctx context.Context, r *Role) error
+
+	// UpdateRole updates a single role with changeset.
+	// Returns the new role state after update.
+	UpdateRole(ctx context.Context, id ID, upd RoleUpdate) (*Role, error)
+
+	// DeleteRole removes a role by ID, along with all of its mappings.
+	DeleteRole(ctx context.Context, id ID) error
+
+	// FindRoleMappings returns a list of role mappings that match filter and the total count of matching mappings.
+	FindRoleMappings(ctx context.Context, filter RoleMappingFilter) ([]*RoleMapping, int, error)
+
+	// CreateRoleMapping assigns a role to a subject.
+	CreateRoleMapping(ctx context.Context, m *RoleMapping) error
+
+	// DeleteRoleMapping removes a role from a subject.
+	DeleteRoleMapping(ctx context.Context, roleID, subjectID ID) error
+}
@@ -0,0 +1,106 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrShareLinkNotFound is the error msg for a missing share link.
+const ErrShareLinkNotFound = "share link not found"
+
+// ErrShareLinkExpired is the error msg for an expired share link.
+const ErrShareLinkExpired = "share link has expired"
+
+// ops for share links error and share link op logs.
+const (
+	OpFindShareLinkByID  = "FindShareLinkByID"
+	OpFindShareLinkByKey = "FindShareLinkByKey"
+	OpFindShareLinks     = "FindShareLinks"
+	OpCreateShareLink    = "CreateShareLink"
+	OpDeleteShareLink    = "DeleteShareLink"
+)
+
+// ShareLink shares a single dashboard, read-only, with people outside of its
+// organization. Creating a share link mints an authorization that can only
+// read the dashboard, the buckets its cells query and the variables they use.
+// Anyone with the key of the link can resolve it to the token of that
+// authorization, until the link expires or is deleted.
+type ShareLink struct {
+	ID              ID     `json:"id,omitempty"`
+	OrgID           ID     `json:"orgID,omitempty"`
+	DashboardID     ID     `json:"dashboardID"`
+	AuthorizationID ID     `json:"authorizationID,omitempty"`
+	Key             string `json:"key,omitempty"`
+	Description     string `json:"description,omitempty"`
+
+	// Permissions are the permissions of the minted authorization. They
+	// are set when the link is created and don't follow later changes to
+	// the dashboard.
+	Permissions []Permission `json:"permissions,omitempty"`
+
+	// ExpiresAt is the time after which the link can no longer be resolved
+	// and its token is rejected. Links without an expiry never expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	// CreatedBy is the user that created the link. The minted
+	// authorization belongs to this user.
+	CreatedBy ID `json:"createdBy,omitempty"`
+}
+
+// Validate returns an error if the share link can't be created.
+func (l *ShareLink) Validate() error {
+	if !l.DashboardID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "share link requires a dashboardID",
+		}
+	}
+
+	if l.ExpiresAt != nil && !l.ExpiresAt.After(time.Now()) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "share link must expire in the future",
+		}
+	}
+
+	return nil
+}
+
+// Expired returns an error if the share link is expired.
+func (l *ShareLink) Expired() error {
+	if l.ExpiresAt != nil && time.Now().After(*l.ExpiresAt) {
+		return &Error{
+			Code: EForbidden,
+			Msg:  ErrShareLinkExpired,
+		}
+	}
+
+	return nil
+}
+
+// ShareLinkFilter represents a set of filters that restrict the returned
+// share links.
+type ShareLinkFilter struct {
+	ID          *ID
+	OrgID       *ID
+	DashboardID *ID
+}
+
+// ShareLinkService represents a service for managing dashboard share links.
+type ShareLinkService interface {
+	// FindShareLinkByID returns a single share link by ID.
+	FindShareLinkByID(ctx context.Context, id ID) (*ShareLink, error)
+
+	// FindShareLinkByKey returns a single share link by its key.
+	FindShareLinkByKey(ctx context.Context, key string) (*ShareLink, error)
+
+	// FindShareLinks returns a list of share links that match filter and the total count of matching share links.
+	FindShareLinks(ctx context.Context, filter ShareLinkFilter) ([]*ShareLink, int, error)
+
+	// CreateShareLink creates a new share link of l.DashboardID, minting its
+	// authorization, and sets l.ID with the new identifier.
+	CreateShareLink(ctx context.Context, l *ShareLink) error
+
+	// DeleteShareLink removes a share link by ID and revokes its authorization.
+	DeleteShareLink(ctx context.Context, id ID) error
+}
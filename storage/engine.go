@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsi1"
@@ -43,6 +44,12 @@ type Engine struct {
 	engine            *tsm1.Engine
 	wal               *wal.WAL
 	retentionEnforcer *retentionEnforcer
+	keyring           *encryption.Keyring
+	writeTracker      *WriteTracker
+
+	// Index partitions being rebuilt, which must be sent newly written series.
+	rebuildMu     sync.RWMutex
+	indexRebuilds []*tsi1.PartitionRebuild
 
 	defaultMetricLabels prometheus.Labels
 
@@ -90,6 +97,13 @@ func WithRetentionEnforcer(finder BucketFinder) Option {
 	}
 }
 
+// WithWriteTracker makes the engine count the changes to each bucket with t.
+func WithWriteTracker(t *WriteTracker) Option {
+	return func(e *Engine) {
+		e.writeTracker = t
+	}
+}
+
 // WithFileStoreObserver makes the engine have the provided file store observer.
 func WithFileStoreObserver(obs tsm1.FileStoreObserver) Option {
 	return func(e *Engine) {
@@ -104,6 +118,22 @@ func WithCompactionPlanner(planner tsm1.CompactionPlanner) Option {
 	}
 }
 
+// WithKeyring encrypts the TSM, tombstone, WAL and index files the engine
+// writes with the current key of kr. Files written with a previous key of kr
+// are still read, and TSM files are rewritten with the current key as they
+// are compacted.
+//
+// The series file is not encrypted: its segments are memory mapped and read
+// at random offsets, so the series keys it holds are stored in plaintext.
+func WithKeyring(kr *encryption.Keyring) Option {
+	return func(e *Engine) {
+		e.keyring = kr
+		e.index.WithKeyring(kr)
+		e.wal.WithKeyring(kr)
+		e.engine.WithKeyring(kr)
+	}
+}
+
 // NewEngine initialises a new storage engine, including a series file, index and
 // TSM engine.
 func NewEngine(path string, c Config, options ...Option) *Engine {
@@ -240,6 +270,7 @@ func (e *Engine) replayWAL() error {
 	// Execute all the entries in the WAL again
 	reader := wal.NewWALReader(walPaths)
 	reader.WithLogger(e.logger)
+	reader.WithKeyring(e.keyring)
 	err = reader.Read(func(entry wal.WALEntry) error {
 		switch en := entry.(type) {
 		case *wal.WriteWALEntry:
@@ -360,7 +391,40 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	collection, j := tsdb.NewSeriesCollection(points), 0
+	collection := tsdb.NewSeriesCollection(points)
+	dropInvalidSeries(collection)
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	// The versions are incremented even if the write fails, since some of
+	// the points may have been written.
+	if e.writeTracker != nil {
+		defer e.writeTracker.trackPoints(points)
+	}
+
+	// Convert the collection to values for adding to the WAL/Cache.
+	values, err := tsm1.CollectionToValues(collection)
+	if err != nil {
+		return err
+	}
+
+	// Add the write to the WAL to be replayed if there is a crash or shutdown.
+	if _, err := e.wal.WriteMulti(ctx, values); err != nil {
+		return err
+	}
+
+	return e.writePointsLocked(ctx, collection, values)
+}
+
+// dropInvalidSeries removes the points with invalid series keys from collection,
+// recording the reason they were dropped.
+func dropInvalidSeries(collection *tsdb.SeriesCollection) {
+	j := 0
 
 	// dropPoint should be called whenever there is reason to drop a point from
 	// the batch.
@@ -418,26 +482,6 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 		j++
 	}
 	collection.Truncate(j)
-
-	e.mu.RLock()
-	defer e.mu.RUnlock()
-
-	if e.closing == nil {
-		return ErrEngineClosed
-	}
-
-	// Convert the collection to values for adding to the WAL/Cache.
-	values, err := tsm1.CollectionToValues(collection)
-	if err != nil {
-		return err
-	}
-
-	// Add the write to the WAL to be replayed if there is a crash or shutdown.
-	if _, err := e.wal.WriteMulti(ctx, values); err != nil {
-		return err
-	}
-
-	return e.writePointsLocked(ctx, collection, values)
 }
 
 // writePointsLocked does the work of writing points and must be called under some sort of lock.
@@ -469,6 +513,13 @@ func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesC
 		return err
 	}
 
+	// Send the series to any index partitions being rebuilt. This must happen
+	// after the values are in the cache so that a rebuild either receives the
+	// series here or finds it when it reads the keys held by the engine.
+	if err := e.addToIndexRebuilds(collection); err != nil {
+		return err
+	}
+
 	return collection.PartialWriteError()
 }
 
@@ -519,6 +570,10 @@ func (e *Engine) DeleteBucketRange(orgID, bucketID platform.ID, min, max int64)
 		return ErrEngineClosed
 	}
 
+	if e.writeTracker != nil {
+		defer e.writeTracker.track(bucketID)
+	}
+
 	// Add the delete to the WAL to be replayed if there is a crash or shutdown.
 	if _, err := e.wal.DeleteBucketRange(orgID, bucketID, min, max); err != nil {
 		return err
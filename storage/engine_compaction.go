@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// CompactionService describes the ability to inspect and control the
+// compaction of TSM files within a storage engine.
+type CompactionService interface {
+	// CompactionStatus returns the running and planned compactions along
+	// with the current compaction settings.
+	CompactionStatus(ctx context.Context) (*tsm1.CompactionStatus, error)
+
+	// PauseCompactions stops all level, optimize and full compactions until
+	// ResumeCompactions is called.
+	PauseCompactions(ctx context.Context) error
+
+	// ResumeCompactions restarts compactions stopped by PauseCompactions.
+	ResumeCompactions(ctx context.Context) error
+
+	// ScheduleFullCompaction forces a full compaction of all data.
+	ScheduleFullCompaction(ctx context.Context) error
+
+	// UpdateCompactions changes the compaction settings without a restart.
+	UpdateCompactions(ctx context.Context, upd CompactionUpdate) (*tsm1.CompactionStatus, error)
+}
+
+// CompactionUpdate describes changes to the compaction settings of an engine.
+// Only non-nil fields are applied.
+type CompactionUpdate struct {
+	Throughput      *int `json:"throughput,omitempty"`
+	ThroughputBurst *int `json:"throughputBurst,omitempty"`
+	MaxConcurrent   *int `json:"maxConcurrent,omitempty"`
+}
+
+var _ CompactionService = (*Engine)(nil)
+
+// CompactionStatus returns the running and planned compactions along with the
+// current compaction settings of the engine.
+func (e *Engine) CompactionStatus(ctx context.Context) (*tsm1.CompactionStatus, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	status := e.engine.CompactionStatus()
+	return &status, nil
+}
+
+// PauseCompactions stops all level, optimize and full compactions. Cache
+// snapshots continue to run.
+func (e *Engine) PauseCompactions(ctx context.Context) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	e.engine.PauseCompactions()
+	return nil
+}
+
+// ResumeCompactions restarts compactions stopped by PauseCompactions.
+func (e *Engine) ResumeCompactions(ctx context.Context) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	e.engine.ResumeCompactions()
+	return nil
+}
+
+// ScheduleFullCompaction snapshots the cache and forces a full compaction of
+// all TSM files. If compactions are paused the full compaction will run once
+// they are resumed.
+func (e *Engine) ScheduleFullCompaction(ctx context.Context) error {
+	// The lock must not be held while scheduling as snapshotting the cache
+	// acquires the WAL segments under the engine's write lock.
+	e.mu.RLock()
+	closed := e.closing == nil
+	e.mu.RUnlock()
+	if closed {
+		return ErrEngineClosed
+	}
+
+	return e.engine.ScheduleFullCompaction(ctx)
+}
+
+// UpdateCompactions changes the compaction throughput and concurrency of the
+// engine without a restart.
+func (e *Engine) UpdateCompactions(ctx context.Context, upd CompactionUpdate) (*tsm1.CompactionStatus, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	if upd.Throughput != nil || upd.ThroughputBurst != nil {
+		status := e.engine.CompactionStatus()
+		throughput, burst := status.Throughput, status.ThroughputBurst
+		if upd.Throughput != nil {
+			throughput = *upd.Throughput
+		}
+		if upd.ThroughputBurst != nil {
+			burst = *upd.ThroughputBurst
+		}
+		if err := e.engine.SetCompactionThroughput(throughput, burst); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "storage/UpdateCompactions",
+				Err:  err,
+			}
+		}
+	}
+
+	if upd.MaxConcurrent != nil {
+		if err := e.engine.SetMaxConcurrentCompactions(*upd.MaxConcurrent); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Op:   "storage/UpdateCompactions",
+				Err:  err,
+			}
+		}
+	}
+
+	status := e.engine.CompactionStatus()
+	return &status, nil
+}
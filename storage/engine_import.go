@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+// importBatchSize is the size of the line protocol parsed at a time by Import.
+const importBatchSize = 4 * 1024 * 1024
+
+// ImportService describes the ability to bulk import data into a bucket,
+// bypassing the write path.
+type ImportService interface {
+	// Import writes the line protocol read from r directly to new TSM files
+	// for the bucket, returning the number of points imported. The data is
+	// only visible once every point has been imported.
+	Import(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) (int, error)
+}
+
+var _ ImportService = (*Engine)(nil)
+
+// Import writes the line protocol read from r to new TSM files, creating any
+// new series in the series file and index as it goes, then adds all the files
+// to the engine at once. Points with timestamps matching existing points in
+// TSM files replace them. Points without a timestamp are given the time at
+// which Import was called.
+//
+// If any point cannot be imported, no data is added to the engine. Series
+// created before the failure remain in the index.
+func (e *Engine) Import(ctx context.Context, orgID, bucketID influxdb.ID, r io.Reader) (int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	if e.closing == nil {
+		e.mu.RUnlock()
+		return 0, ErrEngineClosed
+	}
+	importer := e.engine.NewImporter()
+	e.mu.RUnlock()
+
+	n, err := e.importPoints(ctx, importer, orgID, bucketID, r)
+	if err != nil {
+		importer.Abort()
+		return 0, err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		importer.Abort()
+		return 0, ErrEngineClosed
+	}
+	// The version is incremented even if the commit fails, since some of
+	// the files may have been added.
+	if e.writeTracker != nil {
+		defer e.writeTracker.track(bucketID)
+	}
+	if err := importer.Commit(); err != nil {
+		return 0, err
+	}
+
+	e.logger.Info("Imported points",
+		zap.String("org_id", orgID.String()),
+		zap.String("bucket_id", bucketID.String()),
+		zap.Int("points", n))
+	return n, nil
+}
+
+// importPoints writes the line protocol read from r to importer in batches.
+func (e *Engine) importPoints(ctx context.Context, importer *tsm1.Importer, orgID, bucketID influxdb.ID, r io.Reader) (int, error) {
+	now := time.Now().UTC()
+	br := bufio.NewReader(r)
+
+	var n int
+	var batch []byte
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		batch = append(batch, line...)
+
+		if len(batch) >= importBatchSize || (err == io.EOF && len(batch) > 0) {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+
+			points, perr := models.ParsePointsWithPrecision(batch, now, "n")
+			if perr != nil {
+				return 0, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Op:   "storage/Import",
+					Msg:  "unable to parse points",
+					Err:  perr,
+				}
+			}
+			if err := e.importBatch(importer, orgID, bucketID, points); err != nil {
+				return 0, err
+			}
+			n += len(points)
+			batch = batch[:0]
+		}
+
+		if err == io.EOF {
+			return n, nil
+		}
+	}
+}
+
+// importBatch creates the series of points and writes their values to importer.
+func (e *Engine) importBatch(importer *tsm1.Importer, orgID, bucketID influxdb.ID, points []models.Point) error {
+	points, err := tsdb.ExplodePoints(orgID, bucketID, points)
+	if err != nil {
+		return err
+	}
+
+	collection := tsdb.NewSeriesCollection(points)
+	dropInvalidSeries(collection)
+	if err := collection.PartialWriteError(); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "storage/Import",
+			Err:  err,
+		}
+	}
+
+	values, err := func() (map[string][]tsm1.Value, error) {
+		e.mu.RLock()
+		defer e.mu.RUnlock()
+
+		if e.closing == nil {
+			return nil, ErrEngineClosed
+		}
+
+		if err := e.index.CreateSeriesListIfNotExists(collection); err != nil {
+			return nil, err
+		}
+		if err := collection.PartialWriteError(); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EConflict,
+				Op:   "storage/Import",
+				Err:  err,
+			}
+		}
+		if err := e.addToIndexRebuilds(collection); err != nil {
+			return nil, err
+		}
+		return tsm1.CollectionToValues(collection)
+	}()
+	if err != nil {
+		return err
+	}
+
+	return importer.Write(values)
+}
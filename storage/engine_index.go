@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsi1"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+// indexRebuildBatchSize is the number of series added to rebuilt index
+// partitions at a time.
+const indexRebuildBatchSize = 10000
+
+// IndexService describes the ability to repair the index of a storage engine
+// without taking it offline.
+type IndexService interface {
+	// RebuildIndex rebuilds the index partitions with the provided ids from
+	// the stored series data. If no ids are provided every partition is rebuilt.
+	RebuildIndex(ctx context.Context, partitions []int) error
+}
+
+var _ IndexService = (*Engine)(nil)
+
+// RebuildIndex rebuilds the index partitions with the provided ids, or every
+// partition if none are provided, from the series keys held in the cache and
+// TSM files of the engine.
+//
+// Each partition is built alongside the existing one while the engine
+// continues to serve reads and writes. Series created by writes during the
+// rebuild are added to the rebuilt partitions too. Writes are only blocked
+// while the rebuilt partitions are swapped into place.
+func (e *Engine) RebuildIndex(ctx context.Context, partitions []int) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	if e.closing == nil {
+		e.mu.RUnlock()
+		return ErrEngineClosed
+	}
+
+	if len(partitions) == 0 {
+		for i := 0; i < int(e.index.PartitionN); i++ {
+			partitions = append(partitions, i)
+		}
+	}
+
+	rebuilds := make([]*tsi1.PartitionRebuild, 0, len(partitions))
+	for _, id := range partitions {
+		r, err := e.index.RebuildPartition(id)
+		if err != nil {
+			e.mu.RUnlock()
+			abortIndexRebuilds(rebuilds)
+			return err
+		}
+		rebuilds = append(rebuilds, r)
+	}
+
+	// Writes that complete after the rebuilds are registered send their series
+	// to the rebuilds. The keys of all writes completed before then are in the
+	// key snapshot, as holding the lock prevents the cache being snapshotted
+	// while it is acquired.
+	e.rebuildMu.Lock()
+	e.indexRebuilds = append(e.indexRebuilds, rebuilds...)
+	e.rebuildMu.Unlock()
+
+	keys := e.engine.AcquireKeySnapshot()
+	e.mu.RUnlock()
+
+	e.logger.Info("Rebuilding index", zap.Ints("partitions", partitions))
+	err := e.buildIndexRebuilds(ctx, keys, rebuilds)
+	keys.Release()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.removeIndexRebuilds(rebuilds)
+
+	if err == nil && e.closing == nil {
+		err = ErrEngineClosed
+	}
+	if err != nil {
+		abortIndexRebuilds(rebuilds)
+		return err
+	}
+
+	for i, r := range rebuilds {
+		if err := r.Commit(); err != nil {
+			abortIndexRebuilds(rebuilds[i+1:])
+			return fmt.Errorf("failed to replace index partition %d: %v", r.ID(), err)
+		}
+	}
+
+	e.logger.Info("Rebuilt index", zap.Ints("partitions", partitions))
+	return nil
+}
+
+// buildIndexRebuilds adds every series in keys to the rebuilds.
+func (e *Engine) buildIndexRebuilds(ctx context.Context, keys *tsm1.KeySnapshot, rebuilds []*tsi1.PartitionRebuild) error {
+	collection := &tsdb.SeriesCollection{
+		Keys:  make([][]byte, 0, indexRebuildBatchSize),
+		Names: make([][]byte, 0, indexRebuildBatchSize),
+		Tags:  make([]models.Tags, 0, indexRebuildBatchSize),
+		Types: make([]models.FieldType, 0, indexRebuildBatchSize),
+	}
+
+	flush := func() error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := e.sfile.CreateSeriesListIfNotExists(collection); err != nil {
+			return err
+		}
+		for _, r := range rebuilds {
+			if err := r.AddSeriesList(collection); err != nil {
+				return err
+			}
+		}
+		collection.Truncate(0)
+		return nil
+	}
+
+	var last []byte
+	if err := keys.Walk(func(key []byte, typ models.FieldType) error {
+		seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+		if bytes.Equal(seriesKey, last) {
+			return nil // Another field of the same series.
+		}
+		last = append(last[:0], seriesKey...)
+
+		seriesKey = append([]byte(nil), seriesKey...)
+		name, tags := models.ParseKeyBytes(seriesKey)
+		collection.Keys = append(collection.Keys, seriesKey)
+		collection.Names = append(collection.Names, name)
+		collection.Tags = append(collection.Tags, tags)
+		collection.Types = append(collection.Types, typ)
+
+		if collection.Length() < indexRebuildBatchSize {
+			return nil
+		}
+		return flush()
+	}); err != nil {
+		return err
+	}
+
+	if collection.Length() > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// addToIndexRebuilds adds the series in collection, which have been written to
+// the index, to any index partitions being rebuilt.
+func (e *Engine) addToIndexRebuilds(collection *tsdb.SeriesCollection) error {
+	e.rebuildMu.RLock()
+	defer e.rebuildMu.RUnlock()
+
+	for _, r := range e.indexRebuilds {
+		if err := r.AddSeriesList(collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeIndexRebuilds stops writes being sent to the provided rebuilds.
+func (e *Engine) removeIndexRebuilds(rebuilds []*tsi1.PartitionRebuild) {
+	e.rebuildMu.Lock()
+	defer e.rebuildMu.Unlock()
+
+	other := e.indexRebuilds[:0]
+	for _, r := range e.indexRebuilds {
+		var found bool
+		for _, rr := range rebuilds {
+			if r == rr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			other = append(other, r)
+		}
+	}
+	e.indexRebuilds = other
+}
+
+// abortIndexRebuilds discards the provided rebuilds.
+func abortIndexRebuilds(rebuilds []*tsi1.PartitionRebuild) {
+	for _, r := range rebuilds {
+		r.Abort()
+	}
+}
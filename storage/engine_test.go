@@ -1,11 +1,15 @@
 package storage_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -319,6 +323,143 @@ func TestEngine_WriteConflictingBatch(t *testing.T) {
 	}
 }
 
+func TestEngine_RebuildIndex(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	var points []models.Point
+	for i := 0; i < 100; i++ {
+		points = append(points, models.MustNewPoint(
+			"cpu",
+			models.NewTags(map[string]string{"host": fmt.Sprintf("server%d", i)}),
+			map[string]interface{}{"value": 1.0, "other": 2.0},
+			time.Unix(int64(i), 0),
+		))
+	}
+	if err := engine.Write1xPoints(points); err != nil {
+		t.Fatal(err)
+	}
+
+	verify := func() *storage.IndexVerification {
+		t.Helper()
+		v := &storage.VerifyIndex{Stdout: ioutil.Discard, Path: engine.path, Config: storage.NewConfig()}
+		result, err := v.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// Move the data out of the WAL so that reopening the engine does not
+	// recreate the series, then remove one partition of the index while the
+	// engine is stopped.
+	if err := engine.ScheduleFullCompaction(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	engine.Engine.Close()
+	if result := verify(); len(result.Inconsistencies) != 0 {
+		t.Fatalf("got inconsistencies %v, expected none", result.Inconsistencies)
+	}
+	if err := os.RemoveAll(filepath.Join(storage.NewConfig().GetIndexPath(engine.path), "3")); err != nil {
+		t.Fatal(err)
+	}
+
+	result := verify()
+	if len(result.Inconsistencies) == 0 {
+		t.Fatal("expected inconsistencies after removing an index partition")
+	}
+	for _, inc := range result.Inconsistencies {
+		if inc.Kind != storage.IndexMissingSeries || inc.Partition != 3 {
+			t.Fatalf("got inconsistency %+v, expected series missing from partition 3", inc)
+		}
+	}
+
+	// Rebuild the partition while the engine is running.
+	engine.MustOpen()
+	if got, exp := engine.SeriesCardinality(), int64(200-len(result.Inconsistencies)); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+	if err := engine.RebuildIndex(context.Background(), result.Partitions()); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(200); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// Writes continue to be indexed after a rebuild.
+	pt := models.MustNewPoint(
+		"mem",
+		models.NewTags(map[string]string{"host": "server"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+	if err := engine.Write1xPoints([]models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.RebuildIndex(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(201); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	engine.Engine.Close()
+	if result := verify(); len(result.Inconsistencies) != 0 {
+		t.Fatalf("got inconsistencies %v, expected none", result.Inconsistencies)
+	}
+}
+
+func TestEngine_Import(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	if err := engine.Write1xPoints([]models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.0}, time.Unix(0, 10)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := engine.Import(context.Background(), engine.org, engine.bucket, strings.NewReader(
+		"cpu,host=a value=2 20\ncpu,host=b value=3 20\nmem free=1i 5",
+	))
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("got %d points imported, exp 3", n)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(3); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// A conflicting field type fails the import without adding any data.
+	if _, err := engine.Import(context.Background(), engine.org, engine.bucket, strings.NewReader(
+		"disk used=1 10\ncpu,host=a value=\"x\" 30",
+	)); err == nil {
+		t.Fatal("expected field type conflict")
+	}
+
+	engine.Engine.Close()
+
+	var buf bytes.Buffer
+	export := storage.NewExport(engine.path)
+	export.Out = &buf
+	if err := export.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")[1:]
+	if got, exp := lines, []string{
+		"cpu,host=a value=2 20",
+		"cpu,host=b value=3 20",
+		"mem free=1i 5",
+		"cpu,host=a value=1 10",
+	}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got data %q, exp %q", got, exp)
+	}
+}
+
 func BenchmarkDeleteBucket(b *testing.B) {
 	var engine *Engine
 	setup := func(card int) {
@@ -370,10 +511,10 @@ type Engine struct {
 }
 
 // NewEngine create a new wrapper around a storage engine.
-func NewEngine(c storage.Config) *Engine {
+func NewEngine(c storage.Config, options ...storage.Option) *Engine {
 	path, _ := ioutil.TempDir("", "storage_engine_test")
 
-	engine := storage.NewEngine(path, c)
+	engine := storage.NewEngine(path, c, options...)
 
 	org, err := influxdb.IDFromString("3131313131313131")
 	if err != nil {
@@ -0,0 +1,560 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/escape"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// The formats supported by Export.
+const (
+	ExportLineProtocol = "lp"
+	ExportCSV          = "csv"
+)
+
+// Export writes the data stored in the TSM and WAL files of a storage engine
+// that is not running as line protocol or annotated CSV.
+//
+// Data is streamed a block at a time, first from the TSM files in key order
+// and then from the WAL in the order it was written, so a series may appear
+// more than once in the output. Replaying the output in order restores the
+// data exactly. Bucket deletes recorded in the WAL are applied.
+type Export struct {
+	Out io.Writer
+
+	Path   string // Root path of the storage engine.
+	Config Config // Determines the location of the engine's files.
+
+	OrgID, BucketID *influxdb.ID // Export only data belonging to the provided org or bucket id.
+	Measurement     string       // Export only data belonging to the measurement.
+	Start, End      int64        // Export only data within the time range, inclusive.
+
+	Format   string // ExportLineProtocol or ExportCSV.
+	Compress bool   // Compress the output with gzip.
+
+	// Keyring holds the keys of encrypted TSM and WAL files.
+	Keyring *encryption.Keyring
+}
+
+// NewExport returns an Export of all the data of the engine at path.
+func NewExport(path string) *Export {
+	return &Export{
+		Path:   path,
+		Config: NewConfig(),
+		Start:  math.MinInt64,
+		End:    math.MaxInt64,
+		Format: ExportLineProtocol,
+	}
+}
+
+// exportDelete is a bucket delete recorded in the WAL.
+type exportDelete struct {
+	entry wal.DeleteBucketRangeWALEntry
+	seq   int // The position of the delete within the WAL.
+}
+
+// Run writes the data of the engine to Out.
+func (e *Export) Run() (err error) {
+	if e.Out == nil {
+		e.Out = os.Stdout
+	}
+
+	var enc exportEncoder
+	switch e.Format {
+	case ExportLineProtocol:
+		enc = &lineProtocolEncoder{}
+	case ExportCSV:
+		enc = &csvEncoder{}
+	default:
+		return fmt.Errorf("unsupported export format %q", e.Format)
+	}
+
+	out := e.Out
+	if e.Compress {
+		gz := gzip.NewWriter(out)
+		defer func() {
+			if cerr := gz.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		out = gz
+	}
+
+	bw := bufio.NewWriterSize(out, 1<<20)
+	enc.reset(bw)
+
+	segments, err := wal.SegmentFileNames(e.Config.GetWALPath(e.Path))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	deletes, err := e.readDeletes(segments)
+	if err != nil {
+		return err
+	}
+
+	if err := e.exportTSM(enc, deletes); err != nil {
+		return err
+	}
+	if err := e.exportWAL(enc, segments, deletes); err != nil {
+		return err
+	}
+
+	if err := enc.flush(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// readDeletes returns the bucket deletes recorded in the WAL segments.
+func (e *Export) readDeletes(segments []string) ([]exportDelete, error) {
+	var deletes []exportDelete
+	var seq int
+	reader := wal.NewWALReader(segments)
+	reader.WithKeyring(e.Keyring)
+	err := reader.Read(func(entry wal.WALEntry) error {
+		if en, ok := entry.(*wal.DeleteBucketRangeWALEntry); ok {
+			deletes = append(deletes, exportDelete{entry: *en, seq: seq})
+		}
+		seq++
+		return nil
+	})
+	return deletes, err
+}
+
+// exportTSM writes the data in the engine's TSM files.
+func (e *Export) exportTSM(enc exportEncoder, deletes []exportDelete) error {
+	fs := tsm1.NewFileStore(e.Config.GetEnginePath(e.Path))
+	fs.WithKeyring(e.Keyring)
+	if err := fs.Open(context.Background()); err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	var s exportSeries
+	var buf []tsm1.Value
+	return fs.WalkKeys(nil, func(key []byte, typ byte) error {
+		if !e.parseSeries(&s, key) {
+			return nil
+		}
+		s.typ = typ
+
+		// Data in the TSM files precedes every delete in the WAL.
+		ranges := e.deletedRanges(&s, deletes, -1)
+
+		// Seeking to the minimum time would overflow when the cursor marks the
+		// values before the seek time as read.
+		seek := e.Start
+		if seek == math.MinInt64 {
+			seek++
+		}
+		c := fs.KeyCursor(context.Background(), key, seek, true)
+		defer c.Close()
+
+		for {
+			var err error
+			buf, err = readExportBlock(c, typ, buf[:0])
+			if err != nil {
+				return err
+			} else if len(buf) == 0 {
+				return nil
+			}
+
+			last := buf[len(buf)-1].UnixNano()
+			if values := e.filterValues(buf, ranges); len(values) > 0 {
+				if err := enc.write(&s, values); err != nil {
+					return err
+				}
+			}
+
+			if last >= e.End {
+				return nil
+			}
+			c.Next()
+		}
+	})
+}
+
+// exportWAL writes the data in the engine's WAL segments.
+func (e *Export) exportWAL(enc exportEncoder, segments []string, deletes []exportDelete) error {
+	var s exportSeries
+	var keys []string
+	var seq int
+	reader := wal.NewWALReader(segments)
+	reader.WithKeyring(e.Keyring)
+	return reader.Read(func(entry wal.WALEntry) error {
+		defer func() { seq++ }()
+
+		en, ok := entry.(*wal.WriteWALEntry)
+		if !ok {
+			return nil
+		}
+
+		keys = keys[:0]
+		for key := range en.Values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			values := en.Values[key]
+			if len(values) == 0 || !e.parseSeries(&s, []byte(key)) {
+				continue
+			}
+			s.typ = blockTypeOf(values[0])
+
+			values = e.filterValues(values, e.deletedRanges(&s, deletes, seq))
+			if len(values) == 0 {
+				continue
+			}
+			if err := enc.write(&s, values); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// parseSeries parses key into s, returning false if the series should not be
+// exported.
+func (e *Export) parseSeries(s *exportSeries, key []byte) bool {
+	if len(key) < 16 {
+		return false
+	}
+
+	var a [16]byte
+	copy(a[:], key[:16])
+	s.org, s.bucket = tsdb.DecodeName(a)
+	if e.OrgID != nil && *e.OrgID != s.org {
+		return false
+	} else if e.BucketID != nil && *e.BucketID != s.bucket {
+		return false
+	}
+
+	seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey(key)
+	_, s.allTags = models.ParseKeyBytesWithTags(seriesKey, s.allTags)
+
+	s.measurement = s.allTags.Get(models.MeasurementTagKeyBytes)
+	if e.Measurement != "" && string(s.measurement) != e.Measurement {
+		return false
+	}
+
+	s.tags = s.tags[:0]
+	for _, t := range s.allTags {
+		if !bytes.Equal(t.Key, models.MeasurementTagKeyBytes) && !bytes.Equal(t.Key, models.FieldKeyTagKeyBytes) {
+			s.tags = append(s.tags, t)
+		}
+	}
+	s.field = field
+	return true
+}
+
+// deletedRanges returns the time ranges deleted from the series s by the
+// deletes recorded in the WAL after position seq.
+func (e *Export) deletedRanges(s *exportSeries, deletes []exportDelete, seq int) []tsm1.TimeRange {
+	var ranges []tsm1.TimeRange
+	for _, d := range deletes {
+		if d.seq > seq && d.entry.OrgID == s.org && d.entry.BucketID == s.bucket {
+			ranges = append(ranges, tsm1.TimeRange{Min: d.entry.Min, Max: d.entry.Max})
+		}
+	}
+	return ranges
+}
+
+// filterValues removes the values outside of the export's time range or within
+// any of the deleted ranges. The values are filtered in place.
+func (e *Export) filterValues(values []tsm1.Value, deleted []tsm1.TimeRange) []tsm1.Value {
+	n := 0
+	for _, v := range values {
+		ts := v.UnixNano()
+		if ts < e.Start || ts > e.End {
+			continue
+		}
+
+		var skip bool
+		for _, r := range deleted {
+			if ts >= r.Min && ts <= r.Max {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			values[n] = v
+			n++
+		}
+	}
+	return values[:n]
+}
+
+// readExportBlock appends the next block of values of type typ read by c to buf.
+func readExportBlock(c *tsm1.KeyCursor, typ byte, buf []tsm1.Value) ([]tsm1.Value, error) {
+	switch typ {
+	case tsm1.BlockFloat64:
+		var a []tsm1.FloatValue
+		values, err := c.ReadFloatBlock(&a)
+		for _, v := range values {
+			buf = append(buf, v)
+		}
+		return buf, err
+	case tsm1.BlockInteger:
+		var a []tsm1.IntegerValue
+		values, err := c.ReadIntegerBlock(&a)
+		for _, v := range values {
+			buf = append(buf, v)
+		}
+		return buf, err
+	case tsm1.BlockUnsigned:
+		var a []tsm1.UnsignedValue
+		values, err := c.ReadUnsignedBlock(&a)
+		for _, v := range values {
+			buf = append(buf, v)
+		}
+		return buf, err
+	case tsm1.BlockBoolean:
+		var a []tsm1.BooleanValue
+		values, err := c.ReadBooleanBlock(&a)
+		for _, v := range values {
+			buf = append(buf, v)
+		}
+		return buf, err
+	case tsm1.BlockString:
+		var a []tsm1.StringValue
+		values, err := c.ReadStringBlock(&a)
+		for _, v := range values {
+			buf = append(buf, v)
+		}
+		return buf, err
+	default:
+		return buf, fmt.Errorf("unknown block type: %d", typ)
+	}
+}
+
+// blockTypeOf returns the block type that stores values of the same type as v.
+func blockTypeOf(v tsm1.Value) byte {
+	switch v.(type) {
+	case tsm1.FloatValue:
+		return tsm1.BlockFloat64
+	case tsm1.IntegerValue:
+		return tsm1.BlockInteger
+	case tsm1.UnsignedValue:
+		return tsm1.BlockUnsigned
+	case tsm1.BooleanValue:
+		return tsm1.BlockBoolean
+	case tsm1.StringValue:
+		return tsm1.BlockString
+	default:
+		return 0
+	}
+}
+
+// exportSeries is the series of the values being exported.
+type exportSeries struct {
+	org, bucket influxdb.ID
+	measurement []byte
+	tags        models.Tags // Tags excluding the measurement and field tags.
+	field       []byte
+	typ         byte
+
+	allTags models.Tags
+}
+
+// exportEncoder writes values in an export format.
+type exportEncoder interface {
+	reset(w io.Writer)
+	write(s *exportSeries, values []tsm1.Value) error
+	flush() error
+}
+
+// lineProtocolEncoder writes values as line protocol. The org and bucket of
+// the values that follow are written as a comment whenever they change.
+type lineProtocolEncoder struct {
+	w           io.Writer
+	buf, prefix []byte
+	org, bucket influxdb.ID
+}
+
+func (enc *lineProtocolEncoder) reset(w io.Writer) { enc.w = w }
+
+func (enc *lineProtocolEncoder) write(s *exportSeries, values []tsm1.Value) error {
+	buf := enc.buf[:0]
+	if s.org != enc.org || s.bucket != enc.bucket {
+		enc.org, enc.bucket = s.org, s.bucket
+		buf = append(buf, "# org_id="...)
+		buf = append(buf, s.org.String()...)
+		buf = append(buf, " bucket_id="...)
+		buf = append(buf, s.bucket.String()...)
+		buf = append(buf, '\n')
+	}
+
+	prefix := append(enc.prefix[:0], models.EscapeMeasurement(s.measurement)...)
+	prefix = s.tags.AppendHashKey(prefix)
+	prefix = append(prefix, ' ')
+	prefix = append(prefix, escape.Bytes(s.field)...)
+	prefix = append(prefix, '=')
+
+	for _, v := range values {
+		buf = append(buf, prefix...)
+		switch v := v.(type) {
+		case tsm1.FloatValue:
+			buf = strconv.AppendFloat(buf, v.RawValue(), 'g', -1, 64)
+		case tsm1.IntegerValue:
+			buf = strconv.AppendInt(buf, v.RawValue(), 10)
+			buf = append(buf, 'i')
+		case tsm1.UnsignedValue:
+			buf = strconv.AppendUint(buf, v.RawValue(), 10)
+			buf = append(buf, 'u')
+		case tsm1.BooleanValue:
+			buf = strconv.AppendBool(buf, v.RawValue())
+		case tsm1.StringValue:
+			buf = append(buf, '"')
+			buf = append(buf, models.EscapeStringField(v.RawValue())...)
+			buf = append(buf, '"')
+		default:
+			return fmt.Errorf("unknown value type %T", v)
+		}
+		buf = append(buf, ' ')
+		buf = strconv.AppendInt(buf, v.UnixNano(), 10)
+		buf = append(buf, '\n')
+	}
+
+	enc.buf, enc.prefix = buf, prefix
+	_, err := enc.w.Write(buf)
+	return err
+}
+
+func (enc *lineProtocolEncoder) flush() error { return nil }
+
+// csvEncoder writes values as annotated CSV with a table for each block of
+// values. The annotations are written whenever the columns of a table differ
+// from those of the previous table.
+type csvEncoder struct {
+	w     *csv.Writer
+	table int64
+
+	started bool
+	typ     byte
+	tagKeys [][]byte
+
+	row []string
+}
+
+func (enc *csvEncoder) reset(w io.Writer) { enc.w = csv.NewWriter(w) }
+
+func (enc *csvEncoder) write(s *exportSeries, values []tsm1.Value) error {
+	if !enc.started || enc.typ != s.typ || !sameTagKeys(enc.tagKeys, s.tags) {
+		if err := enc.writeHeader(s); err != nil {
+			return err
+		}
+	}
+
+	row := append(enc.row[:0], "", "", strconv.FormatInt(enc.table, 10), "", "", string(s.field), string(s.measurement))
+	for _, t := range s.tags {
+		row = append(row, string(t.Value))
+	}
+	enc.row = row
+
+	for _, v := range values {
+		row[3] = time.Unix(0, v.UnixNano()).UTC().Format(time.RFC3339Nano)
+		switch v := v.(type) {
+		case tsm1.FloatValue:
+			row[4] = strconv.FormatFloat(v.RawValue(), 'f', -1, 64)
+		case tsm1.IntegerValue:
+			row[4] = strconv.FormatInt(v.RawValue(), 10)
+		case tsm1.UnsignedValue:
+			row[4] = strconv.FormatUint(v.RawValue(), 10)
+		case tsm1.BooleanValue:
+			row[4] = strconv.FormatBool(v.RawValue())
+		case tsm1.StringValue:
+			row[4] = v.RawValue()
+		default:
+			return fmt.Errorf("unknown value type %T", v)
+		}
+		if err := enc.w.Write(row); err != nil {
+			return err
+		}
+	}
+
+	enc.table++
+	return enc.w.Error()
+}
+
+// writeHeader writes the annotations and column names of tables of values in
+// series s.
+func (enc *csvEncoder) writeHeader(s *exportSeries) error {
+	if enc.started {
+		// A blank line separates tables with different columns.
+		enc.w.Flush()
+		if err := enc.w.Write(nil); err != nil {
+			return err
+		}
+	}
+	enc.started = true
+	enc.typ = s.typ
+	enc.tagKeys = enc.tagKeys[:0]
+	for _, t := range s.tags {
+		enc.tagKeys = append(enc.tagKeys, append([]byte(nil), t.Key...))
+	}
+
+	datatype := []string{"#datatype", "string", "long", "dateTime:RFC3339", csvDataType(s.typ), "string", "string"}
+	group := []string{"#group", "false", "false", "false", "false", "true", "true"}
+	def := []string{"#default", "_result", "", "", "", "", ""}
+	columns := []string{"", "result", "table", "_time", "_value", "_field", "_measurement"}
+	for _, k := range enc.tagKeys {
+		datatype = append(datatype, "string")
+		group = append(group, "true")
+		def = append(def, "")
+		columns = append(columns, string(k))
+	}
+	return enc.w.WriteAll([][]string{datatype, group, def, columns})
+}
+
+func (enc *csvEncoder) flush() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+// csvDataType returns the annotated CSV data type of values in blocks of typ.
+func csvDataType(typ byte) string {
+	switch typ {
+	case tsm1.BlockFloat64:
+		return "double"
+	case tsm1.BlockInteger:
+		return "long"
+	case tsm1.BlockUnsigned:
+		return "unsignedLong"
+	case tsm1.BlockBoolean:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// sameTagKeys returns true if tags has the keys in keys.
+func sameTagKeys(keys [][]byte, tags models.Tags) bool {
+	if len(keys) != len(tags) {
+		return false
+	}
+	for i := range keys {
+		if !bytes.Equal(keys[i], tags[i].Key) {
+			return false
+		}
+	}
+	return true
+}
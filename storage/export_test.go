@@ -0,0 +1,157 @@
+package storage_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/storage"
+)
+
+func TestExport(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	const otherBucket = "3333333333333333"
+
+	// Data in TSM files.
+	if err := engine.Write1xPoints([]models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a b"}), map[string]interface{}{"value": 1.5}, time.Unix(0, 10)),
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a b"}), map[string]interface{}{"value": 2.5}, time.Unix(0, 20)),
+		models.MustNewPoint("mem", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"free": int64(3)}, time.Unix(0, 10)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.ScheduleFullCompaction(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Data in the WAL, including data deleted from another bucket.
+	if err := engine.Write1xPoints([]models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a b"}), map[string]interface{}{"value": 3.5}, time.Unix(0, 30)),
+		models.MustNewPoint("disk", models.NewTags(map[string]string{}), map[string]interface{}{"path": `/a "b"`}, time.Unix(0, 30)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Write1xPointsWithOrgBucket([]models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "c"}), map[string]interface{}{"value": 1.0}, time.Unix(0, 10)),
+	}, engine.org.String(), otherBucket); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := influxdb.IDFromString(otherBucket)
+	if err := engine.DeleteBucketRange(engine.org, *other, 0, 100); err != nil {
+		t.Fatal(err)
+	}
+	engine.Engine.Close()
+
+	export := func(fn func(e *storage.Export)) string {
+		t.Helper()
+		var buf bytes.Buffer
+		e := storage.NewExport(engine.path)
+		e.Out = &buf
+		fn(e)
+		if err := e.Run(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	header := "# org_id=" + engine.org.String() + " bucket_id=" + engine.bucket.String() + "\n"
+	if got, exp := export(func(*storage.Export) {}), header+
+		"cpu,host=a\\ b value=1.5 10\n"+
+		"cpu,host=a\\ b value=2.5 20\n"+
+		"mem,host=a free=3i 10\n"+
+		"cpu,host=a\\ b value=3.5 30\n"+
+		"disk path=\"/a \\\"b\\\"\" 30\n"; got != exp {
+		t.Fatalf("unexpected line protocol:\ngot:\n%s\nexp:\n%s", got, exp)
+	}
+
+	if got, exp := export(func(e *storage.Export) {
+		e.Measurement = "cpu"
+		e.Start, e.End = 15, 30
+		e.Format = storage.ExportCSV
+	}), strings.Join([]string{
+		"#datatype,string,long,dateTime:RFC3339,double,string,string,string",
+		"#group,false,false,false,false,true,true,true",
+		"#default,_result,,,,,,",
+		",result,table,_time,_value,_field,_measurement,host",
+		",,0,1970-01-01T00:00:00.00000002Z,2.5,value,cpu,a b",
+		",,1,1970-01-01T00:00:00.00000003Z,3.5,value,cpu,a b",
+		"",
+	}, "\n"); got != exp {
+		t.Fatalf("unexpected CSV:\ngot:\n%s\nexp:\n%s", got, exp)
+	}
+
+	compressed := export(func(e *storage.Export) {
+		e.Measurement = "mem"
+		e.Compress = true
+	})
+	gz, err := gzip.NewReader(strings.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ioutil.ReadAll(gz); err != nil {
+		t.Fatal(err)
+	} else if exp := header + "mem,host=a free=3i 10\n"; string(got) != exp {
+		t.Fatalf("got %q, exp %q", got, exp)
+	}
+}
+
+func TestExport_Encrypted(t *testing.T) {
+	key, err := encryption.NewKey("k1", bytes.Repeat([]byte{1}, encryption.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kr, err := encryption.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewEngine(storage.NewConfig(), storage.WithKeyring(kr))
+	defer engine.Close()
+	engine.MustOpen()
+
+	// Data in TSM files and in the WAL.
+	if err := engine.Write1xPoints([]models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 1.5}, time.Unix(0, 10)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.ScheduleFullCompaction(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Write1xPoints([]models.Point{
+		models.MustNewPoint("cpu", models.NewTags(map[string]string{"host": "a"}), map[string]interface{}{"value": 2.5}, time.Unix(0, 20)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	engine.Engine.Close()
+
+	var buf bytes.Buffer
+	e := storage.NewExport(engine.path)
+	e.Out = &buf
+	e.Keyring = kr
+	if err := e.Run(); err != nil {
+		t.Fatal(err)
+	}
+	header := "# org_id=" + engine.org.String() + " bucket_id=" + engine.bucket.String() + "\n"
+	if got, exp := buf.String(), header+
+		"cpu,host=a value=1.5 10\n"+
+		"cpu,host=a value=2.5 20\n"; got != exp {
+		t.Fatalf("unexpected line protocol:\ngot:\n%s\nexp:\n%s", got, exp)
+	}
+
+	e = storage.NewExport(engine.path)
+	e.Out = ioutil.Discard
+	if err := e.Run(); err == nil {
+		t.Fatal("expected error exporting encrypted files without a keyring")
+	}
+}
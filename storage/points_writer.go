@@ -7,6 +7,9 @@ import (
 )
 
 // PointsWriter describes the ability to write points into a storage engine.
+//
+// How durable points are when WritePoints returns is chosen per write with
+// the consistency of the context; see wal.SetConsistency.
 type PointsWriter interface {
 	WritePoints(context.Context, []models.Point) error
 }
@@ -24,6 +24,11 @@ func newStore(engine *storage.Engine) *store {
 	return &store{engine: engine}
 }
 
+// NewStore returns a reads.Store that reads series from engine.
+func NewStore(engine *storage.Engine) reads.Store {
+	return newStore(engine)
+}
+
 func (s *store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
 	if req.ReadSource == nil {
 		return nil, errors.New("missing read source")
@@ -0,0 +1,284 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsi1"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// The kinds of inconsistency reported by VerifyIndex.
+const (
+	// IndexMissingFromSeriesFile is a series with TSM or WAL data that has no
+	// entry in the series file.
+	IndexMissingFromSeriesFile = "missing from series file"
+
+	// IndexMissingSeries is a series with TSM or WAL data that is in the series
+	// file but not in the index.
+	IndexMissingSeries = "missing from index"
+
+	// IndexMisplacedSeries is a series in an index partition other than the
+	// one its key belongs to.
+	IndexMisplacedSeries = "misplaced in index"
+
+	// IndexUnknownSeries is a series id in the index that has no live entry in
+	// the series file.
+	IndexUnknownSeries = "unknown to series file"
+
+	// IndexOrphanedSeries is a series in the index that has no TSM or WAL data.
+	IndexOrphanedSeries = "orphaned in index"
+)
+
+// IndexInconsistency describes a single series that differs between the
+// index, the series file and the TSM and WAL data of an engine.
+type IndexInconsistency struct {
+	Kind      string
+	Partition int // The index partition that must be rebuilt to fix the inconsistency.
+	SeriesID  tsdb.SeriesID
+	Key       string
+}
+
+// IndexVerification summarises the result of verifying an engine's index.
+type IndexVerification struct {
+	Series          int                  // Number of series with TSM or WAL data.
+	IndexedSeries   int                  // Number of series in the index.
+	Inconsistencies []IndexInconsistency // Every inconsistent series found.
+}
+
+// Partitions returns the sorted ids of the index partitions with
+// inconsistencies.
+func (v *IndexVerification) Partitions() []int {
+	seen := make(map[int]struct{})
+	var ids []int
+	for _, inc := range v.Inconsistencies {
+		if _, ok := seen[inc.Partition]; !ok {
+			seen[inc.Partition] = struct{}{}
+			ids = append(ids, inc.Partition)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// VerifyIndex cross-checks the TSI index, series file and series keys in the
+// TSM and WAL files of a storage engine that is not running.
+type VerifyIndex struct {
+	Stdout io.Writer
+
+	Path    string // Root path of the storage engine.
+	Config  Config // Determines the location of the engine's files.
+	Verbose bool   // Verbose prints every inconsistent series.
+
+	// Keyring holds the keys of encrypted TSM, WAL and index files.
+	Keyring *encryption.Keyring
+}
+
+// Run verifies the index and prints a summary of the inconsistencies found.
+func (v *VerifyIndex) Run() (*IndexVerification, error) {
+	if v.Stdout == nil {
+		v.Stdout = os.Stdout
+	}
+
+	if fi, err := os.Stat(v.Path); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, errors.New("engine path is not a directory")
+	}
+
+	keys, err := v.seriesKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	sfile := tsdb.NewSeriesFile(v.Config.GetSeriesFilePath(v.Path))
+	if err := sfile.Open(context.Background()); err != nil {
+		return nil, err
+	}
+	defer sfile.Close()
+
+	index := tsi1.NewIndex(sfile, v.Config.Index,
+		tsi1.WithPath(v.Config.GetIndexPath(v.Path)),
+		tsi1.DisableCompactions(),
+		tsi1.DisableMetrics(),
+	)
+	index.WithKeyring(v.Keyring)
+	if err := index.Open(context.Background()); err != nil {
+		return nil, err
+	}
+	defer index.Close()
+
+	sets := make([]*tsdb.SeriesIDSet, index.PartitionN)
+	for i := range sets {
+		sets[i] = index.PartitionAt(i).SeriesIDSet()
+	}
+
+	result := &IndexVerification{Series: len(keys)}
+	report := func(kind string, partition int, id tsdb.SeriesID, key string) {
+		result.Inconsistencies = append(result.Inconsistencies, IndexInconsistency{
+			Kind:      kind,
+			Partition: partition,
+			SeriesID:  id,
+			Key:       key,
+		})
+	}
+
+	// Every series with data must be in the series file and in the index
+	// partition its key belongs to.
+	stored := tsdb.NewSeriesIDSet()
+	var buf []byte
+	for key := range keys {
+		partition := index.PartitionIndex([]byte(key))
+		name, tags := models.ParseKeyBytes([]byte(key))
+		id := sfile.SeriesID(name, tags, buf)
+		if id.IsZero() {
+			report(IndexMissingFromSeriesFile, partition, id, key)
+			continue
+		}
+		stored.Add(id)
+
+		if !sets[partition].Contains(id) {
+			report(IndexMissingSeries, partition, id, key)
+		}
+	}
+
+	// Every series in the index must be in the series file, have data and be
+	// in the correct partition.
+	for partition, set := range sets {
+		result.IndexedSeries += int(set.Cardinality())
+		set.ForEach(func(id tsdb.SeriesID) {
+			skey := sfile.SeriesKey(id)
+			if skey == nil || sfile.IsDeleted(id) {
+				report(IndexUnknownSeries, partition, id, "")
+				return
+			}
+
+			key := string(models.MakeKey(tsdb.ParseSeriesKey(skey)))
+			if expected := index.PartitionIndex([]byte(key)); expected != partition {
+				report(IndexMisplacedSeries, partition, id, key)
+			} else if !stored.Contains(id) {
+				report(IndexOrphanedSeries, partition, id, key)
+			}
+		})
+	}
+
+	sort.SliceStable(result.Inconsistencies, func(i, j int) bool {
+		return result.Inconsistencies[i].Partition < result.Inconsistencies[j].Partition
+	})
+
+	v.print(result)
+	return result, nil
+}
+
+// seriesKeys returns the set of series keys with data in the engine's TSM and
+// WAL files. Deletes recorded in the WAL are not applied.
+func (v *VerifyIndex) seriesKeys() (map[string]struct{}, error) {
+	keys := make(map[string]struct{})
+	add := func(key []byte) {
+		seriesKey, _ := tsm1.SeriesAndFieldFromCompositeKey(key)
+		if _, ok := keys[string(seriesKey)]; !ok {
+			keys[string(seriesKey)] = struct{}{}
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(v.Config.GetEnginePath(v.Path), "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if err := func() error {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			r, err := tsm1.NewTSMReader(f, tsm1.WithKeyring(v.Keyring))
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			defer r.Close()
+
+			itr := r.Iterator(nil)
+			for itr.Next() {
+				add(itr.Key())
+			}
+			return itr.Err()
+		}(); err != nil {
+			return nil, err
+		}
+	}
+
+	segments, err := wal.SegmentFileNames(v.Config.GetWALPath(v.Path))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	reader := wal.NewWALReader(segments)
+	reader.WithKeyring(v.Keyring)
+	if err := reader.Read(func(entry wal.WALEntry) error {
+		if en, ok := entry.(*wal.WriteWALEntry); ok {
+			for key := range en.Values {
+				add([]byte(key))
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// print writes a summary of result to Stdout.
+func (v *VerifyIndex) print(result *IndexVerification) {
+	if v.Verbose {
+		tw := tabwriter.NewWriter(v.Stdout, 8, 2, 1, ' ', 0)
+		fmt.Fprintln(tw, strings.Join([]string{"Partition", "Problem", "Series ID", "Series Key"}, "\t"))
+		for _, inc := range result.Inconsistencies {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%q\n", inc.Partition, inc.Kind, inc.SeriesID.RawID(), inc.Key)
+		}
+		tw.Flush()
+		fmt.Fprintln(v.Stdout)
+	}
+
+	counts := make(map[string]int)
+	for _, inc := range result.Inconsistencies {
+		counts[inc.Kind]++
+	}
+
+	fmt.Fprintf(v.Stdout, "Series with data: %d\n", result.Series)
+	fmt.Fprintf(v.Stdout, "Series in index: %d\n", result.IndexedSeries)
+	for _, kind := range []string{
+		IndexMissingFromSeriesFile,
+		IndexMissingSeries,
+		IndexMisplacedSeries,
+		IndexUnknownSeries,
+		IndexOrphanedSeries,
+	} {
+		fmt.Fprintf(v.Stdout, "Series %s: %d\n", kind, counts[kind])
+	}
+
+	if len(result.Inconsistencies) == 0 {
+		fmt.Fprintln(v.Stdout, "Index is consistent.")
+		return
+	}
+
+	partitions := result.Partitions()
+	ids := make([]string, len(partitions))
+	for i, id := range partitions {
+		ids[i] = fmt.Sprint(id)
+	}
+	fmt.Fprintf(v.Stdout, "Inconsistent index partitions: %s\n", strings.Join(ids, ","))
+}
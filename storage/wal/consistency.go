@@ -0,0 +1,57 @@
+package wal
+
+import (
+	"context"
+	"fmt"
+)
+
+// Consistency is how durable a write to the WAL is when it is acknowledged.
+// Writes are fsynced eventually whatever their consistency; it only
+// changes what the writer waits for.
+type Consistency string
+
+const (
+	// ConsistencyBuffered acknowledges writes once they are buffered in
+	// memory by the WAL. They are lost if the process crashes before the
+	// next fsync.
+	ConsistencyBuffered Consistency = "buffered"
+
+	// ConsistencyWAL acknowledges writes once they are written to the WAL
+	// segment file. They survive the process crashing, but not the host.
+	ConsistencyWAL Consistency = "wal"
+
+	// ConsistencyFsync acknowledges writes once the WAL segment file has been
+	// fsynced. It is the default.
+	ConsistencyFsync Consistency = "fsync"
+)
+
+// ParseConsistency returns the consistency named s. An empty s is
+// ConsistencyFsync.
+func ParseConsistency(s string) (Consistency, error) {
+	switch c := Consistency(s); c {
+	case "":
+		return ConsistencyFsync, nil
+	case ConsistencyBuffered, ConsistencyWAL, ConsistencyFsync:
+		return c, nil
+	default:
+		return "", fmt.Errorf("invalid consistency %q; valid consistencies are buffered, wal, and fsync", s)
+	}
+}
+
+type contextKey int
+
+const consistencyContextKey contextKey = 0
+
+// SetConsistency returns a context that writes to the WAL with consistency c.
+func SetConsistency(ctx context.Context, c Consistency) context.Context {
+	return context.WithValue(ctx, consistencyContextKey, c)
+}
+
+// GetConsistency returns the consistency of writes with ctx, which is
+// ConsistencyFsync unless it was set with SetConsistency.
+func GetConsistency(ctx context.Context) Consistency {
+	if c, ok := ctx.Value(consistencyContextKey).(Consistency); ok && c != "" {
+		return c
+	}
+	return ConsistencyFsync
+}
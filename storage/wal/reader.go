@@ -4,14 +4,16 @@ import (
 	"os"
 	"sort"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"go.uber.org/zap"
 )
 
 // WALReader helps one read out the WAL into entries.
 type WALReader struct {
-	files  []string
-	logger *zap.Logger
-	r      *WALSegmentReader
+	files   []string
+	logger  *zap.Logger
+	r       *WALSegmentReader
+	keyring *encryption.Keyring
 }
 
 // NewWALReader constructs a WALReader over the given set of files.
@@ -27,10 +29,14 @@ func NewWALReader(files []string) *WALReader {
 // WithLogger sets the logger for the WALReader.
 func (r *WALReader) WithLogger(logger *zap.Logger) { r.logger = logger }
 
+// WithKeyring sets the keys encrypted entries are read with.
+func (r *WALReader) WithKeyring(kr *encryption.Keyring) { r.keyring = kr }
+
 // Read calls the callback with every entry in the WAL files. If, during
 // reading of a segment file, corruption is encountered, that segment file
 // is truncated up to and including the last valid byte, and processing
-// continues with the next segment file.
+// continues with the next segment file. An entry encrypted with a key that
+// is not available is returned as a *KeyError without truncating the file.
 func (r *WALReader) Read(cb func(WALEntry) error) error {
 	for _, file := range r.files {
 		if err := r.readFile(file, cb); err != nil {
@@ -64,11 +70,14 @@ func (r *WALReader) readFile(file string, cb func(WALEntry) error) error {
 	} else {
 		r.r.Reset(f)
 	}
+	r.r.WithKeyring(r.keyring)
 	defer r.r.Close()
 
 	for r.r.Next() {
 		entry, err := r.r.Read()
-		if err != nil {
+		if _, ok := err.(*KeyError); ok {
+			return err
+		} else if err != nil {
 			n := r.r.Count()
 			r.logger.Info("File corrupt", zap.Error(err), zap.String("path", file), zap.Int64("pos", n))
 			if err := f.Truncate(n); err != nil {
@@ -0,0 +1,107 @@
+package wal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+)
+
+// SegmentVerification is the result of verifying a single WAL segment file.
+type SegmentVerification struct {
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Entries int    `json:"entries"`
+
+	// ValidSize is the number of bytes at the start of the segment that hold
+	// valid entries. Error is set if the segment is corrupt after ValidSize.
+	ValidSize int64  `json:"validSize"`
+	Error     string `json:"error,omitempty"`
+
+	// Truncated is set when the segment was truncated to ValidSize.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Healthy returns true if every entry in the segment could be read.
+func (v *SegmentVerification) Healthy() bool { return v.Error == "" }
+
+// Verify reads every entry of a set of WAL segment files, reporting where any
+// corruption begins. The segments must not be in use by a running WAL.
+type Verify struct {
+	Paths []string
+
+	// Repair truncates corrupt segments after their last valid entry, as is
+	// done when a WAL is opened.
+	Repair bool
+
+	// Keyring holds the keys of encrypted entries.
+	Keyring *encryption.Keyring
+}
+
+// Run verifies each segment in Paths, truncating them if requested.
+func (v *Verify) Run() ([]*SegmentVerification, error) {
+	results := make([]*SegmentVerification, 0, len(v.Paths))
+	for _, path := range v.Paths {
+		result, err := v.verifySegment(path)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// verifySegment verifies and optionally truncates the segment at path.
+func (v *Verify) verifySegment(path string) (*SegmentVerification, error) {
+	flag := os.O_RDONLY
+	if v.Repair {
+		flag = os.O_RDWR
+	}
+
+	f, err := os.OpenFile(path, flag, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	result := &SegmentVerification{Path: path, Size: stat.Size()}
+
+	r := NewWALSegmentReader(f)
+	r.WithKeyring(v.Keyring)
+	for r.Next() {
+		if _, err := r.Read(); err != nil {
+			// A missing key is not corruption, so the segment must not be
+			// truncated.
+			if _, ok := err.(*KeyError); ok {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			result.Error = err.Error()
+			break
+		}
+		result.Entries++
+	}
+	result.ValidSize = r.Count()
+
+	// A segment can only be read to its end without error if every byte of it
+	// is valid.
+	if result.Error == "" && result.ValidSize != result.Size {
+		result.Error = "unexpected data after last entry"
+	}
+
+	if !v.Repair || result.Healthy() {
+		return result, nil
+	}
+
+	if err := f.Truncate(result.ValidSize); err != nil {
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+	result.Truncated = true
+	return result, nil
+}
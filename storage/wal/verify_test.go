@@ -0,0 +1,65 @@
+package wal
+
+import (
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb/value"
+)
+
+func TestVerify(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	f := MustTempFile(dir)
+	w := NewWALSegmentWriter(f)
+
+	entry := &WriteWALEntry{
+		Values: map[string][]value.Value{
+			"cpu,host=A#!~#value": []value.Value{value.NewValue(1, 1.1)},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if err := w.Write(mustMarshalEntry(entry)); err != nil {
+			fatal(t, "write points", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fatal(t, "flush", err)
+	}
+
+	valid := MustReadFileSize(f)
+
+	// Append a partially written entry.
+	if _, err := f.Write([]byte{byte(WriteWALEntryType), 0, 0, 1}); err != nil {
+		fatal(t, "write", err)
+	}
+	size := MustReadFileSize(f)
+
+	results, err := (&Verify{Paths: []string{f.Name()}}).Run()
+	if err != nil {
+		fatal(t, "verify", err)
+	}
+	r := results[0]
+	if r.Healthy() || r.Truncated {
+		t.Fatalf("got %+v, exp corrupt segment", r)
+	}
+	if r.Entries != 2 || r.Size != size || r.ValidSize != valid {
+		t.Fatalf("got entries=%d size=%d valid=%d, exp entries=2 size=%d valid=%d", r.Entries, r.Size, r.ValidSize, size, valid)
+	}
+
+	results, err = (&Verify{Paths: []string{f.Name()}, Repair: true}).Run()
+	if err != nil {
+		fatal(t, "repair", err)
+	}
+	if !results[0].Truncated {
+		t.Fatal("segment was not truncated")
+	}
+
+	results, err = (&Verify{Paths: []string{f.Name()}}).Run()
+	if err != nil {
+		fatal(t, "verify", err)
+	}
+	if r := results[0]; !r.Healthy() || r.Entries != 2 || r.Size != valid {
+		t.Fatalf("got %+v after repair, exp healthy segment of %d bytes", r, valid)
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/pool"
 	"github.com/influxdata/influxdb/tsdb/value"
@@ -63,6 +64,11 @@ const (
 
 	// DeleteBucketRangeWALEntryType indicates a delete bucket range entry.
 	DeleteBucketRangeWALEntryType WalEntryType = 0x04
+
+	// encryptedWALEntryFlag is set on the type of encrypted entries. Their
+	// data starts with the length prefixed id of their encryption key,
+	// followed by the compressed entry sealed with the key.
+	encryptedWALEntryFlag WalEntryType = 0x80
 )
 
 var (
@@ -83,6 +89,9 @@ type WAL struct {
 	// goroutines waiting for the next fsync
 	syncCount   uint64
 	syncWaiters chan chan error
+	// unsynced is set when entries that nobody waits for have been written
+	// since the last fsync. It is protected by mu.
+	unsynced bool
 
 	mu            sync.RWMutex
 	lastWriteTime time.Time
@@ -113,6 +122,9 @@ type WAL struct {
 	defaultMetricLabels prometheus.Labels // N.B this must not be mutated after Open is called.
 
 	limiter limiter.Fixed
+
+	// keyring encrypts new entries with its current key if set.
+	keyring *encryption.Keyring
 }
 
 // NewWAL initializes a new WAL at the given directory.
@@ -141,6 +153,12 @@ func (l *WAL) SetEnabled(enabled bool) {
 	l.enabled = enabled
 }
 
+// WithKeyring encrypts the entries written to the WAL with the current key of
+// kr, and should be called before the WAL is opened.
+func (l *WAL) WithKeyring(kr *encryption.Keyring) {
+	l.keyring = kr
+}
+
 // WithLogger sets the WAL's logger.
 func (l *WAL) WithLogger(log *zap.Logger) {
 	l.logger = log.With(zap.String("service", "wal"))
@@ -222,7 +240,7 @@ func (l *WAL) Open(ctx context.Context) error {
 			if _, err := fd.Seek(0, io.SeekEnd); err != nil {
 				return err
 			}
-			l.currentSegmentWriter = NewWALSegmentWriter(fd)
+			l.currentSegmentWriter = l.newSegmentWriter(fd)
 
 			// Reset the current segment size stat
 			l.tracker.SetCurrentSegmentSize(uint64(stat.Size()))
@@ -280,7 +298,7 @@ func (l *WAL) scheduleSync() {
 			select {
 			case <-timerCh:
 				l.mu.Lock()
-				if len(l.syncWaiters) == 0 {
+				if len(l.syncWaiters) == 0 && !l.unsynced {
 					atomic.StoreUint64(&l.syncCount, 0)
 					l.mu.Unlock()
 					return
@@ -300,6 +318,7 @@ func (l *WAL) scheduleSync() {
 // a write lock on the WAL is obtained before calling sync.
 func (l *WAL) sync() {
 	err := l.currentSegmentWriter.sync()
+	l.unsynced = false
 	for len(l.syncWaiters) > 0 {
 		errC := <-l.syncWaiters
 		errC <- err
@@ -309,6 +328,9 @@ func (l *WAL) sync() {
 // WriteMulti writes the given values to the WAL. It returns the WAL segment ID to
 // which the points were written. If an error is returned the segment ID should
 // be ignored. If the WAL is disabled, -1 and nil is returned.
+//
+// WriteMulti returns once the values are as durable as the consistency of ctx
+// requires; see SetConsistency.
 func (l *WAL) WriteMulti(ctx context.Context, values map[string][]value.Value) (int, error) {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
@@ -321,7 +343,7 @@ func (l *WAL) WriteMulti(ctx context.Context, values map[string][]value.Value) (
 		Values: values,
 	}
 
-	id, err := l.writeToLog(entry)
+	id, err := l.writeToLog(entry, GetConsistency(ctx))
 	if err != nil {
 		l.tracker.IncWritesErr()
 		return -1, err
@@ -417,7 +439,7 @@ func (l *WAL) DiskSizeBytes() int64 {
 	return int64(l.tracker.OldSegmentSize() + l.tracker.CurrentSegmentSize())
 }
 
-func (l *WAL) writeToLog(entry WALEntry) (int, error) {
+func (l *WAL) writeToLog(entry WALEntry, consistency Consistency) (int, error) {
 	// limit how many concurrent encodings can be in flight.  Since we can only
 	// write one at a time to disk, a slow disk can cause the allocations below
 	// to increase quickly.  If we're backed up, wait until others have completed.
@@ -457,10 +479,20 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 			return -1, fmt.Errorf("error writing WAL entry: %v", err)
 		}
 
-		select {
-		case l.syncWaiters <- syncErr:
+		switch consistency {
+		case ConsistencyBuffered:
+			l.unsynced = true
+		case ConsistencyWAL:
+			if err := l.currentSegmentWriter.Flush(); err != nil {
+				return -1, fmt.Errorf("error writing WAL entry: %v", err)
+			}
+			l.unsynced = true
 		default:
-			return -1, fmt.Errorf("error syncing wal")
+			select {
+			case l.syncWaiters <- syncErr:
+			default:
+				return -1, fmt.Errorf("error syncing wal")
+			}
 		}
 		l.scheduleSync()
 
@@ -474,11 +506,11 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 
 	bytesPool.Put(encBuf)
 
-	if err != nil {
+	if err != nil || consistency == ConsistencyBuffered || consistency == ConsistencyWAL {
 		return segID, err
 	}
 
-	// schedule an fsync and wait for it to complete
+	// wait for the scheduled fsync to complete
 	return segID, <-syncErr
 }
 
@@ -531,7 +563,7 @@ func (l *WAL) DeleteBucketRange(orgID, bucketID influxdb.ID, min, max int64) (in
 		Max:      max,
 	}
 
-	id, err := l.writeToLog(entry)
+	id, err := l.writeToLog(entry, ConsistencyFsync)
 	if err != nil {
 		return -1, err
 	}
@@ -593,7 +625,7 @@ func (l *WAL) newSegmentFile() error {
 	if err != nil {
 		return err
 	}
-	l.currentSegmentWriter = NewWALSegmentWriter(fd)
+	l.currentSegmentWriter = l.newSegmentWriter(fd)
 	l.tracker.IncSegments()
 
 	// Reset the current segment size stat
@@ -601,6 +633,16 @@ func (l *WAL) newSegmentFile() error {
 	return nil
 }
 
+// newSegmentWriter returns a segment writer to fd, encrypting entries if the
+// WAL is encrypted.
+func (l *WAL) newSegmentWriter(fd *os.File) *WALSegmentWriter {
+	w := NewWALSegmentWriter(fd)
+	if l.keyring != nil {
+		w.key = l.keyring.Current()
+	}
+	return w
+}
+
 // walTracker tracks writes to the WAL.
 //
 // As well as being responsible for providing atomic reads and writes to the
@@ -1061,6 +1103,10 @@ type WALSegmentWriter struct {
 	bw   *bufio.Writer
 	w    io.WriteCloser
 	size int
+
+	// key encrypts the entries if set.
+	key    *encryption.Key
+	sealed []byte
 }
 
 // NewWALSegmentWriter returns a new WALSegmentWriter writing to w.
@@ -1080,6 +1126,18 @@ func (w *WALSegmentWriter) path() string {
 
 // Write writes entryType and the buffer containing compressed entry data.
 func (w *WALSegmentWriter) Write(entryType WalEntryType, compressed []byte) error {
+	if w.key != nil {
+		entryType |= encryptedWALEntryFlag
+
+		var err error
+		w.sealed = encryption.AppendKeyID(w.sealed[:0], w.key.ID())
+		w.sealed, err = w.key.Seal(w.sealed, compressed, []byte{byte(entryType)})
+		if err != nil {
+			return err
+		}
+		compressed = w.sealed
+	}
+
 	var buf [5]byte
 	buf[0] = byte(entryType)
 	binary.BigEndian.PutUint32(buf[1:5], uint32(len(compressed)))
@@ -1128,6 +1186,20 @@ type WALSegmentReader struct {
 	entry WALEntry
 	n     int64
 	err   error
+
+	// keyring holds the keys of encrypted entries.
+	keyring *encryption.Keyring
+}
+
+// KeyError is returned when reading an entry encrypted with a key that is not
+// available. Unlike other errors, it does not mean that the segment is
+// corrupt.
+type KeyError struct {
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("cannot decrypt wal entry: %v", e.Err)
 }
 
 // NewWALSegmentReader returns a new WALSegmentReader reading from r.
@@ -1138,6 +1210,11 @@ func NewWALSegmentReader(r io.ReadCloser) *WALSegmentReader {
 	}
 }
 
+// WithKeyring sets the keys encrypted entries are read with.
+func (r *WALSegmentReader) WithKeyring(kr *encryption.Keyring) {
+	r.keyring = kr
+}
+
 func (r *WALSegmentReader) Reset(rc io.ReadCloser) {
 	r.rc = rc
 	r.r.Reset(rc)
@@ -1179,7 +1256,15 @@ func (r *WALSegmentReader) Next() bool {
 	}
 	nReadOK += n
 
-	decLen, err := snappy.DecodedLen(b[:length])
+	compressed := b[:length]
+	if WalEntryType(entryType)&encryptedWALEntryFlag != 0 {
+		if compressed, r.err = r.open(entryType, compressed); r.err != nil {
+			return true
+		}
+		entryType &^= byte(encryptedWALEntryFlag)
+	}
+
+	decLen, err := snappy.DecodedLen(compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -1187,7 +1272,7 @@ func (r *WALSegmentReader) Next() bool {
 	decBuf := *(getBuf(decLen))
 	defer putBuf(&decBuf)
 
-	data, err := snappy.Decode(decBuf, b[:length])
+	data, err := snappy.Decode(decBuf, compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -1214,6 +1299,22 @@ func (r *WALSegmentReader) Next() bool {
 	return true
 }
 
+// open decrypts the data of an encrypted entry.
+func (r *WALSegmentReader) open(entryType byte, b []byte) ([]byte, error) {
+	id, n, err := encryption.ReadKeyID(b)
+	if err != nil {
+		return nil, err
+	}
+	if r.keyring == nil {
+		return nil, &KeyError{Err: fmt.Errorf("entry is encrypted with key %s but no encryption keys are configured", id)}
+	}
+	key, err := r.keyring.Key(id)
+	if err != nil {
+		return nil, &KeyError{Err: err}
+	}
+	return key.Open(nil, b[n:], []byte{entryType})
+}
+
 // Read returns the next entry in the reader.
 func (r *WALSegmentReader) Read() (WALEntry, error) {
 	if r.err != nil {
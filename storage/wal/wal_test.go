@@ -1,17 +1,21 @@
 package wal
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/snappy"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb/value"
 )
 
@@ -307,6 +311,83 @@ func TestWAL_ClosedSegments(t *testing.T) {
 	}
 }
 
+func TestWAL_WriteMulti_Consistency(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	// Nothing is fsynced until the WAL is closed.
+	w := NewWAL(dir)
+	w.WithFsyncDelay(time.Hour)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer w.Close()
+
+	write := func(c Consistency) error {
+		_, err := w.WriteMulti(SetConsistency(context.Background(), c), map[string][]value.Value{
+			"cpu,host=A#!~#value": []value.Value{value.NewValue(1, 1.1)},
+		})
+		return err
+	}
+	segmentSize := func() int64 {
+		names, err := SegmentFileNames(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(names[len(names)-1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fi.Size()
+	}
+
+	if err := write(ConsistencyBuffered); err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+	if got := segmentSize(); got != 0 {
+		t.Fatalf("expected buffered write to stay in memory, got segment of %d bytes", got)
+	}
+
+	if err := write(ConsistencyWAL); err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+	if got := segmentSize(); got == 0 {
+		t.Fatal("expected wal write to be written to the segment")
+	}
+
+	done := make(chan error)
+	go func() { done <- write(ConsistencyFsync) }()
+	select {
+	case err := <-done:
+		t.Fatalf("expected fsync write to wait for fsync, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing wal: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+}
+
+func TestParseConsistency(t *testing.T) {
+	for s, exp := range map[string]Consistency{
+		"":         ConsistencyFsync,
+		"buffered": ConsistencyBuffered,
+		"wal":      ConsistencyWAL,
+		"fsync":    ConsistencyFsync,
+	} {
+		if got, err := ParseConsistency(s); err != nil || got != exp {
+			t.Errorf("ParseConsistency(%q) = %q, %v; exp %q", s, got, err, exp)
+		}
+	}
+
+	if _, err := ParseConsistency("any"); err == nil {
+		t.Error("expected invalid consistency to be rejected")
+	}
+}
+
 func TestWALWriter_Corrupt(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -410,6 +491,78 @@ func TestWALSegmentReader_Corrupt(t *testing.T) {
 	}
 }
 
+func TestWALSegmentReader_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	f := MustTempFile(dir)
+
+	key, err := encryption.NewKey("k1", bytes.Repeat([]byte{1}, encryption.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWALSegmentWriter(f)
+	w.key = key
+
+	values := map[string][]value.Value{
+		"cpu,host=secret#!~#float": []value.Value{value.NewValue(1, 1.1)},
+	}
+	if err := w.Write(mustMarshalEntry(&WriteWALEntry{Values: values})); err != nil {
+		fatal(t, "write points", err)
+	}
+	if err := w.Flush(); err != nil {
+		fatal(t, "flush", err)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		fatal(t, "read file", err)
+	}
+	if bytes.Contains(b, []byte("host=secret")) {
+		t.Fatal("expected entry to be encrypted")
+	}
+
+	// Reading without the key must not be mistaken for corruption.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		fatal(t, "seek", err)
+	}
+	r := NewWALSegmentReader(f)
+	if !r.Next() {
+		t.Fatalf("expected next, got false")
+	}
+	if _, err := r.Read(); err == nil {
+		t.Fatal("expected error reading encrypted entry without a key")
+	} else if _, ok := err.(*KeyError); !ok {
+		t.Fatalf("expected key error, got %v", err)
+	}
+
+	kr, err := encryption.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		fatal(t, "seek", err)
+	}
+	r = NewWALSegmentReader(f)
+	r.WithKeyring(kr)
+	if !r.Next() {
+		t.Fatalf("expected next, got false")
+	}
+	we, err := r.Read()
+	if err != nil {
+		fatal(t, "read entry", err)
+	}
+	e, ok := we.(*WriteWALEntry)
+	if !ok {
+		t.Fatalf("expected WriteWALEntry: got %#v", we)
+	}
+	if got, exp := e.Values["cpu,host=secret#!~#float"][0].String(), values["cpu,host=secret#!~#float"][0].String(); got != exp {
+		t.Fatalf("points mismatch: got %v, exp %v", got, exp)
+	}
+	if n := r.Count(); n != MustReadFileSize(f) {
+		t.Fatalf("wrong count of bytes read, got %d, exp %d", n, MustReadFileSize(f))
+	}
+}
+
 func TestWriteWALSegment_UnmarshalBinary_WriteWALCorrupt(t *testing.T) {
 	p1 := value.NewValue(1, 1.1)
 	p2 := value.NewValue(1, int64(1))
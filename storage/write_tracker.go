@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"sync"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// WriteTracker counts the changes to each bucket of an Engine: writes,
+// imports and deletes, including those of retention enforcement. Anything
+// derived from the data of a bucket, such as the results of a query, can be
+// kept until the version of the bucket changes.
+type WriteTracker struct {
+	mu       sync.RWMutex
+	versions map[platform.ID]uint64
+}
+
+// NewWriteTracker returns a WriteTracker of no changes, which is given to
+// an Engine with WithWriteTracker.
+func NewWriteTracker() *WriteTracker {
+	return &WriteTracker{
+		versions: make(map[platform.ID]uint64),
+	}
+}
+
+// BucketVersion returns the number of changes to the bucket so far.
+func (t *WriteTracker) BucketVersion(bucketID platform.ID) uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.versions[bucketID]
+}
+
+// trackPoints increments the version of each bucket of the exploded points.
+func (t *WriteTracker) trackPoints(points []models.Point) {
+	var last platform.ID
+	buckets := make(map[platform.ID]struct{})
+	for _, p := range points {
+		var name [16]byte
+		copy(name[:], p.Name())
+		_, bucketID := tsdb.DecodeName(name)
+		if bucketID == last {
+			continue
+		}
+		buckets[bucketID] = struct{}{}
+		last = bucketID
+	}
+
+	t.mu.Lock()
+	for bucketID := range buckets {
+		t.versions[bucketID]++
+	}
+	t.mu.Unlock()
+}
+
+// track increments the version of the bucket.
+func (t *WriteTracker) track(bucketID platform.ID) {
+	t.mu.Lock()
+	t.versions[bucketID]++
+	t.mu.Unlock()
+}
@@ -0,0 +1,57 @@
+package storage_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+)
+
+func TestEngine_WriteTracker(t *testing.T) {
+	tracker := storage.NewWriteTracker()
+	engine := NewEngine(storage.NewConfig(), storage.WithWriteTracker(tracker))
+	defer engine.Close()
+	engine.MustOpen()
+
+	otherBucket, err := influxdb.IDFromString("3333333333333333")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkVersions := func(bucket, other uint64) {
+		t.Helper()
+		if got := tracker.BucketVersion(engine.bucket); got != bucket {
+			t.Errorf("unexpected version of bucket: got %d, want %d", got, bucket)
+		}
+		if got := tracker.BucketVersion(*otherBucket); got != other {
+			t.Errorf("unexpected version of other bucket: got %d, want %d", got, other)
+		}
+	}
+
+	if err := engine.Write1xPoints([]models.Point{
+		models.MustNewPoint("cpu", nil, models.Fields{"value": 1.0, "value2": 2.0}, time.Unix(0, 10)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	checkVersions(1, 0)
+
+	if _, err := engine.Import(context.Background(), engine.org, *otherBucket, strings.NewReader("cpu value=1 20")); err != nil {
+		t.Fatal(err)
+	}
+	checkVersions(1, 1)
+
+	// Retention enforcement deletes ranges of buckets.
+	if err := engine.DeleteBucketRange(engine.org, engine.bucket, 0, 15); err != nil {
+		t.Fatal(err)
+	}
+	checkVersions(2, 1)
+
+	if err := engine.DeleteBucket(engine.org, *otherBucket); err != nil {
+		t.Fatal(err)
+	}
+	checkVersions(2, 2)
+}
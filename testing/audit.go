@@ -0,0 +1,328 @@
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+// AuditFields will include the IDGenerator and the recorded audit events.
+type AuditFields struct {
+	IDGenerator influxdb.IDGenerator
+	Events      []*influxdb.AuditEvent
+}
+
+// AuditService tests all the service functions.
+func AuditService(
+	init func(AuditFields, *testing.T) (influxdb.AuditService, string, func()), t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(AuditFields, *testing.T) (influxdb.AuditService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "RecordAuditEvent",
+			fn:   RecordAuditEvent,
+		},
+		{
+			name: "FindAuditEvents",
+			fn:   FindAuditEvents,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func auditTime(min int) time.Time {
+	return time.Date(2019, 6, 1, 12, min, 0, 0, time.UTC)
+}
+
+func auditEvents() []*influxdb.AuditEvent {
+	return []*influxdb.AuditEvent{
+		{
+			ID:           MustIDBase16(idA),
+			Time:         auditTime(1),
+			Action:       influxdb.AuditActionCreate,
+			ResourceType: influxdb.BucketsResourceType,
+			ResourceID:   MustIDBase16(idC),
+			OrgID:        MustIDBase16(idD),
+			UserID:       MustIDBase16(idA),
+			After:        json.RawMessage(`{"name":"telegraf"}`),
+		},
+		{
+			ID:           MustIDBase16(idB),
+			Time:         auditTime(2),
+			Action:       influxdb.AuditActionUpdate,
+			ResourceType: influxdb.BucketsResourceType,
+			ResourceID:   MustIDBase16(idC),
+			OrgID:        MustIDBase16(idD),
+			UserID:       MustIDBase16(idB),
+			Before:       json.RawMessage(`{"name":"telegraf"}`),
+			After:        json.RawMessage(`{"name":"metrics"}`),
+		},
+		{
+			ID:           MustIDBase16(idC),
+			Time:         auditTime(3),
+			Action:       influxdb.AuditActionDelete,
+			ResourceType: influxdb.UsersResourceType,
+			ResourceID:   MustIDBase16(idB),
+			UserID:       MustIDBase16(idA),
+			Before:       json.RawMessage(`{"name":"bob"}`),
+		},
+	}
+}
+
+// RecordAuditEvent testing
+func RecordAuditEvent(
+	init func(AuditFields, *testing.T) (influxdb.AuditService, string, func()),
+	t *testing.T,
+) {
+	type args struct {
+		event *influxdb.AuditEvent
+	}
+	type wants struct {
+		err    error
+		events []*influxdb.AuditEvent
+	}
+
+	tests := []struct {
+		name   string
+		fields AuditFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "record event assigns an id",
+			fields: AuditFields{
+				IDGenerator: mock.NewIDGenerator(idB, t),
+			},
+			args: args{
+				event: &influxdb.AuditEvent{
+					Time:           auditTime(1),
+					Action:         influxdb.AuditActionCreate,
+					ResourceType:   influxdb.OrgsResourceType,
+					ResourceID:     MustIDBase16(idC),
+					OrgID:          MustIDBase16(idC),
+					UserID:         MustIDBase16(idA),
+					AuthorizerID:   MustIDBase16(idD),
+					AuthorizerKind: "authorization",
+					After:          json.RawMessage(`{"name":"o1"}`),
+				},
+			},
+			wants: wants{
+				events: []*influxdb.AuditEvent{
+					{
+						ID:             MustIDBase16(idB),
+						Time:           auditTime(1),
+						Action:         influxdb.AuditActionCreate,
+						ResourceType:   influxdb.OrgsResourceType,
+						ResourceID:     MustIDBase16(idC),
+						OrgID:          MustIDBase16(idC),
+						UserID:         MustIDBase16(idA),
+						AuthorizerID:   MustIDBase16(idD),
+						AuthorizerKind: "authorization",
+						After:          json.RawMessage(`{"name":"o1"}`),
+					},
+				},
+			},
+		},
+		{
+			name: "action must be known",
+			fields: AuditFields{
+				IDGenerator: mock.NewIDGenerator(idB, t),
+			},
+			args: args{
+				event: &influxdb.AuditEvent{
+					Time:         auditTime(1),
+					Action:       "read",
+					ResourceType: influxdb.OrgsResourceType,
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "audit event action must be one of create, update or delete",
+				},
+				events: []*influxdb.AuditEvent{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.RecordAuditEvent(ctx, tt.args.event)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			events, _, err := s.FindAuditEvents(ctx, influxdb.AuditEventFilter{})
+			if err != nil {
+				t.Fatalf("failed to retrieve audit events: %v", err)
+			}
+			if diff := cmp.Diff(events, tt.wants.events); diff != "" {
+				t.Errorf("audit events are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindAuditEvents testing
+func FindAuditEvents(
+	init func(AuditFields, *testing.T) (influxdb.AuditService, string, func()),
+	t *testing.T,
+) {
+	type args struct {
+		filter influxdb.AuditEventFilter
+		opts   influxdb.FindOptions
+	}
+	type wants struct {
+		events []*influxdb.AuditEvent
+	}
+
+	bucketsType := influxdb.BucketsResourceType
+	orgID := MustIDBase16(idD)
+	userID := MustIDBase16(idA)
+	deleteAction := influxdb.AuditActionDelete
+	since := auditTime(2)
+	until := auditTime(3)
+
+	all := auditEvents()
+	tests := []struct {
+		name   string
+		fields AuditFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "find all events in the order they were recorded",
+			args: args{},
+			wants: wants{
+				events: all,
+			},
+		},
+		{
+			name: "find events most recent first",
+			args: args{
+				opts: influxdb.FindOptions{Descending: true, Limit: 2},
+			},
+			wants: wants{
+				events: []*influxdb.AuditEvent{all[2], all[1]},
+			},
+		},
+		{
+			name: "find events with offset",
+			args: args{
+				opts: influxdb.FindOptions{Offset: 1},
+			},
+			wants: wants{
+				events: all[1:],
+			},
+		},
+		{
+			name: "find events by resource type",
+			args: args{
+				filter: influxdb.AuditEventFilter{ResourceType: &bucketsType},
+			},
+			wants: wants{
+				events: all[:2],
+			},
+		},
+		{
+			name: "find events by organization",
+			args: args{
+				filter: influxdb.AuditEventFilter{OrgID: &orgID},
+			},
+			wants: wants{
+				events: all[:2],
+			},
+		},
+		{
+			name: "find events by user and action",
+			args: args{
+				filter: influxdb.AuditEventFilter{UserID: &userID, Action: &deleteAction},
+			},
+			wants: wants{
+				events: all[2:],
+			},
+		},
+		{
+			name: "find events of an organization most recent first",
+			args: args{
+				filter: influxdb.AuditEventFilter{OrgID: &orgID},
+				opts:   influxdb.FindOptions{Descending: true},
+			},
+			wants: wants{
+				events: []*influxdb.AuditEvent{all[1], all[0]},
+			},
+		},
+		{
+			name: "find events of an organization in a time range",
+			args: args{
+				filter: influxdb.AuditEventFilter{OrgID: &orgID, Until: &since},
+				opts:   influxdb.FindOptions{Descending: true},
+			},
+			wants: wants{
+				events: all[:1],
+			},
+		},
+		{
+			name: "find events of an organization without events",
+			args: args{
+				filter: influxdb.AuditEventFilter{OrgID: &userID},
+			},
+			wants: wants{
+				events: []*influxdb.AuditEvent{},
+			},
+		},
+		{
+			name: "find events since a time most recent first",
+			args: args{
+				filter: influxdb.AuditEventFilter{Since: &since},
+				opts:   influxdb.FindOptions{Descending: true},
+			},
+			wants: wants{
+				events: []*influxdb.AuditEvent{all[2], all[1]},
+			},
+		},
+		{
+			name: "find events in a time range",
+			args: args{
+				filter: influxdb.AuditEventFilter{Since: &since, Until: &until},
+			},
+			wants: wants{
+				events: all[1:2],
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.Events = auditEvents()
+			s, _, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			events, n, err := s.FindAuditEvents(ctx, tt.args.filter, tt.args.opts)
+			if err != nil {
+				t.Fatalf("failed to retrieve audit events: %v", err)
+			}
+			if n != len(tt.wants.events) {
+				t.Errorf("expected %d audit events, got %d", len(tt.wants.events), n)
+			}
+			if diff := cmp.Diff(events, tt.wants.events); diff != "" {
+				t.Errorf("audit events are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
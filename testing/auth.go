@@ -5,6 +5,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	platform "github.com/influxdata/influxdb"
@@ -73,6 +74,10 @@ func AuthorizationService(
 			name: "DeleteAuthorization",
 			fn:   DeleteAuthorization,
 		},
+		{
+			name: "RotateAuthorization",
+			fn:   RotateAuthorization,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1355,6 +1360,88 @@ func DeleteAuthorization(
 	}
 }
 
+// RotateAuthorization testing
+func RotateAuthorization(
+	init func(AuthorizationFields, *testing.T) (platform.AuthorizationService, string, func()),
+	t *testing.T,
+) {
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	fields := AuthorizationFields{
+		TokenGenerator: &mock.TokenGenerator{
+			TokenFn: func() (string, error) {
+				return "rotated", nil
+			},
+		},
+		Users: []*platform.User{
+			{
+				Name: "cooluser",
+				ID:   MustIDBase16(userOneID),
+			},
+		},
+		Orgs: []*platform.Organization{
+			{
+				Name: "o1",
+				ID:   MustIDBase16(orgOneID),
+			},
+		},
+		Authorizations: []*platform.Authorization{
+			{
+				ID:          MustIDBase16(authOneID),
+				UserID:      MustIDBase16(userOneID),
+				OrgID:       MustIDBase16(orgOneID),
+				Token:       "rand1",
+				Status:      platform.Active,
+				Permissions: allUsersPermission(MustIDBase16(orgOneID)),
+				ExpiresAt:   &expiresAt,
+			},
+			{
+				ID:          MustIDBase16(authTwoID),
+				UserID:      MustIDBase16(userOneID),
+				OrgID:       MustIDBase16(orgOneID),
+				Token:       "rand2",
+				Status:      platform.Active,
+				Permissions: allUsersPermission(MustIDBase16(orgOneID)),
+			},
+		},
+	}
+
+	s, opPrefix, done := init(fields, t)
+	defer done()
+	ctx := context.Background()
+
+	want := &platform.Authorization{
+		ID:          MustIDBase16(authOneID),
+		UserID:      MustIDBase16(userOneID),
+		OrgID:       MustIDBase16(orgOneID),
+		Token:       "rotated",
+		Status:      platform.Active,
+		Permissions: allUsersPermission(MustIDBase16(orgOneID)),
+		ExpiresAt:   &expiresAt,
+	}
+
+	a, err := s.RotateAuthorization(ctx, MustIDBase16(authOneID))
+	if err != nil {
+		t.Fatalf("failed to rotate authorization: %v", err)
+	}
+	if diff := cmp.Diff(a, want, authorizationCmpOptions...); diff != "" {
+		t.Errorf("rotated authorization is different -got/+want\ndiff %s", diff)
+	}
+
+	a, err = s.FindAuthorizationByID(ctx, MustIDBase16(authOneID))
+	if err != nil {
+		t.Fatalf("failed to find authorization: %v", err)
+	}
+	if diff := cmp.Diff(a, want, authorizationCmpOptions...); diff != "" {
+		t.Errorf("stored authorization is different -got/+want\ndiff %s", diff)
+	}
+
+	_, err = s.RotateAuthorization(ctx, MustIDBase16(authThreeID))
+	diffPlatformErrors("rotate missing authorization", err, &platform.Error{
+		Code: platform.ENotFound,
+		Msg:  "authorization not found",
+	}, opPrefix, t)
+}
+
 func allUsersPermission(orgID platform.ID) []platform.Permission {
 	return []platform.Permission{
 		{Action: platform.WriteAction, Resource: platform.Resource{Type: platform.UsersResourceType, OrgID: &orgID}},
@@ -0,0 +1,563 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	"github.com/influxdata/influxdb/mock"
+)
+
+var roleCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*influxdb.Role) []*influxdb.Role {
+		out := append([]*influxdb.Role(nil), in...)
+		sort.Slice(out, func(i, j int) bool {
+			return out[i].ID.String() < out[j].ID.String()
+		})
+		return out
+	}),
+}
+
+var roleMappingCmpOptions = cmp.Options{
+	cmp.Transformer("Sort", func(in []*influxdb.RoleMapping) []*influxdb.RoleMapping {
+		out := append([]*influxdb.RoleMapping(nil), in...)
+		sort.Slice(out, func(i, j int) bool {
+			if out[i].RoleID != out[j].RoleID {
+				return out[i].RoleID < out[j].RoleID
+			}
+			return out[i].SubjectID < out[j].SubjectID
+		})
+		return out
+	}),
+}
+
+// RoleFields will include the IDGenerator, roles and role mappings, and the
+// organization members and authorizations roles are assigned to.
+type RoleFields struct {
+	IDGenerator          influxdb.IDGenerator
+	UserResourceMappings []*influxdb.UserResourceMapping
+	Authorizations       []*influxdb.Authorization
+	Roles                []*influxdb.Role
+	RoleMappings         []*influxdb.RoleMapping
+}
+
+func orgMember(orgID, userID influxdb.ID) *influxdb.UserResourceMapping {
+	return &influxdb.UserResourceMapping{
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   orgID,
+		UserID:       userID,
+		UserType:     influxdb.Member,
+	}
+}
+
+func readBucketsPermission(orgID influxdb.ID) influxdb.Permission {
+	return influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID},
+	}
+}
+
+// RoleService tests all the service functions.
+func RoleService(
+	init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()), t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "CreateRole",
+			fn:   CreateRole,
+		},
+		{
+			name: "FindRoles",
+			fn:   FindRoles,
+		},
+		{
+			name: "UpdateRole",
+			fn:   UpdateRole,
+		},
+		{
+			name: "DeleteRole",
+			fn:   DeleteRole,
+		},
+		{
+			name: "RoleMappings",
+			fn:   RoleMappings,
+		},
+		{
+			name: "RolePermissionsOfRemovedMember",
+			fn:   RolePermissionsOfRemovedMember,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+// CreateRole testing
+func CreateRole(
+	init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()),
+	t *testing.T,
+) {
+	type args struct {
+		role *influxdb.Role
+	}
+	type wants struct {
+		err   error
+		roles []*influxdb.Role
+	}
+
+	existing := &influxdb.Role{
+		ID:          MustIDBase16(idA),
+		OrgID:       MustIDBase16(idC),
+		Name:        "readers",
+		Permissions: []influxdb.Permission{readBucketsPermission(MustIDBase16(idC))},
+	}
+
+	tests := []struct {
+		name   string
+		fields RoleFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "create role assigns an id",
+			fields: RoleFields{
+				IDGenerator: mock.NewIDGenerator(idB, t),
+				Roles:       []*influxdb.Role{existing},
+			},
+			args: args{
+				role: &influxdb.Role{
+					OrgID:       MustIDBase16(idC),
+					Name:        "writers",
+					Permissions: []influxdb.Permission{readBucketsPermission(MustIDBase16(idC))},
+				},
+			},
+			wants: wants{
+				roles: []*influxdb.Role{
+					existing,
+					{
+						ID:          MustIDBase16(idB),
+						OrgID:       MustIDBase16(idC),
+						Name:        "writers",
+						Permissions: []influxdb.Permission{readBucketsPermission(MustIDBase16(idC))},
+					},
+				},
+			},
+		},
+		{
+			name: "names are unique within an organization",
+			fields: RoleFields{
+				IDGenerator: mock.NewIDGenerator(idB, t),
+				Roles:       []*influxdb.Role{existing},
+			},
+			args: args{
+				role: &influxdb.Role{
+					OrgID: MustIDBase16(idC),
+					Name:  "readers",
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EConflict,
+					Msg:  "role with name readers already exists",
+				},
+				roles: []*influxdb.Role{existing},
+			},
+		},
+		{
+			name: "permissions must belong to the organization",
+			fields: RoleFields{
+				IDGenerator: mock.NewIDGenerator(idB, t),
+				Roles:       []*influxdb.Role{existing},
+			},
+			args: args{
+				role: &influxdb.Role{
+					OrgID:       MustIDBase16(idC),
+					Name:        "others",
+					Permissions: []influxdb.Permission{readBucketsPermission(MustIDBase16(idD))},
+				},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "permission read:orgs/020f755c3c082003/buckets is not scoped to organization 020f755c3c082002",
+				},
+				roles: []*influxdb.Role{existing},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateRole(ctx, tt.args.role)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			roles, _, err := s.FindRoles(ctx, influxdb.RoleFilter{})
+			if err != nil {
+				t.Fatalf("failed to retrieve roles: %v", err)
+			}
+			if diff := cmp.Diff(roles, tt.wants.roles, roleCmpOptions...); diff != "" {
+				t.Errorf("roles are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindRoles testing
+func FindRoles(
+	init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()),
+	t *testing.T,
+) {
+	a := &influxdb.Role{ID: MustIDBase16(idA), OrgID: MustIDBase16(idC), Name: "admin", Permissions: []influxdb.Permission{}}
+	b := &influxdb.Role{ID: MustIDBase16(idB), OrgID: MustIDBase16(idC), Name: "admins", Permissions: []influxdb.Permission{}}
+	c := &influxdb.Role{ID: MustIDBase16(idD), OrgID: MustIDBase16(idD), Name: "admin", Permissions: []influxdb.Permission{}}
+	fields := RoleFields{Roles: []*influxdb.Role{a, b, c}}
+
+	tests := []struct {
+		name   string
+		filter influxdb.RoleFilter
+		roles  []*influxdb.Role
+	}{
+		{
+			name:  "find all roles",
+			roles: []*influxdb.Role{a, b, c},
+		},
+		{
+			name:   "find role by id",
+			filter: influxdb.RoleFilter{ID: idPtr(MustIDBase16(idB))},
+			roles:  []*influxdb.Role{b},
+		},
+		{
+			name:   "find roles by org",
+			filter: influxdb.RoleFilter{OrgID: idPtr(MustIDBase16(idC))},
+			roles:  []*influxdb.Role{a, b},
+		},
+		{
+			name:   "find role by org and name",
+			filter: influxdb.RoleFilter{OrgID: idPtr(MustIDBase16(idC)), Name: strPtr("admin")},
+			roles:  []*influxdb.Role{a},
+		},
+		{
+			name:   "find roles by name",
+			filter: influxdb.RoleFilter{Name: strPtr("admin")},
+			roles:  []*influxdb.Role{a, c},
+		},
+		{
+			name:   "find missing role",
+			filter: influxdb.RoleFilter{ID: idPtr(MustIDBase16("020f755c3c0820ff"))},
+			roles:  []*influxdb.Role{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, done := init(fields, t)
+			defer done()
+			ctx := context.Background()
+
+			roles, n, err := s.FindRoles(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("failed to retrieve roles: %v", err)
+			}
+			if n != len(tt.roles) {
+				t.Errorf("got count %d, want %d", n, len(tt.roles))
+			}
+			if diff := cmp.Diff(roles, tt.roles, roleCmpOptions...); diff != "" {
+				t.Errorf("roles are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// UpdateRole testing
+func UpdateRole(
+	init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()),
+	t *testing.T,
+) {
+	orgID := MustIDBase16(idC)
+	fields := RoleFields{
+		Roles: []*influxdb.Role{
+			{ID: MustIDBase16(idA), OrgID: orgID, Name: "readers", Permissions: []influxdb.Permission{}},
+			{ID: MustIDBase16(idB), OrgID: orgID, Name: "writers", Permissions: []influxdb.Permission{}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		upd  influxdb.RoleUpdate
+		role *influxdb.Role
+		err  error
+	}{
+		{
+			name: "update name and permissions",
+			upd: influxdb.RoleUpdate{
+				Name:        strPtr("bucket readers"),
+				Permissions: &[]influxdb.Permission{readBucketsPermission(orgID)},
+			},
+			role: &influxdb.Role{
+				ID:          MustIDBase16(idA),
+				OrgID:       orgID,
+				Name:        "bucket readers",
+				Permissions: []influxdb.Permission{readBucketsPermission(orgID)},
+			},
+		},
+		{
+			name: "update to existing name",
+			upd:  influxdb.RoleUpdate{Name: strPtr("writers")},
+			err: &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "role with name writers already exists",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(fields, t)
+			defer done()
+			ctx := context.Background()
+
+			role, err := s.UpdateRole(ctx, MustIDBase16(idA), tt.upd)
+			diffPlatformErrors(tt.name, err, tt.err, opPrefix, t)
+			if diff := cmp.Diff(role, tt.role); diff != "" {
+				t.Errorf("role is different -got/+want\ndiff %s", diff)
+			}
+			if tt.err != nil {
+				return
+			}
+
+			roles, _, err := s.FindRoles(ctx, influxdb.RoleFilter{OrgID: &orgID, Name: &tt.role.Name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(roles, []*influxdb.Role{tt.role}); diff != "" {
+				t.Errorf("role by name is different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteRole testing
+func DeleteRole(
+	init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()),
+	t *testing.T,
+) {
+	fields := RoleFields{
+		UserResourceMappings: []*influxdb.UserResourceMapping{
+			orgMember(MustIDBase16(idC), MustIDBase16(idD)),
+		},
+		Roles: []*influxdb.Role{
+			{ID: MustIDBase16(idA), OrgID: MustIDBase16(idC), Name: "readers", Permissions: []influxdb.Permission{}},
+		},
+		RoleMappings: []*influxdb.RoleMapping{
+			{RoleID: MustIDBase16(idA), SubjectID: MustIDBase16(idD), SubjectType: influxdb.UsersResourceType},
+		},
+	}
+
+	s, opPrefix, done := init(fields, t)
+	defer done()
+	ctx := context.Background()
+
+	if err := s.DeleteRole(ctx, MustIDBase16(idA)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.FindRoleByID(ctx, MustIDBase16(idA))
+	diffPlatformErrors("deleted role", err, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  influxdb.ErrRoleNotFound,
+	}, opPrefix, t)
+
+	ms, _, err := s.FindRoleMappings(ctx, influxdb.RoleMappingFilter{SubjectID: idPtr(MustIDBase16(idD))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ms) != 0 {
+		t.Errorf("expected mappings of deleted role to be removed, got %v", ms)
+	}
+
+	err = s.DeleteRole(ctx, MustIDBase16(idA))
+	diffPlatformErrors("missing role", err, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  influxdb.ErrRoleNotFound,
+	}, opPrefix, t)
+}
+
+// RoleMappings testing
+func RoleMappings(
+	init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()),
+	t *testing.T,
+) {
+	fields := RoleFields{
+		UserResourceMappings: []*influxdb.UserResourceMapping{
+			orgMember(MustIDBase16(idC), MustIDBase16(idC)),
+			orgMember(MustIDBase16(idD), MustIDBase16(idB)),
+		},
+		Authorizations: []*influxdb.Authorization{
+			{ID: MustIDBase16(idD), Token: "token-d", OrgID: MustIDBase16(idC), UserID: MustIDBase16(idC)},
+			{ID: MustIDBase16(idB), Token: "token-b", OrgID: MustIDBase16(idD), UserID: MustIDBase16(idB)},
+		},
+		Roles: []*influxdb.Role{
+			{ID: MustIDBase16(idA), OrgID: MustIDBase16(idC), Name: "readers", Permissions: []influxdb.Permission{}},
+			{ID: MustIDBase16(idB), OrgID: MustIDBase16(idC), Name: "writers", Permissions: []influxdb.Permission{}},
+		},
+		RoleMappings: []*influxdb.RoleMapping{
+			{RoleID: MustIDBase16(idA), SubjectID: MustIDBase16(idC), SubjectType: influxdb.UsersResourceType},
+		},
+	}
+
+	s, opPrefix, done := init(fields, t)
+	defer done()
+	ctx := context.Background()
+
+	mappings := []*influxdb.RoleMapping{
+		{RoleID: MustIDBase16(idA), SubjectID: MustIDBase16(idD), SubjectType: influxdb.AuthorizationsResourceType},
+		{RoleID: MustIDBase16(idB), SubjectID: MustIDBase16(idC), SubjectType: influxdb.UsersResourceType},
+	}
+	for _, m := range mappings {
+		if err := s.CreateRoleMapping(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := s.CreateRoleMapping(ctx, mappings[0])
+	diffPlatformErrors("duplicate mapping", err, &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "role is already assigned to " + idD,
+	}, opPrefix, t)
+
+	err = s.CreateRoleMapping(ctx, &influxdb.RoleMapping{RoleID: MustIDBase16(idD), SubjectID: MustIDBase16(idC), SubjectType: influxdb.UsersResourceType})
+	diffPlatformErrors("missing role", err, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  influxdb.ErrRoleNotFound,
+	}, opPrefix, t)
+
+	// Roles can only be assigned to members and authorizations of their
+	// organization.
+	err = s.CreateRoleMapping(ctx, &influxdb.RoleMapping{RoleID: MustIDBase16(idA), SubjectID: MustIDBase16(idB), SubjectType: influxdb.UsersResourceType})
+	diffPlatformErrors("user of another organization", err, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "user " + idB + " is not a member of the organization of the role",
+	}, opPrefix, t)
+
+	err = s.CreateRoleMapping(ctx, &influxdb.RoleMapping{RoleID: MustIDBase16(idA), SubjectID: MustIDBase16(idB), SubjectType: influxdb.AuthorizationsResourceType})
+	diffPlatformErrors("authorization of another organization", err, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "authorization " + idB + " is not of the organization of the role",
+	}, opPrefix, t)
+
+	tests := []struct {
+		name     string
+		filter   influxdb.RoleMappingFilter
+		mappings []*influxdb.RoleMapping
+	}{
+		{
+			name:     "by role",
+			filter:   influxdb.RoleMappingFilter{RoleID: idPtr(MustIDBase16(idA))},
+			mappings: []*influxdb.RoleMapping{fields.RoleMappings[0], mappings[0]},
+		},
+		{
+			name:     "by subject",
+			filter:   influxdb.RoleMappingFilter{SubjectID: idPtr(MustIDBase16(idC))},
+			mappings: []*influxdb.RoleMapping{fields.RoleMappings[0], mappings[1]},
+		},
+		{
+			name:     "by subject type",
+			filter:   influxdb.RoleMappingFilter{SubjectType: influxdb.AuthorizationsResourceType},
+			mappings: []*influxdb.RoleMapping{mappings[0]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, _, err := s.FindRoleMappings(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(ms, tt.mappings, roleMappingCmpOptions...); diff != "" {
+				t.Errorf("mappings are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+
+	if err := s.DeleteRoleMapping(ctx, MustIDBase16(idA), MustIDBase16(idC)); err != nil {
+		t.Fatal(err)
+	}
+	ms, _, err := s.FindRoleMappings(ctx, influxdb.RoleMappingFilter{SubjectID: idPtr(MustIDBase16(idC))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(ms, []*influxdb.RoleMapping{mappings[1]}, roleMappingCmpOptions...); diff != "" {
+		t.Errorf("mappings are different after delete -got/+want\ndiff %s", diff)
+	}
+
+	err = s.DeleteRoleMapping(ctx, MustIDBase16(idA), MustIDBase16(idC))
+	diffPlatformErrors("missing mapping", err, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  influxdb.ErrRoleMappingNotFound,
+	}, opPrefix, t)
+}
+
+// RolePermissionsOfRemovedMember tests that a user removed from the
+// organization of a role is no longer granted its permissions.
+func RolePermissionsOfRemovedMember(
+	init func(RoleFields, *testing.T) (influxdb.RoleService, string, func()),
+	t *testing.T,
+) {
+	fields := RoleFields{
+		UserResourceMappings: []*influxdb.UserResourceMapping{
+			orgMember(MustIDBase16(idC), MustIDBase16(idD)),
+		},
+		Roles: []*influxdb.Role{
+			{
+				ID:          MustIDBase16(idA),
+				OrgID:       MustIDBase16(idC),
+				Name:        "readers",
+				Permissions: []influxdb.Permission{readBucketsPermission(MustIDBase16(idC))},
+			},
+		},
+		RoleMappings: []*influxdb.RoleMapping{
+			{RoleID: MustIDBase16(idA), SubjectID: MustIDBase16(idD), SubjectType: influxdb.UsersResourceType},
+		},
+	}
+
+	s, _, done := init(fields, t)
+	defer done()
+	ctx := context.Background()
+
+	urms, ok := s.(influxdb.UserResourceMappingService)
+	if !ok {
+		t.Skip("role service does not manage user resource mappings")
+	}
+
+	sess := &influxdb.Session{UserID: MustIDBase16(idD)}
+	if err := authorizer.AddRolePermissions(ctx, s, urms, sess); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(sess.Permissions, fields.Roles[0].Permissions); diff != "" {
+		t.Errorf("permissions of member are different -got/+want\ndiff %s", diff)
+	}
+
+	if err := urms.DeleteUserResourceMapping(ctx, MustIDBase16(idC), MustIDBase16(idD)); err != nil {
+		t.Fatal(err)
+	}
+
+	sess = &influxdb.Session{UserID: MustIDBase16(idD)}
+	if err := authorizer.AddRolePermissions(ctx, s, urms, sess); err != nil {
+		t.Fatal(err)
+	}
+	if len(sess.Permissions) != 0 {
+		t.Errorf("expected removed member to not be granted the role, got %v", sess.Permissions)
+	}
+}
@@ -0,0 +1,307 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+)
+
+const identityIssuer = "https://idp.example.com"
+
+// UserIdentityFields will include the users and the identities bound to them.
+type UserIdentityFields struct {
+	Users      []*influxdb.User
+	Identities []*influxdb.UserIdentity
+}
+
+// UserIdentityService tests all the service functions.
+func UserIdentityService(
+	init func(UserIdentityFields, *testing.T) (influxdb.UserIdentityService, string, func()), t *testing.T,
+) {
+	tests := []struct {
+		name string
+		fn   func(init func(UserIdentityFields, *testing.T) (influxdb.UserIdentityService, string, func()),
+			t *testing.T)
+	}{
+		{
+			name: "CreateUserIdentity",
+			fn:   CreateUserIdentity,
+		},
+		{
+			name: "FindUserIdentity",
+			fn:   FindUserIdentity,
+		},
+		{
+			name: "DeleteUserIdentity",
+			fn:   DeleteUserIdentity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(init, t)
+		})
+	}
+}
+
+func identityUsers() []*influxdb.User {
+	return []*influxdb.User{
+		{ID: MustIDBase16(idA), Name: "jane@example.com"},
+		{ID: MustIDBase16(idB), Name: "bob@example.com"},
+	}
+}
+
+// CreateUserIdentity testing
+func CreateUserIdentity(
+	init func(UserIdentityFields, *testing.T) (influxdb.UserIdentityService, string, func()),
+	t *testing.T,
+) {
+	type args struct {
+		identity *influxdb.UserIdentity
+	}
+	type wants struct {
+		err      error
+		identity *influxdb.UserIdentity
+	}
+
+	tests := []struct {
+		name   string
+		fields UserIdentityFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "bind an identity to a user",
+			fields: UserIdentityFields{
+				Users: identityUsers(),
+			},
+			args: args{
+				identity: &influxdb.UserIdentity{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idA)},
+			},
+			wants: wants{
+				identity: &influxdb.UserIdentity{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idA)},
+			},
+		},
+		{
+			name: "identity can only be bound to one user",
+			fields: UserIdentityFields{
+				Users: identityUsers(),
+				Identities: []*influxdb.UserIdentity{
+					{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idA)},
+				},
+			},
+			args: args{
+				identity: &influxdb.UserIdentity{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idB)},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EConflict,
+					Msg:  "identity is already bound to a user",
+				},
+				identity: &influxdb.UserIdentity{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idA)},
+			},
+		},
+		{
+			name: "identity requires an issuer",
+			fields: UserIdentityFields{
+				Users: identityUsers(),
+			},
+			args: args{
+				identity: &influxdb.UserIdentity{Subject: "1", UserID: MustIDBase16(idA)},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "user identity requires an issuer and subject",
+				},
+			},
+		},
+		{
+			name: "user must exist",
+			fields: UserIdentityFields{
+				Users: identityUsers(),
+			},
+			args: args{
+				identity: &influxdb.UserIdentity{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idC)},
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Msg:  "user not found",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.CreateUserIdentity(ctx, tt.args.identity)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			identity, err := s.FindUserIdentity(ctx, tt.args.identity.Issuer, tt.args.identity.Subject)
+			if tt.wants.identity == nil {
+				if influxdb.ErrorCode(err) != influxdb.ENotFound {
+					t.Fatalf("expected identity not to be bound, got %v, %v", identity, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to retrieve identity: %v", err)
+			}
+			if diff := cmp.Diff(identity, tt.wants.identity); diff != "" {
+				t.Errorf("identities are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// FindUserIdentity testing
+func FindUserIdentity(
+	init func(UserIdentityFields, *testing.T) (influxdb.UserIdentityService, string, func()),
+	t *testing.T,
+) {
+	type args struct {
+		issuer, subject string
+	}
+	type wants struct {
+		err      error
+		identity *influxdb.UserIdentity
+	}
+
+	fields := UserIdentityFields{
+		Users: identityUsers(),
+		Identities: []*influxdb.UserIdentity{
+			{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idA)},
+			{Issuer: identityIssuer + "/other", Subject: "1", UserID: MustIDBase16(idB)},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		fields UserIdentityFields
+		args   args
+		wants  wants
+	}{
+		{
+			name:   "find identity by issuer and subject",
+			fields: fields,
+			args: args{
+				issuer:  identityIssuer,
+				subject: "1",
+			},
+			wants: wants{
+				identity: &influxdb.UserIdentity{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idA)},
+			},
+		},
+		{
+			name:   "subjects of other issuers are other identities",
+			fields: fields,
+			args: args{
+				issuer:  identityIssuer + "/other",
+				subject: "1",
+			},
+			wants: wants{
+				identity: &influxdb.UserIdentity{Issuer: identityIssuer + "/other", Subject: "1", UserID: MustIDBase16(idB)},
+			},
+		},
+		{
+			name:   "missing identity",
+			fields: fields,
+			args: args{
+				issuer:  identityIssuer,
+				subject: "2",
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Msg:  influxdb.ErrUserIdentityNotFound,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			identity, err := s.FindUserIdentity(ctx, tt.args.issuer, tt.args.subject)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			if diff := cmp.Diff(identity, tt.wants.identity); diff != "" {
+				t.Errorf("identities are different -got/+want\ndiff %s", diff)
+			}
+		})
+	}
+}
+
+// DeleteUserIdentity testing
+func DeleteUserIdentity(
+	init func(UserIdentityFields, *testing.T) (influxdb.UserIdentityService, string, func()),
+	t *testing.T,
+) {
+	type args struct {
+		issuer, subject string
+	}
+	type wants struct {
+		err error
+	}
+
+	fields := UserIdentityFields{
+		Users: identityUsers(),
+		Identities: []*influxdb.UserIdentity{
+			{Issuer: identityIssuer, Subject: "1", UserID: MustIDBase16(idA)},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		fields UserIdentityFields
+		args   args
+		wants  wants
+	}{
+		{
+			name:   "delete identity",
+			fields: fields,
+			args: args{
+				issuer:  identityIssuer,
+				subject: "1",
+			},
+		},
+		{
+			name:   "delete missing identity",
+			fields: fields,
+			args: args{
+				issuer:  identityIssuer,
+				subject: "2",
+			},
+			wants: wants{
+				err: &influxdb.Error{
+					Code: influxdb.ENotFound,
+					Msg:  influxdb.ErrUserIdentityNotFound,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, opPrefix, done := init(tt.fields, t)
+			defer done()
+			ctx := context.Background()
+
+			err := s.DeleteUserIdentity(ctx, tt.args.issuer, tt.args.subject)
+			diffPlatformErrors(tt.name, err, tt.wants.err, opPrefix, t)
+
+			if _, err := s.FindUserIdentity(ctx, tt.args.issuer, tt.args.subject); influxdb.ErrorCode(err) != influxdb.ENotFound {
+				t.Errorf("expected identity to be deleted, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+)
+
+// NewClientConfig returns the configuration of a client trusting the PEM
+// encoded certificate authorities in the file at caCertFile in addition to
+// the system ones. The server certificate is not verified at all if
+// skipVerify is set.
+func NewClientConfig(caCertFile string, skipVerify bool) (*tls.Config, error) {
+	c := &tls.Config{InsecureSkipVerify: skipVerify}
+	if caCertFile == "" {
+		return c, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if err := appendCertsFromFile(pool, caCertFile); err != nil {
+		return nil, err
+	}
+	c.RootCAs = pool
+	return c, nil
+}
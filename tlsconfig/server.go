@@ -0,0 +1,237 @@
+// Package tlsconfig builds the TLS configurations of the influxd HTTP server
+// and of its clients.
+//
+// A Server loads the certificate and key served by influxd and, for mutual
+// TLS, the certificate authorities client certificates are verified with.
+// The files are watched and reloaded when they change, so that certificates
+// can be renewed without restarting influxd. Subjects of verified client
+// certificates can be mapped to authorizations, so that clients
+// authenticate with their certificate instead of a token.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultReloadInterval is how often a Server checks its files for changes.
+const DefaultReloadInterval = 10 * time.Second
+
+// Config configures the TLS of a server.
+type Config struct {
+	// CertFile and KeyFile are the paths to the PEM encoded certificate
+	// chain and private key served to clients.
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is the path to the PEM encoded certificate authorities
+	// that client certificates are verified with. Client certificates are
+	// not requested if it is empty.
+	ClientCAFile string
+	// RequireClientCert refuses connections without a valid client
+	// certificate. Otherwise a client certificate is verified if one is
+	// presented.
+	RequireClientCert bool
+
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+// Validate returns an error if the configuration is incomplete.
+func (c Config) Validate() error {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("tls: both a certificate and a key are required")
+	}
+	if c.RequireClientCert && c.ClientCAFile == "" {
+		return errors.New("tls: requiring client certificates requires a client certificate authority")
+	}
+	return nil
+}
+
+// Server serves the certificate configured, reloading it along with the
+// client certificate authorities when their files change.
+type Server struct {
+	Logger *zap.Logger
+
+	config Config
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewServer loads the files of c.
+func NewServer(c Config) (*Server, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.ReloadInterval <= 0 {
+		c.ReloadInterval = DefaultReloadInterval
+	}
+
+	s := &Server{
+		Logger: zap.NewNop(),
+		config: c,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// files returns the paths of the files the server is configured with.
+func (s *Server) files() []string {
+	files := []string{s.config.CertFile, s.config.KeyFile}
+	if s.config.ClientCAFile != "" {
+		files = append(files, s.config.ClientCAFile)
+	}
+	return files
+}
+
+// load reads the files of the server.
+func (s *Server) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, path := range s.files() {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTimes[path] = fi.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.config.CertFile, s.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("tls: loading certificate: %v", err)
+	}
+
+	var pool *x509.CertPool
+	if s.config.ClientCAFile != "" {
+		if pool, err = LoadCertPool(s.config.ClientCAFile); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.cert, s.clientCAs, s.modTimes = &cert, pool, modTimes
+	s.mu.Unlock()
+	return nil
+}
+
+// changed returns true if any file was modified since it was loaded.
+func (s *Server) changed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for path, modTime := range s.modTimes {
+		fi, err := os.Stat(path)
+		if err != nil {
+			// The file may be in the middle of being replaced.
+			continue
+		}
+		if !fi.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reload reloads the files if any changed. The previous certificates are
+// kept if the new ones can't be loaded.
+func (s *Server) Reload() error {
+	if !s.changed() {
+		return nil
+	}
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.Logger.Info("Reloaded TLS certificates")
+	return nil
+}
+
+// Open starts watching the files for changes.
+func (s *Server) Open() {
+	s.closing = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(s.config.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.closing:
+				return
+			case <-ticker.C:
+				if err := s.Reload(); err != nil {
+					s.Logger.Error("Failed to reload TLS certificates", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Close stops watching the files.
+func (s *Server) Close() error {
+	if s.closing != nil {
+		close(s.closing)
+		s.wg.Wait()
+		s.closing = nil
+	}
+	return nil
+}
+
+// TLSConfig returns the configuration of a TLS listener serving the current
+// certificates.
+func (s *Server) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			s.mu.RLock()
+			defer s.mu.RUnlock()
+
+			c := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*s.cert},
+			}
+			if s.clientCAs != nil {
+				c.ClientCAs = s.clientCAs
+				c.ClientAuth = tls.VerifyClientCertIfGiven
+				if s.config.RequireClientCert {
+					c.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+			return c, nil
+		},
+	}
+}
+
+// LoadCertPool returns a pool of the PEM encoded certificates in the file at
+// path.
+func LoadCertPool(path string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if err := appendCertsFromFile(pool, path); err != nil {
+		return nil, err
+	}
+	return pool, nil
+}
+
+func appendCertsFromFile(pool *x509.CertPool, path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !pool.AppendCertsFromPEM(b) {
+		return fmt.Errorf("tls: no certificates found in %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tlsconfig"
+)
+
+var serial int64
+
+// newCert returns a certificate for cn signed by parent, or self-signed if
+// parent is nil, along with its key.
+func newCert(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serial++
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func writeCert(t *testing.T, path string, cert *x509.Certificate) {
+	t.Helper()
+	b := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeKey(t *testing.T, path string, key *ecdsa.PrivateKey) {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func keyPair(cert *x509.Certificate, key *ecdsa.PrivateKey) tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+}
+
+func TestServer_MutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := newCert(t, "ca", nil, nil)
+	serverCert, serverKey := newCert(t, "server", ca, caKey)
+	clientCert, clientKey := newCert(t, "client", ca, caKey)
+
+	c := tlsconfig.Config{
+		CertFile:          filepath.Join(dir, "server.crt"),
+		KeyFile:           filepath.Join(dir, "server.key"),
+		ClientCAFile:      filepath.Join(dir, "ca.crt"),
+		RequireClientCert: true,
+	}
+	writeCert(t, c.CertFile, serverCert)
+	writeKey(t, c.KeyFile, serverKey)
+	writeCert(t, c.ClientCAFile, ca)
+
+	s, err := tlsconfig.NewServer(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) == 0 || r.TLS.VerifiedChains[0][0].Subject.CommonName != "client" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	ts.TLS = s.TLSConfig()
+	ts.StartTLS()
+	defer ts.Close()
+
+	clientConfig, err := tlsconfig.NewClientConfig(c.ClientCAFile, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a client certificate the handshake fails.
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+	if resp, err := client.Get(ts.URL); err == nil {
+		resp.Body.Close()
+		t.Fatal("expected request without a client certificate to fail")
+	}
+
+	clientConfig.Certificates = []tls.Certificate{keyPair(clientCert, clientKey)}
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("unexpected error with a client certificate: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected client certificate to be verified, got status %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := tlsconfig.Config{
+		CertFile: filepath.Join(dir, "server.crt"),
+		KeyFile:  filepath.Join(dir, "server.key"),
+	}
+	cert, key := newCert(t, "old", nil, nil)
+	writeCert(t, c.CertFile, cert)
+	writeKey(t, c.KeyFile, key)
+
+	s, err := tlsconfig.NewServer(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := func() string {
+		t.Helper()
+		sc, err := s.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(sc.Certificates[0].Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+	if cn := served(); cn != "old" {
+		t.Fatalf("expected old certificate to be served, got %s", cn)
+	}
+
+	// A certificate that doesn't match the key is not loaded.
+	cert, key = newCert(t, "new", nil, nil)
+	writeCert(t, c.CertFile, cert)
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(c.CertFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err == nil {
+		t.Fatal("expected mismatched certificate and key to fail to load")
+	}
+	if cn := served(); cn != "old" {
+		t.Fatalf("expected old certificate to still be served, got %s", cn)
+	}
+
+	writeKey(t, c.KeyFile, key)
+	if err := os.Chtimes(c.KeyFile, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if cn := served(); cn != "new" {
+		t.Fatalf("expected new certificate to be served, got %s", cn)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := (tlsconfig.Config{CertFile: "server.crt"}).Validate(); err == nil {
+		t.Error("expected certificate without a key to be invalid")
+	}
+	if err := (tlsconfig.Config{CertFile: "server.crt", KeyFile: "server.key", RequireClientCert: true}).Validate(); err == nil {
+		t.Error("expected requiring client certificates without a certificate authority to be invalid")
+	}
+}
@@ -0,0 +1,61 @@
+package tlsconfig
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/influxdata/influxdb"
+)
+
+// Subject maps the subject of client certificates to the authorization
+// they authenticate as.
+type Subject struct {
+	// Subject is the distinguished name of the certificate subject, such as
+	// "CN=telegraf,O=Acme".
+	Subject         string      `json:"subject"`
+	AuthorizationID influxdb.ID `json:"authorizationID"`
+}
+
+// Subjects maps the subjects of client certificates to authorizations.
+type Subjects struct {
+	m map[string]influxdb.ID
+}
+
+// NewSubjects returns the mapping of subjects.
+func NewSubjects(subjects []Subject) (*Subjects, error) {
+	s := &Subjects{m: make(map[string]influxdb.ID, len(subjects))}
+	for _, sub := range subjects {
+		if sub.Subject == "" {
+			return nil, fmt.Errorf("tls: subject mapped to authorization %s is empty", sub.AuthorizationID)
+		} else if !sub.AuthorizationID.Valid() {
+			return nil, fmt.Errorf("tls: subject %s requires an authorization id", sub.Subject)
+		} else if _, ok := s.m[sub.Subject]; ok {
+			return nil, fmt.Errorf("tls: subject %s is mapped more than once", sub.Subject)
+		}
+		s.m[sub.Subject] = sub.AuthorizationID
+	}
+	return s, nil
+}
+
+// LoadSubjects reads a JSON list of subjects from the file at path.
+func LoadSubjects(path string) (*Subjects, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []Subject
+	if err := json.Unmarshal(data, &subjects); err != nil {
+		return nil, fmt.Errorf("tls: invalid subjects in %s: %v", path, err)
+	}
+	return NewSubjects(subjects)
+}
+
+// AuthorizationID returns the id of the authorization the subject of cert
+// is mapped to.
+func (s *Subjects) AuthorizationID(cert *x509.Certificate) (influxdb.ID, bool) {
+	id, ok := s.m[cert.Subject.String()]
+	return id, ok
+}
@@ -0,0 +1,50 @@
+package tlsconfig_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tlsconfig"
+)
+
+func TestLoadSubjects(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "subjects.json")
+	data := `[{"subject": "CN=telegraf,O=Acme", "authorizationID": "020f755c3c082000"}]`
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := tlsconfig.LoadSubjects(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "telegraf", Organization: []string{"Acme"}}}
+	if id, ok := s.AuthorizationID(cert); !ok || id.String() != "020f755c3c082000" {
+		t.Errorf("expected subject to be mapped to 020f755c3c082000, got %s, %v", id, ok)
+	}
+	cert.Subject.CommonName = "other"
+	if _, ok := s.AuthorizationID(cert); ok {
+		t.Error("expected other subject not to be mapped")
+	}
+
+	if _, err := tlsconfig.NewSubjects([]tlsconfig.Subject{
+		{Subject: "CN=a", AuthorizationID: influxdb.ID(1)},
+		{Subject: "CN=a", AuthorizationID: influxdb.ID(2)},
+	}); err == nil {
+		t.Error("expected subject mapped twice to be invalid")
+	}
+	if _, err := tlsconfig.NewSubjects([]tlsconfig.Subject{{Subject: "CN=a"}}); err == nil {
+		t.Error("expected subject without an authorization to be invalid")
+	}
+}
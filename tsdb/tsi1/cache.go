@@ -165,6 +165,15 @@ func (c *TagValueSeriesIDCache) DeleteMeasurement(name []byte) {
 	c.Unlock()
 }
 
+// Clear removes all entries from the cache.
+func (c *TagValueSeriesIDCache) Clear() {
+	c.Lock()
+	c.cache = map[string]map[string]map[string]*list.Element{}
+	c.evictor.Init()
+	c.tracker.SetSize(0)
+	c.Unlock()
+}
+
 // delete removes x from the tuple {name, key, value} if it exists.
 func (c *TagValueSeriesIDCache) delete(name, key, value []byte, x tsdb.SeriesID) {
 	if mmap, ok := c.cache[string(name)]; ok {
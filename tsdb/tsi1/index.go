@@ -19,6 +19,7 @@ import (
 	"github.com/cespare/xxhash"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
 	"github.com/influxdata/influxdb/pkg/slices"
 	"github.com/influxdata/influxdb/query"
@@ -46,7 +47,6 @@ func init() {
 //
 // NOTE: Currently, this must not be change once a database is created. Further,
 // it must also be a power of 2.
-//
 var DefaultPartitionN uint64 = 8
 
 // An IndexOption is a functional option for changing the configuration of
@@ -103,6 +103,8 @@ var DisableMetrics = func() IndexOption {
 type Index struct {
 	mu         sync.RWMutex
 	partitions []*Partition
+	rebuilding map[int]bool   // Partitions with a rebuild in progress.
+	wg         sync.WaitGroup // Tracks replaced partitions being closed.
 	res        lifecycle.Resource
 
 	defaultLabels prometheus.Labels
@@ -120,6 +122,8 @@ type Index struct {
 	logger             *zap.Logger // Index's logger.
 	config             Config      // The index configuration
 
+	keyring *encryption.Keyring // Encrypts new log and index files when set.
+
 	// The following must be set when initializing an Index.
 	sfile *tsdb.SeriesFile // series lookup file
 
@@ -193,6 +197,14 @@ func (i *Index) WithLogger(l *zap.Logger) {
 	i.logger = l.With(zap.String("index", "tsi"))
 }
 
+// WithKeyring sets the keyring new log and index files are encrypted with.
+// Encrypted files can only be read back with a keyring holding their key.
+//
+// It's not safe to call WithKeyring after the index has been opened.
+func (i *Index) WithKeyring(kr *encryption.Keyring) {
+	i.keyring = kr
+}
+
 // SeriesFile returns the series file attached to the index.
 func (i *Index) SeriesFile() *tsdb.SeriesFile { return i.sfile }
 
@@ -238,25 +250,15 @@ func (i *Index) Open(ctx context.Context) error {
 	i.tagValueCache.tracker = newCacheTracker(cms, i.defaultLabels)
 	i.tagValueCache.tracker.enabled = i.metricsEnabled
 
+	// Recover from any partition rebuild that was interrupted.
+	if err := i.recoverRebuilds(); err != nil {
+		return err
+	}
+
 	// Initialize index partitions.
 	i.partitions = make([]*Partition, i.PartitionN)
 	for j := 0; j < len(i.partitions); j++ {
-		p := NewPartition(i.sfile, filepath.Join(i.path, fmt.Sprint(j)))
-		p.MaxLogFileSize = i.maxLogFileSize
-		p.nosync = i.disableFsync
-		p.logbufferSize = i.logfileBufferSize
-		p.logger = i.logger.With(zap.String("tsi1_partition", fmt.Sprint(j+1)))
-
-		// Each of the trackers needs to be given slightly different default
-		// labels to ensure the correct partition ids are set as labels.
-		labels := make(prometheus.Labels, len(i.defaultLabels))
-		for k, v := range i.defaultLabels {
-			labels[k] = v
-		}
-		labels["index_partition"] = fmt.Sprint(j)
-		p.tracker = newPartitionTracker(pms, labels)
-		p.tracker.enabled = i.metricsEnabled
-		i.partitions[j] = p
+		i.partitions[j] = i.newPartition(j, filepath.Join(i.path, fmt.Sprint(j)))
 	}
 
 	// Open all the Partitions in parallel.
@@ -303,6 +305,28 @@ func (i *Index) Open(ctx context.Context) error {
 	return nil
 }
 
+// newPartition returns a new partition with the index's settings for the
+// partition with id j, stored at path.
+func (i *Index) newPartition(j int, path string) *Partition {
+	p := NewPartition(i.sfile, path)
+	p.MaxLogFileSize = i.maxLogFileSize
+	p.nosync = i.disableFsync
+	p.logbufferSize = i.logfileBufferSize
+	p.keyring = i.keyring
+	p.logger = i.logger.With(zap.String("tsi1_partition", fmt.Sprint(j+1)))
+
+	// Each of the trackers needs to be given slightly different default
+	// labels to ensure the correct partition ids are set as labels.
+	labels := make(prometheus.Labels, len(i.defaultLabels))
+	for k, v := range i.defaultLabels {
+		labels[k] = v
+	}
+	labels["index_partition"] = fmt.Sprint(j)
+	p.tracker = newPartitionTracker(pms, labels)
+	p.tracker.enabled = i.metricsEnabled
+	return p
+}
+
 // Acquire returns a reference to the index that causes it to be unable to be
 // closed until the reference is released.
 func (i *Index) Acquire() (*lifecycle.Reference, error) {
@@ -339,6 +363,9 @@ func (i *Index) Wait() {
 
 // Close closes the index.
 func (i *Index) Close() error {
+	// Wait for partitions replaced by rebuilds to close.
+	i.wg.Wait()
+
 	// Lock index and close partitions.
 	i.mu.Lock()
 	defer i.mu.Unlock()
@@ -364,6 +391,12 @@ func (i *Index) PartitionAt(index int) *Partition {
 	return i.partitions[index]
 }
 
+// PartitionIndex returns the index of the Partition that the provided series
+// key belongs to.
+func (i *Index) PartitionIndex(key []byte) int {
+	return i.partitionIdx(key)
+}
+
 // partition returns the appropriate Partition for a provided series key.
 func (i *Index) partition(key []byte) *Partition {
 	return i.partitions[int(xxhash.Sum64(key)&(i.PartitionN-1))]
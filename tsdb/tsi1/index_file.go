@@ -10,6 +10,7 @@ import (
 	"unsafe"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
 	"github.com/influxdata/influxdb/pkg/mmap"
 	"github.com/influxdata/influxdb/tsdb"
@@ -48,6 +49,10 @@ var (
 type IndexFile struct {
 	data []byte
 
+	// Encrypted files are decrypted to the heap rather than mmap'd.
+	keyring   *encryption.Keyring
+	decrypted bool
+
 	// Lifecycle tracking
 	res lifecycle.Resource
 
@@ -130,6 +135,16 @@ func (f *IndexFile) Open() (err error) {
 		return err
 	}
 
+	if encryption.IsStream(data) {
+		plain, err := f.decrypt(data)
+		mmap.Unmap(data)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		data, f.decrypted = plain, true
+	}
+
 	if err := f.UnmarshalBinary(data); err != nil {
 		f.Close()
 		return err
@@ -154,9 +169,25 @@ func (f *IndexFile) Close() error {
 	f.sfile = nil
 	f.tblks = nil
 	f.mblk = MeasurementBlock{}
+	if f.decrypted {
+		f.data = nil
+		return nil
+	}
 	return mmap.Unmap(f.data)
 }
 
+// decrypt returns the plaintext of an encrypted index file.
+func (f *IndexFile) decrypt(data []byte) ([]byte, error) {
+	if f.keyring == nil {
+		return nil, fmt.Errorf("tsi1: index file %s is encrypted but no encryption key is configured", f.path)
+	}
+	plain, err := encryption.ReadStream(data, f.keyring)
+	if err != nil {
+		return nil, fmt.Errorf("tsi1: index file %s: %v", f.path, err)
+	}
+	return plain, nil
+}
+
 // ID returns the file sequence identifier.
 func (f *IndexFile) ID() int { return f.id }
 
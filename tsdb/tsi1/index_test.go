@@ -1,6 +1,7 @@
 package tsi1_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsi1"
 )
@@ -206,6 +208,77 @@ func TestIndex_DropMeasurement(t *testing.T) {
 	})
 }
 
+// Ensure index partitions can be rebuilt while the index is open.
+func TestIndex_RebuildPartition(t *testing.T) {
+	idx := MustOpenIndex(2, tsi1.NewConfig())
+	defer idx.Close()
+
+	series := []Series{
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "east"})},
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "west"})},
+		{Name: []byte("mem"), Tags: models.NewTags(map[string]string{"region": "east"})},
+	}
+	if err := idx.CreateSeriesSliceIfNotExists(series); err != nil {
+		t.Fatal(err)
+	}
+
+	// An aborted rebuild leaves the index unchanged.
+	r, err := idx.RebuildPartition(0)
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := idx.RebuildPartition(0); err == nil {
+		t.Fatal("expected error rebuilding a partition twice")
+	} else if err := r.Abort(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rebuild both partitions without the cpu,region=west series.
+	rebuilds := make([]*tsi1.PartitionRebuild, idx.PartitionN)
+	for i := range rebuilds {
+		if rebuilds[i], err = idx.RebuildPartition(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collection := &tsdb.SeriesCollection{}
+	for _, s := range []Series{series[0], series[2]} {
+		collection.Keys = append(collection.Keys, models.MakeKey(s.Name, s.Tags))
+		collection.Names = append(collection.Names, s.Name)
+		collection.Tags = append(collection.Tags, s.Tags)
+		collection.Types = append(collection.Types, s.Type)
+	}
+	for _, r := range rebuilds {
+		if err := r.CreateSeriesListIfNotExists(collection); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, r := range rebuilds {
+		if err := r.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	idx.Run(t, func(t *testing.T) {
+		if got, exp := idx.SeriesIDSet().Cardinality(), uint64(2); got != exp {
+			t.Fatalf("got %d series, expected %d", got, exp)
+		}
+
+		var buf []byte
+		west := idx.SeriesFile.SeriesID(series[1].Name, series[1].Tags, buf)
+		if idx.SeriesIDSet().Contains(west) {
+			t.Fatal("expected cpu,region=west to be removed from the index")
+		}
+
+		for _, name := range []string{"cpu", "mem"} {
+			if v, err := idx.MeasurementExists([]byte(name)); err != nil {
+				t.Fatal(err)
+			} else if !v {
+				t.Fatalf("expected measurement %s to exist", name)
+			}
+		}
+	})
+}
+
 func TestIndex_Open(t *testing.T) {
 	// Opening a fresh index should set the MANIFEST version to current version.
 	idx := NewIndex(tsi1.DefaultPartitionN, tsi1.NewConfig())
@@ -408,17 +481,94 @@ func TestIndex_MeasurementCardinalityStats(t *testing.T) {
 	})
 }
 
+// Ensure an index with a keyring encrypts its log and index files.
+func TestIndex_Encrypted(t *testing.T) {
+	key, err := encryption.NewKey("k1", bytes.Repeat([]byte{1}, encryption.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	kr, err := encryption.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Compact the log file on every write.
+	c := tsi1.NewConfig()
+	c.MaxIndexLogFileSize = 1
+	idx := NewIndex(1, c)
+	idx.Keyring = kr
+	idx.Index.WithKeyring(kr)
+	if err := idx.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+
+	for _, region := range []string{"secret-east", "secret-west"} {
+		if err := idx.CreateSeriesSliceIfNotExists([]Series{
+			{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": region})},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		idx.Wait()
+	}
+
+	var tsiN int
+	if err := filepath.Walk(idx.Path(), func(path string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case tsi1.IndexFileExt:
+			tsiN++
+		case tsi1.LogFileExt:
+		default:
+			return nil
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(b, []byte("secret")) {
+			t.Errorf("expected %s to be encrypted", path)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if tsiN == 0 {
+		t.Fatal("expected log files to be compacted to index files")
+	}
+
+	idx.Run(t, func(t *testing.T) {
+		for _, region := range []string{"secret-east", "secret-west"} {
+			if ok, err := idx.HasTagValue([]byte("cpu"), []byte("region"), []byte(region)); err != nil {
+				t.Fatal(err)
+			} else if !ok {
+				t.Fatalf("expected tag value %s to exist", region)
+			}
+		}
+	})
+
+	// The index can't be opened without the key.
+	idx.Keyring = nil
+	if err := idx.Reopen(); err == nil {
+		t.Fatal("expected encrypted index not to open without a keyring")
+	}
+}
+
 // Index is a test wrapper for tsi1.Index.
 type Index struct {
 	*tsi1.Index
 	Config     tsi1.Config
 	SeriesFile *SeriesFile
+	Keyring    *encryption.Keyring
 }
 
 // NewIndex returns a new instance of Index at a temporary path.
 func NewIndex(partitionN uint64, c tsi1.Config) *Index {
 	idx := &Index{
-		Config:     tsi1.NewConfig(),
+		Config:     c,
 		SeriesFile: NewSeriesFile(),
 	}
 	idx.Index = tsi1.NewIndex(idx.SeriesFile.SeriesFile, idx.Config, tsi1.WithPath(MustTempDir()))
@@ -467,6 +617,7 @@ func (idx *Index) Reopen() error {
 	partitionN := idx.Index.PartitionN // Remember how many partitions to use.
 	idx.Index = tsi1.NewIndex(idx.SeriesFile.SeriesFile, idx.Config, tsi1.WithPath(idx.Index.Path()))
 	idx.Index.PartitionN = partitionN
+	idx.Index.WithKeyring(idx.Keyring)
 	return idx.Open()
 }
 
@@ -16,6 +16,7 @@ import (
 
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/bloom"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
 	"github.com/influxdata/influxdb/pkg/mmap"
 	"github.com/influxdata/influxdb/tsdb"
@@ -32,6 +33,11 @@ const (
 	LogEntryMeasurementTombstoneFlag = 0x02
 	LogEntryTagKeyTombstoneFlag      = 0x04
 	LogEntryTagValueTombstoneFlag    = 0x08
+
+	// logEntryEncryptedFlag marks an entry sealed with a key of the index
+	// keyring. It is followed by the key id, the length of the sealed entry
+	// as a uvarint and the sealed entry itself.
+	logEntryEncryptedFlag = 0xFF
 )
 
 // defaultLogFileBufferSize describes the size of the buffer that the LogFile's buffered
@@ -57,6 +63,9 @@ type LogFile struct {
 	nosync     bool          // Disables buffer flushing and file syncing. Useful for offline tooling.
 	buf        []byte        // marshaling buffer
 	keyBuf     []byte
+	sealed     []byte // encryption buffer
+
+	keyring *encryption.Keyring // encrypts entries when set
 
 	sfile    *tsdb.SeriesFile // series lookup
 	sfileref *lifecycle.Reference
@@ -170,7 +179,7 @@ func (f *LogFile) open() (err error) {
 	for buf := f.data; len(buf) > 0; {
 		// Read next entry. Truncate partial writes.
 		var e LogEntry
-		if err := e.UnmarshalBinary(buf); err == io.ErrShortBuffer || err == ErrLogEntryChecksumMismatch {
+		if err := f.readEntry(&e, buf); err == io.ErrShortBuffer || err == ErrLogEntryChecksumMismatch {
 			break
 		} else if err != nil {
 			return err
@@ -651,11 +660,72 @@ func (f *LogFile) SeriesN() (n uint64) {
 	return n
 }
 
+// readEntry unmarshals the entry at the start of buf, decrypting it if it
+// is sealed. A missing or unknown key is returned as an error rather than as
+// a partial write so that the log is never truncated because of it.
+func (f *LogFile) readEntry(e *LogEntry, buf []byte) error {
+	if len(buf) == 0 || buf[0] != logEntryEncryptedFlag {
+		return e.UnmarshalBinary(buf)
+	}
+
+	id, n, err := encryption.ReadKeyID(buf[1:])
+	if err == io.ErrShortBuffer {
+		return err
+	} else if err != nil {
+		return ErrLogEntryChecksumMismatch
+	}
+	size := 1 + n
+
+	sz, n, err := uvarint(buf[size:])
+	if err != nil {
+		return err
+	}
+	size += n
+	if uint64(len(buf)-size) < sz {
+		return io.ErrShortBuffer
+	}
+	sealed := buf[size : size+int(sz)]
+	size += int(sz)
+
+	if f.keyring == nil {
+		return fmt.Errorf("tsi1: log file %s is encrypted but no encryption key is configured", f.path)
+	}
+	key, err := f.keyring.Key(id)
+	if err != nil {
+		return fmt.Errorf("tsi1: log file %s: %v", f.path, err)
+	}
+	// Entries reference the buffer they are read from, so each one is
+	// decrypted to its own.
+	plain, err := key.Open(nil, sealed, nil)
+	if err != nil {
+		return ErrLogEntryChecksumMismatch
+	}
+	if err := e.UnmarshalBinary(plain); err != nil {
+		return err
+	}
+	e.Size = size
+	return nil
+}
+
 // appendEntry adds a log entry to the end of the file.
 func (f *LogFile) appendEntry(e *LogEntry) error {
 	// Marshal entry to the local buffer.
 	f.buf = appendLogEntry(f.buf[:0], e)
 
+	// Seal the entry when encryption is enabled.
+	if f.keyring != nil {
+		key := f.keyring.Current()
+		var err error
+		if f.sealed, err = key.Seal(f.sealed[:0], f.buf, nil); err != nil {
+			return err
+		}
+		f.buf = encryption.AppendKeyID(append(f.buf[:0], logEntryEncryptedFlag), key.ID())
+		var buf [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(buf[:], uint64(len(f.sealed)))
+		f.buf = append(f.buf, buf[:n]...)
+		f.buf = append(f.buf, f.sealed...)
+	}
+
 	// Save the size of the record.
 	e.Size = len(f.buf)
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/pkg/bytesutil"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
@@ -82,6 +84,9 @@ type Partition struct {
 	nosync         bool // when true, flushing and syncing of LogFile will be disabled.
 	logbufferSize  int  // the LogFile's buffer is set to this value.
 
+	// Encrypts new log and index files when set.
+	keyring *encryption.Keyring
+
 	// Frequency of compaction checks.
 	compactionInterrupt chan struct{}
 	compactionsDisabled int
@@ -231,7 +236,7 @@ func (p *Partition) Open() (err error) {
 			case LogFileExt:
 				f, err := p.openLogFile(filepath.Join(p.path, filename))
 				if err != nil {
-					return nil, err
+					return files, err
 				}
 				files = append(files, f)
 
@@ -244,7 +249,7 @@ func (p *Partition) Open() (err error) {
 			case IndexFileExt:
 				f, err := p.openIndexFile(filepath.Join(p.path, filename))
 				if err != nil {
-					return nil, err
+					return files, err
 				}
 				files = append(files, f)
 			}
@@ -300,6 +305,7 @@ func (p *Partition) openLogFile(path string) (*LogFile, error) {
 	f := NewLogFile(p.sfile, path)
 	f.nosync = p.nosync
 	f.bufferSize = p.logbufferSize
+	f.keyring = p.keyring
 
 	if err := f.Open(); err != nil {
 		return nil, err
@@ -310,6 +316,7 @@ func (p *Partition) openLogFile(path string) (*LogFile, error) {
 // openIndexFile opens a log file and appends it to the index.
 func (p *Partition) openIndexFile(path string) (*IndexFile, error) {
 	f := NewIndexFile(p.sfile)
+	f.keyring = p.keyring
 	f.SetPath(path)
 	if err := f.Open(); err != nil {
 		return nil, err
@@ -317,6 +324,17 @@ func (p *Partition) openIndexFile(path string) (*IndexFile, error) {
 	return f, nil
 }
 
+// indexFileWriter returns the writer an index file is compacted to, which
+// encrypts to f with the current key when a keyring is set, and a function
+// flushing it that must be called once the compaction is done.
+func (p *Partition) indexFileWriter(f *os.File) (io.Writer, func() error) {
+	if p.keyring == nil {
+		return f, func() error { return nil }
+	}
+	w := encryption.NewWriter(f, p.keyring.Current())
+	return w, w.Close
+}
+
 // deleteNonManifestFiles removes all files not in the manifest.
 func (p *Partition) deleteNonManifestFiles(m *Manifest) error {
 	dir, err := os.Open(p.path)
@@ -436,6 +454,11 @@ func (p *Partition) Path() string { return p.path }
 // SeriesFile returns the attached series file.
 func (p *Partition) SeriesFile() *tsdb.SeriesFile { return p.sfile }
 
+// SeriesIDSet returns a copy of the set of series ids present in the partition.
+func (p *Partition) SeriesIDSet() *tsdb.SeriesIDSet {
+	return p.seriesIDSet.Clone()
+}
+
 // NextSequence returns the next file identifier.
 func (p *Partition) NextSequence() int {
 	p.mu.Lock()
@@ -1088,8 +1111,12 @@ func (p *Partition) compactToLevel(files []*IndexFile, frefs lifecycle.Reference
 
 	// Compact all index files to new index file.
 	lvl := p.levels[level]
+	w, finish := p.indexFileWriter(f)
 	var n int64
-	if n, err = IndexFiles(files).CompactTo(f, p.sfile, lvl.M, lvl.K, interrupt); err != nil {
+	if n, err = IndexFiles(files).CompactTo(w, p.sfile, lvl.M, lvl.K, interrupt); err != nil {
+		log.Error("Cannot compact index files", zap.Error(err))
+		return
+	} else if err = finish(); err != nil {
 		log.Error("Cannot compact index files", zap.Error(err))
 		return
 	}
@@ -1102,6 +1129,7 @@ func (p *Partition) compactToLevel(files []*IndexFile, frefs lifecycle.Reference
 
 	// Reopen as an index file.
 	file := NewIndexFile(p.sfile)
+	file.keyring = p.keyring
 	file.SetPath(path)
 	if err = file.Open(); err != nil {
 		log.Error("Cannot open new index file", zap.Error(err))
@@ -1251,7 +1279,11 @@ func (p *Partition) compactLogFile(logFile *LogFile) {
 
 	// Compact log file to new index file.
 	lvl := p.levels[1]
-	n, err := logFile.CompactTo(f, lvl.M, lvl.K, interrupt)
+	w, finish := p.indexFileWriter(f)
+	n, err := logFile.CompactTo(w, lvl.M, lvl.K, interrupt)
+	if err == nil {
+		err = finish()
+	}
 	if err != nil {
 		log.Error("Cannot compact log file", zap.Error(err), zap.String("path", logFile.Path()))
 		return
@@ -1265,6 +1297,7 @@ func (p *Partition) compactLogFile(logFile *LogFile) {
 
 	// Reopen as an index file.
 	file := NewIndexFile(p.sfile)
+	file.keyring = p.keyring
 	file.SetPath(path)
 	if err := file.Open(); err != nil {
 		log.Error("Cannot open compacted index file", zap.Error(err), zap.String("path", file.Path()))
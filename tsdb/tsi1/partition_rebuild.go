@@ -0,0 +1,250 @@
+package tsi1
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+const (
+	// rebuildDirName is the directory within the index where partitions are
+	// rebuilt before being swapped into place.
+	rebuildDirName = ".rebuild"
+
+	// replacedPartitionExt is the extension given to a partition directory
+	// while it is being replaced by a rebuilt partition.
+	replacedPartitionExt = ".replaced"
+)
+
+// PartitionRebuild builds a replacement for a single partition of an open
+// Index. The index continues to serve reads and writes from the existing
+// partition until Commit atomically swaps the rebuilt partition into place.
+//
+// Series are added to the rebuilt partition with CreateSeriesListIfNotExists
+// or AddSeriesList. Series that do not belong to the partition are ignored, so
+// the same collections may be passed to the rebuilds of every partition.
+type PartitionRebuild struct {
+	index *Index
+	id    int
+	p     *Partition
+}
+
+// RebuildPartition starts a rebuild of the partition with the provided id.
+// Only one rebuild of a partition may be in progress at a time. The caller
+// must call Commit or Abort on the returned PartitionRebuild.
+func (i *Index) RebuildPartition(id int) (*PartitionRebuild, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !i.res.Opened() {
+		return nil, errors.New("index not open")
+	} else if id < 0 || id >= len(i.partitions) {
+		return nil, fmt.Errorf("invalid index partition %d", id)
+	} else if i.rebuilding[id] {
+		return nil, fmt.Errorf("index partition %d is already being rebuilt", id)
+	}
+
+	// Remove any partial rebuild left behind by a previous attempt.
+	path := filepath.Join(i.path, rebuildDirName, fmt.Sprint(id))
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+
+	p := NewPartition(i.sfile, path)
+	p.MaxLogFileSize = i.maxLogFileSize
+	p.nosync = i.disableFsync
+	p.logbufferSize = i.logfileBufferSize
+	p.keyring = i.keyring
+	p.logger = i.logger.With(zap.String("tsi1_partition_rebuild", fmt.Sprint(id+1)))
+	p.tracker.enabled = false // The live partition reports the metrics.
+	if err := p.Open(); err != nil {
+		return nil, err
+	}
+
+	if i.rebuilding == nil {
+		i.rebuilding = make(map[int]bool)
+	}
+	i.rebuilding[id] = true
+
+	i.logger.Info("Rebuilding index partition", zap.Int("partition", id))
+	return &PartitionRebuild{index: i, id: id, p: p}, nil
+}
+
+// ID returns the id of the partition being rebuilt.
+func (r *PartitionRebuild) ID() int { return r.id }
+
+// CreateSeriesListIfNotExists adds the series in collection that belong to the
+// partition being rebuilt. Series missing from the series file are created.
+func (r *PartitionRebuild) CreateSeriesListIfNotExists(collection *tsdb.SeriesCollection) error {
+	if err := r.index.sfile.CreateSeriesListIfNotExists(collection); err != nil {
+		return err
+	}
+	return r.AddSeriesList(collection)
+}
+
+// AddSeriesList adds the series in collection that belong to the partition
+// being rebuilt. The collection must already have its series ids assigned, as
+// is the case once it has been written to the Index.
+func (r *PartitionRebuild) AddSeriesList(collection *tsdb.SeriesCollection) error {
+	var pc tsdb.SeriesCollection
+	for iter := collection.Iterator(); iter.Next(); {
+		if iter.SeriesID().IsZero() || r.index.partitionIdx(iter.Key()) != r.id {
+			continue
+		}
+		pc.Names = append(pc.Names, iter.Name())
+		pc.Tags = append(pc.Tags, iter.Tags())
+		pc.SeriesIDs = append(pc.SeriesIDs, iter.SeriesID())
+	}
+
+	_, err := r.p.createSeriesListIfNotExists(&pc)
+	return err
+}
+
+// Commit replaces the partition in the index with the rebuilt partition. Any
+// series deleted from the series file during the rebuild are dropped first.
+//
+// The caller must ensure that no series are created in, or dropped from, the
+// index while Commit runs. The replaced partition is closed in the background
+// once any queries using it have released it.
+func (r *PartitionRebuild) Commit() (err error) {
+	i := r.index
+	defer func() {
+		if err != nil {
+			r.finish()
+		}
+	}()
+
+	// Drop series deleted since they were added to the rebuilt partition.
+	var deleted []tsdb.SeriesID
+	r.p.seriesIDSet.ForEach(func(id tsdb.SeriesID) {
+		if i.sfile.IsDeleted(id) {
+			deleted = append(deleted, id)
+		}
+	})
+	for _, id := range deleted {
+		if err := r.p.DropSeries(id); err != nil {
+			r.p.Close()
+			os.RemoveAll(r.p.Path())
+			return err
+		}
+	}
+
+	// Close the rebuilt partition so that its files can be moved into place.
+	if err := r.p.Close(); err != nil {
+		os.RemoveAll(r.p.Path())
+		return err
+	}
+
+	i.mu.Lock()
+	old := i.partitions[r.id]
+
+	// The replaced partition must not write any new files into its directory
+	// once the rebuilt partition has taken it over.
+	old.DisableCompactions()
+	old.Wait()
+
+	replacedPath := filepath.Join(i.path, rebuildDirName, fmt.Sprint(r.id)+replacedPartitionExt)
+	p, err := r.swap(old.Path(), replacedPath)
+	if err != nil {
+		old.EnableCompactions()
+		i.mu.Unlock()
+		os.RemoveAll(r.p.Path())
+		return err
+	}
+	i.partitions[r.id] = p
+	i.mu.Unlock()
+
+	// Cached series id sets may have been built from the replaced partition.
+	i.tagValueCache.Clear()
+
+	i.logger.Info("Replaced index partition", zap.Int("partition", r.id), zap.Uint64("series", p.seriesIDSet.Cardinality()))
+
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		defer r.finish()
+
+		if err := old.Close(); err != nil {
+			i.logger.Error("Failed to close replaced index partition", zap.Int("partition", r.id), zap.Error(err))
+			return
+		}
+		if err := os.RemoveAll(replacedPath); err != nil {
+			i.logger.Error("Failed to remove replaced index partition", zap.String("path", replacedPath), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// swap moves the rebuilt partition files to path, moving the files already at
+// path to replacedPath, and opens the rebuilt partition from path. If the
+// rebuilt partition cannot be opened the original files are restored.
+func (r *PartitionRebuild) swap(path, replacedPath string) (*Partition, error) {
+	if err := os.Rename(path, replacedPath); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(r.p.Path(), path); err != nil {
+		os.Rename(replacedPath, path)
+		return nil, err
+	}
+
+	p := r.index.newPartition(r.id, path)
+	if err := p.Open(); err != nil {
+		os.Rename(path, r.p.Path())
+		os.Rename(replacedPath, path)
+		return nil, err
+	}
+	return p, nil
+}
+
+// Abort discards the rebuilt partition, leaving the index unchanged.
+func (r *PartitionRebuild) Abort() error {
+	defer r.finish()
+
+	if err := r.p.Close(); err != nil {
+		return err
+	}
+	return os.RemoveAll(r.p.Path())
+}
+
+// finish marks the rebuild of the partition as no longer in progress.
+func (r *PartitionRebuild) finish() {
+	r.index.mu.Lock()
+	delete(r.index.rebuilding, r.id)
+	r.index.mu.Unlock()
+}
+
+// recoverRebuilds restores any partition that was moved aside by a rebuild
+// which was interrupted before the rebuilt partition was moved into place, and
+// removes all other rebuild files.
+func (i *Index) recoverRebuilds() error {
+	dir := filepath.Join(i.path, rebuildDirName)
+	fis, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, fi := range fis {
+		if !strings.HasSuffix(fi.Name(), replacedPartitionExt) {
+			continue
+		}
+
+		path := filepath.Join(i.path, strings.TrimSuffix(fi.Name(), replacedPartitionExt))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			i.logger.Warn("Restoring index partition after interrupted rebuild", zap.String("path", path))
+			if err := os.Rename(filepath.Join(dir, fi.Name()), path); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(dir)
+}
@@ -2,6 +2,7 @@ package tsm1_test
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"math"
@@ -12,6 +13,8 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/tsdb/cursors"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 )
@@ -261,6 +264,122 @@ func TestCompactor_CompactFull(t *testing.T) {
 	}
 }
 
+// Ensures that compactions are rate limited rather than failing when the
+// throughput is less than the size of the writes to the TSM file.
+func TestCompactor_Snapshot_ThroughputBelowBlockSize(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	c := tsm1.NewCache(0)
+	for i := 0; i < 10; i++ {
+		if err := c.Write([]byte(fmt.Sprintf("cpu,host=%d#!~#value", i)), []tsm1.Value{tsm1.NewValue(1, float64(i))}); err != nil {
+			t.Fatalf("failed to write to cache: %v", err)
+		}
+	}
+
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = &fakeFileStore{}
+	compactor.RateLimit = limiter.NewRate(256, 0)
+	compactor.Open()
+
+	files, err := compactor.WriteSnapshot(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error writing snapshot: %v", err)
+	}
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	fi, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatal(err)
+	} else if fi.Size() <= 256 {
+		t.Fatalf("expected a file larger than the throughput, got %d bytes", fi.Size())
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+	if got, exp := r.KeyCount(), 10; got != exp {
+		t.Fatalf("keys length mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+// Ensures that a compaction rewrites files with the current encryption key.
+func TestCompactor_CompactFull_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	old, cur := mustEncryptionKey(t, "old", 1), mustEncryptionKey(t, "cur", 2)
+	kr, err := encryption.NewKeyring(old, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One file is not encrypted and the other is encrypted with the previous key.
+	a1 := tsm1.NewValue(1, 1.1)
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{"cpu,host=A#!~#value": {a1}})
+
+	b1 := tsm1.NewValue(1, 2.1)
+	w, f2 := MustEncryptedTSMWriter(dir, 2, old)
+	if err := w.Write([]byte("cpu,host=B#!~#value"), []tsm1.Value{b1}); err != nil {
+		t.Fatal(err)
+	} else if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := &fakeFileStore{keyring: kr}
+	defer fs.Close()
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.WithKeyring(kr)
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	} else if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tsm1.NewTSMReader(f); err == nil {
+		t.Fatal("expected encrypted file not to open without a keyring")
+	}
+
+	only, err := encryption.NewKeyring(cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err = os.Open(files[0]); err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(f, tsm1.WithKeyring(only))
+	if err != nil {
+		t.Fatalf("unexpected error opening compacted file with the current key: %v", err)
+	}
+	defer r.Close()
+
+	if r.Stats().StaleKey {
+		t.Error("expected compacted file to be encrypted with the current key")
+	}
+	for key, exp := range map[string]tsm1.Value{"cpu,host=A#!~#value": a1, "cpu,host=B#!~#value": b1} {
+		values, err := r.ReadAll([]byte(key))
+		if err != nil {
+			t.Fatalf("unexpected error reading: %v", err)
+		} else if len(values) != 1 {
+			t.Fatalf("values length mismatch %s: got %v, exp 1", key, len(values))
+		}
+		assertValueEqual(t, values[0], exp)
+	}
+}
+
 // Ensures that a compaction will properly merge multiple TSM files
 func TestCompactor_Compact_OverlappingBlocks(t *testing.T) {
 	dir := MustTempDir()
@@ -1991,6 +2110,47 @@ func TestDefaultPlanner_PlanLevel_Tombstone(t *testing.T) {
 	}
 }
 
+func TestDefaultPlanner_PlanLevel_StaleKey(t *testing.T) {
+	data := []tsm1.FileStat{
+		{
+			Path:     "01-03.tsm1",
+			Size:     251 * 1024 * 1024,
+			StaleKey: true,
+		},
+		{
+			Path: "02-03.tsm1",
+			Size: 1 * 1024 * 1024,
+		},
+		{
+			Path: "03-01.tsm1",
+			Size: 2 * 1024 * 1024 * 1024,
+		},
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsm1.DefaultCompactFullWriteColdDuration,
+	)
+
+	expFiles := []tsm1.FileStat{data[0], data[1]}
+	tsm := cp.PlanLevel(3)
+	if len(tsm) == 0 {
+		t.Fatal("expected files with a stale key to be compacted")
+	}
+	if exp, got := len(expFiles), len(tsm[0]); got != exp {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, p := range expFiles {
+		if got, exp := tsm[0][i], p.Path; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+}
+
 func TestDefaultPlanner_PlanLevel_Multiple(t *testing.T) {
 	data := []tsm1.FileStat{
 		{
@@ -2906,7 +3066,7 @@ func assertValueEqual(t *testing.T, a, b tsm1.Value) {
 	}
 }
 
-func MustTSMWriter(dir string, gen int) (tsm1.TSMWriter, string) {
+func mustTSMFile(dir string, gen int) (*os.File, string) {
 	f := MustTempFile(dir)
 	oldName := f.Name()
 
@@ -2927,12 +3087,34 @@ func MustTSMWriter(dir string, gen int) (tsm1.TSMWriter, string) {
 		panic(fmt.Sprintf("open tsm files: %v", err))
 	}
 
+	return f, newName
+}
+
+func MustTSMWriter(dir string, gen int) (tsm1.TSMWriter, string) {
+	f, name := mustTSMFile(dir, gen)
 	w, err := tsm1.NewTSMWriter(f)
 	if err != nil {
 		panic(fmt.Sprintf("create TSM writer: %v", err))
 	}
+	return w, name
+}
+
+func MustEncryptedTSMWriter(dir string, gen int, key *encryption.Key) (tsm1.TSMWriter, string) {
+	f, name := mustTSMFile(dir, gen)
+	w, err := tsm1.NewTSMWriter(f, tsm1.WithEncryptionKey(key))
+	if err != nil {
+		panic(fmt.Sprintf("create TSM writer: %v", err))
+	}
+	return w, name
+}
 
-	return w, newName
+func mustEncryptionKey(t *testing.T, id string, fill byte) *encryption.Key {
+	t.Helper()
+	k, err := encryption.NewKey(id, bytes.Repeat([]byte{fill}, encryption.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
 }
 
 func MustWriteTSM(dir string, gen int, values map[string][]tsm1.Value) string {
@@ -2983,6 +3165,7 @@ type fakeFileStore struct {
 	lastModified time.Time
 	blockCount   int
 	readers      []*tsm1.TSMReader
+	keyring      *encryption.Keyring
 }
 
 func (w *fakeFileStore) Stats() []tsm1.FileStat {
@@ -3002,7 +3185,14 @@ func (w *fakeFileStore) BlockCount(path string, idx int) int {
 }
 
 func (w *fakeFileStore) TSMReader(path string) *tsm1.TSMReader {
-	r := MustOpenTSMReader(path)
+	f, err := os.Open(path)
+	if err != nil {
+		panic(fmt.Sprintf("open file: %v", err))
+	}
+	r, err := tsm1.NewTSMReader(f, tsm1.WithKeyring(w.keyring))
+	if err != nil {
+		panic(fmt.Sprintf("new reader: %v", err))
+	}
 	w.readers = append(w.readers, r)
 	r.Ref()
 	return r
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime"
 
+	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/pool"
 	"github.com/influxdata/influxql"
 )
@@ -148,6 +149,25 @@ func BlockType(block []byte) (byte, error) {
 	}
 }
 
+// BlockTypeFieldType returns the field type of the values stored in a block of
+// type typ, or models.Empty if typ is not a known block type.
+func BlockTypeFieldType(typ byte) models.FieldType {
+	switch typ {
+	case BlockFloat64:
+		return models.Float
+	case BlockInteger:
+		return models.Integer
+	case BlockBoolean:
+		return models.Boolean
+	case BlockString:
+		return models.String
+	case BlockUnsigned:
+		return models.Unsigned
+	default:
+		return models.Empty
+	}
+}
+
 // BlockCount returns the number of timestamps encoded in block.
 func BlockCount(block []byte) int {
 	if len(block) <= encodedBlockHeaderSize {
@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/metrics"
@@ -162,6 +163,16 @@ type Engine struct {
 
 	scheduler   *scheduler
 	snapshotter Snapshotter
+
+	// The following fields track the state exposed by the compaction control
+	// and introspection API. They are protected by compactionsMu.
+	compactionsMu             sync.RWMutex
+	compactionsPaused         bool
+	compactionThroughput      int
+	compactionThroughputBurst int
+	nextCompactionID          uint64
+	runningCompactions        map[uint64]*runningCompaction
+	plannedCompactions        [5][]CompactionGroup // indexed by level; 4 is full or optimize.
 }
 
 // NewEngine returns a new instance of Engine.
@@ -180,24 +191,7 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 		int(config.Compaction.ThroughputBurst))
 
 	// determine max concurrent compactions informed by the system
-	maxCompactions := config.Compaction.MaxConcurrent
-	if maxCompactions == 0 {
-		maxCompactions = runtime.GOMAXPROCS(0) / 2 // Default to 50% of cores for compactions
-
-		// On systems with more cores, cap at 4 to reduce disk utilization.
-		if maxCompactions > 4 {
-			maxCompactions = 4
-		}
-
-		if maxCompactions < 1 {
-			maxCompactions = 1
-		}
-	}
-
-	// Don't allow more compactions to run than cores.
-	if maxCompactions > runtime.GOMAXPROCS(0) {
-		maxCompactions = runtime.GOMAXPROCS(0)
-	}
+	maxCompactions := maxConcurrentCompactions(config.Compaction.MaxConcurrent)
 
 	logger := zap.NewNop()
 	e := &Engine{
@@ -221,6 +215,10 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 		compactionLimiter:              limiter.NewFixed(maxCompactions),
 		scheduler:                      newScheduler(maxCompactions),
 		snapshotter:                    new(noSnapshotter),
+
+		compactionThroughput:      int(config.Compaction.Throughput),
+		compactionThroughputBurst: int(config.Compaction.ThroughputBurst),
+		runningCompactions:        make(map[uint64]*runningCompaction),
 	}
 
 	for _, option := range options {
@@ -230,6 +228,29 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 	return e
 }
 
+// maxConcurrentCompactions returns the number of compactions allowed to run
+// concurrently for the configured value n, informed by the system.
+func maxConcurrentCompactions(n int) int {
+	if n == 0 {
+		n = runtime.GOMAXPROCS(0) / 2 // Default to 50% of cores for compactions
+
+		// On systems with more cores, cap at 4 to reduce disk utilization.
+		if n > 4 {
+			n = 4
+		}
+
+		if n < 1 {
+			n = 1
+		}
+	}
+
+	// Don't allow more compactions to run than cores.
+	if n > runtime.GOMAXPROCS(0) {
+		n = runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
 func (e *Engine) WithFormatFileNameFunc(formatFileNameFunc FormatFileNameFunc) {
 	e.Compactor.WithFormatFileNameFunc(formatFileNameFunc)
 	e.formatFileName = formatFileNameFunc
@@ -244,6 +265,13 @@ func (e *Engine) WithFileStoreObserver(obs FileStoreObserver) {
 	e.FileStore.WithObserver(obs)
 }
 
+// WithKeyring encrypts new TSM files with the current key of kr, and reads
+// files encrypted with any of its keys.
+func (e *Engine) WithKeyring(kr *encryption.Keyring) {
+	e.FileStore.WithKeyring(kr)
+	e.Compactor.WithKeyring(kr)
+}
+
 func (e *Engine) WithCompactionPlanner(planner CompactionPlanner) {
 	planner.SetFileStore(e.FileStore)
 	e.CompactionPlan = planner
@@ -266,7 +294,9 @@ func (e *Engine) SetEnabled(enabled bool) {
 func (e *Engine) SetCompactionsEnabled(enabled bool) {
 	if enabled {
 		e.enableSnapshotCompactions()
-		e.enableLevelCompactions(false)
+		if !e.CompactionsPaused() {
+			e.enableLevelCompactions(false)
+		}
 	} else {
 		e.disableSnapshotCompactions()
 		e.disableLevelCompactions(false)
@@ -653,6 +683,7 @@ type compactionTracker struct {
 	active [6]uint64 // Gauge of TSM compactions (by level) currently running.
 	errors [6]uint64 // Counter of TSM compcations (by level) that have failed due to error.
 	queue  [6]uint64 // Gauge of TSM compactions queues (by level).
+	bytes  [6]uint64 // Gauge of TSM bytes in compaction queues (by level).
 }
 
 func newCompactionTracker(metrics *compactionMetrics, defaultLables prometheus.Labels) *compactionTracker {
@@ -763,6 +794,17 @@ func (t *compactionTracker) SetQueue(level compactionLevel, length uint64) {
 	t.metrics.CompactionQueue.With(labels).Set(float64(length))
 }
 
+// QueueBytes returns the number of bytes in the compaction queue for the provided level.
+func (t *compactionTracker) QueueBytes(level int) uint64 { return atomic.LoadUint64(&t.bytes[level]) }
+
+// SetQueueBytes sets the number of bytes queued for compaction at the provided level.
+func (t *compactionTracker) SetQueueBytes(level compactionLevel, n uint64) {
+	atomic.StoreUint64(&t.bytes[level], n)
+
+	labels := t.Labels(level)
+	t.metrics.CompactionQueueBytes.With(labels).Set(float64(n))
+}
+
 // SetOptimiseQueue sets the queue depth for Optimisation compactions.
 func (t *compactionTracker) SetOptimiseQueue(length uint64) { t.SetQueue(4, length) }
 
@@ -970,6 +1012,7 @@ func (e *Engine) compact(wg *sync.WaitGroup) {
 			e.compactionTracker.SetQueue(1, uint64(len(level1Groups)))
 			e.compactionTracker.SetQueue(2, uint64(len(level2Groups)))
 			e.compactionTracker.SetQueue(3, uint64(len(level3Groups)))
+			e.setPlannedCompactions(level1Groups, level2Groups, level3Groups, level4Groups)
 
 			// Set the queue depths on the scheduler
 			e.scheduler.setDepth(1, len(level1Groups))
@@ -978,7 +1021,9 @@ func (e *Engine) compact(wg *sync.WaitGroup) {
 			e.scheduler.setDepth(4, len(level4Groups))
 
 			// Find the next compaction that can run and try to kick it off
+			e.mu.RLock()
 			level, runnable := e.scheduler.next()
+			e.mu.RUnlock()
 			if runnable {
 				span.LogKV("level", level)
 				switch level {
@@ -1023,14 +1068,17 @@ func (e *Engine) compactHiPriorityLevel(ctx context.Context, grp CompactionGroup
 	}
 
 	// Try hi priority limiter, otherwise steal a little from the low priority if we can.
-	if e.compactionLimiter.TryTake() {
+	compactionLimiter := e.currentCompactionLimiter()
+	if compactionLimiter.TryTake() {
 		e.compactionTracker.IncActive(level)
+		id := e.trackCompaction(level, grp)
 
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer e.compactionTracker.DecActive(level)
-			defer e.compactionLimiter.Release()
+			defer compactionLimiter.Release()
+			defer e.untrackCompaction(id)
 			s.Apply(ctx)
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
@@ -1051,13 +1099,16 @@ func (e *Engine) compactLoPriorityLevel(ctx context.Context, grp CompactionGroup
 	}
 
 	// Try the lo priority limiter, otherwise steal a little from the high priority if we can.
-	if e.compactionLimiter.TryTake() {
+	compactionLimiter := e.currentCompactionLimiter()
+	if compactionLimiter.TryTake() {
 		e.compactionTracker.IncActive(level)
+		id := e.trackCompaction(level, grp)
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer e.compactionTracker.DecActive(level)
-			defer e.compactionLimiter.Release()
+			defer compactionLimiter.Release()
+			defer e.untrackCompaction(id)
 			s.Apply(ctx)
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
@@ -1076,13 +1127,16 @@ func (e *Engine) compactFull(ctx context.Context, grp CompactionGroup, wg *sync.
 	}
 
 	// Try the lo priority limiter, otherwise steal a little from the high priority if we can.
-	if e.compactionLimiter.TryTake() {
+	compactionLimiter := e.currentCompactionLimiter()
+	if compactionLimiter.TryTake() {
 		e.compactionTracker.IncFullActive()
+		id := e.trackCompaction(s.level, grp)
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			defer e.compactionTracker.DecFullActive()
-			defer e.compactionLimiter.Release()
+			defer compactionLimiter.Release()
+			defer e.untrackCompaction(id)
 			s.Apply(ctx)
 			// Release the files in the compaction plan
 			e.CompactionPlan.Release([]CompactionGroup{s.group})
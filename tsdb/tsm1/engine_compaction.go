@@ -0,0 +1,248 @@
+package tsm1
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/limiter"
+	"go.uber.org/zap"
+)
+
+// CompactionInfo describes a single running or planned compaction.
+type CompactionInfo struct {
+	// Level is the compaction level: "1", "2", "3", "optimize" or "full".
+	// Planned full and optimize compactions are reported as "full".
+	Level string `json:"level"`
+
+	Files       []string `json:"files"`
+	Generations []int    `json:"generations"`
+	Bytes       int64    `json:"bytes"`
+
+	// Started is the time the compaction began. It is zero for planned compactions.
+	Started time.Time `json:"started,omitempty"`
+}
+
+// CompactionStatus describes the state of compactions within an Engine.
+type CompactionStatus struct {
+	Paused          bool             `json:"paused"`
+	Throughput      int              `json:"throughput"`
+	ThroughputBurst int              `json:"throughputBurst"`
+	MaxConcurrent   int              `json:"maxConcurrent"`
+	Running         []CompactionInfo `json:"running"`
+	Planned         []CompactionInfo `json:"planned"`
+}
+
+// runningCompaction records a compaction started by the Engine.
+type runningCompaction struct {
+	level   compactionLevel
+	group   CompactionGroup
+	started time.Time
+}
+
+// CompactionStatus returns the running and planned compactions along with the
+// current compaction settings of the engine.
+func (e *Engine) CompactionStatus() CompactionStatus {
+	sizes := make(map[string]int64)
+	for _, st := range e.FileStore.Stats() {
+		sizes[st.Path] = int64(st.Size)
+	}
+
+	e.mu.RLock()
+	maxConcurrent := e.compactionLimiter.Capacity()
+	e.mu.RUnlock()
+
+	e.compactionsMu.RLock()
+	defer e.compactionsMu.RUnlock()
+
+	status := CompactionStatus{
+		Paused:          e.compactionsPaused,
+		Throughput:      e.compactionThroughput,
+		ThroughputBurst: e.compactionThroughputBurst,
+		MaxConcurrent:   maxConcurrent,
+		Running:         make([]CompactionInfo, 0, len(e.runningCompactions)),
+		Planned:         []CompactionInfo{},
+	}
+
+	for _, rc := range e.runningCompactions {
+		info := e.compactionInfo(rc.level, rc.group, sizes)
+		info.Started = rc.started
+		status.Running = append(status.Running, info)
+	}
+	sort.Slice(status.Running, func(i, j int) bool {
+		return status.Running[i].Started.Before(status.Running[j].Started)
+	})
+
+	for level, groups := range e.plannedCompactions {
+		if level == 4 {
+			level = 5
+		}
+		for _, group := range groups {
+			status.Planned = append(status.Planned, e.compactionInfo(compactionLevel(level), group, sizes))
+		}
+	}
+	return status
+}
+
+// compactionInfo builds a CompactionInfo for the group at the provided level.
+func (e *Engine) compactionInfo(level compactionLevel, group CompactionGroup, sizes map[string]int64) CompactionInfo {
+	info := CompactionInfo{
+		Level: level.String(),
+		Files: append([]string(nil), group...),
+	}
+
+	seen := make(map[int]struct{}, len(group))
+	for _, f := range group {
+		info.Bytes += sizes[f]
+		gen, _, err := e.FileStore.ParseFileName(f)
+		if err != nil {
+			continue
+		}
+		if _, ok := seen[gen]; !ok {
+			seen[gen] = struct{}{}
+			info.Generations = append(info.Generations, gen)
+		}
+	}
+	sort.Ints(info.Generations)
+	return info
+}
+
+// CompactionsPaused returns true if level, optimize and full compactions have
+// been paused via PauseCompactions.
+func (e *Engine) CompactionsPaused() bool {
+	e.compactionsMu.RLock()
+	defer e.compactionsMu.RUnlock()
+	return e.compactionsPaused
+}
+
+// PauseCompactions stops all level, optimize and full compactions, aborting
+// any that are running. Cache snapshots continue to run so that writes are not
+// rejected. Compactions remain paused until ResumeCompactions is called.
+func (e *Engine) PauseCompactions() {
+	e.compactionsMu.Lock()
+	e.compactionsPaused = true
+	e.compactionsMu.Unlock()
+
+	e.disableLevelCompactions(false)
+	e.logger.Info("Compactions paused")
+}
+
+// ResumeCompactions restarts compactions previously stopped by PauseCompactions.
+func (e *Engine) ResumeCompactions() {
+	e.compactionsMu.Lock()
+	e.compactionsPaused = false
+	e.compactionsMu.Unlock()
+
+	// Only restart level compactions if the engine has them enabled.
+	e.mu.RLock()
+	enabled := e.snapDone != nil
+	e.mu.RUnlock()
+	if enabled {
+		e.enableLevelCompactions(false)
+	}
+	e.logger.Info("Compactions resumed")
+}
+
+// SetCompactionThroughput changes the rate limit, in bytes per second, that
+// compactions may write to disk. A throughput of zero disables rate limiting.
+func (e *Engine) SetCompactionThroughput(throughput, burst int) error {
+	if throughput < 0 || burst < 0 {
+		return errors.New("compaction throughput must not be negative")
+	}
+	if burst == 0 {
+		burst = throughput
+	}
+
+	if err := limiter.UpdateRate(e.Compactor.RateLimit, throughput, burst); err != nil {
+		return err
+	}
+
+	e.compactionsMu.Lock()
+	e.compactionThroughput, e.compactionThroughputBurst = throughput, burst
+	e.compactionsMu.Unlock()
+
+	e.logger.Info("Compaction throughput changed", zap.Int("throughput", throughput), zap.Int("throughput_burst", burst))
+	return nil
+}
+
+// SetMaxConcurrentCompactions changes the maximum number of compactions that
+// may run at one time. A value of 0 uses the same default as the
+// max-concurrent configuration option. Running compactions are not affected.
+func (e *Engine) SetMaxConcurrentCompactions(n int) error {
+	if n < 0 {
+		return errors.New("max concurrent compactions must not be negative")
+	}
+	n = maxConcurrentCompactions(n)
+
+	e.mu.Lock()
+	e.compactionLimiter = limiter.NewFixed(n)
+	e.scheduler.maxConcurrency = n
+	e.mu.Unlock()
+
+	e.logger.Info("Max concurrent compactions changed", zap.Int("max_concurrent", n))
+	return nil
+}
+
+// currentCompactionLimiter returns the limiter that new compactions must take
+// a token from. Callers must release the token on the same limiter.
+func (e *Engine) currentCompactionLimiter() limiter.Fixed {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.compactionLimiter
+}
+
+// trackCompaction records a started compaction and returns its identifier.
+func (e *Engine) trackCompaction(level compactionLevel, group CompactionGroup) uint64 {
+	e.compactionsMu.Lock()
+	defer e.compactionsMu.Unlock()
+
+	e.nextCompactionID++
+	e.runningCompactions[e.nextCompactionID] = &runningCompaction{
+		level:   level,
+		group:   group,
+		started: time.Now(),
+	}
+	return e.nextCompactionID
+}
+
+// untrackCompaction removes a compaction recorded by trackCompaction.
+func (e *Engine) untrackCompaction(id uint64) {
+	e.compactionsMu.Lock()
+	defer e.compactionsMu.Unlock()
+	delete(e.runningCompactions, id)
+}
+
+// setPlannedCompactions records the most recent compaction plans for each
+// level and updates the queued bytes metrics.
+func (e *Engine) setPlannedCompactions(level1, level2, level3, level4 []CompactionGroup) {
+	sizes := make(map[string]uint64)
+	for _, st := range e.FileStore.Stats() {
+		sizes[st.Path] = uint64(st.Size)
+	}
+
+	plans := [5][]CompactionGroup{nil, copyGroups(level1), copyGroups(level2), copyGroups(level3), copyGroups(level4)}
+	for level := 1; level < len(plans); level++ {
+		var n uint64
+		for _, group := range plans[level] {
+			for _, f := range group {
+				n += sizes[f]
+			}
+		}
+		e.compactionTracker.SetQueueBytes(compactionLevel(level), n)
+	}
+
+	e.compactionsMu.Lock()
+	e.plannedCompactions = plans
+	e.compactionsMu.Unlock()
+}
+
+func copyGroups(groups []CompactionGroup) []CompactionGroup {
+	if len(groups) == 0 {
+		return nil
+	}
+	other := make([]CompactionGroup, len(groups))
+	for i, g := range groups {
+		other[i] = append(CompactionGroup(nil), g...)
+	}
+	return other
+}
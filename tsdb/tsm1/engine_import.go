@@ -0,0 +1,158 @@
+package tsm1
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// importBufferSize is the size of the values an Importer buffers before
+// writing them to a TSM file.
+const importBufferSize = 64 * 1024 * 1024
+
+// Importer writes values directly to new TSM files, bypassing the WAL and
+// cache, and adds the files to the Engine when committed. Values imported for
+// a key replace any existing values with the same timestamp in the TSM files.
+//
+// Importer is not safe for concurrent use.
+type Importer struct {
+	e *Engine
+
+	values map[string][]Value
+	size   int
+	files  []string
+	closed bool
+}
+
+// NewImporter returns a new Importer. The caller must call Commit or Abort
+// on the returned Importer.
+func (e *Engine) NewImporter() *Importer {
+	return &Importer{
+		e:      e,
+		values: make(map[string][]Value),
+	}
+}
+
+// Write buffers values, keyed by series and field composite key, for import.
+// The values of each key must all have the same type.
+func (i *Importer) Write(values map[string][]Value) error {
+	if i.closed {
+		return errors.New("importer closed")
+	}
+
+	for k, vs := range values {
+		i.values[k] = append(i.values[k], vs...)
+		i.size += Values(vs).Size()
+	}
+
+	if i.size < importBufferSize {
+		return nil
+	}
+	return i.flush()
+}
+
+// Commit writes any buffered values and atomically adds every TSM file
+// written by the Importer to the Engine.
+func (i *Importer) Commit() error {
+	if i.closed {
+		return errors.New("importer closed")
+	}
+
+	if err := i.flush(); err != nil {
+		i.Abort()
+		return err
+	}
+	i.closed = true
+
+	if len(i.files) == 0 {
+		return nil
+	}
+
+	if err := i.e.FileStore.Replace(nil, i.files); err != nil {
+		i.removeFiles()
+		return err
+	}
+
+	i.e.logger.Info("Imported TSM files", zap.Strings("files", i.files))
+	return nil
+}
+
+// Abort discards the buffered values and any TSM files written by the Importer.
+func (i *Importer) Abort() {
+	if i.closed {
+		return
+	}
+	i.closed = true
+	i.values = nil
+	i.removeFiles()
+}
+
+// removeFiles removes the temporary TSM files written by the Importer.
+func (i *Importer) removeFiles() {
+	for _, path := range i.files {
+		os.Remove(path)
+		os.Remove(StatsFilename(path))
+	}
+	i.files = nil
+}
+
+// flush writes the buffered values to a new temporary TSM file.
+func (i *Importer) flush() error {
+	if len(i.values) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(i.values))
+	for k := range i.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	generation := i.e.FileStore.NextGeneration()
+	path := filepath.Join(i.e.path, fmt.Sprintf("%s.%s.%s", i.e.formatFileName(generation, 1), TSMFileExtension, TmpTSMFileExtension))
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+
+	w, err := NewTSMWriter(f, i.e.Compactor.writerOptions()...)
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+
+	if err := func() error {
+		for _, k := range keys {
+			values := Values(i.values[k]).Deduplicate()
+			for len(values) > 0 {
+				n := len(values)
+				if n > MaxPointsPerBlock {
+					n = MaxPointsPerBlock
+				}
+				if err := w.Write([]byte(k), values[:n]); err != nil {
+					return fmt.Errorf("%q: %v", k, err)
+				}
+				values = values[n:]
+			}
+		}
+
+		if err := w.WriteIndex(); err != nil {
+			return err
+		}
+		return w.Close()
+	}(); err != nil {
+		w.Remove()
+		return err
+	}
+
+	i.files = append(i.files, path)
+	i.values = make(map[string][]Value)
+	i.size = 0
+	return nil
+}
@@ -0,0 +1,86 @@
+package tsm1
+
+import (
+	"github.com/influxdata/influxdb/models"
+)
+
+// KeySnapshot is a point-in-time view of the keys stored in the cache and TSM
+// files of an Engine. The TSM files in the view remain readable until Release
+// is called, even if they are replaced by compactions in the meantime.
+type KeySnapshot struct {
+	cacheKeys  [][]byte
+	cacheTypes []models.FieldType
+	files      []TSMFile
+}
+
+// AcquireKeySnapshot returns a view of all the keys currently held by the
+// engine. The caller must call Release on the returned snapshot.
+//
+// Keys move from the cache into TSM files when the cache is snapshotted. For the
+// view to contain every key written before it was acquired, callers must ensure
+// that no cache snapshot is acquired or committed concurrently, for example by
+// holding the lock used by the engine's Snapshotter.
+func (e *Engine) AcquireKeySnapshot() *KeySnapshot {
+	s := &KeySnapshot{}
+
+	e.FileStore.mu.RLock()
+	s.files = make([]TSMFile, len(e.FileStore.files))
+	copy(s.files, e.FileStore.files)
+	for _, f := range s.files {
+		f.Ref()
+	}
+	e.FileStore.mu.RUnlock()
+
+	e.Cache.mu.RLock()
+	stores := []*ring{e.Cache.store}
+	if e.Cache.snapshot != nil {
+		stores = append(stores, e.Cache.snapshot.store)
+	}
+	e.Cache.mu.RUnlock()
+
+	for _, store := range stores {
+		for _, key := range store.keys(false) {
+			typ, err := e.Cache.Type(key)
+			if err != nil {
+				continue
+			}
+			s.cacheKeys = append(s.cacheKeys, key)
+			s.cacheTypes = append(s.cacheTypes, typ)
+		}
+	}
+	return s
+}
+
+// Walk calls fn for every key in the snapshot along with the type of its
+// values. Keys that are present in the cache and TSM files may be passed to fn
+// more than once.
+func (s *KeySnapshot) Walk(fn func(key []byte, typ models.FieldType) error) error {
+	for i, key := range s.cacheKeys {
+		if err := fn(key, s.cacheTypes[i]); err != nil {
+			return err
+		}
+	}
+
+	if len(s.files) == 0 {
+		return nil
+	}
+
+	ki := newMergeKeyIterator(s.files, nil)
+	for ki.Next() {
+		key, typ := ki.Read()
+		if err := fn(key, BlockTypeFieldType(typ)); err != nil {
+			return err
+		}
+	}
+	return ki.Err()
+}
+
+// Release releases the TSM files held by the snapshot. It is safe to call
+// Release more than once.
+func (s *KeySnapshot) Release() {
+	for _, f := range s.files {
+		f.Unref()
+	}
+	s.files = nil
+	s.cacheKeys, s.cacheTypes = nil, nil
+}
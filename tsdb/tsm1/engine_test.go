@@ -178,6 +178,61 @@ func TestEngine_ShouldCompactCache(t *testing.T) {
 	}
 }
 
+func TestEngine_CompactionControl(t *testing.T) {
+	e := MustOpenEngine()
+	defer e.Close()
+
+	if err := e.WritePointsString("cpu,host=A value=1.1 1000000000"); err != nil {
+		t.Fatal(err)
+	}
+	e.MustWriteSnapshot()
+
+	status := e.CompactionStatus()
+	if status.Paused {
+		t.Fatal("expected compactions to be running")
+	}
+	if len(status.Running) != 0 || len(status.Planned) != 0 {
+		t.Fatalf("unexpected compactions: %+v", status)
+	}
+
+	e.PauseCompactions()
+	if !e.CompactionStatus().Paused {
+		t.Fatal("expected compactions to be paused")
+	}
+
+	// Re-enabling compactions must not restart level compactions while paused.
+	e.SetCompactionsEnabled(false)
+	e.SetCompactionsEnabled(true)
+	if !e.CompactionsPaused() {
+		t.Fatal("expected compactions to remain paused")
+	}
+
+	if err := e.SetCompactionThroughput(1024, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetMaxConcurrentCompactions(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetMaxConcurrentCompactions(-1); err == nil {
+		t.Fatal("expected error for negative max concurrent compactions")
+	}
+
+	e.ResumeCompactions()
+	status = e.CompactionStatus()
+	if status.Paused {
+		t.Fatal("expected compactions to be resumed")
+	}
+	if got, exp := status.Throughput, 1024; got != exp {
+		t.Fatalf("got throughput %d, expected %d", got, exp)
+	}
+	if got, exp := status.ThroughputBurst, 1024; got != exp {
+		t.Fatalf("got throughput burst %d, expected %d", got, exp)
+	}
+	if got, exp := status.MaxConcurrent, 1; got != exp {
+		t.Fatalf("got max concurrent %d, expected %d", got, exp)
+	}
+}
+
 func makeBlockTypeSlice(n int) []byte {
 	r := make([]byte, n)
 	b := tsm1.BlockFloat64
@@ -65,9 +65,10 @@ func (m *blockMetrics) PrometheusCollectors() []prometheus.Collector {
 
 // compactionMetrics are a set of metrics concerned with tracking data about compactions.
 type compactionMetrics struct {
-	CompactionsActive  *prometheus.GaugeVec
-	CompactionDuration *prometheus.HistogramVec
-	CompactionQueue    *prometheus.GaugeVec
+	CompactionsActive    *prometheus.GaugeVec
+	CompactionDuration   *prometheus.HistogramVec
+	CompactionQueue      *prometheus.GaugeVec
+	CompactionQueueBytes *prometheus.GaugeVec
 
 	// The following metrics include a ``"status" = {ok, error}` label
 	Compactions *prometheus.CounterVec
@@ -111,6 +112,12 @@ func newCompactionMetrics(labels prometheus.Labels) *compactionMetrics {
 			Name:      "queued",
 			Help:      "Number of queued compactions.",
 		}, names),
+		CompactionQueueBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: compactionSubsystem,
+			Name:      "queued_bytes",
+			Help:      "Number of bytes of TSM data in queued compactions.",
+		}, names),
 	}
 }
 
@@ -121,6 +128,7 @@ func (m *compactionMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.CompactionsActive,
 		m.CompactionDuration,
 		m.CompactionQueue,
+		m.CompactionQueueBytes,
 	}
 }
 
@@ -123,7 +123,13 @@ func (m *mmapAccessor) readFloatBlock(entry *IndexEntry, values *[]FloatValue) (
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeFloatBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeFloatBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -142,7 +148,13 @@ func (m *mmapAccessor) readFloatArrayBlock(entry *IndexEntry, values *tsdb.Float
 		return ErrTSMClosed
 	}
 
-	err := DecodeFloatArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return err
+	}
+
+	err = DecodeFloatArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -157,7 +169,13 @@ func (m *mmapAccessor) readIntegerBlock(entry *IndexEntry, values *[]IntegerValu
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeIntegerBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeIntegerBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -176,7 +194,13 @@ func (m *mmapAccessor) readIntegerArrayBlock(entry *IndexEntry, values *tsdb.Int
 		return ErrTSMClosed
 	}
 
-	err := DecodeIntegerArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return err
+	}
+
+	err = DecodeIntegerArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -191,7 +215,13 @@ func (m *mmapAccessor) readUnsignedBlock(entry *IndexEntry, values *[]UnsignedVa
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeUnsignedBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeUnsignedBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -210,7 +240,13 @@ func (m *mmapAccessor) readUnsignedArrayBlock(entry *IndexEntry, values *tsdb.Un
 		return ErrTSMClosed
 	}
 
-	err := DecodeUnsignedArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return err
+	}
+
+	err = DecodeUnsignedArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -225,7 +261,13 @@ func (m *mmapAccessor) readStringBlock(entry *IndexEntry, values *[]StringValue)
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeStringBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeStringBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -244,7 +286,13 @@ func (m *mmapAccessor) readStringArrayBlock(entry *IndexEntry, values *tsdb.Stri
 		return ErrTSMClosed
 	}
 
-	err := DecodeStringArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return err
+	}
+
+	err = DecodeStringArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -259,7 +307,13 @@ func (m *mmapAccessor) readBooleanBlock(entry *IndexEntry, values *[]BooleanValu
 		return nil, ErrTSMClosed
 	}
 
-	a, err := DecodeBooleanBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return nil, err
+	}
+
+	a, err := DecodeBooleanBlock(b, values)
 	m.mu.RUnlock()
 
 	if err != nil {
@@ -278,7 +332,13 @@ func (m *mmapAccessor) readBooleanArrayBlock(entry *IndexEntry, values *tsdb.Boo
 		return ErrTSMClosed
 	}
 
-	err := DecodeBooleanArrayBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		m.mu.RUnlock()
+		return err
+	}
+
+	err = DecodeBooleanArrayBlock(b, values)
 	m.mu.RUnlock()
 
 	return err
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +22,8 @@ type TSMReader struct {
 
 	logger          *zap.Logger
 	madviseWillNeed bool // Hint to the kernel with MADV_WILLNEED.
+	noTombstones    bool // Open the file without applying its tombstones.
+	keyring         *encryption.Keyring
 	mu              sync.RWMutex
 
 	// accessor provides access and decoding of blocks for the reader.
@@ -51,12 +54,27 @@ var WithMadviseWillNeed = func(willNeed bool) tsmReaderOption {
 	}
 }
 
+// withoutTombstones is an option for opening a reader over every block in the
+// file, including those that have been deleted by its tombstones.
+var withoutTombstones = func() tsmReaderOption {
+	return func(r *TSMReader) {
+		r.noTombstones = true
+	}
+}
+
 var WithTSMReaderLogger = func(logger *zap.Logger) tsmReaderOption {
 	return func(r *TSMReader) {
 		r.logger = logger
 	}
 }
 
+// WithKeyring is an option for reading files encrypted with the keys of kr.
+var WithKeyring = func(kr *encryption.Keyring) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.keyring = kr
+	}
+}
+
 // NewTSMReader returns a new TSMReader from the given file.
 func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	t := &TSMReader{
@@ -76,6 +94,7 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 		logger:       t.logger,
 		f:            f,
 		mmapWillNeed: t.madviseWillNeed,
+		keyring:      t.keyring,
 	}
 
 	index, err := t.accessor.init()
@@ -85,6 +104,11 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 
 	t.index = index
 	t.tombstoner = NewTombstoner(t.Path(), index.MaybeContainsKey)
+	t.tombstoner.WithKeyring(t.keyring)
+
+	if t.noTombstones {
+		return t, nil
+	}
 
 	if err := t.applyTombstones(); err != nil {
 		return nil, err
@@ -441,7 +465,18 @@ func (t *TSMReader) Stats() FileStat {
 		MinKey:       minKey,
 		MaxKey:       maxKey,
 		HasTombstone: t.tombstoner.HasTombstones(),
+		StaleKey:     t.staleKey(),
+	}
+}
+
+// staleKey returns true if encryption is enabled and the file is not
+// encrypted with the current key, so it must be rewritten.
+func (t *TSMReader) staleKey() bool {
+	if t.keyring == nil {
+		return false
 	}
+	m, ok := t.accessor.(*mmapAccessor)
+	return ok && (m.key == nil || m.key != t.keyring.Current())
 }
 
 // BlockIterator returns a BlockIterator for the underlying TSM file.
@@ -1,12 +1,14 @@
 package tsm1
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"os"
 	"sync"
 	"sync/atomic"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/file"
 	"go.uber.org/zap"
 )
@@ -25,6 +27,11 @@ type mmapAccessor struct {
 	f  *os.File
 
 	index *indirectIndex
+
+	// keyring holds the keys encrypted files may be encrypted with, and key
+	// is the key the file is encrypted with, if it is.
+	keyring *encryption.Keyring
+	key     *encryption.Key
 }
 
 func (m *mmapAccessor) init() (*indirectIndex, error) {
@@ -63,14 +70,41 @@ func (m *mmapAccessor) init() (*indirectIndex, error) {
 		}
 	}
 
+	if m.b[4] == EncryptedVersion {
+		if m.keyring == nil {
+			return nil, fmt.Errorf("mmapAccessor: file is encrypted but no encryption keys are configured")
+		}
+		id, _, err := encryption.ReadKeyID(m.b[5:])
+		if err != nil {
+			return nil, fmt.Errorf("mmapAccessor: invalid encryption key id: %v", err)
+		}
+		if m.key, err = m.keyring.Key(id); err != nil {
+			return nil, err
+		}
+	}
+
 	indexOfsPos := len(m.b) - 8
 	indexStart := binary.BigEndian.Uint64(m.b[indexOfsPos : indexOfsPos+8])
 	if indexStart >= uint64(indexOfsPos) {
 		return nil, fmt.Errorf("mmapAccessor: invalid indexStart")
 	}
 
+	// The index of an encrypted file is decrypted to the heap rather than
+	// being accessed through the mmap.
+	index := m.b[indexStart:indexOfsPos]
+	if m.key != nil {
+		r := bytes.NewReader(index)
+		plain, err := encryption.ReadSection(make([]byte, 0, len(index)), r, m.key, offsetAD(int64(indexStart)))
+		if err != nil {
+			return nil, fmt.Errorf("mmapAccessor: cannot decrypt index: %v", err)
+		} else if r.Len() != 0 {
+			return nil, fmt.Errorf("mmapAccessor: invalid encrypted index")
+		}
+		index = plain
+	}
+
 	m.index = NewIndirectIndex()
-	if err := m.index.UnmarshalBinary(m.b[indexStart:indexOfsPos]); err != nil {
+	if err := m.index.UnmarshalBinary(index); err != nil {
 		return nil, err
 	}
 	m.index.logger = m.logger
@@ -176,8 +210,11 @@ func (m *mmapAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, er
 		return nil, ErrTSMClosed
 	}
 	//TODO: Validate checksum
-	var err error
-	values, err = DecodeBlock(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values)
+	b, err := m.blockData(entry)
+	if err != nil {
+		return nil, err
+	}
+	values, err = DecodeBlock(b, values)
 	if err != nil {
 		return nil, err
 	}
@@ -195,10 +232,22 @@ func (m *mmapAccessor) readBytes(entry *IndexEntry, b []byte) (uint32, []byte, e
 	}
 
 	// return the bytes after the 4 byte checksum
-	crc, block := binary.BigEndian.Uint32(m.b[entry.Offset:entry.Offset+4]), m.b[entry.Offset+4:entry.Offset+int64(entry.Size)]
+	crc := binary.BigEndian.Uint32(m.b[entry.Offset : entry.Offset+4])
+	block, err := m.blockData(entry)
 	m.mu.RUnlock()
 
-	return crc, block, nil
+	return crc, block, err
+}
+
+// blockData returns the data of the block at entry, after the checksum. The
+// data of an encrypted file is decrypted to a new slice, otherwise it is a
+// slice of the mmap. m.mu must be held.
+func (m *mmapAccessor) blockData(entry *IndexEntry) ([]byte, error) {
+	b := m.b[entry.Offset+4 : entry.Offset+int64(entry.Size)]
+	if m.key == nil {
+		return b, nil
+	}
+	return m.key.Open(nil, b, offsetAD(entry.Offset+4))
 }
 
 // readAll returns all values for a key in all blocks.
@@ -232,8 +281,11 @@ func (m *mmapAccessor) readAll(key []byte) ([]Value, error) {
 		}
 		//TODO: Validate checksum
 		temp = temp[:0]
-		// The +4 is the 4 byte checksum length
-		temp, err = DecodeBlock(m.b[block.Offset+4:block.Offset+int64(block.Size)], temp)
+		b, err := m.blockData(&block)
+		if err != nil {
+			return nil, err
+		}
+		temp, err = DecodeBlock(b, temp)
 		if err != nil {
 			return nil, err
 		}
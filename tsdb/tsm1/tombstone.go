@@ -41,10 +41,17 @@ Tombstone file format:
 
 NOTE: v1, v2 and v3 tombstone supports have been dropped from 2.x. Only v4 is now
 supported.
+
+In a v4 file, the entries written by each flush are a gzip member. A tombstoner
+with a keyring instead writes encrypted files, with a v5 header. Each flush
+appends a section holding the id of the key it is encrypted with, followed by
+its entries sealed in chunks bound to the offset of the section. A v4 file is
+encrypted when it is next written with a keyring.
 */
 
 import (
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"encoding/binary"
 	"errors"
@@ -56,15 +63,20 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/file"
 )
 
 const (
 	headerSize = 4
 	v4header   = 0x1504
+	v5header   = 0x1505
 )
 
-var errIncompatibleV4Version = errors.New("incompatible v4 version")
+var (
+	errIncompatibleV4Version = errors.New("incompatible v4 version")
+	errEncryptedTombstone    = errors.New("tombstone file is encrypted and no keyring is set")
+)
 
 // Tombstoner records tombstones when entries are deleted.
 type Tombstoner struct {
@@ -85,9 +97,16 @@ type Tombstoner struct {
 	// Tombstones that have been written but not flushed to disk yet.
 	tombstones []Tombstone
 
+	// Keys of encrypted tombstone files. If set, tombstones are written
+	// encrypted with the current key.
+	keyring *encryption.Keyring
+
 	// These are references used for pending writes that have not been committed.  If
-	// these are nil, then no pending writes are in progress.
+	// these are nil, then no pending writes are in progress. Tombstones are
+	// written to w, which is either gz or enc.
+	w                 io.Writer
 	gz                *gzip.Writer
+	enc               *encryption.Writer
 	bw                *bufio.Writer
 	pendingFile       *os.File
 	tmp               [8]byte
@@ -136,6 +155,12 @@ func (t *Tombstoner) WithObserver(obs FileStoreObserver) {
 	t.obs = obs
 }
 
+// WithKeyring sets the keys encrypted tombstone files are read with, and
+// that tombstones are written encrypted with.
+func (t *Tombstoner) WithKeyring(kr *encryption.Keyring) {
+	t.keyring = kr
+}
+
 // AddPrefix adds a prefix-based tombstone key.
 func (t *Tombstoner) AddPrefix(key []byte) error {
 	return t.AddPrefixRange(key, math.MinInt64, math.MaxInt64)
@@ -158,7 +183,7 @@ func (t *Tombstoner) AddPrefixRange(key []byte, min, max int64) error {
 		return err
 	}
 
-	return t.writeTombstoneV4(t.gz, Tombstone{
+	return t.writeTombstoneV4(t.w, Tombstone{
 		Key:    key,
 		Min:    min,
 		Max:    max,
@@ -201,7 +226,7 @@ func (t *Tombstoner) AddRange(keys [][]byte, min, max int64) error {
 			continue
 		}
 
-		if err := t.writeTombstoneV4(t.gz, Tombstone{
+		if err := t.writeTombstoneV4(t.w, Tombstone{
 			Key:    k,
 			Min:    min,
 			Max:    max,
@@ -311,8 +336,11 @@ func (t *Tombstoner) Walk(fn func(t Tombstone) error) error {
 	}
 
 	header := binary.BigEndian.Uint32(b[:])
-	if header == v4header {
+	switch header {
+	case v4header:
 		return t.readTombstoneV4(f, fn)
+	case v5header:
+		return t.readTombstoneV5(f, fn)
 	}
 	return errors.New("invalid tombstone file")
 }
@@ -333,7 +361,15 @@ func (t *Tombstoner) prepareLatest() error {
 		os.Remove(tmp.Name())
 	}
 
-	// Copy the existing v4 file if it exists
+	bw := bufio.NewWriterSize(tmp, 64*1024)
+
+	// The size of the file, which is the offset of the tombstones written.
+	var size int64
+
+	// v4 files that are encrypted are walked to copy their tombstones.
+	var plain *os.File
+
+	// Copy the existing file if it exists
 	f, err := os.Open(t.tombstonePath())
 	if err != nil && !os.IsNotExist(err) {
 		// An unexpected error should be returned
@@ -345,46 +381,81 @@ func (t *Tombstoner) prepareLatest() error {
 		var b [4]byte
 		if n, err := f.Read(b[:]); n == 4 && err == nil {
 			header := binary.BigEndian.Uint32(b[:])
-			// There is an existing tombstone on disk and it's not a v4.
-			// We can't support it.
-			if header != v4header {
+			switch {
+			case header == v4header && t.keyring != nil:
+				plain = f
+			case header == v4header:
+			case header == v5header && t.keyring == nil:
+				removeTmp()
+				return errEncryptedTombstone
+			case header == v5header:
+			default:
+				// There is an existing tombstone on disk and it's not a v4
+				// or v5. We can't support it.
 				removeTmp()
 				return errIncompatibleV4Version
 			}
 
-			// Seek back to the beginning we copy the header
-			if _, err := f.Seek(0, io.SeekStart); err != nil {
-				removeTmp()
-				return err
-			}
+			if plain == nil {
+				// Seek back to the beginning we copy the header
+				if _, err := f.Seek(0, io.SeekStart); err != nil {
+					removeTmp()
+					return err
+				}
 
-			// Copy the whole file
-			if _, err := io.Copy(tmp, f); err != nil {
-				f.Close()
-				removeTmp()
-				return err
+				// Copy the whole file
+				if size, err = io.Copy(tmp, f); err != nil {
+					removeTmp()
+					return err
+				}
 			}
 		}
 	}
 
-	// Else, the error was that the file does not exist. Create a new one.
-	var b [8]byte
-	bw := bufio.NewWriterSize(tmp, 64*1024)
-
-	// Write the header only if the file is new
-	if os.IsNotExist(err) {
-		binary.BigEndian.PutUint32(b[:4], v4header)
-		if _, err := bw.Write(b[:4]); err != nil {
+	// Else, the file does not exist, or is encrypted now. Write the header.
+	if size == 0 {
+		header := uint32(v4header)
+		if t.keyring != nil {
+			header = v5header
+		}
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], header)
+		if _, err := bw.Write(b[:]); err != nil {
 			removeTmp()
 			return err
 		}
+		size = headerSize
 	}
 
 	// Write the tombstones
-	gz := gzip.NewWriter(bw)
+	if t.keyring == nil {
+		t.gz = gzip.NewWriter(bw)
+		t.w = t.gz
+	} else {
+		key := t.keyring.Current()
+		if _, err := bw.Write(encryption.AppendKeyID(nil, key.ID())); err != nil {
+			removeTmp()
+			return err
+		}
+		t.enc = encryption.NewSectionWriter(bw, key, offsetAD(size))
+		t.w = t.enc
+	}
+
+	// Encrypt the tombstones of a v4 file. They are walked again, since
+	// their offsets changed.
+	if plain != nil {
+		t.lastAppliedOffset = 0
+		if err := t.readTombstoneV4(plain, func(ts Tombstone) error {
+			return t.writeTombstoneV4(t.w, ts)
+		}); err != nil {
+			t.w, t.gz, t.enc = nil, nil, nil
+			removeTmp()
+			return err
+		}
+		t.lastAppliedOffset = 0
+	}
 
 	t.pendingFile = tmp
-	t.gz = gz
 	t.bw = bw
 
 	return nil
@@ -396,7 +467,11 @@ func (t *Tombstoner) commit() error {
 		return nil
 	}
 
-	if err := t.gz.Close(); err != nil {
+	if t.enc != nil {
+		if err := t.enc.Close(); err != nil {
+			return err
+		}
+	} else if err := t.gz.Close(); err != nil {
 		return err
 	}
 
@@ -426,7 +501,9 @@ func (t *Tombstoner) commit() error {
 
 	t.pendingFile = nil
 	t.bw = nil
+	t.w = nil
 	t.gz = nil
+	t.enc = nil
 
 	return nil
 }
@@ -438,7 +515,9 @@ func (t *Tombstoner) rollback() error {
 
 	tmpFilename := t.pendingFile.Name()
 	t.pendingFile.Close()
+	t.w = nil
 	t.gz = nil
+	t.enc = nil
 	t.bw = nil
 	t.pendingFile = nil
 	return os.Remove(tmpFilename)
@@ -458,13 +537,6 @@ func (t *Tombstoner) readTombstoneV4(f *os.File, fn func(t Tombstone) error) err
 		}
 	}
 
-	var (
-		prefix   bool
-		min, max int64
-		key      []byte
-		kmask    = 0xff000000 // Mask for non key-length bits
-	)
-
 	br := bufio.NewReaderSize(f, 64*1024)
 	gr, err := gzip.NewReader(br)
 	if err == io.EOF {
@@ -477,53 +549,74 @@ func (t *Tombstoner) readTombstoneV4(f *os.File, fn func(t Tombstone) error) err
 	b := make([]byte, 4096)
 	for {
 		gr.Multistream(false)
-		if err := func() error {
-			for {
-				if _, err = io.ReadFull(gr, b[:4]); err == io.EOF || err == io.ErrUnexpectedEOF {
-					return nil
-				} else if err != nil {
-					return err
-				}
+		if b, err = readTombstoneEntries(gr, b, fn); err != nil {
+			return err
+		}
 
-				keyLen := int(binary.BigEndian.Uint32(b[:4]))
-				prefix = keyLen>>31 == 1 // Prefix is set according to whether the highest bit is set.
+		for _, t := range t.tombstones {
+			if err := fn(t); err != nil {
+				return err
+			}
+		}
 
-				// Remove 8 MSB to get correct length.
-				keyLen &^= kmask
+		err = gr.Reset(br)
+		if err == io.EOF {
+			break
+		}
+	}
 
-				if keyLen+16 > len(b) {
-					b = make([]byte, keyLen+16)
-				}
+	// Save the position of tombstone file so we don't re-apply the same set again if there are
+	// more deletes.
+	pos, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	t.lastAppliedOffset = pos
+	return nil
+}
 
-				if _, err := io.ReadFull(gr, b[:keyLen]); err != nil {
-					return err
-				}
+// readTombstoneV5 reads encrypted tombstone files, made of sections
+// appended together.
+func (t *Tombstoner) readTombstoneV5(f *os.File, fn func(t Tombstone) error) error {
+	if t.keyring == nil {
+		return errEncryptedTombstone
+	}
 
-				// Copy the key since b is re-used
-				key = b[:keyLen]
+	// Skip header, already checked earlier
+	pos := t.lastAppliedOffset
+	if pos == 0 {
+		pos = headerSize
+	}
+	if _, err := f.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
 
-				minBuf := b[keyLen : keyLen+8]
-				maxBuf := b[keyLen+8 : keyLen+16]
-				if _, err := io.ReadFull(gr, minBuf); err != nil {
-					return err
-				}
+	var (
+		plain []byte
+		b     = make([]byte, 4096)
+	)
 
-				min = int64(binary.BigEndian.Uint64(minBuf))
-				if _, err := io.ReadFull(gr, maxBuf); err != nil {
-					return err
-				}
+	br := bufio.NewReaderSize(f, 64*1024)
+	for {
+		n, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		id := make([]byte, n)
+		if _, err := io.ReadFull(br, id); err != nil {
+			return err
+		}
+		key, err := t.keyring.Key(string(id))
+		if err != nil {
+			return err
+		}
 
-				max = int64(binary.BigEndian.Uint64(maxBuf))
-				if err := fn(Tombstone{
-					Key:    key,
-					Min:    min,
-					Max:    max,
-					Prefix: prefix,
-				}); err != nil {
-					return err
-				}
-			}
-		}(); err != nil {
+		if plain, err = encryption.ReadSection(plain[:0], br, key, offsetAD(pos)); err != nil {
+			return fmt.Errorf("cannot decrypt tombstones: %v", err)
+		}
+		if b, err = readTombstoneEntries(bytes.NewReader(plain), b, fn); err != nil {
 			return err
 		}
 
@@ -533,22 +626,78 @@ func (t *Tombstoner) readTombstoneV4(f *os.File, fn func(t Tombstone) error) err
 			}
 		}
 
-		err = gr.Reset(br)
-		if err == io.EOF {
-			break
+		// The next section starts where this one was read to.
+		cur, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
 		}
+		pos = cur - int64(br.Buffered())
 	}
 
 	// Save the position of tombstone file so we don't re-apply the same set again if there are
 	// more deletes.
-	pos, err := f.Seek(0, io.SeekCurrent)
-	if err != nil {
-		return err
-	}
 	t.lastAppliedOffset = pos
 	return nil
 }
 
+// readTombstoneEntries calls fn for every entry read from r, until it is
+// exhausted. b is a buffer the keys are read into, and the possibly grown
+// buffer is returned.
+func readTombstoneEntries(r io.Reader, b []byte, fn func(t Tombstone) error) ([]byte, error) {
+	var (
+		prefix   bool
+		min, max int64
+		key      []byte
+		kmask    = 0xff000000 // Mask for non key-length bits
+	)
+
+	for {
+		if _, err := io.ReadFull(r, b[:4]); err == io.EOF || err == io.ErrUnexpectedEOF {
+			return b, nil
+		} else if err != nil {
+			return b, err
+		}
+
+		keyLen := int(binary.BigEndian.Uint32(b[:4]))
+		prefix = keyLen>>31 == 1 // Prefix is set according to whether the highest bit is set.
+
+		// Remove 8 MSB to get correct length.
+		keyLen &^= kmask
+
+		if keyLen+16 > len(b) {
+			b = make([]byte, keyLen+16)
+		}
+
+		if _, err := io.ReadFull(r, b[:keyLen]); err != nil {
+			return b, err
+		}
+
+		// Copy the key since b is re-used
+		key = b[:keyLen]
+
+		minBuf := b[keyLen : keyLen+8]
+		maxBuf := b[keyLen+8 : keyLen+16]
+		if _, err := io.ReadFull(r, minBuf); err != nil {
+			return b, err
+		}
+
+		min = int64(binary.BigEndian.Uint64(minBuf))
+		if _, err := io.ReadFull(r, maxBuf); err != nil {
+			return b, err
+		}
+
+		max = int64(binary.BigEndian.Uint64(maxBuf))
+		if err := fn(Tombstone{
+			Key:    key,
+			Min:    min,
+			Max:    max,
+			Prefix: prefix,
+		}); err != nil {
+			return b, err
+		}
+	}
+}
+
 func (t *Tombstoner) tombstonePath() string {
 	if strings.HasSuffix(t.Path, "tombstone") {
 		return t.Path
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"os"
 	"reflect"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 )
 
@@ -512,6 +514,101 @@ func TestTombstoner_Existing(t *testing.T) {
 	})
 }
 
+func TestTombstoner_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer func() { os.RemoveAll(dir) }()
+
+	f := MustTempFile(dir)
+	k1, k2 := mustEncryptionKey(t, "k1", 1), mustEncryptionKey(t, "k2", 2)
+	kr1, err := encryption.NewKeyring(k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kr2, err := encryption.NewKeyring(k1, k2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := func(entries []tsm1.Tombstone) []string {
+		var keys []string
+		for _, e := range entries {
+			keys = append(keys, string(e.Key))
+		}
+		return keys
+	}
+	header := func() []byte {
+		b, err := ioutil.ReadFile(tsm1.NewTombstoner(f.Name(), nil).TombstoneFiles()[0].Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b[:4]
+	}
+
+	// A plaintext file is encrypted when it is written with a keyring.
+	ts := tsm1.NewTombstoner(f.Name(), nil)
+	if err := ts.Add([][]byte{[]byte("foo")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing tombstone: %v", err)
+	}
+
+	ts = tsm1.NewTombstoner(f.Name(), nil)
+	ts.WithKeyring(kr1)
+	if got, exp := keys(mustReadAll(ts)), []string{"foo"}; !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected tombstones -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+	if err := ts.Add([][]byte{[]byte("bar")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing tombstone: %v", err)
+	}
+	if got, exp := header(), []byte{0, 0, 0x15, 0x05}; !bytes.Equal(got, exp) {
+		t.Fatalf("unexpected header: got %x, exp %x", got, exp)
+	}
+	if got, exp := keys(mustReadAll(ts)), []string{"foo", "bar"}; !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected tombstones -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+
+	// Sections are appended with the current key, and only the new ones
+	// are walked again.
+	ts = tsm1.NewTombstoner(f.Name(), nil)
+	ts.WithKeyring(kr2)
+	if got, exp := keys(mustReadAll(ts)), []string{"foo", "bar"}; !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected tombstones -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+	if err := ts.AddPrefixRange([]byte("baz"), 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing tombstone: %v", err)
+	}
+	exp := []tsm1.Tombstone{{Key: []byte("baz"), Prefix: true, Min: 1, Max: 2}}
+	if got := mustReadAll(ts); !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected tombstones -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+
+	ts = tsm1.NewTombstoner(f.Name(), nil)
+	ts.WithKeyring(kr2)
+	if got, exp := keys(mustReadAll(ts)), []string{"foo", "bar", "baz"}; !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected tombstones -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+
+	// The file can't be read or written without the keys.
+	ts = tsm1.NewTombstoner(f.Name(), nil)
+	if err := ts.Walk(func(tsm1.Tombstone) error { return nil }); err == nil {
+		t.Fatal("expected error walking without a keyring")
+	}
+	if err := ts.Add([][]byte{[]byte("qux")}); err == nil {
+		t.Fatal("expected error adding without a keyring")
+	}
+	ts.WithKeyring(kr1)
+	if err := ts.Walk(func(tsm1.Tombstone) error { return nil }); err == nil {
+		t.Fatal("expected error walking without the current key")
+	}
+}
+
 func mustReadAll(t *tsm1.Tombstoner) []tsm1.Tombstone {
 	var tombstones []tsm1.Tombstone
 	if err := t.Walk(func(t tsm1.Tombstone) error {
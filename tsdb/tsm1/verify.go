@@ -0,0 +1,332 @@
+package tsm1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+)
+
+// The kinds of problem reported by VerifyTSM.
+const (
+	// TSMIndexProblem is a problem with the index of a TSM file. A file whose
+	// index cannot be read cannot be repaired.
+	TSMIndexProblem = "index"
+
+	// TSMBlockProblem is a block that fails its checksum or cannot be decoded.
+	TSMBlockProblem = "block"
+
+	// TSMTombstoneProblem is a tombstone file that cannot be read.
+	TSMTombstoneProblem = "tombstone"
+)
+
+// TSMProblem describes a single problem found in a TSM file.
+type TSMProblem struct {
+	Kind    string `json:"kind"`
+	Key     string `json:"key,omitempty"`
+	MinTime int64  `json:"minTime,omitempty"`
+	MaxTime int64  `json:"maxTime,omitempty"`
+	Offset  int64  `json:"offset,omitempty"`
+	Error   string `json:"error"`
+}
+
+// TSMVerification is the result of verifying a single TSM file.
+type TSMVerification struct {
+	Path     string       `json:"path"`
+	Blocks   int          `json:"blocks"`
+	Problems []TSMProblem `json:"problems,omitempty"`
+
+	// Repaired is set when the file was rewritten without its corrupt blocks.
+	// Removed is set when the file was removed because none of its blocks
+	// could be kept.
+	Repaired bool `json:"repaired,omitempty"`
+	Removed  bool `json:"removed,omitempty"`
+}
+
+// Healthy returns true if no problems were found in the file.
+func (v *TSMVerification) Healthy() bool { return len(v.Problems) == 0 }
+
+// repairable returns true if the file has problems that can be repaired by
+// rewriting its blocks.
+func (v *TSMVerification) repairable() bool {
+	var fixable bool
+	for _, p := range v.Problems {
+		switch {
+		case p.Kind == TSMTombstoneProblem:
+			return false
+		case p.Kind == TSMIndexProblem && p.Key == "":
+			return false // The index could not be read at all.
+		default:
+			fixable = true
+		}
+	}
+	return fixable
+}
+
+// VerifyTSM verifies the block checksums, index ordering and tombstone files
+// of TSM files. The files must not be in use by a running engine.
+type VerifyTSM struct {
+	Paths []string
+
+	// Repair rewrites every file with corrupt blocks or index entries,
+	// keeping only the blocks that verify successfully.
+	Repair bool
+
+	// Keyring holds the keys of encrypted files. Repaired files are
+	// encrypted with its current key.
+	Keyring *encryption.Keyring
+}
+
+// Run verifies each file in Paths, repairing them if requested.
+func (v *VerifyTSM) Run() ([]*TSMVerification, error) {
+	results := make([]*TSMVerification, 0, len(v.Paths))
+	for _, path := range v.Paths {
+		result, err := v.verifyFile(path)
+		if err != nil {
+			return results, fmt.Errorf("%s: %v", path, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// verifyFile verifies and optionally repairs the TSM file at path.
+func (v *VerifyTSM) verifyFile(path string) (*TSMVerification, error) {
+	result := &TSMVerification{Path: path}
+
+	ts := NewTombstoner(path, nil)
+	ts.WithKeyring(v.Keyring)
+	if err := ts.Walk(func(Tombstone) error { return nil }); err != nil {
+		result.Problems = append(result.Problems, TSMProblem{
+			Kind:  TSMTombstoneProblem,
+			Error: err.Error(),
+		})
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewTSMReader(f, withoutTombstones(), WithKeyring(v.Keyring))
+	if err != nil {
+		f.Close()
+		result.Problems = append(result.Problems, TSMProblem{
+			Kind:  TSMIndexProblem,
+			Error: err.Error(),
+		})
+		return result, nil
+	}
+	defer r.Close()
+
+	// The offsets of every block that must be dropped to repair the file.
+	corrupt := make(map[int64]struct{})
+
+	var prev []byte
+	iter := r.index.Iterator(nil)
+	for iter.Next() {
+		key, typ, entries := iter.Key(), iter.Type(), iter.Entries()
+
+		if prev != nil && bytes.Compare(key, prev) <= 0 {
+			for _, e := range entries {
+				corrupt[e.Offset] = struct{}{}
+			}
+			result.Problems = append(result.Problems, TSMProblem{
+				Kind:  TSMIndexProblem,
+				Key:   string(key),
+				Error: "key out of order",
+			})
+			continue
+		}
+		prev = append(prev[:0], key...)
+
+		for j := range entries {
+			e := &entries[j]
+			result.Blocks++
+
+			msg := ""
+			if e.MinTime > e.MaxTime {
+				msg = "block min time after max time"
+			} else if j > 0 && e.MinTime < entries[j-1].MinTime {
+				msg = "block out of order"
+			}
+			if msg != "" {
+				corrupt[e.Offset] = struct{}{}
+				result.Problems = append(result.Problems, TSMProblem{
+					Kind:    TSMIndexProblem,
+					Key:     string(key),
+					MinTime: e.MinTime,
+					MaxTime: e.MaxTime,
+					Offset:  e.Offset,
+					Error:   msg,
+				})
+				continue
+			}
+
+			if err := verifyBlock(r, e, typ); err != nil {
+				corrupt[e.Offset] = struct{}{}
+				result.Problems = append(result.Problems, TSMProblem{
+					Kind:    TSMBlockProblem,
+					Key:     string(key),
+					MinTime: e.MinTime,
+					MaxTime: e.MaxTime,
+					Offset:  e.Offset,
+					Error:   err.Error(),
+				})
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		result.Problems = append(result.Problems, TSMProblem{
+			Kind:  TSMIndexProblem,
+			Error: err.Error(),
+		})
+	}
+
+	if !v.Repair || !result.repairable() {
+		return result, nil
+	}
+
+	var opts []tsmWriterOption
+	if v.Keyring != nil {
+		opts = append(opts, WithEncryptionKey(v.Keyring.Current()))
+	}
+	n, err := rewriteTSM(r, corrupt, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if n == 0 {
+		if err := r.Close(); err != nil {
+			return nil, err
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+		if err := os.RemoveAll(StatsFilename(path)); err != nil {
+			return nil, err
+		}
+		if err := NewTombstoner(path, nil).Delete(); err != nil {
+			return nil, err
+		}
+		result.Removed = true
+		return result, nil
+	}
+
+	if err := r.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(path+"."+TmpTSMFileExtension, path); err != nil {
+		return nil, err
+	}
+	result.Repaired = true
+	return result, nil
+}
+
+// verifyBlock checks the checksum of the block described by e and that it
+// decodes to values of type typ within the time range of e.
+func verifyBlock(r *TSMReader, e *IndexEntry, typ byte) (err error) {
+	// Decoding corrupt data is not guaranteed to fail cleanly.
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic decoding block: %v", rec)
+		}
+	}()
+
+	if e.Offset+int64(e.Size) > int64(r.Size()) {
+		return errors.New("block extends past end of file")
+	}
+
+	checksum, buf, err := r.ReadBytes(e, nil)
+	if err != nil {
+		return err
+	} else if len(buf) == 0 {
+		return errors.New("empty block")
+	} else if got := crc32.ChecksumIEEE(buf); got != checksum {
+		return fmt.Errorf("checksum mismatch: got %d, exp %d", got, checksum)
+	}
+
+	if blockType, err := BlockType(buf); err != nil {
+		return err
+	} else if blockType != typ {
+		return fmt.Errorf("block type mismatch: got %d, exp %d", blockType, typ)
+	}
+
+	values, err := DecodeBlock(buf, nil)
+	if err != nil {
+		return err
+	} else if len(values) == 0 {
+		return errors.New("block has no values")
+	}
+	for _, value := range values {
+		if ts := value.UnixNano(); ts < e.MinTime || ts > e.MaxTime {
+			return fmt.Errorf("value at %d outside of block time range", ts)
+		}
+	}
+	return nil
+}
+
+// rewriteTSM writes every block in r, except those at the offsets in skip, to
+// a temporary file alongside r. It returns the number of blocks written. If no
+// blocks are written the temporary file is removed.
+func rewriteTSM(r *TSMReader, skip map[int64]struct{}, options ...tsmWriterOption) (int, error) {
+	f, err := os.OpenFile(r.Path()+"."+TmpTSMFileExtension, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return 0, err
+	}
+
+	w, err := NewTSMWriter(f, options...)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return 0, err
+	}
+
+	n, err := func() (int, error) {
+		var n int
+		var prev []byte
+		iter := r.index.Iterator(nil)
+		for iter.Next() {
+			key := iter.Key()
+			if prev != nil && bytes.Compare(key, prev) <= 0 {
+				continue
+			}
+			prev = append(prev[:0], key...)
+
+			for _, e := range iter.Entries() {
+				if _, ok := skip[e.Offset]; ok {
+					continue
+				}
+
+				_, buf, err := r.ReadBytes(&e, nil)
+				if err != nil {
+					return 0, err
+				}
+				if err := w.WriteBlock(key, e.MinTime, e.MaxTime, buf); err != nil {
+					return 0, err
+				}
+				n++
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return 0, err
+		}
+
+		if n == 0 {
+			return 0, nil
+		}
+		if err := w.WriteIndex(); err != nil {
+			return 0, err
+		}
+		return n, w.Close()
+	}()
+
+	if err != nil || n == 0 {
+		w.Remove()
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,131 @@
+package tsm1
+
+import (
+	"os"
+	"testing"
+)
+
+func TestVerifyTSM(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	good := mustWriteVerifyTSM(t, dir, "cpu", "mem")
+	partial := mustWriteVerifyTSM(t, dir, "cpu", "mem")
+	bad := mustWriteVerifyTSM(t, dir, "cpu")
+
+	// Corrupt the data of the first block, which belongs to cpu.
+	mustCorruptTSM(t, partial, 5+4+2)
+	mustCorruptTSM(t, bad, 5+4+2)
+
+	results, err := (&VerifyTSM{Paths: []string{good, partial, bad}}).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !results[0].Healthy() {
+		t.Fatalf("got problems %+v, exp healthy", results[0].Problems)
+	}
+	for _, r := range results[1:] {
+		if got := len(r.Problems); got != 1 {
+			t.Fatalf("got %d problems, exp 1", got)
+		}
+		if p := r.Problems[0]; p.Kind != TSMBlockProblem || p.Key != "cpu" {
+			t.Fatalf("got problem %+v, exp corrupt cpu block", p)
+		}
+		if r.Repaired || r.Removed {
+			t.Fatal("file changed without repair")
+		}
+	}
+
+	results, err = (&VerifyTSM{Paths: []string{good, partial, bad}, Repair: true}).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results[0].Repaired || results[0].Removed {
+		t.Fatal("healthy file was changed")
+	}
+	if !results[1].Repaired {
+		t.Fatal("partially corrupt file was not repaired")
+	}
+	if !results[2].Removed {
+		t.Fatal("corrupt file was not removed")
+	}
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Fatalf("got %v, exp corrupt file to be removed", err)
+	}
+
+	results, err = (&VerifyTSM{Paths: []string{partial}}).Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Healthy() {
+		t.Fatalf("got problems %+v after repair, exp healthy", results[0].Problems)
+	}
+	if got := results[0].Blocks; got != 1 {
+		t.Fatalf("got %d blocks after repair, exp 1", got)
+	}
+
+	f, err := os.Open(partial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if r.Contains([]byte("cpu")) {
+		t.Fatal("corrupt cpu block was kept")
+	}
+	values, err := r.ReadAll([]byte("mem"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(values) != 1 || values[0].Value() != int64(2) {
+		t.Fatalf("got values %v for mem, exp [2]", values)
+	}
+}
+
+// mustWriteVerifyTSM writes a TSM file with a single block for each key.
+func mustWriteVerifyTSM(t *testing.T, dir string, keys ...string) string {
+	t.Helper()
+
+	f := mustTempFile(dir)
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, key := range keys {
+		if err := w.Write([]byte(key), []Value{NewValue(int64(i), int64(i+1))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+// mustCorruptTSM flips the bits of the byte at offset in the file at path.
+func mustCorruptTSM(t *testing.T, path string, offset int64) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, offset); err != nil {
+		t.Fatal(err)
+	}
+	b[0] = ^b[0]
+	if _, err := f.WriteAt(b, offset); err != nil {
+		t.Fatal(err)
+	}
+}
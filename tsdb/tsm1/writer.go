@@ -60,6 +60,24 @@ The last section is the footer that stores the offset of the start of the index.
 │Index Ofs│
 │ 8 bytes │
 └─────────┘
+
+Encrypted files use version 2 and store the id of their encryption key
+after the version.
+
+┌───────────────────────────────────────────────┐
+│               Encrypted Header                │
+├─────────┬─────────┬────────────┬──────────────┤
+│  Magic  │ Version │ Key ID Len │    Key ID    │
+│ 4 bytes │ 1 byte  │   1 byte   │   N bytes    │
+└─────────┴─────────┴────────────┴──────────────┘
+
+The data of each block is then sealed with AES-GCM, using its offset in the
+file as additional data.  The CRC32 of a block is computed over its plaintext
+data and the size in the index is the size of the sealed block.  The index is
+sealed in chunks of encryption.ChunkSize bytes, as a section bound to its
+offset in the file (see encryption.NewSectionWriter), so that writers do not
+buffer it whole.  Since the index is accessed at arbitrary offsets, readers
+decrypt it whole into memory instead of accessing it through the mmap.
 */
 
 import (
@@ -75,6 +93,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 )
 
 const (
@@ -85,6 +104,9 @@ const (
 	// Version indicates the version of the TSM file format.
 	Version byte = 1
 
+	// EncryptedVersion indicates the version of the encrypted TSM file format.
+	EncryptedVersion byte = 2
+
 	// Size in bytes of an index entry
 	indexEntrySize = 28
 
@@ -592,22 +614,39 @@ type tsmWriter struct {
 	lastSync int64
 
 	stats MeasurementStats
+
+	// key encrypts the blocks and index if set.
+	key    *encryption.Key
+	sealed []byte
+}
+
+type tsmWriterOption func(*tsmWriter)
+
+// WithEncryptionKey is an option for encrypting the file with key.
+var WithEncryptionKey = func(key *encryption.Key) tsmWriterOption {
+	return func(t *tsmWriter) {
+		t.key = key
+	}
 }
 
 // NewTSMWriter returns a new TSMWriter writing to w.
-func NewTSMWriter(w io.Writer) (TSMWriter, error) {
+func NewTSMWriter(w io.Writer, options ...tsmWriterOption) (TSMWriter, error) {
 	index := NewIndexWriter()
-	return &tsmWriter{
+	t := &tsmWriter{
 		wrapped: w,
 		w:       bufio.NewWriterSize(w, 1024*1024),
 		index:   index,
 		stats:   NewMeasurementStats(),
-	}, nil
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t, nil
 }
 
 // NewTSMWriterWithDiskBuffer returns a new TSMWriter writing to w and will use a disk
 // based buffer for the TSM index if possible.
-func NewTSMWriterWithDiskBuffer(w io.Writer) (TSMWriter, error) {
+func NewTSMWriterWithDiskBuffer(w io.Writer, options ...tsmWriterOption) (TSMWriter, error) {
 	var index IndexWriter
 	// Make sure is a File so we can write the temp index alongside it.
 	if fw, ok := w.(syncer); ok {
@@ -621,23 +660,31 @@ func NewTSMWriterWithDiskBuffer(w io.Writer) (TSMWriter, error) {
 		index = NewIndexWriter()
 	}
 
-	return &tsmWriter{
+	t := &tsmWriter{
 		wrapped: w,
 		w:       bufio.NewWriterSize(w, 1024*1024),
 		index:   index,
 		stats:   NewMeasurementStats(),
-	}, nil
+	}
+	for _, option := range options {
+		option(t)
+	}
+	return t, nil
 }
 
 // MeasurementStats returns the measurement statistics generated by the writer.
 func (t *tsmWriter) MeasurementStats() MeasurementStats { return t.stats }
 
 func (t *tsmWriter) writeHeader() error {
-	var buf [5]byte
+	buf := make([]byte, 5, 6+encryption.MaxKeyIDLength)
 	binary.BigEndian.PutUint32(buf[0:4], MagicNumber)
 	buf[4] = Version
+	if t.key != nil {
+		buf[4] = EncryptedVersion
+		buf = encryption.AppendKeyID(buf, t.key.ID())
+	}
 
-	n, err := t.w.Write(buf[:])
+	n, err := t.w.Write(buf)
 	if err != nil {
 		return err
 	}
@@ -645,6 +692,27 @@ func (t *tsmWriter) writeHeader() error {
 	return nil
 }
 
+// seal returns the data to write at the current position for block, sealed
+// if the file is encrypted. The returned slice is only valid until the next
+// call.
+func (t *tsmWriter) seal(block []byte) ([]byte, error) {
+	if t.key == nil {
+		return block, nil
+	}
+
+	var err error
+	t.sealed, err = t.key.Seal(t.sealed[:0], block, offsetAD(t.n+crc32.Size))
+	return t.sealed, err
+}
+
+// offsetAD returns the additional data sealing the data at offset of an
+// encrypted file, so that it can't be moved within the file.
+func offsetAD(offset int64) []byte {
+	var ad [8]byte
+	binary.BigEndian.PutUint64(ad[:], uint64(offset))
+	return ad[:]
+}
+
 // Write writes a new block containing key and values.
 func (t *tsmWriter) Write(key []byte, values Values) error {
 	if len(key) > maxKeyLength {
@@ -676,12 +744,17 @@ func (t *tsmWriter) Write(key []byte, values Values) error {
 	var checksum [crc32.Size]byte
 	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
 
+	data, err := t.seal(block)
+	if err != nil {
+		return err
+	}
+
 	_, err = t.w.Write(checksum[:])
 	if err != nil {
 		return err
 	}
 
-	n, err := t.w.Write(block)
+	n, err := t.w.Write(data)
 	if err != nil {
 		return err
 	}
@@ -732,12 +805,17 @@ func (t *tsmWriter) WriteBlock(key []byte, minTime, maxTime int64, block []byte)
 	var checksum [crc32.Size]byte
 	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
 
+	data, err := t.seal(block)
+	if err != nil {
+		return err
+	}
+
 	_, err = t.w.Write(checksum[:])
 	if err != nil {
 		return err
 	}
 
-	n, err := t.w.Write(block)
+	n, err := t.w.Write(data)
 	if err != nil {
 		return err
 	}
@@ -783,9 +861,19 @@ func (t *tsmWriter) WriteIndex() error {
 		t.index.(*directIndex).f = f
 	}
 
-	// Write the index
-	if _, err := t.index.WriteTo(t.w); err != nil {
-		return err
+	// Write the index, sealing it a chunk at a time if the file is encrypted.
+	if t.key == nil {
+		if _, err := t.index.WriteTo(t.w); err != nil {
+			return err
+		}
+	} else {
+		w := encryption.NewSectionWriter(t.w, t.key, offsetAD(indexPos))
+		if _, err := t.index.WriteTo(w); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
 	}
 
 	var buf [8]byte
@@ -892,7 +980,7 @@ func (t *tsmWriter) Size() uint32 {
 }
 
 // verifyVersion verifies that the reader's bytes are a TSM byte
-// stream of the correct version (1)
+// stream of the correct version (1, or 2 if encrypted)
 func verifyVersion(r io.ReadSeeker) error {
 	_, err := r.Seek(0, 0)
 	if err != nil {
@@ -910,8 +998,8 @@ func verifyVersion(r io.ReadSeeker) error {
 	if err != nil {
 		return fmt.Errorf("init: error reading version: %v", err)
 	}
-	if b[0] != Version {
-		return fmt.Errorf("init: file is version %b. expected %b", b[0], Version)
+	if b[0] != Version && b[0] != EncryptedVersion {
+		return fmt.Errorf("init: file is version %b. expected %b or %b", b[0], Version, EncryptedVersion)
 	}
 
 	return nil
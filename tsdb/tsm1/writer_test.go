@@ -4,12 +4,14 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 )
 
@@ -397,6 +399,158 @@ func TestTSMWriter_Read_Multiple(t *testing.T) {
 	}
 }
 
+func TestTSMWriter_Encrypted(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	f := MustTempFile(dir)
+
+	key := mustEncryptionKey(t, "k1", 1)
+	w, err := tsm1.NewTSMWriter(f, tsm1.WithEncryptionKey(key))
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	values := []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(1, 2.0)}
+	if err := w.Write([]byte("cpu,host=secret"), values); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error reading file: %v", err)
+	}
+	if bytes.Contains(b, []byte("host=secret")) {
+		t.Fatal("expected series key to be encrypted")
+	}
+
+	kr, err := encryption.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error open file: %v", err)
+	}
+	r, err := tsm1.NewTSMReader(fd, tsm1.WithKeyring(kr))
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+	defer r.Close()
+
+	readValues, err := r.ReadAll([]byte("cpu,host=secret"))
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+	if exp := len(values); exp != len(readValues) {
+		t.Fatalf("read values length mismatch: got %v, exp %v", len(readValues), exp)
+	}
+	for i, v := range values {
+		if v.Value() != readValues[i].Value() {
+			t.Fatalf("read value mismatch(%d): got %v, exp %v", i, readValues[i].Value(), v.Value())
+		}
+	}
+
+	// Tombstones of the file are encrypted too.
+	if err := r.Delete([][]byte{[]byte("cpu,host=secret")}); err != nil {
+		t.Fatalf("unexpected error deleting: %v", err)
+	}
+	ts := r.TombstoneFiles()
+	if len(ts) != 1 {
+		t.Fatalf("tombstone files mismatch: got %v, exp 1", len(ts))
+	}
+	if b, err = ioutil.ReadFile(ts[0].Path); err != nil {
+		t.Fatalf("unexpected error reading tombstone: %v", err)
+	}
+	if got, exp := b[:4], []byte{0, 0, 0x15, 0x05}; !bytes.Equal(got, exp) {
+		t.Fatalf("expected tombstone to be encrypted: got header %x, exp %x", got, exp)
+	}
+	if fd, err = os.Open(f.Name()); err != nil {
+		t.Fatalf("unexpected error open file: %v", err)
+	}
+	deleted, err := tsm1.NewTSMReader(fd, tsm1.WithKeyring(kr))
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+	if deleted.Contains([]byte("cpu,host=secret")) {
+		t.Fatal("expected key to be deleted")
+	}
+	deleted.Close()
+
+	// A file encrypted with a key missing from the keyring can't be read.
+	other, err := encryption.NewKeyring(mustEncryptionKey(t, "k2", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fd, err = os.Open(f.Name()); err != nil {
+		t.Fatalf("unexpected error open file: %v", err)
+	}
+	if _, err := tsm1.NewTSMReader(fd, tsm1.WithKeyring(other)); err == nil {
+		t.Fatal("expected file encrypted with an unknown key not to open")
+	}
+	fd.Close()
+}
+
+func TestTSMWriter_EncryptedIndexChunks(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	f := MustTempFile(dir)
+
+	key := mustEncryptionKey(t, "k1", 1)
+	w, err := tsm1.NewTSMWriterWithDiskBuffer(f, tsm1.WithEncryptionKey(key))
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	// Enough keys for the index to be sealed in several chunks.
+	const n = 5000
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("cpu,host=server-%05d#!~#value", i))
+		if err := w.Write(keys[i], []tsm1.Value{tsm1.NewValue(int64(i), float64(i))}); err != nil {
+			t.Fatalf("unexpected error writing: %v", err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	kr, err := encryption.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error open file: %v", err)
+	}
+	r, err := tsm1.NewTSMReader(fd, tsm1.WithKeyring(kr))
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+	defer r.Close()
+
+	if got := r.KeyCount(); got != n {
+		t.Fatalf("key count mismatch: got %v, exp %v", got, n)
+	}
+	for i, k := range keys {
+		values, err := r.ReadAll(k)
+		if err != nil {
+			t.Fatalf("unexpected error reading: %v", err)
+		}
+		if len(values) != 1 || values[0].Value() != float64(i) {
+			t.Fatalf("read value mismatch for %s: got %v", k, values)
+		}
+	}
+}
+
 func TestTSMWriter_WriteBlock_Empty(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -0,0 +1,55 @@
+package influxdb
+
+import (
+	"context"
+)
+
+// ErrUserIdentityNotFound is the error msg for a missing user identity.
+const ErrUserIdentityNotFound = "user identity not found"
+
+// ops for user identities error and user identity op logs.
+const (
+	OpFindUserIdentity   = "FindUserIdentity"
+	OpCreateUserIdentity = "CreateUserIdentity"
+	OpDeleteUserIdentity = "DeleteUserIdentity"
+)
+
+// UserIdentity binds a user to an identity verified by an external identity
+// provider. The identity is the subject the provider with the issuer knows
+// the user as, which unlike a name or email address is never reassigned.
+type UserIdentity struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+	UserID  ID     `json:"userID"`
+}
+
+// Validate returns an error if the user identity is incomplete.
+func (i *UserIdentity) Validate() error {
+	if i.Issuer == "" || i.Subject == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "user identity requires an issuer and subject",
+		}
+	}
+	if !i.UserID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "user identity requires a userID",
+		}
+	}
+	return nil
+}
+
+// UserIdentityService represents a service for binding users to external
+// identities.
+type UserIdentityService interface {
+	// FindUserIdentity returns the binding of the identity of issuer and subject.
+	FindUserIdentity(ctx context.Context, issuer, subject string) (*UserIdentity, error)
+
+	// CreateUserIdentity binds a user to an identity. An identity can only be
+	// bound to one user.
+	CreateUserIdentity(ctx context.Context, i *UserIdentity) error
+
+	// DeleteUserIdentity removes the binding of the identity of issuer and subject.
+	DeleteUserIdentity(ctx context.Context, issuer, subject string) error
+}
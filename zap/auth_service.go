@@ -80,3 +80,14 @@ func (s *AuthorizationService) UpdateAuthorization(ctx context.Context, id platf
 
 	return s.AuthorizationService.UpdateAuthorization(ctx, id, upd)
 }
+
+// RotateAuthorization replaces the token of an authorization and logs any errors.
+func (s *AuthorizationService) RotateAuthorization(ctx context.Context, id platform.ID) (a *platform.Authorization, err error) {
+	defer func() {
+		if err != nil {
+			s.Logger.Info("error rotating authorization", zap.Error(err))
+		}
+	}()
+
+	return s.AuthorizationService.RotateAuthorization(ctx, id)
+}